@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+)
+
+// client calls the JSON API of the server, with the access token of the session
+// once there is one.
+type client struct {
+	server string
+	token  string
+	http   *http.Client
+}
+
+func newClient(server, token string) *client {
+	return &client{
+		server: strings.TrimSuffix(server, "/"),
+		token:  token,
+		http:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// apiError is an error answer of the API, Code is the one of the server's errors.
+type apiError struct {
+	Status  int
+	Code    int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+}
+
+// do sends body as JSON to the path under the API and decodes the answer into out,
+// a nil out discards it.
+func (c *client) do(method, path string, query url.Values, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	target := c.server + localHttp.PathAPI + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	request, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		var answer localHttp.APIErrorResponse
+		if err := json.NewDecoder(response.Body).Decode(&answer); err != nil || answer.Error.Message == "" {
+			return &apiError{Status: response.StatusCode, Code: response.StatusCode, Message: http.StatusText(response.StatusCode)}
+		}
+		return &apiError{Status: response.StatusCode, Code: answer.Error.Code, Message: answer.Error.Message}
+	}
+
+	if out == nil || response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	return json.NewDecoder(response.Body).Decode(out)
+}
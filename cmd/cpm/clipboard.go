@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultClearAfter is how long a copied secret stays in the clipboard.
+const defaultClearAfter = 30 * time.Second
+
+// clearClipboardCommand is the hidden command the copy leaves running in the background
+// to clear the clipboard. The hash of the secret goes through the environment, so the
+// secret never shows up in the process list.
+const (
+	clearClipboardCommand = "clear-clipboard"
+	clipboardHashEnv      = "CPM_CLIPBOARD_SHA256"
+)
+
+// clipboardTool is a program that copies stdin to the clipboard and, when paste is
+// set, one that prints the clipboard.
+type clipboardTool struct {
+	copy  []string
+	paste []string
+}
+
+func findClipboard() (clipboardTool, error) {
+	tools := []clipboardTool{
+		{copy: []string{"pbcopy"}, paste: []string{"pbpaste"}},
+		{copy: []string{"clip.exe"}},
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append([]clipboardTool{{copy: []string{"wl-copy"}, paste: []string{"wl-paste", "--no-newline"}}}, tools...)
+	}
+	tools = append(tools,
+		clipboardTool{copy: []string{"xclip", "-selection", "clipboard"}, paste: []string{"xclip", "-selection", "clipboard", "-o"}},
+		clipboardTool{copy: []string{"xsel", "--clipboard", "--input"}, paste: []string{"xsel", "--clipboard", "--output"}},
+	)
+
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool.copy[0]); err == nil {
+			return tool, nil
+		}
+	}
+
+	return clipboardTool{}, errors.New("no clipboard tool found, install wl-clipboard, xclip or xsel")
+}
+
+func (t clipboardTool) write(value string) error {
+	cmd := exec.Command(t.copy[0], t.copy[1:]...)
+	cmd.Stdin = strings.NewReader(value)
+	return cmd.Run()
+}
+
+func (t clipboardTool) read() (string, error) {
+	if t.paste == nil {
+		return "", errors.New("the clipboard can not be read")
+	}
+
+	output, err := exec.Command(t.paste[0], t.paste[1:]...).Output()
+	return string(output), err
+}
+
+// copyToClipboard copies the value and clears the clipboard after clearAfter, unless
+// something else was copied in the meantime.
+func copyToClipboard(value string, clearAfter time.Duration) error {
+	tool, err := findClipboard()
+	if err != nil {
+		return err
+	}
+
+	if err := tool.write(value); err != nil {
+		return fmt.Errorf("copying to the clipboard failed: %w", err)
+	}
+
+	if clearAfter <= 0 {
+		fmt.Fprintln(os.Stderr, "Copied to the clipboard.")
+		return nil
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(executable, clearClipboardCommand, clearAfter.String())
+	cmd.Env = append(os.Environ(), clipboardHashEnv+"="+clipboardHash(value))
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("the clipboard will not be cleared: %w", err)
+	}
+	_ = cmd.Process.Release()
+
+	fmt.Fprintf(os.Stderr, "Copied to the clipboard, it is cleared in %s.\n", clearAfter)
+	return nil
+}
+
+func runClearClipboard(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: cpm clear-clipboard <duration>")
+	}
+
+	after, err := time.ParseDuration(args[0])
+	if err != nil {
+		return err
+	}
+	time.Sleep(after)
+
+	tool, err := findClipboard()
+	if err != nil {
+		return err
+	}
+
+	// a clipboard that can not be read is cleared anyway
+	if current, err := tool.read(); err == nil && clipboardHash(current) != os.Getenv(clipboardHashEnv) {
+		return nil
+	}
+
+	return tool.write("")
+}
+
+func clipboardHash(value string) string {
+	sum := sha256.Sum256(bytes.TrimRight([]byte(value), "\r\n"))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	lowerLetters = "abcdefghijklmnopqrstuvwxyz"
+	upperLetters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digits       = "0123456789"
+	symbols      = "!@#$%^&*()-_=+[]{};:,.?/"
+
+	defaultPasswordLength = 20
+	minPasswordLength     = 8
+)
+
+func runGenerate(args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	length := flags.Int("length", defaultPasswordLength, "length of the password")
+	noSymbols := flags.Bool("no-symbols", false, "only letters and digits")
+	copyIt := flags.Bool("copy", false, "copy the password instead of printing it")
+	clearAfter := flags.Duration("clear", defaultClearAfter, "clear the clipboard after this long, 0 keeps it")
+	_ = flags.Parse(args)
+
+	password, err := generatePassword(*length, !*noSymbols)
+	if err != nil {
+		return err
+	}
+
+	if *copyIt {
+		return copyToClipboard(password, *clearAfter)
+	}
+
+	fmt.Println(password)
+	return nil
+}
+
+// generatePassword draws every character uniformly and retries until every class of
+// characters shows up, so the password passes the usual composition rules.
+func generatePassword(length int, withSymbols bool) (string, error) {
+	if length < minPasswordLength {
+		return "", fmt.Errorf("a password has at least %d characters", minPasswordLength)
+	}
+
+	classes := []string{lowerLetters, upperLetters, digits}
+	if withSymbols {
+		classes = append(classes, symbols)
+	}
+	alphabet := strings.Join(classes, "")
+	size := big.NewInt(int64(len(alphabet)))
+
+	for range 100 {
+		password := make([]byte, length)
+		for i := range password {
+			index, err := rand.Int(rand.Reader, size)
+			if err != nil {
+				return "", err
+			}
+			password[i] = alphabet[index.Int64()]
+		}
+
+		if hasEveryClass(string(password), classes) {
+			return string(password), nil
+		}
+	}
+
+	return "", errors.New("no password with every class of characters could be generated")
+}
+
+func hasEveryClass(password string, classes []string) bool {
+	for _, class := range classes {
+		if !strings.ContainsAny(password, class) {
+			return false
+		}
+	}
+
+	return true
+}
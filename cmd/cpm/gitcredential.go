@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	vaultModel "github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/delivery/http/handler/model"
+)
+
+// gitCredential is what git tells a credential helper about the remote, one key=value
+// per line. The path is only sent when credential.useHttpPath is set.
+type gitCredential struct {
+	protocol string
+	host     string
+	path     string
+	username string
+	password string
+}
+
+func readGitCredential(r io.Reader) (gitCredential, error) {
+	var credential gitCredential
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return credential, fmt.Errorf("%q is not a key=value line", line)
+		}
+
+		switch key {
+		case "protocol":
+			credential.protocol = value
+		case "host":
+			credential.host = value
+		case "path":
+			credential.path = value
+		case "username":
+			credential.username = value
+		case "password":
+			credential.password = value
+		case "url":
+			u, err := url.Parse(value)
+			if err != nil {
+				return credential, err
+			}
+			credential.protocol, credential.host, credential.path = u.Scheme, u.Host, u.Path
+			if u.User != nil {
+				credential.username = u.User.Username()
+			}
+		}
+	}
+
+	return credential, scanner.Err()
+}
+
+// match tells whether the url and username of the item fit the credential, and how
+// well. An item without a path fits every repository of its host, one with a path fits
+// the repositories under it and is preferred when git sends the path.
+func (g gitCredential) match(view itemView) (int, bool) {
+	if view.Locked || view.Url == "" {
+		return 0, false
+	}
+
+	raw := view.Url
+	if !strings.Contains(raw, "://") {
+		raw = "//" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || !strings.EqualFold(u.Host, g.host) {
+		return 0, false
+	}
+	if u.Scheme != "" && !strings.EqualFold(u.Scheme, g.protocol) {
+		return 0, false
+	}
+	if g.username != "" && view.Username != g.username {
+		return 0, false
+	}
+
+	itemPath, path := gitPath(u.Path), gitPath(g.path)
+	switch {
+	case itemPath == "":
+		return 1, true
+	case path == "":
+		return 0, true
+	case path == itemPath || strings.HasPrefix(path, itemPath+"/"):
+		return 1 + len(itemPath), true
+	default:
+		return 0, false
+	}
+}
+
+func gitPath(path string) string {
+	return strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+}
+
+func (g gitCredential) url() string {
+	u := url.URL{Scheme: g.protocol, Host: g.host, Path: "/" + gitPath(g.path)}
+	return strings.TrimSuffix(u.String(), "/")
+}
+
+// runGitCredential is a git credential helper. Git passes the action as the last
+// argument and the credential on stdin, the helper is set up as a shell snippet:
+//
+//	git config --global credential.helper '!cpm git-credential'
+//	git config --global credential.https://git.example.com.helper '!cpm git-credential -groups team'
+func runGitCredential(args []string) error {
+	flags := flag.NewFlagSet("git-credential", flag.ExitOnError)
+	groups := flags.String("groups", "", "comma separated ids or names of the groups to share stored credentials with")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: cpm git-credential [flags] get|store|erase")
+	}
+
+	credential, err := readGitCredential(os.Stdin)
+	if err != nil {
+		return err
+	}
+	if credential.host == "" {
+		return nil
+	}
+
+	s, c, err := openSession()
+	if err != nil {
+		return err
+	}
+
+	items, err := readAllItems(c)
+	if err != nil {
+		return err
+	}
+
+	switch flags.Arg(0) {
+	case "get":
+		view, _, ok := bestGitMatch(credential, items, s.VaultKey)
+		if ok {
+			fmt.Printf("username=%s\npassword=%s\n", view.Username, view.Password)
+		}
+		return nil
+	case "store":
+		return storeGitCredential(c, s, credential, items, *groups)
+	case "erase":
+		return eraseGitCredential(c, s, credential, items)
+	default:
+		// git may grow actions a helper does not know, those are ignored
+		return nil
+	}
+}
+
+func bestGitMatch(credential gitCredential, items []vaultModel.APIVaultItem, vaultKey []byte) (itemView, vaultModel.APIVaultItem, bool) {
+	var (
+		best      itemView
+		bestItem  vaultModel.APIVaultItem
+		bestScore = -1
+	)
+	for _, item := range items {
+		view := newItemView(item, vaultKey)
+		if score, ok := credential.match(view); ok && score > bestScore {
+			best, bestItem, bestScore = view, item, score
+		}
+	}
+
+	return best, bestItem, bestScore >= 0
+}
+
+// storeGitCredential keeps a credential git accepted, the password of a matching item
+// is replaced and a new item is added when none matches.
+func storeGitCredential(c *client, s session, credential gitCredential, items []vaultModel.APIVaultItem, groups string) error {
+	if credential.username == "" || credential.password == "" {
+		return nil
+	}
+
+	view, item, ok := bestGitMatch(credential, items, s.VaultKey)
+	if ok && view.Password == credential.password {
+		return nil
+	}
+
+	if ok {
+		encrypted, err := encryptSecrets(s.VaultKey, secrets{
+			Username: view.Username, Password: credential.password, Url: view.Url, Note: view.Note,
+		})
+		if err != nil {
+			return err
+		}
+
+		path := fmt.Sprintf("items/%d/", item.ID)
+		return c.do(http.MethodPut, path, nil, itemWrite(item.Name, item.Description, encrypted, itemGroupIDs(item)), nil)
+	}
+
+	groupIDs, err := resolveGroups(c, groups)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := encryptSecrets(s.VaultKey, secrets{
+		Username: credential.username, Password: credential.password, Url: credential.url(),
+	})
+	if err != nil {
+		return err
+	}
+
+	name := credential.username + "@" + strings.TrimPrefix(credential.url(), credential.protocol+"://")
+	if len(name) > 50 {
+		name = name[:50]
+	}
+
+	return c.do(http.MethodPost, "items/", nil, itemWrite(name, "stored by git", encrypted, groupIDs), nil)
+}
+
+// eraseGitCredential deletes the item of a credential the remote rejected, as long as
+// it still holds the rejected password.
+func eraseGitCredential(c *client, s session, credential gitCredential, items []vaultModel.APIVaultItem) error {
+	view, item, ok := bestGitMatch(credential, items, s.VaultKey)
+	if !ok || credential.password == "" || view.Password != credential.password {
+		return nil
+	}
+
+	return c.do(http.MethodDelete, fmt.Sprintf("items/%d/", item.ID), nil, nil, nil)
+}
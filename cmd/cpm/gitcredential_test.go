@@ -0,0 +1,269 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	vaultModel "github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/delivery/http/handler/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitCredentialMatch(t *testing.T) {
+	t.Parallel()
+
+	https := gitCredential{protocol: "https", host: "git.example.com"}
+	withPath := gitCredential{protocol: "https", host: "git.example.com", path: "org/repo.git"}
+
+	testcases := []struct {
+		name       string
+		credential gitCredential
+		view       itemView
+		score      int
+		ok         bool
+	}{
+		{
+			name:       "host",
+			credential: https,
+			view:       itemView{Url: "https://git.example.com"},
+			score:      1,
+			ok:         true,
+		},
+		{
+			name:       "host in another case",
+			credential: https,
+			view:       itemView{Url: "https://Git.Example.com/"},
+			score:      1,
+			ok:         true,
+		},
+		{
+			name:       "url without a scheme",
+			credential: https,
+			view:       itemView{Url: "git.example.com"},
+			score:      1,
+			ok:         true,
+		},
+		{
+			name:       "other scheme",
+			credential: https,
+			view:       itemView{Url: "http://git.example.com"},
+		},
+		{
+			name:       "other host",
+			credential: https,
+			view:       itemView{Url: "https://gitlab.example.com"},
+		},
+		{
+			name:       "subdomain of the host",
+			credential: https,
+			view:       itemView{Url: "https://example.com"},
+		},
+		{
+			name:       "same port",
+			credential: gitCredential{protocol: "https", host: "git.example.com:8443"},
+			view:       itemView{Url: "https://git.example.com:8443"},
+			score:      1,
+			ok:         true,
+		},
+		{
+			name:       "other port",
+			credential: gitCredential{protocol: "https", host: "git.example.com:8443"},
+			view:       itemView{Url: "https://git.example.com"},
+		},
+		{
+			name:       "port the remote does not use",
+			credential: https,
+			view:       itemView{Url: "https://git.example.com:8443"},
+		},
+		{
+			name:       "item path without a path from git",
+			credential: https,
+			view:       itemView{Url: "https://git.example.com/org"},
+			score:      0,
+			ok:         true,
+		},
+		{
+			name:       "repository",
+			credential: withPath,
+			view:       itemView{Url: "https://git.example.com/org/repo"},
+			score:      1 + len("org/repo"),
+			ok:         true,
+		},
+		{
+			name:       "repository with the .git suffix",
+			credential: gitCredential{protocol: "https", host: "git.example.com", path: "org/repo"},
+			view:       itemView{Url: "https://git.example.com/org/repo.git/"},
+			score:      1 + len("org/repo"),
+			ok:         true,
+		},
+		{
+			name:       "path prefix",
+			credential: withPath,
+			view:       itemView{Url: "https://git.example.com/org"},
+			score:      1 + len("org"),
+			ok:         true,
+		},
+		{
+			name:       "prefix that is not a directory",
+			credential: withPath,
+			view:       itemView{Url: "https://git.example.com/or"},
+		},
+		{
+			name:       "sibling repository",
+			credential: withPath,
+			view:       itemView{Url: "https://git.example.com/org/repo2"},
+		},
+		{
+			name:       "item without a path",
+			credential: withPath,
+			view:       itemView{Url: "https://git.example.com"},
+			score:      1,
+			ok:         true,
+		},
+		{
+			name:       "username git asks for",
+			credential: gitCredential{protocol: "https", host: "git.example.com", username: "john"},
+			view:       itemView{Url: "https://git.example.com", Username: "john"},
+			score:      1,
+			ok:         true,
+		},
+		{
+			name:       "other username",
+			credential: gitCredential{protocol: "https", host: "git.example.com", username: "john"},
+			view:       itemView{Url: "https://git.example.com", Username: "jane"},
+		},
+		{
+			name:       "item without a url",
+			credential: https,
+			view:       itemView{Username: "john"},
+		},
+		{
+			name:       "locked item",
+			credential: https,
+			view:       itemView{Url: "https://git.example.com", Locked: true},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			score, ok := tc.credential.match(tc.view)
+			require.Equal(t, tc.ok, ok)
+			if tc.ok {
+				require.Equal(t, tc.score, score)
+			}
+		})
+	}
+}
+
+func TestBestGitMatch(t *testing.T) {
+	t.Parallel()
+
+	item := func(name, url string) vaultModel.APIVaultItem {
+		encrypted, err := encryptSecrets(testVaultKey, secrets{Username: name, Password: name + "-password", Url: url})
+		require.NoError(t, err)
+		return vaultModel.APIVaultItem{
+			Name:              name,
+			EncryptedUsername: encrypted.Username,
+			EncryptedPassword: encrypted.Password,
+			EncryptedUrl:      encrypted.Url,
+			Nonce:             encrypted.Nonce,
+		}
+	}
+
+	items := []vaultModel.APIVaultItem{
+		item("host", "https://git.example.com"),
+		item("org", "https://git.example.com/org"),
+		item("repo", "https://git.example.com/org/repo"),
+		item("other", "https://gitlab.example.com"),
+	}
+
+	testcases := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "no path", expected: "host"},
+		{name: "repository", path: "org/repo.git", expected: "repo"},
+		{name: "other repository of the org", path: "org/tools.git", expected: "org"},
+		{name: "repository of another org", path: "team/repo.git", expected: "host"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			credential := gitCredential{protocol: "https", host: "git.example.com", path: tc.path}
+			view, _, ok := bestGitMatch(credential, items, testVaultKey)
+			require.True(t, ok)
+			require.Equal(t, tc.expected, view.Username)
+		})
+	}
+
+	_, _, ok := bestGitMatch(gitCredential{protocol: "https", host: "bitbucket.example.com"}, items, testVaultKey)
+	require.False(t, ok)
+}
+
+func TestReadGitCredential(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		input    string
+		expected gitCredential
+		url      string
+		errText  string
+	}{
+		{
+			name:     "keys",
+			input:    "protocol=https\nhost=git.example.com:8443\npath=org/repo.git\nusername=john\npassword=secret\n\n",
+			expected: gitCredential{protocol: "https", host: "git.example.com:8443", path: "org/repo.git", username: "john", password: "secret"},
+			url:      "https://git.example.com:8443/org/repo",
+		},
+		{
+			name:     "url",
+			input:    "url=https://john@git.example.com/org/repo.git\n",
+			expected: gitCredential{protocol: "https", host: "git.example.com", path: "/org/repo.git", username: "john"},
+			url:      "https://git.example.com/org/repo",
+		},
+		{
+			name:     "without a path",
+			input:    "protocol=https\nhost=git.example.com\n",
+			expected: gitCredential{protocol: "https", host: "git.example.com"},
+			url:      "https://git.example.com",
+		},
+		{
+			name:     "stops at the blank line",
+			input:    "protocol=https\nhost=git.example.com\n\nhost=evil.example.com\n",
+			expected: gitCredential{protocol: "https", host: "git.example.com"},
+			url:      "https://git.example.com",
+		},
+		{
+			name:     "password with an equals sign",
+			input:    "protocol=https\nhost=git.example.com\npassword=a=b\n",
+			expected: gitCredential{protocol: "https", host: "git.example.com", password: "a=b"},
+			url:      "https://git.example.com",
+		},
+		{
+			name:    "line without a value",
+			input:   "protocol\n",
+			errText: "is not a key=value line",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			credential, err := readGitCredential(strings.NewReader(tc.input))
+			if tc.errText != "" {
+				require.ErrorContains(t, err, tc.errText)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, credential)
+			require.Equal(t, tc.url, credential.url())
+		})
+	}
+}
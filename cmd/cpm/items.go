@@ -0,0 +1,516 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	accountModel "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler/model"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	vaultModel "github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+)
+
+// searchPageSize is how many items or groups are fetched to find one by its name.
+const searchPageSize = 100
+
+func openSession() (session, *client, error) {
+	s, err := loadSession()
+	if err != nil {
+		return session{}, nil, err
+	}
+
+	return s, newClient(s.Server, s.Token), nil
+}
+
+func runList(args []string) error {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	search := flags.String("search", "", "only the items whose name or description contains this")
+	group := flags.String("group", "", "only the items shared with this group, its id or name")
+	page := flags.Int("page", 1, "page number")
+	pageSize := flags.Int("page-size", 50, "items per page")
+	output := outputFlag(flags)
+	_ = flags.Parse(args)
+
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+
+	s, c, err := openSession()
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set(localHttp.PageKeyParam, strconv.Itoa(*page))
+	query.Set(localHttp.PageSizeKeyParam, strconv.Itoa(*pageSize))
+	if *search != "" {
+		query.Set(localHttp.SearchKeyParam, *search)
+	}
+	if *group != "" {
+		groupID, err := resolveGroup(c, *group)
+		if err != nil {
+			return err
+		}
+		query.Set(localHttp.GroupKeyParam, strconv.FormatInt(int64(groupID), 10))
+	}
+
+	var list vaultModel.APIVaultItemList
+	if err := c.do(http.MethodGet, "items/", query, nil, &list); err != nil {
+		return err
+	}
+
+	views := make([]itemView, 0, len(list.Items))
+	for _, item := range list.Items {
+		views = append(views, newItemView(item, s.VaultKey))
+	}
+
+	if err := printItems(views, *output); err != nil {
+		return err
+	}
+
+	if *output == outputTable && list.Pagination.Total > len(views) {
+		fmt.Fprintf(os.Stderr, "page %d, %d items in total\n", list.Pagination.Page, list.Pagination.Total)
+	}
+	return nil
+}
+
+func runGet(args []string) error {
+	flags := flag.NewFlagSet("get", flag.ExitOnError)
+	show := flags.Bool("show", false, "show the password")
+	field := flags.String("field", "", "print only this field: username, password, url or note")
+	copyIt := flags.Bool("copy", false, "copy the password, or the -field, instead of printing it")
+	clearAfter := flags.Duration("clear", defaultClearAfter, "clear the clipboard after this long, 0 keeps it")
+	output := outputFlag(flags)
+	_ = flags.Parse(args)
+
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: cpm get [flags] <item>")
+	}
+
+	s, c, err := openSession()
+	if err != nil {
+		return err
+	}
+
+	item, err := resolveItem(c, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	view := newItemView(item, s.VaultKey)
+	if view.Locked && (*copyIt || *field != "" || *show) {
+		return errDecrypt
+	}
+
+	if *copyIt || *field != "" {
+		name := *field
+		if name == "" {
+			name = fieldNames[fieldPassword]
+		}
+
+		selected, ok := parseField(name)
+		if !ok {
+			return fmt.Errorf("unknown field %q", name)
+		}
+		value := view.field(selected)
+
+		if *copyIt {
+			return copyToClipboard(value, *clearAfter)
+		}
+		fmt.Println(value)
+		return nil
+	}
+
+	return printItem(view, *output, *show)
+}
+
+// itemFlags are the flags add and edit share, edit only changes what was set.
+type itemFlags struct {
+	name        *string
+	description *string
+	username    *string
+	url         *string
+	note        *string
+	groups      *string
+	generate    *bool
+	length      *int
+	noSymbols   *bool
+}
+
+func newItemFlags(flags *flag.FlagSet) itemFlags {
+	return itemFlags{
+		name:        flags.String("name", "", "name of the item"),
+		description: flags.String("description", "", "description of the item, it is not encrypted"),
+		username:    flags.String("username", "", "username of the item"),
+		url:         flags.String("url", "", "url of the item"),
+		note:        flags.String("note", "", "note of the item"),
+		groups:      flags.String("groups", "", "comma separated ids or names of the groups to share the item with"),
+		generate:    flags.Bool("generate", false, "generate the password instead of asking for it"),
+		length:      flags.Int("length", defaultPasswordLength, "length of a generated password"),
+		noSymbols:   flags.Bool("no-symbols", false, "only letters and digits in a generated password"),
+	}
+}
+
+func (f itemFlags) password() (string, error) {
+	if *f.generate {
+		return generatePassword(*f.length, !*f.noSymbols)
+	}
+
+	password, err := readSecret("Password: ")
+	if err != nil {
+		return "", err
+	}
+
+	repeated, err := readSecret("Repeat the password: ")
+	if err != nil {
+		return "", err
+	}
+
+	if password != repeated {
+		return "", errors.New("the passwords do not match")
+	}
+
+	return password, nil
+}
+
+func runAdd(args []string) error {
+	flags := flag.NewFlagSet("add", flag.ExitOnError)
+	fields := newItemFlags(flags)
+	sshKey := flags.String("ssh-key", "", "store the SSH private key of this file, its passphrase is the password")
+	output := outputFlag(flags)
+	_ = flags.Parse(args)
+
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if flags.NArg() == 1 && *fields.name == "" {
+		*fields.name = flags.Arg(0)
+	}
+	if *fields.name == "" || flags.NArg() > 1 {
+		return errors.New("usage: cpm add [flags] <name>")
+	}
+
+	s, c, err := openSession()
+	if err != nil {
+		return err
+	}
+
+	groupIDs, err := resolveGroups(c, *fields.groups)
+	if err != nil {
+		return err
+	}
+
+	var plain secrets
+	if *sshKey != "" {
+		if plain, err = readSSHKeyFile(*sshKey); err != nil {
+			return err
+		}
+		if *fields.username != "" {
+			plain.Username = *fields.username
+		}
+		plain.Url = *fields.url
+	} else {
+		password, err := fields.password()
+		if err != nil {
+			return err
+		}
+		plain = secrets{Username: *fields.username, Password: password, Url: *fields.url, Note: *fields.note}
+	}
+
+	encrypted, err := encryptSecrets(s.VaultKey, plain)
+	if err != nil {
+		return err
+	}
+
+	var created vaultModel.APIVaultItem
+	if err := c.do(http.MethodPost, "items/", nil, itemWrite(*fields.name, *fields.description, encrypted, groupIDs), &created); err != nil {
+		return err
+	}
+
+	return printItem(newItemView(created, s.VaultKey), *output, false)
+}
+
+func runEdit(args []string) error {
+	flags := flag.NewFlagSet("edit", flag.ExitOnError)
+	fields := newItemFlags(flags)
+	changePassword := flags.Bool("password", false, "ask for a new password")
+	output := outputFlag(flags)
+	_ = flags.Parse(args)
+
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: cpm edit [flags] <item>")
+	}
+
+	set := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	s, c, err := openSession()
+	if err != nil {
+		return err
+	}
+
+	item, err := resolveItem(c, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	plain, err := decryptSecrets(s.VaultKey, itemSecrets(item))
+	if err != nil {
+		return errors.New("only the creator of the item can edit it")
+	}
+
+	name, description, groupIDs := item.Name, item.Description, itemGroupIDs(item)
+	if set["name"] {
+		name = *fields.name
+	}
+	if set["description"] {
+		description = *fields.description
+	}
+	if set["username"] {
+		plain.Username = *fields.username
+	}
+	if set["url"] {
+		plain.Url = *fields.url
+	}
+	if set["note"] {
+		plain.Note = *fields.note
+	}
+	if set["groups"] {
+		if groupIDs, err = resolveGroups(c, *fields.groups); err != nil {
+			return err
+		}
+	}
+	if *changePassword || *fields.generate {
+		if plain.Password, err = fields.password(); err != nil {
+			return err
+		}
+	}
+
+	// a fresh nonce for every edit, the fields are encrypted again as a whole
+	encrypted, err := encryptSecrets(s.VaultKey, plain)
+	if err != nil {
+		return err
+	}
+
+	var updated vaultModel.APIVaultItem
+	path := fmt.Sprintf("items/%d/", item.ID)
+	if err := c.do(http.MethodPut, path, nil, itemWrite(name, description, encrypted, groupIDs), &updated); err != nil {
+		return err
+	}
+
+	return printItem(newItemView(updated, s.VaultKey), *output, false)
+}
+
+func runRemove(args []string) error {
+	flags := flag.NewFlagSet("rm", flag.ExitOnError)
+	force := flags.Bool("force", false, "do not ask for a confirmation")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: cpm rm [flags] <item>")
+	}
+
+	_, c, err := openSession()
+	if err != nil {
+		return err
+	}
+
+	item, err := resolveItem(c, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if !*force && !confirm(fmt.Sprintf("Delete %q?", item.Name)) {
+		return errors.New("nothing was deleted")
+	}
+
+	if err := c.do(http.MethodDelete, fmt.Sprintf("items/%d/", item.ID), nil, nil, nil); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Deleted %q.\n", item.Name)
+	return nil
+}
+
+// runShare shares an item with groups, or stops sharing it with -remove. The members
+// of the groups see the item, its secrets stay encrypted with the vault key of its
+// creator.
+func runShare(args []string) error {
+	flags := flag.NewFlagSet("share", flag.ExitOnError)
+	remove := flags.Bool("remove", false, "stop sharing the item with the groups")
+	output := outputFlag(flags)
+	_ = flags.Parse(args)
+
+	if err := checkOutput(*output); err != nil {
+		return err
+	}
+	if flags.NArg() < 2 {
+		return errors.New("usage: cpm share [flags] <item> <group>...")
+	}
+
+	s, c, err := openSession()
+	if err != nil {
+		return err
+	}
+
+	item, err := resolveItem(c, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	groupIDs := itemGroupIDs(item)
+	for _, arg := range flags.Args()[1:] {
+		groupID, err := resolveGroup(c, arg)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case *remove:
+			groupIDs = slices.DeleteFunc(groupIDs, func(id types.ID) bool { return id == groupID })
+		case !slices.Contains(groupIDs, groupID):
+			groupIDs = append(groupIDs, groupID)
+		}
+	}
+
+	var updated vaultModel.APIVaultItem
+	path := fmt.Sprintf("items/%d/", item.ID)
+	if err := c.do(http.MethodPut, path, nil, itemWrite(item.Name, item.Description, itemSecrets(item), groupIDs), &updated); err != nil {
+		return err
+	}
+
+	return printItem(newItemView(updated, s.VaultKey), *output, false)
+}
+
+// resolveItem finds an item by its id or by its exact name.
+func resolveItem(c *client, arg string) (vaultModel.APIVaultItem, error) {
+	var item vaultModel.APIVaultItem
+	if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return item, c.do(http.MethodGet, fmt.Sprintf("items/%d/", id), nil, nil, &item)
+	}
+
+	query := url.Values{}
+	query.Set(localHttp.SearchKeyParam, arg)
+	query.Set(localHttp.PageSizeKeyParam, strconv.Itoa(searchPageSize))
+
+	var list vaultModel.APIVaultItemList
+	if err := c.do(http.MethodGet, "items/", query, nil, &list); err != nil {
+		return item, err
+	}
+
+	matches := slices.DeleteFunc(list.Items, func(i vaultModel.APIVaultItem) bool { return i.Name != arg })
+	switch len(matches) {
+	case 0:
+		return item, fmt.Errorf("no item is named %q", arg)
+	case 1:
+		return matches[0], nil
+	default:
+		return item, fmt.Errorf("%d items are named %q, use the id of the item", len(matches), arg)
+	}
+}
+
+// readAllItems pages through every item the account can see.
+func readAllItems(c *client) ([]vaultModel.APIVaultItem, error) {
+	var items []vaultModel.APIVaultItem
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set(localHttp.PageKeyParam, strconv.Itoa(page))
+		query.Set(localHttp.PageSizeKeyParam, strconv.Itoa(searchPageSize))
+
+		var list vaultModel.APIVaultItemList
+		if err := c.do(http.MethodGet, "items/", query, nil, &list); err != nil {
+			return nil, err
+		}
+
+		items = append(items, list.Items...)
+		if len(list.Items) == 0 || len(items) >= list.Pagination.Total {
+			return items, nil
+		}
+	}
+}
+
+// resolveGroup finds a group of the account by its id or by its exact name.
+func resolveGroup(c *client, arg string) (types.ID, error) {
+	if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return types.ID(id), nil
+	}
+
+	query := url.Values{}
+	query.Set(localHttp.SearchKeyParam, arg)
+	query.Set(localHttp.PageSizeKeyParam, strconv.Itoa(searchPageSize))
+
+	var list accountModel.APIGroupList
+	if err := c.do(http.MethodGet, "groups/", query, nil, &list); err != nil {
+		return 0, err
+	}
+
+	for _, group := range list.Groups {
+		if group.Name == arg {
+			return group.ID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("you are not a member of a group named %q", arg)
+}
+
+func resolveGroups(c *client, arg string) ([]types.ID, error) {
+	var ids []types.ID
+	for name := range strings.SplitSeq(arg, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+
+		id, err := resolveGroup(c, name)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func itemSecrets(item vaultModel.APIVaultItem) encryptedSecrets {
+	return encryptedSecrets{
+		Username: item.EncryptedUsername,
+		Password: item.EncryptedPassword,
+		Url:      item.EncryptedUrl,
+		Note:     item.EncryptedNote,
+		Nonce:    item.Nonce,
+	}
+}
+
+func itemGroupIDs(item vaultModel.APIVaultItem) []types.ID {
+	ids := make([]types.ID, 0, len(item.Groups))
+	for _, group := range item.Groups {
+		ids = append(ids, group.ID)
+	}
+
+	return ids
+}
+
+func itemWrite(name, description string, encrypted encryptedSecrets, groupIDs []types.ID) vaultModel.APIVaultItemWrite {
+	return vaultModel.APIVaultItemWrite{
+		Name:              name,
+		Description:       description,
+		EncryptedUsername: encrypted.Username,
+		EncryptedPassword: encrypted.Password,
+		EncryptedUrl:      encrypted.Url,
+		EncryptedNote:     encrypted.Note,
+		Nonce:             encrypted.Nonce,
+		GroupIDs:          groupIDs,
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	accountModel "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/oprfutils"
+	"github.com/bytemare/opaque"
+)
+
+const defaultServer = "http://localhost:8080"
+
+// runLogin logs in with OPAQUE and the authenticator, then caches the session. The
+// export line of the session key is the only thing written to stdout, so the login can
+// be run as eval "$(cpm login)".
+func runLogin(args []string) error {
+	flags := flag.NewFlagSet("login", flag.ExitOnError)
+	server := flags.String("server", envOr("CPM_SERVER", defaultServer), "address of the server")
+	serverID := flags.String("server-id", envOr("CPM_SERVER_ID", ""), "OPAQUE identity of the server, the one it serves by default")
+	username := flags.String("username", "", "username of the account")
+	days := flags.Int("days", 7, "days until the session expires, from 1 to 365")
+	_ = flags.Parse(args)
+
+	var err error
+	if *username == "" {
+		if *username, err = readLine("Username: "); err != nil {
+			return err
+		}
+	}
+
+	password, err := readSecret("Master password: ")
+	if err != nil {
+		return err
+	}
+
+	c := newClient(*server, "")
+	setup, err := fetchOpaqueSetup(c, *serverID)
+	if err != nil {
+		return err
+	}
+
+	exportKey, twoFactor, err := opaqueLogin(c, setup, *username, []byte(password))
+	if err != nil {
+		return err
+	}
+
+	code, err := readLine("Authenticator code: ")
+	if err != nil {
+		return err
+	}
+
+	var login accountModel.APILogin
+	err = c.do(http.MethodPost, "auth/two-factor/", nil, accountModel.APITwoFactor{
+		TwoFactorID:      twoFactor,
+		VerificationCode: strings.TrimSpace(code),
+		TokenName:        tokenName(),
+		ExpiryDays:       *days,
+	}, &login)
+	if err != nil {
+		return err
+	}
+
+	key, err := finishLogin(*server, setup, *username, []byte(password), exportKey, login)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Logged in as %s until %s.\n", *username, login.AccessToken.ExpiresAt.Format(time.DateTime))
+	fmt.Printf("export %s=%s\n", sessionEnv, key)
+	return nil
+}
+
+// finishLogin unwraps the vault key, registers the password again when the server asks
+// for it and caches the session, returning its key. A failed rekey revokes the token
+// instead, so no session is left behind and the next login asks for the rekey again.
+func finishLogin(server string, setup opaqueSetup, username string, password, exportKey []byte,
+	login accountModel.APILogin) (string, error) {
+	if login.AccessToken.ExpiresAt == nil {
+		return "", errors.New("the server issued an access token that never expires")
+	}
+
+	vaultKey, err := unwrapVaultKey(exportKey, login.EncryptedVaultKey)
+	if err != nil {
+		return "", errors.New("the vault key can not be unwrapped, the account may have no vault key yet")
+	}
+
+	if login.Rekey {
+		c := newClient(server, login.Token)
+		if err := rekey(c, setup, username, password, vaultKey); err != nil {
+			if err := c.do(http.MethodDelete, "token/", nil, nil, nil); err != nil {
+				fmt.Fprintf(os.Stderr, "the access token could not be revoked: %v\n", err)
+			}
+			return "", fmt.Errorf("the password could not be registered under the new server keys, log in again: %w", err)
+		}
+	}
+
+	return saveSession(session{
+		Server:    server,
+		Username:  username,
+		Token:     login.Token,
+		ExpiresAt: *login.AccessToken.ExpiresAt,
+		VaultKey:  vaultKey,
+	})
+}
+
+// opaqueLogin proves the password to the server and returns the export key of the
+// login along with the id of its two factor.
+func opaqueLogin(c *client, setup opaqueSetup, username string, password []byte) ([]byte, string, error) {
+	opaqueClient, err := setup.suite.Client()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var challenge accountModel.APILoginChallenge
+	err = c.do(http.MethodPost, "auth/login/init/", nil, accountModel.APILoginInit{
+		Username: username,
+		KE1:      opaqueClient.LoginInit(password).Serialize(),
+	}, &challenge)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ke2, err := opaqueClient.Deserialize.KE2(challenge.KE2)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ke3, exportKey, err := opaqueClient.LoginFinish(ke2, opaque.ClientLoginFinishOptions{
+		ClientIdentity: []byte(username),
+		ServerIdentity: setup.serverID,
+	})
+	if err != nil {
+		return nil, "", errors.New("invalid username or password")
+	}
+
+	var twoFactor accountModel.APITwoFactorChallenge
+	err = c.do(http.MethodPost, "auth/login/", nil, accountModel.APILoginFinalize{
+		LoginID: challenge.LoginID,
+		KE3:     ke3.Serialize(),
+	}, &twoFactor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return exportKey, twoFactor.TwoFactorID, nil
+}
+
+// rekey registers the same password again once the server asks for it, since its keys
+// changed after the last registration. The vault key is wrapped under the new export key.
+func rekey(c *client, setup opaqueSetup, username string, password, vaultKey []byte) error {
+	loginClient, err := setup.suite.Client()
+	if err != nil {
+		return err
+	}
+
+	registrationClient, err := setup.suite.Client()
+	if err != nil {
+		return err
+	}
+
+	var challenge accountModel.APIRekeyChallenge
+	err = c.do(http.MethodPost, "password/rekey/init/", nil, accountModel.APIRekeyInit{
+		KE1: loginClient.LoginInit(password).Serialize(),
+	}, &challenge)
+	if err != nil {
+		return err
+	}
+
+	ke2, err := loginClient.Deserialize.KE2(challenge.KE2)
+	if err != nil {
+		return err
+	}
+
+	ke3, _, err := loginClient.LoginFinish(ke2, opaque.ClientLoginFinishOptions{
+		ClientIdentity: []byte(username),
+		ServerIdentity: setup.serverID,
+	})
+	if err != nil {
+		return err
+	}
+
+	var registration accountModel.APIRekeyRegistration
+	err = c.do(http.MethodPost, "password/rekey/verify/", nil, accountModel.APIRekeyVerify{
+		ChangeID:            challenge.ChangeID,
+		KE3:                 ke3.Serialize(),
+		RegistrationRequest: registrationClient.RegistrationInit(password).Serialize(),
+	}, &registration)
+	if err != nil {
+		return err
+	}
+
+	response, err := registrationClient.Deserialize.RegistrationResponse(registration.RegistrationResponse)
+	if err != nil {
+		return err
+	}
+
+	record, exportKey := registrationClient.RegistrationFinalize(response, opaque.ClientRegistrationFinalizeOptions{
+		ClientIdentity: []byte(username),
+		ServerIdentity: setup.serverID,
+	})
+
+	encryptedVaultKey, err := wrapVaultKey(exportKey, vaultKey)
+	if err != nil {
+		return err
+	}
+
+	return c.do(http.MethodPost, "password/rekey/", nil, accountModel.APIRekeyFinalize{
+		ChangeID:           challenge.ChangeID,
+		RegistrationRecord: record.Serialize(),
+		EncryptedVaultKey:  encryptedVaultKey,
+	}, nil)
+}
+
+// runLogout revokes the access token of the session and forgets the session.
+func runLogout(args []string) error {
+	flags := flag.NewFlagSet("logout", flag.ExitOnError)
+	_ = flags.Parse(args)
+
+	s, err := loadSession()
+	if err == nil {
+		if err := newClient(s.Server, s.Token).do(http.MethodDelete, "token/", nil, nil, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "the access token could not be revoked: %v\n", err)
+		}
+	}
+
+	if err := removeSession(); err != nil {
+		return err
+	}
+
+	fmt.Printf("unset %s\n", sessionEnv)
+	return nil
+}
+
+// opaqueSetup is how the server runs OPAQUE, the client has to run the same suite
+// and key stretching function or the records would not log in from the browser.
+type opaqueSetup struct {
+	suite    oprfutils.Suite
+	serverID []byte
+}
+
+// fetchOpaqueSetup reads the setup the server serves, serverID replaces the identity
+// of the server when it is set.
+func fetchOpaqueSetup(c *client, serverID string) (opaqueSetup, error) {
+	var conf accountModel.APIOpaqueConfig
+	if err := c.do(http.MethodGet, "auth/opaque/", nil, nil, &conf); err != nil {
+		return opaqueSetup{}, err
+	}
+
+	if serverID == "" {
+		serverID = conf.ServerID
+	}
+
+	suite := oprfutils.Suite{Name: conf.Suite, KSF: conf.KSF}
+	if err := suite.Validate(); err != nil {
+		return opaqueSetup{}, fmt.Errorf("the server runs an opaque suite cpm does not: %w", err)
+	}
+
+	return opaqueSetup{suite: suite, serverID: []byte(serverID)}, nil
+}
+
+// tokenName tells the sessions apart on the access tokens page of the account.
+func tokenName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown host"
+	}
+
+	name := fmt.Sprintf("cpm %s %s", time.Now().Format(time.DateTime), host)
+	if len(name) > 50 {
+		name = name[:50]
+	}
+
+	return name
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	return fallback
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	accountModel "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler/model"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/oprfutils"
+	"github.com/bytemare/opaque"
+	"github.com/stretchr/testify/require"
+)
+
+// testSuite skips the key stretching, the tests do not need it to be slow.
+var testSuite = oprfutils.Suite{Name: oprfutils.SuiteP256, KSF: oprfutils.KSF{Name: oprfutils.KSFIdentity}}
+
+// opaqueServer answers the login and rekey steps of the API the way the server does,
+// the credential identifier of a record is its username.
+type opaqueServer struct {
+	t *testing.T
+
+	mu                sync.Mutex
+	conf              *opaque.Configuration
+	secretKey         []byte
+	publicKey         []byte
+	oprfSeed          []byte
+	records           map[string]*opaque.ClientRecord
+	state             []byte
+	encryptedVaultKey []byte
+	failRekey         bool
+	revoked           bool
+}
+
+func newOpaqueServer(t *testing.T) (*opaqueServer, *httptest.Server) {
+	conf, err := testSuite.Configuration()
+	require.NoError(t, err)
+
+	s := &opaqueServer{t: t, conf: conf, oprfSeed: conf.GenerateOPRFSeed(), records: map[string]*opaque.ClientRecord{}}
+	s.secretKey, s.publicKey = conf.KeyGen()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+localHttp.PathAPI+"auth/opaque/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, accountModel.APIOpaqueConfig{ServerID: "cpm test", Suite: testSuite.Name, KSF: testSuite.KSF})
+	})
+	mux.HandleFunc("POST "+localHttp.PathAPI+"auth/login/init/", s.loginInit)
+	mux.HandleFunc("POST "+localHttp.PathAPI+"auth/login/", s.loginFinalize)
+	mux.HandleFunc("POST "+localHttp.PathAPI+"password/rekey/init/", s.rekeyInit)
+	mux.HandleFunc("POST "+localHttp.PathAPI+"password/rekey/verify/", s.rekeyVerify)
+	mux.HandleFunc("POST "+localHttp.PathAPI+"password/rekey/", s.rekeyFinalize)
+	mux.HandleFunc("DELETE "+localHttp.PathAPI+"token/", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.revoked = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+func (s *opaqueServer) server() *opaque.Server {
+	server, err := s.conf.Server()
+	require.NoError(s.t, err)
+	require.NoError(s.t, server.SetKeyMaterial([]byte("cpm test"), s.secretKey, s.publicKey, s.oprfSeed))
+	return server
+}
+
+// register registers the password of the username and returns the export key.
+func (s *opaqueServer) register(username string, password []byte) []byte {
+	client, err := testSuite.Client()
+	require.NoError(s.t, err)
+
+	server := s.server()
+	pks, err := server.Deserialize.DecodeAkePublicKey(s.publicKey)
+	require.NoError(s.t, err)
+
+	response := server.RegistrationResponse(client.RegistrationInit(password), pks, []byte(username), s.oprfSeed)
+	record, exportKey := client.RegistrationFinalize(response, opaque.ClientRegistrationFinalizeOptions{
+		ClientIdentity: []byte(username),
+		ServerIdentity: []byte("cpm test"),
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[username] = &opaque.ClientRecord{
+		CredentialIdentifier: []byte(username),
+		ClientIdentity:       []byte(username),
+		RegistrationRecord:   record,
+	}
+
+	return exportKey
+}
+
+func (s *opaqueServer) ke2(w http.ResponseWriter, username string, message []byte) ([]byte, bool) {
+	record, ok := s.records[username]
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "invalid username or password")
+		return nil, false
+	}
+
+	server := s.server()
+	ke1, err := server.Deserialize.KE1(message)
+	require.NoError(s.t, err)
+	ke2, err := server.LoginInit(ke1, record)
+	require.NoError(s.t, err)
+
+	s.state = server.SerializeState()
+	return ke2.Serialize(), true
+}
+
+func (s *opaqueServer) checkKE3(w http.ResponseWriter, message []byte) bool {
+	server := s.server()
+	require.NoError(s.t, server.SetAKEState(s.state))
+
+	ke3, err := server.Deserialize.KE3(message)
+	if err != nil || server.LoginFinish(ke3) != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid password")
+		return false
+	}
+
+	return true
+}
+
+func (s *opaqueServer) loginInit(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var body accountModel.APILoginInit
+	require.NoError(s.t, json.NewDecoder(r.Body).Decode(&body))
+
+	if ke2, ok := s.ke2(w, body.Username, body.KE1); ok {
+		writeJSON(w, http.StatusOK, accountModel.APILoginChallenge{KE2: ke2, LoginID: body.Username})
+	}
+}
+
+func (s *opaqueServer) loginFinalize(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var body accountModel.APILoginFinalize
+	require.NoError(s.t, json.NewDecoder(r.Body).Decode(&body))
+
+	if s.checkKE3(w, body.KE3) {
+		writeJSON(w, http.StatusOK, accountModel.APITwoFactorChallenge{TwoFactorID: "two factor of " + body.LoginID})
+	}
+}
+
+func (s *opaqueServer) rekeyInit(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var body accountModel.APIRekeyInit
+	require.NoError(s.t, json.NewDecoder(r.Body).Decode(&body))
+
+	// the token of the tests is the username
+	username := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if ke2, ok := s.ke2(w, username, body.KE1); ok {
+		writeJSON(w, http.StatusOK, accountModel.APIRekeyChallenge{KE2: ke2, ChangeID: username})
+	}
+}
+
+func (s *opaqueServer) rekeyVerify(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var body accountModel.APIRekeyVerify
+	require.NoError(s.t, json.NewDecoder(r.Body).Decode(&body))
+
+	if !s.checkKE3(w, body.KE3) {
+		return
+	}
+
+	server := s.server()
+	request, err := server.Deserialize.RegistrationRequest(body.RegistrationRequest)
+	require.NoError(s.t, err)
+	pks, err := server.Deserialize.DecodeAkePublicKey(s.publicKey)
+	require.NoError(s.t, err)
+
+	response := server.RegistrationResponse(request, pks, []byte(body.ChangeID), s.oprfSeed)
+	writeJSON(w, http.StatusOK, accountModel.APIRekeyRegistration{RegistrationResponse: response.Serialize()})
+}
+
+func (s *opaqueServer) rekeyFinalize(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failRekey {
+		writeError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	var body accountModel.APIRekeyFinalize
+	require.NoError(s.t, json.NewDecoder(r.Body).Decode(&body))
+
+	record, err := s.server().Deserialize.RegistrationRecord(body.RegistrationRecord)
+	require.NoError(s.t, err)
+
+	s.records[body.ChangeID] = &opaque.ClientRecord{
+		CredentialIdentifier: []byte(body.ChangeID),
+		ClientIdentity:       []byte(body.ChangeID),
+		RegistrationRecord:   record,
+	}
+	s.encryptedVaultKey = body.EncryptedVaultKey
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, localHttp.APIErrorResponse{Error: localHttp.APIError{Code: status * 1000, Message: message}})
+}
+
+func TestOpaqueLogin(t *testing.T) {
+	t.Parallel()
+	s, server := newOpaqueServer(t)
+	exportKey := s.register("john", []byte("password"))
+
+	setup, err := fetchOpaqueSetup(newClient(server.URL, ""), "")
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name     string
+		username string
+		password string
+		status   int
+		errText  string
+	}{
+		{
+			name:     "right password",
+			username: "john",
+			password: "password",
+		},
+		{
+			name:     "wrong password",
+			username: "john",
+			password: "wrong",
+			errText:  "invalid username or password",
+		},
+		{
+			name:     "unknown username",
+			username: "nobody",
+			password: "password",
+			status:   http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, twoFactor, err := opaqueLogin(newClient(server.URL, ""), setup, tc.username, []byte(tc.password))
+
+			switch {
+			case tc.status != 0:
+				var answer *apiError
+				require.ErrorAs(t, err, &answer)
+				require.Equal(t, tc.status, answer.Status)
+			case tc.errText != "":
+				require.EqualError(t, err, tc.errText)
+			default:
+				require.NoError(t, err)
+				require.Equal(t, exportKey, key)
+				require.Equal(t, "two factor of john", twoFactor)
+			}
+		})
+	}
+}
+
+func TestRekey(t *testing.T) {
+	t.Parallel()
+	s, server := newOpaqueServer(t)
+	s.register("john", []byte("password"))
+
+	setup, err := fetchOpaqueSetup(newClient(server.URL, ""), "")
+	require.NoError(t, err)
+
+	vaultKey := []byte("0123456789abcdef0123456789abcdef")
+
+	// a wrong password is refused before anything is registered
+	err = rekey(newClient(server.URL, "john"), setup, "john", []byte("wrong"), vaultKey)
+	require.Error(t, err)
+	require.Nil(t, s.encryptedVaultKey)
+
+	require.NoError(t, rekey(newClient(server.URL, "john"), setup, "john", []byte("password"), vaultKey))
+
+	// the new record logs in and its export key unwraps the vault key
+	exportKey, _, err := opaqueLogin(newClient(server.URL, ""), setup, "john", []byte("password"))
+	require.NoError(t, err)
+	unwrapped, err := unwrapVaultKey(exportKey, s.encryptedVaultKey)
+	require.NoError(t, err)
+	require.Equal(t, vaultKey, unwrapped)
+}
+
+func TestFinishLogin(t *testing.T) {
+	vaultKey := []byte("0123456789abcdef0123456789abcdef")
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	testcases := []struct {
+		name      string
+		rekey     bool
+		failRekey bool
+		noExpiry  bool
+		ok        bool
+	}{
+		{
+			name: "login",
+			ok:   true,
+		},
+		{
+			name:  "login with rekey",
+			rekey: true,
+			ok:    true,
+		},
+		{
+			name:      "failed rekey",
+			rekey:     true,
+			failRekey: true,
+		},
+		{
+			name:     "token that never expires",
+			noExpiry: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("CPM_SESSION_FILE", t.TempDir()+"/session")
+
+			s, server := newOpaqueServer(t)
+			exportKey := s.register("john", []byte("password"))
+			s.failRekey = tc.failRekey
+
+			setup, err := fetchOpaqueSetup(newClient(server.URL, ""), "")
+			require.NoError(t, err)
+
+			wrapped, err := wrapVaultKey(exportKey, vaultKey)
+			require.NoError(t, err)
+
+			login := accountModel.APILogin{Token: "john", EncryptedVaultKey: wrapped, Rekey: tc.rekey}
+			if !tc.noExpiry {
+				login.AccessToken.ExpiresAt = &expiresAt
+			}
+
+			key, err := finishLogin(server.URL, setup, "john", []byte("password"), exportKey, login)
+			if !tc.ok {
+				require.Error(t, err)
+				require.Equal(t, tc.failRekey, s.revoked)
+
+				// no session is left behind
+				path, err := sessionPath()
+				require.NoError(t, err)
+				_, err = os.Stat(path)
+				require.True(t, errors.Is(err, os.ErrNotExist))
+				return
+			}
+
+			require.NoError(t, err)
+			require.False(t, s.revoked)
+			require.Equal(t, tc.rekey, s.encryptedVaultKey != nil)
+
+			t.Setenv(sessionEnv, key)
+			cached, err := loadSession()
+			require.NoError(t, err)
+			require.Equal(t, vaultKey, cached.VaultKey)
+			require.Equal(t, "john", cached.Token)
+		})
+	}
+}
+
+func TestFetchOpaqueSetup(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		config   accountModel.APIOpaqueConfig
+		serverID string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "served identity",
+			config:   accountModel.APIOpaqueConfig{ServerID: "vault", Suite: testSuite.Name, KSF: testSuite.KSF},
+			expected: "vault",
+			ok:       true,
+		},
+		{
+			name:     "identity of the flag",
+			config:   accountModel.APIOpaqueConfig{ServerID: "vault", Suite: testSuite.Name, KSF: testSuite.KSF},
+			serverID: "vault.example.com",
+			expected: "vault.example.com",
+			ok:       true,
+		},
+		{
+			name:   "unknown suite",
+			config: accountModel.APIOpaqueConfig{ServerID: "vault", Suite: "ristretto255", KSF: testSuite.KSF},
+		},
+		{
+			name:   "unknown key stretching function",
+			config: accountModel.APIOpaqueConfig{ServerID: "vault", Suite: testSuite.Name, KSF: oprfutils.KSF{Name: "bcrypt"}},
+		},
+		{
+			name: "argon2id without parameters",
+			config: accountModel.APIOpaqueConfig{
+				ServerID: "vault", Suite: testSuite.Name, KSF: oprfutils.KSF{Name: oprfutils.KSFArgon2id},
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, localHttp.PathAPI+"auth/opaque/", r.URL.Path)
+				writeJSON(w, http.StatusOK, tc.config)
+			}))
+			defer server.Close()
+
+			setup, err := fetchOpaqueSetup(newClient(server.URL, ""), tc.serverID)
+			if !tc.ok {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, []byte(tc.expected), setup.serverID)
+			require.Equal(t, tc.config.Suite, setup.suite.Name)
+		})
+	}
+}
+
+func TestTokenName(t *testing.T) {
+	t.Parallel()
+
+	name := tokenName()
+	require.True(t, strings.HasPrefix(name, "cpm "))
+	require.LessOrEqual(t, len(name), 50)
+	require.Contains(t, name, time.Now().Format(time.DateOnly))
+}
@@ -0,0 +1,92 @@
+// Command cpm is the command-line client of the vault. It logs in with OPAQUE and the
+// authenticator like the browser does, and encrypts and decrypts the items locally, the
+// server only ever sees them encrypted.
+//
+// A login caches the session encrypted on disk, the key of the session is printed as a
+// CPM_SESSION export:
+//
+//	eval "$(cpm login -server https://vault.example.com)"
+//	cpm list
+//	cpm get -copy mail
+//	cpm run -item db-prod -- ./migrate
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+)
+
+type command struct {
+	run     func(args []string) error
+	summary string
+}
+
+var commands = map[string]command{
+	"login":          {runLogin, "log in and cache the session"},
+	"logout":         {runLogout, "revoke the session and forget it"},
+	"list":           {runList, "list the items"},
+	"get":            {runGet, "show an item, or copy its password"},
+	"add":            {runAdd, "add an item"},
+	"edit":           {runEdit, "change an item"},
+	"rm":             {runRemove, "delete an item"},
+	"generate":       {runGenerate, "generate a password"},
+	"share":          {runShare, "share an item with groups"},
+	"run":            {runRun, "run a command with secrets in its environment"},
+	"inject":         {runInject, "fill the references of a template"},
+	"git-credential": {runGitCredential, "serve git the credentials of the vault"},
+	"ssh-agent":      {runSSHAgent, "serve the SSH keys of the vault"},
+}
+
+var commandOrder = []string{"login", "logout", "list", "get", "add", "edit", "rm", "generate", "share", "run", "inject", "git-credential", "ssh-agent"}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name, args := os.Args[1], os.Args[2:]
+	if name == clearClipboardCommand {
+		if err := runClearClipboard(args); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(args); err != nil {
+		// cpm run exits the way the command it ran did
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			os.Exit(max(exit.ExitCode(), 1))
+		}
+
+		fmt.Fprintf(os.Stderr, "cpm %s: %v\n", name, err)
+
+		// the access token of the session expired or was revoked
+		var answer *apiError
+		if errors.As(err, &answer) && answer.Code == account.CodeAccessTokenInvalid {
+			fmt.Fprintln(os.Stderr, `log in again with: eval "$(cpm login)"`)
+		}
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cpm <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, name := range commandOrder {
+		fmt.Fprintf(os.Stderr, "  %-15s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "run cpm <command> -h for the flags of a command")
+}
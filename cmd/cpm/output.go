@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	vaultModel "github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+func outputFlag(flags *flag.FlagSet) *string {
+	output := flags.String("output", envOr("CPM_OUTPUT", outputTable), "output format, table or json")
+	flags.StringVar(output, "o", *output, "shorthand for -output")
+	return output
+}
+
+func checkOutput(output string) error {
+	if output != outputTable && output != outputJSON {
+		return fmt.Errorf("unknown output %q, use table or json", output)
+	}
+
+	return nil
+}
+
+// itemView is an item with its secrets decrypted. Locked items were encrypted with the
+// vault key of another account, only their name and description can be shown.
+type itemView struct {
+	ID          types.ID  `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Username    string    `json:"username,omitempty"`
+	Password    string    `json:"password,omitempty"`
+	Url         string    `json:"url,omitempty"`
+	Note        string    `json:"note,omitempty"`
+	Creator     string    `json:"creator"`
+	Groups      []string  `json:"groups"`
+	Locked      bool      `json:"locked,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func newItemView(item vaultModel.APIVaultItem, vaultKey []byte) itemView {
+	groups := make([]string, 0, len(item.Groups))
+	for _, group := range item.Groups {
+		groups = append(groups, group.Name)
+	}
+
+	view := itemView{
+		ID:          item.ID,
+		Name:        item.Name,
+		Description: item.Description,
+		Creator:     item.Creator.Username,
+		Groups:      groups,
+		UpdatedAt:   item.UpdatedAt,
+	}
+
+	plain, err := decryptSecrets(vaultKey, itemSecrets(item))
+	if err != nil {
+		view.Locked = true
+		return view
+	}
+
+	view.Username, view.Password, view.Url, view.Note = plain.Username, plain.Password, plain.Url, plain.Note
+	return view
+}
+
+func (v itemView) field(field itemField) string {
+	switch field {
+	case fieldUsername:
+		return v.Username
+	case fieldPassword:
+		return v.Password
+	case fieldUrl:
+		return v.Url
+	default:
+		return v.Note
+	}
+}
+
+func printItems(items []itemView, output string) error {
+	if output == outputJSON {
+		return printJSON(items)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tUSERNAME\tURL\tGROUPS\tUPDATED")
+	for _, item := range items {
+		username := item.Username
+		if item.Locked {
+			username = "(locked)"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			item.ID, item.Name, username, item.Url, strings.Join(item.Groups, ", "), item.UpdatedAt.Local().Format(time.DateTime))
+	}
+
+	return w.Flush()
+}
+
+// printItem leaves the password, and the private key of an SSH key item, out unless
+// showPassword is set.
+func printItem(item itemView, output string, showPassword bool) error {
+	if !showPassword {
+		item.Password = ""
+		if strings.Contains(item.Note, privateKeyMarker) {
+			item.Note = "(SSH private key)"
+		}
+	}
+
+	if output == outputJSON {
+		return printJSON(item)
+	}
+
+	password := "********"
+	if showPassword {
+		password = item.Password
+	}
+	if item.Locked {
+		password = "(locked)"
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	rows := [][2]string{
+		{"ID", fmt.Sprint(item.ID)},
+		{"Name", item.Name},
+		{"Description", item.Description},
+		{"Username", item.Username},
+		{"Password", password},
+		{"URL", item.Url},
+		{"Note", item.Note},
+		{"Creator", item.Creator},
+		{"Groups", strings.Join(item.Groups, ", ")},
+		{"Updated", item.UpdatedAt.Local().Format(time.DateTime)},
+	}
+	for _, row := range rows {
+		if row[1] != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", row[0], row[1])
+		}
+	}
+
+	return w.Flush()
+}
+
+func printJSON(value any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Prompts go to stderr, so the output of a command can still be captured or piped.
+var stdin = bufio.NewReader(os.Stdin)
+
+func readLine(label string) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readSecret reads a line without echoing it when stdin is a terminal.
+func readSecret(label string) (string, error) {
+	if stty("-echo") == nil {
+		defer func() {
+			_ = stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+
+	return readLine(label)
+}
+
+func stty(args ...string) error {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
+
+func confirm(label string) bool {
+	answer, err := readLine(label + " [y/N] ")
+	if err != nil {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
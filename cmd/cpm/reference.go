@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	vaultModel "github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/delivery/http/handler/model"
+)
+
+// referenceScheme starts a reference to a field of an item, cpm://group/item/field. The
+// group is the id or name of a group the item is shared with, anyGroup finds the item
+// whether it is shared or not. A name with a slash in it is written percent-encoded.
+const (
+	referenceScheme = "cpm://"
+	anyGroup        = "_"
+)
+
+// templateReference is a reference in a template, {{ cpm://group/item/field }}.
+var templateReference = regexp.MustCompile(`\{\{\s*(cpm://[^\s}]+)\s*\}\}`)
+
+type reference struct {
+	group string
+	item  string
+	field itemField
+}
+
+func parseReference(value string) (reference, error) {
+	rest, ok := strings.CutPrefix(value, referenceScheme)
+	if !ok {
+		return reference{}, fmt.Errorf("%q is not a reference, write it as cpm://group/item/field", value)
+	}
+
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 {
+		return reference{}, fmt.Errorf("%q is not a reference, write it as cpm://group/item/field", value)
+	}
+
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil || unescaped == "" {
+			return reference{}, fmt.Errorf("%q is not a reference, write it as cpm://group/item/field", value)
+		}
+		parts[i] = unescaped
+	}
+
+	field, ok := parseField(parts[2])
+	if !ok {
+		return reference{}, fmt.Errorf("unknown field %q in %q, use username, password, url or note", parts[2], value)
+	}
+
+	return reference{group: parts[0], item: parts[1], field: field}, nil
+}
+
+// resolver reads each referenced item once, however many of its fields are referenced.
+type resolver struct {
+	client   *client
+	vaultKey []byte
+	items    map[[2]string]itemView
+}
+
+func newResolver(s session, c *client) *resolver {
+	return &resolver{client: c, vaultKey: s.VaultKey, items: map[[2]string]itemView{}}
+}
+
+func (r *resolver) resolve(value string) (string, error) {
+	ref, err := parseReference(value)
+	if err != nil {
+		return "", err
+	}
+
+	view, err := r.item(ref.group, ref.item)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", value, err)
+	}
+
+	return view.field(ref.field), nil
+}
+
+func (r *resolver) item(group, name string) (itemView, error) {
+	key := [2]string{group, name}
+	if view, ok := r.items[key]; ok {
+		return view, nil
+	}
+
+	query := url.Values{}
+	query.Set(localHttp.SearchKeyParam, name)
+	query.Set(localHttp.PageSizeKeyParam, strconv.Itoa(searchPageSize))
+	if group != anyGroup {
+		groupID, err := resolveGroup(r.client, group)
+		if err != nil {
+			return itemView{}, err
+		}
+		query.Set(localHttp.GroupKeyParam, strconv.FormatInt(int64(groupID), 10))
+	}
+
+	var list vaultModel.APIVaultItemList
+	if err := r.client.do(http.MethodGet, "items/", query, nil, &list); err != nil {
+		return itemView{}, err
+	}
+
+	matches := slices.DeleteFunc(list.Items, func(i vaultModel.APIVaultItem) bool { return i.Name != name })
+	switch {
+	case len(matches) == 0:
+		return itemView{}, fmt.Errorf("no item is named %q", name)
+	case len(matches) > 1:
+		return itemView{}, fmt.Errorf("%d items are named %q, name the group of the item", len(matches), name)
+	}
+
+	view := newItemView(matches[0], r.vaultKey)
+	if view.Locked {
+		return itemView{}, errDecrypt
+	}
+
+	r.items[key] = view
+	return view, nil
+}
+
+// render replaces the references of a template with the fields they point to. A value
+// that is a reference as a whole is resolved too, so an env entry needs no braces.
+func (r *resolver) render(template string) (string, error) {
+	if strings.HasPrefix(template, referenceScheme) && !strings.ContainsAny(template, " \t\n") {
+		return r.resolve(template)
+	}
+
+	var failed error
+	rendered := templateReference.ReplaceAllStringFunc(template, func(match string) string {
+		if failed != nil {
+			return ""
+		}
+
+		value, err := r.resolve(templateReference.FindStringSubmatch(match)[1])
+		if err != nil {
+			failed = err
+			return ""
+		}
+		return value
+	})
+
+	return rendered, failed
+}
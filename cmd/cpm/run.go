@@ -0,0 +1,269 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"syscall"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultRunConfig is the mapping cpm run reads when -config is not set, it is fine for
+// it to be missing when the items are named with -item.
+const defaultRunConfig = ".cpm.yaml"
+
+var envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// runConfig maps the secrets of a .cpm.yaml to the environment of the child. The values
+// of env and the contents of files are templates, every file is written to a private
+// directory and the variable it is keyed by holds its path:
+//
+//	items:
+//	  - db-prod
+//	env:
+//	  DATABASE_PASSWORD: cpm://backend/db-prod/password
+//	  DATABASE_URL: postgres://{{ cpm://backend/db-prod/username }}@db/app
+//	files:
+//	  PGPASSFILE: |
+//	    db:5432:app:{{ cpm://_/db-prod/username }}:{{ cpm://_/db-prod/password }}
+type runConfig struct {
+	Items []string          `yaml:"items"`
+	Env   map[string]string `yaml:"env"`
+	Files map[string]string `yaml:"files"`
+}
+
+func readRunConfig(path string, required bool) (runConfig, error) {
+	var conf runConfig
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return conf, nil
+	}
+	if err != nil {
+		return conf, err
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&conf); err != nil && !errors.Is(err, io.EOF) {
+		return conf, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for name := range conf.Env {
+		if !envName.MatchString(name) {
+			return conf, fmt.Errorf("%s: %q is not a valid variable name", path, name)
+		}
+	}
+	for name := range conf.Files {
+		if !envName.MatchString(name) {
+			return conf, fmt.Errorf("%s: %q is not a valid variable name", path, name)
+		}
+	}
+
+	return conf, nil
+}
+
+// stringList is a flag that can be given more than once.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// runRun runs a command with the secrets of the vault in its environment. The files
+// of the mapping are overwritten and removed once the command exits, and the exit code
+// of the command is the exit code of cpm.
+func runRun(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	var items stringList
+	flags.Var(&items, "item", "inject every field of this item as <ITEM>_<FIELD>, can be repeated")
+	configPath := flags.String("config", defaultRunConfig, "mapping of the secrets to inject")
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		return errors.New("usage: cpm run [flags] -- <command> [args]")
+	}
+
+	configSet := false
+	flags.Visit(func(f *flag.Flag) { configSet = configSet || f.Name == "config" })
+
+	conf, err := readRunConfig(*configPath, configSet || len(items) == 0)
+	if err != nil {
+		return err
+	}
+	items = append(items, conf.Items...)
+
+	s, c, err := openSession()
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "cpm-run-")
+	if err != nil {
+		return err
+	}
+	defer scrubDir(dir)
+
+	vars, err := runEnvironment(s, c, conf, items, dir)
+	if err != nil {
+		return err
+	}
+
+	// the session key stays with cpm, the command gets the secrets it was given only
+	env := slices.DeleteFunc(os.Environ(), func(v string) bool { return strings.HasPrefix(v, sessionEnv+"=") })
+	env = append(env, vars...)
+
+	cmd := exec.Command(flags.Arg(0), flags.Args()[1:]...)
+	cmd.Env = env
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// an interrupt reaches the command, cpm waits for it so the files are scrubbed
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		for sig := range signals {
+			_ = cmd.Process.Signal(sig)
+		}
+	}()
+
+	return cmd.Wait()
+}
+
+// runEnvironment resolves the fields of the items and the mapping into the variables
+// of the command. The files of the mapping are written to dir.
+func runEnvironment(s session, c *client, conf runConfig, items []string, dir string) ([]string, error) {
+	r := newResolver(s, c)
+
+	var env []string
+	for _, name := range items {
+		item, err := resolveItem(c, name)
+		if err != nil {
+			return nil, err
+		}
+
+		view := newItemView(item, s.VaultKey)
+		if view.Locked {
+			return nil, fmt.Errorf("%s: %w", name, errDecrypt)
+		}
+
+		prefix := itemEnvPrefix(item.Name)
+		for field := fieldUsername; field <= fieldNote; field++ {
+			if value := view.field(field); value != "" {
+				env = append(env, prefix+"_"+strings.ToUpper(fieldNames[field])+"="+value)
+			}
+		}
+	}
+
+	for name, template := range conf.Env {
+		value, err := r.render(template)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, name+"="+value)
+	}
+
+	for name, template := range conf.Files {
+		content, err := r.render(template)
+		if err != nil {
+			return nil, err
+		}
+
+		path := filepath.Join(dir, strings.ToLower(name))
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return nil, err
+		}
+		env = append(env, name+"="+path)
+	}
+
+	return env, nil
+}
+
+// itemEnvPrefix turns the name of an item into the prefix of its variables, db-prod
+// becomes DB_PROD.
+func itemEnvPrefix(name string) string {
+	prefix := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+
+	if prefix == "" || (prefix[0] >= '0' && prefix[0] <= '9') {
+		prefix = "_" + prefix
+	}
+	return prefix
+}
+
+// scrubDir overwrites the files of the directory with zeros before it removes them.
+func scrubDir(dir string) {
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if info, err := entry.Info(); err == nil {
+			_ = os.WriteFile(path, make([]byte, info.Size()), 0o600)
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "the secrets in %s could not be removed: %v\n", dir, err)
+	}
+}
+
+// runInject renders a template, writing the output only the owner can read.
+func runInject(args []string) error {
+	flags := flag.NewFlagSet("inject", flag.ExitOnError)
+	in := flags.String("in", "", "the template, stdin when it is not set")
+	out := flags.String("out", "", "where to write the output, stdout when it is not set")
+	_ = flags.Parse(args)
+
+	var (
+		template []byte
+		err      error
+	)
+	if *in == "" {
+		template, err = io.ReadAll(os.Stdin)
+	} else {
+		template, err = os.ReadFile(*in)
+	}
+	if err != nil {
+		return err
+	}
+
+	s, c, err := openSession()
+	if err != nil {
+		return err
+	}
+
+	rendered, err := newResolver(s, c).render(string(template))
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.WriteString(rendered)
+		return err
+	}
+
+	return os.WriteFile(*out, []byte(rendered), 0o600)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunEnvironment(t *testing.T) {
+	t.Parallel()
+	server := newVaultServer(t)
+
+	testcases := []struct {
+		name     string
+		items    []string
+		conf     runConfig
+		expected []string
+		files    map[string]string
+		errText  string
+	}{
+		{
+			name:     "fields of an item",
+			items:    []string{"db-prod"},
+			expected: []string{"DB_PROD_USERNAME=app", "DB_PROD_PASSWORD=s3cret", "DB_PROD_URL=db.internal"},
+		},
+		{
+			name:     "item by id",
+			items:    []string{"4"},
+			expected: []string{"MAIL_USERNAME=john", "MAIL_PASSWORD=hunter2", "MAIL_NOTE=line one\nline two"},
+		},
+		{
+			name: "mapping",
+			conf: runConfig{Env: map[string]string{
+				"DATABASE_PASSWORD": "cpm://backend/db-prod/password",
+				"DATABASE_URL":      "postgres://{{ cpm://backend/db-prod/username }}@db/app",
+				"PLAIN":             "no secret",
+			}},
+			expected: []string{"DATABASE_PASSWORD=s3cret", "DATABASE_URL=postgres://app@db/app", "PLAIN=no secret"},
+		},
+		{
+			name: "files",
+			conf: runConfig{Files: map[string]string{
+				"PGPASSFILE": "db:5432:app:{{ cpm://_/db-prod/username }}:{{ cpm://_/db-prod/password }}\n",
+			}},
+			files: map[string]string{"PGPASSFILE": "db:5432:app:app:s3cret\n"},
+		},
+		{
+			name:    "item that does not exist",
+			items:   []string{"missing"},
+			errText: `no item is named "missing"`,
+		},
+		{
+			name:    "item of another vault key",
+			items:   []string{"locked"},
+			errText: "locked: " + errDecrypt.Error(),
+		},
+		{
+			name:    "reference that does not resolve",
+			conf:    runConfig{Env: map[string]string{"TLS_KEY": "cpm://_/tls/password"}},
+			errText: `2 items are named "tls"`,
+		},
+		{
+			name:    "file with a broken reference",
+			conf:    runConfig{Files: map[string]string{"KEY": "{{ cpm://backend/tls }}"}},
+			errText: "is not a reference",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+
+			env, err := runEnvironment(session{VaultKey: testVaultKey}, newClient(server.URL, "token"), tc.conf, tc.items, dir)
+			if tc.errText != "" {
+				require.ErrorContains(t, err, tc.errText)
+				return
+			}
+			require.NoError(t, err)
+
+			for name, content := range tc.files {
+				path := filepath.Join(dir, strings.ToLower(name))
+				require.Contains(t, env, name+"="+path)
+
+				written, err := os.ReadFile(path)
+				require.NoError(t, err)
+				require.Equal(t, content, string(written))
+
+				info, err := os.Stat(path)
+				require.NoError(t, err)
+				require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+			}
+
+			if tc.files == nil {
+				require.ElementsMatch(t, tc.expected, env)
+			}
+		})
+	}
+}
+
+func TestReadRunConfig(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		content  *string
+		required bool
+		expected runConfig
+		errText  string
+	}{
+		{
+			name: "mapping",
+			content: ptr("items:\n  - db-prod\nenv:\n  DATABASE_PASSWORD: cpm://backend/db-prod/password\n" +
+				"files:\n  PGPASSFILE: |\n    db:{{ cpm://_/db-prod/password }}\n"),
+			expected: runConfig{
+				Items: []string{"db-prod"},
+				Env:   map[string]string{"DATABASE_PASSWORD": "cpm://backend/db-prod/password"},
+				Files: map[string]string{"PGPASSFILE": "db:{{ cpm://_/db-prod/password }}\n"},
+			},
+		},
+		{
+			name:    "empty file",
+			content: ptr(""),
+		},
+		{
+			name: "missing optional file",
+		},
+		{
+			name:     "missing required file",
+			required: true,
+			errText:  "no such file",
+		},
+		{
+			name:    "unknown key",
+			content: ptr("secrets:\n  A: b\n"),
+			errText: "field secrets not found",
+		},
+		{
+			name:    "invalid variable name",
+			content: ptr("env:\n  1PASSWORD: cpm://_/mail/password\n"),
+			errText: `"1PASSWORD" is not a valid variable name`,
+		},
+		{
+			name:    "invalid file variable",
+			content: ptr("files:\n  MY-FILE: content\n"),
+			errText: `"MY-FILE" is not a valid variable name`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), defaultRunConfig)
+			if tc.content != nil {
+				require.NoError(t, os.WriteFile(path, []byte(*tc.content), 0o600))
+			}
+
+			conf, err := readRunConfig(path, tc.required)
+			if tc.errText != "" {
+				require.ErrorContains(t, err, tc.errText)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, conf)
+		})
+	}
+}
+
+func TestItemEnvPrefix(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "db-prod", expected: "DB_PROD"},
+		{name: "Work Mail", expected: "WORK_MAIL"},
+		{name: "aws_s3", expected: "AWS_S3"},
+		{name: "1password", expected: "_1PASSWORD"},
+		{name: "café", expected: "CAF_"},
+		{name: "", expected: "_"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.expected, itemEnvPrefix(tc.name))
+		})
+	}
+}
+
+func TestRunInject(t *testing.T) {
+	server := newVaultServer(t)
+	dir := t.TempDir()
+
+	t.Setenv("CPM_SESSION_FILE", filepath.Join(dir, "session"))
+	key, err := saveSession(session{
+		Server:    server.URL,
+		Token:     "token",
+		ExpiresAt: time.Now().Add(time.Hour),
+		VaultKey:  testVaultKey,
+	})
+	require.NoError(t, err)
+	t.Setenv(sessionEnv, key)
+
+	in := filepath.Join(dir, "config.tmpl")
+	out := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(in, []byte("user={{ cpm://backend/db-prod/username }}\npassword={{ cpm://backend/db-prod/password }}\n"), 0o644))
+
+	require.NoError(t, runInject([]string{"-in", in, "-out", out}))
+
+	rendered, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "user=app\npassword=s3cret\n", string(rendered))
+
+	info, err := os.Stat(out)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// nothing is written when a reference does not resolve
+	require.NoError(t, os.WriteFile(in, []byte("{{ cpm://_/missing/password }}"), 0o644))
+	broken := filepath.Join(dir, "broken")
+	require.ErrorContains(t, runInject([]string{"-in", in, "-out", broken}), `no item is named "missing"`)
+	_, err = os.Stat(broken)
+	require.True(t, os.IsNotExist(err))
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
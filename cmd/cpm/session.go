@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// sessionEnv holds the key the cached session is encrypted with. The key never touches
+// the disk, so a copy of the session file alone does not open the vault.
+const sessionEnv = "CPM_SESSION"
+
+var errNoSession = errors.New("not logged in, run: eval \"$(cpm login)\"")
+
+// session is what a login leaves behind for the next commands.
+type session struct {
+	Server    string    `json:"server"`
+	Username  string    `json:"username"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	VaultKey  []byte    `json:"vaultKey"`
+}
+
+func sessionPath() (string, error) {
+	if path := os.Getenv("CPM_SESSION_FILE"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "cpm", "session"), nil
+}
+
+// saveSession encrypts the session under a fresh key and returns the key, which the
+// user exports as CPM_SESSION.
+func saveSession(s session) (string, error) {
+	key := make([]byte, keyLength)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := sealed(key, encoded)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := sessionPath()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(path, encrypted, 0o600); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(key), nil
+}
+
+func loadSession() (session, error) {
+	key, err := base64.RawURLEncoding.DecodeString(os.Getenv(sessionEnv))
+	if err != nil || len(key) != keyLength {
+		return session{}, errNoSession
+	}
+
+	path, err := sessionPath()
+	if err != nil {
+		return session{}, err
+	}
+
+	encrypted, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return session{}, errNoSession
+	}
+	if err != nil {
+		return session{}, err
+	}
+
+	decrypted, err := openSealed(key, encrypted)
+	if err != nil {
+		return session{}, errNoSession
+	}
+
+	var s session
+	if err := json.Unmarshal(decrypted, &s); err != nil {
+		return session{}, err
+	}
+
+	if time.Now().After(s.ExpiresAt) {
+		return session{}, errNoSession
+	}
+
+	return s, nil
+}
+
+func removeSession() error {
+	path, err := sessionPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
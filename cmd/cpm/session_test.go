@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cpm", "session")
+	t.Setenv("CPM_SESSION_FILE", path)
+
+	saved := session{
+		Server:    "https://vault.example.com",
+		Username:  "john",
+		Token:     "token",
+		ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Second),
+		VaultKey:  []byte("0123456789abcdef0123456789abcdef"),
+	}
+	key, err := saveSession(saved)
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// the file alone does not give the session away
+	encrypted, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(encrypted), "token")
+
+	t.Setenv(sessionEnv, key)
+	loaded, err := loadSession()
+	require.NoError(t, err)
+	require.Equal(t, saved.Token, loaded.Token)
+	require.Equal(t, saved.VaultKey, loaded.VaultKey)
+	require.True(t, saved.ExpiresAt.Equal(loaded.ExpiresAt))
+
+	require.NoError(t, removeSession())
+	_, err = loadSession()
+	require.ErrorIs(t, err, errNoSession)
+	require.NoError(t, removeSession())
+}
+
+func TestLoadSession(t *testing.T) {
+	otherKey := make([]byte, keyLength)
+	_, err := rand.Read(otherKey)
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name      string
+		expiresAt time.Time
+		key       func(key string) string
+		remove    bool
+		ok        bool
+	}{
+		{
+			name:      "session",
+			expiresAt: time.Now().Add(time.Hour),
+			key:       func(key string) string { return key },
+			ok:        true,
+		},
+		{
+			name:      "no key",
+			expiresAt: time.Now().Add(time.Hour),
+			key:       func(string) string { return "" },
+		},
+		{
+			name:      "key that is not base64",
+			expiresAt: time.Now().Add(time.Hour),
+			key:       func(string) string { return "not a key!" },
+		},
+		{
+			name:      "short key",
+			expiresAt: time.Now().Add(time.Hour),
+			key:       func(key string) string { return key[:10] },
+		},
+		{
+			name:      "key of another session",
+			expiresAt: time.Now().Add(time.Hour),
+			key:       func(string) string { return base64.RawURLEncoding.EncodeToString(otherKey) },
+		},
+		{
+			name:      "expired session",
+			expiresAt: time.Now().Add(-time.Minute),
+			key:       func(key string) string { return key },
+		},
+		{
+			name:      "no session file",
+			expiresAt: time.Now().Add(time.Hour),
+			key:       func(key string) string { return key },
+			remove:    true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("CPM_SESSION_FILE", filepath.Join(t.TempDir(), "session"))
+
+			key, err := saveSession(session{Username: "john", Token: "token", ExpiresAt: tc.expiresAt})
+			require.NoError(t, err)
+			if tc.remove {
+				require.NoError(t, removeSession())
+			}
+
+			t.Setenv(sessionEnv, tc.key(key))
+			loaded, err := loadSession()
+			if !tc.ok {
+				require.ErrorIs(t, err, errNoSession)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "john", loaded.Username)
+		})
+	}
+}
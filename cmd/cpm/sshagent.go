@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	vaultModel "github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/delivery/http/handler/model"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// privateKeyMarker ends the armor line of every PEM and OpenSSH private key.
+const privateKeyMarker = "PRIVATE KEY-----"
+
+var (
+	errAgentReadOnly = errors.New("the keys of this agent come from the vault, add them with cpm add -ssh-key")
+	errAgentRefused  = errors.New("the use of the key was refused")
+)
+
+// An SSH key item holds the private key in its note and the passphrase of the key, if
+// it has one, as its password.
+func sshPrivateKey(view itemView) (any, bool) {
+	if view.Locked || !strings.Contains(view.Note, privateKeyMarker) {
+		return nil, false
+	}
+
+	var (
+		key any
+		err error
+	)
+	if view.Password != "" {
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase([]byte(view.Note), []byte(view.Password))
+	} else {
+		key, err = ssh.ParseRawPrivateKey([]byte(view.Note))
+	}
+
+	return key, err == nil
+}
+
+// readSSHKeyFile reads a private key for an item, asking for its passphrase when it is
+// encrypted. The comment of the public key next to it becomes the username.
+func readSSHKeyFile(path string) (secrets, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return secrets{}, err
+	}
+
+	plain := secrets{Note: string(content)}
+
+	_, err = ssh.ParseRawPrivateKey(content)
+	var missing *ssh.PassphraseMissingError
+	switch {
+	case errors.As(err, &missing):
+		if plain.Password, err = readSecret("Passphrase of the key: "); err != nil {
+			return secrets{}, err
+		}
+		if _, err := ssh.ParseRawPrivateKeyWithPassphrase(content, []byte(plain.Password)); err != nil {
+			return secrets{}, fmt.Errorf("%s: %w", path, err)
+		}
+	case err != nil:
+		return secrets{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if public, err := os.ReadFile(path + ".pub"); err == nil {
+		if _, comment, _, _, err := ssh.ParseAuthorizedKey(public); err == nil {
+			plain.Username = comment
+		}
+	}
+
+	return plain, nil
+}
+
+// vaultAgent serves the keys it was loaded with from memory. Clients can list, use and
+// remove them but not add their own, and every use is confirmed when confirm is set.
+type vaultAgent struct {
+	agent.ExtendedAgent
+	confirm func(comment string) bool
+
+	// one confirmation is asked at a time
+	mu sync.Mutex
+}
+
+func newVaultAgent(keys []agent.AddedKey, confirm func(comment string) bool) (*vaultAgent, error) {
+	keyring := agent.NewKeyring().(agent.ExtendedAgent)
+	for _, key := range keys {
+		if err := keyring.Add(key); err != nil {
+			return nil, fmt.Errorf("%s: %w", key.Comment, err)
+		}
+	}
+
+	return &vaultAgent{ExtendedAgent: keyring, confirm: confirm}, nil
+}
+
+func (a *vaultAgent) Add(agent.AddedKey) error {
+	return errAgentReadOnly
+}
+
+func (a *vaultAgent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+func (a *vaultAgent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	if a.confirm != nil {
+		keys, err := a.List()
+		if err != nil {
+			return nil, err
+		}
+
+		blob := key.Marshal()
+		i := slices.IndexFunc(keys, func(k *agent.Key) bool { return bytes.Equal(k.Blob, blob) })
+		if i < 0 {
+			return nil, errors.New("the key is not in the agent")
+		}
+
+		a.mu.Lock()
+		allowed := a.confirm(keys[i].Comment)
+		a.mu.Unlock()
+		if !allowed {
+			return nil, errAgentRefused
+		}
+	}
+
+	return a.ExtendedAgent.SignWithFlags(key, data, flags)
+}
+
+// askConfirm asks through SSH_ASKPASS like ssh-agent does, or on the terminal of the
+// agent when there is no askpass program.
+func askConfirm(comment string) bool {
+	question := fmt.Sprintf("Allow the use of the key %q?", comment)
+	if askpass := os.Getenv("SSH_ASKPASS"); askpass != "" {
+		cmd := exec.Command(askpass, question)
+		cmd.Env = append(os.Environ(), "SSH_ASKPASS_PROMPT=confirm")
+		return cmd.Run() == nil
+	}
+
+	return confirm(question)
+}
+
+// loadSSHKeys reads the SSH key items of the vault, only those shared with one of the
+// groups when groups is set.
+func loadSSHKeys(c *client, s session, groups string, lifetime time.Duration) ([]agent.AddedKey, error) {
+	groupIDs, err := resolveGroups(c, groups)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := readAllItems(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []agent.AddedKey
+	for _, item := range items {
+		if len(groupIDs) > 0 && !slices.ContainsFunc(item.Groups, func(g vaultModel.APIVaultItemGroup) bool {
+			return slices.Contains(groupIDs, g.ID)
+		}) {
+			continue
+		}
+
+		key, ok := sshPrivateKey(newItemView(item, s.VaultKey))
+		if !ok {
+			continue
+		}
+
+		keys = append(keys, agent.AddedKey{
+			PrivateKey:   key,
+			Comment:      item.Name,
+			LifetimeSecs: uint32(lifetime.Seconds()),
+		})
+	}
+
+	return keys, nil
+}
+
+// runSSHAgent serves the SSH keys of the vault over a socket until it is interrupted.
+// The socket is printed as an export, the agent runs in the foreground so it can ask
+// for confirmations on its terminal:
+//
+//	cpm ssh-agent -confirm -groups ops
+//	export SSH_AUTH_SOCK=/tmp/cpm-agent-1234/agent.sock
+func runSSHAgent(args []string) error {
+	flags := flag.NewFlagSet("ssh-agent", flag.ExitOnError)
+	socket := flags.String("socket", "", "path of the socket, a private temporary directory when it is not set")
+	groups := flags.String("groups", "", "comma separated ids or names of groups, only their keys are served")
+	confirmUse := flags.Bool("confirm", false, "confirm every use of a key")
+	lifetime := flags.Duration("lifetime", 0, "forget the keys after this long, 0 keeps them until the agent stops")
+	_ = flags.Parse(args)
+
+	if *lifetime != 0 && *lifetime < time.Second {
+		return errors.New("the lifetime of the keys is at least a second")
+	}
+
+	s, c, err := openSession()
+	if err != nil {
+		return err
+	}
+
+	keys, err := loadSSHKeys(c, s, *groups, *lifetime)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return errors.New("there are no SSH keys in the vault, add one with cpm add -ssh-key")
+	}
+
+	var confirmer func(string) bool
+	if *confirmUse {
+		confirmer = askConfirm
+	}
+
+	a, err := newVaultAgent(keys, confirmer)
+	if err != nil {
+		return err
+	}
+
+	path := *socket
+	if path == "" {
+		dir, err := os.MkdirTemp("", "cpm-agent-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+		path = filepath.Join(dir, "agent.sock")
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	if err := os.Chmod(path, 0o600); err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		listener.Close()
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving %d keys, stop the agent with Ctrl+C.\n", len(keys))
+	fmt.Printf("export SSH_AUTH_SOCK=%s\n", path)
+
+	for {
+		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			defer conn.Close()
+			_ = agent.ServeAgent(a, conn)
+		}()
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func TestSSHPrivateKey(t *testing.T) {
+	t.Parallel()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	plain, err := ssh.MarshalPrivateKey(key, "plain")
+	require.NoError(t, err)
+	encrypted, err := ssh.MarshalPrivateKeyWithPassphrase(key, "encrypted", []byte("passphrase"))
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name string
+		view itemView
+		ok   bool
+	}{
+		{
+			name: "plain key",
+			view: itemView{Note: string(pem.EncodeToMemory(plain))},
+			ok:   true,
+		},
+		{
+			name: "encrypted key",
+			view: itemView{Note: string(pem.EncodeToMemory(encrypted)), Password: "passphrase"},
+			ok:   true,
+		},
+		{
+			name: "wrong passphrase",
+			view: itemView{Note: string(pem.EncodeToMemory(encrypted)), Password: "wrong"},
+		},
+		{
+			name: "login item",
+			view: itemView{Username: "user", Password: "password", Note: "a note"},
+		},
+		{
+			name: "locked item",
+			view: itemView{Locked: true},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, ok := sshPrivateKey(tc.view)
+			require.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestVaultAgent(t *testing.T) {
+	t.Parallel()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keys := []agent.AddedKey{
+		{PrivateKey: edKey, Comment: "deploy"},
+		{PrivateKey: rsaKey, Comment: "legacy"},
+	}
+
+	testcases := []struct {
+		name    string
+		confirm func(string) bool
+		refused bool
+	}{
+		{
+			name: "without confirmation",
+		},
+		{
+			name:    "confirmed",
+			confirm: func(string) bool { return true },
+		},
+		{
+			name:    "refused",
+			confirm: func(string) bool { return false },
+			refused: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			client := newAgentClient(t, keys, tc.confirm)
+
+			listed, err := client.List()
+			require.NoError(t, err)
+			require.Len(t, listed, 2)
+			require.Equal(t, "deploy", listed[0].Comment)
+			require.Equal(t, "legacy", listed[1].Comment)
+
+			for _, key := range listed {
+				var flags agent.SignatureFlags
+				if key.Type() == ssh.KeyAlgoRSA {
+					flags = agent.SignatureFlagRsaSha256
+				}
+
+				data := []byte("session to sign")
+				signature, err := client.SignWithFlags(key, data, flags)
+				if tc.refused {
+					// the reason does not cross the socket, the client only sees a failure
+					require.Error(t, err)
+					continue
+				}
+				require.NoError(t, err)
+				require.NoError(t, key.Verify(data, signature))
+			}
+
+			require.Error(t, client.Add(agent.AddedKey{PrivateKey: edKey, Comment: "added"}))
+
+			require.NoError(t, client.Remove(listed[0]))
+			listed, err = client.List()
+			require.NoError(t, err)
+			require.Len(t, listed, 1)
+		})
+	}
+}
+
+func TestVaultAgent_Lifetime(t *testing.T) {
+	t.Parallel()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	client := newAgentClient(t, []agent.AddedKey{{PrivateKey: key, Comment: "short", LifetimeSecs: 1}}, nil)
+
+	listed, err := client.List()
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+
+	time.Sleep(1100 * time.Millisecond)
+
+	listed, err = client.List()
+	require.NoError(t, err)
+	require.Empty(t, listed)
+}
+
+func newAgentClient(t *testing.T, keys []agent.AddedKey, confirm func(string) bool) agent.ExtendedAgent {
+	a, err := newVaultAgent(keys, confirm)
+	require.NoError(t, err)
+
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	go func() {
+		_ = agent.ServeAgent(a, server)
+	}()
+
+	return agent.NewClient(client)
+}
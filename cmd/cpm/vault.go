@@ -0,0 +1,205 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+)
+
+// The vault key is wrapped the way the browser wraps it, with an AES-GCM key derived
+// from the OPAQUE export key. The wrapped form is nonce || ciphertext.
+const (
+	wrapInfo    = "cool-password-manager vault key"
+	nonceLength = 12
+	keyLength   = 32
+)
+
+var errDecrypt = errors.New("the value can not be decrypted with the vault key")
+
+func unwrapVaultKey(exportKey, wrapped []byte) ([]byte, error) {
+	key, err := hkdf.Key(sha256.New, exportKey, nil, wrapInfo, keyLength)
+	if err != nil {
+		return nil, err
+	}
+
+	return openSealed(key, wrapped)
+}
+
+func wrapVaultKey(exportKey, vaultKey []byte) ([]byte, error) {
+	key, err := hkdf.Key(sha256.New, exportKey, nil, wrapInfo, keyLength)
+	if err != nil {
+		return nil, err
+	}
+
+	return sealed(key, vaultKey)
+}
+
+// sealed encrypts plaintext under key with a random nonce, it returns nonce || ciphertext.
+func sealed(key, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, nonceLength)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func openSealed(key, value []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(value) < nonceLength {
+		return nil, errDecrypt
+	}
+
+	plaintext, err := aead.Open(nil, value[:nonceLength], value[nonceLength:], nil)
+	if err != nil {
+		return nil, errDecrypt
+	}
+
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// The secret fields of an item are encrypted under the vault key with the nonce of the
+// item, every field xors its index into the last byte of the nonce so no two fields
+// share one. The name of the field is the additional data, so the server can not swap
+// the password and the username of an item.
+type itemField byte
+
+const (
+	fieldUsername itemField = iota
+	fieldPassword
+	fieldUrl
+	fieldNote
+)
+
+var fieldNames = map[itemField]string{
+	fieldUsername: "username",
+	fieldPassword: "password",
+	fieldUrl:      "url",
+	fieldNote:     "note",
+}
+
+func parseField(name string) (itemField, bool) {
+	for field, fieldName := range fieldNames {
+		if fieldName == name {
+			return field, true
+		}
+	}
+
+	return 0, false
+}
+
+// secrets are the decrypted fields of an item.
+type secrets struct {
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+	Url      string `json:"url,omitempty"`
+	Note     string `json:"note,omitempty"`
+}
+
+// encryptedSecrets are the fields the way the API carries them, Url and Note are nil
+// when the item has none.
+type encryptedSecrets struct {
+	Username []byte
+	Password []byte
+	Url      []byte
+	Note     []byte
+	Nonce    []byte
+}
+
+func encryptSecrets(vaultKey []byte, plain secrets) (encryptedSecrets, error) {
+	aead, err := newAEAD(vaultKey)
+	if err != nil {
+		return encryptedSecrets{}, err
+	}
+
+	nonce := make([]byte, nonceLength)
+	if _, err := rand.Read(nonce); err != nil {
+		return encryptedSecrets{}, err
+	}
+
+	seal := func(field itemField, value string) []byte {
+		return aead.Seal(nil, fieldNonce(nonce, field), []byte(value), []byte(fieldNames[field]))
+	}
+
+	encrypted := encryptedSecrets{
+		Username: seal(fieldUsername, plain.Username),
+		Password: seal(fieldPassword, plain.Password),
+		Nonce:    nonce,
+	}
+	if plain.Url != "" {
+		encrypted.Url = seal(fieldUrl, plain.Url)
+	}
+	if plain.Note != "" {
+		encrypted.Note = seal(fieldNote, plain.Note)
+	}
+
+	return encrypted, nil
+}
+
+func decryptSecrets(vaultKey []byte, encrypted encryptedSecrets) (secrets, error) {
+	aead, err := newAEAD(vaultKey)
+	if err != nil {
+		return secrets{}, err
+	}
+
+	if len(encrypted.Nonce) != nonceLength {
+		return secrets{}, errDecrypt
+	}
+
+	open := func(field itemField, value []byte) (string, error) {
+		if value == nil {
+			return "", nil
+		}
+
+		plaintext, err := aead.Open(nil, fieldNonce(encrypted.Nonce, field), value, []byte(fieldNames[field]))
+		if err != nil {
+			return "", errDecrypt
+		}
+		return string(plaintext), nil
+	}
+
+	var plain secrets
+	if plain.Username, err = open(fieldUsername, encrypted.Username); err != nil {
+		return secrets{}, err
+	}
+	if plain.Password, err = open(fieldPassword, encrypted.Password); err != nil {
+		return secrets{}, err
+	}
+	if plain.Url, err = open(fieldUrl, encrypted.Url); err != nil {
+		return secrets{}, err
+	}
+	if plain.Note, err = open(fieldNote, encrypted.Note); err != nil {
+		return secrets{}, err
+	}
+
+	return plain, nil
+}
+
+func fieldNonce(nonce []byte, field itemField) []byte {
+	derived := make([]byte, len(nonce))
+	copy(derived, nonce)
+	derived[len(derived)-1] ^= byte(field)
+
+	return derived
+}
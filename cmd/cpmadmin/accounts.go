@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/param"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultLockDuration is how long a lock lasts when -for is not set.
+const defaultLockDuration = 30 * 24 * time.Hour
+
+func runAccounts(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: cpmadmin accounts list|lock|unlock [flags]")
+	}
+
+	action, args := args[0], args[1:]
+	flags := flag.NewFlagSet("accounts "+action, flag.ExitOnError)
+
+	var run func(u usecase.AdminUsecase) error
+	switch action {
+	case "list":
+		search := flags.String("search", "", "only the accounts whose username, email or name contains this")
+		page := flags.Int("page", 1, "page number")
+		pageSize := flags.Int("page-size", 50, "accounts per page")
+		_ = flags.Parse(args)
+
+		run = func(u usecase.AdminUsecase) error {
+			return listAccounts(ctx, u, *search, *page, *pageSize)
+		}
+	case "lock":
+		duration := flags.Duration("for", defaultLockDuration, "how long the account stays locked")
+		_ = flags.Parse(args)
+		if flags.NArg() != 1 || *duration <= 0 {
+			return errors.New("usage: cpmadmin accounts lock [-for duration] <username>")
+		}
+
+		run = func(u usecase.AdminUsecase) error {
+			if err := u.LockAccount(ctx, flags.Arg(0), *duration); err != nil {
+				return err
+			}
+			fmt.Printf("Locked %s until %s and signed it out everywhere.\n",
+				flags.Arg(0), time.Now().Add(*duration).Format(time.DateTime))
+			return nil
+		}
+	case "unlock":
+		_ = flags.Parse(args)
+		if flags.NArg() != 1 {
+			return errors.New("usage: cpmadmin accounts unlock <username>")
+		}
+
+		run = func(u usecase.AdminUsecase) error {
+			if err := u.UnlockAccount(ctx, flags.Arg(0)); err != nil {
+				return err
+			}
+			fmt.Printf("Unlocked %s.\n", flags.Arg(0))
+			return nil
+		}
+	default:
+		return fmt.Errorf("unknown action %q, use list, lock or unlock", action)
+	}
+
+	u, closeAll, err := openAdminUsecase(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+
+	return run(u)
+}
+
+// openAdminUsecase connects to the database and redis, the returned function closes
+// both connections.
+func openAdminUsecase(ctx context.Context) (usecase.AdminUsecase, func(), error) {
+	conf, db, err := connect(ctx)
+	if err != nil {
+		return usecase.AdminUsecase{}, nil, err
+	}
+
+	options, err := redis.ParseURL(conf.Redis.URL)
+	if err != nil {
+		db.Close()
+		return usecase.AdminUsecase{}, nil, err
+	}
+	client := redis.NewClient(options)
+
+	u := usecase.NewAdminUsecase(
+		repository.NewAccountRepository(db),
+		repository.NewAttemptRepository(client),
+		repository.NewSessionRepository(client),
+		repository.NewAccessTokenRepository(db),
+		conf,
+	)
+
+	return u, func() {
+		client.Close()
+		db.Close()
+	}, nil
+}
+
+func listAccounts(ctx context.Context, u usecase.AdminUsecase, search string, page, pageSize int) error {
+	if page < 1 || pageSize < 1 {
+		return errors.New("the page and the page size are at least 1")
+	}
+
+	accounts, count, err := u.ReadAccounts(ctx, param.ReadAccountParams{
+		SearchQuery: types.NewNullString(search),
+		Limit:       pageSize,
+		Offset:      (page - 1) * pageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tUSERNAME\tEMAIL\tNAME\tLOCKED")
+	for _, acc := range accounts {
+		lockedFor, err := u.LockedFor(ctx, acc.Username)
+		if err != nil {
+			return err
+		}
+
+		locked := ""
+		if lockedFor > 0 {
+			locked = "for " + lockedFor.Round(time.Second).String()
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s %s\t%s\n", acc.Entity.ID, acc.Username, acc.Email, acc.FirstName, acc.LastName, locked)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if count > len(accounts) {
+		fmt.Fprintf(os.Stderr, "page %d, %d accounts in total\n", page, count)
+	}
+	return nil
+}
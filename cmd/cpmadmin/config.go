@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+)
+
+// pingTimeout bounds how long the check waits for the database and redis.
+const pingTimeout = 5 * time.Second
+
+type check struct {
+	name string
+	run  func() error
+}
+
+// runConfig prints a line for every check and fails when any of them failed, so it
+// can gate a deployment.
+func runConfig(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("config", flag.ExitOnError)
+	offline := flags.Bool("offline", false, "do not connect to the database and redis")
+	_ = flags.Parse(args)
+
+	return checkConfig(ctx, os.Stdout, *offline)
+}
+
+// checkConfig writes the report of the checks to out.
+func checkConfig(ctx context.Context, out io.Writer, offline bool) error {
+	conf, err := config.Load()
+	if err != nil {
+		report(out, err, "the config loads")
+		return err
+	}
+	report(out, nil, "the config loads")
+
+	problems := conf.Validate()
+	for _, err := range problems {
+		report(out, err, "")
+	}
+	if len(problems) == 0 {
+		report(out, nil, "the config values are valid")
+	}
+
+	failed := len(problems)
+
+	checks := []check{
+		{"the OPAQUE keys load", func() error { return loadOpaqueKeys(conf) }},
+		{"the mailer starts", func() error { _, err := mail.New(conf); return err }},
+	}
+	if !offline {
+		checks = append(checks,
+			check{"the database answers", func() error { return pingDatabase(ctx, conf) }},
+			check{"redis answers", func() error { return pingRedis(ctx, conf) }},
+		)
+	}
+
+	for _, c := range checks {
+		err := c.run()
+		report(out, err, c.name)
+		if err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d checks failed", failed)
+	}
+	return nil
+}
+
+func report(out io.Writer, err error, name string) {
+	if err != nil {
+		fmt.Fprintf(out, "FAIL  %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(out, "ok    %s\n", name)
+}
+
+func pingDatabase(ctx context.Context, conf *config.Config) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	db, err := database.Open(ctx, conf.DB.URL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Ping(ctx)
+}
+
+func pingRedis(ctx context.Context, conf *config.Config) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	options, err := redis.ParseURL(conf.Redis.URL)
+	if err != nil {
+		return err
+	}
+
+	client := redis.NewClient(options)
+	defer client.Close()
+
+	return client.Ping(ctx).Err()
+}
+
+// connect loads the config and opens the database, for the commands that need both.
+func connect(ctx context.Context) (*config.Config, *pgxpool.Pool, error) {
+	conf, err := config.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := database.Open(ctx, conf.DB.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conf, db, nil
+}
+
+// loadOpaqueKeys loads the keys the way the server does and zeroes them again.
+func loadOpaqueKeys(conf *config.Config) error {
+	opaqueAdaptor, err := opaque.New(conf)
+	if err != nil {
+		return err
+	}
+
+	opaqueAdaptor.Close()
+	return nil
+}
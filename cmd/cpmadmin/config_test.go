@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testConfig is a config of the file key provider with the keys under keys/.
+const testConfig = `app:
+  name: "cpm"
+  version: "1.0.0"
+  template_path: "/frontend/templates/**/*.html"
+  static_path: "/frontend/static"
+  two_factor_duration: 20
+  session_duration: 60
+  default_page: 1
+  default_page_size: 10
+http:
+  port: "%PORT%"
+  host: "localhost"
+opaque:
+  server_id: "cpm"
+  public_key_path: "keys/server_public.bin"
+  private_key_path: "keys/server_private.bin"
+  oprf_key_path: "keys/oprf_seed.bin"
+  registration_duration: 20
+keys:
+  provider: "file"
+security:
+  max_login_attempts: 5
+  max_ip_attempts: 20
+  max_two_factor_attempts: 3
+  attempt_window: 15
+  lockout_duration: 1
+  max_lockout_duration: 60
+mail:
+  driver: "memory"
+  from: "no-reply@example.com"
+`
+
+func TestCheckConfig(t *testing.T) {
+	testcases := []struct {
+		name     string
+		config   string
+		keys     bool
+		expected []string
+		errText  string
+	}{
+		{
+			name:   "valid config",
+			config: strings.ReplaceAll(testConfig, "%PORT%", "8080"),
+			keys:   true,
+			expected: []string{
+				"ok    the config loads",
+				"ok    the config values are valid",
+				"ok    the OPAQUE keys load",
+				"ok    the mailer starts",
+			},
+		},
+		{
+			name:   "missing keys",
+			config: strings.ReplaceAll(testConfig, "%PORT%", "8080"),
+			expected: []string{
+				"ok    the config loads",
+				"FAIL  opaque.public_key_path: keys/server_public.bin is missing or empty",
+				"FAIL  opaque.private_key_path: keys/server_private.bin is missing or empty",
+				"FAIL  opaque.oprf_key_path: keys/oprf_seed.bin is missing or empty",
+				"FAIL  the key provider has no oprf_seed",
+				"FAIL  the key provider has no server_private_key",
+				"FAIL  the key provider has no server_public_key",
+				"FAIL  oprf seed: open keys/oprf_seed.bin",
+				"ok    the mailer starts",
+			},
+			errText: "7 checks failed",
+		},
+		{
+			name:   "invalid value",
+			config: strings.ReplaceAll(testConfig, "%PORT%", "http"),
+			keys:   true,
+			expected: []string{
+				"ok    the config loads",
+				`FAIL  http.port "http" is not a port`,
+				"ok    the OPAQUE keys load",
+				"ok    the mailer starts",
+			},
+			errText: "1 checks failed",
+		},
+		{
+			name:     "missing config",
+			expected: []string{"FAIL  config error"},
+			errText:  "config error",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Chdir(dir)
+			t.Setenv("SECRET_KEY", "secret")
+			t.Setenv("AES_KEY", "syaZbz9ca3SZ51GUdyx3F//e89Hgfr2XuHHn4VdnMQU=")
+			t.Setenv("POSTGRES_URL", "postgres://localhost:5432/cpm")
+			t.Setenv("REDIS_URL", "redis://localhost:6379/0")
+
+			if tc.config != "" {
+				require.NoError(t, os.Mkdir(filepath.Join(dir, "config"), 0o700))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "config", "config.yaml"), []byte(tc.config), 0o600))
+			}
+			if tc.keys {
+				require.NoError(t, generateKeys([]string{"-dir", "keys"}))
+			}
+
+			var out bytes.Buffer
+			err := checkConfig(context.Background(), &out, true)
+			if tc.errText != "" {
+				require.ErrorContains(t, err, tc.errText, out.String())
+			} else {
+				require.NoError(t, err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			require.Len(t, lines, len(tc.expected), out.String())
+			for i, prefix := range tc.expected {
+				require.True(t, strings.HasPrefix(lines[i], prefix), "line %d is %q", i, lines[i])
+			}
+		})
+	}
+}
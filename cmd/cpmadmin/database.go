@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/keyprovider"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/seed"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/oprfutils"
+)
+
+// defaultKeysDir is where config/config.yaml looks for the OPAQUE keys.
+const defaultKeysDir = "internal/infrastructure/opaque/keys"
+
+// runKeys generates the OPAQUE keys of the server, rotates their AKE key pair, or seals
+// them together with the AES key for the keyfile and transit key providers.
+func runKeys(ctx context.Context, args []string) error {
+	action := "generate"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	switch action {
+	case "generate":
+		return generateKeys(args)
+	case "rotate":
+		return rotateKeys(args)
+	case "seal", "wrap":
+		return sealKeys(ctx, action, args)
+	default:
+		return fmt.Errorf("unknown action %q, use generate, rotate, seal or wrap", action)
+	}
+}
+
+// generateKeys never replaces a key, every account registered against the old keys
+// could not log in anymore.
+func generateKeys(args []string) error {
+	flags := flag.NewFlagSet("keys generate", flag.ExitOnError)
+	dir := flags.String("dir", defaultKeysDir, "directory of the keys")
+	suite := flags.String("suite", oprfutils.SuiteP256, "opaque.suite of the server, the keys only work in it")
+	_ = flags.Parse(args)
+
+	existing := 0
+	for _, name := range []string{oprfutils.OprfSeedFile, oprfutils.PrivateKeyFile, oprfutils.PublicKeyFile} {
+		if _, err := os.Stat(filepath.Join(*dir, name)); err == nil {
+			existing++
+		}
+	}
+
+	switch existing {
+	case 3:
+		fmt.Printf("The keys in %s exist already, they were kept.\n", *dir)
+		return nil
+	case 0:
+	default:
+		return fmt.Errorf("only some of the keys exist in %s, restore the missing ones instead of generating new ones", *dir)
+	}
+
+	if err := oprfutils.GenerateAndSaveKeys(*dir, oprfutils.Suite{Name: *suite}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Generated the keys in %s, back them up with the database.\n", *dir)
+	return nil
+}
+
+// rotateKeys writes the AKE key pair of the next version next to the existing ones. The
+// older pairs stay, the accounts registered under them keep logging in until their
+// clients register again.
+func rotateKeys(args []string) error {
+	flags := flag.NewFlagSet("keys rotate", flag.ExitOnError)
+	dir := flags.String("dir", defaultKeysDir, "directory of the keys")
+	suite := flags.String("suite", oprfutils.SuiteP256, "opaque.suite of the server, the keys only work in it")
+	_ = flags.Parse(args)
+
+	version := 0
+	for {
+		_, err := os.Stat(filepath.Join(*dir, oprfutils.PrivateKeyFileVersion(version+1)))
+		if errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		version++
+	}
+
+	if version == 0 {
+		return fmt.Errorf("there are no keys in %s to rotate, run cpmadmin keys generate first", *dir)
+	}
+
+	if err := oprfutils.GenerateKeyPair(*dir, version+1, oprfutils.Suite{Name: *suite}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Generated the key pair of version %d in %s, back it up with the database.\n", version+1, *dir)
+	fmt.Printf("Set opaque.key_version (OPAQUE_KEY_VERSION) to %d and restart the server to use it.\n", version+1)
+	return nil
+}
+
+// sealKeys reads the keys like the file provider does and writes them to a keyfile
+// under KEYFILE_PASSPHRASE (seal) or to a file of transit ciphertexts (wrap).
+func sealKeys(ctx context.Context, action string, args []string) error {
+	flags := flag.NewFlagSet("keys "+action, flag.ExitOnError)
+	out := flags.String("out", "", "the file to write, it must not exist")
+	_ = flags.Parse(args)
+
+	if *out == "" {
+		return fmt.Errorf("usage: cpmadmin keys %s -out <file>", action)
+	}
+
+	conf, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	source, err := conf.GetFileKeyProvider()
+	if err != nil {
+		return err
+	}
+
+	secrets := map[string][]byte{}
+	for _, name := range keyprovider.Names(conf.GetOpaqueKeyVersion()) {
+		if secrets[name], err = source.Key(ctx, name); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	if action == "seal" {
+		if conf.Keys.KeyfilePassphrase == "" {
+			return errors.New("set KEYFILE_PASSPHRASE to the passphrase of the keyfile")
+		}
+		if err := keyprovider.SealKeyfile(*out, conf.Keys.KeyfilePassphrase, secrets); err != nil {
+			return err
+		}
+
+		fmt.Printf("Sealed the keys into %s, set keys.provider to keyfile and keys.keyfile_path to it.\n", *out)
+		return nil
+	}
+
+	if conf.Keys.TransitAddress == "" || conf.Keys.TransitToken == "" || conf.Keys.TransitKey == "" {
+		return errors.New("set VAULT_ADDR, VAULT_TOKEN and keys.transit_key to reach transit")
+	}
+	if err := keyprovider.SealTransitFile(ctx, conf.GetTransitClient(), *out, secrets); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrapped the keys into %s, set keys.provider to transit and keys.transit_secrets_path to it.\n", *out)
+	return nil
+}
+
+func runMigrate(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	_ = flags.Parse(args)
+
+	action := "up"
+	if flags.NArg() > 0 {
+		action = flags.Arg(0)
+	}
+
+	_, db, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch action {
+	case "up":
+		err = database.Migrate(db)
+	case "down":
+		err = database.MigrateDown(db)
+	case "status":
+	default:
+		return fmt.Errorf("unknown action %q, use up, down or status", action)
+	}
+	if err != nil {
+		return err
+	}
+
+	current, latest, err := database.Version(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("The database is at version %d, the latest migration is %d.\n", current, latest)
+	return nil
+}
+
+// runSeed loads the seed data the tests use, it only runs on a database without
+// accounts since the seed relies on the ids of a fresh one.
+func runSeed(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	_ = flags.Parse(args)
+
+	_, db, err := connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var hasAccounts bool
+	if err := db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM accounts)").Scan(&hasAccounts); err != nil {
+		return err
+	}
+	if hasAccounts {
+		return errors.New("the database has accounts already, the seed only loads into an empty one")
+	}
+
+	seed.CreateSeed(ctx, db)
+
+	fmt.Println("Loaded the seed.")
+	return nil
+}
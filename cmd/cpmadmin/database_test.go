@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/oprfutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateKeys(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		existing []string
+		errText  string
+	}{
+		{
+			name: "new directory",
+		},
+		{
+			name:     "every key exists",
+			existing: []string{oprfutils.OprfSeedFile, oprfutils.PrivateKeyFile, oprfutils.PublicKeyFile},
+		},
+		{
+			name:     "some keys exist",
+			existing: []string{oprfutils.OprfSeedFile},
+			errText:  "only some of the keys exist",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := filepath.Join(t.TempDir(), "keys")
+			require.NoError(t, os.Mkdir(dir, 0o700))
+			for _, name := range tc.existing {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("kept "+name), 0o600))
+			}
+
+			err := generateKeys([]string{"-dir", dir})
+			if tc.errText != "" {
+				require.ErrorContains(t, err, tc.errText)
+
+				// the key that exists is kept and the missing ones are not made up
+				_, err := os.Stat(filepath.Join(dir, oprfutils.PrivateKeyFile))
+				require.ErrorIs(t, err, os.ErrNotExist)
+				return
+			}
+			require.NoError(t, err)
+
+			for _, name := range []string{oprfutils.OprfSeedFile, oprfutils.PrivateKeyFile, oprfutils.PublicKeyFile} {
+				path := filepath.Join(dir, name)
+				info, err := os.Stat(path)
+				require.NoError(t, err)
+				require.Equal(t, os.FileMode(0o600), info.Mode().Perm(), name)
+
+				content, err := os.ReadFile(path)
+				require.NoError(t, err)
+				if len(tc.existing) > 0 {
+					require.Equal(t, "kept "+name, string(content))
+				} else {
+					require.NotEmpty(t, content)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateKeysCreatesPrivateDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "deployment", "keys")
+	require.NoError(t, generateKeys([]string{"-dir", dir, "-suite", oprfutils.SuiteP384}))
+
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+	// the keys are the ones of the suite
+	conf, err := oprfutils.Suite{Name: oprfutils.SuiteP384}.Configuration()
+	require.NoError(t, err)
+	publicKey, err := os.ReadFile(filepath.Join(dir, oprfutils.PublicKeyFile))
+	require.NoError(t, err)
+	server, err := conf.Server()
+	require.NoError(t, err)
+	_, err = server.Deserialize.DecodeAkePublicKey(publicKey)
+	require.NoError(t, err)
+}
+
+func TestRotateKeys(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.ErrorContains(t, rotateKeys([]string{"-dir", dir}), "there are no keys")
+
+	require.NoError(t, generateKeys([]string{"-dir", dir}))
+	seed, err := os.ReadFile(filepath.Join(dir, oprfutils.OprfSeedFile))
+	require.NoError(t, err)
+
+	for version := 2; version <= 3; version++ {
+		require.NoError(t, rotateKeys([]string{"-dir", dir}))
+
+		for _, name := range []string{oprfutils.PrivateKeyFileVersion(version), oprfutils.PublicKeyFileVersion(version)} {
+			info, err := os.Stat(filepath.Join(dir, name))
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0o600), info.Mode().Perm(), name)
+		}
+	}
+
+	// the OPRF seed is never replaced
+	rotated, err := os.ReadFile(filepath.Join(dir, oprfutils.OprfSeedFile))
+	require.NoError(t, err)
+	require.Equal(t, seed, rotated)
+}
+
+func TestRunKeysArguments(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name    string
+		args    []string
+		errText string
+	}{
+		{
+			name:    "unknown action",
+			args:    []string{"delete"},
+			errText: `unknown action "delete"`,
+		},
+		{
+			name:    "seal without a file",
+			args:    []string{"seal"},
+			errText: "usage: cpmadmin keys seal -out <file>",
+		},
+		{
+			name:    "wrap without a file",
+			args:    []string{"wrap"},
+			errText: "usage: cpmadmin keys wrap -out <file>",
+		},
+		{
+			name:    "unknown suite",
+			args:    []string{"generate", "-dir", "%TMP%", "-suite", "ristretto255"},
+			errText: "ristretto255",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := make([]string, len(tc.args))
+			for i, arg := range tc.args {
+				if arg == "%TMP%" {
+					arg = t.TempDir()
+				}
+				args[i] = arg
+			}
+
+			require.ErrorContains(t, runKeys(context.Background(), args), tc.errText)
+		})
+	}
+}
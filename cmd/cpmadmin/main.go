@@ -0,0 +1,67 @@
+// Command cpmadmin runs the operations of a deployment against the config of the
+// server, it reads config/config.yaml and .env from the working directory like the
+// server does:
+//
+//	cpmadmin config
+//	cpmadmin keys rotate
+//	cpmadmin migrate up
+//	cpmadmin accounts lock -for 24h j.doe
+//	cpmadmin rotate-totp
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+type command struct {
+	run     func(ctx context.Context, args []string) error
+	summary string
+}
+
+var commands = map[string]command{
+	"config":      {runConfig, "validate the config and the services it points to"},
+	"keys":        {runKeys, "generate or rotate the OPAQUE keys of the server, or seal them for a key provider"},
+	"migrate":     {runMigrate, "apply or roll back the migrations, or show their status"},
+	"seed":        {runSeed, "load the seed data into an empty database"},
+	"accounts":    {runAccounts, "list, lock or unlock accounts"},
+	"rotate-totp": {runRotateTOTP, "encrypt the TOTP secrets again with the active AES key"},
+}
+
+var commandOrder = []string{"config", "keys", "migrate", "seed", "accounts", "rotate-totp"}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	name, args := os.Args[1], os.Args[2:]
+	cmd, ok := commands[name]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := cmd.run(ctx, args); err != nil {
+		fmt.Fprintf(os.Stderr, "cpmadmin %s: %v\n", name, err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cpmadmin <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, name := range commandOrder {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "run cpmadmin <command> -h for the flags of a command")
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+
+	require.Len(t, commandOrder, len(commands))
+	for _, name := range commandOrder {
+		cmd, ok := commands[name]
+		require.True(t, ok, name)
+		require.NotNil(t, cmd.run, name)
+		require.NotEmpty(t, cmd.summary, name)
+	}
+	require.False(t, slices.Contains(commandOrder, ""))
+}
+
+// TestArguments covers the arguments that are refused before cpmadmin connects to
+// anything.
+func TestArguments(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name    string
+		command string
+		args    []string
+		errText string
+	}{
+		{
+			name:    "accounts without an action",
+			command: "accounts",
+			errText: "usage: cpmadmin accounts list|lock|unlock",
+		},
+		{
+			name:    "unknown accounts action",
+			command: "accounts",
+			args:    []string{"delete", "j.doe"},
+			errText: `unknown action "delete"`,
+		},
+		{
+			name:    "lock without a username",
+			command: "accounts",
+			args:    []string{"lock"},
+			errText: "usage: cpmadmin accounts lock",
+		},
+		{
+			name:    "lock of two usernames",
+			command: "accounts",
+			args:    []string{"lock", "j.doe", "jane"},
+			errText: "usage: cpmadmin accounts lock",
+		},
+		{
+			name:    "lock for no time",
+			command: "accounts",
+			args:    []string{"lock", "-for", "0s", "j.doe"},
+			errText: "usage: cpmadmin accounts lock",
+		},
+		{
+			name:    "lock for a negative time",
+			command: "accounts",
+			args:    []string{"lock", "-for", "-1h", "j.doe"},
+			errText: "usage: cpmadmin accounts lock",
+		},
+		{
+			name:    "unlock without a username",
+			command: "accounts",
+			args:    []string{"unlock"},
+			errText: "usage: cpmadmin accounts unlock",
+		},
+		{
+			name:    "empty batches",
+			command: "rotate-totp",
+			args:    []string{"-batch", "0"},
+			errText: "the batch size is at least 1",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require.ErrorContains(t, commands[tc.command].run(context.Background(), tc.args), tc.errText)
+		})
+	}
+}
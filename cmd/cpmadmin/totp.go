@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+)
+
+// runRotateTOTP encrypts the TOTP secrets again with the active AES key. Run it after
+// moving the old AES_KEY into AES_RETIRED_KEYS and setting a new AES_KEY and AES_KEY_ID,
+// the retired key can be dropped once it reports no failures.
+func runRotateTOTP(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("rotate-totp", flag.ExitOnError)
+	batchSize := flags.Int("batch", 100, "accounts read and updated at a time")
+	_ = flags.Parse(args)
+
+	if *batchSize < 1 {
+		return errors.New("the batch size is at least 1")
+	}
+
+	u, closeAll, err := openAdminUsecase(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+
+	rotated, failed, err := u.RotateTOTPSecrets(ctx, *batchSize)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Encrypted %d TOTP secrets with the active key.\n", rotated)
+	if failed > 0 {
+		return fmt.Errorf("%d TOTP secrets could not be decrypted with any key, the log names the accounts", failed)
+	}
+	return nil
+}
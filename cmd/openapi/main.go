@@ -0,0 +1,32 @@
+// Command openapi prints the OpenAPI document of the JSON API without a database, the
+// running app serves the same document at /api/v1/openapi.json.
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	accountRouter "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/router"
+	accountUsecase "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	vaultRouter "github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/delivery/http/router"
+	vaultUsecase "github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/usecase"
+)
+
+func main() {
+	conf := &config.Config{}
+	api := localHttp.API{Doc: localHttp.NewAPIDocument()}
+
+	api.Register(accountRouter.AuthAPIRoutes(accountUsecase.AuthUsecase{}, accountUsecase.AccessTokenUsecase{}, conf)...)
+	api.Register(accountRouter.PasswordAPIRoutes(accountUsecase.PasswordUsecase{})...)
+	api.Register(accountRouter.AccountAPIRoutes(accountUsecase.AccountUsecase{}, accountUsecase.GroupUsecase{}, conf)...)
+	api.Register(vaultRouter.VaultAPIRoutes(vaultUsecase.VaultItemUsecase{}, conf)...)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(api.Doc); err != nil {
+		log.Fatalf("encoding the document failed: %v", err)
+	}
+}
@@ -1,18 +1,30 @@
 package config
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/keyprovider"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/oprfutils"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/encrypt"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
 )
 
+const (
+	// defaultAESKeyID names AES_KEY when AES_KEY_ID is not set.
+	defaultAESKeyID = "1"
+
+	transitUnlockTimeout = 30 * time.Second
+)
+
 var (
 	config *Config
 	once   sync.Once
@@ -20,29 +32,42 @@ var (
 
 type (
 	Config struct {
-		APP    `yaml:"app"`
-		HTTP   `yaml:"http"`
-		DB     `yaml:"db"`
-		Redis  `yaml:"redis"`
-		Opaque `yaml:"opaque"`
+		APP      `yaml:"app"`
+		HTTP     `yaml:"http"`
+		DB       `yaml:"db"`
+		Redis    `yaml:"redis"`
+		Opaque   `yaml:"opaque"`
+		Security `yaml:"security"`
+		Mail     `yaml:"mail"`
+		Keys     `yaml:"keys"`
+
+		provider *providerCache
 	}
 
 	APP struct {
 		Name              string `env-required:"true" yaml:"name"`
 		Version           string `env-required:"true" yaml:"version"`
-		AESKey            string `env-required:"true" yaml:"aes_key" env:"AES_KEY"`
+		AESKey            string `yaml:"aes_key" env:"AES_KEY"`
+		AESKeyID          string `yaml:"aes_key_id" env:"AES_KEY_ID" env-default:"1"`
+		AESRetiredKeys    string `env:"AES_RETIRED_KEYS"`
 		RootPath          string
 		TemplatePath      string `env-required:"true" yaml:"template_path" env:"TEMPLATE_PATH"`
 		StaticPath        string `env-required:"true" yaml:"static_path" env:"STATIC_PATH"`
 		TwoFactorDuration int    `env-required:"true" yaml:"two_factor_duration" env:"TWO_FACTOR_DURATION"`
 		SecretKey         string `env-required:"true" yaml:"secret_key" env:"SECRET_KEY"`
+		SessionDuration   int    `env-required:"true" yaml:"session_duration" env:"SESSION_DURATION"`
 		DefaultPage       int    `env-required:"true" yaml:"default_page" env:"DEFAULT_PAGE"`
 		DefaultPageSize   int    `env-required:"true" yaml:"default_page_size" env:"DEFAULT_PAGE_SIZE"`
+		// GroupInvitationDays is how long an invitation to a group can be accepted.
+		GroupInvitationDays int `yaml:"group_invitation_days" env:"GROUP_INVITATION_DAYS" env-default:"7"`
 	}
 
 	HTTP struct {
 		Port string `env-required:"true" yaml:"port"`
 		Host string `env-required:"true" yaml:"host"`
+		// TrustedProxies are the addresses or CIDRs whose X-Forwarded-For is believed,
+		// the client IP the lockouts count against is the remote address otherwise.
+		TrustedProxies []string `yaml:"trusted_proxies" env:"TRUSTED_PROXIES" env-separator:","`
 	}
 
 	DB struct {
@@ -59,11 +84,72 @@ type (
 		PrivateKeyPath       string `env-required:"true" yaml:"private_key_path" env:"PRIVATE_KEY_PATH"`
 		OprfKeyPath          string `env-required:"true" yaml:"oprf_key_path" env:"ORFP_KEY_PATH"`
 		RegistrationDuration int    `env-required:"true" yaml:"registration_duration" env:"RegistrationDuration"`
+		// KeyVersion is the version of the AKE key pair new registrations use, the key
+		// pairs of the older versions sit next to PrivateKeyPath and PublicKeyPath.
+		KeyVersion int `yaml:"key_version" env:"OPAQUE_KEY_VERSION" env-default:"1"`
+		// Suite is the group OPAQUE runs in and KSF the key stretching function of the
+		// clients, both are served to the clients so the browser and cpm register alike.
+		Suite string    `yaml:"suite" env:"OPAQUE_SUITE" env-default:"P256-SHA256"`
+		KSF   OpaqueKSF `yaml:"ksf"`
+	}
+
+	// OpaqueKSF is one of argon2id, scrypt or identity with its parameters. The default
+	// is the Argon2id the accounts registered before it was configurable were stretched
+	// with, changing it locks those accounts out.
+	OpaqueKSF struct {
+		Name          string `yaml:"name" env:"OPAQUE_KSF" env-default:"argon2id"`
+		Argon2Time    uint32 `yaml:"argon2_time" env:"OPAQUE_ARGON2_TIME" env-default:"3"`
+		Argon2Memory  uint32 `yaml:"argon2_memory" env:"OPAQUE_ARGON2_MEMORY" env-default:"65536"`
+		Argon2Threads uint8  `yaml:"argon2_threads" env:"OPAQUE_ARGON2_THREADS" env-default:"4"`
+		ScryptN       int    `yaml:"scrypt_n" env:"OPAQUE_SCRYPT_N" env-default:"32768"`
+		ScryptR       int    `yaml:"scrypt_r" env:"OPAQUE_SCRYPT_R" env-default:"8"`
+		ScryptP       int    `yaml:"scrypt_p" env:"OPAQUE_SCRYPT_P" env-default:"1"`
+	}
+
+	Security struct {
+		MaxLoginAttempts     int `env-required:"true" yaml:"max_login_attempts" env:"MAX_LOGIN_ATTEMPTS"`
+		MaxIPAttempts        int `env-required:"true" yaml:"max_ip_attempts" env:"MAX_IP_ATTEMPTS"`
+		MaxTwoFactorAttempts int `env-required:"true" yaml:"max_two_factor_attempts" env:"MAX_TWO_FACTOR_ATTEMPTS"`
+		MaxGroupInvitations  int `yaml:"max_group_invitations" env:"MAX_GROUP_INVITATIONS" env-default:"20"`
+		AttemptWindow        int `env-required:"true" yaml:"attempt_window" env:"ATTEMPT_WINDOW"`
+		LockoutDuration      int `env-required:"true" yaml:"lockout_duration" env:"LOCKOUT_DURATION"`
+		MaxLockoutDuration   int `env-required:"true" yaml:"max_lockout_duration" env:"MAX_LOCKOUT_DURATION"`
+	}
+
+	// Mail picks the mailer by Driver, which is one of smtp, file or memory.
+	Mail struct {
+		Driver       string `env-required:"true" yaml:"driver" env:"MAIL_DRIVER"`
+		From         string `env-required:"true" yaml:"from" env:"MAIL_FROM"`
+		SMTPHost     string `yaml:"smtp_host" env:"MAIL_SMTP_HOST"`
+		SMTPPort     string `yaml:"smtp_port" env:"MAIL_SMTP_PORT"`
+		SMTPUsername string `env:"MAIL_SMTP_USERNAME"`
+		SMTPPassword string `env:"MAIL_SMTP_PASSWORD"`
+		Directory    string `yaml:"directory" env:"MAIL_DIRECTORY"`
+	}
+
+	// Keys picks where the server secrets come from, Provider is one of file, keyfile
+	// or transit. The file provider reads the OPAQUE keys from their paths and the AES
+	// key from AES_KEY.
+	Keys struct {
+		Provider           string `yaml:"provider" env:"KEY_PROVIDER" env-default:"file"`
+		KeyfilePath        string `yaml:"keyfile_path" env:"KEYFILE_PATH"`
+		KeyfilePassphrase  string `env:"KEYFILE_PASSPHRASE"`
+		TransitAddress     string `yaml:"transit_address" env:"VAULT_ADDR"`
+		TransitToken       string `env:"VAULT_TOKEN"`
+		TransitMount       string `yaml:"transit_mount" env:"TRANSIT_MOUNT"`
+		TransitKey         string `yaml:"transit_key" env:"TRANSIT_KEY"`
+		TransitSecretsPath string `yaml:"transit_secrets_path" env:"TRANSIT_SECRETS_PATH"`
+	}
+
+	providerCache struct {
+		once     sync.Once
+		provider keyprovider.KeyProvider
+		err      error
 	}
 )
 
 func newConfig() (*Config, error) {
-	conf := &Config{}
+	conf := &Config{provider: &providerCache{}}
 	if _, err := os.Stat(".env"); !errors.Is(err, os.ErrNotExist) {
 		err := godotenv.Load(".env")
 		if err != nil {
@@ -101,7 +187,13 @@ func GetConfig() *Config {
 }
 
 func GetTestConfig() *Config {
-	return &Config{Opaque: createTestCodes()}
+	return &Config{
+		APP:      APP{GroupInvitationDays: 7},
+		Opaque:   createTestCodes(),
+		Security: createTestSecurity(),
+		Mail:     createTestMail(),
+		provider: &providerCache{},
+	}
 }
 
 func (c *Config) GetAESSecretKey() ([]byte, error) {
@@ -109,8 +201,140 @@ func (c *Config) GetAESSecretKey() ([]byte, error) {
 		return base64.StdEncoding.DecodeString("syaZbz9ca3SZ51GUdyx3F//e89Hgfr2XuHHn4VdnMQU=")
 	}
 
-	keyBytes, err := base64.StdEncoding.DecodeString(c.APP.AESKey)
-	return keyBytes, err
+	provider, err := c.GetKeyProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.Key(context.Background(), keyprovider.AESKey)
+}
+
+// GetKeyProvider builds the provider of c.Keys once, the keyfile and transit providers
+// unlock the secrets then.
+func (c *Config) GetKeyProvider() (keyprovider.KeyProvider, error) {
+	if c.provider == nil {
+		return c.newKeyProvider()
+	}
+
+	c.provider.once.Do(func() {
+		c.provider.provider, c.provider.err = c.newKeyProvider()
+	})
+	return c.provider.provider, c.provider.err
+}
+
+// OpenKeyProvider builds the provider of c.Keys again, unlike GetKeyProvider it sees the
+// secrets that changed since the provider was first built.
+func (c *Config) OpenKeyProvider() (keyprovider.KeyProvider, error) {
+	return c.newKeyProvider()
+}
+
+func (c *Config) newKeyProvider() (keyprovider.KeyProvider, error) {
+	switch c.Keys.Provider {
+	case keyprovider.ProviderFile, "":
+		return c.GetFileKeyProvider()
+	case keyprovider.ProviderKeyfile:
+		return keyprovider.OpenKeyfile(c.Keys.KeyfilePath, c.Keys.KeyfilePassphrase)
+	case keyprovider.ProviderTransit:
+		ctx, cancel := context.WithTimeout(context.Background(), transitUnlockTimeout)
+		defer cancel()
+
+		return keyprovider.OpenTransitFile(ctx, c.GetTransitClient(), c.Keys.TransitSecretsPath)
+	default:
+		return nil, fmt.Errorf("unknown key provider %q", c.Keys.Provider)
+	}
+}
+
+// GetFileKeyProvider reads the secrets from the key paths and AES_KEY whatever the
+// configured provider is, the secrets are sealed for the other providers from there.
+func (c *Config) GetFileKeyProvider() (keyprovider.KeyProvider, error) {
+	inline := map[string][]byte{}
+	if c.APP.AESKey != "" {
+		key, err := base64.StdEncoding.DecodeString(c.APP.AESKey)
+		if err != nil {
+			return nil, fmt.Errorf("aes key: %w", err)
+		}
+		inline[keyprovider.AESKey] = key
+	}
+
+	paths := map[string]string{
+		keyprovider.OprfSeed:         c.Opaque.OprfKeyPath,
+		keyprovider.ServerPrivateKey: c.Opaque.PrivateKeyPath,
+		keyprovider.ServerPublicKey:  c.Opaque.PublicKeyPath,
+	}
+	for version := 2; version <= c.GetOpaqueKeyVersion(); version++ {
+		paths[keyprovider.ServerPrivateKeyName(version)] = filepath.Join(
+			filepath.Dir(c.Opaque.PrivateKeyPath), oprfutils.PrivateKeyFileVersion(version))
+		paths[keyprovider.ServerPublicKeyName(version)] = filepath.Join(
+			filepath.Dir(c.Opaque.PublicKeyPath), oprfutils.PublicKeyFileVersion(version))
+	}
+
+	return keyprovider.NewFileProvider(paths, inline), nil
+}
+
+// GetOpaqueKeyVersion is the version of the AKE key pair new registrations use.
+func (c *Config) GetOpaqueKeyVersion() int {
+	return max(c.Opaque.KeyVersion, 1)
+}
+
+// GetOpaqueSuite is the suite of the config, the default one when it names none.
+func (c *Config) GetOpaqueSuite() oprfutils.Suite {
+	if c.Opaque.Suite == "" {
+		return oprfutils.DefaultSuite()
+	}
+
+	ksf := c.Opaque.KSF
+	suite := oprfutils.Suite{Name: c.Opaque.Suite, KSF: oprfutils.KSF{Name: ksf.Name}}
+	switch ksf.Name {
+	case oprfutils.KSFArgon2id:
+		suite.KSF.Argon2Time, suite.KSF.Argon2Memory, suite.KSF.Argon2Threads = ksf.Argon2Time, ksf.Argon2Memory, ksf.Argon2Threads
+	case oprfutils.KSFScrypt:
+		suite.KSF.ScryptN, suite.KSF.ScryptR, suite.KSF.ScryptP = ksf.ScryptN, ksf.ScryptR, ksf.ScryptP
+	}
+
+	return suite
+}
+
+func (c *Config) GetTransitClient() keyprovider.TransitClient {
+	return keyprovider.NewTransitClient(c.Keys.TransitAddress, c.Keys.TransitToken, c.Keys.TransitMount, c.Keys.TransitKey)
+}
+
+// GetAESKeyring puts the AES key of the key provider under AES_KEY_ID as the active key
+// next to the retired keys of AES_RETIRED_KEYS, which is a comma separated list of
+// id:base64 pairs.
+func (c *Config) GetAESKeyring() (*encrypt.Keyring, error) {
+	active, err := c.GetAESSecretKey()
+	if err != nil {
+		return nil, fmt.Errorf("aes key: %w", err)
+	}
+
+	activeID := c.APP.AESKeyID
+	if activeID == "" {
+		activeID = defaultAESKeyID
+	}
+
+	keys := map[string][]byte{activeID: active}
+	for _, pair := range strings.Split(c.APP.AESRetiredKeys, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		id, encoded, found := strings.Cut(pair, ":")
+		if !found {
+			return nil, fmt.Errorf("retired aes key %q is not an id:base64 pair", pair)
+		}
+		if _, exist := keys[id]; exist {
+			return nil, fmt.Errorf("aes key id %q is used twice", id)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("retired aes key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+
+	return encrypt.NewKeyring(activeID, keys)
 }
 
 func InTestMode() bool {
@@ -143,15 +367,34 @@ func createTestCodes() Opaque {
 	}
 
 	keysPath := fmt.Sprint(rootPath, "/internal/infrastructure/opaque/keys/test/")
-	err = oprfutils.GenerateAndSaveKeys(keysPath)
+	err = oprfutils.GenerateAndSaveKeys(keysPath, oprfutils.DefaultSuite())
 	if err != nil {
 		panic(err)
 	}
 
 	return Opaque{
 		ServerID:       "something",
-		PublicKeyPath:  fmt.Sprint(keysPath, "server_public.bin"),
-		PrivateKeyPath: fmt.Sprint(keysPath, "server_private.bin"),
-		OprfKeyPath:    fmt.Sprint(keysPath, "oprf_seed.bin"),
+		PublicKeyPath:  fmt.Sprint(keysPath, oprfutils.PublicKeyFile),
+		PrivateKeyPath: fmt.Sprint(keysPath, oprfutils.PrivateKeyFile),
+		OprfKeyPath:    fmt.Sprint(keysPath, oprfutils.OprfSeedFile),
+	}
+}
+
+func createTestSecurity() Security {
+	return Security{
+		MaxLoginAttempts:     5,
+		MaxIPAttempts:        20,
+		MaxTwoFactorAttempts: 3,
+		MaxGroupInvitations:  20,
+		AttemptWindow:        15,
+		LockoutDuration:      1,
+		MaxLockoutDuration:   60,
+	}
+}
+
+func createTestMail() Mail {
+	return Mail{
+		Driver: "memory",
+		From:   "no-reply@example.com",
 	}
 }
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/keyprovider"
+)
+
+// Load reads the config like GetConfig does, but returns the error instead of
+// panicking, so a command can report it.
+func Load() (*Config, error) {
+	return newConfig()
+}
+
+// Validate checks the values the config loader can not, it returns every problem it
+// finds rather than the first one.
+func (c *Config) Validate() []error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.APP.SecretKey != "", "app.secret_key must be set")
+	check(c.APP.TwoFactorDuration > 0, "app.two_factor_duration must be positive")
+	check(c.APP.SessionDuration > 0, "app.session_duration must be positive")
+	check(c.APP.DefaultPage > 0, "app.default_page must be positive")
+	check(c.APP.DefaultPageSize > 0, "app.default_page_size must be positive")
+	check(c.APP.GroupInvitationDays > 0, "app.group_invitation_days must be positive")
+
+	port, err := strconv.Atoi(c.HTTP.Port)
+	check(err == nil && port > 0 && port < 65536, "http.port %q is not a port", c.HTTP.Port)
+	for _, proxy := range c.HTTP.TrustedProxies {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		check(net.ParseIP(proxy) != nil || cidrErr == nil, "http.trusted_proxies: %q is not an address or a CIDR", proxy)
+	}
+
+	check(c.Opaque.ServerID != "", "opaque.server_id must be set")
+	check(c.Opaque.RegistrationDuration > 0, "opaque.registration_duration must be positive")
+	check(c.Opaque.KeyVersion > 0, "opaque.key_version must be positive")
+	if err := c.GetOpaqueSuite().Validate(); err != nil {
+		check(false, "opaque.suite: %v", err)
+	}
+
+	switch c.Keys.Provider {
+	case keyprovider.ProviderFile:
+		check(c.APP.AESKey != "", "app.aes_key must be set for the file key provider")
+		for _, key := range []struct{ name, path string }{
+			{"opaque.public_key_path", c.Opaque.PublicKeyPath},
+			{"opaque.private_key_path", c.Opaque.PrivateKeyPath},
+			{"opaque.oprf_key_path", c.Opaque.OprfKeyPath},
+		} {
+			info, err := os.Stat(key.path)
+			check(err == nil && info.Size() > 0, "%s: %s is missing or empty", key.name, key.path)
+		}
+	case keyprovider.ProviderKeyfile:
+		check(c.Keys.KeyfilePath != "" && c.Keys.KeyfilePassphrase != "",
+			"keys.keyfile_path and KEYFILE_PASSPHRASE must be set for the keyfile provider")
+	case keyprovider.ProviderTransit:
+		check(c.Keys.TransitAddress != "" && c.Keys.TransitToken != "" && c.Keys.TransitKey != "" && c.Keys.TransitSecretsPath != "",
+			"VAULT_ADDR, VAULT_TOKEN, keys.transit_key and keys.transit_secrets_path must be set for the transit provider")
+	default:
+		check(false, "keys.provider %q is not one of file, keyfile or transit", c.Keys.Provider)
+	}
+
+	if provider, err := c.GetKeyProvider(); err != nil {
+		check(false, "the key provider does not open: %v", err)
+	} else {
+		for _, name := range keyprovider.Names(c.GetOpaqueKeyVersion()) {
+			key, err := provider.Key(context.Background(), name)
+			check(err == nil && len(key) > 0, "the key provider has no %s", name)
+		}
+
+		key, err := c.GetAESSecretKey()
+		check(err != nil || len(key) == 32, "the aes key must be 32 bytes")
+		_, err = c.GetAESKeyring()
+		check(err == nil, "the aes keyring is invalid: %v", err)
+	}
+
+	check(c.Security.MaxLoginAttempts > 0, "security.max_login_attempts must be positive")
+	check(c.Security.MaxIPAttempts > 0, "security.max_ip_attempts must be positive")
+	check(c.Security.MaxTwoFactorAttempts > 0, "security.max_two_factor_attempts must be positive")
+	check(c.Security.MaxGroupInvitations > 0, "security.max_group_invitations must be positive")
+	check(c.Security.AttemptWindow > 0, "security.attempt_window must be positive")
+	check(c.Security.LockoutDuration > 0, "security.lockout_duration must be positive")
+	check(c.Security.MaxLockoutDuration >= c.Security.LockoutDuration,
+		"security.max_lockout_duration must not be shorter than security.lockout_duration")
+
+	switch c.Mail.Driver {
+	case "smtp":
+		check(c.Mail.SMTPHost != "" && c.Mail.SMTPPort != "", "mail.smtp_host and mail.smtp_port must be set for the smtp driver")
+	case "file":
+		check(c.Mail.Directory != "", "mail.directory must be set for the file driver")
+	case "memory":
+	default:
+		check(false, "mail.driver %q is not one of smtp, file or memory", c.Mail.Driver)
+	}
+
+	return errs
+}
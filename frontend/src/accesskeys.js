@@ -0,0 +1,71 @@
+// Every account that takes part in emergency access has an ECDH P-256 key pair. The
+// private key is stored wrapped with the vault key, the public key lets a grantor wrap
+// their vault key to the grantee. A wrapped key is ephemeral public key || nonce || ciphertext.
+const wrapInfo = new TextEncoder().encode("cool-password-manager emergency access")
+const curve = { name: "ECDH", namedCurve: "P-256" }
+const publicKeyLength = 65
+const nonceLength = 12
+
+async function encrypt(key, plaintext) {
+    const nonce = crypto.getRandomValues(new Uint8Array(nonceLength))
+    const ciphertext = await crypto.subtle.encrypt({ name: "AES-GCM", iv: nonce }, key, plaintext)
+
+    const sealed = new Uint8Array(nonceLength + ciphertext.byteLength)
+    sealed.set(nonce)
+    sealed.set(new Uint8Array(ciphertext), nonceLength)
+    return sealed
+}
+
+async function decrypt(key, sealed) {
+    const nonce = sealed.slice(0, nonceLength)
+    const ciphertext = sealed.slice(nonceLength)
+
+    return new Uint8Array(await crypto.subtle.decrypt({ name: "AES-GCM", iv: nonce }, key, ciphertext))
+}
+
+function importVaultKey(vaultKey) {
+    return crypto.subtle.importKey("raw", vaultKey, "AES-GCM", false, ["encrypt", "decrypt"])
+}
+
+async function deriveSharedKey(privateKey, publicKey) {
+    const secret = await crypto.subtle.deriveBits({ name: "ECDH", public: publicKey }, privateKey, 256)
+    const material = await crypto.subtle.importKey("raw", secret, "HKDF", false, ["deriveKey"])
+
+    return crypto.subtle.deriveKey(
+        { name: "HKDF", hash: "SHA-256", salt: new Uint8Array(), info: wrapInfo },
+        material,
+        { name: "AES-GCM", length: 256 },
+        false,
+        ["encrypt", "decrypt"],
+    )
+}
+
+export async function generateKeyPair(vaultKey) {
+    const pair = await crypto.subtle.generateKey(curve, true, ["deriveBits"])
+    const publicKey = new Uint8Array(await crypto.subtle.exportKey("raw", pair.publicKey))
+    const privateKey = new Uint8Array(await crypto.subtle.exportKey("pkcs8", pair.privateKey))
+
+    const encryptedPrivateKey = await encrypt(await importVaultKey(vaultKey), privateKey)
+    return { publicKey, encryptedPrivateKey }
+}
+
+export async function wrapToPublicKey(publicKeyBytes, vaultKey) {
+    const publicKey = await crypto.subtle.importKey("raw", publicKeyBytes, curve, false, [])
+    const ephemeral = await crypto.subtle.generateKey(curve, true, ["deriveBits"])
+    const ephemeralPublicKey = new Uint8Array(await crypto.subtle.exportKey("raw", ephemeral.publicKey))
+
+    const sealed = await encrypt(await deriveSharedKey(ephemeral.privateKey, publicKey), vaultKey)
+
+    const wrapped = new Uint8Array(publicKeyLength + sealed.length)
+    wrapped.set(ephemeralPublicKey)
+    wrapped.set(sealed, publicKeyLength)
+    return wrapped
+}
+
+export async function unwrapWithPrivateKey(vaultKey, encryptedPrivateKey, wrapped) {
+    const privateKeyBytes = await decrypt(await importVaultKey(vaultKey), encryptedPrivateKey)
+    const privateKey = await crypto.subtle.importKey("pkcs8", privateKeyBytes, curve, false, ["deriveBits"])
+    const ephemeralPublicKey = await crypto.subtle.importKey("raw", wrapped.slice(0, publicKeyLength), curve, false, [])
+
+    return decrypt(await deriveSharedKey(privateKey, ephemeralPublicKey), wrapped.slice(publicKeyLength))
+}
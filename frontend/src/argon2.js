@@ -0,0 +1,323 @@
+// Argon2id (RFC 9106) over BLAKE2b (RFC 7693), synchronous since opaque-ts hardens the
+// OPRF output without awaiting. It accepts the empty salt bytemare/opaque stretches
+// with, which the Argon2 libraries of npm refuse. The 64-bit words are kept as
+// little-endian pairs of 32-bit halves.
+
+const blake2bIV = new Uint32Array([
+    0xf3bcc908, 0x6a09e667, 0x84caa73b, 0xbb67ae85, 0xfe94f82b, 0x3c6ef372, 0x5f1d36f1, 0xa54ff53a,
+    0xade682d1, 0x510e527f, 0x2b3e6c1f, 0x9b05688c, 0xfb41bd6b, 0x1f83d9ab, 0x137e2179, 0x5be0cd19,
+])
+
+const sigma = [
+    [0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15],
+    [14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3],
+    [11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4],
+    [7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8],
+    [9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13],
+    [2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9],
+    [12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11],
+    [13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10],
+    [6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5],
+    [10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0],
+]
+
+// add64 adds the word at j of b to the word at i of a.
+function add64(a, i, b, j) {
+    const lo = a[i] + b[j]
+    a[i + 1] = a[i + 1] + b[j + 1] + (lo > 0xffffffff ? 1 : 0)
+    a[i] = lo
+}
+
+// xorRotr64 sets the word at i to (word at i ^ word at j) rotated right by n bits.
+function xorRotr64(v, i, j, n) {
+    let lo = v[i] ^ v[j]
+    let hi = v[i + 1] ^ v[j + 1]
+    if (n >= 32) {
+        [lo, hi] = [hi, lo]
+        n -= 32
+    }
+    if (n > 0) {
+        [lo, hi] = [(lo >>> n) | (hi << (32 - n)), (hi >>> n) | (lo << (32 - n))]
+    }
+    v[i] = lo
+    v[i + 1] = hi
+}
+
+function blake2bMix(v, m, a, b, c, d, x, y) {
+    add64(v, a, v, b)
+    add64(v, a, m, x)
+    xorRotr64(v, d, a, 32)
+    add64(v, c, v, d)
+    xorRotr64(v, b, c, 24)
+    add64(v, a, v, b)
+    add64(v, a, m, y)
+    xorRotr64(v, d, a, 16)
+    add64(v, c, v, d)
+    xorRotr64(v, b, c, 63)
+}
+
+function blake2bCompress(h, block, counter, last) {
+    const v = new Uint32Array(32)
+    const m = new Uint32Array(32)
+    v.set(h)
+    v.set(blake2bIV, 16)
+    v[24] ^= counter
+    v[25] ^= Math.floor(counter / 0x100000000)
+    if (last) {
+        v[28] = ~v[28]
+        v[29] = ~v[29]
+    }
+    for (let i = 0; i < 32; i++) {
+        m[i] = block[4 * i] | (block[4 * i + 1] << 8) | (block[4 * i + 2] << 16) | (block[4 * i + 3] << 24)
+    }
+
+    for (let round = 0; round < 12; round++) {
+        const s = sigma[round % 10]
+        blake2bMix(v, m, 0, 8, 16, 24, 2 * s[0], 2 * s[1])
+        blake2bMix(v, m, 2, 10, 18, 26, 2 * s[2], 2 * s[3])
+        blake2bMix(v, m, 4, 12, 20, 28, 2 * s[4], 2 * s[5])
+        blake2bMix(v, m, 6, 14, 22, 30, 2 * s[6], 2 * s[7])
+        blake2bMix(v, m, 0, 10, 20, 30, 2 * s[8], 2 * s[9])
+        blake2bMix(v, m, 2, 12, 22, 24, 2 * s[10], 2 * s[11])
+        blake2bMix(v, m, 4, 14, 16, 26, 2 * s[12], 2 * s[13])
+        blake2bMix(v, m, 6, 8, 18, 28, 2 * s[14], 2 * s[15])
+    }
+
+    for (let i = 0; i < 16; i++) {
+        h[i] ^= v[i] ^ v[i + 16]
+    }
+}
+
+// blake2b is the unkeyed BLAKE2b of the input with an output of length bytes.
+export function blake2b(input, length) {
+    const h = new Uint32Array(blake2bIV)
+    h[0] ^= 0x01010000 ^ length
+
+    const block = new Uint8Array(128)
+    let offset = 0
+    while (input.length - offset > 128) {
+        blake2bCompress(h, input.subarray(offset, offset + 128), offset + 128, false)
+        offset += 128
+    }
+    block.set(input.subarray(offset))
+    blake2bCompress(h, block, input.length, true)
+
+    const out = new Uint8Array(length)
+    for (let i = 0; i < length; i++) {
+        out[i] = h[i >> 2] >>> (8 * (i & 3))
+    }
+    return out
+}
+
+function le32(n) {
+    return new Uint8Array([n, n >>> 8, n >>> 16, n >>> 24])
+}
+
+function concat(...parts) {
+    const out = new Uint8Array(parts.reduce((length, part) => length + part.length, 0))
+    let offset = 0
+    for (const part of parts) {
+        out.set(part, offset)
+        offset += part.length
+    }
+    return out
+}
+
+// hashLong is the variable length hash H' of Argon2.
+function hashLong(length, input) {
+    input = concat(le32(length), input)
+    if (length <= 64) {
+        return blake2b(input, length)
+    }
+
+    const out = new Uint8Array(length)
+    const r = Math.ceil(length / 32) - 2
+    let v = blake2b(input, 64)
+    out.set(v.subarray(0, 32))
+    for (let i = 1; i < r; i++) {
+        v = blake2b(v, 64)
+        out.set(v.subarray(0, 32), 32 * i)
+    }
+    out.set(blake2b(v, length - 32 * r), 32 * r)
+    return out
+}
+
+// mulHi is the upper 32 bits of the 64-bit product of two 32-bit numbers.
+function mulHi(a, b) {
+    const al = a & 0xffff, ah = a >>> 16, bl = b & 0xffff, bh = b >>> 16
+    const lh = al * bh, hl = ah * bl
+    const mid = Math.floor((al * bl) / 0x10000) + (lh & 0xffff) + (hl & 0xffff)
+    return ah * bh + Math.floor(lh / 0x10000) + Math.floor(hl / 0x10000) + Math.floor(mid / 0x10000)
+}
+
+// blaMka sets the word at i to itself + the word at j + 2 * their lower halves.
+function blaMka(v, i, j) {
+    const xl = v[i], yl = v[j]
+    let pl = Math.imul(xl, yl) >>> 0
+    let ph = mulHi(xl, yl)
+    ph = ((ph << 1) | (pl >>> 31)) >>> 0
+    pl = (pl << 1) >>> 0
+
+    const lo = xl + yl + pl
+    v[i + 1] = v[i + 1] + v[j + 1] + ph + Math.floor(lo / 0x100000000)
+    v[i] = lo
+}
+
+function argon2Mix(v, a, b, c, d) {
+    blaMka(v, a, b)
+    xorRotr64(v, d, a, 32)
+    blaMka(v, c, d)
+    xorRotr64(v, b, c, 24)
+    blaMka(v, a, b)
+    xorRotr64(v, d, a, 16)
+    blaMka(v, c, d)
+    xorRotr64(v, b, c, 63)
+}
+
+// permute is the permutation P over the 16 words of the block at the offsets.
+function permute(v, o) {
+    argon2Mix(v, o[0], o[4], o[8], o[12])
+    argon2Mix(v, o[1], o[5], o[9], o[13])
+    argon2Mix(v, o[2], o[6], o[10], o[14])
+    argon2Mix(v, o[3], o[7], o[11], o[15])
+    argon2Mix(v, o[0], o[5], o[10], o[15])
+    argon2Mix(v, o[1], o[6], o[11], o[12])
+    argon2Mix(v, o[2], o[7], o[8], o[13])
+    argon2Mix(v, o[3], o[4], o[9], o[14])
+}
+
+// The offsets of the words of the rows and columns of a block, in 32-bit halves.
+const rows = [], columns = []
+for (let i = 0; i < 8; i++) {
+    const row = [], column = []
+    for (let j = 0; j < 16; j++) {
+        row.push(2 * (16 * i + j))
+        column.push(2 * (2 * i + 16 * (j >> 1) + (j & 1)))
+    }
+    rows.push(row)
+    columns.push(column)
+}
+
+const blockWords = 256
+const r = new Uint32Array(blockWords)
+const q = new Uint32Array(blockWords)
+
+// compress writes G(x, y) to out, or xors it into out.
+function compress(memory, out, x, y, xor) {
+    for (let i = 0; i < blockWords; i++) {
+        r[i] = memory[x + i] ^ memory[y + i]
+    }
+    q.set(r)
+    for (const row of rows) {
+        permute(q, row)
+    }
+    for (const column of columns) {
+        permute(q, column)
+    }
+    for (let i = 0; i < blockWords; i++) {
+        memory[out + i] = (xor ? memory[out + i] : 0) ^ q[i] ^ r[i]
+    }
+}
+
+function bytesToWords(bytes, words, offset) {
+    for (let i = 0; i < bytes.length / 4; i++) {
+        words[offset + i] = bytes[4 * i] | (bytes[4 * i + 1] << 8) | (bytes[4 * i + 2] << 16) | (bytes[4 * i + 3] << 24)
+    }
+}
+
+function wordsToBytes(words) {
+    const bytes = new Uint8Array(4 * words.length)
+    for (let i = 0; i < bytes.length; i++) {
+        bytes[i] = words[i >> 2] >>> (8 * (i & 3))
+    }
+    return bytes
+}
+
+// argon2id derives length bytes of the password like golang.org/x/crypto/argon2.IDKey
+// does, memory is in KiB.
+export function argon2id(password, salt, { time, memory, threads, length }) {
+    const h0 = blake2b(concat(
+        le32(threads), le32(length), le32(memory), le32(time), le32(0x13), le32(2),
+        le32(password.length), password, le32(salt.length), salt, le32(0), le32(0),
+    ), 64)
+
+    const blocks = Math.max(Math.floor(memory / (4 * threads)) * 4 * threads, 8 * threads)
+    const laneLength = blocks / threads
+    const segmentLength = laneLength / 4
+    const mem = new Uint32Array(blocks * blockWords)
+    const offset = (lane, index) => (lane * laneLength + index) * blockWords
+
+    for (let lane = 0; lane < threads; lane++) {
+        bytesToWords(hashLong(1024, concat(h0, le32(0), le32(lane))), mem, offset(lane, 0))
+        bytesToWords(hashLong(1024, concat(h0, le32(1), le32(lane))), mem, offset(lane, 1))
+    }
+
+    // the address blocks of the data independent passes, zero is G's other input
+    const scratch = new Uint32Array(4 * blockWords)
+    const zero = 0, input = blockWords, address = 2 * blockWords, tmp = 3 * blockWords
+
+    for (let pass = 0; pass < time; pass++) {
+        for (let slice = 0; slice < 4; slice++) {
+            const independent = pass === 0 && slice < 2
+            for (let lane = 0; lane < threads; lane++) {
+                if (independent) {
+                    scratch.fill(0)
+                    scratch.set([pass, 0, lane, 0, slice, 0, blocks, 0, time, 0, 2, 0], input)
+                }
+                const nextAddresses = () => {
+                    scratch[input + 12]++
+                    compress(scratch, tmp, zero, input, false)
+                    compress(scratch, address, zero, tmp, false)
+                }
+
+                let start = 0
+                if (pass === 0 && slice === 0) {
+                    start = 2
+                    if (independent) {
+                        nextAddresses()
+                    }
+                }
+
+                for (let i = start; i < segmentLength; i++) {
+                    const index = slice * segmentLength + i
+                    const prev = offset(lane, index === 0 ? laneLength - 1 : index - 1)
+
+                    let j1, j2
+                    if (independent) {
+                        if (i % 128 === 0) {
+                            nextAddresses()
+                        }
+                        j1 = scratch[address + 2 * (i % 128)]
+                        j2 = scratch[address + 2 * (i % 128) + 1]
+                    } else {
+                        j1 = mem[prev]
+                        j2 = mem[prev + 1]
+                    }
+
+                    const refLane = pass === 0 && slice === 0 ? lane : j2 % threads
+                    const sameLane = refLane === lane
+                    let area
+                    if (pass === 0) {
+                        area = slice * segmentLength + (sameLane ? i - 1 : (i === 0 ? -1 : 0))
+                    } else {
+                        area = laneLength - segmentLength + (sameLane ? i - 1 : (i === 0 ? -1 : 0))
+                    }
+                    const relative = area - 1 - mulHi(area, mulHi(j1, j1))
+                    const startPosition = pass === 0 || slice === 3 ? 0 : (slice + 1) * segmentLength
+                    const refIndex = (startPosition + relative) % laneLength
+
+                    compress(mem, offset(lane, index), prev, offset(refLane, refIndex), pass > 0)
+                }
+            }
+        }
+    }
+
+    const final = mem.slice(offset(0, laneLength - 1), offset(0, laneLength - 1) + blockWords)
+    for (let lane = 1; lane < threads; lane++) {
+        const last = offset(lane, laneLength - 1)
+        for (let i = 0; i < blockWords; i++) {
+            final[i] ^= mem[last + i]
+        }
+    }
+    return hashLong(length, wordsToBytes(final))
+}
@@ -0,0 +1,71 @@
+import { OpaqueClientWrapper } from "./opaque.js"
+import { base64ToBytes, uint8ArrayToBase64 } from "./utils.js"
+import { generateVaultKey, unwrapVaultKey, wrapVaultKey } from "./vaultkey.js"
+
+const form = document.getElementById("changePasswordForm");
+const errBox = document.getElementById("errorBox");
+
+async function postJSON(url, body) {
+    const res = await fetch(url, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body),
+    });
+
+    const data = await res.json();
+    if (!res.ok) {
+        throw new Error(data.message);
+    }
+    return data;
+}
+
+form.addEventListener("submit", async (e) => {
+    e.preventDefault();
+    errBox.innerHTML = "";
+
+    const username = form.dataset.username;
+    const currentPassword = form.currentPassword.value;
+    const newPassword = form.newPassword.value;
+
+    if (newPassword !== form.confirmPassword.value) {
+        errBox.innerHTML = "The new passwords do not match.";
+        return;
+    }
+
+    try {
+        // prove the current password with a fresh login
+        const login = new OpaqueClientWrapper();
+        const ke1 = await login.loginInit(currentPassword);
+
+        const initData = await postJSON(form.dataset.initUrl, { ke1: uint8ArrayToBase64(ke1) });
+        const { ke3, exportKey: oldExportKey } = await login.loginFinish(base64ToBytes(initData.ke2), username);
+
+        // register the new password
+        const registration = new OpaqueClientWrapper();
+        const registrationRequest = await registration.registerInit(newPassword);
+
+        const verifyData = await postJSON(form.dataset.verifyUrl, {
+            changeID: initData.changeID,
+            ke3: uint8ArrayToBase64(ke3),
+            registrationRequest: uint8ArrayToBase64(registrationRequest),
+        });
+
+        const vaultKey = verifyData.encryptedVaultKey
+            ? await unwrapVaultKey(oldExportKey, base64ToBytes(verifyData.encryptedVaultKey))
+            : generateVaultKey();
+
+        const { record, exportKey: newExportKey } = await registration.registerFinish(base64ToBytes(verifyData.record), username);
+        const encryptedVaultKey = await wrapVaultKey(newExportKey, vaultKey);
+
+        await postJSON(form.dataset.finalUrl, {
+            changeID: initData.changeID,
+            registrationRecord: uint8ArrayToBase64(record),
+            encryptedVaultKey: uint8ArrayToBase64(encryptedVaultKey),
+        });
+
+        window.location.href = form.dataset.successUrl;
+    } catch (err) {
+        console.error(err);
+        errBox.innerHTML = err.message || "Changing the password failed. See console for details.";
+    }
+});
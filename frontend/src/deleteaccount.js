@@ -0,0 +1,53 @@
+import { OpaqueClientWrapper } from "./opaque.js"
+import { base64ToBytes, uint8ArrayToBase64 } from "./utils.js"
+
+const form = document.getElementById("deleteAccountForm");
+const errBox = document.getElementById("errorBox");
+
+async function postJSON(url, body) {
+    const res = await fetch(url, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body),
+    });
+
+    const data = await res.json();
+    if (!res.ok) {
+        throw new Error(data.message);
+    }
+    return data;
+}
+
+form.addEventListener("submit", async (e) => {
+    e.preventDefault();
+    errBox.innerHTML = "";
+
+    if (!confirm("Delete your account and every item in your vault?")) {
+        return;
+    }
+
+    // the new owner of every owned group, 0 deletes the group
+    const successors = {};
+    form.querySelectorAll("select[data-group-id]").forEach((select) => {
+        successors[select.dataset.groupId] = Number(select.value);
+    });
+
+    try {
+        const login = new OpaqueClientWrapper();
+        const ke1 = await login.loginInit(form.password.value);
+
+        const initData = await postJSON(form.dataset.initUrl, { ke1: uint8ArrayToBase64(ke1) });
+        const { ke3 } = await login.loginFinish(base64ToBytes(initData.ke2), form.dataset.username);
+
+        await postJSON(form.dataset.finalUrl, {
+            deletionID: initData.deletionID,
+            ke3: uint8ArrayToBase64(ke3),
+            successors: successors,
+        });
+
+        window.location.href = form.dataset.successUrl;
+    } catch (err) {
+        console.error(err);
+        errBox.innerHTML = err.message || "Deleting the account failed. See console for details.";
+    }
+});
@@ -0,0 +1,121 @@
+import { OpaqueClientWrapper } from "./opaque.js"
+import { base64ToBytes, uint8ArrayToBase64 } from "./utils.js"
+import { unwrapVaultKey } from "./vaultkey.js"
+import { generateKeyPair, unwrapWithPrivateKey, wrapToPublicKey } from "./accesskeys.js"
+
+const page = document.getElementById("emergencyAccess");
+const errBox = document.getElementById("errorBox");
+const passwordInput = document.getElementById("unlockPassword");
+
+// public keys are rendered as hex by the page template
+function hexToBytes(hex) {
+    const bytes = new Uint8Array(hex.length / 2);
+    for (let i = 0; i < bytes.length; i++) {
+        bytes[i] = parseInt(hex.substr(i * 2, 2), 16);
+    }
+    return bytes;
+}
+
+async function request(url, method, body) {
+    const res = await fetch(url, {
+        method: method,
+        headers: { "Content-Type": "application/json" },
+        body: body ? JSON.stringify(body) : undefined,
+    });
+
+    const data = await res.json();
+    if (!res.ok) {
+        throw new Error(data.message);
+    }
+    return data;
+}
+
+// unlock proves the master password with a fresh login and returns the vault key
+// together with the stored key pair of the account.
+async function unlock() {
+    const password = passwordInput.value;
+    if (!password) {
+        throw new Error("Enter your master password first.");
+    }
+
+    const login = new OpaqueClientWrapper();
+    const ke1 = await login.loginInit(password);
+
+    const initData = await request(page.dataset.unlockInitUrl, "POST", { ke1: uint8ArrayToBase64(ke1) });
+    const { ke3, exportKey } = await login.loginFinish(base64ToBytes(initData.ke2), page.dataset.username);
+
+    const keys = await request(page.dataset.unlockFinalUrl, "POST", {
+        unlockID: initData.unlockID,
+        ke3: uint8ArrayToBase64(ke3),
+    });
+    if (!keys.encryptedVaultKey) {
+        throw new Error("This account has no vault key.");
+    }
+
+    return {
+        vaultKey: await unwrapVaultKey(exportKey, base64ToBytes(keys.encryptedVaultKey)),
+        encryptedPrivateKey: keys.encryptedPrivateKey ? base64ToBytes(keys.encryptedPrivateKey) : null,
+    };
+}
+
+async function publishKeys() {
+    const { vaultKey } = await unlock();
+    const { publicKey, encryptedPrivateKey } = await generateKeyPair(vaultKey);
+
+    await request(page.dataset.keysUrl, "POST", {
+        publicKey: uint8ArrayToBase64(publicKey),
+        encryptedPrivateKey: uint8ArrayToBase64(encryptedPrivateKey),
+    });
+    window.location.reload();
+}
+
+async function confirmAccess(button) {
+    const { vaultKey } = await unlock();
+    const encryptedVaultKey = await wrapToPublicKey(hexToBytes(button.dataset.publicKey), vaultKey);
+
+    await request(`${page.dataset.confirmUrl}${button.dataset.id}/`, "POST", {
+        encryptedVaultKey: uint8ArrayToBase64(encryptedVaultKey),
+    });
+    window.location.reload();
+}
+
+async function takeOver(button) {
+    const { vaultKey, encryptedPrivateKey } = await unlock();
+    if (!encryptedPrivateKey) {
+        throw new Error("This account has no key pair.");
+    }
+
+    const data = await request(`${page.dataset.vaultKeyUrl}${button.dataset.id}/`, "GET");
+    const grantorVaultKey = await unwrapWithPrivateKey(vaultKey, encryptedPrivateKey, base64ToBytes(data.encryptedVaultKey));
+
+    sessionStorage.setItem(`emergency-vault-key:${button.dataset.grantor}`, uint8ArrayToBase64(grantorVaultKey));
+    errBox.classList.replace("text-danger", "text-success");
+    errBox.innerHTML = `The vault key of ${button.dataset.grantor} is unlocked for this browser session.`;
+}
+
+const actions = {
+    "publish-keys": publishKeys,
+    "confirm": confirmAccess,
+    "take-over": takeOver,
+};
+
+page.addEventListener("click", async (e) => {
+    const button = e.target.closest("[data-action]");
+    if (!button) {
+        return;
+    }
+
+    e.preventDefault();
+    errBox.classList.replace("text-success", "text-danger");
+    errBox.innerHTML = "";
+    button.disabled = true;
+
+    try {
+        await actions[button.dataset.action](button);
+    } catch (err) {
+        console.error(err);
+        errBox.innerHTML = err.message || "The action failed. See console for details.";
+    } finally {
+        button.disabled = false;
+    }
+});
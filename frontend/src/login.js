@@ -10,7 +10,7 @@ form.addEventListener("submit", async (e) => {
     const password = form.password.value;
     const username = form.username.value;
 
-    const opaque = new OpaqueClientWrapper("cool-password-manager");
+    const opaque = new OpaqueClientWrapper();
 
     try {
         const ke1 = await opaque.loginInit(password);
@@ -1,17 +1,91 @@
 // opaqueClient.js
-import { OpaqueClient, getOpaqueConfig, RegistrationResponse, OPAQUE_P256 } from "@cloudflare/opaque-ts";
+import { OpaqueClient, OpaqueID, getOpaqueConfig, RegistrationResponse, KE2, IdentityMemHardFn } from "@cloudflare/opaque-ts";
+import { scrypt } from "@noble/hashes/lib/scrypt";
+import { argon2id } from "./argon2.js";
 
-const encoder = new TextEncoder();
+const opaqueConfigURL = "/api/v1/auth/opaque/";
+
+// The suites of the server with the length of their compressed points, the key
+// stretching function of bytemare/opaque outputs that many bytes.
+export const suites = {
+    "P256-SHA256": { id: OpaqueID.OPAQUE_P256, elementLength: 33 },
+    "P384-SHA384": { id: OpaqueID.OPAQUE_P384, elementLength: 49 },
+    "P521-SHA512": { id: OpaqueID.OPAQUE_P521, elementLength: 67 },
+};
+
+let opaqueConfig = null;
+
+// loadOpaqueConfig reads the suite of the server once per page, a record the browser
+// registers has to log in from cpm too.
+function loadOpaqueConfig() {
+    if (!opaqueConfig) {
+        opaqueConfig = fetch(opaqueConfigURL).then(async (res) => {
+            if (!res.ok) {
+                throw new Error("the OPAQUE config of the server could not be read");
+            }
+            return res.json();
+        });
+        opaqueConfig.catch(() => { opaqueConfig = null });
+    }
+    return opaqueConfig;
+}
+
+// memHardFn is the key stretching function of the config with the empty salt and the
+// output length of bytemare/opaque, the defaults of opaque-ts differ from both.
+export function memHardFn(ksf, elementLength) {
+    switch (ksf.name) {
+        case "argon2id":
+            return {
+                name: "argon2id",
+                harden: (msg) => argon2id(msg, new Uint8Array(), {
+                    time: ksf.argon2Time,
+                    memory: ksf.argon2Memory,
+                    threads: ksf.argon2Threads,
+                    length: elementLength,
+                }),
+            };
+        case "scrypt":
+            return {
+                name: "scrypt",
+                harden: (msg) => scrypt(msg, new Uint8Array(), {
+                    N: ksf.scryptN,
+                    r: ksf.scryptR,
+                    p: ksf.scryptP,
+                    dkLen: elementLength,
+                }),
+            };
+        case "identity":
+            return IdentityMemHardFn;
+        default:
+            throw new Error(`unknown key stretching function ${ksf.name}`);
+    }
+}
 
 export class OpaqueClientWrapper {
-    constructor(serverIdentity) {
-        this.cfg = getOpaqueConfig(3);
-        this.client = new OpaqueClient(this.cfg);
-        this.state = null;
-        this.serverIdentity = serverIdentity
+    constructor() {
+        this.cfg = null;
+        this.client = null;
+        this.serverIdentity = null;
+    }
+
+    async setup() {
+        if (this.client) {
+            return;
+        }
+
+        const conf = await loadOpaqueConfig();
+        const suite = suites[conf.suite];
+        if (!suite) {
+            throw new Error(`unknown OPAQUE suite ${conf.suite}`);
+        }
+
+        this.cfg = getOpaqueConfig(suite.id);
+        this.client = new OpaqueClient(this.cfg, memHardFn(conf.ksf, suite.elementLength));
+        this.serverIdentity = conf.serverID;
     }
 
     async registerInit(password) {
+        await this.setup()
         const registrationRequest = await this.client.registerInit(password)
         return registrationRequest.serialize()
     }
@@ -21,25 +95,29 @@ export class OpaqueClientWrapper {
 
         const rec = await this.client.registerFinish(deserRes, this.serverIdentity, clientIdentity)
 
-        const { record, _ } = rec
-        return record.serialize()
+        const { record, export_key } = rec
+        return { record: record.serialize(), exportKey: new Uint8Array(export_key) }
     }
 
     async loginInit(password) {
+        await this.setup()
         const ke1 = await this.client.authInit(password)
 
         return ke1.serialize()
     }
 
-    async loginFinish(serverResponseBytes) {
-        const credentialResponse =
-            this.client.deserializeCredentialResponse(serverResponseBytes);
+    async loginFinish(serverResponseBytes, clientIdentity) {
+        const ke2 = KE2.deserialize(this.cfg, Array.from(serverResponseBytes))
 
-        const result = await this.client.loginFinish(this.state, credentialResponse);
+        const result = await this.client.authFinish(ke2, this.serverIdentity, clientIdentity)
+        if (result instanceof Error) {
+            throw result
+        }
 
         return {
-            sessionKey: result.sessionKey,
-            exportKey: result.exportKey,
+            ke3: result.ke3.serialize(),
+            sessionKey: new Uint8Array(result.session_key),
+            exportKey: new Uint8Array(result.export_key),
         };
     }
 }
@@ -0,0 +1,69 @@
+import { OpaqueClientWrapper } from "./opaque.js"
+import { base64ToBytes, uint8ArrayToBase64 } from "./utils.js"
+import { generateVaultKey, unwrapVaultKey, wrapVaultKey } from "./vaultkey.js"
+
+const form = document.getElementById("changeUsernameForm");
+const errBox = document.getElementById("errorBox");
+
+async function postJSON(url, body) {
+    const res = await fetch(url, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body),
+    });
+
+    const data = await res.json();
+    if (!res.ok) {
+        throw new Error(data.message);
+    }
+    return data;
+}
+
+form.addEventListener("submit", async (e) => {
+    e.preventDefault();
+    errBox.innerHTML = "";
+
+    const username = form.dataset.username;
+    const newUsername = form.newUsername.value.trim();
+    const password = form.password.value;
+
+    try {
+        // prove the password with a fresh login under the current username
+        const login = new OpaqueClientWrapper();
+        const ke1 = await login.loginInit(password);
+
+        const initData = await postJSON(form.dataset.initUrl, {
+            username: newUsername,
+            ke1: uint8ArrayToBase64(ke1),
+        });
+        const { ke3, exportKey: oldExportKey } = await login.loginFinish(base64ToBytes(initData.ke2), username);
+
+        // register the same password under the new username
+        const registration = new OpaqueClientWrapper();
+        const registrationRequest = await registration.registerInit(password);
+
+        const verifyData = await postJSON(form.dataset.verifyUrl, {
+            changeID: initData.changeID,
+            ke3: uint8ArrayToBase64(ke3),
+            registrationRequest: uint8ArrayToBase64(registrationRequest),
+        });
+
+        const vaultKey = verifyData.encryptedVaultKey
+            ? await unwrapVaultKey(oldExportKey, base64ToBytes(verifyData.encryptedVaultKey))
+            : generateVaultKey();
+
+        const { record, exportKey: newExportKey } = await registration.registerFinish(base64ToBytes(verifyData.record), newUsername);
+        const encryptedVaultKey = await wrapVaultKey(newExportKey, vaultKey);
+
+        await postJSON(form.dataset.finalUrl, {
+            changeID: initData.changeID,
+            registrationRecord: uint8ArrayToBase64(record),
+            encryptedVaultKey: uint8ArrayToBase64(encryptedVaultKey),
+        });
+
+        window.location.reload();
+    } catch (err) {
+        console.error(err);
+        errBox.innerHTML = err.message || "Changing the username failed. See console for details.";
+    }
+});
@@ -0,0 +1,343 @@
+// QR codes (ISO/IEC 18004) for the recovery kit, drawn in the browser since the key
+// must never reach the server. Only byte mode at error correction level M and the
+// versions 1 to 6 are written, they hold up to 106 bytes and need no version
+// information, which is plenty for a recovery key.
+
+// versions holds the total codewords, the error correction codewords of each block,
+// the blocks and the alignment pattern positions of the versions 1 to 6 at level M.
+const versions = [
+    { total: 26, ecPerBlock: 10, blocks: 1, alignment: [] },
+    { total: 44, ecPerBlock: 16, blocks: 1, alignment: [6, 18] },
+    { total: 70, ecPerBlock: 26, blocks: 1, alignment: [6, 22] },
+    { total: 100, ecPerBlock: 18, blocks: 2, alignment: [6, 26] },
+    { total: 134, ecPerBlock: 24, blocks: 2, alignment: [6, 30] },
+    { total: 172, ecPerBlock: 16, blocks: 4, alignment: [6, 34] },
+]
+
+// the format bits of level M are 00
+const levelBits = 0
+
+const masks = [
+    (x, y) => (x + y) % 2 === 0,
+    (x, y) => y % 2 === 0,
+    (x, y) => x % 3 === 0,
+    (x, y) => (x + y) % 3 === 0,
+    (x, y) => (Math.floor(x / 3) + Math.floor(y / 2)) % 2 === 0,
+    (x, y) => ((x * y) % 2) + ((x * y) % 3) === 0,
+    (x, y) => (((x * y) % 2) + ((x * y) % 3)) % 2 === 0,
+    (x, y) => (((x + y) % 2) + ((x * y) % 3)) % 2 === 0,
+]
+
+// encodeQRCode returns the modules of the code as rows, true being dark. The mask with
+// the lowest penalty is picked, the way the standard asks for.
+export function encodeQRCode(text) {
+    const data = new TextEncoder().encode(text)
+
+    const version = versions.findIndex((v) => v.total - v.ecPerBlock * v.blocks >= data.length + 2)
+    if (version === -1) {
+        throw new Error("The text is too long for a QR code.")
+    }
+
+    const codewords = addErrorCorrection(dataCodewords(data, versions[version]), versions[version])
+
+    let best = null
+    let bestPenalty = Infinity
+    for (let mask = 0; mask < masks.length; mask++) {
+        const symbol = drawSymbol(version + 1, codewords, mask)
+        const score = penalty(symbol)
+        if (score < bestPenalty) {
+            best = symbol
+            bestPenalty = score
+        }
+    }
+
+    return best
+}
+
+// renderQRCode draws the code as an SVG with the quiet zone of four modules around it,
+// so it stays sharp when the kit is printed.
+export function renderQRCode(element, text) {
+    const modules = encodeQRCode(text)
+    const size = modules.length + 8
+    const namespace = "http://www.w3.org/2000/svg"
+
+    let path = ""
+    modules.forEach((row, y) => row.forEach((dark, x) => {
+        if (dark) {
+            path += `M${x + 4},${y + 4}h1v1h-1z`
+        }
+    }))
+
+    const svg = document.createElementNS(namespace, "svg")
+    svg.setAttribute("viewBox", `0 0 ${size} ${size}`)
+    svg.setAttribute("shape-rendering", "crispEdges")
+    svg.setAttribute("role", "img")
+    svg.setAttribute("aria-label", "QR code of the recovery key")
+
+    const background = document.createElementNS(namespace, "rect")
+    background.setAttribute("width", size)
+    background.setAttribute("height", size)
+    background.setAttribute("fill", "#fff")
+
+    const foreground = document.createElementNS(namespace, "path")
+    foreground.setAttribute("d", path)
+    foreground.setAttribute("fill", "#000")
+
+    svg.append(background, foreground)
+    element.replaceChildren(svg)
+}
+
+// dataCodewords writes the bytes in byte mode and pads them to the data capacity of
+// the version.
+function dataCodewords(data, version) {
+    const capacity = version.total - version.ecPerBlock * version.blocks
+    const bits = []
+    const push = (value, length) => {
+        for (let i = length - 1; i >= 0; i--) {
+            bits.push((value >>> i) & 1)
+        }
+    }
+
+    push(0b0100, 4)
+    push(data.length, 8)
+    data.forEach((byte) => push(byte, 8))
+    push(0, Math.min(4, capacity * 8 - bits.length))
+    push(0, (8 - (bits.length % 8)) % 8)
+
+    const codewords = []
+    for (let i = 0; i < bits.length; i += 8) {
+        codewords.push(bits.slice(i, i + 8).reduce((byte, bit) => (byte << 1) | bit, 0))
+    }
+
+    for (let pad = 0xec; codewords.length < capacity; pad ^= 0xec ^ 0x11) {
+        codewords.push(pad)
+    }
+
+    return codewords
+}
+
+// addErrorCorrection splits the data into the blocks of the version and interleaves
+// the blocks and their Reed-Solomon codewords. The blocks of the versions 1 to 6 at
+// level M are all of the same length.
+function addErrorCorrection(data, version) {
+    const blockLength = data.length / version.blocks
+    const divisor = reedSolomonDivisor(version.ecPerBlock)
+
+    const blocks = []
+    for (let i = 0; i < version.blocks; i++) {
+        const block = data.slice(i * blockLength, (i + 1) * blockLength)
+        blocks.push({ data: block, ec: reedSolomonRemainder(block, divisor) })
+    }
+
+    const result = []
+    for (let i = 0; i < blockLength; i++) {
+        blocks.forEach((block) => result.push(block.data[i]))
+    }
+    for (let i = 0; i < version.ecPerBlock; i++) {
+        blocks.forEach((block) => result.push(block.ec[i]))
+    }
+
+    return result
+}
+
+// multiply multiplies two elements of GF(2^8) over the polynomial 0x11d.
+function multiply(x, y) {
+    let z = 0
+    for (let i = 7; i >= 0; i--) {
+        z = (z << 1) ^ ((z >>> 7) * 0x11d)
+        z ^= ((y >>> i) & 1) * x
+    }
+    return z
+}
+
+function reedSolomonDivisor(degree) {
+    const result = new Array(degree).fill(0)
+    result[degree - 1] = 1
+
+    let root = 1
+    for (let i = 0; i < degree; i++) {
+        for (let j = 0; j < degree; j++) {
+            result[j] = multiply(result[j], root)
+            if (j + 1 < degree) {
+                result[j] ^= result[j + 1]
+            }
+        }
+        root = multiply(root, 0x02)
+    }
+
+    return result
+}
+
+function reedSolomonRemainder(data, divisor) {
+    const result = new Array(divisor.length).fill(0)
+    for (const byte of data) {
+        const factor = byte ^ result.shift()
+        result.push(0)
+        divisor.forEach((coefficient, i) => {
+            result[i] ^= multiply(coefficient, factor)
+        })
+    }
+    return result
+}
+
+// drawSymbol places the function patterns, the codewords and the format bits of the
+// mask, the modules are indexed as [y][x].
+function drawSymbol(version, codewords, mask) {
+    const size = version * 4 + 17
+    const modules = Array.from({ length: size }, () => new Array(size).fill(false))
+    const reserved = Array.from({ length: size }, () => new Array(size).fill(false))
+    const set = (x, y, dark) => {
+        modules[y][x] = dark
+        reserved[y][x] = true
+    }
+
+    for (let i = 0; i < size; i++) {
+        set(6, i, i % 2 === 0)
+        set(i, 6, i % 2 === 0)
+    }
+
+    for (const [cx, cy] of [[3, 3], [size - 4, 3], [3, size - 4]]) {
+        for (let dy = -4; dy <= 4; dy++) {
+            for (let dx = -4; dx <= 4; dx++) {
+                const x = cx + dx
+                const y = cy + dy
+                const distance = Math.max(Math.abs(dx), Math.abs(dy))
+                if (x >= 0 && x < size && y >= 0 && y < size) {
+                    set(x, y, distance !== 2 && distance !== 4)
+                }
+            }
+        }
+    }
+
+    // the alignment patterns skip the corners taken by the finder patterns
+    const alignment = versions[version - 1].alignment
+    alignment.forEach((cx, i) => alignment.forEach((cy, j) => {
+        const last = alignment.length - 1
+        if ((i === 0 && j === 0) || (i === 0 && j === last) || (i === last && j === 0)) {
+            return
+        }
+
+        for (let dy = -2; dy <= 2; dy++) {
+            for (let dx = -2; dx <= 2; dx++) {
+                set(cx + dx, cy + dy, Math.max(Math.abs(dx), Math.abs(dy)) !== 1)
+            }
+        }
+    }))
+
+    drawFormatBits(set, size, mask)
+
+    // the codewords run in columns of two from the bottom right, turning at each edge
+    let bit = 0
+    for (let right = size - 1; right >= 1; right -= 2) {
+        if (right === 6) {
+            right = 5
+        }
+
+        for (let vertical = 0; vertical < size; vertical++) {
+            for (let j = 0; j < 2; j++) {
+                const x = right - j
+                const upward = ((right + 1) & 2) === 0
+                const y = upward ? size - 1 - vertical : vertical
+
+                if (reserved[y][x]) {
+                    continue
+                }
+
+                if (bit < codewords.length * 8) {
+                    modules[y][x] = ((codewords[bit >>> 3] >>> (7 - (bit & 7))) & 1) === 1
+                    bit++
+                }
+                if (masks[mask](x, y)) {
+                    modules[y][x] = !modules[y][x]
+                }
+            }
+        }
+    }
+
+    return modules
+}
+
+// drawFormatBits writes the level and the mask with their BCH code twice, next to the
+// finder patterns.
+function drawFormatBits(set, size, mask) {
+    const data = (levelBits << 3) | mask
+    let remainder = data
+    for (let i = 0; i < 10; i++) {
+        remainder = (remainder << 1) ^ ((remainder >>> 9) * 0x537)
+    }
+
+    const bits = ((data << 10) | remainder) ^ 0x5412
+    const bitAt = (i) => ((bits >>> i) & 1) === 1
+
+    for (let i = 0; i <= 5; i++) {
+        set(8, i, bitAt(i))
+    }
+    set(8, 7, bitAt(6))
+    set(8, 8, bitAt(7))
+    set(7, 8, bitAt(8))
+    for (let i = 9; i < 15; i++) {
+        set(14 - i, 8, bitAt(i))
+    }
+
+    for (let i = 0; i < 8; i++) {
+        set(size - 1 - i, 8, bitAt(i))
+    }
+    for (let i = 8; i < 15; i++) {
+        set(8, size - 15 + i, bitAt(i))
+    }
+    set(8, size - 8, true)
+}
+
+// penalty scores the symbol with the four rules of the standard: runs of five or more
+// modules, 2x2 blocks, patterns that look like a finder and an unbalanced darkness.
+function penalty(modules) {
+    const size = modules.length
+    const columns = modules.map((_, x) => modules.map((row) => row[x]))
+    const finderLike = [
+        [true, false, true, true, true, false, true, false, false, false, false],
+        [false, false, false, false, true, false, true, true, true, false, true],
+    ]
+
+    let score = 0
+    let dark = 0
+
+    for (const line of [...modules, ...columns]) {
+        let run = 1
+        for (let i = 1; i <= size; i++) {
+            if (i < size && line[i] === line[i - 1]) {
+                run++
+                continue
+            }
+            if (run >= 5) {
+                score += run - 2
+            }
+            run = 1
+        }
+
+        for (let i = 0; i + 11 <= size; i++) {
+            for (const pattern of finderLike) {
+                if (pattern.every((module, j) => line[i + j] === module)) {
+                    score += 40
+                }
+            }
+        }
+    }
+
+    for (let y = 0; y < size; y++) {
+        for (let x = 0; x < size; x++) {
+            if (modules[y][x]) {
+                dark++
+            }
+
+            if (x + 1 < size && y + 1 < size) {
+                const color = modules[y][x]
+                if (modules[y][x + 1] === color && modules[y + 1][x] === color && modules[y + 1][x + 1] === color) {
+                    score += 3
+                }
+            }
+        }
+    }
+
+    score += Math.floor(Math.abs((dark * 100) / (size * size) - 50) / 5) * 10
+    return score
+}
@@ -0,0 +1,65 @@
+import { OpaqueClientWrapper } from "./opaque.js"
+import { deriveRecoveryProof, parseRecoveryKey } from "./recoverykit.js"
+import { base64ToBytes, uint8ArrayToBase64 } from "./utils.js"
+import { unwrapVaultKey, wrapVaultKey } from "./vaultkey.js"
+
+const form = document.getElementById("recoverForm");
+const errBox = document.getElementById("errorBox");
+
+async function postJSON(url, body) {
+    const res = await fetch(url, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body),
+    });
+
+    const data = await res.json();
+    if (!res.ok) {
+        throw new Error(data.message);
+    }
+    return data;
+}
+
+form.addEventListener("submit", async (e) => {
+    e.preventDefault();
+    errBox.innerHTML = "";
+
+    const username = form.username.value;
+    const newPassword = form.newPassword.value;
+
+    if (newPassword !== form.confirmPassword.value) {
+        errBox.innerHTML = "The new passwords do not match.";
+        return;
+    }
+
+    try {
+        const recoveryKey = parseRecoveryKey(form.recoveryKey.value);
+        const recoveryProof = await deriveRecoveryProof(recoveryKey);
+
+        const registration = new OpaqueClientWrapper();
+        const registrationRequest = await registration.registerInit(newPassword);
+
+        const initData = await postJSON(form.dataset.initUrl, {
+            username,
+            recoveryProof: uint8ArrayToBase64(recoveryProof),
+            verificationCode: form.verificationCode.value,
+            registrationRequest: uint8ArrayToBase64(registrationRequest),
+        });
+
+        const vaultKey = await unwrapVaultKey(recoveryKey, base64ToBytes(initData.recoveryVaultKey));
+
+        const { record, exportKey } = await registration.registerFinish(base64ToBytes(initData.record), username);
+        const encryptedVaultKey = await wrapVaultKey(exportKey, vaultKey);
+
+        await postJSON(form.dataset.finalUrl, {
+            recoveryID: initData.recoveryID,
+            registrationRecord: uint8ArrayToBase64(record),
+            encryptedVaultKey: uint8ArrayToBase64(encryptedVaultKey),
+        });
+
+        window.location.href = form.dataset.successUrl;
+    } catch (err) {
+        console.error(err);
+        errBox.innerHTML = err.message || "Recovering the account failed. See console for details.";
+    }
+});
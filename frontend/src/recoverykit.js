@@ -0,0 +1,71 @@
+// The recovery key is generated in the browser and only shown to the user. It wraps
+// the vault key the same way the export key does, and a proof derived from it with a
+// different HKDF info lets the server check the key without learning it.
+const proofInfo = new TextEncoder().encode("cool-password-manager recovery proof")
+const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+const keyLength = 20
+
+export function generateRecoveryKey() {
+    return crypto.getRandomValues(new Uint8Array(keyLength))
+}
+
+// formatRecoveryKey writes the key in base32 groups of four, the way it is printed.
+export function formatRecoveryKey(key) {
+    let bits = 0
+    let value = 0
+    let output = ""
+
+    for (const byte of key) {
+        value = (value << 8) | byte
+        bits += 8
+
+        while (bits >= 5) {
+            output += alphabet[(value >>> (bits - 5)) & 31]
+            bits -= 5
+        }
+    }
+
+    return output.match(/.{1,4}/g).join("-")
+}
+
+// parseRecoveryKey ignores case, dashes and spaces, so the key can be typed the way
+// it was printed.
+export function parseRecoveryKey(text) {
+    const normalized = text.toUpperCase().replace(/[-\s]/g, "")
+
+    let bits = 0
+    let value = 0
+    const bytes = []
+
+    for (const char of normalized) {
+        const index = alphabet.indexOf(char)
+        if (index === -1) {
+            throw new Error("The recovery key is not valid.")
+        }
+
+        value = (value << 5) | index
+        bits += 5
+
+        if (bits >= 8) {
+            bytes.push((value >>> (bits - 8)) & 255)
+            bits -= 8
+        }
+    }
+
+    if (bytes.length !== keyLength) {
+        throw new Error("The recovery key is not valid.")
+    }
+
+    return new Uint8Array(bytes)
+}
+
+export async function deriveRecoveryProof(key) {
+    const material = await crypto.subtle.importKey("raw", key, "HKDF", false, ["deriveBits"])
+
+    const proof = await crypto.subtle.deriveBits(
+        { name: "HKDF", hash: "SHA-256", salt: new Uint8Array(), info: proofInfo },
+        material,
+        256,
+    )
+    return new Uint8Array(proof)
+}
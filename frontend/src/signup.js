@@ -1,19 +1,31 @@
 import { OpaqueClientWrapper } from "./opaque.js"
+import { renderQRCode } from "./qrcode.js"
+import { deriveRecoveryProof, formatRecoveryKey, generateRecoveryKey } from "./recoverykit.js"
 import { base64ToBytes, uint8ArrayToBase64 } from "./utils.js"
+import { generateVaultKey, wrapVaultKey } from "./vaultkey.js"
 
 const form = document.getElementById("signupForm");
+const verifyForm = document.getElementById("verifyForm");
+const recoveryKitSection = document.getElementById("recoveryKitSection");
 const errBox = document.getElementById("errorBox");
 
+// kept between the sign up and the verification step
+let opaque;
+let registration;
+let createRecoveryKit;
+let username;
+
 form.addEventListener("submit", async (e) => {
     e.preventDefault();
 
     const password = form.password.value;
-    const username = form.username.value;
+    username = form.username.value;
+    createRecoveryKit = form.recoveryKit.checked;
     const email = form.email.value;
     const firstName = form.firstName.value;
     const lastName = form.lastName.value;
 
-    const opaque = new OpaqueClientWrapper("cool-password-manager");
+    opaque = new OpaqueClientWrapper();
 
     try {
         const registrationRequest = await opaque.registerInit(password);
@@ -36,19 +48,75 @@ form.addEventListener("submit", async (e) => {
             return;
         }
 
-        const record = await opaque.registerFinish(base64ToBytes(res1Data.record), res1Data.registrationID);
+        registration = res1Data;
+        errBox.innerHTML = "";
+        form.hidden = true;
+        verifyForm.hidden = false;
 
-        htmx.ajax("POST", "/account/auth/sign-up/final/", {
-            target: "#signup-container",
-            swap: "outerHTML",
-            values: {
-                registrationID: res1Data.registrationID,
-                registrationRecord: uint8ArrayToBase64(record),
-            },
+    } catch (err) {
+        console.error(err);
+        errBox.innerHTML = "Registration failed. See console for details.";
+    }
+});
+
+verifyForm.addEventListener("submit", async (e) => {
+    e.preventDefault();
+
+    try {
+        const res2 = await fetch("/account/auth/sign-up/verify/", {
+            method: "POST",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({
+                registrationID: registration.registrationID,
+                verificationCode: verifyForm.verificationCode.value,
+            }),
         });
 
+        const res2Data = await res2.json();
+        if (!res2.ok) {
+            errBox.innerHTML = res2Data.message;
+            return;
+        }
+
+        const { record, exportKey } = await opaque.registerFinish(base64ToBytes(registration.record), registration.registrationID);
+
+        const vaultKey = generateVaultKey();
+        const values = {
+            registrationID: registration.registrationID,
+            registrationRecord: uint8ArrayToBase64(record),
+            encryptedVaultKey: uint8ArrayToBase64(await wrapVaultKey(exportKey, vaultKey)),
+        };
+
+        if (!createRecoveryKit) {
+            finalize(values);
+            return;
+        }
+
+        // the recovery key wraps the vault key the same way the export key does
+        const recoveryKey = generateRecoveryKey();
+        values.recoveryProof = uint8ArrayToBase64(await deriveRecoveryProof(recoveryKey));
+        values.recoveryVaultKey = uint8ArrayToBase64(await wrapVaultKey(recoveryKey, vaultKey));
+
+        document.getElementById("recoveryKitUsername").textContent = username;
+        document.getElementById("recoveryKitKey").textContent = formatRecoveryKey(recoveryKey);
+        renderQRCode(document.getElementById("recoveryKitQr"), formatRecoveryKey(recoveryKey));
+        errBox.innerHTML = "";
+        verifyForm.hidden = true;
+        recoveryKitSection.hidden = false;
+
+        document.getElementById("recoveryKitPrint").onclick = () => window.print();
+        document.getElementById("recoveryKitContinue").onclick = () => finalize(values);
+
     } catch (err) {
         console.error(err);
-        errBox.innerHTML = "Registration failed. See console for details.";
+        errBox.innerHTML = "Verification failed. See console for details.";
     }
-});
\ No newline at end of file
+});
+
+function finalize(values) {
+    htmx.ajax("POST", "/account/auth/sign-up/final/", {
+        target: "#signup-container",
+        swap: "outerHTML",
+        values,
+    });
+}
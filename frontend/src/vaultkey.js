@@ -0,0 +1,40 @@
+// The vault key never leaves the browser in plain text. It is wrapped with an
+// AES-GCM key derived from the OPAQUE export key, the stored form is nonce || ciphertext.
+const wrapInfo = new TextEncoder().encode("cool-password-manager vault key")
+const nonceLength = 12
+
+async function deriveWrappingKey(exportKey) {
+    const material = await crypto.subtle.importKey("raw", exportKey, "HKDF", false, ["deriveKey"])
+
+    return crypto.subtle.deriveKey(
+        { name: "HKDF", hash: "SHA-256", salt: new Uint8Array(), info: wrapInfo },
+        material,
+        { name: "AES-GCM", length: 256 },
+        false,
+        ["encrypt", "decrypt"],
+    )
+}
+
+export function generateVaultKey() {
+    return crypto.getRandomValues(new Uint8Array(32))
+}
+
+export async function wrapVaultKey(exportKey, vaultKey) {
+    const key = await deriveWrappingKey(exportKey)
+    const nonce = crypto.getRandomValues(new Uint8Array(nonceLength))
+    const ciphertext = await crypto.subtle.encrypt({ name: "AES-GCM", iv: nonce }, key, vaultKey)
+
+    const wrapped = new Uint8Array(nonceLength + ciphertext.byteLength)
+    wrapped.set(nonce)
+    wrapped.set(new Uint8Array(ciphertext), nonceLength)
+    return wrapped
+}
+
+export async function unwrapVaultKey(exportKey, wrapped) {
+    const key = await deriveWrappingKey(exportKey)
+    const nonce = wrapped.slice(0, nonceLength)
+    const ciphertext = wrapped.slice(nonceLength)
+
+    const vaultKey = await crypto.subtle.decrypt({ name: "AES-GCM", iv: nonce }, key, ciphertext)
+    return new Uint8Array(vaultKey)
+}
@@ -0,0 +1,73 @@
+// The browser side of the OPAQUE interoperability test of the server, see
+// internal/infrastructure/opaque/interop_test.go. It runs the client of the frontend
+// over JSON lines: every line on stdin is a step, every line on stdout its answer.
+//
+//	{"step":"registerInit","suite":...,"ksf":...,"serverID":...,"username":...,"password":...}
+//	{"step":"registerFinish","registrationResponse":...}
+//	{"step":"loginInit"}
+//	{"step":"loginFinish","ke2":...}
+//	{"step":"harden","input":...}
+//
+// The messages are base64, like the ones the pages send.
+import { createInterface } from "node:readline";
+import { OpaqueClient, getOpaqueConfig, RegistrationResponse, KE2 } from "@cloudflare/opaque-ts";
+import { memHardFn, suites } from "../src/opaque.js";
+
+const toBase64 = (bytes) => Buffer.from(bytes).toString("base64");
+const fromBase64 = (text) => Array.from(Buffer.from(text, "base64"));
+
+let session = null;
+
+function newClient() {
+    return new OpaqueClient(session.cfg, session.memHard);
+}
+
+async function run(request) {
+    switch (request.step) {
+        case "registerInit": {
+            const suite = suites[request.suite];
+            if (!suite) {
+                throw new Error(`unknown OPAQUE suite ${request.suite}`);
+            }
+            session = { ...request, cfg: getOpaqueConfig(suite.id), memHard: memHardFn(request.ksf, suite.elementLength) };
+            session.client = newClient();
+
+            const registrationRequest = await session.client.registerInit(session.password);
+            return { registrationRequest: toBase64(registrationRequest.serialize()) };
+        }
+        case "registerFinish": {
+            const response = RegistrationResponse.deserialize(session.cfg, fromBase64(request.registrationResponse));
+            const { record, export_key } = await session.client.registerFinish(response, session.serverID, session.username);
+            return { record: toBase64(record.serialize()), exportKey: toBase64(export_key) };
+        }
+        case "loginInit": {
+            session.client = newClient();
+            const ke1 = await session.client.authInit(session.password);
+            return { ke1: toBase64(ke1.serialize()) };
+        }
+        case "loginFinish": {
+            const ke2 = KE2.deserialize(session.cfg, fromBase64(request.ke2));
+            const result = await session.client.authFinish(ke2, session.serverID, session.username);
+            if (result instanceof Error) {
+                throw result;
+            }
+            return {
+                ke3: toBase64(result.ke3.serialize()),
+                sessionKey: toBase64(result.session_key),
+                exportKey: toBase64(result.export_key),
+            };
+        }
+        case "harden":
+            return { output: toBase64(session.memHard.harden(new Uint8Array(fromBase64(request.input)))) };
+        default:
+            throw new Error(`unknown step ${request.step}`);
+    }
+}
+
+for await (const line of createInterface({ input: process.stdin })) {
+    try {
+        console.log(JSON.stringify(await run(JSON.parse(line))));
+    } catch (err) {
+        console.log(JSON.stringify({ error: String(err.message ?? err) }));
+    }
+}
@@ -4,6 +4,11 @@ module.exports = {
     entry: {
         signup: './src/signup.js',
         login: './src/login.js',
+        changePassword: './src/changepassword.js',
+        recover: './src/recover.js',
+        emergencyAccess: './src/emergencyaccess.js',
+        profile: './src/profile.js',
+        deleteAccount: './src/deleteaccount.js',
     },
     output: {
         filename: '[name].js', // signup.js & login.js
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/param"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// accessTokenGroupLimit is how many groups the token page offers as restrictions.
+const accessTokenGroupLimit = 100
+
+func AccessTokenListHandler(ctx *gin.Context, usecase usecase.AccessTokenUsecase, groupUsecase usecase.GroupUsecase) {
+	templateName := "access_tokens.html"
+	data, err := accessTokenPageData(ctx, usecase, groupUsecase)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, gin.H{})
+		return
+	}
+
+	ctx.HTML(http.StatusOK, templateName, data)
+}
+
+func AccessTokenCreateHandler(ctx *gin.Context, usecase usecase.AccessTokenUsecase, groupUsecase usecase.GroupUsecase) {
+	templateName := "access_tokens.html"
+	data, err := accessTokenPageData(ctx, usecase, groupUsecase)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, gin.H{})
+		return
+	}
+
+	var form model.AccessTokenCreateModel
+	if err := ctx.ShouldBind(&form); err != nil {
+		formErr := errors.NewError(err.Error(), http.StatusBadRequest)
+		localHttp.HandlerFormError(ctx, formErr, templateName, data)
+		return
+	}
+
+	var itemIDs []types.ID
+	for _, field := range strings.Split(form.ItemIDs, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			localHttp.HandleError(ctx, errors.Error2Custom(account.AccessTokenInvalidRestriction), templateName, data)
+			return
+		}
+
+		itemIDs = append(itemIDs, types.ID(id))
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	raw, token, err := usecase.Create(
+		ctx, userID, form.Name, entity.AccessTokenPermission(form.Permission), form.ExpiryDays, itemIDs, form.GroupIDs,
+	)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
+		return
+	}
+
+	// the token is only ever shown here, so the page is rendered instead of redirected
+	data["Tokens"] = append(data["Tokens"].([]entity.AccessToken), token)
+	data["NewToken"] = raw
+	ctx.HTML(http.StatusCreated, templateName, data)
+}
+
+func AccessTokenRevokeHandler(ctx *gin.Context, usecase usecase.AccessTokenUsecase) {
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	tokenID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		localHttp.HandleNotFoundError(ctx)
+		return
+	}
+
+	if err := usecase.Revoke(ctx, userID, types.ID(tokenID)); err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), "general_error.html", gin.H{})
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, localHttp.PathAccessTokens)
+}
+
+// APIAuthRequired lets the JSON API be called with an access token in the
+// Authorization header or with the session of the browser. Unlike AuthRequired it
+// answers with JSON instead of redirecting to the login page.
+func APIAuthRequired(usecase usecase.AccessTokenUsecase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		header := ctx.GetHeader("Authorization")
+		if header == "" {
+			session := sessions.Default(ctx)
+			username := session.Get(localHttp.AuthUsernameKey)
+			userID, ok := session.Get(localHttp.AuthUserIDKey).(int64)
+			if username == nil || !ok {
+				localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AuthRequired))
+				return
+			}
+
+			ctx.Set(localHttp.AuthUserIDKey, userID)
+			ctx.Set(localHttp.AuthUsernameKey, username)
+			ctx.Next()
+			return
+		}
+
+		raw, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenInvalid))
+			return
+		}
+
+		token, err := usecase.Authenticate(ctx, strings.TrimSpace(raw))
+		if err != nil {
+			localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+			return
+		}
+
+		if !token.CanWrite() && !isReadOnlyMethod(ctx.Request.Method) {
+			localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenReadOnly))
+			return
+		}
+
+		ctx.Set(localHttp.AuthUserIDKey, int64(token.Account.Entity.ID))
+		ctx.Set(localHttp.AuthUsernameKey, token.Account.Username)
+		ctx.Set(localHttp.AuthAccessTokenKey, token)
+		ctx.Next()
+	}
+}
+
+// CurrentAccessToken returns the token the request was authenticated with. A request
+// made with a session has no token and is not restricted by one.
+func CurrentAccessToken(ctx *gin.Context) (entity.AccessToken, bool) {
+	value, ok := ctx.Get(localHttp.AuthAccessTokenKey)
+	if !ok {
+		return entity.AccessToken{}, false
+	}
+
+	token, ok := value.(entity.AccessToken)
+	return token, ok
+}
+
+// APITokenHandler tells a script who it is calling as and what its token allows.
+func APITokenHandler(ctx *gin.Context) {
+	response := model.APIToken{
+		UserID:   types.ID(ctx.GetInt64(localHttp.AuthUserIDKey)),
+		Username: ctx.GetString(localHttp.AuthUsernameKey),
+	}
+
+	if token, ok := CurrentAccessToken(ctx); ok {
+		response.Token = model.NewAPIAccessToken(token)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func accessTokenPageData(ctx *gin.Context, usecase usecase.AccessTokenUsecase, groupUsecase usecase.GroupUsecase) (gin.H, error) {
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	tokens, err := usecase.Read(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, _, err := groupUsecase.Read(ctx, param.ReadGroupParams{MemberID: userID, Limit: accessTokenGroupLimit})
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"Username":   ctx.GetString(localHttp.AuthUsernameKey),
+		"LogoutUrl":  localHttp.PathLogout,
+		"Tokens":     tokens,
+		"Groups":     groups,
+		"CreatePath": localHttp.PathAccessTokenCreate,
+		"RevokePath": localHttp.PathAccessTokenRevoke,
+		"APIPath":    localHttp.PathAPIToken,
+	}, nil
+}
+
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
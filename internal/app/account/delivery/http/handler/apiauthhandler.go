@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// APIOpaqueConfigHandler serves the suite of the server, it is public since the
+// clients need it before they log in.
+func APIOpaqueConfigHandler(ctx *gin.Context, conf *config.Config) {
+	suite := conf.GetOpaqueSuite()
+	ctx.JSON(http.StatusOK, model.APIOpaqueConfig{ServerID: conf.Opaque.ServerID, Suite: suite.Name, KSF: suite.KSF})
+}
+
+func APILoginInitHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
+	var body model.APILoginInit
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		localHttp.HandleAPIBindError(ctx, err)
+		return
+	}
+
+	ke2, loginID, err := usecase.APILoginInit(ctx, body.KE1, body.Username, ctx.ClientIP())
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.APILoginChallenge{KE2: ke2, LoginID: string(loginID)})
+}
+
+func APILoginFinalizeHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
+	var body model.APILoginFinalize
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		localHttp.HandleAPIBindError(ctx, err)
+		return
+	}
+
+	twoFactor, err := usecase.APILoginFinalize(ctx, types.CacheID(body.LoginID), body.KE3)
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.APITwoFactorChallenge{TwoFactorID: string(twoFactor.ID)})
+}
+
+// APITwoFactorHandler finishes the login with an access token instead of a session,
+// along with the wrapped vault key the client unwraps with its export key.
+func APITwoFactorHandler(ctx *gin.Context, authUsecase usecase.AuthUsecase, accessTokenUsecase usecase.AccessTokenUsecase) {
+	var body model.APITwoFactor
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		localHttp.HandleAPIBindError(ctx, err)
+		return
+	}
+
+	acc, err := authUsecase.ValidateTwoFactor(ctx, types.CacheID(body.TwoFactorID), body.VerificationCode, ctx.ClientIP())
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	raw, token, err := accessTokenUsecase.Create(
+		ctx, acc.Entity.ID, body.TokenName, entity.AccessTokenReadWrite, body.ExpiryDays, nil, nil,
+	)
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.APILogin{
+		Token:             raw,
+		AccessToken:       *model.NewAPIAccessToken(token),
+		EncryptedVaultKey: acc.EncryptedVaultKey,
+		Rekey:             authUsecase.NeedsRekey(acc),
+	})
+}
+
+// APITokenRevokeHandler revokes the access token the request is made with, which is
+// how a client of the API logs out.
+func APITokenRevokeHandler(ctx *gin.Context, usecase usecase.AccessTokenUsecase) {
+	token, ok := CurrentAccessToken(ctx)
+	if !ok {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenDoesNotExist))
+		return
+	}
+
+	if err := usecase.Revoke(ctx, token.Account.Entity.ID, token.ID); err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// APIRekeyInitHandler, APIRekeyVerifyHandler and APIRekeyFinalizeHandler move the
+// account to the current server keys, a token restricted to some items or groups can
+// not touch the account itself.
+func APIRekeyInitHandler(ctx *gin.Context, usecase usecase.PasswordUsecase) {
+	if token, ok := CurrentAccessToken(ctx); ok && token.Restricted() {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenRestricted))
+		return
+	}
+
+	var body model.APIRekeyInit
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		localHttp.HandleAPIBindError(ctx, err)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	ke2, changeID, err := usecase.ChangeInit(ctx, userID, body.KE1)
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.APIRekeyChallenge{KE2: ke2, ChangeID: string(changeID)})
+}
+
+func APIRekeyVerifyHandler(ctx *gin.Context, usecase usecase.PasswordUsecase) {
+	if token, ok := CurrentAccessToken(ctx); ok && token.Restricted() {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenRestricted))
+		return
+	}
+
+	var body model.APIRekeyVerify
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		localHttp.HandleAPIBindError(ctx, err)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	response, encryptedVaultKey, err := usecase.ChangeVerify(
+		ctx, userID, types.CacheID(body.ChangeID), body.KE3, body.RegistrationRequest,
+	)
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.APIRekeyRegistration{RegistrationResponse: response, EncryptedVaultKey: encryptedVaultKey})
+}
+
+func APIRekeyFinalizeHandler(ctx *gin.Context, usecase usecase.PasswordUsecase) {
+	if token, ok := CurrentAccessToken(ctx); ok && token.Restricted() {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenRestricted))
+		return
+	}
+
+	var body model.APIRekeyFinalize
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		localHttp.HandleAPIBindError(ctx, err)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	err := usecase.RekeyFinalize(ctx, userID, types.CacheID(body.ChangeID), body.RegistrationRecord, body.EncryptedVaultKey)
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
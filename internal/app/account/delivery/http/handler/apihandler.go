@@ -0,0 +1,430 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/param"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/convertors"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+func APIAccountReadHandler(ctx *gin.Context, usecase usecase.AccountUsecase) {
+	acc, err := usecase.Read(ctx, types.ID(ctx.GetInt64(localHttp.AuthUserIDKey)))
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIAccount(acc))
+}
+
+// APIAccountUpdateHandler changes the name of the account, a token restricted to some
+// items or groups can not touch the account itself.
+func APIAccountUpdateHandler(ctx *gin.Context, usecase usecase.AccountUsecase) {
+	if token, ok := CurrentAccessToken(ctx); ok && token.Restricted() {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenRestricted))
+		return
+	}
+
+	var body model.APIAccountUpdate
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		localHttp.HandleAPIBindError(ctx, err)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	if err := usecase.UpdateProfile(ctx, userID, body.FirstName, body.LastName); err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	APIAccountReadHandler(ctx, usecase)
+}
+
+func APIAccountSearchHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	acc, err := usecase.SearchMember(ctx, ctx.Query("username"))
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.APIMember{ID: acc.Entity.ID, Username: acc.Username})
+}
+
+func APIGroupListHandler(ctx *gin.Context, usecase usecase.GroupUsecase, conf *config.Config) {
+	if token, ok := CurrentAccessToken(ctx); ok && token.Restricted() {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenRestricted))
+		return
+	}
+
+	page := convertors.ParseQueryParamToInt(ctx.Query(localHttp.PageKeyParam), conf.DefaultPage)
+	pageSize := convertors.ParseQueryParamToInt(ctx.Query(localHttp.PageSizeKeyParam), conf.DefaultPageSize)
+	limit, offset := convertors.SimplePaginationToLimitOffset(page, pageSize)
+
+	groups, numRows, err := usecase.Read(ctx, param.ReadGroupParams{
+		MemberID:    types.ID(ctx.GetInt64(localHttp.AuthUserIDKey)),
+		SearchQuery: types.NewNullString(ctx.Query(localHttp.SearchKeyParam)),
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	response := model.APIGroupList{
+		Groups:     make([]model.APIGroup, 0, len(groups)),
+		Pagination: localHttp.APIPagination{Page: page, PageSize: pageSize, Total: numRows},
+	}
+	for _, group := range groups {
+		response.Groups = append(response.Groups, model.NewAPIGroup(group))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func APIGroupCreateHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	if token, ok := CurrentAccessToken(ctx); ok && token.Restricted() {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenRestricted))
+		return
+	}
+
+	var body model.APIGroupWrite
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		localHttp.HandleAPIBindError(ctx, err)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	group := apiGroup(body, userID)
+	if err := usecase.Create(ctx, &group); err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	created, err := usecase.ReadOne(ctx, group.ID, userID)
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, model.NewAPIGroup(created))
+}
+
+func APIGroupReadHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	group, ok := apiReadGroup(ctx, usecase)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIGroup(group))
+}
+
+func APIGroupUpdateHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	group, ok := apiReadGroup(ctx, usecase)
+	if !ok {
+		return
+	}
+
+	var body model.APIGroupWrite
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		localHttp.HandleAPIBindError(ctx, err)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	updated := apiGroup(body, userID)
+	updated.ID = group.ID
+	if err := usecase.Update(ctx, updated.Owner, updated); err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	APIGroupReadHandler(ctx, usecase)
+}
+
+func APIGroupDeleteHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	group, ok := apiReadGroup(ctx, usecase)
+	if !ok {
+		return
+	}
+
+	if err := usecase.Delete(ctx, group.ID, types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))); err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// APIGroupMemberUpdateHandler gives a member of the group another role, the other
+// members are left as they are.
+func APIGroupMemberUpdateHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	group, memberID, ok := apiReadGroupMember(ctx, usecase)
+	if !ok {
+		return
+	}
+
+	var body model.APIGroupMemberWrite
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		localHttp.HandleAPIBindError(ctx, err)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	if err := usecase.ChangeMemberRole(ctx, userID, group.ID, memberID, body.Role); err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	APIGroupReadHandler(ctx, usecase)
+}
+
+// APIGroupMemberDeleteHandler removes a member from the group, the items it shared with
+// the group stop being shared with it.
+func APIGroupMemberDeleteHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	group, memberID, ok := apiReadGroupMember(ctx, usecase)
+	if !ok {
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	if err := usecase.RemoveMember(ctx, userID, group.ID, memberID); err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func APIGroupEventsHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	group, ok := apiReadGroup(ctx, usecase)
+	if !ok {
+		return
+	}
+
+	events, err := usecase.ReadMemberEvents(ctx, group.ID, types.ID(ctx.GetInt64(localHttp.AuthUserIDKey)))
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIGroupMemberEventList(events))
+}
+
+// APIGroupInviteHandler invites an email to the group, the account that signed up with
+// it when there is one.
+func APIGroupInviteHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	group, ok := apiReadGroup(ctx, usecase)
+	if !ok {
+		return
+	}
+
+	var body model.APIGroupInvite
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		localHttp.HandleAPIBindError(ctx, err)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	invitation, err := usecase.Invite(ctx, userID, group.ID, body.Email, body.Role)
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, model.NewAPIGroupInvitation(invitation))
+}
+
+func APIGroupInvitationsHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	group, ok := apiReadGroup(ctx, usecase)
+	if !ok {
+		return
+	}
+
+	invitations, err := usecase.ReadGroupInvitations(ctx, group.ID, types.ID(ctx.GetInt64(localHttp.AuthUserIDKey)))
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIGroupInvitationList(invitations))
+}
+
+// APIInvitationListHandler lists the invitations the account received, a token
+// restricted to some items or groups can not see them.
+func APIInvitationListHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	if token, ok := CurrentAccessToken(ctx); ok && token.Restricted() {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenRestricted))
+		return
+	}
+
+	invitations, err := usecase.ReadInvitations(ctx, types.ID(ctx.GetInt64(localHttp.AuthUserIDKey)))
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIGroupInvitationList(invitations))
+}
+
+func APIInvitationAcceptHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	apiMembershipAction(ctx, usecase.AcceptInvitation, account.GroupInvitationDoesNotExist)
+}
+
+func APIInvitationDeclineHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	apiMembershipAction(ctx, usecase.DeclineInvitation, account.GroupInvitationDoesNotExist)
+}
+
+func APIInvitationCancelHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	apiMembershipAction(ctx, usecase.CancelInvitation, account.GroupInvitationDoesNotExist)
+}
+
+// APIGroupLeaveHandler takes the account out of the group, the items it shared with the
+// group stop being shared with it.
+func APIGroupLeaveHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	apiMembershipAction(ctx, usecase.Leave, account.GroupDoesNotExist)
+}
+
+// APIGroupTransferHandler offers the group to another member, it changes hands once the
+// member accepts.
+func APIGroupTransferHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	group, ok := apiReadGroup(ctx, usecase)
+	if !ok {
+		return
+	}
+
+	var body model.APIGroupTransfer
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		localHttp.HandleAPIBindError(ctx, err)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	if err := usecase.OfferOwnership(ctx, userID, group.ID, body.NewOwnerID); err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	APIGroupReadHandler(ctx, usecase)
+}
+
+// APIGroupTransferDeleteHandler drops the ownership offer of the group, the owner takes
+// it back or the member it was offered to declines it.
+func APIGroupTransferDeleteHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	apiMembershipAction(ctx, usecase.DeleteOwnershipOffer, account.GroupOwnershipOfferDoesNotExist)
+}
+
+func APIGroupTransferAcceptHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	apiMembershipAction(ctx, usecase.AcceptOwnership, account.GroupOwnershipOfferDoesNotExist)
+}
+
+// APIOwnershipOfferListHandler lists the groups offered to the account, a token
+// restricted to some items or groups can not see them.
+func APIOwnershipOfferListHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	if token, ok := CurrentAccessToken(ctx); ok && token.Restricted() {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenRestricted))
+		return
+	}
+
+	offers, err := usecase.ReadOwnershipOffers(ctx, types.ID(ctx.GetInt64(localHttp.AuthUserIDKey)))
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIOwnershipOfferList(offers))
+}
+
+// apiMembershipAction runs an action on the invitation or the group of the id param and
+// answers with notFound when the id is not a number, a restricted token can not join or
+// leave groups.
+func apiMembershipAction(ctx *gin.Context, action func(ctx context.Context, accountID, id types.ID) error, notFound error) {
+	if token, ok := CurrentAccessToken(ctx); ok && token.Restricted() {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenRestricted))
+		return
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(notFound))
+		return
+	}
+
+	if err := action(ctx, types.ID(ctx.GetInt64(localHttp.AuthUserIDKey)), types.ID(id)); err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// apiReadGroup reads the group of the id param and answers with the error itself when
+// the account is not a member of it or the token is not allowed to use it.
+func apiReadGroup(ctx *gin.Context, usecase usecase.GroupUsecase) (entity.Group, bool) {
+	groupID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.GroupDoesNotExist))
+		return entity.Group{}, false
+	}
+
+	if token, ok := CurrentAccessToken(ctx); ok && !token.AllowsGroup(types.ID(groupID)) {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenRestricted))
+		return entity.Group{}, false
+	}
+
+	group, err := usecase.ReadOne(ctx, types.ID(groupID), types.ID(ctx.GetInt64(localHttp.AuthUserIDKey)))
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return entity.Group{}, false
+	}
+
+	if !group.ID.Valid() {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.GroupDoesNotExist))
+		return entity.Group{}, false
+	}
+
+	return group, true
+}
+
+// apiReadGroupMember reads the group like apiReadGroup along with the memberID param.
+func apiReadGroupMember(ctx *gin.Context, usecase usecase.GroupUsecase) (entity.Group, types.ID, bool) {
+	group, ok := apiReadGroup(ctx, usecase)
+	if !ok {
+		return entity.Group{}, 0, false
+	}
+
+	memberID, err := strconv.ParseInt(ctx.Param("memberID"), 10, 64)
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.GroupMemberDoesNotExist))
+		return entity.Group{}, 0, false
+	}
+
+	return group, types.ID(memberID), true
+}
+
+func apiGroup(body model.APIGroupWrite, ownerID types.ID) entity.Group {
+	group := entity.Group{
+		Name:        body.Name,
+		Description: types.NewNullString(body.Description),
+		Owner:       entity.Account{Entity: base.Entity{ID: ownerID}},
+		Roles:       body.Roles,
+	}
+
+	for _, memberID := range body.MemberIDs {
+		group.Members = append(group.Members, entity.Account{Entity: base.Entity{ID: memberID}})
+	}
+
+	return group
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+func AuthenticatorEnrollHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
+	templateName := "authenticator.html"
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	data := gin.H{
+		"Username":   ctx.GetString(localHttp.AuthUsernameKey),
+		"LogoutUrl":  localHttp.PathLogout,
+		"action":     localHttp.PathAuthenticator,
+		"confirmUrl": localHttp.PathAuthenticatorConfirm,
+	}
+
+	switch ctx.Request.Method {
+	case http.MethodGet:
+		codesLeft, err := usecase.RecoveryCodesLeft(ctx, userID)
+		if err != nil {
+			localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
+			return
+		}
+
+		data["RecoveryCodesLeft"] = codesLeft
+		ctx.HTML(http.StatusOK, templateName, data)
+
+	case http.MethodPost:
+		var form model.TwoFactorModel
+		if err := ctx.ShouldBind(&form); err != nil {
+			formErr := errors.NewError(err.Error(), http.StatusBadRequest)
+			localHttp.HandlerFormError(ctx, formErr, templateName, data)
+			return
+		}
+
+		authenticator, enrollmentID, err := usecase.EnrollAuthenticatorInit(ctx, userID, form.VerificationCode)
+		if err != nil {
+			localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
+			return
+		}
+
+		data["QRCode"] = base64.StdEncoding.EncodeToString(authenticator.QrCode)
+		data["Secret"] = authenticator.Secret
+		data["EnrollmentID"] = enrollmentID
+		ctx.HTML(http.StatusOK, templateName, data)
+	}
+}
+
+func AuthenticatorConfirmHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
+	templateName := "authenticator.html"
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	data := gin.H{
+		"Username":   ctx.GetString(localHttp.AuthUsernameKey),
+		"LogoutUrl":  localHttp.PathLogout,
+		"action":     localHttp.PathAuthenticator,
+		"confirmUrl": localHttp.PathAuthenticatorConfirm,
+	}
+
+	var form model.AuthenticatorConfirmModel
+	if err := ctx.ShouldBind(&form); err != nil {
+		formErr := errors.NewError(err.Error(), http.StatusBadRequest)
+		localHttp.HandlerFormError(ctx, formErr, templateName, data)
+		return
+	}
+
+	data["EnrollmentID"] = form.EnrollmentID
+
+	recoveryCodes, err := usecase.EnrollAuthenticatorFinalize(ctx, userID, types.CacheID(form.EnrollmentID), form.VerificationCode)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
+		return
+	}
+
+	data["RecoveryCodes"] = recoveryCodes
+	ctx.HTML(http.StatusOK, templateName, data)
+}
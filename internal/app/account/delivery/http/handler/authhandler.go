@@ -8,6 +8,7 @@ import (
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
 	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	sessionStore "github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/session"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
 	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
 	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
@@ -45,6 +46,22 @@ func SignUpInitialHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
 	ctx.JSON(http.StatusAccepted, gin.H{"record": base64.StdEncoding.EncodeToString(record), "registrationID": cacheID})
 }
 
+func SignUpVerifyHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
+	var body model.SignUpVerifyModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	err := usecase.VerifyEmail(ctx, types.CacheID(body.RegistrationID), body.VerificationCode)
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "email verified"})
+}
+
 func SignUpFinalizeHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
 	var body model.SignUpFinalizeModel
 	if err := ctx.ShouldBind(&body); err != nil {
@@ -58,7 +75,17 @@ func SignUpFinalizeHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
 		return
 	}
 
-	authenticator, username, err := usecase.SignUpFinalize(ctx, recordBytes, types.CacheID(body.RegistrationID))
+	// the vault key and the recovery kit are optional, an empty field decodes to nothing
+	encryptedVaultKey, errVaultKey := base64.StdEncoding.DecodeString(body.EncryptedVaultKey)
+	recoveryProof, errProof := base64.StdEncoding.DecodeString(body.RecoveryProof)
+	recoveryVaultKey, errRecoveryKey := base64.StdEncoding.DecodeString(body.RecoveryVaultKey)
+	if errVaultKey != nil || errProof != nil || errRecoveryKey != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": "invalid vault key encoding"})
+		return
+	}
+
+	kit := entity.RecoveryKit{Proof: recoveryProof, EncryptedVaultKey: recoveryVaultKey}
+	authenticator, username, recoveryCodes, err := usecase.SignUpFinalize(ctx, recordBytes, types.CacheID(body.RegistrationID), encryptedVaultKey, kit)
 	if err != nil {
 		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
 		return
@@ -83,13 +110,14 @@ func SignUpFinalizeHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
 
 	ctx.HTML(http.StatusOK, "qrcode.html", gin.H{
 		"QRCode":        base64Img,
+		"RecoveryCodes": recoveryCodes,
 		"twoFactorPath": localHttp.PathTwoFactor,
 	})
 }
 
 func LoginHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
 	templateName := "login.html"
-	data := gin.H{"signUpUrl": localHttp.PathSignUp}
+	data := gin.H{"signUpUrl": localHttp.PathSignUp, "recoverUrl": localHttp.PathRecover}
 
 	switch ctx.Request.Method {
 	case http.MethodGet:
@@ -130,7 +158,7 @@ func LoginInitHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
 		return
 	}
 
-	ke2, err := usecase.LoginInit(ctx, body.KE1, body.Username)
+	ke2, err := usecase.LoginInit(ctx, body.KE1, body.Username, ctx.ClientIP())
 	if err != nil {
 		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
 		return
@@ -139,7 +167,7 @@ func LoginInitHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
 	ctx.JSON(http.StatusOK, gin.H{"ke2": ke2})
 }
 
-func TwoFactorHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
+func TwoFactorHandler(ctx *gin.Context, usecase usecase.AuthUsecase, sessionUsecase usecase.SessionUsecase, store *sessionStore.RedisStore) {
 	templateName := "two_factor.html"
 	data := gin.H{"action": localHttp.PathTwoFactor}
 
@@ -163,12 +191,20 @@ func TwoFactorHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
 			return
 		}
 
-		account, err := usecase.ValidateTwoFactor(ctx, types.CacheID(twoFactorID), form.VerificationCode)
+		account, err := usecase.ValidateTwoFactor(ctx, types.CacheID(twoFactorID), form.VerificationCode, ctx.ClientIP())
 		if err != nil {
 			localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
 			return
 		}
 
+		// a fresh session id keeps a session planted before the login from being reused
+		if err := store.Renew(ctx.Request, localHttp.SessionName); err != nil {
+			log.ErrorLogger.Error("can not renew session", "error", err.Error())
+			localHttp.NewServerError(ctx)
+			return
+		}
+
+		session.Delete(localHttp.AuthTwoFactorIDKey)
 		session.Set(localHttp.AuthUsernameKey, account.Username)
 		session.Set(localHttp.AuthUserIDKey, int64(account.Entity.ID))
 
@@ -178,20 +214,36 @@ func TwoFactorHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
 			return
 		}
 
+		err = sessionUsecase.Create(ctx, account.Entity.ID, types.CacheID(session.ID()), ctx.ClientIP(), ctx.Request.UserAgent())
+		if err != nil {
+			localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
+			return
+		}
+
 		ctx.Redirect(http.StatusFound, localHttp.PathMe)
 		ctx.Abort()
 	}
 }
 
-func LogoutHandler(ctx *gin.Context) {
+func LogoutHandler(ctx *gin.Context, usecase usecase.SessionUsecase) {
 	session := sessions.Default(ctx)
-	session.Delete(localHttp.AuthUserIDKey)
-	session.Delete(localHttp.AuthUsernameKey)
+	userID, _ := session.Get(localHttp.AuthUserIDKey).(int64)
+	sessionID := session.ID()
+
+	session.Clear()
+	session.Options(sessions.Options{Path: "/", MaxAge: -1})
 
 	if err := session.Save(); err != nil {
 		localHttp.NewServerError(ctx)
 		return
 	}
 
+	if userID != 0 && sessionID != "" {
+		if err := usecase.Logout(ctx, types.ID(userID), types.CacheID(sessionID)); err != nil {
+			localHttp.HandleError(ctx, errors.Error2Custom(err), "general_error.html", gin.H{})
+			return
+		}
+	}
+
 	ctx.Redirect(http.StatusFound, localHttp.PathLogin)
 }
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+func EmergencyAccessListHandler(ctx *gin.Context, usecase usecase.EmergencyAccessUsecase, keyUsecase usecase.KeyUsecase) {
+	templateName := "emergency_access.html"
+	data, err := emergencyAccessPageData(ctx, usecase, keyUsecase)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, gin.H{})
+		return
+	}
+
+	ctx.HTML(http.StatusOK, templateName, data)
+}
+
+func EmergencyAccessCreateHandler(ctx *gin.Context, usecase usecase.EmergencyAccessUsecase, keyUsecase usecase.KeyUsecase) {
+	templateName := "emergency_access.html"
+	data, err := emergencyAccessPageData(ctx, usecase, keyUsecase)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, gin.H{})
+		return
+	}
+
+	var form model.EmergencyAccessCreate
+	if err := ctx.ShouldBind(&form); err != nil {
+		formErr := errors.NewError(err.Error(), http.StatusBadRequest)
+		localHttp.HandlerFormError(ctx, formErr, templateName, data)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	if _, err := usecase.Create(ctx, userID, form.Username, form.WaitDays); err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, localHttp.PathEmergencyAccess)
+}
+
+func EmergencyAccessAcceptHandler(ctx *gin.Context, usecase usecase.EmergencyAccessUsecase) {
+	emergencyAccessAction(ctx, usecase.Accept)
+}
+
+func EmergencyAccessRequestHandler(ctx *gin.Context, usecase usecase.EmergencyAccessUsecase) {
+	emergencyAccessAction(ctx, usecase.RequestAccess)
+}
+
+func EmergencyAccessApproveHandler(ctx *gin.Context, usecase usecase.EmergencyAccessUsecase) {
+	emergencyAccessAction(ctx, usecase.Approve)
+}
+
+func EmergencyAccessRejectHandler(ctx *gin.Context, usecase usecase.EmergencyAccessUsecase) {
+	emergencyAccessAction(ctx, usecase.Reject)
+}
+
+func EmergencyAccessDeleteHandler(ctx *gin.Context, usecase usecase.EmergencyAccessUsecase) {
+	emergencyAccessAction(ctx, usecase.Delete)
+}
+
+func EmergencyAccessConfirmHandler(ctx *gin.Context, usecase usecase.EmergencyAccessUsecase) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "emergency access does not exist"})
+		return
+	}
+
+	var body model.EmergencyAccessConfirm
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	if err := usecase.Confirm(ctx, userID, types.ID(id), body.EncryptedVaultKey); err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "emergency access confirmed"})
+}
+
+func EmergencyAccessVaultKeyHandler(ctx *gin.Context, usecase usecase.EmergencyAccessUsecase) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"message": "emergency access does not exist"})
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	encryptedVaultKey, err := usecase.ReadVaultKey(ctx, userID, types.ID(id))
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"encryptedVaultKey": encryptedVaultKey})
+}
+
+// emergencyAccessAction runs a state change of the access in the path on behalf of the
+// current user and goes back to the list.
+func emergencyAccessAction(ctx *gin.Context, action func(ctx context.Context, accountID, id types.ID) error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		localHttp.HandleNotFoundError(ctx)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	if err := action(ctx, userID, types.ID(id)); err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), "general_error.html", gin.H{})
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, localHttp.PathEmergencyAccess)
+}
+
+func emergencyAccessPageData(ctx *gin.Context, usecase usecase.EmergencyAccessUsecase, keyUsecase usecase.KeyUsecase) (gin.H, error) {
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	granted, received, err := usecase.Read(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	acc, err := keyUsecase.Read(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"Username":        ctx.GetString(localHttp.AuthUsernameKey),
+		"LogoutUrl":       localHttp.PathLogout,
+		"Granted":         granted,
+		"Received":        received,
+		"HasKeys":         len(acc.PublicKey) > 0,
+		"KeysPath":        localHttp.PathKeys,
+		"UnlockInitPath":  localHttp.PathKeysUnlockInit,
+		"UnlockFinalPath": localHttp.PathKeysUnlockFinal,
+		"CreatePath":      localHttp.PathEmergencyAccessCreate,
+		"AcceptPath":      localHttp.PathEmergencyAccessAccept,
+		"ConfirmPath":     localHttp.PathEmergencyAccessConfirm,
+		"RequestPath":     localHttp.PathEmergencyAccessRequest,
+		"ApprovePath":     localHttp.PathEmergencyAccessApprove,
+		"RejectPath":      localHttp.PathEmergencyAccessReject,
+		"DeletePath":      localHttp.PathEmergencyAccessDelete,
+		"VaultKeyPath":    localHttp.PathEmergencyAccessVaultKey,
+	}, nil
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -41,14 +42,16 @@ func GroupListHandler(ctx *gin.Context, usecase usecase.GroupUsecase, conf *conf
 	}
 
 	ctx.HTML(http.StatusOK, templateName, gin.H{
-		"Username":    username,
-		"LogoutUrl":   localHttp.PathLogout,
-		"EditPath":    localHttp.PathGroupEdit,
-		"DeletePath":  localHttp.PathGroupDelete,
-		"Groups":      groups,
-		"Pagination":  paginator.PaginationForTemplate(paginator.GetTotalPage(numRows, pageSize), page, ctx.Request.URL.Query()),
-		"SearchQuery": searchQuery,
-		"CreateUrl":   localHttp.PathGroupCreate,
+		"Username":       username,
+		"LogoutUrl":      localHttp.PathLogout,
+		"EditPath":       localHttp.PathGroupEdit,
+		"DeletePath":     localHttp.PathGroupDelete,
+		"LeavePath":      localHttp.PathGroupLeave,
+		"Groups":         groups,
+		"Pagination":     paginator.PaginationForTemplate(paginator.GetTotalPage(numRows, pageSize), page, ctx.Request.URL.Query()),
+		"SearchQuery":    searchQuery,
+		"CreateUrl":      localHttp.PathGroupCreate,
+		"InvitationsUrl": localHttp.PathGroupInvitations,
 	})
 }
 
@@ -104,21 +107,14 @@ func GroupEditHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
 		return
 	}
 
-	data := gin.H{
-		"SearchUrl": localHttp.PathGroupSearchMember,
-		"Action":    fmt.Sprint(localHttp.PathGroupEdit, groupID),
-		"LogoutUrl": localHttp.PathLogout,
-		"Username":  ctx.GetString(localHttp.AuthUsernameKey),
-	}
+	data := groupEditPageData(ctx, types.ID(groupID))
 
 	switch ctx.Request.Method {
 	case http.MethodGet:
-		group, err := usecase.ReadOne(ctx, types.ID(groupID), userID)
-		if err != nil {
+		if err := readGroupEditPage(ctx, usecase, types.ID(groupID), data); err != nil {
 			localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
 			return
 		}
-		data["Group"] = group
 		ctx.HTML(http.StatusOK, templateName, data)
 
 	case http.MethodPost:
@@ -134,6 +130,7 @@ func GroupEditHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
 			Name:        form.Name,
 			Description: types.NewNullString(form.Description),
 			Owner:       entity.Account{Entity: base.Entity{ID: userID}},
+			Roles:       groupRoles(ctx),
 		}
 
 		for _, memberID := range form.MembersID {
@@ -167,6 +164,137 @@ func GroupDeleteHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
 	ctx.Redirect(http.StatusSeeOther, localHttp.PathGroupList)
 }
 
+// GroupInviteHandler invites the email to the group in the path and goes back to the
+// edit page of the group.
+func GroupInviteHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	templateName := "group_edit.html"
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	groupID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		localHttp.HandleNotFoundError(ctx)
+		return
+	}
+
+	data := groupEditPageData(ctx, types.ID(groupID))
+	if err := readGroupEditPage(ctx, usecase, types.ID(groupID), data); err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
+		return
+	}
+
+	var form model.GroupInvite
+	if err := ctx.ShouldBind(&form); err != nil {
+		formErr := errors.NewError(err.Error(), http.StatusBadRequest)
+		localHttp.HandlerFormError(ctx, formErr, templateName, data)
+		return
+	}
+
+	if _, err := usecase.Invite(ctx, userID, types.ID(groupID), form.Email, form.Role); err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, fmt.Sprint(localHttp.PathGroupEdit, groupID))
+}
+
+func GroupInvitationListHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	templateName := "group_invitations.html"
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	invitations, err := usecase.ReadInvitations(ctx, userID)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, gin.H{})
+		return
+	}
+
+	offers, err := usecase.ReadOwnershipOffers(ctx, userID)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, gin.H{})
+		return
+	}
+
+	ctx.HTML(http.StatusOK, templateName, gin.H{
+		"Username":         ctx.GetString(localHttp.AuthUsernameKey),
+		"LogoutUrl":        localHttp.PathLogout,
+		"Invitations":      invitations,
+		"AcceptPath":       localHttp.PathGroupInvitationAccept,
+		"DeclinePath":      localHttp.PathGroupInvitationDecline,
+		"Offers":           offers,
+		"OfferAcceptPath":  localHttp.PathGroupOwnershipAccept,
+		"OfferDeclinePath": localHttp.PathGroupOwnershipDecline,
+		"GroupsPath":       localHttp.PathGroupList,
+	})
+}
+
+func GroupInvitationAcceptHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	groupAction(ctx, usecase.AcceptInvitation, localHttp.PathGroupInvitations)
+}
+
+func GroupInvitationDeclineHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	groupAction(ctx, usecase.DeclineInvitation, localHttp.PathGroupInvitations)
+}
+
+// GroupInvitationCancelHandler is posted from the edit page of the group, which sends
+// the id of the group to go back to.
+func GroupInvitationCancelHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	redirect := localHttp.PathGroupList
+	if groupID, err := strconv.ParseInt(ctx.PostForm("group"), 10, 64); err == nil {
+		redirect = fmt.Sprint(localHttp.PathGroupEdit, groupID)
+	}
+
+	groupAction(ctx, usecase.CancelInvitation, redirect)
+}
+
+// GroupLeaveHandler takes the current user out of the group in the path.
+func GroupLeaveHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	groupAction(ctx, usecase.Leave, localHttp.PathGroupList)
+}
+
+// GroupOwnershipOfferHandler offers the group in the path to the chosen member and goes
+// back to the edit page of the group.
+func GroupOwnershipOfferHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	templateName := "group_edit.html"
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	groupID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		localHttp.HandleNotFoundError(ctx)
+		return
+	}
+
+	data := groupEditPageData(ctx, types.ID(groupID))
+	if err := readGroupEditPage(ctx, usecase, types.ID(groupID), data); err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
+		return
+	}
+
+	var form model.GroupOwnershipOffer
+	if err := ctx.ShouldBind(&form); err != nil {
+		formErr := errors.NewError(err.Error(), http.StatusBadRequest)
+		localHttp.HandlerFormError(ctx, formErr, templateName, data)
+		return
+	}
+
+	if err := usecase.OfferOwnership(ctx, userID, types.ID(groupID), form.NewOwnerID); err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, fmt.Sprint(localHttp.PathGroupEdit, groupID))
+}
+
+// GroupOwnershipCancelHandler takes back the ownership offer of the group in the path
+// from its edit page.
+func GroupOwnershipCancelHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	groupAction(ctx, usecase.DeleteOwnershipOffer, fmt.Sprint(localHttp.PathGroupEdit, ctx.Param("id")))
+}
+
+func GroupOwnershipAcceptHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	groupAction(ctx, usecase.AcceptOwnership, localHttp.PathGroupList)
+}
+
+func GroupOwnershipDeclineHandler(ctx *gin.Context, usecase usecase.GroupUsecase) {
+	groupAction(ctx, usecase.DeleteOwnershipOffer, localHttp.PathGroupInvitations)
+}
+
 func GroupSearchMember(ctx *gin.Context, usecase usecase.GroupUsecase) {
 	username := ctx.Query("username")
 	account, err := usecase.SearchMember(ctx, username)
@@ -179,3 +307,75 @@ func GroupSearchMember(ctx *gin.Context, usecase usecase.GroupUsecase) {
 	ctx.Header("Content-Type", "text/html; charset=utf-8")
 	ctx.String(http.StatusOK, option)
 }
+
+// groupRoles reads the roles[<account id>] fields of the edit form.
+func groupRoles(ctx *gin.Context) map[types.ID]entity.GroupRole {
+	roles := make(map[types.ID]entity.GroupRole)
+	for id, role := range ctx.PostFormMap("roles") {
+		accountID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			continue
+		}
+		roles[types.ID(accountID)] = entity.GroupRole(role)
+	}
+
+	return roles
+}
+
+// groupInvitationAction runs an action on the invitation in the path on behalf of the
+// current user and redirects to the given path.
+func groupAction(ctx *gin.Context, action func(ctx context.Context, accountID, id types.ID) error, redirect string) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		localHttp.HandleNotFoundError(ctx)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	if err := action(ctx, userID, types.ID(id)); err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), "general_error.html", gin.H{})
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, redirect)
+}
+
+func groupEditPageData(ctx *gin.Context, groupID types.ID) gin.H {
+	return gin.H{
+		"SearchUrl":       localHttp.PathGroupSearchMember,
+		"Action":          fmt.Sprint(localHttp.PathGroupEdit, groupID),
+		"InvitePath":      fmt.Sprint(localHttp.PathGroupInvite, groupID, "/"),
+		"CancelPath":      localHttp.PathGroupInvitationCancel,
+		"OfferPath":       fmt.Sprint(localHttp.PathGroupOwnershipOffer, groupID, "/"),
+		"OfferCancelPath": fmt.Sprint(localHttp.PathGroupOwnershipCancel, groupID, "/"),
+		"LogoutUrl":       localHttp.PathLogout,
+		"Username":        ctx.GetString(localHttp.AuthUsernameKey),
+	}
+}
+
+// readGroupEditPage adds the group, its pending invitations and the latest changes to
+// its members to the data of the edit page.
+func readGroupEditPage(ctx *gin.Context, usecase usecase.GroupUsecase, groupID types.ID, data gin.H) error {
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	group, err := usecase.ReadOne(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	data["Group"] = group
+
+	invitations, err := usecase.ReadGroupInvitations(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	data["Invitations"] = invitations
+
+	events, err := usecase.ReadMemberEvents(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	data["Events"] = events
+
+	return nil
+}
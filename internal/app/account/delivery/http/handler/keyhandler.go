@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+func KeysPublishHandler(ctx *gin.Context, usecase usecase.KeyUsecase) {
+	var body model.KeysPublishModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	if err := usecase.Publish(ctx, userID, body.PublicKey, body.EncryptedPrivateKey); err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"message": "keys published"})
+}
+
+func KeysUnlockInitHandler(ctx *gin.Context, usecase usecase.KeyUsecase) {
+	var body model.KeysUnlockInitModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	ke2, unlockID, err := usecase.UnlockInit(ctx, userID, body.KE1)
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"ke2": ke2, "unlockID": unlockID})
+}
+
+func KeysUnlockFinalizeHandler(ctx *gin.Context, usecase usecase.KeyUsecase) {
+	var body model.KeysUnlockFinalizeModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	acc, err := usecase.UnlockFinalize(ctx, userID, types.CacheID(body.UnlockID), body.KE3)
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"encryptedVaultKey":   acc.EncryptedVaultKey,
+		"publicKey":           acc.PublicKey,
+		"encryptedPrivateKey": acc.EncryptedPrivateKey,
+	})
+}
@@ -23,9 +23,15 @@ func MeHandler(ctx *gin.Context, usecase usecase.GroupUsecase, conf *config.Conf
 	}
 
 	ctx.HTML(http.StatusOK, templateName, gin.H{
-		"Username":     username,
-		"LogoutUrl":    localHttp.PathLogout,
-		"Group":        group,
-		"GroupListUrl": localHttp.PathGroupList,
+		"Username":           username,
+		"LogoutUrl":          localHttp.PathLogout,
+		"Group":              group,
+		"GroupListUrl":       localHttp.PathGroupList,
+		"SessionListUrl":     localHttp.PathSessionList,
+		"PasswordUrl":        localHttp.PathPasswordChange,
+		"AuthenticatorUrl":   localHttp.PathAuthenticator,
+		"EmergencyAccessUrl": localHttp.PathEmergencyAccess,
+		"ProfileUrl":         localHttp.PathProfile,
+		"AccessTokensUrl":    localHttp.PathAccessTokens,
 	})
 }
@@ -0,0 +1,13 @@
+package model
+
+import "github.com/TheAmirhosssein/cool-password-manage/internal/types"
+
+type AccessTokenCreateModel struct {
+	Name       string     `form:"name" binding:"required,max=50"`
+	Permission string     `form:"permission" binding:"required"`
+	ExpiryDays int        `form:"expiry_days"`
+	GroupIDs   []types.ID `form:"groups[]"`
+
+	// ItemIDs is a comma separated list, there is no page to pick the items from yet.
+	ItemIDs string `form:"items"`
+}
@@ -0,0 +1,303 @@
+package model
+
+import (
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/oprfutils"
+)
+
+type APIAccount struct {
+	ID        types.ID `json:"id"`
+	Username  string   `json:"username"`
+	Email     string   `json:"email"`
+	FirstName string   `json:"firstName"`
+	LastName  string   `json:"lastName"`
+}
+
+type APIAccountUpdate struct {
+	FirstName string `json:"firstName" binding:"required"`
+	LastName  string `json:"lastName" binding:"required"`
+}
+
+// APIMember is what the API tells about the accounts of a group.
+type APIMember struct {
+	ID       types.ID         `json:"id"`
+	Username string           `json:"username"`
+	Role     entity.GroupRole `json:"role"`
+}
+
+// APIGroup is a group, PendingOwnerID is the member the owner offered it to.
+type APIGroup struct {
+	ID             types.ID    `json:"id"`
+	Name           string      `json:"name"`
+	Description    string      `json:"description,omitempty"`
+	Owner          APIMember   `json:"owner"`
+	Members        []APIMember `json:"members"`
+	PendingOwnerID types.ID    `json:"pendingOwnerID,omitempty"`
+}
+
+type APIGroupList struct {
+	Groups     []APIGroup              `json:"groups"`
+	Pagination localHttp.APIPagination `json:"pagination"`
+}
+
+// APIGroupWrite creates or replaces a group, the owner is always kept as a member.
+// Roles is the role of members by id, the others keep theirs. Accounts that are not
+// members yet are invited, as viewers unless Roles says otherwise.
+type APIGroupWrite struct {
+	Name        string                        `json:"name" binding:"required,max=50"`
+	Description string                        `json:"description,omitempty"`
+	MemberIDs   []types.ID                    `json:"memberIDs,omitempty"`
+	Roles       map[types.ID]entity.GroupRole `json:"roles,omitempty"`
+}
+
+// APIGroupInvitation is an invitation to a group, Invitee is empty while the invited
+// email has no account.
+type APIGroupInvitation struct {
+	ID        types.ID         `json:"id"`
+	GroupID   types.ID         `json:"groupID"`
+	GroupName string           `json:"groupName"`
+	Inviter   string           `json:"inviter"`
+	Invitee   string           `json:"invitee,omitempty"`
+	Email     string           `json:"email,omitempty"`
+	Role      entity.GroupRole `json:"role"`
+	ExpiresAt time.Time        `json:"expiresAt"`
+}
+
+type APIGroupInvitationList struct {
+	Invitations []APIGroupInvitation `json:"invitations"`
+}
+
+type APIGroupInvite struct {
+	Email string           `json:"email" binding:"required,email"`
+	Role  entity.GroupRole `json:"role" binding:"required"`
+}
+
+type APIGroupMemberWrite struct {
+	Role entity.GroupRole `json:"role" binding:"required"`
+}
+
+// APIGroupMemberEvent is a change to the members of a group, PreviousRole is empty when
+// the member joined and Role when it left. Actor and Member are empty once their account
+// is deleted.
+type APIGroupMemberEvent struct {
+	ID           types.ID                 `json:"id"`
+	Action       entity.GroupMemberAction `json:"action"`
+	Actor        string                   `json:"actor"`
+	Member       string                   `json:"member"`
+	PreviousRole entity.GroupRole         `json:"previousRole,omitempty"`
+	Role         entity.GroupRole         `json:"role,omitempty"`
+	CreatedAt    time.Time                `json:"createdAt"`
+}
+
+type APIGroupMemberEventList struct {
+	Events []APIGroupMemberEvent `json:"events"`
+}
+
+// APIOwnershipOffer is a group its owner offered to the account.
+type APIOwnershipOffer struct {
+	GroupID   types.ID `json:"groupID"`
+	GroupName string   `json:"groupName"`
+	Owner     string   `json:"owner"`
+}
+
+type APIOwnershipOfferList struct {
+	Offers []APIOwnershipOffer `json:"offers"`
+}
+
+type APIGroupTransfer struct {
+	NewOwnerID types.ID `json:"newOwnerID" binding:"required"`
+}
+
+type APIAccessToken struct {
+	ID         types.ID                     `json:"id"`
+	Name       string                       `json:"name"`
+	Permission entity.AccessTokenPermission `json:"permission"`
+	ItemIDs    []types.ID                   `json:"itemIDs"`
+	GroupIDs   []types.ID                   `json:"groupIDs"`
+	ExpiresAt  *time.Time                   `json:"expiresAt"`
+}
+
+// APIToken is who a request is made as, Token is null for the session of a browser.
+type APIToken struct {
+	UserID   types.ID        `json:"userID"`
+	Username string          `json:"username"`
+	Token    *APIAccessToken `json:"token"`
+}
+
+func NewAPIAccount(account entity.Account) APIAccount {
+	return APIAccount{
+		ID:        account.Entity.ID,
+		Username:  account.Username,
+		Email:     account.Email,
+		FirstName: account.FirstName,
+		LastName:  account.LastName,
+	}
+}
+
+func NewAPIGroup(group entity.Group) APIGroup {
+	members := make([]APIMember, 0, len(group.Members))
+	for _, member := range group.Members {
+		members = append(members, APIMember{ID: member.Entity.ID, Username: member.Username, Role: group.Role(member.Entity.ID)})
+	}
+
+	return APIGroup{
+		ID:             group.ID,
+		Name:           group.Name,
+		Description:    group.Description.String,
+		Owner:          APIMember{ID: group.Owner.Entity.ID, Username: group.Owner.Username, Role: entity.GroupRoleOwner},
+		Members:        members,
+		PendingOwnerID: group.PendingOwnerID,
+	}
+}
+
+func NewAPIGroupInvitation(invitation entity.GroupInvitation) APIGroupInvitation {
+	return APIGroupInvitation{
+		ID:        invitation.ID,
+		GroupID:   invitation.Group.ID,
+		GroupName: invitation.Group.Name,
+		Inviter:   invitation.Inviter.Username,
+		Invitee:   invitation.Invitee.Username,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		ExpiresAt: invitation.ExpiresAt,
+	}
+}
+
+func NewAPIGroupInvitationList(invitations []entity.GroupInvitation) APIGroupInvitationList {
+	list := APIGroupInvitationList{Invitations: make([]APIGroupInvitation, 0, len(invitations))}
+	for _, invitation := range invitations {
+		list.Invitations = append(list.Invitations, NewAPIGroupInvitation(invitation))
+	}
+
+	return list
+}
+
+func NewAPIGroupMemberEventList(events []entity.GroupMemberEvent) APIGroupMemberEventList {
+	list := APIGroupMemberEventList{Events: make([]APIGroupMemberEvent, 0, len(events))}
+	for _, event := range events {
+		list.Events = append(list.Events, APIGroupMemberEvent{
+			ID:           event.ID,
+			Action:       event.Action(),
+			Actor:        event.Actor.Username,
+			Member:       event.Member.Username,
+			PreviousRole: event.PreviousRole,
+			Role:         event.Role,
+			CreatedAt:    event.CreatedAt,
+		})
+	}
+
+	return list
+}
+
+func NewAPIOwnershipOfferList(groups []entity.Group) APIOwnershipOfferList {
+	list := APIOwnershipOfferList{Offers: make([]APIOwnershipOffer, 0, len(groups))}
+	for _, group := range groups {
+		list.Offers = append(list.Offers, APIOwnershipOffer{
+			GroupID:   group.ID,
+			GroupName: group.Name,
+			Owner:     group.Owner.Username,
+		})
+	}
+
+	return list
+}
+
+func NewAPIAccessToken(token entity.AccessToken) *APIAccessToken {
+	itemIDs, groupIDs := token.ItemIDs, token.GroupIDs
+	if itemIDs == nil {
+		itemIDs = []types.ID{}
+	}
+	if groupIDs == nil {
+		groupIDs = []types.ID{}
+	}
+
+	return &APIAccessToken{
+		ID:         token.ID,
+		Name:       token.Name,
+		Permission: token.Permission,
+		ItemIDs:    itemIDs,
+		GroupIDs:   groupIDs,
+		ExpiresAt:  token.ExpiresAt,
+	}
+}
+
+// APIOpaqueConfig is what a client has to run OPAQUE with to register and log in, the
+// browser and cpm read it from the server so their records log in on both.
+type APIOpaqueConfig struct {
+	ServerID string        `json:"serverID"`
+	Suite    string        `json:"suite"`
+	KSF      oprfutils.KSF `json:"ksf"`
+}
+
+// APILoginInit, APILoginFinalize and APITwoFactor are the steps of the login of a
+// client of the API, the OPAQUE messages are the ones of the browser login.
+type APILoginInit struct {
+	Username string `json:"username" binding:"required"`
+	KE1      []byte `json:"ke1" binding:"required"`
+}
+
+type APILoginChallenge struct {
+	KE2     []byte `json:"ke2"`
+	LoginID string `json:"loginID"`
+}
+
+type APILoginFinalize struct {
+	LoginID string `json:"loginID" binding:"required"`
+	KE3     []byte `json:"ke3" binding:"required"`
+}
+
+type APITwoFactorChallenge struct {
+	TwoFactorID string `json:"twoFactorID"`
+}
+
+// APITwoFactor finishes the login with the code of the authenticator, the login issues
+// a read-write access token named TokenName that expires after ExpiryDays, between 1
+// and 365 days. The API does not issue tokens that never expire.
+type APITwoFactor struct {
+	TwoFactorID      string `json:"twoFactorID" binding:"required"`
+	VerificationCode string `json:"verificationCode" binding:"required"`
+	TokenName        string `json:"tokenName" binding:"required,max=50"`
+	ExpiryDays       int    `json:"expiryDays" binding:"min=1,max=365"`
+}
+
+// APILogin carries the access token in clear, it is never shown again. The vault key
+// is wrapped under the export key of the login. Rekey asks the client to register the
+// password again, since the server keys changed since the last registration.
+type APILogin struct {
+	Token             string         `json:"token"`
+	AccessToken       APIAccessToken `json:"accessToken"`
+	EncryptedVaultKey []byte         `json:"encryptedVaultKey"`
+	Rekey             bool           `json:"rekey"`
+}
+
+// APIRekeyInit, APIRekeyVerify and APIRekeyFinalize register the password again under
+// the current server keys, the steps are the ones of the password change.
+type APIRekeyInit struct {
+	KE1 []byte `json:"ke1" binding:"required"`
+}
+
+type APIRekeyChallenge struct {
+	KE2      []byte `json:"ke2"`
+	ChangeID string `json:"changeID"`
+}
+
+type APIRekeyVerify struct {
+	ChangeID            string `json:"changeID" binding:"required"`
+	KE3                 []byte `json:"ke3" binding:"required"`
+	RegistrationRequest []byte `json:"registrationRequest" binding:"required"`
+}
+
+type APIRekeyRegistration struct {
+	RegistrationResponse []byte `json:"registrationResponse"`
+	EncryptedVaultKey    []byte `json:"encryptedVaultKey"`
+}
+
+type APIRekeyFinalize struct {
+	ChangeID           string `json:"changeID" binding:"required"`
+	RegistrationRecord []byte `json:"registrationRecord" binding:"required"`
+	EncryptedVaultKey  []byte `json:"encryptedVaultKey" binding:"required"`
+}
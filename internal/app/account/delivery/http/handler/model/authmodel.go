@@ -8,9 +8,30 @@ type SignUpInitModel struct {
 	RegistrationRequest []byte `json:"registrationRequest" binding:"required"`
 }
 
+type SignUpVerifyModel struct {
+	RegistrationID   string `json:"registrationID" binding:"required"`
+	VerificationCode string `json:"verificationCode" binding:"required"`
+}
+
 type SignUpFinalizeModel struct {
 	RegistrationID     string `form:"registrationID" binding:"required"`
 	RegistrationRecord string `form:"registrationRecord" binding:"required"`
+	EncryptedVaultKey  string `form:"encryptedVaultKey"`
+	RecoveryProof      string `form:"recoveryProof"`
+	RecoveryVaultKey   string `form:"recoveryVaultKey"`
+}
+
+type RecoverInitModel struct {
+	Username            string `json:"username" binding:"required"`
+	RecoveryProof       []byte `json:"recoveryProof" binding:"required"`
+	VerificationCode    string `json:"verificationCode" binding:"required"`
+	RegistrationRequest []byte `json:"registrationRequest" binding:"required"`
+}
+
+type RecoverFinalizeModel struct {
+	RecoveryID         string `json:"recoveryID" binding:"required"`
+	RegistrationRecord []byte `json:"registrationRecord" binding:"required"`
+	EncryptedVaultKey  []byte `json:"encryptedVaultKey" binding:"required"`
 }
 
 type LoginModel struct {
@@ -26,3 +47,24 @@ type LoginInitModel struct {
 type TwoFactorModel struct {
 	VerificationCode string `form:"verification_code" binding:"required"`
 }
+
+type AuthenticatorConfirmModel struct {
+	EnrollmentID     string `form:"enrollment_id" binding:"required"`
+	VerificationCode string `form:"verification_code" binding:"required"`
+}
+
+type PasswordChangeInitModel struct {
+	KE1 []byte `json:"ke1" binding:"required"`
+}
+
+type PasswordChangeVerifyModel struct {
+	ChangeID            string `json:"changeID" binding:"required"`
+	KE3                 []byte `json:"ke3" binding:"required"`
+	RegistrationRequest []byte `json:"registrationRequest" binding:"required"`
+}
+
+type PasswordChangeFinalizeModel struct {
+	ChangeID           string `json:"changeID" binding:"required"`
+	RegistrationRecord []byte `json:"registrationRecord" binding:"required"`
+	EncryptedVaultKey  []byte `json:"encryptedVaultKey" binding:"required"`
+}
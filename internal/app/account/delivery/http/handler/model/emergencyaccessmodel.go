@@ -0,0 +1,24 @@
+package model
+
+type EmergencyAccessCreate struct {
+	Username string `form:"username" binding:"required"`
+	WaitDays int    `form:"wait_days" binding:"required"`
+}
+
+type EmergencyAccessConfirm struct {
+	EncryptedVaultKey []byte `json:"encryptedVaultKey" binding:"required"`
+}
+
+type KeysPublishModel struct {
+	PublicKey           []byte `json:"publicKey" binding:"required"`
+	EncryptedPrivateKey []byte `json:"encryptedPrivateKey" binding:"required"`
+}
+
+type KeysUnlockInitModel struct {
+	KE1 []byte `json:"ke1" binding:"required"`
+}
+
+type KeysUnlockFinalizeModel struct {
+	UnlockID string `json:"unlockID" binding:"required"`
+	KE3      []byte `json:"ke3" binding:"required"`
+}
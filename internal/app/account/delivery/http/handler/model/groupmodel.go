@@ -1,15 +1,27 @@
 package model
 
-import "github.com/TheAmirhosssein/cool-password-manage/internal/types"
+import (
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+)
 
 type GroupUpdate struct {
-	Name        string     `form:"name" binding:"required"`
+	Name        string     `form:"name" binding:"required,max=50"`
 	Description string     `form:"description"`
 	MembersID   []types.ID `form:"members[]" binding:"required"`
 }
 
 type GroupCreate struct {
-	Name        string     `form:"name" binding:"required"`
+	Name        string     `form:"name" binding:"required,max=50"`
 	Description string     `form:"description"`
-	MembersID   []types.ID `form:"members[]" binding:"required"`
+	MembersID   []types.ID `form:"members[]"`
+}
+
+type GroupInvite struct {
+	Email string           `form:"email" binding:"required,email"`
+	Role  entity.GroupRole `form:"role" binding:"required"`
+}
+
+type GroupOwnershipOffer struct {
+	NewOwnerID types.ID `form:"new_owner" binding:"required"`
 }
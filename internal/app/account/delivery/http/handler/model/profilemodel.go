@@ -0,0 +1,44 @@
+package model
+
+type ProfileUpdateModel struct {
+	FirstName string `form:"first_name" binding:"required"`
+	LastName  string `form:"last_name" binding:"required"`
+}
+
+type EmailChangeModel struct {
+	Email string `form:"email" binding:"required,email"`
+}
+
+type EmailChangeVerifyModel struct {
+	ChangeID         string `form:"change_id" binding:"required"`
+	VerificationCode string `form:"verification_code" binding:"required"`
+}
+
+type UsernameChangeInitModel struct {
+	Username string `json:"username" binding:"required"`
+	KE1      []byte `json:"ke1" binding:"required"`
+}
+
+type UsernameChangeVerifyModel struct {
+	ChangeID            string `json:"changeID" binding:"required"`
+	KE3                 []byte `json:"ke3" binding:"required"`
+	RegistrationRequest []byte `json:"registrationRequest" binding:"required"`
+}
+
+type UsernameChangeFinalizeModel struct {
+	ChangeID           string `json:"changeID" binding:"required"`
+	RegistrationRecord []byte `json:"registrationRecord" binding:"required"`
+	EncryptedVaultKey  []byte `json:"encryptedVaultKey" binding:"required"`
+}
+
+type AccountDeleteInitModel struct {
+	KE1 []byte `json:"ke1" binding:"required"`
+}
+
+// AccountDeleteModel carries the new owner of every owned group by group id, a zero
+// owner deletes the group.
+type AccountDeleteModel struct {
+	DeletionID string           `json:"deletionID" binding:"required"`
+	KE3        []byte           `json:"ke3" binding:"required"`
+	Successors map[string]int64 `json:"successors"`
+}
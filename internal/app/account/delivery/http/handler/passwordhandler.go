@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+func PasswordChangeHandler(ctx *gin.Context, usecase usecase.PasswordUsecase) {
+	ctx.HTML(http.StatusOK, "change_password.html", gin.H{
+		"Username":   ctx.GetString(localHttp.AuthUsernameKey),
+		"LogoutUrl":  localHttp.PathLogout,
+		"InitUrl":    localHttp.PathPasswordChangeInit,
+		"VerifyUrl":  localHttp.PathPasswordChangeVerify,
+		"FinalUrl":   localHttp.PathPasswordChangeFinal,
+		"SuccessUrl": localHttp.PathMe,
+	})
+}
+
+func PasswordChangeInitHandler(ctx *gin.Context, usecase usecase.PasswordUsecase) {
+	var body model.PasswordChangeInitModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	ke2, changeID, err := usecase.ChangeInit(ctx, userID, body.KE1)
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"ke2": ke2, "changeID": changeID})
+}
+
+func PasswordChangeVerifyHandler(ctx *gin.Context, usecase usecase.PasswordUsecase) {
+	var body model.PasswordChangeVerifyModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	record, encryptedVaultKey, err := usecase.ChangeVerify(
+		ctx, userID, types.CacheID(body.ChangeID), body.KE3, body.RegistrationRequest,
+	)
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"record": record, "encryptedVaultKey": encryptedVaultKey})
+}
+
+func PasswordChangeFinalizeHandler(ctx *gin.Context, usecase usecase.PasswordUsecase) {
+	var body model.PasswordChangeFinalizeModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	currentID := types.CacheID(sessions.Default(ctx).ID())
+
+	err := usecase.ChangeFinalize(
+		ctx, userID, currentID, types.CacheID(body.ChangeID), body.RegistrationRecord, body.EncryptedVaultKey,
+	)
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "password changed"})
+}
@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+func ProfileHandler(ctx *gin.Context, usecase usecase.AccountUsecase) {
+	templateName := "profile.html"
+	data, err := profilePageData(ctx, usecase)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, gin.H{})
+		return
+	}
+
+	ctx.HTML(http.StatusOK, templateName, data)
+}
+
+func ProfileUpdateHandler(ctx *gin.Context, usecase usecase.AccountUsecase) {
+	templateName := "profile.html"
+	data, err := profilePageData(ctx, usecase)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, gin.H{})
+		return
+	}
+
+	var form model.ProfileUpdateModel
+	if err := ctx.ShouldBind(&form); err != nil {
+		formErr := errors.NewError(err.Error(), http.StatusBadRequest)
+		localHttp.HandlerFormError(ctx, formErr, templateName, data)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	if err := usecase.UpdateProfile(ctx, userID, form.FirstName, form.LastName); err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, localHttp.PathProfile)
+}
+
+func EmailChangeHandler(ctx *gin.Context, usecase usecase.AccountUsecase) {
+	templateName := "profile.html"
+	data, err := profilePageData(ctx, usecase)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, gin.H{})
+		return
+	}
+
+	var form model.EmailChangeModel
+	if err := ctx.ShouldBind(&form); err != nil {
+		formErr := errors.NewError(err.Error(), http.StatusBadRequest)
+		localHttp.HandlerFormError(ctx, formErr, templateName, data)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	changeID, err := usecase.EmailChangeInit(ctx, userID, form.Email)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
+		return
+	}
+
+	data["ChangeID"] = changeID
+	data["NewEmail"] = form.Email
+	ctx.HTML(http.StatusOK, templateName, data)
+}
+
+func EmailChangeVerifyHandler(ctx *gin.Context, usecase usecase.AccountUsecase) {
+	templateName := "profile.html"
+	data, err := profilePageData(ctx, usecase)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, gin.H{})
+		return
+	}
+
+	var form model.EmailChangeVerifyModel
+	if err := ctx.ShouldBind(&form); err != nil {
+		formErr := errors.NewError(err.Error(), http.StatusBadRequest)
+		localHttp.HandlerFormError(ctx, formErr, templateName, data)
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	err = usecase.EmailChangeFinalize(ctx, userID, types.CacheID(form.ChangeID), form.VerificationCode)
+	if err != nil {
+		// the code can be entered again as long as the change is still there
+		data["ChangeID"] = form.ChangeID
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, data)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, localHttp.PathProfile)
+}
+
+func UsernameChangeInitHandler(ctx *gin.Context, usecase usecase.AccountUsecase) {
+	var body model.UsernameChangeInitModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	ke2, changeID, err := usecase.UsernameChangeInit(ctx, userID, body.Username, body.KE1)
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"ke2": ke2, "changeID": changeID})
+}
+
+func UsernameChangeVerifyHandler(ctx *gin.Context, usecase usecase.AccountUsecase) {
+	var body model.UsernameChangeVerifyModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	record, encryptedVaultKey, err := usecase.UsernameChangeVerify(
+		ctx, userID, types.CacheID(body.ChangeID), body.KE3, body.RegistrationRequest,
+	)
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"record": record, "encryptedVaultKey": encryptedVaultKey})
+}
+
+func UsernameChangeFinalizeHandler(ctx *gin.Context, usecase usecase.AccountUsecase) {
+	var body model.UsernameChangeFinalizeModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	username, err := usecase.UsernameChangeFinalize(
+		ctx, userID, types.CacheID(body.ChangeID), body.RegistrationRecord, body.EncryptedVaultKey,
+	)
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	session := sessions.Default(ctx)
+	session.Set(localHttp.AuthUsernameKey, username)
+	if err := session.Save(); err != nil {
+		log.ErrorLogger.Error("can not set username into session", "error", err.Error())
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(errors.NewServerError()))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "username changed", "username": username})
+}
+
+func AccountDeleteHandler(ctx *gin.Context, usecase usecase.AccountUsecase) {
+	templateName := "delete_account.html"
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	groups, err := usecase.ReadOwnedGroups(ctx, userID)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, gin.H{})
+		return
+	}
+
+	ctx.HTML(http.StatusOK, templateName, gin.H{
+		"Username":   ctx.GetString(localHttp.AuthUsernameKey),
+		"UserID":     userID,
+		"LogoutUrl":  localHttp.PathLogout,
+		"ProfileUrl": localHttp.PathProfile,
+		"InitUrl":    localHttp.PathProfileDeleteInit,
+		"FinalUrl":   localHttp.PathProfileDeleteFinal,
+		"SuccessUrl": localHttp.PathLogin,
+		"Groups":     groups,
+	})
+}
+
+func AccountDeleteInitHandler(ctx *gin.Context, usecase usecase.AccountUsecase) {
+	var body model.AccountDeleteInitModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	ke2, deletionID, err := usecase.DeleteInit(ctx, userID, body.KE1)
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"ke2": ke2, "deletionID": deletionID})
+}
+
+func AccountDeleteFinalizeHandler(ctx *gin.Context, usecase usecase.AccountUsecase) {
+	var body model.AccountDeleteModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	successors := make(map[types.ID]types.ID, len(body.Successors))
+	for groupID, successorID := range body.Successors {
+		id, err := strconv.ParseInt(groupID, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"message": "invalid group id"})
+			return
+		}
+
+		successors[types.ID(id)] = types.ID(successorID)
+	}
+
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	err := usecase.Delete(ctx, userID, types.CacheID(body.DeletionID), body.KE3, successors)
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	session := sessions.Default(ctx)
+	session.Clear()
+	session.Options(sessions.Options{Path: "/", MaxAge: -1})
+	if err := session.Save(); err != nil {
+		log.ErrorLogger.Error("can not clear the session of a deleted account", "error", err.Error())
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "account deleted"})
+}
+
+func profilePageData(ctx *gin.Context, usecase usecase.AccountUsecase) (gin.H, error) {
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	acc, err := usecase.Read(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"Username":          acc.Username,
+		"LogoutUrl":         localHttp.PathLogout,
+		"Account":           acc,
+		"UpdatePath":        localHttp.PathProfileUpdate,
+		"EmailPath":         localHttp.PathProfileEmail,
+		"EmailVerifyPath":   localHttp.PathProfileEmailVerify,
+		"UsernameInitUrl":   localHttp.PathProfileUsernameInit,
+		"UsernameVerifyUrl": localHttp.PathProfileUsernameVerify,
+		"UsernameFinalUrl":  localHttp.PathProfileUsernameFinal,
+		"DeleteUrl":         localHttp.PathProfileDelete,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+func RecoverHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
+	ctx.HTML(http.StatusOK, "recover.html", gin.H{
+		"InitUrl":    localHttp.PathRecoverInit,
+		"FinalUrl":   localHttp.PathRecoverFinal,
+		"SuccessUrl": localHttp.PathLogin,
+	})
+}
+
+func RecoverInitHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
+	var body model.RecoverInitModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	record, recoveryVaultKey, recoveryID, err := usecase.RecoverInit(
+		ctx, body.Username, body.RecoveryProof, body.VerificationCode, body.RegistrationRequest, ctx.ClientIP(),
+	)
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"record": record, "recoveryVaultKey": recoveryVaultKey, "recoveryID": recoveryID})
+}
+
+func RecoverFinalizeHandler(ctx *gin.Context, usecase usecase.AuthUsecase) {
+	var body model.RecoverFinalizeModel
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	err := usecase.RecoverFinalize(
+		ctx, types.CacheID(body.RecoveryID), body.RegistrationRecord, body.EncryptedVaultKey, ctx.ClientIP(),
+	)
+	if err != nil {
+		localHttp.HandleJSONError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "account recovered"})
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+func SessionListHandler(ctx *gin.Context, usecase usecase.SessionUsecase) {
+	templateName := "sessions.html"
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+
+	userSessions, err := usecase.Read(ctx, userID)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), templateName, gin.H{})
+		return
+	}
+
+	ctx.HTML(http.StatusOK, templateName, gin.H{
+		"Username":         ctx.GetString(localHttp.AuthUsernameKey),
+		"LogoutUrl":        localHttp.PathLogout,
+		"Sessions":         userSessions,
+		"CurrentSessionID": types.CacheID(sessions.Default(ctx).ID()),
+		"RevokePath":       localHttp.PathSessionRevoke,
+		"RevokeOthersPath": localHttp.PathSessionRevokeOthers,
+	})
+}
+
+func SessionRevokeHandler(ctx *gin.Context, usecase usecase.SessionUsecase) {
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	sessionID := types.CacheID(ctx.Param("id"))
+
+	err := usecase.Revoke(ctx, userID, sessionID)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), "general_error.html", gin.H{})
+		return
+	}
+
+	if string(sessionID) == sessions.Default(ctx).ID() {
+		ctx.Redirect(http.StatusSeeOther, localHttp.PathLogin)
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, localHttp.PathSessionList)
+}
+
+func SessionRevokeOthersHandler(ctx *gin.Context, usecase usecase.SessionUsecase) {
+	userID := types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))
+	currentID := types.CacheID(sessions.Default(ctx).ID())
+
+	err := usecase.RevokeOthers(ctx, userID, currentID)
+	if err != nil {
+		localHttp.HandleError(ctx, errors.Error2Custom(err), "general_error.html", gin.H{})
+		return
+	}
+
+	ctx.Redirect(http.StatusSeeOther, localHttp.PathSessionList)
+}
+
+// SessionTracker keeps the last seen time and ip of the current session up to date.
+// It has to run after AuthRequired.
+func SessionTracker(usecase usecase.SessionUsecase) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		sessionID := sessions.Default(ctx).ID()
+		if sessionID != "" {
+			// failing to track the session must not break the request
+			_ = usecase.Touch(ctx, types.CacheID(sessionID), ctx.ClientIP())
+		}
+
+		ctx.Next()
+	}
+}
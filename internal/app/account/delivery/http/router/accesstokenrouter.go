@@ -0,0 +1,33 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
+	"github.com/gin-gonic/gin"
+)
+
+func accessTokenRouter(
+	server *gin.Engine, atRepo repository.AccessTokenRepository, gRepo repository.GroupRepository, aRepo repository.AccountRepository,
+	giRepo repository.GroupInvitationRepository, attRepo repository.AttemptRepository,
+	txManager database.TxManager, mailer mail.Mailer, conf *config.Config,
+) {
+	accessTokenUsecase := usecase.NewAccessTokenUsecase(atRepo)
+	groupUsecase := usecase.NewGroupUsecase(gRepo, aRepo, giRepo, attRepo, txManager, mailer, conf)
+
+	server.GET(http.PathAccessTokens, func(ctx *gin.Context) {
+		handler.AccessTokenListHandler(ctx, accessTokenUsecase, groupUsecase)
+	})
+	server.POST(http.PathAccessTokenCreate, func(ctx *gin.Context) {
+		handler.AccessTokenCreateHandler(ctx, accessTokenUsecase, groupUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathAccessTokenRevoke, ":id/"), func(ctx *gin.Context) {
+		handler.AccessTokenRevokeHandler(ctx, accessTokenUsecase)
+	})
+}
@@ -1,35 +1,72 @@
 package router
 
 import (
+	"context"
+	"time"
+
 	"github.com/TheAmirhosssein/cool-password-manage/config"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/session"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/totp"
 	"github.com/gin-contrib/sessions"
-	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 )
 
-func AccountRouter(server *gin.Engine, conf *config.Config, db *pgxpool.Pool, redis *redis.Client) error {
-	store := cookie.NewStore([]byte(conf.SecretKey))
-	server.Use(sessions.Sessions("mysession", store))
+func AccountRouter(ctx context.Context, server *gin.Engine, conf *config.Config, db *pgxpool.Pool, redis *redis.Client,
+	opaqueAdaptor opaque.OpaqueService) (http.API, error) {
+	sessionDuration := time.Minute * time.Duration(conf.SessionDuration)
+	store := session.NewRedisStore(redis, sessionDuration, []byte(conf.SecretKey))
+	server.Use(sessions.Sessions(http.SessionName, store))
 
 	// Create auth
 	accountRepo := repository.NewAccountRepository(db)
 	twoFactorRepo := repository.NewTwoFactorRepository(redis)
 	registrationRepo := repository.NewRegistrationRepository(redis)
+	attemptRepo := repository.NewAttemptRepository(redis)
+	sessionRepo := repository.NewSessionRepository(redis)
+	passwordChangeRepo := repository.NewPasswordChangeRepository(redis)
+	enrollmentRepo := repository.NewEnrollmentRepository(redis)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	accountRecoveryRepo := repository.NewAccountRecoveryRepository(redis)
 	groupRepo := repository.NewGroupRepository(db)
+	vaultUnlockRepo := repository.NewVaultUnlockRepository(redis)
+	emergencyAccessRepo := repository.NewEmergencyAccessRepository(db)
+	emailChangeRepo := repository.NewEmailChangeRepository(redis)
+	usernameChangeRepo := repository.NewUsernameChangeRepository(redis)
+	accessTokenRepo := repository.NewAccessTokenRepository(db)
+	groupInvitationRepo := repository.NewGroupInvitationRepository(db)
+	txManager := database.NewTxManager(db)
 	authenticator := totp.NewAuthenticatorAdaptor(conf.Name)
-	opaqueAdaptor, err := opaque.New(conf)
+	mailer, err := mail.New(conf)
 	if err != nil {
-		return err
+		return http.API{}, err
 	}
 
 	// Register routers
-	authRouter(server, accountRepo, twoFactorRepo, registrationRepo, authenticator, opaqueAdaptor, conf)
-	meRouter(server, groupRepo, accountRepo, conf)
-	groupRouter(server, groupRepo, accountRepo, conf)
-	return nil
+	authRouter(
+		server, accountRepo, twoFactorRepo, registrationRepo, attemptRepo, enrollmentRepo, recoveryCodeRepo,
+		accountRecoveryRepo, sessionRepo, vaultUnlockRepo, groupInvitationRepo, authenticator, opaqueAdaptor, mailer, store, conf,
+	)
+	api := apiRouter(
+		server, accountRepo, groupRepo, emailChangeRepo, usernameChangeRepo, passwordChangeRepo, vaultUnlockRepo, sessionRepo,
+		accessTokenRepo, twoFactorRepo, registrationRepo, attemptRepo, enrollmentRepo, recoveryCodeRepo, accountRecoveryRepo,
+		groupInvitationRepo, txManager, authenticator, opaqueAdaptor, mailer, conf,
+	)
+	sessionRouter(server, sessionRepo, conf)
+	passwordRouter(server, accountRepo, passwordChangeRepo, sessionRepo, attemptRepo, opaqueAdaptor, mailer, conf)
+	profileRouter(
+		server, accountRepo, groupRepo, emailChangeRepo, usernameChangeRepo, vaultUnlockRepo, sessionRepo, attemptRepo,
+		opaqueAdaptor, mailer, conf,
+	)
+	meRouter(server, groupRepo, accountRepo, groupInvitationRepo, attemptRepo, txManager, mailer, conf)
+	groupRouter(server, groupRepo, accountRepo, groupInvitationRepo, attemptRepo, txManager, mailer, conf)
+	accessTokenRouter(server, accessTokenRepo, groupRepo, accountRepo, groupInvitationRepo, attemptRepo, txManager, mailer, conf)
+	emergencyAccessRouter(ctx, server, emergencyAccessRepo, accountRepo, vaultUnlockRepo, opaqueAdaptor, mailer, conf)
+	return api, nil
 }
@@ -0,0 +1,562 @@
+package router
+
+import (
+	nethttp "net/http"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/totp"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// apiRouter has to be registered before sessionRouter, the AuthRequired it installs
+// would otherwise redirect the API requests to the login page. The other apps add
+// their routes to the returned API.
+func apiRouter(
+	server *gin.Engine, aRepo repository.AccountRepository, gRepo repository.GroupRepository,
+	ecRepo repository.EmailChangeRepository, ucRepo repository.UsernameChangeRepository, pcRepo repository.PasswordChangeRepository,
+	vuRepo repository.VaultUnlockRepository, sRepo repository.SessionRepository, atRepo repository.AccessTokenRepository,
+	tfRepo repository.TwoFactorRepository, rRepo repository.RegistrationRepository, attRepo repository.AttemptRepository,
+	eRepo repository.EnrollmentRepository, rcRepo repository.RecoveryCodeRepository, arRepo repository.AccountRecoveryRepository,
+	giRepo repository.GroupInvitationRepository, txManager database.TxManager, totp totp.AuthenticatorAdaptor, opaqueAdaptor opaque.OpaqueService, mailer mail.Mailer, conf *config.Config,
+) http.API {
+	accountUsecase := usecase.NewAccountUsecase(aRepo, gRepo, ecRepo, ucRepo, vuRepo, sRepo, attRepo, opaqueAdaptor, mailer, conf)
+	groupUsecase := usecase.NewGroupUsecase(gRepo, aRepo, giRepo, attRepo, txManager, mailer, conf)
+	accessTokenUsecase := usecase.NewAccessTokenUsecase(atRepo)
+	passwordUsecase := usecase.NewPasswordUsecase(aRepo, pcRepo, sRepo, attRepo, opaqueAdaptor, mailer, conf)
+	authUsecase := usecase.NewAuthUsecase(
+		aRepo, tfRepo, rRepo, attRepo, eRepo, rcRepo, arRepo, sRepo, vuRepo, giRepo, totp, opaqueAdaptor, mailer, conf,
+	)
+
+	api := http.API{
+		Group:  server.Group(http.PathAPI, handler.APIAuthRequired(accessTokenUsecase)),
+		Public: server.Group(http.PathAPI),
+		Doc:    http.NewAPIDocument(),
+	}
+
+	server.GET(http.PathAPIDoc, api.Doc.Handler())
+	api.Register(AuthAPIRoutes(authUsecase, accessTokenUsecase, conf)...)
+	api.Register(PasswordAPIRoutes(passwordUsecase)...)
+	api.Register(AccountAPIRoutes(accountUsecase, groupUsecase, conf)...)
+
+	return api
+}
+
+// AuthAPIRoutes log a client of the API in, the login ends with an access token instead
+// of a session.
+func AuthAPIRoutes(authUsecase usecase.AuthUsecase, accessTokenUsecase usecase.AccessTokenUsecase, conf *config.Config) []openapi.Route {
+	apiError := http.APIErrorResponse{}
+	lockErrors := map[int]any{
+		nethttp.StatusBadRequest:      apiError,
+		nethttp.StatusLocked:          apiError,
+		nethttp.StatusTooManyRequests: apiError,
+	}
+
+	return []openapi.Route{
+		{
+			Method:    nethttp.MethodGet,
+			Path:      "auth/opaque/",
+			Summary:   "The OPAQUE suite and key stretching function the clients register and log in with",
+			Tag:       "auth",
+			Public:    true,
+			Responses: map[int]any{nethttp.StatusOK: model.APIOpaqueConfig{}},
+			Handler: func(ctx *gin.Context) {
+				handler.APIOpaqueConfigHandler(ctx, conf)
+			},
+		},
+		{
+			Method:    nethttp.MethodPost,
+			Path:      "auth/login/init/",
+			Summary:   "Start the OPAQUE login with the KE1 message",
+			Tag:       "auth",
+			Public:    true,
+			Request:   model.APILoginInit{},
+			Responses: withResponses(lockErrors, map[int]any{nethttp.StatusOK: model.APILoginChallenge{}}),
+			Handler: func(ctx *gin.Context) {
+				handler.APILoginInitHandler(ctx, authUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPost,
+			Path:    "auth/login/",
+			Summary: "Finish the OPAQUE login with the KE3 message and start the two factor",
+			Tag:     "auth",
+			Public:  true,
+			Request: model.APILoginFinalize{},
+			Responses: withResponses(lockErrors, map[int]any{
+				nethttp.StatusOK:                  model.APITwoFactorChallenge{},
+				nethttp.StatusNotFound:            apiError,
+				nethttp.StatusUnprocessableEntity: apiError,
+			}),
+			Handler: func(ctx *gin.Context) {
+				handler.APILoginFinalizeHandler(ctx, authUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPost,
+			Path:    "auth/two-factor/",
+			Summary: "Check the code of the authenticator and issue an access token",
+			Tag:     "auth",
+			Public:  true,
+			Request: model.APITwoFactor{},
+			Responses: withResponses(lockErrors, map[int]any{
+				nethttp.StatusOK:                  model.APILogin{},
+				nethttp.StatusNotFound:            apiError,
+				nethttp.StatusConflict:            apiError,
+				nethttp.StatusUnprocessableEntity: apiError,
+			}),
+			Handler: func(ctx *gin.Context) {
+				handler.APITwoFactorHandler(ctx, authUsecase, accessTokenUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodDelete,
+			Path:    "token/",
+			Summary: "Revoke the access token the request is made with",
+			Tag:     "auth",
+			Responses: map[int]any{
+				nethttp.StatusNoContent: nil,
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APITokenRevokeHandler(ctx, accessTokenUsecase)
+			},
+		},
+	}
+}
+
+// PasswordAPIRoutes let a client register its password again once the login asked for
+// it, the steps are the ones of the password change.
+func PasswordAPIRoutes(passwordUsecase usecase.PasswordUsecase) []openapi.Route {
+	apiError := http.APIErrorResponse{}
+
+	return []openapi.Route{
+		{
+			Method:  nethttp.MethodPost,
+			Path:    "password/rekey/init/",
+			Summary: "Start the OPAQUE login with the current password",
+			Tag:     "password",
+			Request: model.APIRekeyInit{},
+			Responses: map[int]any{
+				nethttp.StatusOK:         model.APIRekeyChallenge{},
+				nethttp.StatusBadRequest: apiError,
+				nethttp.StatusForbidden:  apiError,
+				nethttp.StatusLocked:     apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIRekeyInitHandler(ctx, passwordUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPost,
+			Path:    "password/rekey/verify/",
+			Summary: "Finish the login with the KE3 message and answer the registration request",
+			Tag:     "password",
+			Request: model.APIRekeyVerify{},
+			Responses: map[int]any{
+				nethttp.StatusOK:                  model.APIRekeyRegistration{},
+				nethttp.StatusBadRequest:          apiError,
+				nethttp.StatusForbidden:           apiError,
+				nethttp.StatusNotFound:            apiError,
+				nethttp.StatusUnprocessableEntity: apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIRekeyVerifyHandler(ctx, passwordUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPost,
+			Path:    "password/rekey/",
+			Summary: "Store the record registered under the current server keys",
+			Tag:     "password",
+			Request: model.APIRekeyFinalize{},
+			Responses: map[int]any{
+				nethttp.StatusNoContent:  nil,
+				nethttp.StatusBadRequest: apiError,
+				nethttp.StatusForbidden:  apiError,
+				nethttp.StatusNotFound:   apiError,
+				nethttp.StatusConflict:   apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIRekeyFinalizeHandler(ctx, passwordUsecase)
+			},
+		},
+	}
+}
+
+// withResponses returns the responses of both maps, the ones of the second win.
+func withResponses(common, responses map[int]any) map[int]any {
+	merged := make(map[int]any, len(common)+len(responses))
+	for status, body := range common {
+		merged[status] = body
+	}
+	for status, body := range responses {
+		merged[status] = body
+	}
+
+	return merged
+}
+
+// AccountAPIRoutes are the routes of the account app in the JSON API.
+func AccountAPIRoutes(accountUsecase usecase.AccountUsecase, groupUsecase usecase.GroupUsecase, conf *config.Config) []openapi.Route {
+	apiError := http.APIErrorResponse{}
+	groupID := openapi.PathParam("id", "id of the group")
+	invitationID := openapi.PathParam("id", "id of the invitation")
+	memberID := openapi.PathParam("memberID", "id of the account of the member")
+
+	return []openapi.Route{
+		{
+			Method:    nethttp.MethodGet,
+			Path:      "token/",
+			Summary:   "Who the request is made as and what its access token allows",
+			Tag:       "account",
+			Responses: map[int]any{nethttp.StatusOK: model.APIToken{}},
+			Handler:   handler.APITokenHandler,
+		},
+		{
+			Method:    nethttp.MethodGet,
+			Path:      "account/",
+			Summary:   "Read the account",
+			Tag:       "account",
+			Responses: map[int]any{nethttp.StatusOK: model.APIAccount{}},
+			Handler: func(ctx *gin.Context) {
+				handler.APIAccountReadHandler(ctx, accountUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPut,
+			Path:    "account/",
+			Summary: "Update the name of the account",
+			Tag:     "account",
+			Request: model.APIAccountUpdate{},
+			Responses: map[int]any{
+				nethttp.StatusOK:         model.APIAccount{},
+				nethttp.StatusBadRequest: apiError,
+				nethttp.StatusForbidden:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIAccountUpdateHandler(ctx, accountUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodGet,
+			Path:    "accounts/search/",
+			Summary: "Find an account by its username",
+			Tag:     "account",
+			Params:  []openapi.Param{openapi.QueryParam("username", "string", "exact username of the account")},
+			Responses: map[int]any{
+				nethttp.StatusOK:       model.APIMember{},
+				nethttp.StatusNotFound: apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIAccountSearchHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodGet,
+			Path:    "groups/",
+			Summary: "List the groups the account is a member of",
+			Tag:     "groups",
+			Params: []openapi.Param{
+				openapi.QueryParam(http.PageKeyParam, "integer", "page number"),
+				openapi.QueryParam(http.PageSizeKeyParam, "integer", "groups per page"),
+				openapi.QueryParam(http.SearchKeyParam, "string", "search in the name and description"),
+			},
+			Responses: map[int]any{
+				nethttp.StatusOK:        model.APIGroupList{},
+				nethttp.StatusForbidden: apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupListHandler(ctx, groupUsecase, conf)
+			},
+		},
+		{
+			Method:  nethttp.MethodPost,
+			Path:    "groups/",
+			Summary: "Create a group owned by the account",
+			Tag:     "groups",
+			Request: model.APIGroupWrite{},
+			Responses: map[int]any{
+				nethttp.StatusCreated:    model.APIGroup{},
+				nethttp.StatusBadRequest: apiError,
+				nethttp.StatusForbidden:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupCreateHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodGet,
+			Path:    "groups/:id/",
+			Summary: "Read a group",
+			Tag:     "groups",
+			Params:  []openapi.Param{groupID},
+			Responses: map[int]any{
+				nethttp.StatusOK:        model.APIGroup{},
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupReadHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPut,
+			Path:    "groups/:id/",
+			Summary: "Replace the name, description and members of a group",
+			Tag:     "groups",
+			Params:  []openapi.Param{groupID},
+			Request: model.APIGroupWrite{},
+			Responses: map[int]any{
+				nethttp.StatusOK:         model.APIGroup{},
+				nethttp.StatusBadRequest: apiError,
+				nethttp.StatusForbidden:  apiError,
+				nethttp.StatusNotFound:   apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupUpdateHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodDelete,
+			Path:    "groups/:id/",
+			Summary: "Delete a group",
+			Tag:     "groups",
+			Params:  []openapi.Param{groupID},
+			Responses: map[int]any{
+				nethttp.StatusNoContent: nil,
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupDeleteHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPut,
+			Path:    "groups/:id/members/:memberID/",
+			Summary: "Change the role of a member of a group",
+			Tag:     "groups",
+			Params:  []openapi.Param{groupID, memberID},
+			Request: model.APIGroupMemberWrite{},
+			Responses: map[int]any{
+				nethttp.StatusOK:         model.APIGroup{},
+				nethttp.StatusBadRequest: apiError,
+				nethttp.StatusForbidden:  apiError,
+				nethttp.StatusNotFound:   apiError,
+				nethttp.StatusConflict:   apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupMemberUpdateHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodDelete,
+			Path:    "groups/:id/members/:memberID/",
+			Summary: "Remove a member from a group, the items it shared with the group stop being shared",
+			Tag:     "groups",
+			Params:  []openapi.Param{groupID, memberID},
+			Responses: map[int]any{
+				nethttp.StatusNoContent: nil,
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+				nethttp.StatusConflict:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupMemberDeleteHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodGet,
+			Path:    "groups/:id/events/",
+			Summary: "List the latest changes to the members of a group",
+			Tag:     "groups",
+			Params:  []openapi.Param{groupID},
+			Responses: map[int]any{
+				nethttp.StatusOK:        model.APIGroupMemberEventList{},
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupEventsHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodGet,
+			Path:    "groups/:id/invitations/",
+			Summary: "List the pending invitations to a group",
+			Tag:     "groups",
+			Params:  []openapi.Param{groupID},
+			Responses: map[int]any{
+				nethttp.StatusOK:        model.APIGroupInvitationList{},
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupInvitationsHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPost,
+			Path:    "groups/:id/invitations/",
+			Summary: "Invite an email to a group",
+			Tag:     "groups",
+			Params:  []openapi.Param{groupID},
+			Request: model.APIGroupInvite{},
+			Responses: map[int]any{
+				nethttp.StatusCreated:    model.APIGroupInvitation{},
+				nethttp.StatusBadRequest: apiError,
+				nethttp.StatusForbidden:  apiError,
+				nethttp.StatusNotFound:   apiError,
+				nethttp.StatusConflict:   apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupInviteHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPost,
+			Path:    "groups/:id/leave/",
+			Summary: "Leave a group, the items the account shared with it stop being shared",
+			Tag:     "groups",
+			Params:  []openapi.Param{groupID},
+			Responses: map[int]any{
+				nethttp.StatusNoContent: nil,
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupLeaveHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPost,
+			Path:    "groups/:id/transfer/",
+			Summary: "Offer a group to another member, it changes hands once the member accepts",
+			Tag:     "groups",
+			Params:  []openapi.Param{groupID},
+			Request: model.APIGroupTransfer{},
+			Responses: map[int]any{
+				nethttp.StatusOK:         model.APIGroup{},
+				nethttp.StatusBadRequest: apiError,
+				nethttp.StatusForbidden:  apiError,
+				nethttp.StatusNotFound:   apiError,
+				nethttp.StatusConflict:   apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupTransferHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodDelete,
+			Path:    "groups/:id/transfer/",
+			Summary: "Take back or decline the ownership offer of a group",
+			Tag:     "groups",
+			Params:  []openapi.Param{groupID},
+			Responses: map[int]any{
+				nethttp.StatusNoContent: nil,
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupTransferDeleteHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPost,
+			Path:    "groups/:id/transfer/accept/",
+			Summary: "Become the owner of a group offered to the account",
+			Tag:     "groups",
+			Params:  []openapi.Param{groupID},
+			Responses: map[int]any{
+				nethttp.StatusNoContent: nil,
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+				nethttp.StatusConflict:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIGroupTransferAcceptHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodGet,
+			Path:    "ownership-offers/",
+			Summary: "List the groups offered to the account",
+			Tag:     "groups",
+			Responses: map[int]any{
+				nethttp.StatusOK:        model.APIOwnershipOfferList{},
+				nethttp.StatusForbidden: apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIOwnershipOfferListHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodGet,
+			Path:    "invitations/",
+			Summary: "List the pending group invitations the account received",
+			Tag:     "groups",
+			Responses: map[int]any{
+				nethttp.StatusOK:        model.APIGroupInvitationList{},
+				nethttp.StatusForbidden: apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIInvitationListHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPost,
+			Path:    "invitations/:id/accept/",
+			Summary: "Join the group of an invitation",
+			Tag:     "groups",
+			Params:  []openapi.Param{invitationID},
+			Responses: map[int]any{
+				nethttp.StatusNoContent: nil,
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIInvitationAcceptHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPost,
+			Path:    "invitations/:id/decline/",
+			Summary: "Decline an invitation to a group",
+			Tag:     "groups",
+			Params:  []openapi.Param{invitationID},
+			Responses: map[int]any{
+				nethttp.StatusNoContent: nil,
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIInvitationDeclineHandler(ctx, groupUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodDelete,
+			Path:    "invitations/:id/",
+			Summary: "Take back an invitation to a group the account manages",
+			Tag:     "groups",
+			Params:  []openapi.Param{invitationID},
+			Responses: map[int]any{
+				nethttp.StatusNoContent: nil,
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.APIInvitationCancelHandler(ctx, groupUsecase)
+			},
+		},
+	}
+}
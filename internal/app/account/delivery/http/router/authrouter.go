@@ -6,16 +6,22 @@ import (
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/session"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/totp"
 	"github.com/gin-gonic/gin"
 )
 
 func authRouter(
 	server *gin.Engine, aRepo repository.AccountRepository, tfRepo repository.TwoFactorRepository, rRepo repository.RegistrationRepository,
-	totp totp.AuthenticatorAdaptor, opaqueAdaptor opaque.OpaqueService, conf *config.Config,
+	atRepo repository.AttemptRepository, eRepo repository.EnrollmentRepository, rcRepo repository.RecoveryCodeRepository,
+	arRepo repository.AccountRecoveryRepository, sRepo repository.SessionRepository, vuRepo repository.VaultUnlockRepository,
+	giRepo repository.GroupInvitationRepository, totp totp.AuthenticatorAdaptor, opaqueAdaptor opaque.OpaqueService,
+	mailer mail.Mailer, store *session.RedisStore, conf *config.Config,
 ) {
-	authUsecase := usecase.NewAuthUsecase(aRepo, tfRepo, rRepo, totp, opaqueAdaptor, conf)
+	authUsecase := usecase.NewAuthUsecase(aRepo, tfRepo, rRepo, atRepo, eRepo, rcRepo, arRepo, sRepo, vuRepo, giRepo, totp, opaqueAdaptor, mailer, conf)
+	sessionUsecase := usecase.NewSessionUsecase(sRepo, conf)
 
 	server.GET(http.PathSignUp, http.GuestOnly(), func(ctx *gin.Context) {
 		handler.SignUpHandler(ctx, authUsecase)
@@ -25,6 +31,10 @@ func authRouter(
 		handler.SignUpInitialHandler(ctx, authUsecase)
 	})
 
+	server.POST(http.PathSignUpVerify, http.GuestOnly(), func(ctx *gin.Context) {
+		handler.SignUpVerifyHandler(ctx, authUsecase)
+	})
+
 	server.POST(http.PathSignUpFinal, http.GuestOnly(), func(ctx *gin.Context) {
 		handler.SignUpFinalizeHandler(ctx, authUsecase)
 	})
@@ -38,12 +48,38 @@ func authRouter(
 	})
 
 	server.GET(http.PathTwoFactor, http.GuestOnly(), func(ctx *gin.Context) {
-		handler.TwoFactorHandler(ctx, authUsecase)
+		handler.TwoFactorHandler(ctx, authUsecase, sessionUsecase, store)
 	})
 
 	server.POST(http.PathTwoFactor, http.GuestOnly(), func(ctx *gin.Context) {
-		handler.TwoFactorHandler(ctx, authUsecase)
+		handler.TwoFactorHandler(ctx, authUsecase, sessionUsecase, store)
+	})
+
+	server.GET(http.PathRecover, http.GuestOnly(), func(ctx *gin.Context) {
+		handler.RecoverHandler(ctx, authUsecase)
+	})
+
+	server.POST(http.PathRecoverInit, http.GuestOnly(), func(ctx *gin.Context) {
+		handler.RecoverInitHandler(ctx, authUsecase)
+	})
+
+	server.POST(http.PathRecoverFinal, http.GuestOnly(), func(ctx *gin.Context) {
+		handler.RecoverFinalizeHandler(ctx, authUsecase)
 	})
 
-	server.GET(http.PathLogout, handler.LogoutHandler)
+	server.GET(http.PathLogout, func(ctx *gin.Context) {
+		handler.LogoutHandler(ctx, sessionUsecase)
+	})
+
+	server.GET(http.PathAuthenticator, http.AuthRequired(), func(ctx *gin.Context) {
+		handler.AuthenticatorEnrollHandler(ctx, authUsecase)
+	})
+
+	server.POST(http.PathAuthenticator, http.AuthRequired(), func(ctx *gin.Context) {
+		handler.AuthenticatorEnrollHandler(ctx, authUsecase)
+	})
+
+	server.POST(http.PathAuthenticatorConfirm, http.AuthRequired(), func(ctx *gin.Context) {
+		handler.AuthenticatorConfirmHandler(ctx, authUsecase)
+	})
 }
@@ -0,0 +1,68 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/scheduler"
+	"github.com/gin-gonic/gin"
+)
+
+// emergencyAccessCheckInterval is how often requests past their wait period are approved.
+const emergencyAccessCheckInterval = time.Minute
+
+func emergencyAccessRouter(
+	ctx context.Context, server *gin.Engine, eaRepo repository.EmergencyAccessRepository, aRepo repository.AccountRepository,
+	vuRepo repository.VaultUnlockRepository, opaqueAdaptor opaque.OpaqueService, mailer mail.Mailer, conf *config.Config,
+) {
+	emergencyAccessUsecase := usecase.NewEmergencyAccessUsecase(eaRepo, aRepo, mailer)
+	keyUsecase := usecase.NewKeyUsecase(aRepo, vuRepo, opaqueAdaptor, conf)
+
+	go scheduler.Every(ctx, emergencyAccessCheckInterval, "approve expired emergency access", emergencyAccessUsecase.ApproveExpired)
+
+	server.POST(http.PathKeys, func(ctx *gin.Context) {
+		handler.KeysPublishHandler(ctx, keyUsecase)
+	})
+	server.POST(http.PathKeysUnlockInit, func(ctx *gin.Context) {
+		handler.KeysUnlockInitHandler(ctx, keyUsecase)
+	})
+	server.POST(http.PathKeysUnlockFinal, func(ctx *gin.Context) {
+		handler.KeysUnlockFinalizeHandler(ctx, keyUsecase)
+	})
+
+	server.GET(http.PathEmergencyAccess, func(ctx *gin.Context) {
+		handler.EmergencyAccessListHandler(ctx, emergencyAccessUsecase, keyUsecase)
+	})
+	server.POST(http.PathEmergencyAccessCreate, func(ctx *gin.Context) {
+		handler.EmergencyAccessCreateHandler(ctx, emergencyAccessUsecase, keyUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathEmergencyAccessAccept, ":id/"), func(ctx *gin.Context) {
+		handler.EmergencyAccessAcceptHandler(ctx, emergencyAccessUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathEmergencyAccessConfirm, ":id/"), func(ctx *gin.Context) {
+		handler.EmergencyAccessConfirmHandler(ctx, emergencyAccessUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathEmergencyAccessRequest, ":id/"), func(ctx *gin.Context) {
+		handler.EmergencyAccessRequestHandler(ctx, emergencyAccessUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathEmergencyAccessApprove, ":id/"), func(ctx *gin.Context) {
+		handler.EmergencyAccessApproveHandler(ctx, emergencyAccessUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathEmergencyAccessReject, ":id/"), func(ctx *gin.Context) {
+		handler.EmergencyAccessRejectHandler(ctx, emergencyAccessUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathEmergencyAccessDelete, ":id/"), func(ctx *gin.Context) {
+		handler.EmergencyAccessDeleteHandler(ctx, emergencyAccessUsecase)
+	})
+	server.GET(fmt.Sprint(http.PathEmergencyAccessVaultKey, ":id/"), func(ctx *gin.Context) {
+		handler.EmergencyAccessVaultKeyHandler(ctx, emergencyAccessUsecase)
+	})
+}
@@ -8,12 +8,16 @@ import (
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
 	"github.com/gin-gonic/gin"
 )
 
-func groupRouter(server *gin.Engine, gRepo repository.GroupRepository, aRepo repository.AccountRepository, conf *config.Config) {
+func groupRouter(server *gin.Engine, gRepo repository.GroupRepository, aRepo repository.AccountRepository,
+	giRepo repository.GroupInvitationRepository, attRepo repository.AttemptRepository,
+	txManager database.TxManager, mailer mail.Mailer, conf *config.Config) {
 	server.Use(http.AuthRequired())
-	groupeUsecase := usecase.NewGroupUsecase(gRepo, aRepo)
+	groupeUsecase := usecase.NewGroupUsecase(gRepo, aRepo, giRepo, attRepo, txManager, mailer, conf)
 	server.GET(http.PathGroupList, func(ctx *gin.Context) {
 		handler.GroupListHandler(ctx, groupeUsecase, conf)
 	})
@@ -35,4 +39,34 @@ func groupRouter(server *gin.Engine, gRepo repository.GroupRepository, aRepo rep
 	server.GET(fmt.Sprint(http.PathGroupSearchMember), func(ctx *gin.Context) {
 		handler.GroupSearchMember(ctx, groupeUsecase)
 	})
+	server.POST(fmt.Sprint(http.PathGroupInvite, ":id/"), func(ctx *gin.Context) {
+		handler.GroupInviteHandler(ctx, groupeUsecase)
+	})
+	server.GET(http.PathGroupInvitations, func(ctx *gin.Context) {
+		handler.GroupInvitationListHandler(ctx, groupeUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathGroupInvitationAccept, ":id/"), func(ctx *gin.Context) {
+		handler.GroupInvitationAcceptHandler(ctx, groupeUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathGroupInvitationDecline, ":id/"), func(ctx *gin.Context) {
+		handler.GroupInvitationDeclineHandler(ctx, groupeUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathGroupInvitationCancel, ":id/"), func(ctx *gin.Context) {
+		handler.GroupInvitationCancelHandler(ctx, groupeUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathGroupLeave, ":id/"), func(ctx *gin.Context) {
+		handler.GroupLeaveHandler(ctx, groupeUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathGroupOwnershipOffer, ":id/"), func(ctx *gin.Context) {
+		handler.GroupOwnershipOfferHandler(ctx, groupeUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathGroupOwnershipCancel, ":id/"), func(ctx *gin.Context) {
+		handler.GroupOwnershipCancelHandler(ctx, groupeUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathGroupOwnershipAccept, ":id/"), func(ctx *gin.Context) {
+		handler.GroupOwnershipAcceptHandler(ctx, groupeUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathGroupOwnershipDecline, ":id/"), func(ctx *gin.Context) {
+		handler.GroupOwnershipDeclineHandler(ctx, groupeUsecase)
+	})
 }
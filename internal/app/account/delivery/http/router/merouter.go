@@ -6,12 +6,16 @@ import (
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
 	"github.com/gin-gonic/gin"
 )
 
-func meRouter(server *gin.Engine, gRepo repository.GroupRepository, aRepo repository.AccountRepository, conf *config.Config) {
+func meRouter(server *gin.Engine, gRepo repository.GroupRepository, aRepo repository.AccountRepository,
+	giRepo repository.GroupInvitationRepository, attRepo repository.AttemptRepository,
+	txManager database.TxManager, mailer mail.Mailer, conf *config.Config) {
 	server.Use(http.AuthRequired())
-	groupeUsecase := usecase.NewGroupUsecase(gRepo, aRepo)
+	groupeUsecase := usecase.NewGroupUsecase(gRepo, aRepo, giRepo, attRepo, txManager, mailer, conf)
 
 	server.GET(http.PathMe, func(ctx *gin.Context) {
 		handler.MeHandler(ctx, groupeUsecase, conf)
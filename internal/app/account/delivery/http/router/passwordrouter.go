@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/gin-gonic/gin"
+)
+
+func passwordRouter(
+	server *gin.Engine, aRepo repository.AccountRepository, pcRepo repository.PasswordChangeRepository,
+	sRepo repository.SessionRepository, atRepo repository.AttemptRepository, opaqueAdaptor opaque.OpaqueService,
+	mailer mail.Mailer, conf *config.Config,
+) {
+	passwordUsecase := usecase.NewPasswordUsecase(aRepo, pcRepo, sRepo, atRepo, opaqueAdaptor, mailer, conf)
+
+	server.GET(http.PathPasswordChange, func(ctx *gin.Context) {
+		handler.PasswordChangeHandler(ctx, passwordUsecase)
+	})
+	server.POST(http.PathPasswordChangeInit, func(ctx *gin.Context) {
+		handler.PasswordChangeInitHandler(ctx, passwordUsecase)
+	})
+	server.POST(http.PathPasswordChangeVerify, func(ctx *gin.Context) {
+		handler.PasswordChangeVerifyHandler(ctx, passwordUsecase)
+	})
+	server.POST(http.PathPasswordChangeFinal, func(ctx *gin.Context) {
+		handler.PasswordChangeFinalizeHandler(ctx, passwordUsecase)
+	})
+}
@@ -0,0 +1,52 @@
+package router
+
+import (
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/gin-gonic/gin"
+)
+
+func profileRouter(
+	server *gin.Engine, aRepo repository.AccountRepository, gRepo repository.GroupRepository,
+	ecRepo repository.EmailChangeRepository, ucRepo repository.UsernameChangeRepository,
+	vuRepo repository.VaultUnlockRepository, sRepo repository.SessionRepository, atRepo repository.AttemptRepository,
+	opaqueAdaptor opaque.OpaqueService, mailer mail.Mailer, conf *config.Config,
+) {
+	accountUsecase := usecase.NewAccountUsecase(aRepo, gRepo, ecRepo, ucRepo, vuRepo, sRepo, atRepo, opaqueAdaptor, mailer, conf)
+
+	server.GET(http.PathProfile, func(ctx *gin.Context) {
+		handler.ProfileHandler(ctx, accountUsecase)
+	})
+	server.POST(http.PathProfileUpdate, func(ctx *gin.Context) {
+		handler.ProfileUpdateHandler(ctx, accountUsecase)
+	})
+	server.POST(http.PathProfileEmail, func(ctx *gin.Context) {
+		handler.EmailChangeHandler(ctx, accountUsecase)
+	})
+	server.POST(http.PathProfileEmailVerify, func(ctx *gin.Context) {
+		handler.EmailChangeVerifyHandler(ctx, accountUsecase)
+	})
+	server.POST(http.PathProfileUsernameInit, func(ctx *gin.Context) {
+		handler.UsernameChangeInitHandler(ctx, accountUsecase)
+	})
+	server.POST(http.PathProfileUsernameVerify, func(ctx *gin.Context) {
+		handler.UsernameChangeVerifyHandler(ctx, accountUsecase)
+	})
+	server.POST(http.PathProfileUsernameFinal, func(ctx *gin.Context) {
+		handler.UsernameChangeFinalizeHandler(ctx, accountUsecase)
+	})
+	server.GET(http.PathProfileDelete, func(ctx *gin.Context) {
+		handler.AccountDeleteHandler(ctx, accountUsecase)
+	})
+	server.POST(http.PathProfileDeleteInit, func(ctx *gin.Context) {
+		handler.AccountDeleteInitHandler(ctx, accountUsecase)
+	})
+	server.POST(http.PathProfileDeleteFinal, func(ctx *gin.Context) {
+		handler.AccountDeleteFinalizeHandler(ctx, accountUsecase)
+	})
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/gin-gonic/gin"
+)
+
+func sessionRouter(server *gin.Engine, sRepo repository.SessionRepository, conf *config.Config) {
+	sessionUsecase := usecase.NewSessionUsecase(sRepo, conf)
+	server.Use(http.AuthRequired(), handler.SessionTracker(sessionUsecase))
+
+	server.GET(http.PathSessionList, func(ctx *gin.Context) {
+		handler.SessionListHandler(ctx, sessionUsecase)
+	})
+	server.POST(fmt.Sprint(http.PathSessionRevoke, ":id/"), func(ctx *gin.Context) {
+		handler.SessionRevokeHandler(ctx, sessionUsecase)
+	})
+	server.POST(http.PathSessionRevokeOthers, func(ctx *gin.Context) {
+		handler.SessionRevokeOthersHandler(ctx, sessionUsecase)
+	})
+}
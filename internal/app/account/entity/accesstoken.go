@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"slices"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+)
+
+type AccessTokenPermission string
+
+const (
+	AccessTokenRead      AccessTokenPermission = "read"
+	AccessTokenReadWrite AccessTokenPermission = "read-write"
+)
+
+func (p AccessTokenPermission) Valid() bool {
+	return p == AccessTokenRead || p == AccessTokenReadWrite
+}
+
+// AccessToken lets scripts call the JSON API without a browser session. Only the hash
+// of the token is stored, the token itself is shown once when it is created.
+type AccessToken struct {
+	base.Entity
+	Account    Account
+	Name       string
+	Prefix     string
+	TokenHash  []byte
+	Permission AccessTokenPermission
+
+	// ItemIDs and GroupIDs restrict the token to those vault items and the items
+	// shared with those groups. A token without restrictions sees the whole vault of
+	// its account.
+	ItemIDs  []types.ID
+	GroupIDs []types.ID
+
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+}
+
+func (t AccessToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+func (t AccessToken) CanWrite() bool {
+	return t.Permission == AccessTokenReadWrite
+}
+
+func (t AccessToken) Restricted() bool {
+	return len(t.ItemIDs) > 0 || len(t.GroupIDs) > 0
+}
+
+// AllowsItem reports whether the token may touch the item shared with the given groups.
+func (t AccessToken) AllowsItem(itemID types.ID, groupIDs []types.ID) bool {
+	if !t.Restricted() || slices.Contains(t.ItemIDs, itemID) {
+		return true
+	}
+
+	for _, groupID := range groupIDs {
+		if t.AllowsGroup(groupID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsGroup reports whether the token may touch the items shared with the group.
+func (t AccessToken) AllowsGroup(groupID types.ID) bool {
+	return !t.Restricted() || slices.Contains(t.GroupIDs, groupID)
+}
+
+// AllowsRegrouping reports whether the token may share an item with the groups to
+// instead of the groups from. A group out of the scope of the token can neither be
+// added to the item nor taken from it.
+func (t AccessToken) AllowsRegrouping(from, to []types.ID) bool {
+	for _, groupID := range from {
+		if !slices.Contains(to, groupID) && !t.AllowsGroup(groupID) {
+			return false
+		}
+	}
+
+	for _, groupID := range to {
+		if !slices.Contains(from, groupID) && !t.AllowsGroup(groupID) {
+			return false
+		}
+	}
+
+	return true
+}
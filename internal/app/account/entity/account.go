@@ -12,4 +12,35 @@ type Account struct {
 	LastName     string
 	TOTPSecret   []byte
 	OpaqueRecord []byte
+
+	// OpaqueKeyVersion is the version of the server keys the opaque record was
+	// registered under.
+	OpaqueKeyVersion int
+
+	// OpaqueCredentialID is the credential ID the OPRF key of the account is derived
+	// from, it stays the same across password changes. The accounts registered before
+	// it was kept have none.
+	OpaqueCredentialID []byte
+
+	// EncryptedVaultKey is the vault key wrapped by the client under the OPAQUE
+	// export key, the server can not read it.
+	EncryptedVaultKey []byte
+
+	// RecoveryVerifier is the hash of the proof derived from the recovery key and
+	// RecoveryVaultKey is the vault key wrapped under the recovery key. Both are empty
+	// when the user skipped the recovery kit.
+	RecoveryVerifier []byte
+	RecoveryVaultKey []byte
+
+	// PublicKey lets other accounts wrap keys for this one, the matching private key
+	// is stored wrapped under the vault key.
+	PublicKey           []byte
+	EncryptedPrivateKey []byte
+}
+
+// RecoveryKit is what the client sends for the recovery key it generated. The key
+// itself stays with the user.
+type RecoveryKit struct {
+	Proof             []byte
+	EncryptedVaultKey []byte
 }
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+)
+
+// AccountRecovery keeps the state of a recovery between the proof of the recovery
+// key and the registration of the new password.
+type AccountRecovery struct {
+	base.CacheEntity
+	AccountID    types.ID `json:"account_id"`
+	Username     string   `json:"username"`
+	CredentialID []byte   `json:"credential_id"`
+}
@@ -0,0 +1,15 @@
+package entity
+
+type AttemptScope string
+
+const (
+	AttemptScopeUsername  AttemptScope = "username"
+	AttemptScopeLogin     AttemptScope = "login"
+	AttemptScopeIP        AttemptScope = "ip"
+	AttemptScopeTwoFactor AttemptScope = "two-factor"
+
+	AttemptScopeAuthenticator     AttemptScope = "authenticator"
+	AttemptScopeEmailVerification AttemptScope = "email-verification"
+	AttemptScopeRecovery          AttemptScope = "recovery"
+	AttemptScopeGroupInvitation   AttemptScope = "group-invitation"
+)
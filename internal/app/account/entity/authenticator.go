@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+)
+
+// AuthenticatorEnrollment holds a new TOTP secret until the user confirms it with
+// a code from the new device. The secret is kept encrypted like the stored one.
+type AuthenticatorEnrollment struct {
+	base.CacheEntity
+	AccountID types.ID `json:"account_id"`
+	Secret    []byte   `json:"secret"`
+}
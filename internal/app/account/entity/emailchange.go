@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+)
+
+// EmailChange keeps the new email address until the code mailed to it is entered.
+type EmailChange struct {
+	base.CacheEntity
+	AccountID types.ID `json:"account_id"`
+	Email     string   `json:"email"`
+
+	// VerificationCode is the hash of the code mailed to Email. Attempts counts the
+	// wrong codes, the change is dropped once they are spent.
+	VerificationCode []byte `json:"verification_code"`
+	Attempts         int    `json:"attempts"`
+}
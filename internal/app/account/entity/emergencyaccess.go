@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+)
+
+type EmergencyAccessStatus string
+
+// An emergency access moves from invited to confirmed once the grantee accepts it and
+// the grantor wraps the vault key to the grantee's public key. A request of the
+// grantee is approved by the grantor or by the wait period running out, a rejection
+// moves it back to confirmed.
+const (
+	EmergencyAccessInvited   EmergencyAccessStatus = "invited"
+	EmergencyAccessAccepted  EmergencyAccessStatus = "accepted"
+	EmergencyAccessConfirmed EmergencyAccessStatus = "confirmed"
+	EmergencyAccessRequested EmergencyAccessStatus = "requested"
+	EmergencyAccessApproved  EmergencyAccessStatus = "approved"
+)
+
+type EmergencyAccess struct {
+	base.Entity
+	Grantor  Account
+	Grantee  Account
+	Status   EmergencyAccessStatus
+	WaitDays int
+
+	// EncryptedVaultKey is the vault key of the grantor wrapped to the public key of
+	// the grantee. It is only handed out once the access is approved.
+	EncryptedVaultKey []byte
+	RequestedAt       *time.Time
+}
+
+// ApproveAt is when a pending request is approved without the grantor.
+func (a EmergencyAccess) ApproveAt() time.Time {
+	if a.RequestedAt == nil {
+		return time.Time{}
+	}
+
+	return a.RequestedAt.AddDate(0, 0, a.WaitDays)
+}
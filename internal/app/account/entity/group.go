@@ -1,14 +1,85 @@
 package entity
 
 import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
 )
 
+// GroupRole is what a member can do in a group, every role can also do what the roles
+// below it can.
+type GroupRole string
+
+const (
+	// GroupRoleViewer reads the items shared with the group.
+	GroupRoleViewer GroupRole = "viewer"
+	// GroupRoleEditor shares items with the group and edits the items shared with it.
+	GroupRoleEditor GroupRole = "editor"
+	// GroupRoleAdmin edits the group and manages the members below admin.
+	GroupRoleAdmin GroupRole = "admin"
+	// GroupRoleOwner deletes the group and makes admins, a group has exactly one.
+	GroupRoleOwner GroupRole = "owner"
+)
+
+var groupRoleRanks = map[GroupRole]int{
+	GroupRoleViewer: 1,
+	GroupRoleEditor: 2,
+	GroupRoleAdmin:  3,
+	GroupRoleOwner:  4,
+}
+
+func (r GroupRole) Valid() bool {
+	_, ok := groupRoleRanks[r]
+	return ok
+}
+
+// AtLeast reports whether the role can do everything the given role can, the empty
+// role of an account that is not a member is below every role.
+func (r GroupRole) AtLeast(role GroupRole) bool {
+	return groupRoleRanks[r] >= groupRoleRanks[role]
+}
+
+func (r GroupRole) CanEditItems() bool {
+	return r.AtLeast(GroupRoleEditor)
+}
+
+func (r GroupRole) CanManageMembers() bool {
+	return r.AtLeast(GroupRoleAdmin)
+}
+
+// GroupNameMaxLength is the length of the name column of groups.
+const GroupNameMaxLength = 50
+
+// ValidGroupName reports whether the name fits the name column and has no line breaks
+// or other control characters, since the name ends up in the subject of mails.
+func ValidGroupName(name string) bool {
+	if strings.TrimSpace(name) == "" || utf8.RuneCountInString(name) > GroupNameMaxLength {
+		return false
+	}
+
+	return !strings.ContainsFunc(name, unicode.IsControl)
+}
+
 type Group struct {
 	base.Entity
 	Name        string
 	Description types.NullString
 	Owner       Account
 	Members     []Account
+
+	// Roles is the role of each member by account id.
+	Roles map[types.ID]GroupRole
+
+	// PendingOwnerID is the member the owner offered the group to, zero when there is
+	// no offer.
+	PendingOwnerID types.ID
+}
+
+// Role returns the role of the account in the group, or an empty role when it is not a
+// member.
+func (g Group) Role(accountID types.ID) GroupRole {
+	return g.Roles[accountID]
 }
@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+)
+
+// GroupInvitation asks an account to join a group with Role, nobody becomes a member
+// without accepting one. People without an account are invited by Email, Invitee is
+// empty until they sign up with that address.
+type GroupInvitation struct {
+	base.Entity
+	Group     Group
+	Inviter   Account
+	Invitee   Account
+	Email     string
+	Role      GroupRole
+	ExpiresAt time.Time
+}
+
+func (i GroupInvitation) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
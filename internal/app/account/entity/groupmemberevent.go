@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+)
+
+type GroupMemberAction string
+
+const (
+	GroupMemberAdded       GroupMemberAction = "added"
+	GroupMemberRemoved     GroupMemberAction = "removed"
+	GroupMemberRoleChanged GroupMemberAction = "role changed"
+)
+
+// GroupMemberChange moves a member of a group from PreviousRole to Role. A change from
+// the empty role adds the account and a change to it removes the member.
+type GroupMemberChange struct {
+	AccountID    types.ID
+	PreviousRole GroupRole
+	Role         GroupRole
+}
+
+func (c GroupMemberChange) Action() GroupMemberAction {
+	switch {
+	case c.PreviousRole == "":
+		return GroupMemberAdded
+	case c.Role == "":
+		return GroupMemberRemoved
+	default:
+		return GroupMemberRoleChanged
+	}
+}
+
+// GroupMemberEvent records a change to the members of a group and who made it, Actor
+// and Member are empty once their account is deleted.
+type GroupMemberEvent struct {
+	base.Entity
+	GroupMemberChange
+	GroupID types.ID
+	Actor   Account
+	Member  Account
+}
@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+)
+
+// PasswordChange keeps the state of a password change between the fresh login
+// and the new registration.
+type PasswordChange struct {
+	base.CacheEntity
+	AccountID    types.ID `json:"account_id"`
+	Username     string   `json:"username"`
+	AKEState     []byte   `json:"ake_state"`
+	CredentialID []byte   `json:"credential_id"`
+	Verified     bool     `json:"verified"`
+}
@@ -8,4 +8,8 @@ type Registration struct {
 	Email     string `json:"email"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
+
+	// VerificationCode is the hash of the code mailed to Email.
+	VerificationCode []byte `json:"verification_code"`
+	EmailVerified    bool   `json:"email_verified"`
 }
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+)
+
+// UsernameChange keeps the state of a username change. The username is the OPAQUE
+// client identity, so the user proves the password with a fresh login and registers
+// it again under the new username.
+type UsernameChange struct {
+	base.CacheEntity
+	AccountID    types.ID `json:"account_id"`
+	Username     string   `json:"username"`
+	NewUsername  string   `json:"new_username"`
+	AKEState     []byte   `json:"ake_state"`
+	CredentialID []byte   `json:"credential_id"`
+	Verified     bool     `json:"verified"`
+}
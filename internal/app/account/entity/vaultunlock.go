@@ -0,0 +1,14 @@
+package entity
+
+import (
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+)
+
+// VaultUnlock keeps the login state while the client proves the master password to
+// get its wrapped keys back, a client of the JSON API logs in the same way.
+type VaultUnlock struct {
+	base.CacheEntity
+	AccountID types.ID `json:"account_id"`
+	AKEState  []byte   `json:"ake_state"`
+}
@@ -3,58 +3,224 @@ package account
 import "github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
 
 const (
-	CodeGroupInvalidGroupID = 400_100
+	CodeGroupInvalidGroupID              = 400_100
+	CodeEmergencyAccessSelfGrant         = 400_101
+	CodeEmergencyAccessInvalidWaitPeriod = 400_102
+	CodeGroupInvalidNewOwner             = 400_103
+	CodeAccountGroupDecisionMissing      = 400_104
+	CodeAccessTokenInvalidPermission     = 400_105
+	CodeAccessTokenInvalidExpiry         = 400_106
+	CodeAccessTokenInvalidRestriction    = 400_107
+	CodePasswordVaultKeyMissing          = 400_108
+	CodeGroupInvalidRole                 = 400_109
+	CodeGroupInvalidName                 = 400_110
+	CodeUsernameChangeVaultKeyMissing    = 400_111
 
-	CodeAuthInvalidAccount = 401_100
+	CodeAuthInvalidAccount         = 401_100
+	CodeAuthTwoFactorAttemptsSpent = 401_101
+	CodeAuthRequired               = 401_102
+	CodeAccessTokenInvalid         = 401_103
 
-	CodeGroupOnlyTheOwnerCanEdit   = 403_100
-	CodeGroupOnlyTheOwnerCanDelete = 403_101
+	CodeGroupOnlyAdminsCanEdit           = 403_100
+	CodeGroupOnlyTheOwnerCanDelete       = 403_101
+	CodeAuthEmailNotVerified             = 403_102
+	CodeAccessTokenReadOnly              = 403_103
+	CodeAccessTokenRestricted            = 403_104
+	CodeGroupOnlyTheOwnerCanManageAdmins = 403_105
+	CodeGroupOnlyTheOwnerCanTransfer     = 403_106
+	CodeGroupOwnerCannotLeave            = 403_107
+	CodeGroupOwnerRoleFixed              = 403_108
 
-	CodeAuthTwoFactorDoesNotExist   = 404_100
-	CodeAccountUsernameDoesNotExist = 404_101
-	CodeGroupDoesNotExist           = 404_102
+	CodeAuthTwoFactorDoesNotExist       = 404_100
+	CodeAccountUsernameDoesNotExist     = 404_101
+	CodeGroupDoesNotExist               = 404_102
+	CodeSessionDoesNotExist             = 404_103
+	CodePasswordChangeDoesNotExist      = 404_104
+	CodeAuthEnrollmentDoesNotExist      = 404_105
+	CodeAuthRegistrationDoesNotExist    = 404_106
+	CodeAuthRecoveryDoesNotExist        = 404_107
+	CodeEmergencyAccessDoesNotExist     = 404_108
+	CodeVaultUnlockDoesNotExist         = 404_109
+	CodeEmailChangeDoesNotExist         = 404_110
+	CodeUsernameChangeDoesNotExist      = 404_111
+	CodeAccessTokenDoesNotExist         = 404_112
+	CodeAuthLoginDoesNotExist           = 404_113
+	CodeGroupInvitationDoesNotExist     = 404_114
+	CodeGroupOwnershipOfferDoesNotExist = 404_115
+	CodeGroupMemberDoesNotExist         = 404_116
 
-	CodeAuthUsernameExist = 409_100
-	CodeAuthEmailExist    = 409_101
+	CodeAuthUsernameExist           = 409_100
+	CodeAuthEmailExist              = 409_101
+	CodeEmergencyAccessExist        = 409_102
+	CodeEmergencyAccessInvalidState = 409_103
+	CodeAccountKeysMissing          = 409_104
+	CodeAccountKeysExist            = 409_105
+	CodeGroupNewOwnerNameTaken      = 409_106
+	CodeAccessTokenExist            = 409_107
+	CodePasswordRekeyNotNeeded      = 409_108
+	CodeGroupAlreadyMember          = 409_109
+	CodeGroupMembersChanged         = 409_110
 
 	CodeAuthInvalidPassword         = 422_100
 	CodeAuthInvalidVerificationCode = 422_101
+	CodeAuthInvalidRecoveryKit      = 422_102
+
+	CodeAuthAccountLocked = 423_100
+
+	CodeAuthTooManyAttempts     = 429_100
+	CodeGroupTooManyInvitations = 429_101
 )
 
 const (
 	// Auth
-	MessageAuthInvalidAccount          = "invalid user information"
-	MessageAuthUsernameExist           = "taken username"
-	MessageInvalidPassword             = "invalid password"
-	MessageAuthEmailExist              = "an account with that email already exist"
-	MessageAuthTwoFactorDoesNotExist   = "two factor authentication does not exist"
-	MessageAuthInvalidVerificationCode = "the verification code is invalid"
+	MessageAuthInvalidAccount           = "invalid user information"
+	MessageAuthUsernameExist            = "taken username"
+	MessageInvalidPassword              = "invalid password"
+	MessageAuthEmailExist               = "an account with that email already exist"
+	MessageAuthTwoFactorDoesNotExist    = "two factor authentication does not exist"
+	MessageAuthInvalidVerificationCode  = "the verification code is invalid"
+	MessageAuthTwoFactorAttemptsSpent   = "too many invalid verification codes, please log in again"
+	MessageAuthAccountLocked            = "the account is temporarily locked because of too many failed attempts"
+	MessageAuthTooManyAttempts          = "too many attempts, please try again later"
+	MessageAuthEnrollmentDoesNotExist   = "authenticator enrollment does not exist or has expired"
+	MessageAuthRegistrationDoesNotExist = "registration does not exist or has expired"
+	MessageAuthEmailNotVerified         = "the email address is not verified"
+	MessageAuthInvalidRecoveryKit       = "the recovery key or the verification code is invalid"
+	MessageAuthRecoveryDoesNotExist     = "account recovery does not exist or has expired"
+	MessageAuthRequired                 = "a session or an access token is required"
+	MessageAuthLoginDoesNotExist        = "login does not exist or has expired"
 
 	// Group
-	MessageGroupOnlyTheOwnerCanEdit   = "only the group owner can edit the group"
-	MessageGroupOnlyTheOwnerCanDelete = "only the group owner can delete the group"
-	MessageGroupInvalidGroupID        = "invalid group id"
-	MessageGroupDoesNotExist          = "group does not exist"
+	MessageGroupOnlyAdminsCanEdit           = "only the owner and the admins of the group can edit it"
+	MessageGroupOnlyTheOwnerCanDelete       = "only the group owner can delete the group"
+	MessageGroupInvalidGroupID              = "invalid group id"
+	MessageGroupDoesNotExist                = "group does not exist"
+	MessageGroupInvalidNewOwner             = "the new owner of a group must be one of its members"
+	MessageGroupNewOwnerNameTaken           = "the new owner already has a group with that name"
+	MessageGroupInvalidRole                 = "the role of a member must be viewer, editor or admin"
+	MessageGroupOnlyTheOwnerCanManageAdmins = "only the group owner can add, remove or change admins"
+	MessageGroupInvitationDoesNotExist      = "group invitation does not exist or has expired"
+	MessageGroupAlreadyMember               = "the account is already a member of the group"
+	MessageGroupOnlyTheOwnerCanTransfer     = "only the group owner can hand the group over"
+	MessageGroupOwnerCannotLeave            = "the owner has to hand the group over before leaving it"
+	MessageGroupOwnershipOfferDoesNotExist  = "the group was not offered to you"
+	MessageGroupOwnerRoleFixed              = "the owner keeps its role until the group is handed over"
+	MessageGroupMemberDoesNotExist          = "the account is not a member of the group"
+	MessageGroupMembersChanged              = "the members of the group changed meanwhile, reload and try again"
+	MessageGroupInvalidName                 = "the group name must be 1 to 50 characters without line breaks"
+	MessageGroupTooManyInvitations          = "too many group invitations, please try again later"
 
 	// Account
-	MessageAccountUsernameDoesNotExist = "account with that username does not exist"
+	MessageAccountUsernameDoesNotExist   = "account with that username does not exist"
+	MessageAccountGroupDecisionMissing   = "choose whether to hand over or delete every group you own"
+	MessageEmailChangeDoesNotExist       = "email change does not exist or has expired"
+	MessageUsernameChangeDoesNotExist    = "username change does not exist or has expired"
+	MessageUsernameChangeVaultKeyMissing = "the vault key wrapped under the new username is missing"
+
+	// Session
+	MessageSessionDoesNotExist = "session does not exist"
+
+	// Password
+	MessagePasswordChangeDoesNotExist = "password change does not exist or has expired"
+	MessagePasswordRekeyNotNeeded     = "the password is already registered under the current server keys"
+	MessagePasswordVaultKeyMissing    = "the vault key wrapped under the new password is missing"
+
+	// Keys
+	MessageAccountKeysMissing      = "the account has not published its public key yet"
+	MessageAccountKeysExist        = "the account already has a key pair"
+	MessageVaultUnlockDoesNotExist = "vault unlock does not exist or has expired"
+
+	// Emergency access
+	MessageEmergencyAccessSelfGrant         = "you can not grant emergency access to yourself"
+	MessageEmergencyAccessInvalidWaitPeriod = "the wait period must be between 1 and 90 days"
+	MessageEmergencyAccessDoesNotExist      = "emergency access does not exist"
+	MessageEmergencyAccessExist             = "emergency access for that account already exist"
+	MessageEmergencyAccessInvalidState      = "the emergency access does not allow this right now"
+
+	// Access token
+	MessageAccessTokenInvalidPermission  = "the permission must be read or read-write"
+	MessageAccessTokenInvalidExpiry      = "the expiry must be between 1 and 365 days, or never"
+	MessageAccessTokenInvalidRestriction = "a token can only be restricted to items and groups you can access"
+	MessageAccessTokenInvalid            = "invalid or expired access token"
+	MessageAccessTokenReadOnly           = "the access token is read-only"
+	MessageAccessTokenRestricted         = "the access token is not allowed to access this resource"
+	MessageAccessTokenDoesNotExist       = "access token does not exist"
+	MessageAccessTokenExist              = "an access token with that name already exist"
 )
 
 var (
 	// Auth
-	AuthInvalidAccount          = errors.NewError(MessageAuthInvalidAccount, CodeAuthInvalidAccount)
-	AuthUsernameExist           = errors.NewError(MessageAuthUsernameExist, CodeAuthUsernameExist)
-	AuthEmailExist              = errors.NewError(MessageAuthEmailExist, CodeAuthEmailExist)
-	AuthInvalidPassword         = errors.NewError(MessageInvalidPassword, CodeAuthInvalidPassword)
-	AuthTwoFactorDoesNotExist   = errors.NewError(MessageAuthTwoFactorDoesNotExist, CodeAuthTwoFactorDoesNotExist)
-	AuthInvalidVerificationCode = errors.NewError(MessageAuthInvalidVerificationCode, CodeAuthInvalidVerificationCode)
+	AuthInvalidAccount           = errors.NewError(MessageAuthInvalidAccount, CodeAuthInvalidAccount)
+	AuthUsernameExist            = errors.NewError(MessageAuthUsernameExist, CodeAuthUsernameExist)
+	AuthEmailExist               = errors.NewError(MessageAuthEmailExist, CodeAuthEmailExist)
+	AuthInvalidPassword          = errors.NewError(MessageInvalidPassword, CodeAuthInvalidPassword)
+	AuthTwoFactorDoesNotExist    = errors.NewError(MessageAuthTwoFactorDoesNotExist, CodeAuthTwoFactorDoesNotExist)
+	AuthInvalidVerificationCode  = errors.NewError(MessageAuthInvalidVerificationCode, CodeAuthInvalidVerificationCode)
+	AuthTwoFactorAttemptsSpent   = errors.NewError(MessageAuthTwoFactorAttemptsSpent, CodeAuthTwoFactorAttemptsSpent)
+	AuthAccountLocked            = errors.NewError(MessageAuthAccountLocked, CodeAuthAccountLocked)
+	AuthTooManyAttempts          = errors.NewError(MessageAuthTooManyAttempts, CodeAuthTooManyAttempts)
+	AuthEnrollmentDoesNotExist   = errors.NewError(MessageAuthEnrollmentDoesNotExist, CodeAuthEnrollmentDoesNotExist)
+	AuthRegistrationDoesNotExist = errors.NewError(MessageAuthRegistrationDoesNotExist, CodeAuthRegistrationDoesNotExist)
+	AuthEmailNotVerified         = errors.NewError(MessageAuthEmailNotVerified, CodeAuthEmailNotVerified)
+	AuthInvalidRecoveryKit       = errors.NewError(MessageAuthInvalidRecoveryKit, CodeAuthInvalidRecoveryKit)
+	AuthRecoveryDoesNotExist     = errors.NewError(MessageAuthRecoveryDoesNotExist, CodeAuthRecoveryDoesNotExist)
+	AuthRequired                 = errors.NewError(MessageAuthRequired, CodeAuthRequired)
+	AuthLoginDoesNotExist        = errors.NewError(MessageAuthLoginDoesNotExist, CodeAuthLoginDoesNotExist)
 
 	// Group
-	GroupOnlyTheOwnerCanEdit   = errors.NewError(MessageGroupOnlyTheOwnerCanEdit, CodeGroupOnlyTheOwnerCanEdit)
-	GroupOnlyTheOwnerCanDelete = errors.NewError(MessageGroupOnlyTheOwnerCanDelete, CodeGroupOnlyTheOwnerCanDelete)
-	GroupInvalidGroupID        = errors.NewError(MessageGroupInvalidGroupID, CodeGroupInvalidGroupID)
-	GroupDoesNotExist          = errors.NewError(MessageGroupDoesNotExist, CodeGroupDoesNotExist)
+	GroupOnlyAdminsCanEdit           = errors.NewError(MessageGroupOnlyAdminsCanEdit, CodeGroupOnlyAdminsCanEdit)
+	GroupOnlyTheOwnerCanDelete       = errors.NewError(MessageGroupOnlyTheOwnerCanDelete, CodeGroupOnlyTheOwnerCanDelete)
+	GroupInvalidGroupID              = errors.NewError(MessageGroupInvalidGroupID, CodeGroupInvalidGroupID)
+	GroupDoesNotExist                = errors.NewError(MessageGroupDoesNotExist, CodeGroupDoesNotExist)
+	GroupInvalidNewOwner             = errors.NewError(MessageGroupInvalidNewOwner, CodeGroupInvalidNewOwner)
+	GroupNewOwnerNameTaken           = errors.NewError(MessageGroupNewOwnerNameTaken, CodeGroupNewOwnerNameTaken)
+	GroupInvalidRole                 = errors.NewError(MessageGroupInvalidRole, CodeGroupInvalidRole)
+	GroupOnlyTheOwnerCanManageAdmins = errors.NewError(MessageGroupOnlyTheOwnerCanManageAdmins, CodeGroupOnlyTheOwnerCanManageAdmins)
+	GroupInvitationDoesNotExist      = errors.NewError(MessageGroupInvitationDoesNotExist, CodeGroupInvitationDoesNotExist)
+	GroupAlreadyMember               = errors.NewError(MessageGroupAlreadyMember, CodeGroupAlreadyMember)
+	GroupOnlyTheOwnerCanTransfer     = errors.NewError(MessageGroupOnlyTheOwnerCanTransfer, CodeGroupOnlyTheOwnerCanTransfer)
+	GroupOwnerCannotLeave            = errors.NewError(MessageGroupOwnerCannotLeave, CodeGroupOwnerCannotLeave)
+	GroupOwnershipOfferDoesNotExist  = errors.NewError(MessageGroupOwnershipOfferDoesNotExist, CodeGroupOwnershipOfferDoesNotExist)
+	GroupOwnerRoleFixed              = errors.NewError(MessageGroupOwnerRoleFixed, CodeGroupOwnerRoleFixed)
+	GroupMemberDoesNotExist          = errors.NewError(MessageGroupMemberDoesNotExist, CodeGroupMemberDoesNotExist)
+	GroupMembersChanged              = errors.NewError(MessageGroupMembersChanged, CodeGroupMembersChanged)
+	GroupInvalidName                 = errors.NewError(MessageGroupInvalidName, CodeGroupInvalidName)
+	GroupTooManyInvitations          = errors.NewError(MessageGroupTooManyInvitations, CodeGroupTooManyInvitations)
 
 	// Account
-	AccountUsernameDoesNotExist = errors.NewError(MessageAccountUsernameDoesNotExist, CodeAccountUsernameDoesNotExist)
+	AccountUsernameDoesNotExist   = errors.NewError(MessageAccountUsernameDoesNotExist, CodeAccountUsernameDoesNotExist)
+	AccountGroupDecisionMissing   = errors.NewError(MessageAccountGroupDecisionMissing, CodeAccountGroupDecisionMissing)
+	EmailChangeDoesNotExist       = errors.NewError(MessageEmailChangeDoesNotExist, CodeEmailChangeDoesNotExist)
+	UsernameChangeDoesNotExist    = errors.NewError(MessageUsernameChangeDoesNotExist, CodeUsernameChangeDoesNotExist)
+	UsernameChangeVaultKeyMissing = errors.NewError(MessageUsernameChangeVaultKeyMissing, CodeUsernameChangeVaultKeyMissing)
+
+	// Session
+	SessionDoesNotExist = errors.NewError(MessageSessionDoesNotExist, CodeSessionDoesNotExist)
+
+	// Password
+	PasswordChangeDoesNotExist = errors.NewError(MessagePasswordChangeDoesNotExist, CodePasswordChangeDoesNotExist)
+	PasswordRekeyNotNeeded     = errors.NewError(MessagePasswordRekeyNotNeeded, CodePasswordRekeyNotNeeded)
+	PasswordVaultKeyMissing    = errors.NewError(MessagePasswordVaultKeyMissing, CodePasswordVaultKeyMissing)
+
+	// Keys
+	AccountKeysMissing      = errors.NewError(MessageAccountKeysMissing, CodeAccountKeysMissing)
+	AccountKeysExist        = errors.NewError(MessageAccountKeysExist, CodeAccountKeysExist)
+	VaultUnlockDoesNotExist = errors.NewError(MessageVaultUnlockDoesNotExist, CodeVaultUnlockDoesNotExist)
+
+	// Emergency access
+	EmergencyAccessSelfGrant         = errors.NewError(MessageEmergencyAccessSelfGrant, CodeEmergencyAccessSelfGrant)
+	EmergencyAccessInvalidWaitPeriod = errors.NewError(MessageEmergencyAccessInvalidWaitPeriod, CodeEmergencyAccessInvalidWaitPeriod)
+	EmergencyAccessDoesNotExist      = errors.NewError(MessageEmergencyAccessDoesNotExist, CodeEmergencyAccessDoesNotExist)
+	EmergencyAccessExist             = errors.NewError(MessageEmergencyAccessExist, CodeEmergencyAccessExist)
+	EmergencyAccessInvalidState      = errors.NewError(MessageEmergencyAccessInvalidState, CodeEmergencyAccessInvalidState)
+
+	// Access token
+	AccessTokenInvalidPermission  = errors.NewError(MessageAccessTokenInvalidPermission, CodeAccessTokenInvalidPermission)
+	AccessTokenInvalidExpiry      = errors.NewError(MessageAccessTokenInvalidExpiry, CodeAccessTokenInvalidExpiry)
+	AccessTokenInvalidRestriction = errors.NewError(MessageAccessTokenInvalidRestriction, CodeAccessTokenInvalidRestriction)
+	AccessTokenInvalid            = errors.NewError(MessageAccessTokenInvalid, CodeAccessTokenInvalid)
+	AccessTokenReadOnly           = errors.NewError(MessageAccessTokenReadOnly, CodeAccessTokenReadOnly)
+	AccessTokenRestricted         = errors.NewError(MessageAccessTokenRestricted, CodeAccessTokenRestricted)
+	AccessTokenDoesNotExist       = errors.NewError(MessageAccessTokenDoesNotExist, CodeAccessTokenDoesNotExist)
+	AccessTokenExist              = errors.NewError(MessageAccessTokenExist, CodeAccessTokenExist)
 )
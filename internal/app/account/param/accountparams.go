@@ -0,0 +1,9 @@
+package param
+
+import "github.com/TheAmirhosssein/cool-password-manage/internal/types"
+
+type ReadAccountParams struct {
+	SearchQuery types.NullString
+	Limit       int
+	Offset      int
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/jackc/pgx/v5"
+)
+
+type AccessTokenRepository interface {
+	Create(ctx context.Context, token *entity.AccessToken) error
+	ReadByAccount(ctx context.Context, accountID types.ID) ([]entity.AccessToken, error)
+	ReadByHash(ctx context.Context, tokenHash []byte) (entity.AccessToken, error)
+	Touch(ctx context.Context, id types.ID, usedAt time.Time) error
+	Delete(ctx context.Context, id, accountID types.ID) error
+}
+
+type accessTokenRepo struct {
+	db database.Querier
+}
+
+func NewAccessTokenRepository(db database.Querier) AccessTokenRepository {
+	return accessTokenRepo{db: database.Join(db)}
+}
+
+const accessTokenSelect = `
+	SELECT t.id, t.name, t.prefix, t.token_hash, t.permission, t.expires_at, t.last_used_at, t.created_at,
+		a.id, a.username, a.email,
+		ARRAY(SELECT vault_item_id FROM access_tokens_vault_items WHERE access_token_id = t.id ORDER BY vault_item_id),
+		ARRAY(SELECT group_id FROM access_tokens_groups WHERE access_token_id = t.id ORDER BY group_id)
+	FROM access_tokens t
+	JOIN accounts a ON a.id = t.account_id`
+
+// Create stores the token along with its restrictions in one transaction. It returns
+// pgx.ErrNoRows when one of the items or groups is not visible to the account.
+func (r accessTokenRepo) Create(ctx context.Context, token *entity.AccessToken) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		log.ErrorLogger.Error("error at beginning transaction", "error", err.Error())
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO access_tokens (account_id, name, prefix, token_hash, permission, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`
+
+	err = tx.QueryRow(
+		ctx, query, token.Account.Entity.ID, token.Name, token.Prefix, token.TokenHash, token.Permission, token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		log.ErrorLogger.Error("error at creating access token", "error", err.Error())
+		return err
+	}
+
+	// an item is only linked when the account created it or it is shared with one of
+	// the groups of the account
+	for _, itemID := range token.ItemIDs {
+		query := `
+		INSERT INTO access_tokens_vault_items (access_token_id, vault_item_id)
+		SELECT $1, v.id FROM vault_items v
+		WHERE v.id = $2 AND (v.creator_id = $3 OR EXISTS(
+			SELECT 1 FROM vault_items_groups vg
+			JOIN groups_accounts ga ON ga.group_id = vg.group_id
+			WHERE vg.vault_item_id = v.id AND ga.account_id = $3
+		))`
+
+		tag, err := tx.Exec(ctx, query, token.ID, itemID, token.Account.Entity.ID)
+		if err != nil {
+			log.ErrorLogger.Error("error at restricting access token to item", "error", err.Error(), "item_id", itemID)
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+	}
+
+	for _, groupID := range token.GroupIDs {
+		query := `
+		INSERT INTO access_tokens_groups (access_token_id, group_id)
+		SELECT $1, group_id FROM groups_accounts WHERE group_id = $2 AND account_id = $3`
+
+		tag, err := tx.Exec(ctx, query, token.ID, groupID, token.Account.Entity.ID)
+		if err != nil {
+			log.ErrorLogger.Error("error at restricting access token to group", "error", err.Error(), "group_id", groupID)
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.ErrorLogger.Error("error at committing access token", "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r accessTokenRepo) ReadByAccount(ctx context.Context, accountID types.ID) ([]entity.AccessToken, error) {
+	rows, err := r.db.Query(ctx, accessTokenSelect+" WHERE t.account_id = $1 ORDER BY t.id", accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading access tokens", "error", err.Error(), "account_id", accountID)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]entity.AccessToken, 0)
+	for rows.Next() {
+		token, err := scanAccessToken(rows)
+		if err != nil {
+			log.ErrorLogger.Error("error at scanning access token", "error", err.Error())
+			return nil, err
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (r accessTokenRepo) ReadByHash(ctx context.Context, tokenHash []byte) (entity.AccessToken, error) {
+	token, err := scanAccessToken(r.db.QueryRow(ctx, accessTokenSelect+" WHERE t.token_hash = $1", tokenHash))
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			log.ErrorLogger.Error("error at reading access token", "error", err.Error())
+		}
+		return entity.AccessToken{}, err
+	}
+
+	return token, nil
+}
+
+func (r accessTokenRepo) Touch(ctx context.Context, id types.ID, usedAt time.Time) error {
+	_, err := r.db.Exec(ctx, "UPDATE access_tokens SET last_used_at = $1 WHERE id = $2", usedAt, id)
+	if err != nil {
+		log.ErrorLogger.Error("error at touching access token", "error", err.Error(), "id", id)
+		return err
+	}
+
+	return nil
+}
+
+func (r accessTokenRepo) Delete(ctx context.Context, id, accountID types.ID) error {
+	tag, err := r.db.Exec(ctx, "DELETE FROM access_tokens WHERE id = $1 AND account_id = $2", id, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at deleting access token", "error", err.Error(), "id", id)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func scanAccessToken(row pgx.Row) (entity.AccessToken, error) {
+	var (
+		token    entity.AccessToken
+		itemIDs  []int64
+		groupIDs []int64
+	)
+
+	err := row.Scan(
+		&token.ID, &token.Name, &token.Prefix, &token.TokenHash, &token.Permission, &token.ExpiresAt,
+		&token.LastUsedAt, &token.CreatedAt,
+		&token.Account.Entity.ID, &token.Account.Username, &token.Account.Email,
+		&itemIDs, &groupIDs,
+	)
+	if err != nil {
+		return entity.AccessToken{}, err
+	}
+
+	for _, id := range itemIDs {
+		token.ItemIDs = append(token.ItemIDs, types.ID(id))
+	}
+	for _, id := range groupIDs {
+		token.GroupIDs = append(token.GroupIDs, types.ID(id))
+	}
+
+	return token, nil
+}
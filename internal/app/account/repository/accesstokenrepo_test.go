@@ -0,0 +1,147 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessTokenRepository_Create(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccessTokenRepository(pgTestSuite.db)
+	owner := createAccount(t, "access_token_owner")
+	stranger := createAccount(t, "access_token_stranger")
+
+	group := entity.Group{Name: "token group", Owner: owner}
+	require.NoError(t, repository.NewGroupRepository(pgTestSuite.db).Create(ctx, &group))
+	require.NoError(t, repository.NewGroupRepository(pgTestSuite.db).AddAccounts(ctx, group.ID, []entity.Account{owner}, entity.GroupRoleOwner))
+
+	itemID := createVaultItem(t, "token item", owner.Entity.ID)
+
+	testcases := []struct {
+		name     string
+		account  entity.Account
+		hash     string
+		itemIDs  []types.ID
+		groupIDs []types.ID
+		err      error
+	}{
+		{
+			name:    "item of another account",
+			account: stranger,
+			hash:    "stranger item hash",
+			itemIDs: []types.ID{itemID},
+			err:     pgx.ErrNoRows,
+		},
+		{
+			name:     "group of another account",
+			account:  stranger,
+			hash:     "stranger group hash",
+			groupIDs: []types.ID{group.ID},
+			err:      pgx.ErrNoRows,
+		},
+		{
+			name:     "create successfully",
+			account:  owner,
+			hash:     "owner hash",
+			itemIDs:  []types.ID{itemID},
+			groupIDs: []types.ID{group.ID},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+			token := entity.AccessToken{
+				Account:    tc.account,
+				Name:       tc.name,
+				Prefix:     "cpm_12345678",
+				TokenHash:  []byte(tc.hash),
+				Permission: entity.AccessTokenRead,
+				ItemIDs:    tc.itemIDs,
+				GroupIDs:   tc.groupIDs,
+				ExpiresAt:  &expiresAt,
+			}
+
+			err := repo.Create(ctx, &token)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				// nothing of a rejected token is kept
+				_, err = repo.ReadByHash(ctx, []byte(tc.hash))
+				require.ErrorIs(t, err, pgx.ErrNoRows)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, token.ID.Valid())
+
+			saved, err := repo.ReadByHash(ctx, []byte(tc.hash))
+			require.NoError(t, err)
+			require.Equal(t, token.ID, saved.ID)
+			require.Equal(t, tc.account.Username, saved.Account.Username)
+			require.Equal(t, tc.itemIDs, saved.ItemIDs)
+			require.Equal(t, tc.groupIDs, saved.GroupIDs)
+			require.Equal(t, entity.AccessTokenRead, saved.Permission)
+			require.WithinDuration(t, expiresAt, *saved.ExpiresAt, time.Second)
+			require.Nil(t, saved.LastUsedAt)
+		})
+	}
+}
+
+func TestAccessTokenRepository_TouchAndDelete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccessTokenRepository(pgTestSuite.db)
+	owner := createAccount(t, "access_token_touch_owner")
+
+	token := entity.AccessToken{
+		Account:    owner,
+		Name:       "touched",
+		Prefix:     "cpm_12345678",
+		TokenHash:  []byte("touched hash"),
+		Permission: entity.AccessTokenReadWrite,
+	}
+	require.NoError(t, repo.Create(ctx, &token))
+
+	usedAt := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, repo.Touch(ctx, token.ID, usedAt))
+
+	tokens, err := repo.ReadByAccount(ctx, owner.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Nil(t, tokens[0].ExpiresAt)
+	require.Empty(t, tokens[0].ItemIDs)
+	require.WithinDuration(t, usedAt, *tokens[0].LastUsedAt, time.Second)
+
+	// only the owner can delete the token
+	err = repo.Delete(ctx, token.ID, types.ID(0))
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	err = repo.Delete(ctx, token.ID, owner.Entity.ID)
+	require.NoError(t, err)
+
+	tokens, err = repo.ReadByAccount(ctx, owner.Entity.ID)
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+}
+
+func createVaultItem(t *testing.T, name string, creatorID types.ID) types.ID {
+	query := `
+	INSERT INTO vault_items (name, encrypted_username, encrypted_password, nonce, creator_id)
+	VALUES ($1, $2, $3, $4, $5) RETURNING id`
+
+	var id types.ID
+	err := pgTestSuite.db.QueryRow(
+		context.Background(), query, name, []byte("username"), []byte("password"), []byte("nonce"), creatorID,
+	).Scan(&id)
+	require.NoError(t, err)
+
+	return id
+}
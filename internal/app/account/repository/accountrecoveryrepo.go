@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/redis/go-redis/v9"
+)
+
+type AccountRecoveryRepository interface {
+	Create(ctx context.Context, recovery entity.AccountRecovery) error
+	Get(ctx context.Context, id types.CacheID) (entity.AccountRecovery, error)
+	Exist(ctx context.Context, id types.CacheID) (bool, error)
+	Delete(ctx context.Context, id types.CacheID) error
+}
+
+type accountRecoveryRepo struct {
+	client *redis.Client
+}
+
+func NewAccountRecoveryRepository(client *redis.Client) AccountRecoveryRepository {
+	return accountRecoveryRepo{client: client}
+}
+
+func (r accountRecoveryRepo) Create(ctx context.Context, recovery entity.AccountRecovery) error {
+	marshaledRecovery, err := json.Marshal(recovery)
+	if err != nil {
+		log.ErrorLogger.Error("error marshaling account recovery", "error", err.Error())
+		return err
+	}
+
+	err = r.client.Set(ctx, accountRecoveryKey(recovery.ID), marshaledRecovery, recovery.Duration).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error saving account recovery", "error", err.Error(), "account_id", recovery.AccountID)
+		return err
+	}
+
+	return nil
+}
+
+func (r accountRecoveryRepo) Get(ctx context.Context, id types.CacheID) (entity.AccountRecovery, error) {
+	result, err := r.client.Get(ctx, accountRecoveryKey(id)).Bytes()
+	if err != nil {
+		log.ErrorLogger.Error("error getting account recovery", "error", err.Error(), "id", id)
+		return entity.AccountRecovery{}, err
+	}
+
+	recovery := new(entity.AccountRecovery)
+	if err := json.Unmarshal(result, recovery); err != nil {
+		log.ErrorLogger.Error("error at unmarshaling account recovery", "error", err.Error())
+		return entity.AccountRecovery{}, err
+	}
+
+	return *recovery, nil
+}
+
+func (r accountRecoveryRepo) Exist(ctx context.Context, id types.CacheID) (bool, error) {
+	count, err := r.client.Exists(ctx, accountRecoveryKey(id)).Result()
+	if err != nil {
+		log.ErrorLogger.Error("error checking account recovery existence", "error", err.Error(), "id", id)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r accountRecoveryRepo) Delete(ctx context.Context, id types.CacheID) error {
+	err := r.client.Del(ctx, accountRecoveryKey(id)).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error deleting account recovery", "error", err.Error(), "id", id)
+		return err
+	}
+
+	return nil
+}
+
+func accountRecoveryKey(id types.CacheID) string {
+	return fmt.Sprintf("account-recovery:%s", id)
+}
@@ -0,0 +1,83 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountRecoveryRepository_Get(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccountRecoveryRepository(redisClient)
+
+	recovery := entity.AccountRecovery{
+		CacheEntity:  base.CacheEntity{ID: "get_account_recovery", Duration: time.Minute},
+		AccountID:    types.ID(110),
+		Username:     "something",
+		CredentialID: []byte("credential"),
+	}
+	require.NoError(t, repo.Create(ctx, recovery))
+
+	testcases := []struct {
+		name string
+		id   types.CacheID
+		err  error
+	}{
+		{
+			name: "successful",
+			id:   recovery.ID,
+		},
+		{
+			name: "not found",
+			id:   "missing_account_recovery",
+			err:  redis.Nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			saved, err := repo.Get(ctx, tc.id)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, recovery.AccountID, saved.AccountID)
+			require.Equal(t, recovery.CredentialID, saved.CredentialID)
+		})
+	}
+}
+
+func TestAccountRecoveryRepository_Delete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccountRecoveryRepository(redisClient)
+
+	recovery := entity.AccountRecovery{
+		CacheEntity: base.CacheEntity{ID: "delete_account_recovery", Duration: time.Minute},
+		AccountID:   types.ID(111),
+	}
+	require.NoError(t, repo.Create(ctx, recovery))
+
+	exist, err := repo.Exist(ctx, recovery.ID)
+	require.NoError(t, err)
+	require.True(t, exist)
+
+	err = repo.Delete(ctx, recovery.ID)
+	require.NoError(t, err)
+
+	exist, err = repo.Exist(ctx, recovery.ID)
+	require.NoError(t, err)
+	require.False(t, exist)
+}
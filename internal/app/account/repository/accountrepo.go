@@ -2,38 +2,58 @@ package repository
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/param"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/helper"
 	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type AccountRepository interface {
 	Create(ctx context.Context, account entity.Account) error
 	ReadByUsername(ctx context.Context, username string) (entity.Account, error)
 	ReadByID(ctx context.Context, id types.ID) (entity.Account, error)
+	ReadByEmail(ctx context.Context, email string) (entity.Account, error)
+	Read(ctx context.Context, param param.ReadAccountParams) ([]entity.Account, int, error)
+	ReadTOTPSecrets(ctx context.Context, afterID types.ID, limit int) ([]entity.Account, error)
 	Update(ctx context.Context, account entity.Account) error
+	ReplaceTOTPSecret(ctx context.Context, id types.ID, oldSecret, newSecret []byte) error
+	UpdateCredentials(ctx context.Context, id types.ID, opaqueRecord, credentialID, encryptedVaultKey []byte, keyVersion int) error
+	UpdateKeys(ctx context.Context, id types.ID, publicKey, encryptedPrivateKey []byte) error
+	UpdateProfile(ctx context.Context, id types.ID, firstName, lastName string) error
+	UpdateEmail(ctx context.Context, id types.ID, email string) error
+	UpdateUsername(ctx context.Context, id types.ID, username string, opaqueRecord, credentialID, encryptedVaultKey []byte, keyVersion int) error
+	Delete(ctx context.Context, id types.ID) error
+	DeleteWithGroups(ctx context.Context, id types.ID, successors map[types.ID]types.ID) error
 	ExistByUsername(ctx context.Context, username string) (bool, error)
 	ExistByEmail(ctx context.Context, email string) (bool, error)
 }
 
 type accountRepo struct {
-	db *pgxpool.Pool
+	db database.Querier
 }
 
-func NewAccountRepository(db *pgxpool.Pool) AccountRepository {
-	return accountRepo{db: db}
+func NewAccountRepository(db database.Querier) AccountRepository {
+	return accountRepo{db: database.Join(db)}
 }
 
 func (r accountRepo) Create(ctx context.Context, account entity.Account) error {
 	query := `
-	INSERT INTO accounts (username, email, first_name, last_name, opaque_record, totp_secret)
-	VALUES ($1, $2, $3, $4, $5, $6)`
+	INSERT INTO accounts (
+		username, email, first_name, last_name, opaque_record, totp_secret,
+		encrypted_vault_key, recovery_verifier, recovery_vault_key, opaque_key_version, opaque_credential_id
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 
 	_, err := r.db.Exec(
 		ctx, query, account.Username, account.Email, account.FirstName, account.LastName, account.OpaqueRecord, account.TOTPSecret,
+		account.EncryptedVaultKey, account.RecoveryVerifier, account.RecoveryVaultKey, max(account.OpaqueKeyVersion, 1),
+		account.OpaqueCredentialID,
 	)
 
 	if err != nil {
@@ -45,11 +65,16 @@ func (r accountRepo) Create(ctx context.Context, account entity.Account) error {
 }
 
 func (r accountRepo) ReadByUsername(ctx context.Context, username string) (entity.Account, error) {
-	query := "SELECT id, username, email, opaque_record, totp_secret FROM accounts WHERE username = $1"
+	query := `
+	SELECT id, username, email, first_name, last_name, opaque_record, opaque_key_version, opaque_credential_id, totp_secret,
+		encrypted_vault_key, recovery_verifier, recovery_vault_key, public_key, encrypted_private_key
+	FROM accounts WHERE username = $1`
 
 	var account entity.Account
 	err := r.db.QueryRow(ctx, query, username).Scan(
-		&account.Entity.ID, &account.Username, &account.Email, &account.OpaqueRecord, &account.TOTPSecret,
+		&account.Entity.ID, &account.Username, &account.Email, &account.FirstName, &account.LastName, &account.OpaqueRecord,
+		&account.OpaqueKeyVersion, &account.OpaqueCredentialID, &account.TOTPSecret, &account.EncryptedVaultKey, &account.RecoveryVerifier, &account.RecoveryVaultKey,
+		&account.PublicKey, &account.EncryptedPrivateKey,
 	)
 
 	if err != nil {
@@ -61,10 +86,17 @@ func (r accountRepo) ReadByUsername(ctx context.Context, username string) (entit
 }
 
 func (r accountRepo) ReadByID(ctx context.Context, id types.ID) (entity.Account, error) {
-	query := "SELECT username, email, totp_secret FROM accounts WHERE id = $1"
+	query := `
+	SELECT id, username, email, first_name, last_name, opaque_record, opaque_key_version, opaque_credential_id, totp_secret,
+		encrypted_vault_key, recovery_verifier, recovery_vault_key, public_key, encrypted_private_key
+	FROM accounts WHERE id = $1`
 
 	var account entity.Account
-	err := r.db.QueryRow(ctx, query, id).Scan(&account.Username, &account.Email, &account.TOTPSecret)
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&account.Entity.ID, &account.Username, &account.Email, &account.FirstName, &account.LastName, &account.OpaqueRecord,
+		&account.OpaqueKeyVersion, &account.OpaqueCredentialID, &account.TOTPSecret, &account.EncryptedVaultKey, &account.RecoveryVerifier, &account.RecoveryVaultKey,
+		&account.PublicKey, &account.EncryptedPrivateKey,
+	)
 
 	if err != nil {
 		log.ErrorLogger.Error("getting account by id", "error", err.Error(), "id", id)
@@ -74,6 +106,96 @@ func (r accountRepo) ReadByID(ctx context.Context, id types.ID) (entity.Account,
 	return account, nil
 }
 
+// ReadByEmail returns the account with the email, ignoring its case, without the
+// credentials of the account.
+func (r accountRepo) ReadByEmail(ctx context.Context, email string) (entity.Account, error) {
+	query := "SELECT id, username, email, first_name, last_name FROM accounts WHERE lower(email) = lower($1) LIMIT 1"
+
+	var account entity.Account
+	err := r.db.QueryRow(ctx, query, email).Scan(
+		&account.Entity.ID, &account.Username, &account.Email, &account.FirstName, &account.LastName,
+	)
+
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			log.ErrorLogger.Error("getting account by email", "error", err.Error())
+		}
+		return entity.Account{}, err
+	}
+
+	return account, nil
+}
+
+// Read pages through every account for the operators, without the credentials of
+// the accounts.
+func (r accountRepo) Read(ctx context.Context, param param.ReadAccountParams) ([]entity.Account, int, error) {
+	searchQuery := helper.MakeSearchQuery(param.SearchQuery, []string{"username", "email", "first_name", "last_name"})
+
+	query := fmt.Sprintf(`
+	SELECT id, username, email, first_name, last_name, COUNT(*) OVER ()
+	FROM accounts WHERE TRUE %v
+	ORDER BY id
+	LIMIT $1 OFFSET $2`, searchQuery)
+
+	rows, err := r.db.Query(ctx, query, param.Limit, param.Offset)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading accounts", "error", err.Error())
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var (
+		accounts []entity.Account
+		count    int
+	)
+	for rows.Next() {
+		var account entity.Account
+		err := rows.Scan(&account.Entity.ID, &account.Username, &account.Email, &account.FirstName, &account.LastName, &count)
+		if err != nil {
+			log.ErrorLogger.Error("error at scanning accounts", "error", err.Error())
+			return nil, 0, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.ErrorLogger.Error("error at reading accounts", "error", err.Error())
+		return nil, 0, err
+	}
+
+	return accounts, count, nil
+}
+
+// ReadTOTPSecrets pages through the encrypted TOTP secrets by id, so a batch is not
+// skipped or read twice while accounts are created or deleted.
+func (r accountRepo) ReadTOTPSecrets(ctx context.Context, afterID types.ID, limit int) ([]entity.Account, error) {
+	query := "SELECT id, totp_secret FROM accounts WHERE id > $1 ORDER BY id LIMIT $2"
+
+	rows, err := r.db.Query(ctx, query, afterID, limit)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading totp secrets", "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []entity.Account
+	for rows.Next() {
+		var account entity.Account
+		if err := rows.Scan(&account.Entity.ID, &account.TOTPSecret); err != nil {
+			log.ErrorLogger.Error("error at scanning totp secrets", "error", err.Error())
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.ErrorLogger.Error("error at reading totp secrets", "error", err.Error())
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
 func (r accountRepo) Update(ctx context.Context, account entity.Account) error {
 	query := "UPDATE accounts SET totp_secret = $1 WHERE id = $2"
 
@@ -86,6 +208,157 @@ func (r accountRepo) Update(ctx context.Context, account entity.Account) error {
 	return nil
 }
 
+// ReplaceTOTPSecret stores the secret encrypted again only while the old one is still
+// stored, a secret the user enrolled in the meantime is kept and pgx.ErrNoRows returned.
+func (r accountRepo) ReplaceTOTPSecret(ctx context.Context, id types.ID, oldSecret, newSecret []byte) error {
+	query := "UPDATE accounts SET totp_secret = $1 WHERE id = $2 AND totp_secret = $3"
+
+	tag, err := r.db.Exec(ctx, query, newSecret, id, oldSecret)
+	if err != nil {
+		log.ErrorLogger.Error("error at replacing totp secret", "error", err.Error(), "id", id)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateCredentials replaces the opaque record, the credential ID its OPRF key is
+// derived from, its key version and the wrapped vault key in a single statement, so the
+// account never ends up with a record that does not match its key.
+func (r accountRepo) UpdateCredentials(ctx context.Context, id types.ID, opaqueRecord, credentialID, encryptedVaultKey []byte, keyVersion int) error {
+	query := `
+	UPDATE accounts SET opaque_record = $1, opaque_credential_id = $2, encrypted_vault_key = $3,
+		opaque_key_version = $4, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $5`
+
+	tag, err := r.db.Exec(ctx, query, opaqueRecord, credentialID, encryptedVaultKey, keyVersion, id)
+	if err != nil {
+		log.ErrorLogger.Error("error at updating account credentials", "error", err.Error(), "id", id)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// UpdateKeys stores the key pair of the account only when it has none, since grants
+// already wrapped to the old public key would break otherwise.
+func (r accountRepo) UpdateKeys(ctx context.Context, id types.ID, publicKey, encryptedPrivateKey []byte) error {
+	query := `
+	UPDATE accounts SET public_key = $1, encrypted_private_key = $2, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $3 AND public_key IS NULL`
+
+	tag, err := r.db.Exec(ctx, query, publicKey, encryptedPrivateKey, id)
+	if err != nil {
+		log.ErrorLogger.Error("error at updating account keys", "error", err.Error(), "id", id)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r accountRepo) UpdateProfile(ctx context.Context, id types.ID, firstName, lastName string) error {
+	query := "UPDATE accounts SET first_name = $1, last_name = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3"
+
+	return r.exec(ctx, "error at updating account profile", id, query, firstName, lastName, id)
+}
+
+func (r accountRepo) UpdateEmail(ctx context.Context, id types.ID, email string) error {
+	query := "UPDATE accounts SET email = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2"
+
+	return r.exec(ctx, "error at updating account email", id, query, email, id)
+}
+
+// UpdateUsername renames the account together with its opaque record and wrapped vault
+// key, since the username is the client identity the record was registered with.
+func (r accountRepo) UpdateUsername(
+	ctx context.Context, id types.ID, username string, opaqueRecord, credentialID, encryptedVaultKey []byte, keyVersion int,
+) error {
+	query := `
+	UPDATE accounts SET username = $1, opaque_record = $2, opaque_credential_id = $3, encrypted_vault_key = $4,
+		opaque_key_version = $5, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $6`
+
+	return r.exec(
+		ctx, "error at updating account username", id, query,
+		username, opaqueRecord, credentialID, encryptedVaultKey, keyVersion, id,
+	)
+}
+
+// Delete removes the account, its vault items, recovery codes and emergency accesses
+// go with it through the foreign keys.
+func (r accountRepo) Delete(ctx context.Context, id types.ID) error {
+	return r.exec(ctx, "error at deleting account", id, "DELETE FROM accounts WHERE id = $1", id)
+}
+
+// DeleteWithGroups hands every group in successors over to its successor, or deletes it
+// for a zero successor, and removes the account in the same transaction. Nothing is
+// changed when a group is no longer owned by the account or the successor left it.
+func (r accountRepo) DeleteWithGroups(ctx context.Context, id types.ID, successors map[types.ID]types.ID) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		for groupID, successorID := range successors {
+			var (
+				tag pgconn.CommandTag
+				err error
+			)
+			if successorID == 0 {
+				tag, err = tx.Exec(ctx, deleteGroupQuery, groupID, id)
+			} else {
+				tag, err = tx.Exec(ctx, updateGroupOwnerQuery, successorID, groupID, id)
+			}
+
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return pgx.ErrNoRows
+			}
+		}
+
+		tag, err := tx.Exec(ctx, "DELETE FROM accounts WHERE id = $1", id)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.ErrorLogger.Error("error at deleting account with its groups", "error", err.Error(), "id", id)
+	}
+
+	return err
+}
+
+// exec runs a statement that changes a single account and reports pgx.ErrNoRows when
+// there is no such account.
+func (r accountRepo) exec(ctx context.Context, message string, id types.ID, query string, args ...any) error {
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		log.ErrorLogger.Error(message, "error", err.Error(), "id", id)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
 func (r accountRepo) ExistByUsername(ctx context.Context, username string) (bool, error) {
 	query := "SELECT EXISTS(SELECT 1 FROM accounts WHERE username = $1) FROM accounts"
 
@@ -4,15 +4,18 @@ import (
 	"context"
 	"log"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/param"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/seed"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
 	"github.com/TheAmirhosssein/cool-password-manage/pkg/testdocker"
 	"github.com/alicebob/miniredis/v2"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
@@ -77,6 +80,22 @@ func TestAccountRepository_Create(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "create new account with recovery kit",
+			account: entity.Account{
+				Username:           "recovery_kit_user",
+				Email:              "recovery_kit_user@example.com",
+				FirstName:          "Recovery",
+				LastName:           "Kit",
+				OpaqueRecord:       []byte("new_record"),
+				OpaqueCredentialID: []byte("credential_id"),
+				TOTPSecret:         []byte("new_secret"),
+				EncryptedVaultKey:  []byte("vault_key"),
+				RecoveryVerifier:   []byte("verifier"),
+				RecoveryVaultKey:   []byte("recovery_vault_key"),
+			},
+			wantErr: false,
+		},
 		{
 			name: "duplicate username",
 			account: entity.Account{
@@ -117,6 +136,10 @@ func TestAccountRepository_Create(t *testing.T) {
 				require.NoError(t, err)
 				require.Equal(t, tc.account.Username, account.Username)
 				require.Equal(t, tc.account.Email, account.Email)
+				require.Equal(t, tc.account.OpaqueCredentialID, account.OpaqueCredentialID)
+				require.Equal(t, tc.account.EncryptedVaultKey, account.EncryptedVaultKey)
+				require.Equal(t, tc.account.RecoveryVerifier, account.RecoveryVerifier)
+				require.Equal(t, tc.account.RecoveryVaultKey, account.RecoveryVaultKey)
 			}
 		})
 	}
@@ -159,7 +182,10 @@ func TestAccountRepository_ReadByUsername(t *testing.T) {
 				require.NoError(t, err)
 				require.Equal(t, tc.expect.Username, account.Username)
 				require.Equal(t, tc.expect.Email, account.Email)
+				require.Equal(t, tc.expect.FirstName, account.FirstName)
+				require.Equal(t, tc.expect.LastName, account.LastName)
 				require.Equal(t, tc.expect.TOTPSecret, account.TOTPSecret)
+				require.Equal(t, 1, account.OpaqueKeyVersion)
 			}
 		})
 	}
@@ -202,12 +228,63 @@ func TestAccountRepository_ReadByID(t *testing.T) {
 				require.NoError(t, err)
 				require.Equal(t, tc.expect.Username, account.Username)
 				require.Equal(t, tc.expect.Email, account.Email)
+				require.Equal(t, tc.expect.FirstName, account.FirstName)
+				require.Equal(t, tc.expect.LastName, account.LastName)
 				require.Equal(t, tc.expect.TOTPSecret, account.TOTPSecret)
 			}
 		})
 	}
 }
 
+func TestAccountRepository_ReadByEmail(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccountRepository(pgTestSuite.db)
+
+	testcases := []struct {
+		name    string
+		email   string
+		expect  entity.Account
+		wantErr bool
+	}{
+		{
+			name:    "existing user",
+			email:   seed.AccountJohnDoe.Email,
+			expect:  seed.AccountJohnDoe,
+			wantErr: false,
+		},
+		{
+			name:    "existing user in another case",
+			email:   strings.ToUpper(seed.AccountJohnDoe.Email),
+			expect:  seed.AccountJohnDoe,
+			wantErr: false,
+		},
+		{
+			name:    "non-existing user",
+			email:   "not_found@example.com",
+			expect:  entity.Account{},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			account, err := repo.ReadByEmail(ctx, tc.email)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.expect.Entity.ID, account.Entity.ID)
+				require.Equal(t, tc.expect.Username, account.Username)
+				require.Equal(t, tc.expect.Email, account.Email)
+			}
+		})
+	}
+}
+
 func TestAccountRepository_Update(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -240,6 +317,229 @@ func TestAccountRepository_Update(t *testing.T) {
 	}
 }
 
+func TestAccountRepository_UpdateCredentials(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccountRepository(pgTestSuite.db)
+
+	testcases := []struct {
+		name              string
+		id                types.ID
+		opaqueRecord      []byte
+		credentialID      []byte
+		encryptedVaultKey []byte
+		keyVersion        int
+		wantErr           bool
+	}{
+		{
+			name:              "valid update",
+			id:                seed.AccountKevinAbstract.Entity.ID,
+			opaqueRecord:      []byte("new opaque record"),
+			credentialID:      []byte("new credential id"),
+			encryptedVaultKey: []byte("new encrypted vault key"),
+			keyVersion:        2,
+			wantErr:           false,
+		},
+		{
+			name:              "non-existing user",
+			id:                types.ID(0),
+			opaqueRecord:      []byte("new opaque record"),
+			credentialID:      []byte("new credential id"),
+			encryptedVaultKey: []byte("new encrypted vault key"),
+			keyVersion:        2,
+			wantErr:           true,
+		},
+	}
+
+	for _, tc := range testcases {
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := repo.UpdateCredentials(ctx, tc.id, tc.opaqueRecord, tc.credentialID, tc.encryptedVaultKey, tc.keyVersion)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			account, err := repo.ReadByID(ctx, tc.id)
+			require.NoError(t, err)
+			require.Equal(t, tc.opaqueRecord, account.OpaqueRecord)
+			require.Equal(t, tc.credentialID, account.OpaqueCredentialID)
+			require.Equal(t, tc.encryptedVaultKey, account.EncryptedVaultKey)
+			require.Equal(t, tc.keyVersion, account.OpaqueKeyVersion)
+		})
+	}
+}
+
+func TestAccountRepository_UpdateKeys(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccountRepository(pgTestSuite.db)
+
+	testcases := []struct {
+		name                string
+		id                  types.ID
+		publicKey           []byte
+		encryptedPrivateKey []byte
+		wantErr             bool
+	}{
+		{
+			name:                "first publish",
+			id:                  seed.AccountJoba.Entity.ID,
+			publicKey:           []byte("public key"),
+			encryptedPrivateKey: []byte("encrypted private key"),
+			wantErr:             false,
+		},
+		{
+			name:                "keys already published",
+			id:                  seed.AccountJoba.Entity.ID,
+			publicKey:           []byte("another public key"),
+			encryptedPrivateKey: []byte("another encrypted private key"),
+			wantErr:             true,
+		},
+		{
+			name:                "non-existing user",
+			id:                  types.ID(0),
+			publicKey:           []byte("public key"),
+			encryptedPrivateKey: []byte("encrypted private key"),
+			wantErr:             true,
+		},
+	}
+
+	// the cases depend on each other, so they run in order
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := repo.UpdateKeys(ctx, tc.id, tc.publicKey, tc.encryptedPrivateKey)
+			if tc.wantErr {
+				require.ErrorIs(t, err, pgx.ErrNoRows)
+				return
+			}
+
+			require.NoError(t, err)
+			account, err := repo.ReadByID(ctx, tc.id)
+			require.NoError(t, err)
+			require.Equal(t, tc.publicKey, account.PublicKey)
+			require.Equal(t, tc.encryptedPrivateKey, account.EncryptedPrivateKey)
+		})
+	}
+}
+
+func TestAccountRepository_UpdateProfile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccountRepository(pgTestSuite.db)
+	acc := createAccount(t, "update_profile_user")
+
+	err := repo.UpdateProfile(ctx, acc.Entity.ID, "New", "Name")
+	require.NoError(t, err)
+
+	err = repo.UpdateEmail(ctx, acc.Entity.ID, "new_profile_email@example.com")
+	require.NoError(t, err)
+
+	updated, err := repo.ReadByID(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, "New", updated.FirstName)
+	require.Equal(t, "Name", updated.LastName)
+	require.Equal(t, "new_profile_email@example.com", updated.Email)
+
+	err = repo.UpdateProfile(ctx, types.ID(0), "New", "Name")
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	err = repo.UpdateEmail(ctx, types.ID(0), "missing@example.com")
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestAccountRepository_UpdateUsername(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccountRepository(pgTestSuite.db)
+	acc := createAccount(t, "update_username_user")
+
+	err := repo.UpdateUsername(
+		ctx, acc.Entity.ID, "renamed_username_user", []byte("new record"), []byte("credential id"), []byte("new vault key"), 2,
+	)
+	require.NoError(t, err)
+
+	updated, err := repo.ReadByUsername(ctx, "renamed_username_user")
+	require.NoError(t, err)
+	require.Equal(t, acc.Entity.ID, updated.Entity.ID)
+	require.Equal(t, []byte("new record"), updated.OpaqueRecord)
+	require.Equal(t, []byte("credential id"), updated.OpaqueCredentialID)
+	require.Equal(t, []byte("new vault key"), updated.EncryptedVaultKey)
+	require.Equal(t, 2, updated.OpaqueKeyVersion)
+
+	_, err = repo.ReadByUsername(ctx, "update_username_user")
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	// usernames stay unique
+	err = repo.UpdateUsername(ctx, acc.Entity.ID, seed.AccountJohnDoe.Username, []byte("record"), nil, []byte("key"), 1)
+	require.Error(t, err)
+}
+
+func TestAccountRepository_Delete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccountRepository(pgTestSuite.db)
+	acc := createAccount(t, "delete_account_user")
+
+	err := repo.Delete(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+
+	_, err = repo.ReadByID(ctx, acc.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	err = repo.Delete(ctx, acc.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestAccountRepository_DeleteWithGroups(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccountRepository(pgTestSuite.db)
+	groupRepo := repository.NewGroupRepository(pgTestSuite.db)
+	acc := createAccount(t, "delete_with_groups_user")
+	member := createAccount(t, "delete_with_groups_member")
+	stranger := createAccount(t, "delete_with_groups_stranger")
+
+	private := entity.Group{Name: "private group", Owner: acc}
+	require.NoError(t, groupRepo.Create(ctx, &private))
+	require.NoError(t, groupRepo.AddAccounts(ctx, private.ID, []entity.Account{acc}, entity.GroupRoleOwner))
+
+	shared := entity.Group{Name: "shared group", Owner: acc}
+	require.NoError(t, groupRepo.Create(ctx, &shared))
+	require.NoError(t, groupRepo.AddAccounts(ctx, shared.ID, []entity.Account{acc}, entity.GroupRoleOwner))
+	require.NoError(t, groupRepo.AddAccounts(ctx, shared.ID, []entity.Account{member}, entity.GroupRoleEditor))
+
+	// a successor that is not a member rolls the whole deletion back
+	err := repo.DeleteWithGroups(ctx, acc.Entity.ID, map[types.ID]types.ID{private.ID: 0, shared.ID: stranger.Entity.ID})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	_, err = repo.ReadByID(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+
+	groups, err := groupRepo.ReadByOwner(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+
+	err = repo.DeleteWithGroups(ctx, acc.Entity.ID, map[types.ID]types.ID{private.ID: 0, shared.ID: member.Entity.ID})
+	require.NoError(t, err)
+
+	_, err = repo.ReadByID(ctx, acc.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	groups, err = groupRepo.ReadByOwner(ctx, member.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, shared.ID, groups[0].ID)
+
+	var exist bool
+	err = pgTestSuite.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM groups WHERE id = $1)", private.ID).Scan(&exist)
+	require.NoError(t, err)
+	require.False(t, exist)
+}
+
 func TestAccountRepository_ExistByUsername(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -303,3 +603,126 @@ func TestAccountRepository_ExistByEmail(t *testing.T) {
 		})
 	}
 }
+
+func TestAccountRepository_Read(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccountRepository(pgTestSuite.db)
+
+	first := createAccount(t, "read_accounts_first")
+	second := createAccount(t, "read_accounts_second")
+
+	testcases := []struct {
+		name     string
+		params   param.ReadAccountParams
+		expected []string
+		count    int
+	}{
+		{
+			name:     "search",
+			params:   param.ReadAccountParams{SearchQuery: types.NewNullString("read_accounts_"), Limit: 10},
+			expected: []string{first.Username, second.Username},
+			count:    2,
+		},
+		{
+			name:     "second page",
+			params:   param.ReadAccountParams{SearchQuery: types.NewNullString("read_accounts_"), Limit: 1, Offset: 1},
+			expected: []string{second.Username},
+			count:    2,
+		},
+		{
+			name:     "no match",
+			params:   param.ReadAccountParams{SearchQuery: types.NewNullString("read_accounts_missing"), Limit: 10},
+			expected: nil,
+			count:    0,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			accounts, count, err := repo.Read(ctx, tc.params)
+			require.NoError(t, err)
+			require.Equal(t, tc.count, count)
+
+			var usernames []string
+			for _, acc := range accounts {
+				usernames = append(usernames, acc.Username)
+			}
+			require.Equal(t, tc.expected, usernames)
+		})
+	}
+}
+
+func TestAccountRepository_ReadTOTPSecrets(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccountRepository(pgTestSuite.db)
+
+	acc := createAccount(t, "read_totp_secrets_user")
+
+	accounts, err := repo.ReadTOTPSecrets(ctx, acc.Entity.ID-1, 1)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, acc.Entity.ID, accounts[0].Entity.ID)
+	require.Equal(t, acc.TOTPSecret, accounts[0].TOTPSecret)
+}
+
+func TestAccountRepository_ReplaceTOTPSecret(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAccountRepository(pgTestSuite.db)
+
+	acc := createAccount(t, "replace_totp_secret_user")
+
+	testcases := []struct {
+		name      string
+		oldSecret []byte
+		newSecret []byte
+		err       error
+		expected  []byte
+	}{
+		{
+			name:      "stale secret",
+			oldSecret: []byte("stale"),
+			newSecret: []byte("ignored"),
+			err:       pgx.ErrNoRows,
+			expected:  acc.TOTPSecret,
+		},
+		{
+			name:      "current secret",
+			oldSecret: acc.TOTPSecret,
+			newSecret: []byte("replaced"),
+			expected:  []byte("replaced"),
+		},
+	}
+
+	for _, tc := range testcases {
+		err := repo.ReplaceTOTPSecret(ctx, acc.Entity.ID, tc.oldSecret, tc.newSecret)
+		require.ErrorIs(t, err, tc.err, tc.name)
+
+		updated, err := repo.ReadByID(ctx, acc.Entity.ID)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, updated.TOTPSecret, tc.name)
+	}
+}
+
+func createAccount(t *testing.T, username string) entity.Account {
+	ctx := context.Background()
+	repo := repository.NewAccountRepository(pgTestSuite.db)
+
+	err := repo.Create(ctx, entity.Account{
+		Username:     username,
+		Email:        username + "@example.com",
+		FirstName:    "First",
+		LastName:     "Last",
+		OpaqueRecord: []byte("record"),
+		TOTPSecret:   []byte("secret"),
+	})
+	require.NoError(t, err)
+
+	acc, err := repo.ReadByUsername(ctx, username)
+	require.NoError(t, err)
+
+	return acc
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/redis/go-redis/v9"
+)
+
+type AttemptRepository interface {
+	Increment(ctx context.Context, scope entity.AttemptScope, identifier string, window time.Duration) (int64, error)
+	Reset(ctx context.Context, scope entity.AttemptScope, identifier string) error
+	Decrement(ctx context.Context, scope entity.AttemptScope, identifier string) error
+	Lock(ctx context.Context, scope entity.AttemptScope, identifier string, duration time.Duration) error
+	LockedFor(ctx context.Context, scope entity.AttemptScope, identifier string) (time.Duration, error)
+	Unlock(ctx context.Context, scope entity.AttemptScope, identifier string) error
+}
+
+type attemptRepo struct {
+	client *redis.Client
+}
+
+func NewAttemptRepository(client *redis.Client) AttemptRepository {
+	return attemptRepo{client: client}
+}
+
+func (r attemptRepo) Increment(ctx context.Context, scope entity.AttemptScope, identifier string, window time.Duration) (int64, error) {
+	key := attemptKey(scope, identifier)
+
+	pipe := r.client.TxPipeline()
+	count := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, window)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.ErrorLogger.Error("error incrementing attempts", "error", err.Error(), "scope", scope, "identifier", identifier)
+		return 0, err
+	}
+
+	return count.Val(), nil
+}
+
+func (r attemptRepo) Reset(ctx context.Context, scope entity.AttemptScope, identifier string) error {
+	err := r.client.Del(ctx, attemptKey(scope, identifier)).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error resetting attempts", "error", err.Error(), "scope", scope, "identifier", identifier)
+		return err
+	}
+
+	return nil
+}
+
+// decrementScript takes one attempt back without going below zero or creating a counter
+// that never expires.
+var decrementScript = redis.NewScript(`
+if tonumber(redis.call("GET", KEYS[1]) or "0") > 0 then
+	return redis.call("DECR", KEYS[1])
+end
+return 0`)
+
+// Decrement takes one attempt of the identifier back, the counter keeps its expiry.
+func (r attemptRepo) Decrement(ctx context.Context, scope entity.AttemptScope, identifier string) error {
+	err := decrementScript.Run(ctx, r.client, []string{attemptKey(scope, identifier)}).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error decrementing attempts", "error", err.Error(), "scope", scope, "identifier", identifier)
+		return err
+	}
+
+	return nil
+}
+
+func (r attemptRepo) Lock(ctx context.Context, scope entity.AttemptScope, identifier string, duration time.Duration) error {
+	err := r.client.Set(ctx, lockKey(scope, identifier), time.Now().Add(duration).Unix(), duration).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error saving lock", "error", err.Error(), "scope", scope, "identifier", identifier)
+		return err
+	}
+
+	return nil
+}
+
+func (r attemptRepo) LockedFor(ctx context.Context, scope entity.AttemptScope, identifier string) (time.Duration, error) {
+	ttl, err := r.client.TTL(ctx, lockKey(scope, identifier)).Result()
+	if err != nil {
+		log.ErrorLogger.Error("error checking lock", "error", err.Error(), "scope", scope, "identifier", identifier)
+		return 0, err
+	}
+
+	// redis returns a negative ttl when the key does not exist
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+func (r attemptRepo) Unlock(ctx context.Context, scope entity.AttemptScope, identifier string) error {
+	err := r.client.Del(ctx, lockKey(scope, identifier), attemptKey(scope, identifier)).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error removing lock", "error", err.Error(), "scope", scope, "identifier", identifier)
+		return err
+	}
+
+	return nil
+}
+
+func attemptKey(scope entity.AttemptScope, identifier string) string {
+	return fmt.Sprintf("attempts:%s:%s", scope, identifier)
+}
+
+func lockKey(scope entity.AttemptScope, identifier string) string {
+	return fmt.Sprintf("lock:%s:%s", scope, identifier)
+}
@@ -0,0 +1,157 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttemptRepository_Increment(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAttemptRepository(redisClient)
+
+	testcases := []struct {
+		name       string
+		scope      entity.AttemptScope
+		identifier string
+		times      int
+		expected   int64
+	}{
+		{
+			name:       "first attempt",
+			scope:      entity.AttemptScopeUsername,
+			identifier: "increment_once",
+			times:      1,
+			expected:   1,
+		},
+		{
+			name:       "several attempts",
+			scope:      entity.AttemptScopeIP,
+			identifier: "198.51.100.1",
+			times:      4,
+			expected:   4,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var count int64
+			for i := 0; i < tc.times; i++ {
+				var err error
+				count, err = repo.Increment(ctx, tc.scope, tc.identifier, time.Minute)
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expected, count)
+		})
+	}
+}
+
+func TestAttemptRepository_Reset(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAttemptRepository(redisClient)
+
+	_, err := repo.Increment(ctx, entity.AttemptScopeTwoFactor, "reset_me", time.Minute)
+	require.NoError(t, err)
+
+	err = repo.Reset(ctx, entity.AttemptScopeTwoFactor, "reset_me")
+	require.NoError(t, err)
+
+	count, err := repo.Increment(ctx, entity.AttemptScopeTwoFactor, "reset_me", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+func TestAttemptRepository_Decrement(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAttemptRepository(redisClient)
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.Increment(ctx, entity.AttemptScopeIP, "decrement_me", time.Minute)
+		require.NoError(t, err)
+	}
+
+	err := repo.Decrement(ctx, entity.AttemptScopeIP, "decrement_me")
+	require.NoError(t, err)
+
+	count, err := repo.Increment(ctx, entity.AttemptScopeIP, "decrement_me", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	// a missing counter is not created and never goes below zero
+	err = repo.Decrement(ctx, entity.AttemptScopeIP, "never_counted")
+	require.NoError(t, err)
+
+	count, err = repo.Increment(ctx, entity.AttemptScopeIP, "never_counted", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+func TestAttemptRepository_Lock(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAttemptRepository(redisClient)
+
+	testcases := []struct {
+		name       string
+		identifier string
+		lock       time.Duration
+		locked     bool
+	}{
+		{
+			name:       "locked",
+			identifier: "locked_user",
+			lock:       time.Minute,
+			locked:     true,
+		},
+		{
+			name:       "not locked",
+			identifier: "free_user",
+			locked:     false,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if tc.lock > 0 {
+				err := repo.Lock(ctx, entity.AttemptScopeUsername, tc.identifier, tc.lock)
+				require.NoError(t, err)
+			}
+
+			lockedFor, err := repo.LockedFor(ctx, entity.AttemptScopeUsername, tc.identifier)
+			require.NoError(t, err)
+			require.Equal(t, tc.locked, lockedFor > 0)
+		})
+	}
+}
+
+func TestAttemptRepository_Unlock(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewAttemptRepository(redisClient)
+
+	_, err := repo.Increment(ctx, entity.AttemptScopeUsername, "unlock_me", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, repo.Lock(ctx, entity.AttemptScopeUsername, "unlock_me", time.Minute))
+
+	err = repo.Unlock(ctx, entity.AttemptScopeUsername, "unlock_me")
+	require.NoError(t, err)
+
+	lockedFor, err := repo.LockedFor(ctx, entity.AttemptScopeUsername, "unlock_me")
+	require.NoError(t, err)
+	require.Zero(t, lockedFor)
+
+	count, err := repo.Increment(ctx, entity.AttemptScopeUsername, "unlock_me", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/redis/go-redis/v9"
+)
+
+type EmailChangeRepository interface {
+	Create(ctx context.Context, change entity.EmailChange) error
+	Get(ctx context.Context, id types.CacheID) (entity.EmailChange, error)
+	Exist(ctx context.Context, id types.CacheID) (bool, error)
+	Delete(ctx context.Context, id types.CacheID) error
+}
+
+type emailChangeRepo struct {
+	client *redis.Client
+}
+
+func NewEmailChangeRepository(client *redis.Client) EmailChangeRepository {
+	return emailChangeRepo{client: client}
+}
+
+func (r emailChangeRepo) Create(ctx context.Context, change entity.EmailChange) error {
+	marshaledChange, err := json.Marshal(change)
+	if err != nil {
+		log.ErrorLogger.Error("error marshaling email change", "error", err.Error())
+		return err
+	}
+
+	err = r.client.Set(ctx, emailChangeKey(change.ID), marshaledChange, change.Duration).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error saving email change", "error", err.Error(), "account_id", change.AccountID)
+		return err
+	}
+
+	return nil
+}
+
+func (r emailChangeRepo) Get(ctx context.Context, id types.CacheID) (entity.EmailChange, error) {
+	result, err := r.client.Get(ctx, emailChangeKey(id)).Bytes()
+	if err != nil {
+		log.ErrorLogger.Error("error getting email change", "error", err.Error(), "id", id)
+		return entity.EmailChange{}, err
+	}
+
+	change := new(entity.EmailChange)
+	if err := json.Unmarshal(result, change); err != nil {
+		log.ErrorLogger.Error("error at unmarshaling email change", "error", err.Error())
+		return entity.EmailChange{}, err
+	}
+
+	return *change, nil
+}
+
+func (r emailChangeRepo) Exist(ctx context.Context, id types.CacheID) (bool, error) {
+	count, err := r.client.Exists(ctx, emailChangeKey(id)).Result()
+	if err != nil {
+		log.ErrorLogger.Error("error checking email change existence", "error", err.Error(), "id", id)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r emailChangeRepo) Delete(ctx context.Context, id types.CacheID) error {
+	err := r.client.Del(ctx, emailChangeKey(id)).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error deleting email change", "error", err.Error(), "id", id)
+		return err
+	}
+
+	return nil
+}
+
+func emailChangeKey(id types.CacheID) string {
+	return fmt.Sprintf("email-change:%s", id)
+}
@@ -0,0 +1,44 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailChangeRepository(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewEmailChangeRepository(redisClient)
+
+	change := entity.EmailChange{
+		CacheEntity:      base.CacheEntity{ID: "email_change", Duration: time.Minute},
+		AccountID:        types.ID(120),
+		Email:            "changed@example.com",
+		VerificationCode: []byte("hashed code"),
+		Attempts:         1,
+	}
+
+	err := repo.Create(ctx, change)
+	require.NoError(t, err)
+
+	saved, err := repo.Get(ctx, change.ID)
+	require.NoError(t, err)
+	require.Equal(t, change.AccountID, saved.AccountID)
+	require.Equal(t, change.Email, saved.Email)
+	require.Equal(t, change.VerificationCode, saved.VerificationCode)
+	require.Equal(t, change.Attempts, saved.Attempts)
+
+	err = repo.Delete(ctx, change.ID)
+	require.NoError(t, err)
+
+	exist, err := repo.Exist(ctx, change.ID)
+	require.NoError(t, err)
+	require.False(t, exist)
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/jackc/pgx/v5"
+)
+
+type EmergencyAccessRepository interface {
+	Create(ctx context.Context, access *entity.EmergencyAccess) error
+	ReadOne(ctx context.Context, id types.ID) (entity.EmergencyAccess, error)
+	ReadByGrantor(ctx context.Context, grantorID types.ID) ([]entity.EmergencyAccess, error)
+	ReadByGrantee(ctx context.Context, granteeID types.ID) ([]entity.EmergencyAccess, error)
+	Exist(ctx context.Context, grantorID, granteeID types.ID) (bool, error)
+	Update(ctx context.Context, access entity.EmergencyAccess) error
+	Delete(ctx context.Context, id types.ID) error
+	ApproveExpired(ctx context.Context, now time.Time) ([]types.ID, error)
+}
+
+type emergencyAccessRepo struct {
+	db database.Querier
+}
+
+func NewEmergencyAccessRepository(db database.Querier) EmergencyAccessRepository {
+	return emergencyAccessRepo{db: database.Join(db)}
+}
+
+const emergencyAccessSelect = `
+	SELECT ea.id, ea.status, ea.wait_days, ea.encrypted_vault_key, ea.requested_at,
+		g.id, g.username, g.email, e.id, e.username, e.email, e.public_key
+	FROM emergency_access ea
+	JOIN accounts g ON g.id = ea.grantor_id
+	JOIN accounts e ON e.id = ea.grantee_id`
+
+func (r emergencyAccessRepo) Create(ctx context.Context, access *entity.EmergencyAccess) error {
+	query := `
+	INSERT INTO emergency_access (grantor_id, grantee_id, status, wait_days)
+	VALUES ($1, $2, $3, $4) RETURNING id`
+
+	err := r.db.QueryRow(
+		ctx, query, access.Grantor.Entity.ID, access.Grantee.Entity.ID, access.Status, access.WaitDays,
+	).Scan(&access.ID)
+	if err != nil {
+		log.ErrorLogger.Error("error at creating emergency access", "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r emergencyAccessRepo) ReadOne(ctx context.Context, id types.ID) (entity.EmergencyAccess, error) {
+	query := emergencyAccessSelect + " WHERE ea.id = $1"
+
+	access, err := scanEmergencyAccess(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		log.ErrorLogger.Error("error at reading emergency access", "error", err.Error(), "id", id)
+		return entity.EmergencyAccess{}, err
+	}
+
+	return access, nil
+}
+
+func (r emergencyAccessRepo) ReadByGrantor(ctx context.Context, grantorID types.ID) ([]entity.EmergencyAccess, error) {
+	return r.read(ctx, emergencyAccessSelect+" WHERE ea.grantor_id = $1 ORDER BY ea.id", grantorID)
+}
+
+func (r emergencyAccessRepo) ReadByGrantee(ctx context.Context, granteeID types.ID) ([]entity.EmergencyAccess, error) {
+	return r.read(ctx, emergencyAccessSelect+" WHERE ea.grantee_id = $1 ORDER BY ea.id", granteeID)
+}
+
+func (r emergencyAccessRepo) read(ctx context.Context, query string, accountID types.ID) ([]entity.EmergencyAccess, error) {
+	rows, err := r.db.Query(ctx, query, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading emergency accesses", "error", err.Error(), "account_id", accountID)
+		return nil, err
+	}
+	defer rows.Close()
+
+	accesses := make([]entity.EmergencyAccess, 0)
+	for rows.Next() {
+		access, err := scanEmergencyAccess(rows)
+		if err != nil {
+			log.ErrorLogger.Error("error at scanning emergency access", "error", err.Error())
+			return nil, err
+		}
+
+		accesses = append(accesses, access)
+	}
+
+	return accesses, rows.Err()
+}
+
+func (r emergencyAccessRepo) Exist(ctx context.Context, grantorID, granteeID types.ID) (bool, error) {
+	query := "SELECT EXISTS(SELECT 1 FROM emergency_access WHERE grantor_id = $1 AND grantee_id = $2)"
+
+	var exist bool
+	if err := r.db.QueryRow(ctx, query, grantorID, granteeID).Scan(&exist); err != nil {
+		log.ErrorLogger.Error("error at checking emergency access existence", "error", err.Error())
+		return false, err
+	}
+
+	return exist, nil
+}
+
+func (r emergencyAccessRepo) Update(ctx context.Context, access entity.EmergencyAccess) error {
+	query := `
+	UPDATE emergency_access
+	SET status = $1, encrypted_vault_key = $2, requested_at = $3, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $4`
+
+	tag, err := r.db.Exec(ctx, query, access.Status, access.EncryptedVaultKey, access.RequestedAt, access.ID)
+	if err != nil {
+		log.ErrorLogger.Error("error at updating emergency access", "error", err.Error(), "id", access.ID)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r emergencyAccessRepo) Delete(ctx context.Context, id types.ID) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM emergency_access WHERE id = $1", id)
+	if err != nil {
+		log.ErrorLogger.Error("error at deleting emergency access", "error", err.Error(), "id", id)
+		return err
+	}
+
+	return nil
+}
+
+// ApproveExpired approves every request whose wait period ran out by now and returns
+// the ids of the approved accesses.
+func (r emergencyAccessRepo) ApproveExpired(ctx context.Context, now time.Time) ([]types.ID, error) {
+	query := `
+	UPDATE emergency_access SET status = $1, updated_at = CURRENT_TIMESTAMP
+	WHERE status = $2 AND requested_at + make_interval(days => wait_days) <= $3
+	RETURNING id`
+
+	rows, err := r.db.Query(ctx, query, entity.EmergencyAccessApproved, entity.EmergencyAccessRequested, now)
+	if err != nil {
+		log.ErrorLogger.Error("error at approving expired emergency accesses", "error", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]types.ID, 0)
+	for rows.Next() {
+		var id types.ID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func scanEmergencyAccess(row pgx.Row) (entity.EmergencyAccess, error) {
+	var access entity.EmergencyAccess
+	err := row.Scan(
+		&access.ID, &access.Status, &access.WaitDays, &access.EncryptedVaultKey, &access.RequestedAt,
+		&access.Grantor.Entity.ID, &access.Grantor.Username, &access.Grantor.Email,
+		&access.Grantee.Entity.ID, &access.Grantee.Username, &access.Grantee.Email, &access.Grantee.PublicKey,
+	)
+
+	return access, err
+}
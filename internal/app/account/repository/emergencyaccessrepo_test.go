@@ -0,0 +1,129 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/seed"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmergencyAccessRepository_Create(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewEmergencyAccessRepository(pgTestSuite.db)
+
+	access := entity.EmergencyAccess{
+		Grantor:  seed.AccountJoba,
+		Grantee:  seed.AccountTyler,
+		Status:   entity.EmergencyAccessInvited,
+		WaitDays: 7,
+	}
+
+	err := repo.Create(ctx, &access)
+	require.NoError(t, err)
+	require.NotZero(t, access.ID)
+
+	saved, err := repo.ReadOne(ctx, access.ID)
+	require.NoError(t, err)
+	require.Equal(t, seed.AccountJoba.Username, saved.Grantor.Username)
+	require.Equal(t, seed.AccountTyler.Username, saved.Grantee.Username)
+	require.Equal(t, entity.EmergencyAccessInvited, saved.Status)
+	require.Equal(t, 7, saved.WaitDays)
+	require.Nil(t, saved.RequestedAt)
+
+	exist, err := repo.Exist(ctx, seed.AccountJoba.Entity.ID, seed.AccountTyler.Entity.ID)
+	require.NoError(t, err)
+	require.True(t, exist)
+
+	exist, err = repo.Exist(ctx, seed.AccountTyler.Entity.ID, seed.AccountJoba.Entity.ID)
+	require.NoError(t, err)
+	require.False(t, exist)
+
+	granted, err := repo.ReadByGrantor(ctx, seed.AccountJoba.Entity.ID)
+	require.NoError(t, err)
+	require.Contains(t, accessIDs(granted), access.ID)
+
+	received, err := repo.ReadByGrantee(ctx, seed.AccountTyler.Entity.ID)
+	require.NoError(t, err)
+	require.Contains(t, accessIDs(received), access.ID)
+
+	duplicate := access
+	err = repo.Create(ctx, &duplicate)
+	require.Error(t, err)
+}
+
+func TestEmergencyAccessRepository_Update(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewEmergencyAccessRepository(pgTestSuite.db)
+
+	access := entity.EmergencyAccess{
+		Grantor:  seed.AccountTyler,
+		Grantee:  seed.AccountJayRock,
+		Status:   entity.EmergencyAccessInvited,
+		WaitDays: 1,
+	}
+	require.NoError(t, repo.Create(ctx, &access))
+
+	// the wait period of this request ran out a day ago
+	requestedAt := time.Now().AddDate(0, 0, -2)
+	access.Status = entity.EmergencyAccessRequested
+	access.EncryptedVaultKey = []byte("wrapped vault key")
+	access.RequestedAt = &requestedAt
+
+	err := repo.Update(ctx, access)
+	require.NoError(t, err)
+
+	saved, err := repo.ReadOne(ctx, access.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.EmergencyAccessRequested, saved.Status)
+	require.Equal(t, access.EncryptedVaultKey, saved.EncryptedVaultKey)
+	require.NotNil(t, saved.RequestedAt)
+
+	approved, err := repo.ApproveExpired(ctx, time.Now())
+	require.NoError(t, err)
+	require.Contains(t, approved, access.ID)
+
+	saved, err = repo.ReadOne(ctx, access.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.EmergencyAccessApproved, saved.Status)
+
+	access.ID = types.ID(0)
+	err = repo.Update(ctx, access)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestEmergencyAccessRepository_Delete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewEmergencyAccessRepository(pgTestSuite.db)
+
+	access := entity.EmergencyAccess{
+		Grantor:  seed.AccountJayRock,
+		Grantee:  seed.AccountJoba,
+		Status:   entity.EmergencyAccessInvited,
+		WaitDays: 3,
+	}
+	require.NoError(t, repo.Create(ctx, &access))
+
+	err := repo.Delete(ctx, access.ID)
+	require.NoError(t, err)
+
+	_, err = repo.ReadOne(ctx, access.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func accessIDs(accesses []entity.EmergencyAccess) []types.ID {
+	ids := make([]types.ID, 0, len(accesses))
+	for _, access := range accesses {
+		ids = append(ids, access.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/redis/go-redis/v9"
+)
+
+type EnrollmentRepository interface {
+	Create(ctx context.Context, enrollment entity.AuthenticatorEnrollment) error
+	Get(ctx context.Context, id types.CacheID) (entity.AuthenticatorEnrollment, error)
+	Exist(ctx context.Context, id types.CacheID) (bool, error)
+	Delete(ctx context.Context, id types.CacheID) error
+}
+
+type enrollmentRepo struct {
+	client *redis.Client
+}
+
+func NewEnrollmentRepository(client *redis.Client) EnrollmentRepository {
+	return enrollmentRepo{client: client}
+}
+
+func (r enrollmentRepo) Create(ctx context.Context, enrollment entity.AuthenticatorEnrollment) error {
+	marshaledEnrollment, err := json.Marshal(enrollment)
+	if err != nil {
+		log.ErrorLogger.Error("error marshaling authenticator enrollment", "error", err.Error())
+		return err
+	}
+
+	err = r.client.Set(ctx, enrollmentKey(enrollment.ID), marshaledEnrollment, enrollment.Duration).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error saving authenticator enrollment", "error", err.Error(), "account_id", enrollment.AccountID)
+		return err
+	}
+
+	return nil
+}
+
+func (r enrollmentRepo) Get(ctx context.Context, id types.CacheID) (entity.AuthenticatorEnrollment, error) {
+	result, err := r.client.Get(ctx, enrollmentKey(id)).Bytes()
+	if err != nil {
+		log.ErrorLogger.Error("error getting authenticator enrollment", "error", err.Error(), "id", id)
+		return entity.AuthenticatorEnrollment{}, err
+	}
+
+	enrollment := new(entity.AuthenticatorEnrollment)
+	if err := json.Unmarshal(result, enrollment); err != nil {
+		log.ErrorLogger.Error("error at unmarshaling authenticator enrollment", "error", err.Error())
+		return entity.AuthenticatorEnrollment{}, err
+	}
+
+	return *enrollment, nil
+}
+
+func (r enrollmentRepo) Exist(ctx context.Context, id types.CacheID) (bool, error) {
+	count, err := r.client.Exists(ctx, enrollmentKey(id)).Result()
+	if err != nil {
+		log.ErrorLogger.Error("error checking authenticator enrollment existence", "error", err.Error(), "id", id)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r enrollmentRepo) Delete(ctx context.Context, id types.CacheID) error {
+	err := r.client.Del(ctx, enrollmentKey(id)).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error deleting authenticator enrollment", "error", err.Error(), "id", id)
+		return err
+	}
+
+	return nil
+}
+
+func enrollmentKey(id types.CacheID) string {
+	return fmt.Sprintf("authenticator-enrollment:%s", id)
+}
@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/jackc/pgx/v5"
+)
+
+type GroupInvitationRepository interface {
+	Create(ctx context.Context, invitation *entity.GroupInvitation) error
+	ReadOne(ctx context.Context, id types.ID) (entity.GroupInvitation, error)
+	ReadByInvitee(ctx context.Context, inviteeID types.ID, now time.Time) ([]entity.GroupInvitation, error)
+	ReadByGroup(ctx context.Context, groupID types.ID, now time.Time) ([]entity.GroupInvitation, error)
+	Accept(ctx context.Context, id types.ID, now time.Time) error
+	Delete(ctx context.Context, id types.ID) error
+	AssignEmail(ctx context.Context, email string, accountID types.ID, now time.Time) error
+}
+
+type groupInvitationRepo struct {
+	db database.Querier
+}
+
+func NewGroupInvitationRepository(db database.Querier) GroupInvitationRepository {
+	return groupInvitationRepo{db: database.Join(db)}
+}
+
+const groupInvitationSelect = `
+	SELECT gi.id, gi.email, gi.role, gi.expires_at, g.id, g.name,
+		i.id, i.username, i.email, COALESCE(e.id, 0), COALESCE(e.username, ''), COALESCE(e.email, '')
+	FROM group_invitations gi
+	JOIN groups g ON g.id = gi.group_id
+	JOIN accounts i ON i.id = gi.inviter_id
+	LEFT JOIN accounts e ON e.id = gi.invitee_id`
+
+// Create invites the account, or the email when the invitee has no account. Inviting
+// the same person to the same group again renews the invitation.
+func (r groupInvitationRepo) Create(ctx context.Context, invitation *entity.GroupInvitation) error {
+	var (
+		inviteeID *types.ID
+		email     *string
+		target    = "(group_id, email)"
+	)
+	if invitation.Invitee.Entity.ID.Valid() {
+		inviteeID, target = &invitation.Invitee.Entity.ID, "(group_id, invitee_id)"
+	} else {
+		email = &invitation.Email
+	}
+
+	query := `
+	INSERT INTO group_invitations (group_id, inviter_id, invitee_id, email, role, expires_at)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT ` + target + ` DO UPDATE
+	SET inviter_id = EXCLUDED.inviter_id, role = EXCLUDED.role, expires_at = EXCLUDED.expires_at,
+		updated_at = CURRENT_TIMESTAMP
+	RETURNING id`
+
+	err := r.db.QueryRow(
+		ctx, query, invitation.Group.ID, invitation.Inviter.Entity.ID, inviteeID, email, invitation.Role,
+		invitation.ExpiresAt,
+	).Scan(&invitation.ID)
+	if err != nil {
+		log.ErrorLogger.Error("error at creating group invitation", "error", err.Error(), "group_id", invitation.Group.ID)
+		return err
+	}
+
+	return nil
+}
+
+func (r groupInvitationRepo) ReadOne(ctx context.Context, id types.ID) (entity.GroupInvitation, error) {
+	invitation, err := scanGroupInvitation(r.db.QueryRow(ctx, groupInvitationSelect+" WHERE gi.id = $1", id))
+	if err != nil {
+		log.ErrorLogger.Error("error at reading group invitation", "error", err.Error(), "id", id)
+		return entity.GroupInvitation{}, err
+	}
+
+	return invitation, nil
+}
+
+// ReadByInvitee returns the invitations of the account that have not expired by now.
+func (r groupInvitationRepo) ReadByInvitee(ctx context.Context, inviteeID types.ID, now time.Time) ([]entity.GroupInvitation, error) {
+	query := groupInvitationSelect + " WHERE gi.invitee_id = $1 AND gi.expires_at > $2 ORDER BY gi.id"
+	return r.read(ctx, query, inviteeID, now)
+}
+
+// ReadByGroup returns the invitations to the group that have not expired by now.
+func (r groupInvitationRepo) ReadByGroup(ctx context.Context, groupID types.ID, now time.Time) ([]entity.GroupInvitation, error) {
+	query := groupInvitationSelect + " WHERE gi.group_id = $1 AND gi.expires_at > $2 ORDER BY gi.id"
+	return r.read(ctx, query, groupID, now)
+}
+
+func (r groupInvitationRepo) read(ctx context.Context, query string, id types.ID, now time.Time) ([]entity.GroupInvitation, error) {
+	rows, err := r.db.Query(ctx, query, id, now)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading group invitations", "error", err.Error(), "id", id)
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := make([]entity.GroupInvitation, 0)
+	for rows.Next() {
+		invitation, err := scanGroupInvitation(rows)
+		if err != nil {
+			log.ErrorLogger.Error("error at scanning group invitation", "error", err.Error())
+			return nil, err
+		}
+
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+// Accept makes the invitee a member of the group with the role of the invitation and
+// removes the invitation in the same transaction, joining is recorded as a member event
+// of the invitee. An expired invitation is left as is.
+func (r groupInvitationRepo) Accept(ctx context.Context, id types.ID, now time.Time) error {
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		query := `
+		WITH joined AS (
+			INSERT INTO groups_accounts (group_id, account_id, role)
+			SELECT group_id, invitee_id, role FROM group_invitations
+			WHERE id = $1 AND invitee_id IS NOT NULL AND expires_at > $2
+			ON CONFLICT (account_id, group_id) DO NOTHING
+			RETURNING group_id, account_id, role
+		)
+		INSERT INTO group_member_events (group_id, actor_id, account_id, role)
+		SELECT group_id, account_id, account_id, role FROM joined`
+
+		if _, err := tx.Exec(ctx, query, id, now); err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, "DELETE FROM group_invitations WHERE id = $1 AND expires_at > $2", id, now)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+
+		return nil
+	})
+	if err != nil && err != pgx.ErrNoRows {
+		log.ErrorLogger.Error("error at accepting group invitation", "error", err.Error(), "id", id)
+	}
+
+	return err
+}
+
+func (r groupInvitationRepo) Delete(ctx context.Context, id types.ID) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM group_invitations WHERE id = $1", id)
+	if err != nil {
+		log.ErrorLogger.Error("error at deleting group invitation", "error", err.Error(), "id", id)
+		return err
+	}
+
+	return nil
+}
+
+// AssignEmail hands the invitations sent to the email that have not expired by now to
+// the account that signed up with it.
+func (r groupInvitationRepo) AssignEmail(ctx context.Context, email string, accountID types.ID, now time.Time) error {
+	query := `
+	UPDATE group_invitations SET invitee_id = $1, email = NULL, updated_at = CURRENT_TIMESTAMP
+	WHERE lower(email) = lower($2) AND expires_at > $3`
+
+	_, err := r.db.Exec(ctx, query, accountID, email, now)
+	if err != nil {
+		log.ErrorLogger.Error("error at assigning group invitations", "error", err.Error(), "account_id", accountID)
+		return err
+	}
+
+	return nil
+}
+
+func scanGroupInvitation(row pgx.Row) (entity.GroupInvitation, error) {
+	var (
+		invitation entity.GroupInvitation
+		email      *string
+	)
+	err := row.Scan(
+		&invitation.ID, &email, &invitation.Role, &invitation.ExpiresAt, &invitation.Group.ID, &invitation.Group.Name,
+		&invitation.Inviter.Entity.ID, &invitation.Inviter.Username, &invitation.Inviter.Email,
+		&invitation.Invitee.Entity.ID, &invitation.Invitee.Username, &invitation.Invitee.Email,
+	)
+	if email != nil {
+		invitation.Email = *email
+	}
+
+	return invitation, err
+}
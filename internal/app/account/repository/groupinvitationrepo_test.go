@@ -0,0 +1,152 @@
+package repository_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupInvitationRepository_Create(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewGroupInvitationRepository(pgTestSuite.db)
+	group, owner := createInvitationGroup(t, "invitation_create_owner")
+	invitee := createAccount(t, "invitation_create_invitee")
+
+	invitation := entity.GroupInvitation{
+		Group:     group,
+		Inviter:   owner,
+		Invitee:   invitee,
+		Role:      entity.GroupRoleEditor,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, repo.Create(ctx, &invitation))
+	require.True(t, invitation.ID.Valid())
+
+	saved, err := repo.ReadOne(ctx, invitation.ID)
+	require.NoError(t, err)
+	require.Equal(t, group.ID, saved.Group.ID)
+	require.Equal(t, group.Name, saved.Group.Name)
+	require.Equal(t, owner.Username, saved.Inviter.Username)
+	require.Equal(t, invitee.Entity.ID, saved.Invitee.Entity.ID)
+	require.Empty(t, saved.Email)
+	require.Equal(t, entity.GroupRoleEditor, saved.Role)
+
+	// inviting the account again renews the invitation
+	again := invitation
+	again.Role = entity.GroupRoleViewer
+	require.NoError(t, repo.Create(ctx, &again))
+	require.Equal(t, invitation.ID, again.ID)
+
+	received, err := repo.ReadByInvitee(ctx, invitee.Entity.ID, time.Now())
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	require.Equal(t, entity.GroupRoleViewer, received[0].Role)
+
+	byEmail := entity.GroupInvitation{
+		Group:     group,
+		Inviter:   owner,
+		Email:     "invitation_create_stranger@example.com",
+		Role:      entity.GroupRoleViewer,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, repo.Create(ctx, &byEmail))
+
+	pending, err := repo.ReadByGroup(ctx, group.ID, time.Now())
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, byEmail.Email, pending[1].Email)
+	require.False(t, pending[1].Invitee.Entity.ID.Valid())
+
+	// expired invitations are not pending anymore
+	pending, err = repo.ReadByGroup(ctx, group.ID, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestGroupInvitationRepository_Accept(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewGroupInvitationRepository(pgTestSuite.db)
+	groupRepo := repository.NewGroupRepository(pgTestSuite.db)
+	group, owner := createInvitationGroup(t, "invitation_accept_owner")
+	invitee := createAccount(t, "invitation_accept_invitee")
+
+	invitation := entity.GroupInvitation{
+		Group:     group,
+		Inviter:   owner,
+		Invitee:   invitee,
+		Role:      entity.GroupRoleAdmin,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, repo.Create(ctx, &invitation))
+
+	err := repo.Accept(ctx, invitation.ID, time.Now().Add(2*time.Hour))
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	joined, err := groupRepo.ReadOne(ctx, group.ID, invitee.Entity.ID)
+	require.NoError(t, err)
+	require.False(t, joined.ID.Valid())
+
+	require.NoError(t, repo.Accept(ctx, invitation.ID, time.Now()))
+
+	joined, err = groupRepo.ReadOne(ctx, group.ID, invitee.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.GroupRoleAdmin, joined.Role(invitee.Entity.ID))
+
+	_, err = repo.ReadOne(ctx, invitation.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	err = repo.Accept(ctx, invitation.ID, time.Now())
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestGroupInvitationRepository_AssignEmail(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewGroupInvitationRepository(pgTestSuite.db)
+	group, owner := createInvitationGroup(t, "invitation_assign_owner")
+
+	invitation := entity.GroupInvitation{
+		Group:     group,
+		Inviter:   owner,
+		Email:     "Invitation_Assign_Invitee@example.com",
+		Role:      entity.GroupRoleViewer,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	require.NoError(t, repo.Create(ctx, &invitation))
+
+	invitee := createAccount(t, "invitation_assign_invitee")
+	require.NoError(t, repo.AssignEmail(ctx, strings.ToLower(invitation.Email), invitee.Entity.ID, time.Now()))
+
+	received, err := repo.ReadByInvitee(ctx, invitee.Entity.ID, time.Now())
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	require.Equal(t, invitation.ID, received[0].ID)
+	require.Empty(t, received[0].Email)
+
+	require.NoError(t, repo.Delete(ctx, invitation.ID))
+
+	received, err = repo.ReadByInvitee(ctx, invitee.Entity.ID, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, received)
+}
+
+// createInvitationGroup creates a group owned by a new account with the username.
+func createInvitationGroup(t *testing.T, username string) (entity.Group, entity.Account) {
+	ctx := context.Background()
+	groupRepo := repository.NewGroupRepository(pgTestSuite.db)
+	owner := createAccount(t, username)
+
+	group := entity.Group{Name: username + " group", Owner: owner}
+	require.NoError(t, groupRepo.Create(ctx, &group))
+	require.NoError(t, groupRepo.AddAccounts(ctx, group.ID, []entity.Account{owner}, entity.GroupRoleOwner))
+
+	return group, owner
+}
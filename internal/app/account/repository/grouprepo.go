@@ -1,34 +1,63 @@
 package repository
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/param"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/helper"
 	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	deleteGroupQuery = "DELETE FROM groups WHERE id = $1 AND owner_id = $2"
+
+	// updateGroupOwnerQuery only hands the group over to one of its members, the old
+	// owner stays on as an admin and a pending ownership offer is dropped.
+	updateGroupOwnerQuery = `
+	WITH handed_over AS (
+		UPDATE groups SET owner_id = $1, pending_owner_id = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND owner_id = $3 AND EXISTS(
+			SELECT 1 FROM groups_accounts WHERE group_id = $2 AND account_id = $1
+		)
+		RETURNING id
+	)
+	UPDATE groups_accounts ga SET role = CASE WHEN ga.account_id = $1 THEN 'owner' ELSE 'admin' END
+	FROM handed_over h
+	WHERE ga.group_id = h.id AND ga.account_id IN ($1, $3)`
 )
 
 type GroupRepository interface {
 	Create(ctx context.Context, group *entity.Group) error
 	Read(ctx context.Context, param param.ReadGroupParams) ([]entity.Group, int, error)
 	ReadOne(ctx context.Context, id, memberID types.ID) (entity.Group, error)
+	ReadByOwner(ctx context.Context, ownerID types.ID) ([]entity.Group, error)
 	Update(ctx context.Context, group entity.Group) error
 	Delete(ctx context.Context, groupID, ownerID types.ID) error
-	AddAccounts(ctx context.Context, groupID types.ID, accounts []entity.Account) error
-	DeleteAllMembers(ctx context.Context, groupID, ownerID types.ID) error
+	UpdateOwner(ctx context.Context, groupID, ownerID, newOwnerID types.ID) error
+	AddAccounts(ctx context.Context, groupID types.ID, accounts []entity.Account, role entity.GroupRole) error
+	ChangeMembers(ctx context.Context, groupID, actorID types.ID, changes []entity.GroupMemberChange) error
+	ReadMemberEvents(ctx context.Context, groupID types.ID, limit int) ([]entity.GroupMemberEvent, error)
+	DeleteMember(ctx context.Context, groupID, accountID types.ID) error
+	OfferOwnership(ctx context.Context, groupID, ownerID, newOwnerID types.ID) error
+	ReadOwnershipOffers(ctx context.Context, accountID types.ID) ([]entity.Group, error)
+	AcceptOwnership(ctx context.Context, groupID, newOwnerID types.ID) error
+	DeleteOwnershipOffer(ctx context.Context, groupID, accountID types.ID) error
 }
 
 type groupRepo struct {
-	db *pgxpool.Pool
+	db database.Querier
 }
 
-func NewGroupRepository(db *pgxpool.Pool) GroupRepository {
-	return groupRepo{db: db}
+func NewGroupRepository(db database.Querier) GroupRepository {
+	return groupRepo{db: database.Join(db)}
 }
 
 func (repo groupRepo) Create(ctx context.Context, group *entity.Group) error {
@@ -67,7 +96,7 @@ func (repo groupRepo) Read(ctx context.Context, param param.ReadGroupParams) ([]
 		o.id AS owner_id, o.username AS owner_username, o.first_name AS owner_first_name, 
 		o.last_name AS owner_last_name, o.email AS owner_email,
 		m.id as member_id, m.username AS member_username, m.first_name AS member_first_name,
-		m.last_name AS member_last_name, m.email AS member_email
+		m.last_name AS member_last_name, m.email AS member_email, ga.role
 	FROM paged_groups pg
 	JOIN accounts o ON o.id = pg.owner_id
 	JOIN groups_accounts ga ON ga.group_id = pg.id
@@ -92,12 +121,13 @@ func (repo groupRepo) Read(ctx context.Context, param param.ReadGroupParams) ([]
 			g       entity.Group
 			owner   entity.Account
 			member  entity.Account
+			role    entity.GroupRole
 		)
 
 		err := rows.Scan(
 			&count, &groupID, &g.Name, &g.Description,
 			&owner.Entity.ID, &owner.Username, &owner.FirstName, &owner.LastName, &owner.Email,
-			&member.Entity.ID, &member.Username, &member.FirstName, &member.LastName, &member.Email,
+			&member.Entity.ID, &member.Username, &member.FirstName, &member.LastName, &member.Email, &role,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -108,11 +138,13 @@ func (repo groupRepo) Read(ctx context.Context, param param.ReadGroupParams) ([]
 			g.Entity.ID = groupID
 			g.Owner = owner
 			g.Members = []entity.Account{member}
+			g.Roles = map[types.ID]entity.GroupRole{member.Entity.ID: role}
 
 			groupMap[groupID] = &g
 			groupOrder = append(groupOrder, groupID)
 		} else {
 			existing.Members = append(existing.Members, member)
+			existing.Roles[member.Entity.ID] = role
 		}
 	}
 
@@ -126,9 +158,9 @@ func (repo groupRepo) Read(ctx context.Context, param param.ReadGroupParams) ([]
 
 func (repo groupRepo) ReadOne(ctx context.Context, id, memberID types.ID) (entity.Group, error) {
 	query := `
-	SELECT g.id, g.name, g.description,
+	SELECT g.id, g.name, g.description, COALESCE(g.pending_owner_id, 0),
 				o.id, o.username, o.first_name, o.last_name, o.email,
-				m.id, m.username, m.first_name, m.last_name, m.email
+				m.id, m.username, m.first_name, m.last_name, m.email, ga.role
 		FROM groups g
 		JOIN accounts o ON o.id = g.owner_id
 		JOIN groups_accounts ga ON ga.group_id = g.id
@@ -136,7 +168,7 @@ func (repo groupRepo) ReadOne(ctx context.Context, id, memberID types.ID) (entit
 	WHERE g.id = $1 AND g.id IN (
 		SELECT group_id FROM groups_accounts WHERE account_id = $2
 	)
-	ORDER BY g.id
+	ORDER BY g.id, m.id
 	`
 
 	rows, err := repo.db.Query(ctx, query, id, memberID)
@@ -146,22 +178,78 @@ func (repo groupRepo) ReadOne(ctx context.Context, id, memberID types.ID) (entit
 
 	var g entity.Group
 	for rows.Next() {
-		var member entity.Account
+		var (
+			member entity.Account
+			role   entity.GroupRole
+		)
 		err := rows.Scan(
-			&g.Entity.ID, &g.Name, &g.Description,
+			&g.Entity.ID, &g.Name, &g.Description, &g.PendingOwnerID,
 			&g.Owner.Entity.ID, &g.Owner.Username, &g.Owner.FirstName, &g.Owner.LastName, &g.Owner.Email,
-			&member.Entity.ID, &member.Username, &member.FirstName, &member.LastName, &member.Email,
+			&member.Entity.ID, &member.Username, &member.FirstName, &member.LastName, &member.Email, &role,
 		)
 		if err != nil {
 			return entity.Group{}, err
 		}
 
+		if g.Roles == nil {
+			g.Roles = make(map[types.ID]entity.GroupRole)
+		}
 		g.Members = append(g.Members, member)
+		g.Roles[member.Entity.ID] = role
 	}
 
 	return g, nil
 }
 
+// ReadByOwner returns every group the account owns along with its members.
+func (repo groupRepo) ReadByOwner(ctx context.Context, ownerID types.ID) ([]entity.Group, error) {
+	query := `
+	SELECT g.id, g.name, g.description,
+				m.id, m.username, m.first_name, m.last_name, m.email, ga.role
+		FROM groups g
+		JOIN groups_accounts ga ON ga.group_id = g.id
+		JOIN accounts m ON m.id = ga.account_id
+	WHERE g.owner_id = $1
+	ORDER BY g.id, m.id
+	`
+
+	rows, err := repo.db.Query(ctx, query, ownerID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading groups by owner", "error", err.Error(), "owner_id", ownerID)
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make([]entity.Group, 0)
+	for rows.Next() {
+		var (
+			g      entity.Group
+			member entity.Account
+			role   entity.GroupRole
+		)
+
+		err := rows.Scan(
+			&g.Entity.ID, &g.Name, &g.Description,
+			&member.Entity.ID, &member.Username, &member.FirstName, &member.LastName, &member.Email, &role,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(groups) == 0 || groups[len(groups)-1].ID != g.ID {
+			g.Owner.Entity.ID = ownerID
+			g.Roles = make(map[types.ID]entity.GroupRole)
+			groups = append(groups, g)
+		}
+
+		last := &groups[len(groups)-1]
+		last.Members = append(last.Members, member)
+		last.Roles[member.Entity.ID] = role
+	}
+
+	return groups, rows.Err()
+}
+
 func (repo groupRepo) Update(ctx context.Context, group entity.Group) error {
 	query := "UPDATE groups SET name = $1, description = $2 WHERE id = $3 AND owner_id = $4"
 
@@ -175,9 +263,7 @@ func (repo groupRepo) Update(ctx context.Context, group entity.Group) error {
 }
 
 func (repo groupRepo) Delete(ctx context.Context, groupID, ownerID types.ID) error {
-	query := "DELETE FROM groups WHERE id = $1 AND owner_id = $2"
-
-	_, err := repo.db.Exec(ctx, query, groupID, ownerID)
+	_, err := repo.db.Exec(ctx, deleteGroupQuery, groupID, ownerID)
 	if err != nil {
 		log.ErrorLogger.Error("error at deleting group", "error", err.Error())
 		return err
@@ -186,7 +272,23 @@ func (repo groupRepo) Delete(ctx context.Context, groupID, ownerID types.ID) err
 	return nil
 }
 
-func (repo groupRepo) AddAccounts(ctx context.Context, groupID types.ID, accounts []entity.Account) error {
+// UpdateOwner hands the group over to one of its members.
+func (repo groupRepo) UpdateOwner(ctx context.Context, groupID, ownerID, newOwnerID types.ID) error {
+	tag, err := repo.db.Exec(ctx, updateGroupOwnerQuery, newOwnerID, groupID, ownerID)
+	if err != nil {
+		log.ErrorLogger.Error("error at updating group owner", "error", err.Error(), "group_id", groupID)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// AddAccounts makes the accounts members of the group with the given role.
+func (repo groupRepo) AddAccounts(ctx context.Context, groupID types.ID, accounts []entity.Account, role entity.GroupRole) error {
 	if len(accounts) == 0 {
 		return nil
 	}
@@ -196,17 +298,17 @@ func (repo groupRepo) AddAccounts(ctx context.Context, groupID types.ID, account
 		args   []any
 	)
 
-	args = append(args, groupID)
+	args = append(args, groupID, role)
 
 	for i, account := range accounts {
-		placeholder := fmt.Sprintf("($1, $%d)", i+2)
+		placeholder := fmt.Sprintf("($1, $%d, $2)", i+3)
 		values = append(values, placeholder)
 
 		args = append(args, account.Entity.ID)
 	}
 
 	query := fmt.Sprintf(
-		"INSERT INTO groups_accounts (group_id, account_id) VALUES %s",
+		"INSERT INTO groups_accounts (group_id, account_id, role) VALUES %s",
 		strings.Join(values, ","),
 	)
 
@@ -219,13 +321,293 @@ func (repo groupRepo) AddAccounts(ctx context.Context, groupID types.ID, account
 	return nil
 }
 
-func (repo groupRepo) DeleteAllMembers(ctx context.Context, groupID, ownerID types.ID) error {
+// ChangeMembers applies the changes to the members of the group in one transaction and
+// records an event for each of them on behalf of the actor. A change only applies to a
+// member that still has its previous role, otherwise nothing is changed and it returns
+// pgx.ErrNoRows. Removed members stop sharing their items with the group, see
+// DeleteMember.
+func (repo groupRepo) ChangeMembers(ctx context.Context, groupID, actorID types.ID, changes []entity.GroupMemberChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	// members are locked in the same order by every edit so concurrent ones can not
+	// deadlock
+	changes = slices.Clone(changes)
+	slices.SortFunc(changes, func(a, b entity.GroupMemberChange) int {
+		return cmp.Compare(a.AccountID, b.AccountID)
+	})
+
+	err := pgx.BeginFunc(ctx, repo.db, func(tx pgx.Tx) error {
+		for _, change := range changes {
+			if err := changeGroupMember(ctx, tx, groupID, change); err != nil {
+				return err
+			}
+
+			if err := createGroupMemberEvent(ctx, tx, groupID, actorID, change); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil && err != pgx.ErrNoRows {
+		log.ErrorLogger.Error("error at changing group members", "error", err.Error(), "group_id", groupID)
+	}
+
+	return err
+}
+
+// ReadMemberEvents returns the latest changes to the members of the group, newest first.
+func (repo groupRepo) ReadMemberEvents(ctx context.Context, groupID types.ID, limit int) ([]entity.GroupMemberEvent, error) {
 	query := `
-	DELETE FROM groups_accounts ga
-	USING groups g
-	WHERE g.id = ga.group_id AND g.id = $1 AND g.owner_id = $2
+	SELECT e.id, e.created_at, COALESCE(e.previous_role, ''), COALESCE(e.role, ''),
+		COALESCE(a.id, 0), COALESCE(a.username, ''), COALESCE(m.id, 0), COALESCE(m.username, '')
+	FROM group_member_events e
+	LEFT JOIN accounts a ON a.id = e.actor_id
+	LEFT JOIN accounts m ON m.id = e.account_id
+	WHERE e.group_id = $1
+	ORDER BY e.id DESC
+	LIMIT $2
 	`
 
-	_, err := repo.db.Exec(ctx, query, groupID, ownerID)
+	rows, err := repo.db.Query(ctx, query, groupID, limit)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading group member events", "error", err.Error(), "group_id", groupID)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]entity.GroupMemberEvent, 0)
+	for rows.Next() {
+		event := entity.GroupMemberEvent{GroupID: groupID}
+		err := rows.Scan(
+			&event.ID, &event.CreatedAt, &event.PreviousRole, &event.Role,
+			&event.Actor.Entity.ID, &event.Actor.Username, &event.Member.Entity.ID, &event.Member.Username,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		event.AccountID = event.Member.Entity.ID
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// DeleteMember removes the account from the group unless it is the owner, the items it
+// created stop being shared with the group and an ownership offer to it is dropped. The
+// items the other members shared with the group stay.
+func (repo groupRepo) DeleteMember(ctx context.Context, groupID, accountID types.ID) error {
+	err := pgx.BeginFunc(ctx, repo.db, func(tx pgx.Tx) error {
+		role, err := deleteGroupMember(ctx, tx, groupID, accountID)
+		if err != nil {
+			return err
+		}
+
+		change := entity.GroupMemberChange{AccountID: accountID, PreviousRole: role}
+		return createGroupMemberEvent(ctx, tx, groupID, accountID, change)
+	})
+	if err != nil && err != pgx.ErrNoRows {
+		log.ErrorLogger.Error("error at deleting group member", "error", err.Error(), "group_id", groupID)
+	}
+
+	return err
+}
+
+// OfferOwnership offers the group to one of its other members, an earlier offer is
+// replaced. It returns pgx.ErrNoRows when the group is not owned by ownerID or the new
+// owner is not a member.
+func (repo groupRepo) OfferOwnership(ctx context.Context, groupID, ownerID, newOwnerID types.ID) error {
+	query := `
+	UPDATE groups SET pending_owner_id = $3, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND owner_id = $2 AND owner_id <> $3 AND EXISTS(
+		SELECT 1 FROM groups_accounts WHERE group_id = $1 AND account_id = $3
+	)`
+
+	tag, err := repo.db.Exec(ctx, query, groupID, ownerID, newOwnerID)
+	if err != nil {
+		log.ErrorLogger.Error("error at offering group ownership", "error", err.Error(), "group_id", groupID)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// ReadOwnershipOffers returns the groups offered to the account along with their owner.
+func (repo groupRepo) ReadOwnershipOffers(ctx context.Context, accountID types.ID) ([]entity.Group, error) {
+	query := `
+	SELECT g.id, g.name, g.description, o.id, o.username, o.first_name, o.last_name, o.email
+		FROM groups g
+		JOIN accounts o ON o.id = g.owner_id
+	WHERE g.pending_owner_id = $1
+	ORDER BY g.id
+	`
+
+	rows, err := repo.db.Query(ctx, query, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading ownership offers", "error", err.Error(), "account_id", accountID)
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make([]entity.Group, 0)
+	for rows.Next() {
+		g := entity.Group{PendingOwnerID: accountID}
+		err := rows.Scan(
+			&g.Entity.ID, &g.Name, &g.Description,
+			&g.Owner.Entity.ID, &g.Owner.Username, &g.Owner.FirstName, &g.Owner.LastName, &g.Owner.Email,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, g)
+	}
+
+	return groups, rows.Err()
+}
+
+// AcceptOwnership hands the group over to the member it was offered to. It returns
+// pgx.ErrNoRows when the group was not offered to newOwnerID or it is not a member
+// anymore.
+func (repo groupRepo) AcceptOwnership(ctx context.Context, groupID, newOwnerID types.ID) error {
+	err := pgx.BeginFunc(ctx, repo.db, func(tx pgx.Tx) error {
+		var (
+			ownerID types.ID
+			role    entity.GroupRole
+		)
+		query := `
+		SELECT g.owner_id, ga.role FROM groups g
+		JOIN groups_accounts ga ON ga.group_id = g.id AND ga.account_id = g.pending_owner_id
+		WHERE g.id = $1 AND g.pending_owner_id = $2
+		FOR UPDATE`
+		if err := tx.QueryRow(ctx, query, groupID, newOwnerID).Scan(&ownerID, &role); err != nil {
+			return err
+		}
+
+		tag, err := tx.Exec(ctx, updateGroupOwnerQuery, newOwnerID, groupID, ownerID)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+
+		changes := []entity.GroupMemberChange{
+			{AccountID: newOwnerID, PreviousRole: role, Role: entity.GroupRoleOwner},
+			{AccountID: ownerID, PreviousRole: entity.GroupRoleOwner, Role: entity.GroupRoleAdmin},
+		}
+		for _, change := range changes {
+			if err := createGroupMemberEvent(ctx, tx, groupID, newOwnerID, change); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil && err != pgx.ErrNoRows {
+		log.ErrorLogger.Error("error at accepting group ownership", "error", err.Error(), "group_id", groupID)
+	}
+
+	return err
+}
+
+// DeleteOwnershipOffer drops the ownership offer of the group, the owner takes it back
+// or the member it was offered to declines it. It returns pgx.ErrNoRows when there is
+// no such offer.
+func (repo groupRepo) DeleteOwnershipOffer(ctx context.Context, groupID, accountID types.ID) error {
+	query := `
+	UPDATE groups SET pending_owner_id = NULL, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $1 AND pending_owner_id IS NOT NULL AND (owner_id = $2 OR pending_owner_id = $2)`
+
+	tag, err := repo.db.Exec(ctx, query, groupID, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at deleting ownership offer", "error", err.Error(), "group_id", groupID)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// changeGroupMember applies the change within tx, see ChangeMembers.
+func changeGroupMember(ctx context.Context, tx pgx.Tx, groupID types.ID, change entity.GroupMemberChange) error {
+	var role entity.GroupRole
+	query := "SELECT role FROM groups_accounts WHERE group_id = $1 AND account_id = $2 FOR UPDATE"
+	err := tx.QueryRow(ctx, query, groupID, change.AccountID).Scan(&role)
+	if err != nil && err != pgx.ErrNoRows {
+		return err
+	}
+
+	if role != change.PreviousRole {
+		return pgx.ErrNoRows
+	}
+
+	switch change.Action() {
+	case entity.GroupMemberAdded:
+		query = `
+		INSERT INTO groups_accounts (group_id, account_id, role) VALUES ($1, $2, $3)
+		ON CONFLICT (account_id, group_id) DO NOTHING`
+		tag, err := tx.Exec(ctx, query, groupID, change.AccountID, change.Role)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+	case entity.GroupMemberRemoved:
+		_, err = deleteGroupMember(ctx, tx, groupID, change.AccountID)
+		return err
+	default:
+		query = "UPDATE groups_accounts SET role = $3 WHERE group_id = $1 AND account_id = $2"
+		_, err = tx.Exec(ctx, query, groupID, change.AccountID, change.Role)
+		return err
+	}
+
+	return nil
+}
+
+// deleteGroupMember removes a member other than the owner from the group within tx and
+// returns the role it had, see DeleteMember.
+func deleteGroupMember(ctx context.Context, tx pgx.Tx, groupID, accountID types.ID) (entity.GroupRole, error) {
+	var role entity.GroupRole
+	query := "DELETE FROM groups_accounts WHERE group_id = $1 AND account_id = $2 AND role <> 'owner' RETURNING role"
+	if err := tx.QueryRow(ctx, query, groupID, accountID).Scan(&role); err != nil {
+		return "", err
+	}
+
+	query = `
+	DELETE FROM vault_items_groups vg USING vault_items v
+	WHERE v.id = vg.vault_item_id AND vg.group_id = $1 AND v.creator_id = $2`
+	if _, err := tx.Exec(ctx, query, groupID, accountID); err != nil {
+		return "", err
+	}
+
+	query = "UPDATE groups SET pending_owner_id = NULL WHERE id = $1 AND pending_owner_id = $2"
+	if _, err := tx.Exec(ctx, query, groupID, accountID); err != nil {
+		return "", err
+	}
+
+	return role, nil
+}
+
+// createGroupMemberEvent records the change to the members of the group within tx.
+func createGroupMemberEvent(ctx context.Context, tx pgx.Tx, groupID, actorID types.ID, change entity.GroupMemberChange) error {
+	query := `
+	INSERT INTO group_member_events (group_id, actor_id, account_id, previous_role, role)
+	VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))`
+
+	_, err := tx.Exec(ctx, query, groupID, actorID, change.AccountID, change.PreviousRole, change.Role)
 	return err
 }
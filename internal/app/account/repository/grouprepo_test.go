@@ -2,14 +2,18 @@ package repository_test
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
 	params "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/param"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/seed"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
 )
 
@@ -195,7 +199,9 @@ func TestGroupRepository_ReadOne(t *testing.T) {
 				for _, m := range group.Members {
 					require.NotZero(t, m.Entity.ID)
 					require.NotEmpty(t, m.Username)
+					require.True(t, group.Role(m.Entity.ID).Valid())
 				}
+				require.Equal(t, entity.GroupRoleOwner, group.Role(group.Owner.Entity.ID))
 			}
 		})
 	}
@@ -348,7 +354,7 @@ func TestGroupRepository_AddAccount(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			err := repo.AddAccounts(ctx, tc.groupID, tc.accounts)
+			err := repo.AddAccounts(ctx, tc.groupID, tc.accounts, entity.GroupRoleViewer)
 			if tc.wantErr {
 				require.Error(t, err)
 			} else {
@@ -358,48 +364,347 @@ func TestGroupRepository_AddAccount(t *testing.T) {
 	}
 }
 
-func TestGroupRepository_DeleteAllMembers(t *testing.T) {
+func TestGroupRepository_ChangeMembers(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
 	repo := repository.NewGroupRepository(pgTestSuite.db)
+	owner := createAccount(t, "change_members_owner")
+	member := createAccount(t, "change_members_member")
+	removed := createAccount(t, "change_members_removed")
+	late := createAccount(t, "change_members_late")
+
+	group := entity.Group{Name: "changed group", Owner: owner}
+	require.NoError(t, repo.Create(ctx, &group))
+
+	err := repo.ChangeMembers(ctx, group.ID, owner.Entity.ID, []entity.GroupMemberChange{
+		{AccountID: owner.Entity.ID, Role: entity.GroupRoleOwner},
+		{AccountID: member.Entity.ID, Role: entity.GroupRoleViewer},
+		{AccountID: removed.Entity.ID, Role: entity.GroupRoleViewer},
+	})
+	require.NoError(t, err)
+
+	// nothing is changed when a member does not have its previous role anymore
+	err = repo.ChangeMembers(ctx, group.ID, owner.Entity.ID, []entity.GroupMemberChange{
+		{AccountID: late.Entity.ID, Role: entity.GroupRoleViewer},
+		{AccountID: member.Entity.ID, PreviousRole: entity.GroupRoleEditor, Role: entity.GroupRoleAdmin},
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	err = repo.ChangeMembers(ctx, group.ID, owner.Entity.ID, []entity.GroupMemberChange{
+		{AccountID: member.Entity.ID, Role: entity.GroupRoleViewer},
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	err = repo.ChangeMembers(ctx, group.ID, owner.Entity.ID, []entity.GroupMemberChange{
+		{AccountID: member.Entity.ID, PreviousRole: entity.GroupRoleViewer, Role: entity.GroupRoleEditor},
+		{AccountID: removed.Entity.ID, PreviousRole: entity.GroupRoleViewer},
+	})
+	require.NoError(t, err)
+
+	changed, err := repo.ReadOne(ctx, group.ID, owner.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, changed.Members, 2)
+	require.Equal(t, entity.GroupRoleEditor, changed.Role(member.Entity.ID))
+	require.Empty(t, changed.Role(removed.Entity.ID))
+	require.Empty(t, changed.Role(late.Entity.ID))
+
+	events, err := repo.ReadMemberEvents(ctx, group.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 5)
+	require.Equal(t, removed.Username, events[0].Member.Username)
+	require.Equal(t, entity.GroupMemberRemoved, events[0].Action())
+	require.Equal(t, entity.GroupRoleViewer, events[0].PreviousRole)
+	require.Equal(t, member.Username, events[1].Member.Username)
+	require.Equal(t, entity.GroupMemberRoleChanged, events[1].Action())
+	require.Equal(t, entity.GroupRoleEditor, events[1].Role)
+	require.Equal(t, owner.Username, events[4].Member.Username)
+	require.Equal(t, entity.GroupMemberAdded, events[4].Action())
+	for _, event := range events {
+		require.Equal(t, owner.Username, event.Actor.Username)
+	}
 
-	testcases := []struct {
-		name        string
-		groupID     types.ID
-		ownerID     types.ID
-		wouldDelete bool
+	events, err = repo.ReadMemberEvents(ctx, group.ID, 2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+}
+
+func TestGroupRepository_ChangeMembersConcurrently(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewGroupRepository(pgTestSuite.db)
+	owner := createAccount(t, "concurrent_members_owner")
+	admin := createAccount(t, "concurrent_members_admin")
+	removed := createAccount(t, "concurrent_members_removed")
+	promoted := createAccount(t, "concurrent_members_promoted")
+	contested := createAccount(t, "concurrent_members_contested")
+
+	group := entity.Group{Name: "concurrent group", Owner: owner}
+	require.NoError(t, repo.Create(ctx, &group))
+
+	changes := []entity.GroupMemberChange{
+		{AccountID: owner.Entity.ID, Role: entity.GroupRoleOwner},
+		{AccountID: admin.Entity.ID, Role: entity.GroupRoleAdmin},
+	}
+	for _, acc := range []entity.Account{removed, promoted, contested} {
+		changes = append(changes, entity.GroupMemberChange{AccountID: acc.Entity.ID, Role: entity.GroupRoleViewer})
+	}
+	require.NoError(t, repo.ChangeMembers(ctx, group.ID, owner.Entity.ID, changes))
+
+	// the owner and the admin edit the group at the same time, both from the members
+	// they read before the other one saved
+	edits := []struct {
+		actorID types.ID
+		change  entity.GroupMemberChange
 	}{
-		{
-			name:        "successful",
-			groupID:     seed.GroupBlackHippy.Entity.ID,
-			ownerID:     seed.GroupBlackHippy.Owner.Entity.ID,
-			wouldDelete: true,
-		},
-		{
-			name:        "different owner",
-			groupID:     seed.GroupBrockhampton.Entity.ID,
-			ownerID:     seed.GroupBlackHippy.Owner.Entity.ID,
-			wouldDelete: false,
-		},
+		{owner.Entity.ID, entity.GroupMemberChange{AccountID: removed.Entity.ID, PreviousRole: entity.GroupRoleViewer}},
+		{admin.Entity.ID, entity.GroupMemberChange{
+			AccountID: promoted.Entity.ID, PreviousRole: entity.GroupRoleViewer, Role: entity.GroupRoleEditor,
+		}},
+		{owner.Entity.ID, entity.GroupMemberChange{
+			AccountID: contested.Entity.ID, PreviousRole: entity.GroupRoleViewer, Role: entity.GroupRoleAdmin,
+		}},
+		{admin.Entity.ID, entity.GroupMemberChange{
+			AccountID: contested.Entity.ID, PreviousRole: entity.GroupRoleViewer, Role: entity.GroupRoleEditor,
+		}},
 	}
 
-	for _, tc := range testcases {
+	errs := make([]error, len(edits))
+	var wg sync.WaitGroup
+	for i, edit := range edits {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = repo.ChangeMembers(ctx, group.ID, edit.actorID, []entity.GroupMemberChange{edit.change})
+		}()
+	}
+	wg.Wait()
+
+	// the edits of different members are all kept, only one edit of the same member wins
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.True(t, (errs[2] == nil) != (errs[3] == nil))
+
+	winner := edits[2].change
+	if errs[2] != nil {
+		require.ErrorIs(t, errs[2], pgx.ErrNoRows)
+		winner = edits[3].change
+	} else {
+		require.ErrorIs(t, errs[3], pgx.ErrNoRows)
+	}
 
-		t.Run(tc.name, func(t *testing.T) {
-			t.Parallel()
+	edited, err := repo.ReadOne(ctx, group.ID, owner.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, edited.Members, 4)
+	require.Empty(t, edited.Role(removed.Entity.ID))
+	require.Equal(t, entity.GroupRoleEditor, edited.Role(promoted.Entity.ID))
+	require.Equal(t, winner.Role, edited.Role(contested.Entity.ID))
 
-			err := repo.DeleteAllMembers(ctx, tc.groupID, tc.ownerID)
-			require.NoError(t, err)
+	events, err := repo.ReadMemberEvents(ctx, group.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, events, len(changes)+3)
+}
 
-			var count int64
-			query := "SELECT count(account_id) from groups_accounts WHERE groups_accounts.group_id = $1"
-			pgTestSuite.db.QueryRow(ctx, query, tc.groupID).Scan(&count)
+func TestGroupRepository_Transaction(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewGroupRepository(pgTestSuite.db)
+	txManager := database.NewTxManager(pgTestSuite.db)
+	owner := createAccount(t, "transaction_owner")
+	addOwner := []entity.GroupMemberChange{{AccountID: owner.Entity.ID, Role: entity.GroupRoleOwner}}
+	errRollback := errors.New("rollback")
+
+	// the group and its owner are rolled back together
+	rolledBack := entity.Group{Name: "rolled back group", Owner: owner}
+	err := txManager.Do(ctx, func(ctx context.Context) error {
+		require.NoError(t, repo.Create(ctx, &rolledBack))
+		require.NoError(t, repo.ChangeMembers(ctx, rolledBack.ID, owner.Entity.ID, addOwner))
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	owned, err := repo.ReadByOwner(ctx, owner.Entity.ID)
+	require.NoError(t, err)
+	require.Empty(t, owned)
+
+	committed := entity.Group{Name: "committed group", Owner: owner}
+	err = txManager.Do(ctx, func(ctx context.Context) error {
+		if err := repo.Create(ctx, &committed); err != nil {
+			return err
+		}
+
+		return repo.ChangeMembers(ctx, committed.ID, owner.Entity.ID, addOwner)
+	})
+	require.NoError(t, err)
+
+	created, err := repo.ReadOne(ctx, committed.ID, owner.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, committed.Name, created.Name)
+	require.Equal(t, entity.GroupRoleOwner, created.Role(owner.Entity.ID))
+}
 
-			if tc.wouldDelete {
-				require.Zero(t, count)
-			} else {
-				require.NotZero(t, count)
-			}
-		})
+func TestGroupRepository_ReadByOwner(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewGroupRepository(pgTestSuite.db)
+	owner := createAccount(t, "read_by_owner_user")
+	member := createAccount(t, "read_by_owner_member")
+
+	group := entity.Group{Name: "owned group", Owner: owner}
+	require.NoError(t, repo.Create(ctx, &group))
+	require.NoError(t, repo.AddAccounts(ctx, group.ID, []entity.Account{owner}, entity.GroupRoleOwner))
+	require.NoError(t, repo.AddAccounts(ctx, group.ID, []entity.Account{member}, entity.GroupRoleEditor))
+
+	groups, err := repo.ReadByOwner(ctx, owner.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, group.ID, groups[0].ID)
+	require.Equal(t, owner.Entity.ID, groups[0].Owner.Entity.ID)
+	require.Len(t, groups[0].Members, 2)
+
+	groups, err = repo.ReadByOwner(ctx, member.Entity.ID)
+	require.NoError(t, err)
+	require.Empty(t, groups)
+}
+
+func TestGroupRepository_UpdateOwner(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewGroupRepository(pgTestSuite.db)
+	owner := createAccount(t, "update_owner_user")
+	member := createAccount(t, "update_owner_member")
+	stranger := createAccount(t, "update_owner_stranger")
+
+	group := entity.Group{Name: "handed over group", Owner: owner}
+	require.NoError(t, repo.Create(ctx, &group))
+	require.NoError(t, repo.AddAccounts(ctx, group.ID, []entity.Account{owner}, entity.GroupRoleOwner))
+	require.NoError(t, repo.AddAccounts(ctx, group.ID, []entity.Account{member}, entity.GroupRoleEditor))
+
+	err := repo.UpdateOwner(ctx, group.ID, owner.Entity.ID, stranger.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	err = repo.UpdateOwner(ctx, group.ID, member.Entity.ID, owner.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	err = repo.UpdateOwner(ctx, group.ID, owner.Entity.ID, member.Entity.ID)
+	require.NoError(t, err)
+
+	groups, err := repo.ReadByOwner(ctx, member.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, group.ID, groups[0].ID)
+
+	// the old owner stays on as an admin
+	require.Equal(t, entity.GroupRoleOwner, groups[0].Role(member.Entity.ID))
+	require.Equal(t, entity.GroupRoleAdmin, groups[0].Role(owner.Entity.ID))
+}
+
+func TestGroupRepository_DeleteMember(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewGroupRepository(pgTestSuite.db)
+	owner := createAccount(t, "delete_member_owner")
+	leaver := createAccount(t, "delete_member_leaver")
+	stayer := createAccount(t, "delete_member_stayer")
+
+	group := entity.Group{Name: "left group", Owner: owner}
+	require.NoError(t, repo.Create(ctx, &group))
+	require.NoError(t, repo.AddAccounts(ctx, group.ID, []entity.Account{owner}, entity.GroupRoleOwner))
+	require.NoError(t, repo.AddAccounts(ctx, group.ID, []entity.Account{leaver, stayer}, entity.GroupRoleEditor))
+	require.NoError(t, repo.OfferOwnership(ctx, group.ID, owner.Entity.ID, leaver.Entity.ID))
+
+	leaverItem := createVaultItem(t, "delete member leaver item", leaver.Entity.ID)
+	stayerItem := createVaultItem(t, "delete member stayer item", stayer.Entity.ID)
+	for _, itemID := range []types.ID{leaverItem, stayerItem} {
+		_, err := pgTestSuite.db.Exec(ctx, "INSERT INTO vault_items_groups (vault_item_id, group_id) VALUES ($1, $2)", itemID, group.ID)
+		require.NoError(t, err)
 	}
+
+	// the owner can not leave
+	err := repo.DeleteMember(ctx, group.ID, owner.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	require.NoError(t, repo.DeleteMember(ctx, group.ID, leaver.Entity.ID))
+
+	err = repo.DeleteMember(ctx, group.ID, leaver.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	left, err := repo.ReadOne(ctx, group.ID, owner.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, left.Members, 2)
+	require.Empty(t, left.Role(leaver.Entity.ID))
+	require.False(t, left.PendingOwnerID.Valid())
+
+	// only the items of the leaver stop being shared with the group
+	var shared []types.ID
+	rows, err := pgTestSuite.db.Query(ctx, "SELECT vault_item_id FROM vault_items_groups WHERE group_id = $1", group.ID)
+	require.NoError(t, err)
+	for rows.Next() {
+		var id types.ID
+		require.NoError(t, rows.Scan(&id))
+		shared = append(shared, id)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []types.ID{stayerItem}, shared)
+}
+
+func TestGroupRepository_Ownership(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewGroupRepository(pgTestSuite.db)
+	owner := createAccount(t, "ownership_owner")
+	member := createAccount(t, "ownership_member")
+	stranger := createAccount(t, "ownership_stranger")
+
+	group := entity.Group{Name: "offered group", Owner: owner}
+	require.NoError(t, repo.Create(ctx, &group))
+	require.NoError(t, repo.AddAccounts(ctx, group.ID, []entity.Account{owner}, entity.GroupRoleOwner))
+	require.NoError(t, repo.AddAccounts(ctx, group.ID, []entity.Account{member}, entity.GroupRoleViewer))
+
+	// only the owner can offer the group and only to another member
+	err := repo.OfferOwnership(ctx, group.ID, member.Entity.ID, owner.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	err = repo.OfferOwnership(ctx, group.ID, owner.Entity.ID, stranger.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	err = repo.OfferOwnership(ctx, group.ID, owner.Entity.ID, owner.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	err = repo.AcceptOwnership(ctx, group.ID, member.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	require.NoError(t, repo.OfferOwnership(ctx, group.ID, owner.Entity.ID, member.Entity.ID))
+
+	offers, err := repo.ReadOwnershipOffers(ctx, member.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, offers, 1)
+	require.Equal(t, group.ID, offers[0].ID)
+	require.Equal(t, owner.Username, offers[0].Owner.Username)
+
+	// the member declines, then the owner offers it again and takes it back
+	require.NoError(t, repo.DeleteOwnershipOffer(ctx, group.ID, member.Entity.ID))
+	err = repo.DeleteOwnershipOffer(ctx, group.ID, member.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	require.NoError(t, repo.OfferOwnership(ctx, group.ID, owner.Entity.ID, member.Entity.ID))
+	err = repo.DeleteOwnershipOffer(ctx, group.ID, stranger.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+	require.NoError(t, repo.DeleteOwnershipOffer(ctx, group.ID, owner.Entity.ID))
+
+	offers, err = repo.ReadOwnershipOffers(ctx, member.Entity.ID)
+	require.NoError(t, err)
+	require.Empty(t, offers)
+
+	require.NoError(t, repo.OfferOwnership(ctx, group.ID, owner.Entity.ID, member.Entity.ID))
+	require.NoError(t, repo.AcceptOwnership(ctx, group.ID, member.Entity.ID))
+
+	handedOver, err := repo.ReadOne(ctx, group.ID, member.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, member.Entity.ID, handedOver.Owner.Entity.ID)
+	require.Equal(t, entity.GroupRoleOwner, handedOver.Role(member.Entity.ID))
+	require.Equal(t, entity.GroupRoleAdmin, handedOver.Role(owner.Entity.ID))
+	require.False(t, handedOver.PendingOwnerID.Valid())
+
+	err = repo.AcceptOwnership(ctx, group.ID, member.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
 }
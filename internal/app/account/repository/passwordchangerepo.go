@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/redis/go-redis/v9"
+)
+
+type PasswordChangeRepository interface {
+	Create(ctx context.Context, change entity.PasswordChange) error
+	Get(ctx context.Context, id types.CacheID) (entity.PasswordChange, error)
+	Exist(ctx context.Context, id types.CacheID) (bool, error)
+	Delete(ctx context.Context, id types.CacheID) error
+}
+
+type passwordChangeRepo struct {
+	client *redis.Client
+}
+
+func NewPasswordChangeRepository(client *redis.Client) PasswordChangeRepository {
+	return passwordChangeRepo{client: client}
+}
+
+func (r passwordChangeRepo) Create(ctx context.Context, change entity.PasswordChange) error {
+	marshaledChange, err := json.Marshal(change)
+	if err != nil {
+		log.ErrorLogger.Error("error marshaling password change", "error", err.Error())
+		return err
+	}
+
+	err = r.client.Set(ctx, passwordChangeKey(change.ID), marshaledChange, change.Duration).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error saving password change", "error", err.Error(), "account_id", change.AccountID)
+		return err
+	}
+
+	return nil
+}
+
+func (r passwordChangeRepo) Get(ctx context.Context, id types.CacheID) (entity.PasswordChange, error) {
+	result, err := r.client.Get(ctx, passwordChangeKey(id)).Bytes()
+	if err != nil {
+		log.ErrorLogger.Error("error getting password change", "error", err.Error(), "id", id)
+		return entity.PasswordChange{}, err
+	}
+
+	change := new(entity.PasswordChange)
+	if err := json.Unmarshal(result, change); err != nil {
+		log.ErrorLogger.Error("error at unmarshaling password change", "error", err.Error())
+		return entity.PasswordChange{}, err
+	}
+
+	return *change, nil
+}
+
+func (r passwordChangeRepo) Exist(ctx context.Context, id types.CacheID) (bool, error) {
+	count, err := r.client.Exists(ctx, passwordChangeKey(id)).Result()
+	if err != nil {
+		log.ErrorLogger.Error("error checking password change existence", "error", err.Error(), "id", id)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r passwordChangeRepo) Delete(ctx context.Context, id types.CacheID) error {
+	err := r.client.Del(ctx, passwordChangeKey(id)).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error deleting password change", "error", err.Error(), "id", id)
+		return err
+	}
+
+	return nil
+}
+
+func passwordChangeKey(id types.CacheID) string {
+	return fmt.Sprintf("password-change:%s", id)
+}
@@ -0,0 +1,106 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordChangeRepository_Create(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewPasswordChangeRepository(redisClient)
+
+	change := entity.PasswordChange{
+		CacheEntity: base.CacheEntity{ID: "create_password_change", Duration: time.Minute},
+		AccountID:   types.ID(100),
+		Username:    "something",
+		AKEState:    []byte("state"),
+	}
+
+	err := repo.Create(ctx, change)
+	require.NoError(t, err)
+
+	saved, err := repo.Get(ctx, change.ID)
+	require.NoError(t, err)
+	require.Equal(t, change.AccountID, saved.AccountID)
+	require.Equal(t, change.AKEState, saved.AKEState)
+	require.False(t, saved.Verified)
+}
+
+func TestPasswordChangeRepository_Get(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewPasswordChangeRepository(redisClient)
+
+	change := entity.PasswordChange{
+		CacheEntity:  base.CacheEntity{ID: "get_password_change", Duration: time.Minute},
+		AccountID:    types.ID(101),
+		Username:     "something",
+		CredentialID: []byte("credential"),
+		Verified:     true,
+	}
+	require.NoError(t, repo.Create(ctx, change))
+
+	testcases := []struct {
+		name string
+		id   types.CacheID
+		err  error
+	}{
+		{
+			name: "successful",
+			id:   change.ID,
+		},
+		{
+			name: "not found",
+			id:   "missing_password_change",
+			err:  redis.Nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			saved, err := repo.Get(ctx, tc.id)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, change.CredentialID, saved.CredentialID)
+			require.True(t, saved.Verified)
+		})
+	}
+}
+
+func TestPasswordChangeRepository_Delete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewPasswordChangeRepository(redisClient)
+
+	change := entity.PasswordChange{
+		CacheEntity: base.CacheEntity{ID: "delete_password_change", Duration: time.Minute},
+		AccountID:   types.ID(102),
+	}
+	require.NoError(t, repo.Create(ctx, change))
+
+	exist, err := repo.Exist(ctx, change.ID)
+	require.NoError(t, err)
+	require.True(t, exist)
+
+	err = repo.Delete(ctx, change.ID)
+	require.NoError(t, err)
+
+	exist, err = repo.Exist(ctx, change.ID)
+	require.NoError(t, err)
+	require.False(t, exist)
+}
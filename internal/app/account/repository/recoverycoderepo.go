@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+)
+
+type RecoveryCodeRepository interface {
+	Replace(ctx context.Context, accountID types.ID, codeHashes [][]byte) error
+	Use(ctx context.Context, accountID types.ID, codeHash []byte) (bool, error)
+	CountUnused(ctx context.Context, accountID types.ID) (int, error)
+}
+
+type recoveryCodeRepo struct {
+	db database.Querier
+}
+
+func NewRecoveryCodeRepository(db database.Querier) RecoveryCodeRepository {
+	return recoveryCodeRepo{db: database.Join(db)}
+}
+
+// Replace drops every code of the account and stores the new ones in one transaction.
+func (r recoveryCodeRepo) Replace(ctx context.Context, accountID types.ID, codeHashes [][]byte) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		log.ErrorLogger.Error("error at beginning transaction", "error", err.Error())
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "DELETE FROM recovery_codes WHERE account_id = $1", accountID); err != nil {
+		log.ErrorLogger.Error("error at deleting recovery codes", "error", err.Error(), "account_id", accountID)
+		return err
+	}
+
+	for _, codeHash := range codeHashes {
+		query := "INSERT INTO recovery_codes (account_id, code_hash) VALUES ($1, $2)"
+		if _, err := tx.Exec(ctx, query, accountID, codeHash); err != nil {
+			log.ErrorLogger.Error("error at creating recovery code", "error", err.Error(), "account_id", accountID)
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.ErrorLogger.Error("error at committing recovery codes", "error", err.Error(), "account_id", accountID)
+		return err
+	}
+
+	return nil
+}
+
+// Use marks an unused code as used and reports whether there was one to mark.
+func (r recoveryCodeRepo) Use(ctx context.Context, accountID types.ID, codeHash []byte) (bool, error) {
+	query := `
+	UPDATE recovery_codes SET used_at = CURRENT_TIMESTAMP
+	WHERE account_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	tag, err := r.db.Exec(ctx, query, accountID, codeHash)
+	if err != nil {
+		log.ErrorLogger.Error("error at using recovery code", "error", err.Error(), "account_id", accountID)
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r recoveryCodeRepo) CountUnused(ctx context.Context, accountID types.ID) (int, error) {
+	query := "SELECT COUNT(*) FROM recovery_codes WHERE account_id = $1 AND used_at IS NULL"
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, accountID).Scan(&count); err != nil {
+		log.ErrorLogger.Error("error at counting recovery codes", "error", err.Error(), "account_id", accountID)
+		return 0, err
+	}
+
+	return count, nil
+}
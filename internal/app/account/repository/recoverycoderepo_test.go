@@ -0,0 +1,71 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/seed"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryCodeRepository_Replace(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewRecoveryCodeRepository(pgTestSuite.db)
+	accountID := seed.AccountEarl.Entity.ID
+
+	err := repo.Replace(ctx, accountID, [][]byte{[]byte("first"), []byte("second")})
+	require.NoError(t, err)
+
+	count, err := repo.CountUnused(ctx, accountID)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	err = repo.Replace(ctx, accountID, [][]byte{[]byte("third")})
+	require.NoError(t, err)
+
+	count, err = repo.CountUnused(ctx, accountID)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestRecoveryCodeRepository_Use(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewRecoveryCodeRepository(pgTestSuite.db)
+	accountID := seed.AccountFrankOcean.Entity.ID
+
+	require.NoError(t, repo.Replace(ctx, accountID, [][]byte{[]byte("code")}))
+
+	testcases := []struct {
+		name     string
+		codeHash []byte
+		expected bool
+	}{
+		{
+			name:     "unused code",
+			codeHash: []byte("code"),
+			expected: true,
+		},
+		{
+			name:     "used code",
+			codeHash: []byte("code"),
+			expected: false,
+		},
+		{
+			name:     "unknown code",
+			codeHash: []byte("unknown"),
+			expected: false,
+		},
+	}
+
+	// the cases depend on each other, so they run in order
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			used, err := repo.Use(ctx, accountID, tc.codeHash)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, used)
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 type RegistrationRepository interface {
 	Create(ctx context.Context, registration entity.Registration) error
 	Get(ctx context.Context, id types.CacheID) (entity.Registration, error)
+	Exist(ctx context.Context, id types.CacheID) (bool, error)
 }
 
 type registrationRepo struct {
@@ -54,3 +55,13 @@ func (r registrationRepo) Get(ctx context.Context, id types.CacheID) (entity.Reg
 
 	return *registration, nil
 }
+
+func (r registrationRepo) Exist(ctx context.Context, id types.CacheID) (bool, error) {
+	count, err := r.client.Exists(ctx, string(id)).Result()
+	if err != nil {
+		log.ErrorLogger.Error("error checking registration existence", "error", err.Error(), "id", id)
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/session"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/redis/go-redis/v9"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session entity.Session) error
+	Get(ctx context.Context, id types.CacheID) (entity.Session, error)
+	Exist(ctx context.Context, id types.CacheID) (bool, error)
+	Update(ctx context.Context, session entity.Session) error
+	ReadByAccount(ctx context.Context, accountID types.ID) ([]entity.Session, error)
+	Delete(ctx context.Context, accountID types.ID, id types.CacheID) error
+}
+
+type sessionRepo struct {
+	client *redis.Client
+}
+
+func NewSessionRepository(client *redis.Client) SessionRepository {
+	return sessionRepo{client: client}
+}
+
+func (r sessionRepo) Create(ctx context.Context, s entity.Session) error {
+	marshaledSession, err := json.Marshal(s)
+	if err != nil {
+		log.ErrorLogger.Error("error marshaling session", "error", err.Error())
+		return err
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.Set(ctx, sessionInfoKey(s.ID), marshaledSession, s.Duration)
+	pipe.SAdd(ctx, accountSessionsKey(s.AccountID), string(s.ID))
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.ErrorLogger.Error("error saving session", "error", err.Error(), "account_id", s.AccountID)
+		return err
+	}
+
+	return nil
+}
+
+func (r sessionRepo) Get(ctx context.Context, id types.CacheID) (entity.Session, error) {
+	result, err := r.client.Get(ctx, sessionInfoKey(id)).Bytes()
+	if err != nil {
+		log.ErrorLogger.Error("error getting session", "error", err.Error(), "id", id)
+		return entity.Session{}, err
+	}
+
+	s := new(entity.Session)
+	if err := json.Unmarshal(result, s); err != nil {
+		log.ErrorLogger.Error("error at unmarshaling session", "error", err.Error())
+		return entity.Session{}, err
+	}
+
+	return *s, nil
+}
+
+func (r sessionRepo) Exist(ctx context.Context, id types.CacheID) (bool, error) {
+	count, err := r.client.Exists(ctx, sessionInfoKey(id)).Result()
+	if err != nil {
+		log.ErrorLogger.Error("error checking session existence", "error", err.Error(), "id", id)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r sessionRepo) Update(ctx context.Context, s entity.Session) error {
+	marshaledSession, err := json.Marshal(s)
+	if err != nil {
+		log.ErrorLogger.Error("error marshaling session", "error", err.Error())
+		return err
+	}
+
+	err = r.client.SetArgs(ctx, sessionInfoKey(s.ID), marshaledSession, redis.SetArgs{KeepTTL: true, Mode: "XX"}).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.ErrorLogger.Error("error updating session", "error", err.Error(), "id", s.ID)
+		return err
+	}
+
+	return nil
+}
+
+func (r sessionRepo) ReadByAccount(ctx context.Context, accountID types.ID) ([]entity.Session, error) {
+	ids, err := r.client.SMembers(ctx, accountSessionsKey(accountID)).Result()
+	if err != nil {
+		log.ErrorLogger.Error("error reading account sessions", "error", err.Error(), "account_id", accountID)
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return []entity.Session{}, nil
+	}
+
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, sessionInfoKey(types.CacheID(id)))
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		log.ErrorLogger.Error("error reading sessions", "error", err.Error(), "account_id", accountID)
+		return nil, err
+	}
+
+	sessions := make([]entity.Session, 0, len(values))
+	expired := make([]any, 0)
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+
+		var s entity.Session
+		if err := json.Unmarshal([]byte(data), &s); err != nil {
+			log.ErrorLogger.Error("error at unmarshaling session", "error", err.Error())
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	// the info of expired sessions is gone, only their ids are left in the set
+	if len(expired) > 0 {
+		if err := r.client.SRem(ctx, accountSessionsKey(accountID), expired...).Err(); err != nil {
+			log.ErrorLogger.Error("error removing expired sessions", "error", err.Error(), "account_id", accountID)
+			return nil, err
+		}
+	}
+
+	return sessions, nil
+}
+
+func (r sessionRepo) Delete(ctx context.Context, accountID types.ID, id types.CacheID) error {
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, sessionInfoKey(id), session.Key(string(id)))
+	pipe.SRem(ctx, accountSessionsKey(accountID), string(id))
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.ErrorLogger.Error("error deleting session", "error", err.Error(), "id", id)
+		return err
+	}
+
+	return nil
+}
+
+func sessionInfoKey(id types.CacheID) string {
+	return fmt.Sprintf("session-info:%s", id)
+}
+
+func accountSessionsKey(accountID types.ID) string {
+	return fmt.Sprintf("account-sessions:%d", accountID)
+}
@@ -0,0 +1,85 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRepository_Create(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewSessionRepository(redisClient)
+
+	session := entity.Session{
+		CacheEntity: base.CacheEntity{ID: "create_session", Duration: time.Minute},
+		AccountID:   types.ID(100),
+		IP:          "198.51.100.10",
+		UserAgent:   "Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0",
+		Device:      "Firefox on Linux",
+		CreatedAt:   time.Now(),
+		LastSeen:    time.Now(),
+	}
+
+	err := repo.Create(ctx, session)
+	require.NoError(t, err)
+
+	saved, err := repo.Get(ctx, session.ID)
+	require.NoError(t, err)
+	require.Equal(t, session.AccountID, saved.AccountID)
+	require.Equal(t, session.Device, saved.Device)
+
+	sessions, err := repo.ReadByAccount(ctx, session.AccountID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+}
+
+func TestSessionRepository_Update(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewSessionRepository(redisClient)
+
+	session := entity.Session{
+		CacheEntity: base.CacheEntity{ID: "update_session", Duration: time.Minute},
+		AccountID:   types.ID(101),
+		IP:          "198.51.100.11",
+	}
+	require.NoError(t, repo.Create(ctx, session))
+
+	session.IP = "198.51.100.12"
+	err := repo.Update(ctx, session)
+	require.NoError(t, err)
+
+	saved, err := repo.Get(ctx, session.ID)
+	require.NoError(t, err)
+	require.Equal(t, "198.51.100.12", saved.IP)
+}
+
+func TestSessionRepository_Delete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewSessionRepository(redisClient)
+
+	session := entity.Session{
+		CacheEntity: base.CacheEntity{ID: "delete_session", Duration: time.Minute},
+		AccountID:   types.ID(102),
+	}
+	require.NoError(t, repo.Create(ctx, session))
+
+	err := repo.Delete(ctx, session.AccountID, session.ID)
+	require.NoError(t, err)
+
+	exist, err := repo.Exist(ctx, session.ID)
+	require.NoError(t, err)
+	require.False(t, exist)
+
+	sessions, err := repo.ReadByAccount(ctx, session.AccountID)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/redis/go-redis/v9"
+)
+
+type UsernameChangeRepository interface {
+	Create(ctx context.Context, change entity.UsernameChange) error
+	Get(ctx context.Context, id types.CacheID) (entity.UsernameChange, error)
+	Exist(ctx context.Context, id types.CacheID) (bool, error)
+	Delete(ctx context.Context, id types.CacheID) error
+}
+
+type usernameChangeRepo struct {
+	client *redis.Client
+}
+
+func NewUsernameChangeRepository(client *redis.Client) UsernameChangeRepository {
+	return usernameChangeRepo{client: client}
+}
+
+func (r usernameChangeRepo) Create(ctx context.Context, change entity.UsernameChange) error {
+	marshaledChange, err := json.Marshal(change)
+	if err != nil {
+		log.ErrorLogger.Error("error marshaling username change", "error", err.Error())
+		return err
+	}
+
+	err = r.client.Set(ctx, usernameChangeKey(change.ID), marshaledChange, change.Duration).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error saving username change", "error", err.Error(), "account_id", change.AccountID)
+		return err
+	}
+
+	return nil
+}
+
+func (r usernameChangeRepo) Get(ctx context.Context, id types.CacheID) (entity.UsernameChange, error) {
+	result, err := r.client.Get(ctx, usernameChangeKey(id)).Bytes()
+	if err != nil {
+		log.ErrorLogger.Error("error getting username change", "error", err.Error(), "id", id)
+		return entity.UsernameChange{}, err
+	}
+
+	change := new(entity.UsernameChange)
+	if err := json.Unmarshal(result, change); err != nil {
+		log.ErrorLogger.Error("error at unmarshaling username change", "error", err.Error())
+		return entity.UsernameChange{}, err
+	}
+
+	return *change, nil
+}
+
+func (r usernameChangeRepo) Exist(ctx context.Context, id types.CacheID) (bool, error) {
+	count, err := r.client.Exists(ctx, usernameChangeKey(id)).Result()
+	if err != nil {
+		log.ErrorLogger.Error("error checking username change existence", "error", err.Error(), "id", id)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r usernameChangeRepo) Delete(ctx context.Context, id types.CacheID) error {
+	err := r.client.Del(ctx, usernameChangeKey(id)).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error deleting username change", "error", err.Error(), "id", id)
+		return err
+	}
+
+	return nil
+}
+
+func usernameChangeKey(id types.CacheID) string {
+	return fmt.Sprintf("username-change:%s", id)
+}
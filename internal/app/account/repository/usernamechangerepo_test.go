@@ -0,0 +1,44 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsernameChangeRepository(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewUsernameChangeRepository(redisClient)
+
+	change := entity.UsernameChange{
+		CacheEntity: base.CacheEntity{ID: "username_change", Duration: time.Minute},
+		AccountID:   types.ID(121),
+		Username:    "old_name",
+		NewUsername: "new_name",
+		AKEState:    []byte("state"),
+		Verified:    true,
+	}
+
+	err := repo.Create(ctx, change)
+	require.NoError(t, err)
+
+	saved, err := repo.Get(ctx, change.ID)
+	require.NoError(t, err)
+	require.Equal(t, change.AccountID, saved.AccountID)
+	require.Equal(t, change.NewUsername, saved.NewUsername)
+	require.Equal(t, change.AKEState, saved.AKEState)
+	require.True(t, saved.Verified)
+
+	err = repo.Delete(ctx, change.ID)
+	require.NoError(t, err)
+
+	_, err = repo.Get(ctx, change.ID)
+	require.Error(t, err)
+}
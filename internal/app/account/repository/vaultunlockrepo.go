@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/redis/go-redis/v9"
+)
+
+type VaultUnlockRepository interface {
+	Create(ctx context.Context, unlock entity.VaultUnlock) error
+	Get(ctx context.Context, id types.CacheID) (entity.VaultUnlock, error)
+	Exist(ctx context.Context, id types.CacheID) (bool, error)
+	Delete(ctx context.Context, id types.CacheID) error
+}
+
+type vaultUnlockRepo struct {
+	client *redis.Client
+}
+
+func NewVaultUnlockRepository(client *redis.Client) VaultUnlockRepository {
+	return vaultUnlockRepo{client: client}
+}
+
+func (r vaultUnlockRepo) Create(ctx context.Context, unlock entity.VaultUnlock) error {
+	marshaledUnlock, err := json.Marshal(unlock)
+	if err != nil {
+		log.ErrorLogger.Error("error marshaling vault unlock", "error", err.Error())
+		return err
+	}
+
+	err = r.client.Set(ctx, vaultUnlockKey(unlock.ID), marshaledUnlock, unlock.Duration).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error saving vault unlock", "error", err.Error(), "account_id", unlock.AccountID)
+		return err
+	}
+
+	return nil
+}
+
+func (r vaultUnlockRepo) Get(ctx context.Context, id types.CacheID) (entity.VaultUnlock, error) {
+	result, err := r.client.Get(ctx, vaultUnlockKey(id)).Bytes()
+	if err != nil {
+		log.ErrorLogger.Error("error getting vault unlock", "error", err.Error(), "id", id)
+		return entity.VaultUnlock{}, err
+	}
+
+	unlock := new(entity.VaultUnlock)
+	if err := json.Unmarshal(result, unlock); err != nil {
+		log.ErrorLogger.Error("error at unmarshaling vault unlock", "error", err.Error())
+		return entity.VaultUnlock{}, err
+	}
+
+	return *unlock, nil
+}
+
+func (r vaultUnlockRepo) Exist(ctx context.Context, id types.CacheID) (bool, error) {
+	count, err := r.client.Exists(ctx, vaultUnlockKey(id)).Result()
+	if err != nil {
+		log.ErrorLogger.Error("error checking vault unlock existence", "error", err.Error(), "id", id)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r vaultUnlockRepo) Delete(ctx context.Context, id types.CacheID) error {
+	err := r.client.Del(ctx, vaultUnlockKey(id)).Err()
+	if err != nil {
+		log.ErrorLogger.Error("error deleting vault unlock", "error", err.Error(), "id", id)
+		return err
+	}
+
+	return nil
+}
+
+func vaultUnlockKey(id types.CacheID) string {
+	return fmt.Sprintf("vault-unlock:%s", id)
+}
@@ -0,0 +1,59 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultUnlockRepository_Create(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewVaultUnlockRepository(redisClient)
+
+	unlock := entity.VaultUnlock{
+		CacheEntity: base.CacheEntity{ID: "create_vault_unlock", Duration: time.Minute},
+		AccountID:   types.ID(110),
+		AKEState:    []byte("state"),
+	}
+
+	err := repo.Create(ctx, unlock)
+	require.NoError(t, err)
+
+	saved, err := repo.Get(ctx, unlock.ID)
+	require.NoError(t, err)
+	require.Equal(t, unlock.AccountID, saved.AccountID)
+	require.Equal(t, unlock.AKEState, saved.AKEState)
+
+	_, err = repo.Get(ctx, "missing_vault_unlock")
+	require.Error(t, err)
+}
+
+func TestVaultUnlockRepository_Delete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewVaultUnlockRepository(redisClient)
+
+	unlock := entity.VaultUnlock{
+		CacheEntity: base.CacheEntity{ID: "delete_vault_unlock", Duration: time.Minute},
+		AccountID:   types.ID(111),
+	}
+	require.NoError(t, repo.Create(ctx, unlock))
+
+	exist, err := repo.Exist(ctx, unlock.ID)
+	require.NoError(t, err)
+	require.True(t, exist)
+
+	err = repo.Delete(ctx, unlock.ID)
+	require.NoError(t, err)
+
+	exist, err = repo.Exist(ctx, unlock.ID)
+	require.NoError(t, err)
+	require.False(t, exist)
+}
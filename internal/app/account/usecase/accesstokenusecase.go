@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// accessTokenPrefix marks our tokens, so secret scanners can find the ones that
+	// leaked into a repository.
+	accessTokenPrefix = "cpm_"
+
+	// accessTokenDisplayLength is how much of the token is kept in clear to tell the
+	// tokens apart on the management page.
+	accessTokenDisplayLength = len(accessTokenPrefix) + 8
+
+	maxAccessTokenDays = 365
+)
+
+type AccessTokenUsecase struct {
+	accessTokenRepo repository.AccessTokenRepository
+}
+
+func NewAccessTokenUsecase(atRepo repository.AccessTokenRepository) AccessTokenUsecase {
+	return AccessTokenUsecase{accessTokenRepo: atRepo}
+}
+
+func (u *AccessTokenUsecase) Read(ctx context.Context, accountID types.ID) ([]entity.AccessToken, error) {
+	tokens, err := u.accessTokenRepo.ReadByAccount(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading access tokens", "error", err.Error(), "account_id", accountID)
+		return nil, errors.NewServerError()
+	}
+
+	return tokens, nil
+}
+
+// Create issues a token and returns it in clear along with what was stored. An
+// expiry of zero days makes a token that never expires.
+func (u *AccessTokenUsecase) Create(
+	ctx context.Context, accountID types.ID, name string, permission entity.AccessTokenPermission, expiryDays int,
+	itemIDs, groupIDs []types.ID,
+) (string, entity.AccessToken, error) {
+	if !permission.Valid() {
+		return "", entity.AccessToken{}, account.AccessTokenInvalidPermission
+	}
+
+	if expiryDays < 0 || expiryDays > maxAccessTokenDays {
+		return "", entity.AccessToken{}, account.AccessTokenInvalidExpiry
+	}
+
+	tokens, err := u.Read(ctx, accountID)
+	if err != nil {
+		return "", entity.AccessToken{}, err
+	}
+
+	if slices.ContainsFunc(tokens, func(t entity.AccessToken) bool { return t.Name == name }) {
+		return "", entity.AccessToken{}, account.AccessTokenExist
+	}
+
+	raw, err := generateAccessToken()
+	if err != nil {
+		log.ErrorLogger.Error("error at generating access token", "error", err.Error())
+		return "", entity.AccessToken{}, errors.NewServerError()
+	}
+
+	token := entity.AccessToken{
+		Name:       name,
+		Prefix:     raw[:accessTokenDisplayLength],
+		TokenHash:  hashAccessToken(raw),
+		Permission: permission,
+		ItemIDs:    uniqueIDs(itemIDs),
+		GroupIDs:   uniqueIDs(groupIDs),
+	}
+	token.Account.Entity.ID = accountID
+
+	if expiryDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, expiryDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	err = u.accessTokenRepo.Create(ctx, &token)
+	if err == pgx.ErrNoRows {
+		return "", entity.AccessToken{}, account.AccessTokenInvalidRestriction
+	}
+	if err != nil {
+		log.ErrorLogger.Error("error at creating access token", "error", err.Error(), "account_id", accountID)
+		return "", entity.AccessToken{}, errors.NewServerError()
+	}
+
+	return raw, token, nil
+}
+
+// Authenticate returns the token behind the bearer value of a request and keeps its
+// last use up to date.
+func (u *AccessTokenUsecase) Authenticate(ctx context.Context, raw string) (entity.AccessToken, error) {
+	if !strings.HasPrefix(raw, accessTokenPrefix) {
+		return entity.AccessToken{}, account.AccessTokenInvalid
+	}
+
+	token, err := u.accessTokenRepo.ReadByHash(ctx, hashAccessToken(raw))
+	if err == pgx.ErrNoRows {
+		return entity.AccessToken{}, account.AccessTokenInvalid
+	}
+	if err != nil {
+		log.ErrorLogger.Error("error at reading access token", "error", err.Error())
+		return entity.AccessToken{}, errors.NewServerError()
+	}
+
+	now := time.Now()
+	if token.Expired(now) {
+		return entity.AccessToken{}, account.AccessTokenInvalid
+	}
+
+	// failing to track the use must not break the request
+	_ = u.accessTokenRepo.Touch(ctx, token.ID, now)
+	token.LastUsedAt = &now
+
+	return token, nil
+}
+
+func (u *AccessTokenUsecase) Revoke(ctx context.Context, accountID, tokenID types.ID) error {
+	err := u.accessTokenRepo.Delete(ctx, tokenID, accountID)
+	if err == pgx.ErrNoRows {
+		return account.AccessTokenDoesNotExist
+	}
+	if err != nil {
+		log.ErrorLogger.Error("error at revoking access token", "error", err.Error(), "id", tokenID)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+func generateAccessToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return accessTokenPrefix + hex.EncodeToString(bytes), nil
+}
+
+// hashAccessToken does not need a slow hash, the token is random and long enough
+// that it can not be guessed from its hash.
+func hashAccessToken(raw string) []byte {
+	hash := sha256.Sum256([]byte(raw))
+	return hash[:]
+}
+
+func uniqueIDs(ids []types.ID) []types.ID {
+	unique := make([]types.ID, 0, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(unique, id) {
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,142 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/seed"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessTokenUsecase_Create(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	acc := createEmergencyAccessAccount(t, "access_token_create_user")
+	u := setupAccessTokenUsecase()
+
+	_, _, err := u.Create(ctx, acc.Entity.ID, "existing", entity.AccessTokenRead, 30, nil, nil)
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name       string
+		tokenName  string
+		permission entity.AccessTokenPermission
+		expiryDays int
+		groupIDs   []types.ID
+		err        error
+	}{
+		{
+			name:       "invalid permission",
+			tokenName:  "admin",
+			permission: "admin",
+			expiryDays: 30,
+			err:        account.AccessTokenInvalidPermission,
+		},
+		{
+			name:       "expiry too long",
+			tokenName:  "forever",
+			permission: entity.AccessTokenRead,
+			expiryDays: 1000,
+			err:        account.AccessTokenInvalidExpiry,
+		},
+		{
+			name:       "taken name",
+			tokenName:  "existing",
+			permission: entity.AccessTokenRead,
+			expiryDays: 30,
+			err:        account.AccessTokenExist,
+		},
+		{
+			name:       "group the account is not a member of",
+			tokenName:  "foreign group",
+			permission: entity.AccessTokenRead,
+			expiryDays: 30,
+			groupIDs:   []types.ID{seed.GroupBrockhampton.ID},
+			err:        account.AccessTokenInvalidRestriction,
+		},
+		{
+			name:       "never expiring token",
+			tokenName:  "ci",
+			permission: entity.AccessTokenReadWrite,
+			expiryDays: 0,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, token, err := u.Create(ctx, acc.Entity.ID, tc.tokenName, tc.permission, tc.expiryDays, nil, tc.groupIDs)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(raw, "cpm_"))
+			require.True(t, strings.HasPrefix(raw, token.Prefix))
+			require.NotContains(t, string(token.TokenHash), raw)
+			require.Nil(t, token.ExpiresAt)
+		})
+	}
+}
+
+func TestAccessTokenUsecase_Authenticate(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	acc := createEmergencyAccessAccount(t, "access_token_auth_user")
+	u := setupAccessTokenUsecase()
+
+	raw, created, err := u.Create(ctx, acc.Entity.ID, "script", entity.AccessTokenRead, 7, nil, nil)
+	require.NoError(t, err)
+
+	token, err := u.Authenticate(ctx, raw)
+	require.NoError(t, err)
+	require.Equal(t, created.ID, token.ID)
+	require.Equal(t, acc.Username, token.Account.Username)
+	require.False(t, token.CanWrite())
+
+	tokens, err := u.Read(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.NotNil(t, tokens[0].LastUsedAt)
+
+	for _, invalid := range []string{"", "not a token", raw + "0", "cpm_" + strings.Repeat("0", 64)} {
+		_, err = u.Authenticate(ctx, invalid)
+		require.ErrorIs(t, err, account.AccessTokenInvalid)
+	}
+
+	// expired tokens are refused
+	_, err = pgTestSuite.db.Exec(ctx, "UPDATE access_tokens SET expires_at = NOW() - INTERVAL '1 day' WHERE id = $1", token.ID)
+	require.NoError(t, err)
+
+	_, err = u.Authenticate(ctx, raw)
+	require.ErrorIs(t, err, account.AccessTokenInvalid)
+}
+
+func TestAccessTokenUsecase_Revoke(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	acc := createEmergencyAccessAccount(t, "access_token_revoke_user")
+	u := setupAccessTokenUsecase()
+
+	raw, token, err := u.Create(ctx, acc.Entity.ID, "revoked", entity.AccessTokenReadWrite, 7, nil, nil)
+	require.NoError(t, err)
+
+	err = u.Revoke(ctx, seed.AccountJohnDoe.Entity.ID, token.ID)
+	require.ErrorIs(t, err, account.AccessTokenDoesNotExist)
+
+	err = u.Revoke(ctx, acc.Entity.ID, token.ID)
+	require.NoError(t, err)
+
+	_, err = u.Authenticate(ctx, raw)
+	require.ErrorIs(t, err, account.AccessTokenInvalid)
+}
+
+func setupAccessTokenUsecase() usecase.AccessTokenUsecase {
+	return usecase.NewAccessTokenUsecase(repository.NewAccessTokenRepository(pgTestSuite.db))
+}
@@ -0,0 +1,586 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+)
+
+// AccountUsecase manages the profile of the signed in account. Changing the email
+// needs the code mailed to the new address, renaming the account registers the
+// password again under the new username and deleting it needs a fresh login.
+type AccountUsecase struct {
+	accountRepo        repository.AccountRepository
+	groupRepo          repository.GroupRepository
+	emailChangeRepo    repository.EmailChangeRepository
+	usernameChangeRepo repository.UsernameChangeRepository
+	vaultUnlockRepo    repository.VaultUnlockRepository
+	sessionRepo        repository.SessionRepository
+	lockout
+
+	opaqueServer opaque.OpaqueService
+	mailer       mail.Mailer
+
+	config *config.Config
+}
+
+func NewAccountUsecase(aRepo repository.AccountRepository, gRepo repository.GroupRepository,
+	ecRepo repository.EmailChangeRepository, ucRepo repository.UsernameChangeRepository,
+	vuRepo repository.VaultUnlockRepository, sRepo repository.SessionRepository, atRepo repository.AttemptRepository,
+	opaqueServer opaque.OpaqueService, mailer mail.Mailer, config *config.Config) AccountUsecase {
+	return AccountUsecase{
+		accountRepo:        aRepo,
+		groupRepo:          gRepo,
+		emailChangeRepo:    ecRepo,
+		usernameChangeRepo: ucRepo,
+		vaultUnlockRepo:    vuRepo,
+		sessionRepo:        sRepo,
+		lockout:            lockout{attemptRepo: atRepo, config: config},
+		opaqueServer:       opaqueServer,
+		mailer:             mailer,
+		config:             config,
+	}
+}
+
+func (u *AccountUsecase) Read(ctx context.Context, accountID types.ID) (entity.Account, error) {
+	acc, err := u.accountRepo.ReadByID(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", accountID)
+		return entity.Account{}, errors.NewServerError()
+	}
+
+	return acc, nil
+}
+
+// ReadOwnedGroups returns the groups that have to be handed over or deleted along
+// with the account.
+func (u *AccountUsecase) ReadOwnedGroups(ctx context.Context, accountID types.ID) ([]entity.Group, error) {
+	groups, err := u.groupRepo.ReadByOwner(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading owned groups", "error", err.Error(), "account_id", accountID)
+		return nil, errors.NewServerError()
+	}
+
+	return groups, nil
+}
+
+func (u *AccountUsecase) UpdateProfile(ctx context.Context, accountID types.ID, firstName, lastName string) error {
+	if err := u.accountRepo.UpdateProfile(ctx, accountID, firstName, lastName); err != nil {
+		log.ErrorLogger.Error("error at updating profile", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+// EmailChangeInit mails a verification code to the new address. The email of the
+// account stays the same until the code is entered.
+func (u *AccountUsecase) EmailChangeInit(ctx context.Context, accountID types.ID, email string) (types.CacheID, error) {
+	exist, err := u.accountRepo.ExistByEmail(ctx, email)
+	if err != nil {
+		log.ErrorLogger.Error("error checking user existence by email", "error", err.Error(), "email", email)
+		return types.CacheID(""), errors.NewServerError()
+	}
+
+	if exist {
+		return types.CacheID(""), account.AuthEmailExist
+	}
+
+	acc, err := u.Read(ctx, accountID)
+	if err != nil {
+		return types.CacheID(""), err
+	}
+
+	code, err := generateVerificationCode()
+	if err != nil {
+		log.ErrorLogger.Error("error at generating verification code", "error", err.Error())
+		return types.CacheID(""), errors.NewServerError()
+	}
+
+	changeID, err := generateRandomID()
+	if err != nil {
+		log.ErrorLogger.Error("error generating email change id", "error", err.Error(), "account_id", accountID)
+		return types.CacheID(""), errors.NewServerError()
+	}
+
+	change := entity.EmailChange{
+		CacheEntity:      base.CacheEntity{ID: types.CacheID(changeID), Duration: u.changeDuration()},
+		AccountID:        accountID,
+		Email:            email,
+		VerificationCode: hashVerificationCode(code),
+	}
+
+	if err := u.emailChangeRepo.Create(ctx, change); err != nil {
+		log.ErrorLogger.Error("error at saving email change", "error", err.Error(), "account_id", accountID)
+		return types.CacheID(""), errors.NewServerError()
+	}
+
+	verificationEmail, err := mail.NewMessage(email, mail.TemplateEmailChangeVerification, map[string]any{
+		"Username": acc.Username,
+		"Code":     code,
+		"Minutes":  u.config.TwoFactorDuration,
+	})
+	if err != nil {
+		log.ErrorLogger.Error("error at rendering verification email", "error", err.Error())
+		return types.CacheID(""), errors.NewServerError()
+	}
+
+	// without the email the change can not be finished, so a failure is not ignored here
+	if err := u.mailer.Send(ctx, verificationEmail); err != nil {
+		log.ErrorLogger.Error("error at sending verification email", "error", err.Error(), "account_id", accountID)
+		return types.CacheID(""), errors.NewServerError()
+	}
+
+	return change.ID, nil
+}
+
+// EmailChangeFinalize moves the account to the new address once the code matches.
+// The old address is told about the change.
+func (u *AccountUsecase) EmailChangeFinalize(ctx context.Context, accountID types.ID, changeID types.CacheID, code string) error {
+	exist, err := u.emailChangeRepo.Exist(ctx, changeID)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking email change existence", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	if !exist {
+		return account.EmailChangeDoesNotExist
+	}
+
+	change, err := u.emailChangeRepo.Get(ctx, changeID)
+	if err != nil {
+		log.ErrorLogger.Error("error at getting email change", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	if change.AccountID != accountID {
+		return account.EmailChangeDoesNotExist
+	}
+
+	if subtle.ConstantTimeCompare(change.VerificationCode, hashVerificationCode(code)) != 1 {
+		change.Attempts++
+		if change.Attempts >= u.config.MaxTwoFactorAttempts {
+			if err := u.emailChangeRepo.Delete(ctx, changeID); err != nil {
+				log.ErrorLogger.Error("error at deleting email change", "error", err.Error(), "account_id", accountID)
+				return errors.NewServerError()
+			}
+
+			log.WarningLogger.Warn("email change dropped after too many invalid codes", "account_id", accountID)
+			return account.AuthTooManyAttempts
+		}
+
+		if err := u.emailChangeRepo.Create(ctx, change); err != nil {
+			log.ErrorLogger.Error("error at saving email change", "error", err.Error(), "account_id", accountID)
+			return errors.NewServerError()
+		}
+
+		return account.AuthInvalidVerificationCode
+	}
+
+	acc, err := u.Read(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.emailChangeRepo.Delete(ctx, changeID); err != nil {
+		log.ErrorLogger.Error("error at deleting email change", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	// the address could have been taken while the code was on its way
+	exist, err = u.accountRepo.ExistByEmail(ctx, change.Email)
+	if err != nil {
+		log.ErrorLogger.Error("error checking user existence by email", "error", err.Error(), "email", change.Email)
+		return errors.NewServerError()
+	}
+
+	if exist {
+		return account.AuthEmailExist
+	}
+
+	if err := u.accountRepo.UpdateEmail(ctx, accountID, change.Email); err != nil {
+		log.ErrorLogger.Error("error at updating email", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	notify(ctx, u.mailer, acc.Email, mail.TemplateEmailChanged, map[string]any{
+		"Username": acc.Username,
+		"Email":    change.Email,
+		"Time":     time.Now(),
+	})
+
+	log.InfoLogger.Info("email changed for account", "account_id", accountID)
+	return nil
+}
+
+// UsernameChangeInit checks that the new username is free and starts the login with
+// the current password, the attempt counts against the username like a login does.
+func (u *AccountUsecase) UsernameChangeInit(ctx context.Context, accountID types.ID, newUsername string,
+	message []byte) ([]byte, types.CacheID, error) {
+	if err := u.checkUsername(ctx, newUsername); err != nil {
+		return nil, types.CacheID(""), err
+	}
+
+	acc, err := u.Read(ctx, accountID)
+	if err != nil {
+		return nil, types.CacheID(""), err
+	}
+
+	if err := u.countPasswordAttempt(ctx, acc.Username); err != nil {
+		return nil, types.CacheID(""), err
+	}
+
+	response, state, err := u.opaqueServer.LoginInit(message, acc.OpaqueRecord, acc.OpaqueCredentialID, acc.Username, acc.OpaqueKeyVersion)
+	if err != nil {
+		log.ErrorLogger.Error("error at username change login initiation", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	changeID, err := generateRandomID()
+	if err != nil {
+		log.ErrorLogger.Error("error generating username change id", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	change := entity.UsernameChange{
+		CacheEntity: base.CacheEntity{ID: types.CacheID(changeID), Duration: u.changeDuration()},
+		AccountID:   accountID,
+		Username:    acc.Username,
+		NewUsername: newUsername,
+		AKEState:    state,
+	}
+
+	if err := u.usernameChangeRepo.Create(ctx, change); err != nil {
+		log.ErrorLogger.Error("error at saving username change", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	return response, change.ID, nil
+}
+
+// UsernameChangeVerify finishes the login and answers the registration request the
+// client made with the same password. The wrapped vault key is returned so the client
+// can wrap it again under the export key of the new registration.
+func (u *AccountUsecase) UsernameChangeVerify(ctx context.Context, accountID types.ID, changeID types.CacheID,
+	loginMessage, registrationMessage []byte) ([]byte, []byte, error) {
+	change, err := u.getUsernameChange(ctx, accountID, changeID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if change.Verified {
+		return nil, nil, account.UsernameChangeDoesNotExist
+	}
+
+	if _, err := u.opaqueServer.LoginFinalize(loginMessage, change.AKEState); err != nil {
+		if err := u.usernameChangeRepo.Delete(ctx, changeID); err != nil {
+			log.ErrorLogger.Error("error at deleting username change", "error", err.Error(), "account_id", accountID)
+			return nil, nil, errors.NewServerError()
+		}
+
+		log.WarningLogger.Warn("username change rejected because of invalid password", "account_id", accountID)
+		return nil, nil, account.AuthInvalidPassword
+	}
+
+	if err := u.passwordProven(ctx, change.Username); err != nil {
+		return nil, nil, err
+	}
+
+	acc, err := u.Read(ctx, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response, opaqueRegistrationID, err := u.opaqueServer.RegisterInit(registrationMessage, accountCredentialID(acc))
+	if err != nil {
+		log.ErrorLogger.Error("error at username change registration initiation", "error", err.Error(), "account_id", accountID)
+		return nil, nil, errors.NewServerError()
+	}
+
+	change.Verified = true
+	change.AKEState = nil
+	change.CredentialID = opaqueRegistrationID
+	change.Duration = u.changeDuration()
+
+	if err := u.usernameChangeRepo.Create(ctx, change); err != nil {
+		log.ErrorLogger.Error("error at saving username change", "error", err.Error(), "account_id", accountID)
+		return nil, nil, errors.NewServerError()
+	}
+
+	return response, acc.EncryptedVaultKey, nil
+}
+
+// UsernameChangeFinalize renames the account and stores the record registered under
+// the new username in the same statement. It returns the new username. The vault key
+// wrapped under the new export key is required, the vault could not be opened after
+// the rename without it.
+func (u *AccountUsecase) UsernameChangeFinalize(ctx context.Context, accountID types.ID, changeID types.CacheID,
+	message, encryptedVaultKey []byte) (string, error) {
+	if len(encryptedVaultKey) == 0 {
+		return "", account.UsernameChangeVaultKeyMissing
+	}
+
+	change, err := u.getUsernameChange(ctx, accountID, changeID)
+	if err != nil {
+		return "", err
+	}
+
+	if !change.Verified {
+		return "", account.UsernameChangeDoesNotExist
+	}
+
+	if err := u.checkUsername(ctx, change.NewUsername); err != nil {
+		return "", err
+	}
+
+	opaqueRecord, credentialID, keyVersion, err := u.opaqueServer.RegisterFinalize(message, change.CredentialID, change.NewUsername)
+	if err != nil {
+		log.ErrorLogger.Error("error at username change registration finalization", "error", err.Error(), "account_id", accountID)
+		return "", errors.NewServerError()
+	}
+
+	err = u.accountRepo.UpdateUsername(ctx, accountID, change.NewUsername, opaqueRecord, credentialID, encryptedVaultKey, keyVersion)
+	if err != nil {
+		log.ErrorLogger.Error("error at updating username", "error", err.Error(), "account_id", accountID)
+		return "", errors.NewServerError()
+	}
+
+	if err := u.usernameChangeRepo.Delete(ctx, changeID); err != nil {
+		log.ErrorLogger.Error("error at deleting username change", "error", err.Error(), "account_id", accountID)
+		return "", errors.NewServerError()
+	}
+
+	acc, err := u.Read(ctx, accountID)
+	if err != nil {
+		return "", err
+	}
+
+	notify(ctx, u.mailer, acc.Email, mail.TemplateUsernameChanged, map[string]any{
+		"Username": change.NewUsername,
+		"Other":    change.Username,
+		"Time":     time.Now(),
+	})
+
+	log.InfoLogger.Info("username changed for account", "account_id", accountID)
+	return change.NewUsername, nil
+}
+
+// DeleteInit starts the login that proves the master password before the account
+// is deleted, the attempt counts against the username like a login does.
+func (u *AccountUsecase) DeleteInit(ctx context.Context, accountID types.ID, message []byte) ([]byte, types.CacheID, error) {
+	acc, err := u.Read(ctx, accountID)
+	if err != nil {
+		return nil, types.CacheID(""), err
+	}
+
+	if err := u.countPasswordAttempt(ctx, acc.Username); err != nil {
+		return nil, types.CacheID(""), err
+	}
+
+	response, state, err := u.opaqueServer.LoginInit(message, acc.OpaqueRecord, acc.OpaqueCredentialID, acc.Username, acc.OpaqueKeyVersion)
+	if err != nil {
+		log.ErrorLogger.Error("error at account deletion login initiation", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	deletionID, err := generateRandomID()
+	if err != nil {
+		log.ErrorLogger.Error("error generating account deletion id", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	unlock := entity.VaultUnlock{
+		CacheEntity: base.CacheEntity{ID: types.CacheID(deletionID), Duration: u.changeDuration()},
+		AccountID:   accountID,
+		AKEState:    state,
+	}
+
+	if err := u.vaultUnlockRepo.Create(ctx, unlock); err != nil {
+		log.ErrorLogger.Error("error at saving account deletion", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	return response, unlock.ID, nil
+}
+
+// Delete removes the account once the KE3 message proves the password. Every group the
+// account owns is either handed over to the member in successors or, for a zero id,
+// deleted. The vault items of the account are deleted with it and every session is
+// revoked.
+func (u *AccountUsecase) Delete(ctx context.Context, accountID types.ID, deletionID types.CacheID, message []byte,
+	successors map[types.ID]types.ID) error {
+	exist, err := u.vaultUnlockRepo.Exist(ctx, deletionID)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking account deletion existence", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	if !exist {
+		return account.VaultUnlockDoesNotExist
+	}
+
+	unlock, err := u.vaultUnlockRepo.Get(ctx, deletionID)
+	if err != nil {
+		log.ErrorLogger.Error("error at getting account deletion", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	if unlock.AccountID != accountID {
+		return account.VaultUnlockDoesNotExist
+	}
+
+	if err := u.vaultUnlockRepo.Delete(ctx, deletionID); err != nil {
+		log.ErrorLogger.Error("error at deleting account deletion", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	if _, err := u.opaqueServer.LoginFinalize(message, unlock.AKEState); err != nil {
+		log.WarningLogger.Warn("account deletion rejected because of invalid password", "account_id", accountID)
+		return account.AuthInvalidPassword
+	}
+
+	acc, err := u.Read(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.passwordProven(ctx, acc.Username); err != nil {
+		return err
+	}
+
+	handovers, err := u.handOverGroups(ctx, accountID, successors)
+	if err != nil {
+		return err
+	}
+
+	// the groups are handed over in the transaction the account is deleted in, so a
+	// failure leaves both the account and its groups as they were
+	if err := u.accountRepo.DeleteWithGroups(ctx, accountID, handovers); err != nil {
+		log.ErrorLogger.Error("error at deleting account", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	sessions, err := u.sessionRepo.ReadByAccount(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading sessions", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	for _, session := range sessions {
+		if err := u.sessionRepo.Delete(ctx, accountID, session.ID); err != nil {
+			log.ErrorLogger.Error("error at revoking session", "error", err.Error(), "account_id", accountID)
+			return errors.NewServerError()
+		}
+	}
+
+	notify(ctx, u.mailer, acc.Email, mail.TemplateAccountDeleted, map[string]any{
+		"Username": acc.Username,
+		"Time":     time.Now(),
+	})
+
+	log.WarningLogger.Warn("account deleted", "account_id", accountID)
+	return nil
+}
+
+// handOverGroups checks the decision about every group the account owns and returns
+// the successor of each, a zero successor deletes the group.
+func (u *AccountUsecase) handOverGroups(ctx context.Context, accountID types.ID,
+	successors map[types.ID]types.ID) (map[types.ID]types.ID, error) {
+	groups, err := u.ReadOwnedGroups(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	handovers := make(map[types.ID]types.ID, len(groups))
+	for _, group := range groups {
+		successorID, ok := successors[group.ID]
+		if !ok {
+			return nil, account.AccountGroupDecisionMissing
+		}
+
+		handovers[group.ID] = successorID
+		if successorID == 0 {
+			continue
+		}
+
+		if successorID == accountID || !isMember(group.Members, successorID) {
+			return nil, account.GroupInvalidNewOwner
+		}
+
+		successorGroups, err := u.ReadOwnedGroups(ctx, successorID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, successorGroup := range successorGroups {
+			if successorGroup.Name == group.Name {
+				return nil, account.GroupNewOwnerNameTaken
+			}
+		}
+	}
+
+	return handovers, nil
+}
+
+func (u *AccountUsecase) checkUsername(ctx context.Context, username string) error {
+	exist, err := u.accountRepo.ExistByUsername(ctx, username)
+	if err != nil {
+		log.ErrorLogger.Error("error checking user existence by username", "error", err.Error(), "username", username)
+		return errors.NewServerError()
+	}
+
+	if exist {
+		return account.AuthUsernameExist
+	}
+
+	return nil
+}
+
+func (u *AccountUsecase) getUsernameChange(ctx context.Context, accountID types.ID, changeID types.CacheID) (entity.UsernameChange, error) {
+	exist, err := u.usernameChangeRepo.Exist(ctx, changeID)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking username change existence", "error", err.Error())
+		return entity.UsernameChange{}, errors.NewServerError()
+	}
+
+	if !exist {
+		return entity.UsernameChange{}, account.UsernameChangeDoesNotExist
+	}
+
+	change, err := u.usernameChangeRepo.Get(ctx, changeID)
+	if err != nil {
+		log.ErrorLogger.Error("error at getting username change", "error", err.Error())
+		return entity.UsernameChange{}, errors.NewServerError()
+	}
+
+	if change.AccountID != accountID {
+		return entity.UsernameChange{}, account.UsernameChangeDoesNotExist
+	}
+
+	return change, nil
+}
+
+func (u *AccountUsecase) changeDuration() time.Duration {
+	return time.Minute * time.Duration(u.config.TwoFactorDuration)
+}
+
+func isMember(members []entity.Account, accountID types.ID) bool {
+	for _, member := range members {
+		if member.Entity.ID == accountID {
+			return true
+		}
+	}
+
+	return false
+}
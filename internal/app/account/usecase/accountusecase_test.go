@@ -0,0 +1,286 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/seed"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	bytemareOpaque "github.com/bytemare/opaque"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountUsecase_UpdateProfile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	acc := createEmergencyAccessAccount(t, "profile_update_user")
+	u := setupAccountUsecase()
+
+	err := u.UpdateProfile(ctx, acc.Entity.ID, "Updated", "Profile")
+	require.NoError(t, err)
+
+	updated, err := u.Read(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, "Updated", updated.FirstName)
+	require.Equal(t, "Profile", updated.LastName)
+}
+
+func TestAccountUsecase_EmailChange(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	acc := createEmergencyAccessAccount(t, "email_change_user")
+	u := setupAccountUsecase()
+
+	_, err := u.EmailChangeInit(ctx, acc.Entity.ID, seed.AccountJohnDoe.Email)
+	require.ErrorIs(t, err, account.AuthEmailExist)
+
+	newEmail := "email_change_user_new@example.com"
+	changeID, err := u.EmailChangeInit(ctx, acc.Entity.ID, newEmail)
+	require.NoError(t, err)
+	code := verificationCode(t, newEmail)
+
+	// a change of another account is not visible
+	err = u.EmailChangeFinalize(ctx, seed.AccountJohnDoe.Entity.ID, changeID, code)
+	require.ErrorIs(t, err, account.EmailChangeDoesNotExist)
+
+	err = u.EmailChangeFinalize(ctx, acc.Entity.ID, changeID, "wrong code")
+	require.ErrorIs(t, err, account.AuthInvalidVerificationCode)
+
+	err = u.EmailChangeFinalize(ctx, acc.Entity.ID, changeID, code)
+	require.NoError(t, err)
+
+	updated, err := u.Read(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, newEmail, updated.Email)
+
+	// the old address is told about the change
+	require.NotEmpty(t, mailer.Messages(acc.Email))
+
+	err = u.EmailChangeFinalize(ctx, acc.Entity.ID, changeID, code)
+	require.ErrorIs(t, err, account.EmailChangeDoesNotExist)
+}
+
+func TestAccountUsecase_EmailChangeAttempts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	acc := createEmergencyAccessAccount(t, "email_attempts_user")
+	u := setupAccountUsecase()
+
+	changeID, err := u.EmailChangeInit(ctx, acc.Entity.ID, "email_attempts_user_new@example.com")
+	require.NoError(t, err)
+
+	for range conf.MaxTwoFactorAttempts - 1 {
+		err = u.EmailChangeFinalize(ctx, acc.Entity.ID, changeID, "wrong code")
+		require.ErrorIs(t, err, account.AuthInvalidVerificationCode)
+	}
+
+	err = u.EmailChangeFinalize(ctx, acc.Entity.ID, changeID, "wrong code")
+	require.ErrorIs(t, err, account.AuthTooManyAttempts)
+
+	err = u.EmailChangeFinalize(ctx, acc.Entity.ID, changeID, "wrong code")
+	require.ErrorIs(t, err, account.EmailChangeDoesNotExist)
+}
+
+func TestAccountUsecase_UsernameChange(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	password := []byte("strong-password")
+	acc := createPasswordAccount(t, "username_change_user", string(password))
+	u := setupAccountUsecase()
+
+	_, _, err := u.UsernameChangeInit(ctx, acc.Entity.ID, seed.AccountJohnDoe.Username, []byte("ke1"))
+	require.ErrorIs(t, err, account.AuthUsernameExist)
+
+	// ---------- fresh login under the current username ----------
+	loginClient := newOpaqueClient(t)
+	ke2Message, changeID, err := u.UsernameChangeInit(
+		ctx, acc.Entity.ID, "username_change_renamed", loginClient.LoginInit(password).Serialize(),
+	)
+	require.NoError(t, err)
+
+	ke2, err := loginClient.Deserialize.KE2(ke2Message)
+	require.NoError(t, err)
+
+	ke3, _, err := loginClient.LoginFinish(ke2, bytemareOpaque.ClientLoginFinishOptions{
+		ClientIdentity: []byte(acc.Username),
+		ServerIdentity: []byte(conf.Opaque.ServerID),
+	})
+	require.NoError(t, err)
+
+	// ---------- registration under the new username ----------
+	registrationClient := newOpaqueClient(t)
+	registrationRequest := registrationClient.RegistrationInit(password).Serialize()
+
+	_, err = u.UsernameChangeFinalize(ctx, acc.Entity.ID, changeID, []byte("record"), []byte("key"))
+	require.ErrorIs(t, err, account.UsernameChangeDoesNotExist)
+
+	registrationResponse, encryptedVaultKey, err := u.UsernameChangeVerify(
+		ctx, acc.Entity.ID, changeID, ke3.Serialize(), registrationRequest,
+	)
+	require.NoError(t, err)
+	require.Equal(t, acc.EncryptedVaultKey, encryptedVaultKey)
+
+	response, err := registrationClient.Deserialize.RegistrationResponse(registrationResponse)
+	require.NoError(t, err)
+
+	record, _ := registrationClient.RegistrationFinalize(response, bytemareOpaque.ClientRegistrationFinalizeOptions{
+		ClientIdentity: []byte("username_change_renamed"),
+		ServerIdentity: []byte(conf.Opaque.ServerID),
+	})
+
+	_, err = u.UsernameChangeFinalize(ctx, acc.Entity.ID, changeID, record.Serialize(), nil)
+	require.ErrorIs(t, err, account.UsernameChangeVaultKeyMissing)
+
+	newVaultKey := []byte("vault key wrapped under the new export key")
+	username, err := u.UsernameChangeFinalize(ctx, acc.Entity.ID, changeID, record.Serialize(), newVaultKey)
+	require.NoError(t, err)
+	require.Equal(t, "username_change_renamed", username)
+
+	// ---------- verify ----------
+	updated, err := u.Read(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, "username_change_renamed", updated.Username)
+	require.Equal(t, newVaultKey, updated.EncryptedVaultKey)
+
+	_, err = u.UsernameChangeFinalize(ctx, acc.Entity.ID, changeID, record.Serialize(), newVaultKey)
+	require.ErrorIs(t, err, account.UsernameChangeDoesNotExist)
+}
+
+func TestAccountUsecase_PasswordLockout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	acc := createPasswordAccount(t, "account_lockout_user", "strong-password")
+	u := setupAccountUsecase()
+
+	// the username change and the deletion count their guesses like a login does
+	for attempt := range conf.MaxLoginAttempts {
+		ke1 := newOpaqueClient(t).LoginInit([]byte("guess")).Serialize()
+
+		var err error
+		if attempt%2 == 0 {
+			_, _, err = u.DeleteInit(ctx, acc.Entity.ID, ke1)
+		} else {
+			_, _, err = u.UsernameChangeInit(ctx, acc.Entity.ID, "account_lockout_renamed", ke1)
+		}
+		require.NoError(t, err)
+	}
+
+	ke1 := newOpaqueClient(t).LoginInit([]byte("strong-password")).Serialize()
+	_, _, err := u.DeleteInit(ctx, acc.Entity.ID, ke1)
+	require.ErrorIs(t, err, account.AuthAccountLocked)
+
+	_, _, err = u.UsernameChangeInit(ctx, acc.Entity.ID, "account_lockout_renamed", ke1)
+	require.ErrorIs(t, err, account.AuthAccountLocked)
+}
+
+func TestAccountUsecase_Delete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	password := []byte("strong-password")
+	acc := createPasswordAccount(t, "delete_account_owner", string(password))
+	member := createEmergencyAccessAccount(t, "delete_account_member")
+	stranger := createEmergencyAccessAccount(t, "delete_account_stranger")
+
+	groupRepo := repository.NewGroupRepository(pgTestSuite.db)
+	shared := entity.Group{Name: "shared group", Owner: acc}
+	require.NoError(t, groupRepo.Create(ctx, &shared))
+	require.NoError(t, groupRepo.AddAccounts(ctx, shared.ID, []entity.Account{acc}, entity.GroupRoleOwner))
+	require.NoError(t, groupRepo.AddAccounts(ctx, shared.ID, []entity.Account{member}, entity.GroupRoleEditor))
+
+	private := entity.Group{Name: "private group", Owner: acc}
+	require.NoError(t, groupRepo.Create(ctx, &private))
+	require.NoError(t, groupRepo.AddAccounts(ctx, private.ID, []entity.Account{acc}, entity.GroupRoleOwner))
+
+	u := setupAccountUsecase()
+
+	login := func() ([]byte, types.CacheID) {
+		client := newOpaqueClient(t)
+		ke2Message, deletionID, err := u.DeleteInit(ctx, acc.Entity.ID, client.LoginInit(password).Serialize())
+		require.NoError(t, err)
+
+		ke2, err := client.Deserialize.KE2(ke2Message)
+		require.NoError(t, err)
+
+		ke3, _, err := client.LoginFinish(ke2, bytemareOpaque.ClientLoginFinishOptions{
+			ClientIdentity: []byte(acc.Username),
+			ServerIdentity: []byte(conf.Opaque.ServerID),
+		})
+		require.NoError(t, err)
+
+		return ke3.Serialize(), deletionID
+	}
+
+	testcases := []struct {
+		name       string
+		successors map[types.ID]types.ID
+		err        error
+	}{
+		{
+			name:       "decision missing for a group",
+			successors: map[types.ID]types.ID{shared.ID: member.Entity.ID},
+			err:        account.AccountGroupDecisionMissing,
+		},
+		{
+			name:       "successor is not a member",
+			successors: map[types.ID]types.ID{shared.ID: stranger.Entity.ID, private.ID: 0},
+			err:        account.GroupInvalidNewOwner,
+		},
+		{
+			name:       "delete successfully",
+			successors: map[types.ID]types.ID{shared.ID: member.Entity.ID, private.ID: 0},
+		},
+	}
+
+	// the cases run in order since the last one deletes the account
+	for _, tc := range testcases {
+		ke3, deletionID := login()
+		err := u.Delete(ctx, acc.Entity.ID, deletionID, ke3, tc.successors)
+		if tc.err != nil {
+			require.ErrorIs(t, err, tc.err, tc.name)
+		} else {
+			require.NoError(t, err, tc.name)
+		}
+	}
+
+	_, err := u.Read(ctx, acc.Entity.ID)
+	require.Error(t, err)
+
+	groups, err := groupRepo.ReadByOwner(ctx, member.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, shared.ID, groups[0].ID)
+
+	query := `SELECT EXISTS (SELECT 1 FROM groups WHERE id = $1)`
+	var exist bool
+	err = pgTestSuite.db.QueryRow(ctx, query, private.ID).Scan(&exist)
+	require.NoError(t, err)
+	require.False(t, exist)
+
+	_, err = repository.NewAccountRepository(pgTestSuite.db).ReadByID(ctx, acc.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func setupAccountUsecase() usecase.AccountUsecase {
+	aRepo := repository.NewAccountRepository(pgTestSuite.db)
+	gRepo := repository.NewGroupRepository(pgTestSuite.db)
+	ecRepo := repository.NewEmailChangeRepository(redisClient)
+	ucRepo := repository.NewUsernameChangeRepository(redisClient)
+	vuRepo := repository.NewVaultUnlockRepository(redisClient)
+	sRepo := repository.NewSessionRepository(redisClient)
+	atRepo := repository.NewAttemptRepository(redisClient)
+	opqaue, err := opaque.New(conf)
+	if err != nil {
+		panic(err)
+	}
+
+	return usecase.NewAccountUsecase(aRepo, gRepo, ecRepo, ucRepo, vuRepo, sRepo, atRepo, opqaue, mailer, conf)
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/param"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/jackc/pgx/v5"
+)
+
+// AdminUsecase backs the operator commands of cpmadmin. Locking an account reuses the
+// lockout of failed logins, so logins are refused until the lock runs out, and it
+// signs the account out of its sessions and access tokens.
+type AdminUsecase struct {
+	config          *config.Config
+	accountRepo     repository.AccountRepository
+	attemptRepo     repository.AttemptRepository
+	sessionRepo     repository.SessionRepository
+	accessTokenRepo repository.AccessTokenRepository
+}
+
+func NewAdminUsecase(aRepo repository.AccountRepository, attRepo repository.AttemptRepository,
+	sRepo repository.SessionRepository, atRepo repository.AccessTokenRepository, config *config.Config) AdminUsecase {
+	return AdminUsecase{
+		config:          config,
+		accountRepo:     aRepo,
+		attemptRepo:     attRepo,
+		sessionRepo:     sRepo,
+		accessTokenRepo: atRepo,
+	}
+}
+
+func (u *AdminUsecase) ReadAccounts(ctx context.Context, params param.ReadAccountParams) ([]entity.Account, int, error) {
+	accounts, count, err := u.accountRepo.Read(ctx, params)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading accounts", "error", err.Error())
+		return nil, 0, errors.NewServerError()
+	}
+
+	return accounts, count, nil
+}
+
+// LockedFor is how long logins of the account are still refused, zero when it is not
+// locked.
+func (u *AdminUsecase) LockedFor(ctx context.Context, username string) (time.Duration, error) {
+	lockedFor, err := u.attemptRepo.LockedFor(ctx, entity.AttemptScopeUsername, username)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking lock", "error", err.Error(), "username", username)
+		return 0, errors.NewServerError()
+	}
+
+	return lockedFor, nil
+}
+
+func (u *AdminUsecase) LockAccount(ctx context.Context, username string, duration time.Duration) error {
+	acc, err := u.readAccount(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if err := u.attemptRepo.Lock(ctx, entity.AttemptScopeUsername, username, duration); err != nil {
+		log.ErrorLogger.Error("error at locking account", "error", err.Error(), "username", username)
+		return errors.NewServerError()
+	}
+
+	sessions, err := u.sessionRepo.ReadByAccount(ctx, acc.Entity.ID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading sessions", "error", err.Error(), "account_id", acc.Entity.ID)
+		return errors.NewServerError()
+	}
+
+	for _, session := range sessions {
+		if err := u.sessionRepo.Delete(ctx, acc.Entity.ID, session.ID); err != nil {
+			log.ErrorLogger.Error("error at deleting session", "error", err.Error(), "account_id", acc.Entity.ID)
+			return errors.NewServerError()
+		}
+	}
+
+	tokens, err := u.accessTokenRepo.ReadByAccount(ctx, acc.Entity.ID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading access tokens", "error", err.Error(), "account_id", acc.Entity.ID)
+		return errors.NewServerError()
+	}
+
+	for _, token := range tokens {
+		if err := u.accessTokenRepo.Delete(ctx, token.ID, acc.Entity.ID); err != nil {
+			log.ErrorLogger.Error("error at deleting access token", "error", err.Error(), "account_id", acc.Entity.ID)
+			return errors.NewServerError()
+		}
+	}
+
+	log.InfoLogger.Info("account locked by an operator", "username", username, "duration", duration.String())
+	return nil
+}
+
+// UnlockAccount lifts a lock, whether an operator or failed logins set it.
+func (u *AdminUsecase) UnlockAccount(ctx context.Context, username string) error {
+	if _, err := u.readAccount(ctx, username); err != nil {
+		return err
+	}
+
+	if err := u.attemptRepo.Unlock(ctx, entity.AttemptScopeUsername, username); err != nil {
+		log.ErrorLogger.Error("error at unlocking account", "error", err.Error(), "username", username)
+		return errors.NewServerError()
+	}
+
+	log.InfoLogger.Info("account unlocked by an operator", "username", username)
+	return nil
+}
+
+// RotateTOTPSecrets encrypts every TOTP secret that is not under the active AES key
+// again with it, batchSize accounts at a time. A secret no key decrypts is logged and
+// counted as failed instead of stopping the rotation, so it returns how many secrets
+// were rotated and how many failed.
+func (u *AdminUsecase) RotateTOTPSecrets(ctx context.Context, batchSize int) (int, int, error) {
+	keyring, err := u.config.GetAESKeyring()
+	if err != nil {
+		log.ErrorLogger.Error("error at getting aes keyring", "error", err.Error())
+		return 0, 0, errors.NewServerError()
+	}
+
+	var (
+		afterID types.ID
+		rotated int
+		failed  int
+	)
+	for {
+		accounts, err := u.accountRepo.ReadTOTPSecrets(ctx, afterID, batchSize)
+		if err != nil {
+			log.ErrorLogger.Error("error at reading totp secrets", "error", err.Error(), "after_id", afterID)
+			return rotated, failed, errors.NewServerError()
+		}
+
+		if len(accounts) == 0 {
+			break
+		}
+
+		for _, acc := range accounts {
+			afterID = acc.Entity.ID
+			if keyring.IsActive(acc.TOTPSecret) {
+				continue
+			}
+
+			secret, err := keyring.Decrypt(acc.TOTPSecret)
+			if err != nil {
+				log.ErrorLogger.Error("error at decrypting totp secret", "error", err.Error(), "account_id", acc.Entity.ID)
+				failed++
+				continue
+			}
+
+			encrypted, err := keyring.Encrypt(secret)
+			if err != nil {
+				log.ErrorLogger.Error("error at encrypting totp secret", "error", err.Error(), "account_id", acc.Entity.ID)
+				return rotated, failed, errors.NewServerError()
+			}
+
+			err = u.accountRepo.ReplaceTOTPSecret(ctx, acc.Entity.ID, acc.TOTPSecret, []byte(encrypted))
+			if err == pgx.ErrNoRows {
+				// a new authenticator was enrolled meanwhile, under the active key already
+				continue
+			}
+			if err != nil {
+				log.ErrorLogger.Error("error at replacing totp secret", "error", err.Error(), "account_id", acc.Entity.ID)
+				return rotated, failed, errors.NewServerError()
+			}
+
+			rotated++
+		}
+	}
+
+	log.InfoLogger.Info("totp secrets rotated", "key_id", keyring.ActiveID(), "rotated", rotated, "failed", failed)
+	return rotated, failed, nil
+}
+
+func (u *AdminUsecase) readAccount(ctx context.Context, username string) (entity.Account, error) {
+	acc, err := u.accountRepo.ReadByUsername(ctx, username)
+	if err == pgx.ErrNoRows {
+		return entity.Account{}, account.AccountUsernameDoesNotExist
+	}
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account", "error", err.Error(), "username", username)
+		return entity.Account{}, errors.NewServerError()
+	}
+
+	return acc, nil
+}
@@ -0,0 +1,135 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/param"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/seed"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/encrypt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminUsecase_ReadAccounts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	createEmergencyAccessAccount(t, "admin_read_first")
+	createEmergencyAccessAccount(t, "admin_read_second")
+	u := setupAdminUsecase()
+
+	accounts, count, err := u.ReadAccounts(ctx, param.ReadAccountParams{
+		SearchQuery: types.NewNullString("admin_read_"),
+		Limit:       1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Len(t, accounts, 1)
+	require.Equal(t, "admin_read_first", accounts[0].Username)
+}
+
+func TestAdminUsecase_LockAccount(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	acc := createEmergencyAccessAccount(t, "admin_lock_user")
+	u := setupAdminUsecase()
+
+	sessionUsecase := setupSessionUsecase()
+	require.NoError(t, sessionUsecase.Create(ctx, acc.Entity.ID, "admin_lock_session", "203.0.113.20", ""))
+
+	accessTokenUsecase := setupAccessTokenUsecase()
+	_, _, err := accessTokenUsecase.Create(ctx, acc.Entity.ID, "ci", entity.AccessTokenRead, 30, nil, nil)
+	require.NoError(t, err)
+
+	err = u.LockAccount(ctx, "admin_missing_user", time.Hour)
+	require.ErrorIs(t, err, account.AccountUsernameDoesNotExist)
+
+	err = u.LockAccount(ctx, acc.Username, time.Hour)
+	require.NoError(t, err)
+
+	lockedFor, err := u.LockedFor(ctx, acc.Username)
+	require.NoError(t, err)
+	require.Greater(t, lockedFor, time.Duration(0))
+
+	// the account is signed out everywhere
+	sessions, err := sessionUsecase.Read(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	tokens, err := repository.NewAccessTokenRepository(pgTestSuite.db).ReadByAccount(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+
+	err = u.UnlockAccount(ctx, acc.Username)
+	require.NoError(t, err)
+
+	lockedFor, err = u.LockedFor(ctx, acc.Username)
+	require.NoError(t, err)
+	require.Zero(t, lockedFor)
+}
+
+// the rotation rewrites the secrets of every account, it runs before the parallel
+// tests and puts the seeded secret back, which other tests compare with
+func TestAdminUsecase_RotateTOTPSecrets(t *testing.T) {
+	ctx := context.Background()
+	accRepo := repository.NewAccountRepository(pgTestSuite.db)
+
+	retiredKey := bytes.Repeat([]byte{7}, 32)
+	retired, err := encrypt.NewKeyring("retired", map[string][]byte{"retired": retiredKey})
+	require.NoError(t, err)
+	secret, err := retired.Encrypt("JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+
+	err = accRepo.Create(ctx, entity.Account{
+		Username:     "admin_rotate_user",
+		Email:        "admin_rotate_user@example.com",
+		FirstName:    "Rotate",
+		LastName:     "User",
+		OpaqueRecord: []byte("record"),
+		TOTPSecret:   []byte(secret),
+	})
+	require.NoError(t, err)
+
+	rotationConf := *conf
+	rotationConf.APP.AESRetiredKeys = "retired:" + base64.StdEncoding.EncodeToString(retiredKey)
+	u := setupAdminUsecaseWithConfig(&rotationConf)
+
+	rotated, _, err := u.RotateTOTPSecrets(ctx, 2)
+	require.NoError(t, err)
+	require.Positive(t, rotated)
+	t.Cleanup(func() {
+		require.NoError(t, accRepo.Update(ctx, seed.AccountJohnDoe))
+	})
+
+	acc, err := accRepo.ReadByUsername(ctx, "admin_rotate_user")
+	require.NoError(t, err)
+
+	keyring, err := rotationConf.GetAESKeyring()
+	require.NoError(t, err)
+	require.True(t, keyring.IsActive(acc.TOTPSecret))
+
+	decrypted, err := keyring.Decrypt(acc.TOTPSecret)
+	require.NoError(t, err)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", decrypted)
+}
+
+func setupAdminUsecase() usecase.AdminUsecase {
+	return setupAdminUsecaseWithConfig(conf)
+}
+
+func setupAdminUsecaseWithConfig(conf *config.Config) usecase.AdminUsecase {
+	aRepo := repository.NewAccountRepository(pgTestSuite.db)
+	attRepo := repository.NewAttemptRepository(redisClient)
+	sRepo := repository.NewSessionRepository(redisClient)
+	atRepo := repository.NewAccessTokenRepository(pgTestSuite.db)
+
+	return usecase.NewAdminUsecase(aRepo, attRepo, sRepo, atRepo, conf)
+}
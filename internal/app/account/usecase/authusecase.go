@@ -3,42 +3,72 @@ package usecase
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
 	"encoding/base64"
 	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
 	"time"
 
 	"github.com/TheAmirhosssein/cool-password-manage/config"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/totp"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
-	"github.com/TheAmirhosssein/cool-password-manage/pkg/encrypt"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
 	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
 	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
 )
 
+// recoveryCodeCount is how many recovery codes are issued with every authenticator.
+const recoveryCodeCount = 10
+
 type AuthUsecase struct {
 	accountRepo      repository.AccountRepository
 	twoFactorRepo    repository.TwoFactorRepository
 	registrationRepo repository.RegistrationRepository
+	attemptRepo      repository.AttemptRepository
+	enrollmentRepo   repository.EnrollmentRepository
+	recoveryCodeRepo repository.RecoveryCodeRepository
+	recoveryRepo     repository.AccountRecoveryRepository
+	sessionRepo      repository.SessionRepository
+	vaultUnlockRepo  repository.VaultUnlockRepository
+	invitationRepo   repository.GroupInvitationRepository
+	lockout
 
 	authenticator totp.AuthenticatorAdaptor
 	opaqueServer  opaque.OpaqueService
+	mailer        mail.Mailer
 
 	config *config.Config
 }
 
 func NewAuthUsecase(aRepo repository.AccountRepository, tfRepo repository.TwoFactorRepository,
-	rRepo repository.RegistrationRepository, authenticator totp.AuthenticatorAdaptor,
-	opaqueServer opaque.OpaqueService, config *config.Config) AuthUsecase {
+	rRepo repository.RegistrationRepository, atRepo repository.AttemptRepository, eRepo repository.EnrollmentRepository,
+	rcRepo repository.RecoveryCodeRepository, arRepo repository.AccountRecoveryRepository, sRepo repository.SessionRepository,
+	vuRepo repository.VaultUnlockRepository, giRepo repository.GroupInvitationRepository, authenticator totp.AuthenticatorAdaptor,
+	opaqueServer opaque.OpaqueService, mailer mail.Mailer, config *config.Config) AuthUsecase {
 	return AuthUsecase{
 		accountRepo:      aRepo,
 		twoFactorRepo:    tfRepo,
 		authenticator:    authenticator,
 		opaqueServer:     opaqueServer,
+		mailer:           mailer,
 		registrationRepo: rRepo,
+		attemptRepo:      atRepo,
+		enrollmentRepo:   eRepo,
+		recoveryCodeRepo: rcRepo,
+		recoveryRepo:     arRepo,
+		sessionRepo:      sRepo,
+		vaultUnlockRepo:  vuRepo,
+		invitationRepo:   giRepo,
+		lockout:          lockout{attemptRepo: atRepo, config: config},
 		config:           config,
 	}
 }
@@ -64,14 +94,22 @@ func (u *AuthUsecase) SignUpInit(ctx context.Context, registration entity.Regist
 		return nil, types.CacheID(""), account.AuthEmailExist
 	}
 
-	response, credID, err := u.opaqueServer.RegisterInit(message)
+	response, opaqueRegistrationID, err := u.opaqueServer.RegisterInit(message, opaque.NewCredentialID())
 	if err != nil {
 		log.ErrorLogger.Error("error at registration initiation", "error", err.Error())
 		return nil, types.CacheID(""), errors.NewServerError()
 	}
 
+	code, err := generateVerificationCode()
+	if err != nil {
+		log.ErrorLogger.Error("error at generating verification code", "error", err.Error())
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
 	registration.Duration = time.Minute * time.Duration(u.config.TwoFactorDuration)
-	registration.ID = types.CacheID(base64.RawURLEncoding.EncodeToString(credID))
+	registration.ID = types.CacheID(base64.RawURLEncoding.EncodeToString(opaqueRegistrationID))
+	registration.VerificationCode = hashVerificationCode(code)
+	registration.EmailVerified = false
 
 	err = u.registrationRepo.Create(ctx, registration)
 	if err != nil {
@@ -79,92 +117,306 @@ func (u *AuthUsecase) SignUpInit(ctx context.Context, registration entity.Regist
 		return nil, types.CacheID(""), errors.NewServerError()
 	}
 
+	verificationEmail, err := mail.NewMessage(registration.Email, mail.TemplateEmailVerification, map[string]any{
+		"FirstName": registration.FirstName,
+		"Code":      code,
+		"Minutes":   u.config.TwoFactorDuration,
+	})
+	if err != nil {
+		log.ErrorLogger.Error("error at rendering verification email", "error", err.Error())
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	if err := u.mailer.Send(ctx, verificationEmail); err != nil {
+		log.ErrorLogger.Error("error at sending verification email", "error", err.Error(), "username", registration.Username)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
 	return response, registration.ID, nil
 }
 
-func (u *AuthUsecase) SignUpFinalize(ctx context.Context, message []byte, registrationID types.CacheID) (totp.Authenticator, string, error) {
+// VerifyEmail checks the code mailed by SignUpInit. The registration can not be
+// finalized until its email is verified.
+func (u *AuthUsecase) VerifyEmail(ctx context.Context, registrationID types.CacheID, code string) error {
+	identifier := string(registrationID)
+	if err := u.checkLock(ctx, entity.AttemptScopeEmailVerification, identifier, account.AuthTooManyAttempts); err != nil {
+		return err
+	}
+
+	exist, err := u.registrationRepo.Exist(ctx, registrationID)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking registration existence", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	if !exist {
+		return account.AuthRegistrationDoesNotExist
+	}
+
+	registration, err := u.registrationRepo.Get(ctx, registrationID)
+	if err != nil {
+		log.ErrorLogger.Error("error at getting registration", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	if subtle.ConstantTimeCompare(registration.VerificationCode, hashVerificationCode(code)) != 1 {
+		if err := u.registerFailure(ctx, entity.AttemptScopeEmailVerification, identifier, u.config.MaxTwoFactorAttempts); err != nil {
+			return err
+		}
+		return account.AuthInvalidVerificationCode
+	}
+
+	registration.EmailVerified = true
+	if err := u.registrationRepo.Create(ctx, registration); err != nil {
+		log.ErrorLogger.Error("error at saving registration", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+// SignUpFinalize creates the account. The vault key arrives wrapped under the export
+// key and, when the user asked for a recovery kit, also wrapped under the recovery key.
+// The recovery codes are issued along with the authenticator and returned only once,
+// so a lost authenticator can be replaced without ever re-enrolling first.
+func (u *AuthUsecase) SignUpFinalize(ctx context.Context, message []byte, registrationID types.CacheID,
+	encryptedVaultKey []byte, kit entity.RecoveryKit) (totp.Authenticator, string, []string, error) {
 	registration, err := u.registrationRepo.Get(ctx, registrationID)
 	if err != nil {
 		log.ErrorLogger.Error("error at getting registration", "error", err.Error())
-		return totp.Authenticator{}, "", errors.NewServerError()
+		return totp.Authenticator{}, "", nil, errors.NewServerError()
+	}
+
+	if !registration.EmailVerified {
+		return totp.Authenticator{}, "", nil, account.AuthEmailNotVerified
 	}
 
-	credID, err := base64.RawURLEncoding.DecodeString(string(registrationID))
+	opaqueRegistrationID, err := base64.RawURLEncoding.DecodeString(string(registrationID))
 	if err != nil {
 		log.ErrorLogger.Error("error at converting registration id into byte", "error", err.Error())
-		return totp.Authenticator{}, "", errors.NewServerError()
+		return totp.Authenticator{}, "", nil, errors.NewServerError()
 	}
 
-	opaqueRecord, err := u.opaqueServer.RegisterFinalize(message, credID, registration.Username)
+	opaqueRecord, credentialID, keyVersion, err := u.opaqueServer.RegisterFinalize(message, opaqueRegistrationID, registration.Username)
 	if err != nil {
 		log.ErrorLogger.Error("error at finalizing registration", "error", err.Error())
-		return totp.Authenticator{}, "", errors.NewServerError()
+		return totp.Authenticator{}, "", nil, errors.NewServerError()
 	}
 
 	acc := entity.Account{
-		Username:     registration.Username,
-		Email:        registration.Email,
-		FirstName:    registration.FirstName,
-		LastName:     registration.LastName,
-		OpaqueRecord: opaqueRecord,
+		Username:           registration.Username,
+		Email:              registration.Email,
+		FirstName:          registration.FirstName,
+		LastName:           registration.LastName,
+		OpaqueRecord:       opaqueRecord,
+		OpaqueKeyVersion:   keyVersion,
+		OpaqueCredentialID: credentialID,
+		EncryptedVaultKey:  encryptedVaultKey,
+	}
+
+	if len(kit.Proof) > 0 && len(kit.EncryptedVaultKey) > 0 {
+		acc.RecoveryVerifier = hashRecoveryProof(kit.Proof)
+		acc.RecoveryVaultKey = kit.EncryptedVaultKey
 	}
 
 	authenticator, err := u.authenticator.GenerateQRCode(acc.Username)
 	if err != nil {
 		log.ErrorLogger.Error("error at generating authenticator qr code", "error", err.Error(), "username", acc.Username)
-		return totp.Authenticator{}, "", errors.NewServerError()
+		return totp.Authenticator{}, "", nil, errors.NewServerError()
 	}
 
-	key, err := u.config.GetAESSecretKey()
+	keyring, err := u.config.GetAESKeyring()
 	if err != nil {
-		log.ErrorLogger.Error("error at getting aes secret key", "error", err.Error())
-		return totp.Authenticator{}, "", errors.NewServerError()
+		log.ErrorLogger.Error("error at getting aes keyring", "error", err.Error())
+		return totp.Authenticator{}, "", nil, errors.NewServerError()
 	}
 
-	secret, err := encrypt.EncryptAESSecret(key, authenticator.Secret)
+	secret, err := keyring.Encrypt(authenticator.Secret)
 	if err != nil {
 		log.ErrorLogger.Error("error at encrypting authenticator secret", "error", err.Error(), "username", acc.Username)
-		return totp.Authenticator{}, "", errors.NewServerError()
+		return totp.Authenticator{}, "", nil, errors.NewServerError()
 	}
 
 	acc.TOTPSecret = []byte(secret)
 	err = u.accountRepo.Create(ctx, acc)
 	if err != nil {
 		log.ErrorLogger.Error("error at creating account", "error", err.Error(), "username", acc.Username)
-		return totp.Authenticator{}, "", errors.NewServerError()
+		return totp.Authenticator{}, "", nil, errors.NewServerError()
+	}
+
+	created, err := u.accountRepo.ReadByUsername(ctx, acc.Username)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by username", "error", err.Error(), "username", acc.Username)
+		return totp.Authenticator{}, "", nil, errors.NewServerError()
+	}
+
+	codes, hashes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		log.ErrorLogger.Error("error at generating recovery codes", "error", err.Error(), "username", acc.Username)
+		return totp.Authenticator{}, "", nil, errors.NewServerError()
+	}
+
+	if err := u.recoveryCodeRepo.Replace(ctx, created.Entity.ID, hashes); err != nil {
+		log.ErrorLogger.Error("error at saving recovery codes", "error", err.Error(), "username", acc.Username)
+		return totp.Authenticator{}, "", nil, errors.NewServerError()
+	}
+
+	// The email was verified before the account is created, so the invitations sent to
+	// it belong to the account. Failing to hand them over must not fail the sign-up.
+	if err := u.invitationRepo.AssignEmail(ctx, created.Email, created.Entity.ID, time.Now()); err != nil {
+		log.ErrorLogger.Error("error at assigning group invitations", "error", err.Error(), "username", acc.Username)
+	}
+
+	return authenticator, acc.Username, codes, nil
+}
+
+// LoginInit counts every attempt against the username from that ip and against the ip,
+// since a wrong password can not be detected by the server during an OPAQUE login. The
+// attempt is taken back once the user passes the two factor step, so only the failed
+// logins lock out. Keying the username lock on the ip keeps others from locking an
+// account out.
+func (u *AuthUsecase) LoginInit(ctx context.Context, message []byte, username, ip string) ([]byte, error) {
+	message2, _, _, err := u.loginInit(ctx, message, username, ip)
+	return message2, err
+}
+
+// APILoginInit starts the login of a client of the JSON API. Unlike LoginInit it keeps
+// the AKE state, so APILoginFinalize can check the KE3 message before the two factor.
+func (u *AuthUsecase) APILoginInit(ctx context.Context, message []byte, username, ip string) ([]byte, types.CacheID, error) {
+	message2, state, acc, err := u.loginInit(ctx, message, username, ip)
+	if err != nil {
+		return nil, types.CacheID(""), err
+	}
+
+	loginID, err := generateRandomID()
+	if err != nil {
+		log.ErrorLogger.Error("error generating login id", "error", err.Error(), "username", username)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	login := entity.VaultUnlock{
+		CacheEntity: base.CacheEntity{
+			ID:       types.CacheID(loginID),
+			Duration: time.Minute * time.Duration(u.config.TwoFactorDuration),
+		},
+		AccountID: acc.Entity.ID,
+		AKEState:  state,
+	}
+
+	if err := u.vaultUnlockRepo.Create(ctx, login); err != nil {
+		log.ErrorLogger.Error("error at saving login", "error", err.Error(), "username", username)
+		return nil, types.CacheID(""), errors.NewServerError()
 	}
 
-	return authenticator, acc.Username, nil
+	return message2, login.ID, nil
 }
 
-func (u *AuthUsecase) LoginInit(ctx context.Context, message []byte, username string) ([]byte, error) {
+// APILoginFinalize checks the KE3 message and starts the two factor of the login. The
+// login can be finalized only once.
+func (u *AuthUsecase) APILoginFinalize(ctx context.Context, loginID types.CacheID, message []byte) (entity.TwoFactor, error) {
+	exist, err := u.vaultUnlockRepo.Exist(ctx, loginID)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking login existence", "error", err.Error())
+		return entity.TwoFactor{}, errors.NewServerError()
+	}
+
+	if !exist {
+		return entity.TwoFactor{}, account.AuthLoginDoesNotExist
+	}
+
+	login, err := u.vaultUnlockRepo.Get(ctx, loginID)
+	if err != nil {
+		log.ErrorLogger.Error("error at getting login", "error", err.Error())
+		return entity.TwoFactor{}, errors.NewServerError()
+	}
+
+	if err := u.vaultUnlockRepo.Delete(ctx, loginID); err != nil {
+		log.ErrorLogger.Error("error at deleting login", "error", err.Error(), "account_id", login.AccountID)
+		return entity.TwoFactor{}, errors.NewServerError()
+	}
+
+	if _, err := u.opaqueServer.LoginFinalize(message, login.AKEState); err != nil {
+		log.WarningLogger.Warn("login rejected because of invalid password", "account_id", login.AccountID)
+		return entity.TwoFactor{}, account.AuthInvalidPassword
+	}
+
+	acc, err := u.accountRepo.ReadByID(ctx, login.AccountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", login.AccountID)
+		return entity.TwoFactor{}, errors.NewServerError()
+	}
+
+	return u.CreateTwoFactor(ctx, acc.Username)
+}
+
+// loginInit checks the locks of the ip and the username and answers the KE1 message,
+// it returns the AKE state the KE3 message is checked against.
+func (u *AuthUsecase) loginInit(ctx context.Context, message []byte, username, ip string) ([]byte, []byte, entity.Account, error) {
+	if err := u.checkLock(ctx, entity.AttemptScopeIP, ip, account.AuthTooManyAttempts); err != nil {
+		return nil, nil, entity.Account{}, err
+	}
+
+	// the username lock is set by the admins and by the checks of a logged in user
+	if err := u.checkLock(ctx, entity.AttemptScopeUsername, username, account.AuthAccountLocked); err != nil {
+		return nil, nil, entity.Account{}, err
+	}
+
+	if err := u.checkLock(ctx, entity.AttemptScopeLogin, loginIdentifier(username, ip), account.AuthAccountLocked); err != nil {
+		return nil, nil, entity.Account{}, err
+	}
+
+	if err := u.registerFailure(ctx, entity.AttemptScopeIP, ip, u.config.MaxIPAttempts); err != nil {
+		return nil, nil, entity.Account{}, err
+	}
+
 	existence, err := u.accountRepo.ExistByUsername(ctx, username)
 	if err != nil {
 		log.ErrorLogger.Error("error checking user existence by username", "error", err.Error(), "username", username)
-		return nil, errors.NewServerError()
+		return nil, nil, entity.Account{}, errors.NewServerError()
 	}
 
 	if !existence {
-		return nil, account.AuthInvalidAccount
+		return nil, nil, entity.Account{}, account.AuthInvalidAccount
+	}
+
+	if err := u.registerFailure(ctx, entity.AttemptScopeLogin, loginIdentifier(username, ip), u.config.MaxLoginAttempts); err != nil {
+		return nil, nil, entity.Account{}, err
 	}
 
 	account, err := u.accountRepo.ReadByUsername(ctx, username)
 	if err != nil {
 		log.ErrorLogger.Error("error at reading user by username")
-		return nil, errors.NewServerError()
+		return nil, nil, entity.Account{}, errors.NewServerError()
 	}
 
-	message2, err := u.opaqueServer.LoginInit(message, account.OpaqueRecord, account.Username)
+	message2, state, err := u.opaqueServer.LoginInit(
+		message, account.OpaqueRecord, account.OpaqueCredentialID, account.Username, account.OpaqueKeyVersion,
+	)
 	if err != nil {
 		log.ErrorLogger.Error("error at login initiation", "error", err.Error())
-		return nil, errors.NewServerError()
+		return nil, nil, entity.Account{}, errors.NewServerError()
 	}
 
-	return message2, nil
+	return message2, state, account, nil
+}
+
+// loginIdentifier keys the login attempts of a username on the ip they come from.
+func loginIdentifier(username, ip string) string {
+	return ip + "/" + username
+}
+
+// NeedsRekey tells whether the opaque record of the account was registered under older
+// server keys, the client is then expected to register its password again.
+func (u *AuthUsecase) NeedsRekey(acc entity.Account) bool {
+	return acc.OpaqueKeyVersion != u.opaqueServer.KeyVersion()
 }
 
 func (u *AuthUsecase) CreateTwoFactor(ctx context.Context, username string) (entity.TwoFactor, error) {
-	twoFactorID, err := generateTwoFactorID()
+	if err := u.checkLock(ctx, entity.AttemptScopeUsername, username, account.AuthAccountLocked); err != nil {
+		return entity.TwoFactor{}, err
+	}
+
+	twoFactorID, err := generateRandomID()
 	if err != nil {
 		log.ErrorLogger.Error("error generation two factor id", "error", err.Error(), "username", username)
 		return entity.TwoFactor{}, errors.NewServerError()
@@ -183,7 +435,13 @@ func (u *AuthUsecase) CreateTwoFactor(ctx context.Context, username string) (ent
 	return twoFactor, nil
 }
 
-func (u *AuthUsecase) ValidateTwoFactor(ctx context.Context, twoFactorID types.CacheID, verificationCode string) (entity.Account, error) {
+// ValidateTwoFactor checks the code of the authenticator against the challenge and
+// consumes the challenge once the code is right, so a code can only log in once.
+func (u *AuthUsecase) ValidateTwoFactor(ctx context.Context, twoFactorID types.CacheID, verificationCode, ip string) (entity.Account, error) {
+	if err := u.checkLock(ctx, entity.AttemptScopeIP, ip, account.AuthTooManyAttempts); err != nil {
+		return entity.Account{}, err
+	}
+
 	twoFactorExist, err := u.twoFactorRepo.Exist(ctx, twoFactorID)
 	if err != nil {
 		log.ErrorLogger.Error("error at checking if two factor exist", "error", err.Error())
@@ -200,19 +458,27 @@ func (u *AuthUsecase) ValidateTwoFactor(ctx context.Context, twoFactorID types.C
 		return entity.Account{}, errors.NewServerError()
 	}
 
+	if err := u.checkLock(ctx, entity.AttemptScopeUsername, twoFactor.Username, account.AuthAccountLocked); err != nil {
+		return entity.Account{}, err
+	}
+
+	if err := u.checkLock(ctx, entity.AttemptScopeLogin, loginIdentifier(twoFactor.Username, ip), account.AuthAccountLocked); err != nil {
+		return entity.Account{}, err
+	}
+
 	acc, err := u.accountRepo.ReadByUsername(ctx, twoFactor.Username)
 	if err != nil {
 		log.ErrorLogger.Error("error at reading account username", "error", err.Error(), "username", acc.Username)
 		return entity.Account{}, errors.NewServerError()
 	}
 
-	key, err := u.config.GetAESSecretKey()
+	keyring, err := u.config.GetAESKeyring()
 	if err != nil {
-		log.ErrorLogger.Error("error at getting aes secret key", "error", err.Error())
+		log.ErrorLogger.Error("error at getting aes keyring", "error", err.Error())
 		return entity.Account{}, errors.NewServerError()
 	}
 
-	secret, err := encrypt.DecryptAESSecret(key, acc.TOTPSecret)
+	secret, err := keyring.Decrypt(acc.TOTPSecret)
 	if err != nil {
 		log.ErrorLogger.Error("error at decrypting secret", "error", err.Error(), "username", acc.Username)
 		return entity.Account{}, errors.NewServerError()
@@ -220,13 +486,510 @@ func (u *AuthUsecase) ValidateTwoFactor(ctx context.Context, twoFactorID types.C
 
 	codeValid := u.authenticator.VerifyCode(secret, verificationCode)
 	if !codeValid {
-		return entity.Account{}, account.AuthInvalidVerificationCode
+		return entity.Account{}, u.registerTwoFactorFailure(ctx, twoFactor, ip)
+	}
+
+	if err := u.attemptRepo.Reset(ctx, entity.AttemptScopeLogin, loginIdentifier(twoFactor.Username, ip)); err != nil {
+		log.ErrorLogger.Error("error at resetting login attempts", "error", err.Error(), "username", twoFactor.Username)
+		return entity.Account{}, errors.NewServerError()
 	}
 
+	// the login init was counted against the ip before the password could be checked,
+	// a successful login takes it back so users behind a shared address are not locked
+	if err := u.attemptRepo.Decrement(ctx, entity.AttemptScopeIP, ip); err != nil {
+		log.ErrorLogger.Error("error at taking back ip attempt", "error", err.Error(), "username", twoFactor.Username)
+		return entity.Account{}, errors.NewServerError()
+	}
+
+	if err := u.attemptRepo.Reset(ctx, entity.AttemptScopeTwoFactor, string(twoFactorID)); err != nil {
+		log.ErrorLogger.Error("error at resetting two factor attempts", "error", err.Error(), "username", twoFactor.Username)
+		return entity.Account{}, errors.NewServerError()
+	}
+
+	// the code stays valid for a while, the challenge must not let it log in twice
+	if err := u.twoFactorRepo.Delete(ctx, twoFactorID); err != nil {
+		log.ErrorLogger.Error("error at deleting two factor", "error", err.Error(), "username", twoFactor.Username)
+		return entity.Account{}, errors.NewServerError()
+	}
+
+	notify(ctx, u.mailer, acc.Email, mail.TemplateNewLogin, map[string]any{
+		"Username": acc.Username,
+		"IP":       ip,
+		"Time":     time.Now(),
+	})
+
 	return acc, nil
 }
 
-func generateTwoFactorID() (string, error) {
+// registerTwoFactorFailure counts an invalid code against the two factor entry, the
+// username from the ip and the ip. Once the entry runs out of attempts it is removed, so the
+// user has to go through the login again to get a new one.
+func (u *AuthUsecase) registerTwoFactorFailure(ctx context.Context, twoFactor entity.TwoFactor, ip string) error {
+	if err := u.registerFailure(ctx, entity.AttemptScopeIP, ip, u.config.MaxIPAttempts); err != nil {
+		return err
+	}
+
+	if err := u.registerFailure(ctx, entity.AttemptScopeLogin, loginIdentifier(twoFactor.Username, ip), u.config.MaxLoginAttempts); err != nil {
+		return err
+	}
+
+	window := time.Minute * time.Duration(u.config.AttemptWindow)
+	count, err := u.attemptRepo.Increment(ctx, entity.AttemptScopeTwoFactor, string(twoFactor.ID), window)
+	if err != nil {
+		log.ErrorLogger.Error("error at counting two factor attempts", "error", err.Error(), "username", twoFactor.Username)
+		return errors.NewServerError()
+	}
+
+	if count < int64(u.config.MaxTwoFactorAttempts) {
+		return account.AuthInvalidVerificationCode
+	}
+
+	if err := u.twoFactorRepo.Delete(ctx, twoFactor.ID); err != nil {
+		log.ErrorLogger.Error("error at invalidating two factor", "error", err.Error(), "username", twoFactor.Username)
+		return errors.NewServerError()
+	}
+
+	if err := u.attemptRepo.Reset(ctx, entity.AttemptScopeTwoFactor, string(twoFactor.ID)); err != nil {
+		log.ErrorLogger.Error("error at resetting two factor attempts", "error", err.Error(), "username", twoFactor.Username)
+		return errors.NewServerError()
+	}
+
+	log.WarningLogger.Warn("two factor invalidated after too many attempts", "username", twoFactor.Username, "ip", ip)
+	return account.AuthTwoFactorAttemptsSpent
+}
+
+// EnrollAuthenticatorInit checks the code of the current authenticator, or an unused
+// recovery code when the old device is gone, and returns a new authenticator. The new
+// secret is only stored on the account once EnrollAuthenticatorFinalize confirms it.
+func (u *AuthUsecase) EnrollAuthenticatorInit(ctx context.Context, accountID types.ID, code string) (totp.Authenticator, types.CacheID, error) {
+	identifier := fmt.Sprint(accountID)
+	if err := u.checkLock(ctx, entity.AttemptScopeAuthenticator, identifier, account.AuthTooManyAttempts); err != nil {
+		return totp.Authenticator{}, types.CacheID(""), err
+	}
+
+	acc, err := u.accountRepo.ReadByID(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", accountID)
+		return totp.Authenticator{}, types.CacheID(""), errors.NewServerError()
+	}
+
+	keyring, err := u.config.GetAESKeyring()
+	if err != nil {
+		log.ErrorLogger.Error("error at getting aes keyring", "error", err.Error())
+		return totp.Authenticator{}, types.CacheID(""), errors.NewServerError()
+	}
+
+	secret, err := keyring.Decrypt(acc.TOTPSecret)
+	if err != nil {
+		log.ErrorLogger.Error("error at decrypting secret", "error", err.Error(), "account_id", accountID)
+		return totp.Authenticator{}, types.CacheID(""), errors.NewServerError()
+	}
+
+	verifiedWith := "authenticator code"
+	if !u.authenticator.VerifyCode(secret, code) {
+		used, err := u.recoveryCodeRepo.Use(ctx, accountID, hashRecoveryCode(code))
+		if err != nil {
+			log.ErrorLogger.Error("error at using recovery code", "error", err.Error(), "account_id", accountID)
+			return totp.Authenticator{}, types.CacheID(""), errors.NewServerError()
+		}
+
+		if !used {
+			if err := u.registerFailure(ctx, entity.AttemptScopeAuthenticator, identifier, u.config.MaxTwoFactorAttempts); err != nil {
+				return totp.Authenticator{}, types.CacheID(""), err
+			}
+			return totp.Authenticator{}, types.CacheID(""), account.AuthInvalidVerificationCode
+		}
+
+		verifiedWith = "recovery code"
+		log.WarningLogger.Warn("recovery code used", "account_id", accountID)
+	}
+
+	if err := u.attemptRepo.Reset(ctx, entity.AttemptScopeAuthenticator, identifier); err != nil {
+		log.ErrorLogger.Error("error at resetting authenticator attempts", "error", err.Error(), "account_id", accountID)
+		return totp.Authenticator{}, types.CacheID(""), errors.NewServerError()
+	}
+
+	authenticator, err := u.authenticator.GenerateQRCode(acc.Username)
+	if err != nil {
+		log.ErrorLogger.Error("error at generating authenticator qr code", "error", err.Error(), "account_id", accountID)
+		return totp.Authenticator{}, types.CacheID(""), errors.NewServerError()
+	}
+
+	encryptedSecret, err := keyring.Encrypt(authenticator.Secret)
+	if err != nil {
+		log.ErrorLogger.Error("error at encrypting authenticator secret", "error", err.Error(), "account_id", accountID)
+		return totp.Authenticator{}, types.CacheID(""), errors.NewServerError()
+	}
+
+	enrollmentID, err := generateRandomID()
+	if err != nil {
+		log.ErrorLogger.Error("error generating enrollment id", "error", err.Error(), "account_id", accountID)
+		return totp.Authenticator{}, types.CacheID(""), errors.NewServerError()
+	}
+
+	enrollment := entity.AuthenticatorEnrollment{
+		CacheEntity: base.CacheEntity{
+			ID:       types.CacheID(enrollmentID),
+			Duration: time.Minute * time.Duration(u.config.TwoFactorDuration),
+		},
+		AccountID: accountID,
+		Secret:    []byte(encryptedSecret),
+	}
+
+	if err := u.enrollmentRepo.Create(ctx, enrollment); err != nil {
+		log.ErrorLogger.Error("error at saving authenticator enrollment", "error", err.Error(), "account_id", accountID)
+		return totp.Authenticator{}, types.CacheID(""), errors.NewServerError()
+	}
+
+	log.InfoLogger.Info("authenticator enrollment started", "account_id", accountID, "verified_with", verifiedWith)
+	return authenticator, enrollment.ID, nil
+}
+
+// EnrollAuthenticatorFinalize replaces the totp secret of the account once a code of
+// the new authenticator is valid, and issues a new set of recovery codes.
+func (u *AuthUsecase) EnrollAuthenticatorFinalize(ctx context.Context, accountID types.ID, enrollmentID types.CacheID, code string) ([]string, error) {
+	identifier := fmt.Sprint(accountID)
+	if err := u.checkLock(ctx, entity.AttemptScopeAuthenticator, identifier, account.AuthTooManyAttempts); err != nil {
+		return nil, err
+	}
+
+	exist, err := u.enrollmentRepo.Exist(ctx, enrollmentID)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking enrollment existence", "error", err.Error())
+		return nil, errors.NewServerError()
+	}
+
+	if !exist {
+		return nil, account.AuthEnrollmentDoesNotExist
+	}
+
+	enrollment, err := u.enrollmentRepo.Get(ctx, enrollmentID)
+	if err != nil {
+		log.ErrorLogger.Error("error at getting enrollment", "error", err.Error())
+		return nil, errors.NewServerError()
+	}
+
+	if enrollment.AccountID != accountID {
+		return nil, account.AuthEnrollmentDoesNotExist
+	}
+
+	keyring, err := u.config.GetAESKeyring()
+	if err != nil {
+		log.ErrorLogger.Error("error at getting aes keyring", "error", err.Error())
+		return nil, errors.NewServerError()
+	}
+
+	secret, err := keyring.Decrypt(enrollment.Secret)
+	if err != nil {
+		log.ErrorLogger.Error("error at decrypting secret", "error", err.Error(), "account_id", accountID)
+		return nil, errors.NewServerError()
+	}
+
+	if !u.authenticator.VerifyCode(secret, code) {
+		if err := u.registerFailure(ctx, entity.AttemptScopeAuthenticator, identifier, u.config.MaxTwoFactorAttempts); err != nil {
+			return nil, err
+		}
+		return nil, account.AuthInvalidVerificationCode
+	}
+
+	err = u.accountRepo.Update(ctx, entity.Account{Entity: base.Entity{ID: accountID}, TOTPSecret: enrollment.Secret})
+	if err != nil {
+		log.ErrorLogger.Error("error at updating totp secret", "error", err.Error(), "account_id", accountID)
+		return nil, errors.NewServerError()
+	}
+
+	codes, hashes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		log.ErrorLogger.Error("error at generating recovery codes", "error", err.Error(), "account_id", accountID)
+		return nil, errors.NewServerError()
+	}
+
+	if err := u.recoveryCodeRepo.Replace(ctx, accountID, hashes); err != nil {
+		log.ErrorLogger.Error("error at saving recovery codes", "error", err.Error(), "account_id", accountID)
+		return nil, errors.NewServerError()
+	}
+
+	if err := u.enrollmentRepo.Delete(ctx, enrollmentID); err != nil {
+		log.ErrorLogger.Error("error at deleting enrollment", "error", err.Error(), "account_id", accountID)
+		return nil, errors.NewServerError()
+	}
+
+	if err := u.attemptRepo.Reset(ctx, entity.AttemptScopeAuthenticator, identifier); err != nil {
+		log.ErrorLogger.Error("error at resetting authenticator attempts", "error", err.Error(), "account_id", accountID)
+		return nil, errors.NewServerError()
+	}
+
+	acc, err := u.accountRepo.ReadByID(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", accountID)
+		return nil, errors.NewServerError()
+	}
+
+	notify(ctx, u.mailer, acc.Email, mail.TemplateAuthenticatorChanged, map[string]any{
+		"Username": acc.Username,
+		"Time":     time.Now(),
+	})
+
+	log.InfoLogger.Info("authenticator re-enrolled", "account_id", accountID)
+	return codes, nil
+}
+
+// RecoverInit checks the proof of the recovery key together with a code of the
+// authenticator, or an unused recovery code, and answers the registration request
+// of the new password. The vault key wrapped under the recovery key is returned so
+// the client can wrap it again under the new export key.
+func (u *AuthUsecase) RecoverInit(ctx context.Context, username string, proof []byte, code string,
+	message []byte, ip string) ([]byte, []byte, types.CacheID, error) {
+	if err := u.checkLock(ctx, entity.AttemptScopeIP, ip, account.AuthTooManyAttempts); err != nil {
+		return nil, nil, types.CacheID(""), err
+	}
+
+	if err := u.checkLock(ctx, entity.AttemptScopeRecovery, username, account.AuthAccountLocked); err != nil {
+		return nil, nil, types.CacheID(""), err
+	}
+
+	exist, err := u.accountRepo.ExistByUsername(ctx, username)
+	if err != nil {
+		log.ErrorLogger.Error("error checking user existence by username", "error", err.Error(), "username", username)
+		return nil, nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	if !exist {
+		return nil, nil, types.CacheID(""), u.registerRecoveryFailure(ctx, username, ip)
+	}
+
+	acc, err := u.accountRepo.ReadByUsername(ctx, username)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by username", "error", err.Error(), "username", username)
+		return nil, nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	// accounts without a recovery kit fail the same way as a wrong key
+	if len(acc.RecoveryVerifier) == 0 || subtle.ConstantTimeCompare(acc.RecoveryVerifier, hashRecoveryProof(proof)) != 1 {
+		return nil, nil, types.CacheID(""), u.registerRecoveryFailure(ctx, username, ip)
+	}
+
+	keyring, err := u.config.GetAESKeyring()
+	if err != nil {
+		log.ErrorLogger.Error("error at getting aes keyring", "error", err.Error())
+		return nil, nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	secret, err := keyring.Decrypt(acc.TOTPSecret)
+	if err != nil {
+		log.ErrorLogger.Error("error at decrypting secret", "error", err.Error(), "username", username)
+		return nil, nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	if !u.authenticator.VerifyCode(secret, code) {
+		used, err := u.recoveryCodeRepo.Use(ctx, acc.Entity.ID, hashRecoveryCode(code))
+		if err != nil {
+			log.ErrorLogger.Error("error at using recovery code", "error", err.Error(), "username", username)
+			return nil, nil, types.CacheID(""), errors.NewServerError()
+		}
+
+		if !used {
+			return nil, nil, types.CacheID(""), u.registerRecoveryFailure(ctx, username, ip)
+		}
+
+		log.WarningLogger.Warn("recovery code used", "account_id", acc.Entity.ID)
+	}
+
+	if err := u.attemptRepo.Reset(ctx, entity.AttemptScopeRecovery, username); err != nil {
+		log.ErrorLogger.Error("error at resetting recovery attempts", "error", err.Error(), "username", username)
+		return nil, nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	response, opaqueRegistrationID, err := u.opaqueServer.RegisterInit(message, accountCredentialID(acc))
+	if err != nil {
+		log.ErrorLogger.Error("error at recovery registration initiation", "error", err.Error(), "username", username)
+		return nil, nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	recoveryID, err := generateRandomID()
+	if err != nil {
+		log.ErrorLogger.Error("error generating recovery id", "error", err.Error(), "username", username)
+		return nil, nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	recovery := entity.AccountRecovery{
+		CacheEntity: base.CacheEntity{
+			ID:       types.CacheID(recoveryID),
+			Duration: time.Minute * time.Duration(u.config.TwoFactorDuration),
+		},
+		AccountID:    acc.Entity.ID,
+		Username:     acc.Username,
+		CredentialID: opaqueRegistrationID,
+	}
+
+	if err := u.recoveryRepo.Create(ctx, recovery); err != nil {
+		log.ErrorLogger.Error("error at saving account recovery", "error", err.Error(), "username", username)
+		return nil, nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	return response, acc.RecoveryVaultKey, recovery.ID, nil
+}
+
+// RecoverFinalize stores the new opaque record with the re-wrapped vault key and
+// signs out every session of the account. The recovery kit stays valid, since it
+// still wraps the same vault key.
+func (u *AuthUsecase) RecoverFinalize(ctx context.Context, recoveryID types.CacheID, message, encryptedVaultKey []byte, ip string) error {
+	exist, err := u.recoveryRepo.Exist(ctx, recoveryID)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking account recovery existence", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	if !exist {
+		return account.AuthRecoveryDoesNotExist
+	}
+
+	recovery, err := u.recoveryRepo.Get(ctx, recoveryID)
+	if err != nil {
+		log.ErrorLogger.Error("error at getting account recovery", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	opaqueRecord, credentialID, keyVersion, err := u.opaqueServer.RegisterFinalize(message, recovery.CredentialID, recovery.Username)
+	if err != nil {
+		log.ErrorLogger.Error("error at recovery registration finalization", "error", err.Error(), "account_id", recovery.AccountID)
+		return errors.NewServerError()
+	}
+
+	err = u.accountRepo.UpdateCredentials(ctx, recovery.AccountID, opaqueRecord, credentialID, encryptedVaultKey, keyVersion)
+	if err != nil {
+		log.ErrorLogger.Error("error at updating account credentials", "error", err.Error(), "account_id", recovery.AccountID)
+		return errors.NewServerError()
+	}
+
+	if err := u.recoveryRepo.Delete(ctx, recoveryID); err != nil {
+		log.ErrorLogger.Error("error at deleting account recovery", "error", err.Error(), "account_id", recovery.AccountID)
+		return errors.NewServerError()
+	}
+
+	sessions, err := u.sessionRepo.ReadByAccount(ctx, recovery.AccountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading sessions", "error", err.Error(), "account_id", recovery.AccountID)
+		return errors.NewServerError()
+	}
+
+	for _, session := range sessions {
+		if err := u.sessionRepo.Delete(ctx, recovery.AccountID, session.ID); err != nil {
+			log.ErrorLogger.Error("error at revoking session", "error", err.Error(), "account_id", recovery.AccountID)
+			return errors.NewServerError()
+		}
+	}
+
+	// the user may have locked the login while trying to remember the password
+	if err := u.attemptRepo.Reset(ctx, entity.AttemptScopeUsername, recovery.Username); err != nil {
+		log.ErrorLogger.Error("error at resetting username attempts", "error", err.Error(), "account_id", recovery.AccountID)
+		return errors.NewServerError()
+	}
+
+	if err := u.attemptRepo.Reset(ctx, entity.AttemptScopeLogin, loginIdentifier(recovery.Username, ip)); err != nil {
+		log.ErrorLogger.Error("error at resetting login attempts", "error", err.Error(), "account_id", recovery.AccountID)
+		return errors.NewServerError()
+	}
+
+	acc, err := u.accountRepo.ReadByID(ctx, recovery.AccountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", recovery.AccountID)
+		return errors.NewServerError()
+	}
+
+	notify(ctx, u.mailer, acc.Email, mail.TemplateAccountRecovered, map[string]any{
+		"Username": acc.Username,
+		"IP":       ip,
+		"Time":     time.Now(),
+	})
+
+	log.WarningLogger.Warn("account recovered with recovery kit", "account_id", recovery.AccountID, "ip", ip)
+	return nil
+}
+
+// registerRecoveryFailure counts an invalid recovery against the username and the ip.
+// The attempts are kept apart from the login, so a failed recovery does not lock it.
+func (u *AuthUsecase) registerRecoveryFailure(ctx context.Context, username, ip string) error {
+	if err := u.registerFailure(ctx, entity.AttemptScopeIP, ip, u.config.MaxIPAttempts); err != nil {
+		return err
+	}
+
+	if err := u.registerFailure(ctx, entity.AttemptScopeRecovery, username, u.config.MaxTwoFactorAttempts); err != nil {
+		return err
+	}
+
+	return account.AuthInvalidRecoveryKit
+}
+
+func (u *AuthUsecase) RecoveryCodesLeft(ctx context.Context, accountID types.ID) (int, error) {
+	count, err := u.recoveryCodeRepo.CountUnused(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at counting recovery codes", "error", err.Error(), "account_id", accountID)
+		return 0, errors.NewServerError()
+	}
+
+	return count, nil
+}
+
+// generateVerificationCode returns a six digit code for the email verification.
+func generateVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func hashVerificationCode(code string) []byte {
+	hash := sha256.Sum256([]byte(strings.TrimSpace(code)))
+	return hash[:]
+}
+
+// generateRecoveryCodes returns the codes to show to the user and the hashes to store.
+func generateRecoveryCodes(count int) ([]string, [][]byte, error) {
+	codes := make([]string, 0, count)
+	hashes := make([][]byte, 0, count)
+
+	for range count {
+		bytes := make([]byte, 10)
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, nil, err
+		}
+
+		raw := base32.StdEncoding.EncodeToString(bytes)
+		code := fmt.Sprintf("%s-%s-%s-%s", raw[0:4], raw[4:8], raw[8:12], raw[12:16])
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case, dashes and spaces, so the code can be typed the way
+// it was written down.
+func hashRecoveryCode(code string) []byte {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hash := sha256.Sum256([]byte(normalized))
+	return hash[:]
+}
+
+func hashRecoveryProof(proof []byte) []byte {
+	hash := sha256.Sum256(proof)
+	return hash[:]
+}
+
+// accountCredentialID is the credential ID the account registers under again, the
+// accounts registered before they kept one are given one with their next registration.
+func accountCredentialID(acc entity.Account) []byte {
+	if len(acc.OpaqueCredentialID) == 0 {
+		return opaque.NewCredentialID()
+	}
+	return acc.OpaqueCredentialID
+}
+
+func generateRandomID() (string, error) {
 	characterLength := 16
 	bytes := make([]byte, characterLength)
 	_, err := rand.Read(bytes)
@@ -3,8 +3,11 @@ package usecase_test
 import (
 	"context"
 	"crypto"
+	"crypto/sha256"
 	"log"
 	"os"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,10 +17,12 @@ import (
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/totp"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/seed"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
 	"github.com/TheAmirhosssein/cool-password-manage/pkg/encrypt"
 	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
 	"github.com/TheAmirhosssein/cool-password-manage/pkg/testdocker"
@@ -38,6 +43,7 @@ type postgresTest struct {
 var pgTestSuite postgresTest
 var redisClient *redis.Client
 var conf *config.Config
+var mailer = mail.NewMemoryMailer()
 
 func TestMain(m *testing.M) {
 	ctx := context.Background()
@@ -188,8 +194,8 @@ func TestAuthUsecase_SignUpFinalize(t *testing.T) {
 	initMsg := client.RegistrationInit(password).Serialize()
 
 	reg := entity.Registration{
-		Username:  "new_user",
-		Email:     "new_user@example.com",
+		Username:  "finalize_user",
+		Email:     "finalize_user@example.com",
 		FirstName: "New",
 		LastName:  "User",
 	}
@@ -198,6 +204,13 @@ func TestAuthUsecase_SignUpFinalize(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, resp)
 
+	// a registration can not be finalized before its email is verified
+	_, _, _, err = u.SignUpFinalize(ctx, []byte("invalid-message"), registrationID, nil, entity.RecoveryKit{})
+	require.ErrorIs(t, err, account.AuthEmailNotVerified)
+
+	err = u.VerifyEmail(ctx, registrationID, verificationCode(t, reg.Email))
+	require.NoError(t, err)
+
 	response, err := client.Deserialize.RegistrationResponse(resp)
 	if err != nil {
 		log.Fatalln(err)
@@ -209,6 +222,23 @@ func TestAuthUsecase_SignUpFinalize(t *testing.T) {
 	})
 	message3 := record.Serialize()
 
+	vaultKey := []byte("vault key wrapped under the export key")
+	kit := entity.RecoveryKit{
+		Proof:             []byte("recovery proof"),
+		EncryptedVaultKey: []byte("vault key wrapped under the recovery key"),
+	}
+
+	// the invitations sent to the email before the sign-up belong to the account
+	invitationRepo := repository.NewGroupInvitationRepository(pgTestSuite.db)
+	err = invitationRepo.Create(ctx, &entity.GroupInvitation{
+		Group:     seed.GroupOddFuture,
+		Inviter:   seed.GroupOddFuture.Owner,
+		Email:     reg.Email,
+		Role:      entity.GroupRoleViewer,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
 	testcases := []struct {
 		name           string
 		message        []byte
@@ -240,7 +270,7 @@ func TestAuthUsecase_SignUpFinalize(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			auth, username, err := u.SignUpFinalize(ctx, tc.message, tc.registrationID)
+			auth, username, codes, err := u.SignUpFinalize(ctx, tc.message, tc.registrationID, vaultKey, kit)
 
 			if tc.expectedErr {
 				require.Error(t, err)
@@ -260,11 +290,89 @@ func TestAuthUsecase_SignUpFinalize(t *testing.T) {
 			require.Equal(t, reg.Username, acc.Username)
 			require.Equal(t, reg.Email, acc.Email)
 			require.NotEmpty(t, acc.OpaqueRecord)
+			require.Len(t, acc.OpaqueCredentialID, 64)
 			require.NotEmpty(t, acc.TOTPSecret)
+			require.Equal(t, vaultKey, acc.EncryptedVaultKey)
+			require.Equal(t, kit.EncryptedVaultKey, acc.RecoveryVaultKey)
+			require.NotEqual(t, kit.Proof, acc.RecoveryVerifier)
+
+			// the recovery codes are issued at sign-up, without a re-enrollment
+			require.Len(t, codes, 10)
+			left, err := u.RecoveryCodesLeft(ctx, acc.Entity.ID)
+			require.NoError(t, err)
+			require.Equal(t, len(codes), left)
+
+			invitations, err := invitationRepo.ReadByInvitee(ctx, acc.Entity.ID, time.Now())
+			require.NoError(t, err)
+			require.Len(t, invitations, 1)
+			require.Equal(t, seed.GroupOddFuture.ID, invitations[0].Group.ID)
+			require.Empty(t, invitations[0].Email)
 		})
 	}
 }
 
+func TestAuthUsecase_VerifyEmail(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	client, err := bytemareOpaque.NewClient(&bytemareOpaque.Configuration{
+		OPRF: bytemareOpaque.P256Sha256,
+		AKE:  bytemareOpaque.P256Sha256,
+		Hash: crypto.SHA256,
+		KDF:  crypto.SHA256,
+		MAC:  crypto.SHA256,
+		KSF:  ksf.Argon2id,
+	})
+	require.NoError(t, err)
+
+	u := setupAuthUsecase()
+
+	reg := entity.Registration{Username: "verify_email_user", Email: "verify_email_user@example.com"}
+	_, registrationID, err := u.SignUpInit(ctx, reg, client.RegistrationInit([]byte("strong-password")).Serialize())
+	require.NoError(t, err)
+
+	code := verificationCode(t, reg.Email)
+
+	testcases := []struct {
+		name           string
+		registrationID types.CacheID
+		code           string
+		expectedErr    error
+	}{
+		{
+			name:           "registration does not exist",
+			registrationID: "non-existent-id",
+			code:           code,
+			expectedErr:    account.AuthRegistrationDoesNotExist,
+		},
+		{
+			name:           "invalid code",
+			registrationID: registrationID,
+			code:           "not-the-code",
+			expectedErr:    account.AuthInvalidVerificationCode,
+		},
+		{
+			name:           "success",
+			registrationID: registrationID,
+			code:           code,
+			expectedErr:    nil,
+		},
+	}
+
+	for _, tc := range testcases {
+		err := u.VerifyEmail(ctx, tc.registrationID, tc.code)
+		if tc.expectedErr != nil {
+			require.ErrorIs(t, err, tc.expectedErr, tc.name)
+			continue
+		}
+		require.NoError(t, err, tc.name)
+	}
+
+	saved, err := repository.NewRegistrationRepository(redisClient).Get(ctx, registrationID)
+	require.NoError(t, err)
+	require.True(t, saved.EmailVerified)
+}
+
 func TestAuthUsecase_LoginInit(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -321,7 +429,7 @@ func TestAuthUsecase_LoginInit(t *testing.T) {
 
 			u := setupAuthUsecase()
 
-			resp, err := u.LoginInit(ctx, tc.message, tc.account.Username)
+			resp, err := u.LoginInit(ctx, tc.message, tc.account.Username, "192.0.2.1")
 
 			if tc.expectedErr != nil {
 				require.Error(t, err)
@@ -397,10 +505,11 @@ func TestAuthUsecase_ValidateTwoFactor(t *testing.T) {
 	ctx := context.Background()
 
 	johnDoe := seed.AccountJohnDoe
-	twoFactor := seed.TwoFactorJohnDoe // seeded in `seed.CreateTowFactorSeed`
 
-	// setup usecase with real authenticator
-	u := setupAuthUsecase()
+	// a dedicated redis, the successful check consumes its challenge
+	client := newIsolatedRedis(t)
+	u := setupAuthUsecaseWithRedis(client)
+	twoFactorRepo := repository.NewTwoFactorRepository(client)
 
 	// decrypt JohnDoe’s secret so we can generate a valid TOTP code
 	key, err := config.GetTestConfig().GetAESSecretKey()
@@ -420,7 +529,7 @@ func TestAuthUsecase_ValidateTwoFactor(t *testing.T) {
 	}{
 		{
 			name:        "success login",
-			twoFactorID: twoFactor.ID,
+			twoFactorID: "valid_code_two_factor",
 			code:        validCode,
 			expectedErr: nil,
 			expectAcc:   true,
@@ -434,18 +543,25 @@ func TestAuthUsecase_ValidateTwoFactor(t *testing.T) {
 		},
 		{
 			name:        "invalid verification code",
-			twoFactorID: twoFactor.ID,
+			twoFactorID: "invalid_code_two_factor",
 			code:        "000000",
 			expectedErr: account.AuthInvalidVerificationCode,
 			expectAcc:   false,
 		},
 	}
 
+	for _, tc := range testcases {
+		if tc.expectedErr != account.AuthTwoFactorDoesNotExist {
+			twoFactor := entity.TwoFactor{ID: tc.twoFactorID, Username: johnDoe.Username, Duration: time.Minute}
+			require.NoError(t, twoFactorRepo.Create(ctx, twoFactor))
+		}
+	}
+
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			acc, err := u.ValidateTwoFactor(ctx, tc.twoFactorID, tc.code)
+			acc, err := u.ValidateTwoFactor(ctx, tc.twoFactorID, tc.code, "192.0.2.2")
 
 			if tc.expectedErr != nil {
 				require.ErrorIs(t, err, tc.expectedErr)
@@ -455,21 +571,336 @@ func TestAuthUsecase_ValidateTwoFactor(t *testing.T) {
 				require.True(t, tc.expectAcc)
 				require.Equal(t, johnDoe.Username, acc.Username)
 				require.NotEmpty(t, acc.TOTPSecret)
+
+				// the same code can not log in again
+				_, err = u.ValidateTwoFactor(ctx, tc.twoFactorID, tc.code, "192.0.2.2")
+				require.ErrorIs(t, err, account.AuthTwoFactorDoesNotExist)
 			}
 		})
 	}
 }
 
+func TestAuthUsecase_LoginInitLockout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	// a dedicated redis keeps the counters away from the other tests
+	client := newIsolatedRedis(t)
+	u := setupAuthUsecaseWithRedis(client)
+
+	opaqueConf := &bytemareOpaque.Configuration{
+		OPRF: bytemareOpaque.P256Sha256,
+		AKE:  bytemareOpaque.P256Sha256,
+		Hash: crypto.SHA256,
+		KDF:  crypto.SHA256,
+		MAC:  crypto.SHA256,
+		KSF:  ksf.Argon2id,
+	}
+	opaqueClient, err := bytemareOpaque.NewClient(opaqueConf)
+	require.NoError(t, err)
+	message := opaqueClient.LoginInit([]byte(seed.DefaultPassword)).Serialize()
+
+	t.Run("username gets locked for the ip", func(t *testing.T) {
+		for i := 0; i < conf.MaxLoginAttempts; i++ {
+			_, err := u.LoginInit(ctx, message, seed.AccountJohnDoe.Username, "192.0.2.10")
+			require.NoError(t, err)
+		}
+
+		_, err := u.LoginInit(ctx, message, seed.AccountJohnDoe.Username, "192.0.2.10")
+		require.ErrorIs(t, err, account.AuthAccountLocked)
+
+		// the failures of one address can not lock the owner out
+		_, err = u.LoginInit(ctx, message, seed.AccountJohnDoe.Username, "192.0.2.11")
+		require.NoError(t, err)
+	})
+
+	t.Run("ip gets locked", func(t *testing.T) {
+		for i := 0; i < conf.MaxIPAttempts; i++ {
+			_, err := u.LoginInit(ctx, message, "ghost", "192.0.2.12")
+			require.ErrorIs(t, err, account.AuthInvalidAccount)
+		}
+
+		_, err := u.LoginInit(ctx, message, "ghost", "192.0.2.12")
+		require.ErrorIs(t, err, account.AuthTooManyAttempts)
+	})
+}
+
+func TestAuthUsecase_LoginSharedIP(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	client := newIsolatedRedis(t)
+	u := setupAuthUsecaseWithRedis(client)
+
+	opaqueConf := &bytemareOpaque.Configuration{
+		OPRF: bytemareOpaque.P256Sha256,
+		AKE:  bytemareOpaque.P256Sha256,
+		Hash: crypto.SHA256,
+		KDF:  crypto.SHA256,
+		MAC:  crypto.SHA256,
+		KSF:  ksf.Argon2id,
+	}
+	opaqueClient, err := bytemareOpaque.NewClient(opaqueConf)
+	require.NoError(t, err)
+	message := opaqueClient.LoginInit([]byte(seed.DefaultPassword)).Serialize()
+
+	key, err := conf.GetAESSecretKey()
+	require.NoError(t, err)
+	secret, err := encrypt.DecryptAESSecret(key, seed.AccountJohnDoe.TOTPSecret)
+	require.NoError(t, err)
+
+	// the successful logins behind one address must not lock it out
+	for i := 0; i <= conf.MaxIPAttempts; i++ {
+		_, err := u.LoginInit(ctx, message, seed.AccountJohnDoe.Username, "192.0.2.30")
+		require.NoError(t, err)
+
+		twoFactor, err := u.CreateTwoFactor(ctx, seed.AccountJohnDoe.Username)
+		require.NoError(t, err)
+
+		code, err := googleTotp.GenerateCode(secret, time.Now())
+		require.NoError(t, err)
+
+		_, err = u.ValidateTwoFactor(ctx, twoFactor.ID, code, "192.0.2.30")
+		require.NoError(t, err)
+	}
+}
+
+func TestAuthUsecase_ValidateTwoFactorAttempts(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	client := newIsolatedRedis(t)
+	u := setupAuthUsecaseWithRedis(client)
+
+	twoFactor := entity.TwoFactor{ID: "attempts_two_factor", Username: seed.AccountJohnDoe.Username, Duration: time.Minute}
+	require.NoError(t, repository.NewTwoFactorRepository(client).Create(ctx, twoFactor))
+
+	for i := 1; i < conf.MaxTwoFactorAttempts; i++ {
+		_, err := u.ValidateTwoFactor(ctx, twoFactor.ID, "000000", "192.0.2.20")
+		require.ErrorIs(t, err, account.AuthInvalidVerificationCode)
+	}
+
+	_, err := u.ValidateTwoFactor(ctx, twoFactor.ID, "000000", "192.0.2.20")
+	require.ErrorIs(t, err, account.AuthTwoFactorAttemptsSpent)
+
+	_, err = u.ValidateTwoFactor(ctx, twoFactor.ID, "000000", "192.0.2.20")
+	require.ErrorIs(t, err, account.AuthTwoFactorDoesNotExist)
+}
+
+func TestAuthUsecase_EnrollAuthenticator(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	u := setupAuthUsecaseWithRedis(newIsolatedRedis(t))
+	accRepo := repository.NewAccountRepository(pgTestSuite.db)
+
+	key, err := conf.GetAESSecretKey()
+	require.NoError(t, err)
+
+	oldKey, err := googleTotp.Generate(googleTotp.GenerateOpts{Issuer: "something", AccountName: "enroll_user"})
+	require.NoError(t, err)
+	oldSecret, err := encrypt.EncryptAESSecret(key, oldKey.Secret())
+	require.NoError(t, err)
+
+	err = accRepo.Create(ctx, entity.Account{
+		Username:     "enroll_user",
+		Email:        "enroll_user@example.com",
+		FirstName:    "Enroll",
+		LastName:     "User",
+		OpaqueRecord: []byte("record"),
+		TOTPSecret:   []byte(oldSecret),
+	})
+	require.NoError(t, err)
+	acc, err := accRepo.ReadByUsername(ctx, "enroll_user")
+	require.NoError(t, err)
+
+	// ---------- prove the current authenticator ----------
+	_, _, err = u.EnrollAuthenticatorInit(ctx, acc.Entity.ID, "000000")
+	require.ErrorIs(t, err, account.AuthInvalidVerificationCode)
+
+	oldCode, err := googleTotp.GenerateCode(oldKey.Secret(), time.Now())
+	require.NoError(t, err)
+
+	authenticator, enrollmentID, err := u.EnrollAuthenticatorInit(ctx, acc.Entity.ID, oldCode)
+	require.NoError(t, err)
+	require.NotEmpty(t, authenticator.QrCode)
+	require.NotEqual(t, oldKey.Secret(), authenticator.Secret)
+
+	// ---------- confirm the new authenticator ----------
+	newCode, err := googleTotp.GenerateCode(authenticator.Secret, time.Now())
+	require.NoError(t, err)
+
+	_, err = u.EnrollAuthenticatorFinalize(ctx, seed.AccountJohnDoe.Entity.ID, enrollmentID, newCode)
+	require.ErrorIs(t, err, account.AuthEnrollmentDoesNotExist)
+
+	recoveryCodes, err := u.EnrollAuthenticatorFinalize(ctx, acc.Entity.ID, enrollmentID, newCode)
+	require.NoError(t, err)
+	require.Len(t, recoveryCodes, 10)
+
+	updated, err := accRepo.ReadByID(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	keyring, err := conf.GetAESKeyring()
+	require.NoError(t, err)
+	require.True(t, keyring.IsActive(updated.TOTPSecret))
+	secret, err := keyring.Decrypt(updated.TOTPSecret)
+	require.NoError(t, err)
+	require.Equal(t, authenticator.Secret, secret)
+
+	_, err = u.EnrollAuthenticatorFinalize(ctx, acc.Entity.ID, enrollmentID, newCode)
+	require.ErrorIs(t, err, account.AuthEnrollmentDoesNotExist)
+
+	// ---------- a recovery code works once ----------
+	_, _, err = u.EnrollAuthenticatorInit(ctx, acc.Entity.ID, strings.ToLower(recoveryCodes[0]))
+	require.NoError(t, err)
+
+	left, err := u.RecoveryCodesLeft(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, 9, left)
+
+	_, _, err = u.EnrollAuthenticatorInit(ctx, acc.Entity.ID, recoveryCodes[0])
+	require.ErrorIs(t, err, account.AuthInvalidVerificationCode)
+}
+
+func TestAuthUsecase_Recover(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	client := newIsolatedRedis(t)
+	u := setupAuthUsecaseWithRedis(client)
+	accRepo := repository.NewAccountRepository(pgTestSuite.db)
+
+	key, err := conf.GetAESSecretKey()
+	require.NoError(t, err)
+
+	totpKey, err := googleTotp.Generate(googleTotp.GenerateOpts{Issuer: "something", AccountName: "recover_user"})
+	require.NoError(t, err)
+	secret, err := encrypt.EncryptAESSecret(key, totpKey.Secret())
+	require.NoError(t, err)
+
+	proof := []byte("proof derived from the recovery key")
+	verifier := sha256.Sum256(proof)
+
+	err = accRepo.Create(ctx, entity.Account{
+		Username:          "recover_user",
+		Email:             "recover_user@example.com",
+		FirstName:         "Recover",
+		LastName:          "User",
+		OpaqueRecord:      []byte("record"),
+		TOTPSecret:        []byte(secret),
+		EncryptedVaultKey: []byte("vault key wrapped under the forgotten password"),
+		RecoveryVerifier:  verifier[:],
+		RecoveryVaultKey:  []byte("vault key wrapped under the recovery key"),
+	})
+	require.NoError(t, err)
+	acc, err := accRepo.ReadByUsername(ctx, "recover_user")
+	require.NoError(t, err)
+
+	sessionRepo := repository.NewSessionRepository(client)
+	err = sessionRepo.Create(ctx, entity.Session{
+		CacheEntity: base.CacheEntity{ID: "recover_session", Duration: time.Minute},
+		AccountID:   acc.Entity.ID,
+	})
+	require.NoError(t, err)
+
+	registrationClient := newOpaqueClient(t)
+	registrationRequest := registrationClient.RegistrationInit([]byte("new-strong-password")).Serialize()
+
+	code, err := googleTotp.GenerateCode(totpKey.Secret(), time.Now())
+	require.NoError(t, err)
+
+	// ---------- prove the recovery key and the second factor ----------
+	testcases := []struct {
+		name     string
+		username string
+		proof    []byte
+		code     string
+	}{
+		{name: "unknown username", username: "missing_recover_user", proof: proof, code: code},
+		{name: "invalid recovery key", username: acc.Username, proof: []byte("wrong proof"), code: code},
+		{name: "invalid verification code", username: acc.Username, proof: proof, code: "000000"},
+	}
+
+	for _, tc := range testcases {
+		_, _, _, err := u.RecoverInit(ctx, tc.username, tc.proof, tc.code, registrationRequest, "127.0.0.1")
+		require.ErrorIs(t, err, account.AuthInvalidRecoveryKit, tc.name)
+	}
+
+	response, recoveryVaultKey, recoveryID, err := u.RecoverInit(ctx, acc.Username, proof, code, registrationRequest, "127.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, acc.RecoveryVaultKey, recoveryVaultKey)
+
+	// ---------- register the new password ----------
+	registrationResponse, err := registrationClient.Deserialize.RegistrationResponse(response)
+	require.NoError(t, err)
+
+	record, _ := registrationClient.RegistrationFinalize(registrationResponse, bytemareOpaque.ClientRegistrationFinalizeOptions{
+		ClientIdentity: []byte(acc.Username),
+		ServerIdentity: []byte(conf.Opaque.ServerID),
+	})
+
+	err = u.RecoverFinalize(ctx, "missing-recovery", record.Serialize(), []byte("key"), "127.0.0.1")
+	require.ErrorIs(t, err, account.AuthRecoveryDoesNotExist)
+
+	newVaultKey := []byte("vault key wrapped under the new password")
+	err = u.RecoverFinalize(ctx, recoveryID, record.Serialize(), newVaultKey, "127.0.0.1")
+	require.NoError(t, err)
+
+	updated, err := accRepo.ReadByID(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	require.NotEqual(t, acc.OpaqueRecord, updated.OpaqueRecord)
+	require.Equal(t, newVaultKey, updated.EncryptedVaultKey)
+	require.Equal(t, acc.RecoveryVaultKey, updated.RecoveryVaultKey)
+
+	exist, err := sessionRepo.Exist(ctx, "recover_session")
+	require.NoError(t, err)
+	require.False(t, exist)
+
+	require.NotEmpty(t, mailer.Messages(acc.Email))
+
+	// the recovery can not be replayed
+	err = u.RecoverFinalize(ctx, recoveryID, record.Serialize(), newVaultKey, "127.0.0.1")
+	require.ErrorIs(t, err, account.AuthRecoveryDoesNotExist)
+}
+
+func newIsolatedRedis(t *testing.T) *redis.Client {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	return redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
 func setupAuthUsecase() usecase.AuthUsecase {
+	return setupAuthUsecaseWithRedis(redisClient)
+}
 
+func setupAuthUsecaseWithRedis(client *redis.Client) usecase.AuthUsecase {
 	aRepo := repository.NewAccountRepository(pgTestSuite.db)
-	tfRepo := repository.NewTwoFactorRepository(redisClient)
-	rRepo := repository.NewRegistrationRepository(redisClient)
+	tfRepo := repository.NewTwoFactorRepository(client)
+	rRepo := repository.NewRegistrationRepository(client)
+	atRepo := repository.NewAttemptRepository(client)
+	eRepo := repository.NewEnrollmentRepository(client)
+	rcRepo := repository.NewRecoveryCodeRepository(pgTestSuite.db)
+	arRepo := repository.NewAccountRecoveryRepository(client)
+	sRepo := repository.NewSessionRepository(client)
+	vuRepo := repository.NewVaultUnlockRepository(client)
+	giRepo := repository.NewGroupInvitationRepository(pgTestSuite.db)
 	authenticator := totp.NewAuthenticatorAdaptor("something")
 	opqaue, err := opaque.New(conf)
 	if err != nil {
 		panic(err)
 	}
 
-	return usecase.NewAuthUsecase(aRepo, tfRepo, rRepo, authenticator, opqaue, conf)
+	return usecase.NewAuthUsecase(aRepo, tfRepo, rRepo, atRepo, eRepo, rcRepo, arRepo, sRepo, vuRepo, giRepo, authenticator, opqaue, mailer, conf)
+}
+
+// verificationCode reads the code of the last verification email sent to the address.
+func verificationCode(t *testing.T, email string) string {
+	messages := mailer.Messages(email)
+	require.NotEmpty(t, messages)
+
+	code := regexp.MustCompile(`\b\d{6}\b`).FindString(messages[len(messages)-1].Body)
+	require.NotEmpty(t, code)
+
+	return code
 }
@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	minEmergencyAccessWaitDays = 1
+	maxEmergencyAccessWaitDays = 90
+)
+
+// EmergencyAccessUsecase lets a grantor name a grantee who can take over the vault
+// after a wait period. The server never sees the vault key, the grantor's client
+// wraps it to the public key of the grantee when confirming the access.
+type EmergencyAccessUsecase struct {
+	emergencyAccessRepo repository.EmergencyAccessRepository
+	accountRepo         repository.AccountRepository
+
+	mailer mail.Mailer
+}
+
+func NewEmergencyAccessUsecase(eaRepo repository.EmergencyAccessRepository, aRepo repository.AccountRepository,
+	mailer mail.Mailer) EmergencyAccessUsecase {
+	return EmergencyAccessUsecase{
+		emergencyAccessRepo: eaRepo,
+		accountRepo:         aRepo,
+		mailer:              mailer,
+	}
+}
+
+// Read returns the accesses the account granted and the ones it received.
+func (u *EmergencyAccessUsecase) Read(ctx context.Context, accountID types.ID) ([]entity.EmergencyAccess, []entity.EmergencyAccess, error) {
+	granted, err := u.emergencyAccessRepo.ReadByGrantor(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading granted emergency accesses", "error", err.Error(), "account_id", accountID)
+		return nil, nil, errors.NewServerError()
+	}
+
+	received, err := u.emergencyAccessRepo.ReadByGrantee(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading received emergency accesses", "error", err.Error(), "account_id", accountID)
+		return nil, nil, errors.NewServerError()
+	}
+
+	return granted, received, nil
+}
+
+func (u *EmergencyAccessUsecase) Create(ctx context.Context, grantorID types.ID, granteeUsername string, waitDays int) (entity.EmergencyAccess, error) {
+	if waitDays < minEmergencyAccessWaitDays || waitDays > maxEmergencyAccessWaitDays {
+		return entity.EmergencyAccess{}, account.EmergencyAccessInvalidWaitPeriod
+	}
+
+	exist, err := u.accountRepo.ExistByUsername(ctx, granteeUsername)
+	if err != nil {
+		log.ErrorLogger.Error("error checking user existence by username", "error", err.Error(), "username", granteeUsername)
+		return entity.EmergencyAccess{}, errors.NewServerError()
+	}
+
+	if !exist {
+		return entity.EmergencyAccess{}, account.AccountUsernameDoesNotExist
+	}
+
+	grantee, err := u.accountRepo.ReadByUsername(ctx, granteeUsername)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by username", "error", err.Error(), "username", granteeUsername)
+		return entity.EmergencyAccess{}, errors.NewServerError()
+	}
+
+	if grantee.Entity.ID == grantorID {
+		return entity.EmergencyAccess{}, account.EmergencyAccessSelfGrant
+	}
+
+	exist, err = u.emergencyAccessRepo.Exist(ctx, grantorID, grantee.Entity.ID)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking emergency access existence", "error", err.Error(), "account_id", grantorID)
+		return entity.EmergencyAccess{}, errors.NewServerError()
+	}
+
+	if exist {
+		return entity.EmergencyAccess{}, account.EmergencyAccessExist
+	}
+
+	grantor, err := u.accountRepo.ReadByID(ctx, grantorID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", grantorID)
+		return entity.EmergencyAccess{}, errors.NewServerError()
+	}
+
+	access := entity.EmergencyAccess{
+		Grantor:  grantor,
+		Grantee:  grantee,
+		Status:   entity.EmergencyAccessInvited,
+		WaitDays: waitDays,
+	}
+
+	if err := u.emergencyAccessRepo.Create(ctx, &access); err != nil {
+		log.ErrorLogger.Error("error at creating emergency access", "error", err.Error(), "account_id", grantorID)
+		return entity.EmergencyAccess{}, errors.NewServerError()
+	}
+
+	notify(ctx, u.mailer, grantee.Email, mail.TemplateEmergencyAccessInvited, map[string]any{
+		"Username": grantee.Username,
+		"Other":    grantor.Username,
+		"WaitDays": waitDays,
+	})
+
+	return access, nil
+}
+
+// Accept is done by the grantee, who needs a published public key so the grantor
+// can wrap the vault key to it.
+func (u *EmergencyAccessUsecase) Accept(ctx context.Context, granteeID, id types.ID) error {
+	access, err := u.readAsGrantee(ctx, granteeID, id, entity.EmergencyAccessInvited)
+	if err != nil {
+		return err
+	}
+
+	if len(access.Grantee.PublicKey) == 0 {
+		return account.AccountKeysMissing
+	}
+
+	access.Status = entity.EmergencyAccessAccepted
+	if err := u.update(ctx, access); err != nil {
+		return err
+	}
+
+	notify(ctx, u.mailer, access.Grantor.Email, mail.TemplateEmergencyAccessAccepted, map[string]any{
+		"Username": access.Grantor.Username,
+		"Other":    access.Grantee.Username,
+	})
+
+	return nil
+}
+
+// Confirm stores the vault key of the grantor wrapped to the public key of the grantee.
+func (u *EmergencyAccessUsecase) Confirm(ctx context.Context, grantorID, id types.ID, encryptedVaultKey []byte) error {
+	access, err := u.readAsGrantor(ctx, grantorID, id, entity.EmergencyAccessAccepted)
+	if err != nil {
+		return err
+	}
+
+	access.Status = entity.EmergencyAccessConfirmed
+	access.EncryptedVaultKey = encryptedVaultKey
+	return u.update(ctx, access)
+}
+
+// RequestAccess starts the wait period. The grantor is told when the access will be
+// granted, so they can reject it before.
+func (u *EmergencyAccessUsecase) RequestAccess(ctx context.Context, granteeID, id types.ID) error {
+	access, err := u.readAsGrantee(ctx, granteeID, id, entity.EmergencyAccessConfirmed)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	access.Status = entity.EmergencyAccessRequested
+	access.RequestedAt = &now
+	if err := u.update(ctx, access); err != nil {
+		return err
+	}
+
+	notify(ctx, u.mailer, access.Grantor.Email, mail.TemplateEmergencyAccessRequested, map[string]any{
+		"Username":  access.Grantor.Username,
+		"Other":     access.Grantee.Username,
+		"Time":      now,
+		"ApproveAt": access.ApproveAt(),
+	})
+
+	log.WarningLogger.Warn("emergency access requested", "id", id, "grantee_id", granteeID)
+	return nil
+}
+
+// Reject takes back a pending or approved request, the access goes back to confirmed.
+func (u *EmergencyAccessUsecase) Reject(ctx context.Context, grantorID, id types.ID) error {
+	access, err := u.readAsGrantor(ctx, grantorID, id, entity.EmergencyAccessRequested, entity.EmergencyAccessApproved)
+	if err != nil {
+		return err
+	}
+
+	access.Status = entity.EmergencyAccessConfirmed
+	access.RequestedAt = nil
+	if err := u.update(ctx, access); err != nil {
+		return err
+	}
+
+	notify(ctx, u.mailer, access.Grantee.Email, mail.TemplateEmergencyAccessRejected, map[string]any{
+		"Username": access.Grantee.Username,
+		"Other":    access.Grantor.Username,
+	})
+
+	return nil
+}
+
+// Approve lets the grantor grant a pending request without waiting.
+func (u *EmergencyAccessUsecase) Approve(ctx context.Context, grantorID, id types.ID) error {
+	access, err := u.readAsGrantor(ctx, grantorID, id, entity.EmergencyAccessRequested)
+	if err != nil {
+		return err
+	}
+
+	access.Status = entity.EmergencyAccessApproved
+	if err := u.update(ctx, access); err != nil {
+		return err
+	}
+
+	u.notifyApproved(ctx, access)
+	return nil
+}
+
+// ApproveExpired approves every request whose wait period ran out. It is run by the
+// scheduler.
+func (u *EmergencyAccessUsecase) ApproveExpired(ctx context.Context) error {
+	ids, err := u.emergencyAccessRepo.ApproveExpired(ctx, time.Now())
+	if err != nil {
+		log.ErrorLogger.Error("error at approving expired emergency accesses", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	for _, id := range ids {
+		access, err := u.emergencyAccessRepo.ReadOne(ctx, id)
+		if err != nil {
+			log.ErrorLogger.Error("error at reading emergency access", "error", err.Error(), "id", id)
+			continue
+		}
+
+		log.WarningLogger.Warn("emergency access approved after the wait period", "id", id)
+		u.notifyApproved(ctx, access)
+	}
+
+	return nil
+}
+
+// ReadVaultKey returns the vault key of the grantor wrapped to the grantee's public
+// key, once the access is approved.
+func (u *EmergencyAccessUsecase) ReadVaultKey(ctx context.Context, granteeID, id types.ID) ([]byte, error) {
+	access, err := u.readAsGrantee(ctx, granteeID, id, entity.EmergencyAccessApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	log.WarningLogger.Warn("emergency access used", "id", id, "grantee_id", granteeID)
+	return access.EncryptedVaultKey, nil
+}
+
+// Delete removes the access, both the grantor and the grantee can do it.
+func (u *EmergencyAccessUsecase) Delete(ctx context.Context, accountID, id types.ID) error {
+	access, err := u.read(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if access.Grantor.Entity.ID != accountID && access.Grantee.Entity.ID != accountID {
+		return account.EmergencyAccessDoesNotExist
+	}
+
+	if err := u.emergencyAccessRepo.Delete(ctx, id); err != nil {
+		log.ErrorLogger.Error("error at deleting emergency access", "error", err.Error(), "id", id)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+func (u *EmergencyAccessUsecase) readAsGrantor(ctx context.Context, grantorID, id types.ID,
+	allowed ...entity.EmergencyAccessStatus) (entity.EmergencyAccess, error) {
+	access, err := u.read(ctx, id)
+	if err != nil {
+		return entity.EmergencyAccess{}, err
+	}
+
+	if access.Grantor.Entity.ID != grantorID {
+		return entity.EmergencyAccess{}, account.EmergencyAccessDoesNotExist
+	}
+
+	return access, checkEmergencyAccessStatus(access, allowed)
+}
+
+func (u *EmergencyAccessUsecase) readAsGrantee(ctx context.Context, granteeID, id types.ID,
+	allowed ...entity.EmergencyAccessStatus) (entity.EmergencyAccess, error) {
+	access, err := u.read(ctx, id)
+	if err != nil {
+		return entity.EmergencyAccess{}, err
+	}
+
+	if access.Grantee.Entity.ID != granteeID {
+		return entity.EmergencyAccess{}, account.EmergencyAccessDoesNotExist
+	}
+
+	return access, checkEmergencyAccessStatus(access, allowed)
+}
+
+func (u *EmergencyAccessUsecase) read(ctx context.Context, id types.ID) (entity.EmergencyAccess, error) {
+	access, err := u.emergencyAccessRepo.ReadOne(ctx, id)
+	if err == pgx.ErrNoRows {
+		return entity.EmergencyAccess{}, account.EmergencyAccessDoesNotExist
+	}
+
+	if err != nil {
+		log.ErrorLogger.Error("error at reading emergency access", "error", err.Error(), "id", id)
+		return entity.EmergencyAccess{}, errors.NewServerError()
+	}
+
+	return access, nil
+}
+
+func (u *EmergencyAccessUsecase) update(ctx context.Context, access entity.EmergencyAccess) error {
+	if err := u.emergencyAccessRepo.Update(ctx, access); err != nil {
+		log.ErrorLogger.Error("error at updating emergency access", "error", err.Error(), "id", access.ID)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+func (u *EmergencyAccessUsecase) notifyApproved(ctx context.Context, access entity.EmergencyAccess) {
+	notify(ctx, u.mailer, access.Grantee.Email, mail.TemplateEmergencyAccessApproved, map[string]any{
+		"Username": access.Grantee.Username,
+		"Other":    access.Grantor.Username,
+	})
+}
+
+func checkEmergencyAccessStatus(access entity.EmergencyAccess, allowed []entity.EmergencyAccessStatus) error {
+	for _, status := range allowed {
+		if access.Status == status {
+			return nil
+		}
+	}
+
+	return account.EmergencyAccessInvalidState
+}
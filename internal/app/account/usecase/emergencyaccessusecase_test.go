@@ -0,0 +1,194 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmergencyAccessUsecase_Create(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	grantor := createEmergencyAccessAccount(t, "ea_create_grantor")
+	grantee := createEmergencyAccessAccount(t, "ea_create_grantee")
+	u := setupEmergencyAccessUsecase()
+
+	testcases := []struct {
+		name            string
+		granteeUsername string
+		waitDays        int
+		expectedErr     error
+	}{
+		{
+			name:            "wait period too short",
+			granteeUsername: grantee.Username,
+			waitDays:        0,
+			expectedErr:     account.EmergencyAccessInvalidWaitPeriod,
+		},
+		{
+			name:            "wait period too long",
+			granteeUsername: grantee.Username,
+			waitDays:        91,
+			expectedErr:     account.EmergencyAccessInvalidWaitPeriod,
+		},
+		{
+			name:            "unknown grantee",
+			granteeUsername: "ea_unknown_grantee",
+			waitDays:        7,
+			expectedErr:     account.AccountUsernameDoesNotExist,
+		},
+		{
+			name:            "self grant",
+			granteeUsername: grantor.Username,
+			waitDays:        7,
+			expectedErr:     account.EmergencyAccessSelfGrant,
+		},
+		{
+			name:            "valid grant",
+			granteeUsername: grantee.Username,
+			waitDays:        7,
+		},
+		{
+			name:            "duplicate grant",
+			granteeUsername: grantee.Username,
+			waitDays:        7,
+			expectedErr:     account.EmergencyAccessExist,
+		},
+	}
+
+	// the cases depend on each other, so they run in order
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			access, err := u.Create(ctx, grantor.Entity.ID, tc.granteeUsername, tc.waitDays)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, entity.EmergencyAccessInvited, access.Status)
+			require.NotEmpty(t, mailer.Messages(grantee.Email))
+		})
+	}
+}
+
+func TestEmergencyAccessUsecase_Flow(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	grantor := createEmergencyAccessAccount(t, "ea_flow_grantor")
+	grantee := createEmergencyAccessAccount(t, "ea_flow_grantee")
+	u := setupEmergencyAccessUsecase()
+
+	access, err := u.Create(ctx, grantor.Entity.ID, grantee.Username, 3)
+	require.NoError(t, err)
+
+	// ---------- invitation ----------
+	err = u.Accept(ctx, grantee.Entity.ID, access.ID)
+	require.ErrorIs(t, err, account.AccountKeysMissing)
+
+	accountRepo := repository.NewAccountRepository(pgTestSuite.db)
+	require.NoError(t, accountRepo.UpdateKeys(ctx, grantee.Entity.ID, []byte("public key"), []byte("private key")))
+
+	err = u.Accept(ctx, grantor.Entity.ID, access.ID)
+	require.ErrorIs(t, err, account.EmergencyAccessDoesNotExist)
+
+	err = u.Accept(ctx, grantee.Entity.ID, access.ID)
+	require.NoError(t, err)
+
+	// ---------- confirmation ----------
+	wrappedVaultKey := []byte("vault key wrapped to the grantee")
+
+	err = u.RequestAccess(ctx, grantee.Entity.ID, access.ID)
+	require.ErrorIs(t, err, account.EmergencyAccessInvalidState)
+
+	err = u.Confirm(ctx, grantor.Entity.ID, access.ID, wrappedVaultKey)
+	require.NoError(t, err)
+
+	// ---------- request and rejection ----------
+	_, err = u.ReadVaultKey(ctx, grantee.Entity.ID, access.ID)
+	require.ErrorIs(t, err, account.EmergencyAccessInvalidState)
+
+	err = u.RequestAccess(ctx, grantee.Entity.ID, access.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, mailer.Messages(grantor.Email))
+
+	_, err = u.ReadVaultKey(ctx, grantee.Entity.ID, access.ID)
+	require.ErrorIs(t, err, account.EmergencyAccessInvalidState)
+
+	err = u.Reject(ctx, grantee.Entity.ID, access.ID)
+	require.ErrorIs(t, err, account.EmergencyAccessDoesNotExist)
+
+	err = u.Reject(ctx, grantor.Entity.ID, access.ID)
+	require.NoError(t, err)
+
+	// the wait period has not run out, so nothing is approved by the scheduler
+	err = u.RequestAccess(ctx, grantee.Entity.ID, access.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, u.ApproveExpired(ctx))
+
+	_, err = u.ReadVaultKey(ctx, grantee.Entity.ID, access.ID)
+	require.ErrorIs(t, err, account.EmergencyAccessInvalidState)
+
+	// ---------- approval ----------
+	err = u.Approve(ctx, grantor.Entity.ID, access.ID)
+	require.NoError(t, err)
+
+	vaultKey, err := u.ReadVaultKey(ctx, grantee.Entity.ID, access.ID)
+	require.NoError(t, err)
+	require.Equal(t, wrappedVaultKey, vaultKey)
+
+	_, err = u.ReadVaultKey(ctx, grantor.Entity.ID, access.ID)
+	require.ErrorIs(t, err, account.EmergencyAccessDoesNotExist)
+
+	granted, _, err := u.Read(ctx, grantor.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, granted, 1)
+	require.Equal(t, entity.EmergencyAccessApproved, granted[0].Status)
+
+	// ---------- removal ----------
+	err = u.Delete(ctx, types.ID(0), access.ID)
+	require.ErrorIs(t, err, account.EmergencyAccessDoesNotExist)
+
+	err = u.Delete(ctx, grantee.Entity.ID, access.ID)
+	require.NoError(t, err)
+
+	_, received, err := u.Read(ctx, grantee.Entity.ID)
+	require.NoError(t, err)
+	require.Empty(t, received)
+}
+
+func createEmergencyAccessAccount(t *testing.T, username string) entity.Account {
+	ctx := context.Background()
+	accountRepo := repository.NewAccountRepository(pgTestSuite.db)
+
+	err := accountRepo.Create(ctx, entity.Account{
+		Username:     username,
+		Email:        username + "@example.com",
+		FirstName:    "Emergency",
+		LastName:     "Access",
+		OpaqueRecord: []byte("record"),
+		TOTPSecret:   []byte("secret"),
+	})
+	require.NoError(t, err)
+
+	acc, err := accountRepo.ReadByUsername(ctx, username)
+	require.NoError(t, err)
+
+	return acc
+}
+
+func setupEmergencyAccessUsecase() usecase.EmergencyAccessUsecase {
+	eaRepo := repository.NewEmergencyAccessRepository(pgTestSuite.db)
+	aRepo := repository.NewAccountRepository(pgTestSuite.db)
+
+	return usecase.NewEmergencyAccessUsecase(eaRepo, aRepo, mailer)
+}
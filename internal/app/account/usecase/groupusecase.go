@@ -2,42 +2,97 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/TheAmirhosssein/cool-password-manage/config"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
 	params "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/param"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
 	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
 	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/jackc/pgx/v5"
 )
 
+// groupMemberEventsLimit is how many of the latest member events of a group are shown.
+const groupMemberEventsLimit = 50
+
+// GroupUsecase manages groups and their members. Nobody is added to a group without
+// consent, the members given to Create and Update are invited and join once they
+// accept the invitation.
 type GroupUsecase struct {
-	groupRepo   repository.GroupRepository
-	accountRepo repository.AccountRepository
+	lockout
+
+	groupRepo      repository.GroupRepository
+	accountRepo    repository.AccountRepository
+	invitationRepo repository.GroupInvitationRepository
+	txManager      database.TxManager
+
+	mailer mail.Mailer
+	config *config.Config
 }
 
-func NewGroupUsecase(groupRepo repository.GroupRepository, accountRepo repository.AccountRepository) GroupUsecase {
-	return GroupUsecase{groupRepo: groupRepo, accountRepo: accountRepo}
+func NewGroupUsecase(groupRepo repository.GroupRepository, accountRepo repository.AccountRepository,
+	invitationRepo repository.GroupInvitationRepository, atRepo repository.AttemptRepository, txManager database.TxManager,
+	mailer mail.Mailer, config *config.Config) GroupUsecase {
+	return GroupUsecase{
+		lockout:        lockout{attemptRepo: atRepo, config: config},
+		groupRepo:      groupRepo,
+		accountRepo:    accountRepo,
+		invitationRepo: invitationRepo,
+		txManager:      txManager,
+		mailer:         mailer,
+		config:         config,
+	}
 }
 
+// Create makes the owner the only member of the group and invites the other members
+// with the role they are given. The group is created together with its owner and the
+// invitations or not at all.
 func (u *GroupUsecase) Create(ctx context.Context, group *entity.Group) error {
-	err := u.groupRepo.Create(ctx, group)
-	if err != nil {
-		log.ErrorLogger.Error("error at creating group", "error", err.Error())
-		return errors.NewServerError()
+	if !entity.ValidGroupName(group.Name) {
+		return account.GroupInvalidName
 	}
 
-	if !u.isOwnerInMembers(group.Owner, group.Members) {
-		group.Members = append(group.Members, entity.Account{Entity: group.Owner.Entity})
+	roles, err := memberRoles(entity.Group{Owner: group.Owner}, *group, entity.GroupRoleOwner)
+	if err != nil {
+		return err
 	}
 
-	err = u.groupRepo.AddAccounts(ctx, group.ID, group.Members)
+	ownerID := group.Owner.Entity.ID
+	delete(roles, ownerID)
+
+	var invitations []entity.GroupInvitation
+	err = u.inTx(ctx, func(ctx context.Context) error {
+		if err := u.groupRepo.Create(ctx, group); err != nil {
+			log.ErrorLogger.Error("error at creating group", "error", err.Error())
+			return errors.NewServerError()
+		}
+
+		owner := entity.GroupMemberChange{AccountID: ownerID, Role: entity.GroupRoleOwner}
+		err := u.groupRepo.ChangeMembers(ctx, group.ID, ownerID, []entity.GroupMemberChange{owner})
+		if err != nil {
+			log.ErrorLogger.Error("error at adding the owner into group", "error", err.Error())
+			return errors.NewServerError()
+		}
+
+		invitations, err = u.invite(ctx, *group, ownerID, roles)
+		return err
+	})
 	if err != nil {
-		log.ErrorLogger.Error("error at adding members into group", "error", err.Error())
-		return errors.NewServerError()
+		group.ID = 0
+		return err
 	}
 
+	u.notifyInvitations(ctx, invitations)
+
+	group.Members = []entity.Account{{Entity: group.Owner.Entity}}
+	group.Roles = map[types.ID]entity.GroupRole{ownerID: entity.GroupRoleOwner}
+
 	return nil
 }
 
@@ -74,91 +129,675 @@ func (u *GroupUsecase) ReadOne(ctx context.Context, id, memberID types.ID) (enti
 	return group, nil
 }
 
+// Update replaces the name, the description and the members of the group, only its
+// owner and admins can. Members not given a role in group.Roles keep the one they have,
+// accounts that are not members yet are invited. Only the members that differ are
+// changed, so edits made meanwhile to the other members are kept. Nothing is changed
+// when any of it fails.
 func (u *GroupUsecase) Update(ctx context.Context, editorAccount entity.Account, group entity.Group) error {
+	if !entity.ValidGroupName(group.Name) {
+		return account.GroupInvalidName
+	}
+
 	toBeUpdatedGroup, err := u.groupRepo.ReadOne(ctx, group.ID, editorAccount.Entity.ID)
 	if err != nil {
 		log.ErrorLogger.Error("error at getting group by id", "error", err.Error())
 		return errors.NewServerError()
 	}
 
-	if editorAccount.Entity.ID != toBeUpdatedGroup.Owner.Entity.ID {
-		return account.GroupOnlyTheOwnerCanEdit
+	editorRole := toBeUpdatedGroup.Role(editorAccount.Entity.ID)
+	if !editorRole.CanManageMembers() {
+		return account.GroupOnlyAdminsCanEdit
 	}
 
-	err = u.groupRepo.Update(ctx, group)
+	group.Owner = toBeUpdatedGroup.Owner
+	roles, err := memberRoles(toBeUpdatedGroup, group, editorRole)
 	if err != nil {
-		log.ErrorLogger.Error("error at updating group", "error", err.Error())
-		return errors.NewServerError()
+		return err
+	}
+
+	toBeUpdatedGroup.Name, toBeUpdatedGroup.Description = group.Name, group.Description
+
+	var invitations []entity.GroupInvitation
+	err = u.inTx(ctx, func(ctx context.Context) error {
+		if err := u.groupRepo.Update(ctx, group); err != nil {
+			log.ErrorLogger.Error("error at updating group", "error", err.Error())
+			return errors.NewServerError()
+		}
+
+		if err := u.changeMembers(ctx, toBeUpdatedGroup, editorAccount.Entity.ID, roles); err != nil {
+			return err
+		}
+
+		invitations, err = u.invite(ctx, toBeUpdatedGroup, editorAccount.Entity.ID, invitees(toBeUpdatedGroup, roles))
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
-	if !u.isOwnerInMembers(group.Owner, group.Members) {
-		group.Members = append(group.Members, entity.Account{Entity: group.Owner.Entity})
+	u.notifyInvitations(ctx, invitations)
+	return nil
+}
+
+// ChangeMemberRole gives a member of the group another role, only the owner and admins
+// can and only the owner can change admins.
+func (u *GroupUsecase) ChangeMemberRole(ctx context.Context, editorID, groupID, memberID types.ID, role entity.GroupRole) error {
+	if role == "" {
+		return account.GroupInvalidRole
 	}
 
-	err = u.groupRepo.DeleteAllMembers(ctx, group.ID, group.Owner.Entity.ID)
+	return u.editMember(ctx, editorID, groupID, memberID, role)
+}
+
+// RemoveMember removes a member from the group, the items it shared with the group stop
+// being shared with it. Only the owner and admins can and only the owner can remove
+// admins.
+func (u *GroupUsecase) RemoveMember(ctx context.Context, editorID, groupID, memberID types.ID) error {
+	return u.editMember(ctx, editorID, groupID, memberID, "")
+}
+
+// ReadMemberEvents returns the latest changes to the members of the group, the members
+// of the group can see them.
+func (u *GroupUsecase) ReadMemberEvents(ctx context.Context, groupID, memberID types.ID) ([]entity.GroupMemberEvent, error) {
+	if _, err := u.readGroup(ctx, groupID, memberID); err != nil {
+		return nil, err
+	}
+
+	events, err := u.groupRepo.ReadMemberEvents(ctx, groupID, groupMemberEventsLimit)
 	if err != nil {
-		log.ErrorLogger.Error("error at deleting all the members of group", "error", err.Error())
-		return errors.NewServerError()
+		log.ErrorLogger.Error("error at reading group member events", "error", err.Error(), "group_id", groupID)
+		return nil, errors.NewServerError()
+	}
+
+	return events, nil
+}
+
+// Delete deletes the group, only its owner can. The group is read and deleted in the
+// same transaction.
+func (u *GroupUsecase) Delete(ctx context.Context, id, ownerID types.ID) error {
+	return u.inTx(ctx, func(ctx context.Context) error {
+		group, err := u.readGroup(ctx, id, ownerID)
+		if err != nil {
+			return err
+		}
+
+		if group.Owner.Entity.ID != ownerID {
+			return account.GroupOnlyTheOwnerCanDelete
+		}
+
+		if err := u.groupRepo.Delete(ctx, id, ownerID); err != nil {
+			log.ErrorLogger.Error("error at deleting group", "error", err.Error())
+			return errors.NewServerError()
+		}
+
+		return nil
+	})
+}
+
+func (u *GroupUsecase) SearchMember(ctx context.Context, username string) (entity.Account, error) {
+	exist, err := u.accountRepo.ExistByUsername(ctx, username)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking if the user with the username exist", "error", err.Error())
+		return entity.Account{}, errors.NewServerError()
+	}
+
+	if !exist {
+		return entity.Account{}, account.AccountUsernameDoesNotExist
 	}
 
-	err = u.groupRepo.AddAccounts(ctx, group.ID, group.Members)
+	account, err := u.accountRepo.ReadByUsername(ctx, username)
 	if err != nil {
-		log.ErrorLogger.Error("error at adding members to group", "error", err.Error())
+		log.ErrorLogger.Error("error at reading account by username", "error", err.Error())
+		return entity.Account{}, errors.NewServerError()
+	}
+
+	return account, nil
+}
+
+// Invite invites the account with the email to the group, or the email itself when
+// nobody signed up with it yet. Only the owner and admins can invite, and only the
+// owner can invite admins. Every invitation mails an address the inviter picked, so
+// the invitations of each inviter are limited like the attempts of a login.
+func (u *GroupUsecase) Invite(ctx context.Context, inviterID, groupID types.ID, email string,
+	role entity.GroupRole) (entity.GroupInvitation, error) {
+	identifier := fmt.Sprint(inviterID)
+	if err := u.checkLock(ctx, entity.AttemptScopeGroupInvitation, identifier, account.GroupTooManyInvitations); err != nil {
+		return entity.GroupInvitation{}, err
+	}
+
+	group, err := u.groupRepo.ReadOne(ctx, groupID, inviterID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading one group", "error", err.Error(), "id", groupID)
+		return entity.GroupInvitation{}, errors.NewServerError()
+	}
+
+	if !group.ID.Valid() {
+		return entity.GroupInvitation{}, account.GroupDoesNotExist
+	}
+
+	inviterRole := group.Role(inviterID)
+	if !inviterRole.CanManageMembers() {
+		return entity.GroupInvitation{}, account.GroupOnlyAdminsCanEdit
+	}
+
+	if !role.Valid() || role == entity.GroupRoleOwner {
+		return entity.GroupInvitation{}, account.GroupInvalidRole
+	}
+
+	if role == entity.GroupRoleAdmin && inviterRole != entity.GroupRoleOwner {
+		return entity.GroupInvitation{}, account.GroupOnlyTheOwnerCanManageAdmins
+	}
+
+	inviter, err := u.accountRepo.ReadByID(ctx, inviterID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", inviterID)
+		return entity.GroupInvitation{}, errors.NewServerError()
+	}
+
+	invitation := entity.GroupInvitation{Group: group, Inviter: inviter, Email: email, Role: role}
+
+	invitee, err := u.accountRepo.ReadByEmail(ctx, email)
+	switch {
+	case err == nil:
+		if group.Role(invitee.Entity.ID) != "" {
+			return entity.GroupInvitation{}, account.GroupAlreadyMember
+		}
+		invitation.Invitee, invitation.Email = invitee, ""
+	case err != pgx.ErrNoRows:
+		log.ErrorLogger.Error("error at reading account by email", "error", err.Error())
+		return entity.GroupInvitation{}, errors.NewServerError()
+	}
+
+	err = u.registerFailure(ctx, entity.AttemptScopeGroupInvitation, identifier, u.config.MaxGroupInvitations)
+	if err != nil {
+		return entity.GroupInvitation{}, err
+	}
+
+	if err := u.createInvitation(ctx, &invitation); err != nil {
+		return entity.GroupInvitation{}, err
+	}
+
+	u.notifyInvitations(ctx, []entity.GroupInvitation{invitation})
+	return invitation, nil
+}
+
+// ReadInvitations returns the pending invitations the account received.
+func (u *GroupUsecase) ReadInvitations(ctx context.Context, accountID types.ID) ([]entity.GroupInvitation, error) {
+	invitations, err := u.invitationRepo.ReadByInvitee(ctx, accountID, time.Now())
+	if err != nil {
+		log.ErrorLogger.Error("error at reading group invitations", "error", err.Error(), "account_id", accountID)
+		return nil, errors.NewServerError()
+	}
+
+	return invitations, nil
+}
+
+// ReadGroupInvitations returns the pending invitations to the group, the members of
+// the group can see them.
+func (u *GroupUsecase) ReadGroupInvitations(ctx context.Context, groupID, memberID types.ID) ([]entity.GroupInvitation, error) {
+	group, err := u.groupRepo.ReadOne(ctx, groupID, memberID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading one group", "error", err.Error(), "id", groupID)
+		return nil, errors.NewServerError()
+	}
+
+	if !group.ID.Valid() {
+		return nil, account.GroupDoesNotExist
+	}
+
+	invitations, err := u.invitationRepo.ReadByGroup(ctx, groupID, time.Now())
+	if err != nil {
+		log.ErrorLogger.Error("error at reading group invitations", "error", err.Error(), "group_id", groupID)
+		return nil, errors.NewServerError()
+	}
+
+	return invitations, nil
+}
+
+// AcceptInvitation makes the invitee a member of the group with the role of the
+// invitation.
+func (u *GroupUsecase) AcceptInvitation(ctx context.Context, accountID, id types.ID) error {
+	if _, err := u.readInvitationAsInvitee(ctx, accountID, id); err != nil {
+		return err
+	}
+
+	err := u.invitationRepo.Accept(ctx, id, time.Now())
+	if err == pgx.ErrNoRows {
+		return account.GroupInvitationDoesNotExist
+	}
+
+	if err != nil {
+		log.ErrorLogger.Error("error at accepting group invitation", "error", err.Error(), "id", id)
 		return errors.NewServerError()
 	}
 
 	return nil
 }
 
-func (u *GroupUsecase) Delete(ctx context.Context, id, ownerID types.ID) error {
-	group, err := u.ReadOne(ctx, id, ownerID)
+func (u *GroupUsecase) DeclineInvitation(ctx context.Context, accountID, id types.ID) error {
+	if _, err := u.readInvitationAsInvitee(ctx, accountID, id); err != nil {
+		return err
+	}
+
+	return u.deleteInvitation(ctx, id)
+}
+
+// CancelInvitation takes back an invitation to the group, the owner and admins can do
+// it but only the owner can take back the invitation of an admin.
+func (u *GroupUsecase) CancelInvitation(ctx context.Context, accountID, id types.ID) error {
+	invitation, err := u.readInvitation(ctx, id)
 	if err != nil {
-		log.ErrorLogger.Error("error at reading one group", "error", err.Error())
+		return err
+	}
+
+	group, err := u.groupRepo.ReadOne(ctx, invitation.Group.ID, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading one group", "error", err.Error(), "id", invitation.Group.ID)
 		return errors.NewServerError()
 	}
 
-	if !group.ID.Valid() {
+	role := group.Role(accountID)
+	if role == "" {
+		return account.GroupInvitationDoesNotExist
+	}
+
+	if !role.CanManageMembers() {
+		return account.GroupOnlyAdminsCanEdit
+	}
+
+	if invitation.Role == entity.GroupRoleAdmin && role != entity.GroupRoleOwner {
+		return account.GroupOnlyTheOwnerCanManageAdmins
+	}
+
+	return u.deleteInvitation(ctx, id)
+}
+
+// Leave removes the account from the group, the items it shared with the group stop
+// being shared with it. The owner has to hand the group over before leaving.
+func (u *GroupUsecase) Leave(ctx context.Context, accountID, groupID types.ID) error {
+	group, err := u.readGroup(ctx, groupID, accountID)
+	if err != nil {
+		return err
+	}
+
+	if group.Role(accountID) == entity.GroupRoleOwner {
+		return account.GroupOwnerCannotLeave
+	}
+
+	err = u.groupRepo.DeleteMember(ctx, groupID, accountID)
+	if err == pgx.ErrNoRows {
 		return account.GroupDoesNotExist
 	}
 
+	if err != nil {
+		log.ErrorLogger.Error("error at leaving group", "error", err.Error(), "group_id", groupID)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+// OfferOwnership offers the group to another member, the group changes hands once the
+// member accepts. Offering it again replaces the earlier offer.
+func (u *GroupUsecase) OfferOwnership(ctx context.Context, ownerID, groupID, newOwnerID types.ID) error {
+	group, err := u.readGroup(ctx, groupID, ownerID)
+	if err != nil {
+		return err
+	}
+
 	if group.Owner.Entity.ID != ownerID {
-		return account.GroupOnlyTheOwnerCanDelete
+		return account.GroupOnlyTheOwnerCanTransfer
+	}
+
+	if newOwnerID == ownerID || group.Role(newOwnerID) == "" {
+		return account.GroupInvalidNewOwner
+	}
+
+	if err := u.checkOwnedName(ctx, newOwnerID, group.Name); err != nil {
+		return err
+	}
+
+	err = u.groupRepo.OfferOwnership(ctx, groupID, ownerID, newOwnerID)
+	if err == pgx.ErrNoRows {
+		return account.GroupInvalidNewOwner
 	}
 
-	err = u.groupRepo.Delete(ctx, id, ownerID)
 	if err != nil {
-		log.ErrorLogger.Error("error at deleting group", "error", err.Error())
+		log.ErrorLogger.Error("error at offering group ownership", "error", err.Error(), "group_id", groupID)
 		return errors.NewServerError()
 	}
 
+	for _, member := range group.Members {
+		if member.Entity.ID == newOwnerID {
+			notify(ctx, u.mailer, member.Email, mail.TemplateGroupOwnershipOffer, map[string]any{
+				"Username": member.Username,
+				"Other":    group.Owner.Username,
+				"Group":    group.Name,
+			})
+		}
+	}
+
 	return nil
 }
 
-func (u *GroupUsecase) SearchMember(ctx context.Context, username string) (entity.Account, error) {
-	exist, err := u.accountRepo.ExistByUsername(ctx, username)
+// ReadOwnershipOffers returns the groups offered to the account.
+func (u *GroupUsecase) ReadOwnershipOffers(ctx context.Context, accountID types.ID) ([]entity.Group, error) {
+	groups, err := u.groupRepo.ReadOwnershipOffers(ctx, accountID)
 	if err != nil {
-		log.ErrorLogger.Error("error at checking if the user with the username exist", "error", err.Error())
-		return entity.Account{}, errors.NewServerError()
+		log.ErrorLogger.Error("error at reading ownership offers", "error", err.Error(), "account_id", accountID)
+		return nil, errors.NewServerError()
 	}
 
-	if !exist {
-		return entity.Account{}, account.AccountUsernameDoesNotExist
+	return groups, nil
+}
+
+// AcceptOwnership makes the account the owner of the group offered to it, the old owner
+// stays on as an admin.
+func (u *GroupUsecase) AcceptOwnership(ctx context.Context, accountID, groupID types.ID) error {
+	group, err := u.groupRepo.ReadOne(ctx, groupID, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading one group", "error", err.Error(), "id", groupID)
+		return errors.NewServerError()
+	}
+
+	if !group.ID.Valid() || group.PendingOwnerID != accountID {
+		return account.GroupOwnershipOfferDoesNotExist
+	}
+
+	if err := u.checkOwnedName(ctx, accountID, group.Name); err != nil {
+		return err
+	}
+
+	err = u.groupRepo.AcceptOwnership(ctx, groupID, accountID)
+	if err == pgx.ErrNoRows {
+		return account.GroupOwnershipOfferDoesNotExist
 	}
 
-	account, err := u.accountRepo.ReadByUsername(ctx, username)
 	if err != nil {
-		log.ErrorLogger.Error("error at reading account by username", "error", err.Error())
-		return entity.Account{}, errors.NewServerError()
+		log.ErrorLogger.Error("error at accepting group ownership", "error", err.Error(), "group_id", groupID)
+		return errors.NewServerError()
 	}
 
-	return account, nil
+	return nil
+}
+
+// DeleteOwnershipOffer drops the ownership offer of the group, the owner takes it back
+// or the member it was offered to declines it.
+func (u *GroupUsecase) DeleteOwnershipOffer(ctx context.Context, accountID, groupID types.ID) error {
+	err := u.groupRepo.DeleteOwnershipOffer(ctx, groupID, accountID)
+	if err == pgx.ErrNoRows {
+		return account.GroupOwnershipOfferDoesNotExist
+	}
+
+	if err != nil {
+		log.ErrorLogger.Error("error at deleting ownership offer", "error", err.Error(), "group_id", groupID)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+// editMember gives the member the role on behalf of the editor, the empty role removes
+// it.
+func (u *GroupUsecase) editMember(ctx context.Context, editorID, groupID, memberID types.ID, role entity.GroupRole) error {
+	current, err := u.readGroup(ctx, groupID, editorID)
+	if err != nil {
+		return err
+	}
+
+	editorRole := current.Role(editorID)
+	if !editorRole.CanManageMembers() {
+		return account.GroupOnlyAdminsCanEdit
+	}
+
+	switch current.Role(memberID) {
+	case "":
+		return account.GroupMemberDoesNotExist
+	case entity.GroupRoleOwner:
+		return account.GroupOwnerRoleFixed
+	}
+
+	group := entity.Group{Roles: make(map[types.ID]entity.GroupRole)}
+	for _, member := range current.Members {
+		if member.Entity.ID != memberID || role != "" {
+			group.Members = append(group.Members, member)
+		}
+	}
+	if role != "" {
+		group.Roles[memberID] = role
+	}
+
+	roles, err := memberRoles(current, group, editorRole)
+	if err != nil {
+		return err
+	}
+
+	return u.changeMembers(ctx, current, editorID, roles)
 }
 
-func (u *GroupUsecase) isOwnerInMembers(owner entity.Account, members []entity.Account) bool {
-	for _, member := range members {
-		if member.Entity.ID == owner.Entity.ID {
-			return true
+// changeMembers brings the members of the group to the roles on behalf of the editor,
+// the accounts that are not members yet are left to be invited.
+func (u *GroupUsecase) changeMembers(ctx context.Context, current entity.Group, editorID types.ID,
+	roles map[types.ID]entity.GroupRole) error {
+	var changes []entity.GroupMemberChange
+	for id, role := range current.Roles {
+		if roles[id] != role {
+			changes = append(changes, entity.GroupMemberChange{AccountID: id, PreviousRole: role, Role: roles[id]})
 		}
 	}
-	return false
+
+	err := u.groupRepo.ChangeMembers(ctx, current.ID, editorID, changes)
+	if err == pgx.ErrNoRows {
+		return account.GroupMembersChanged
+	}
+
+	if err != nil {
+		log.ErrorLogger.Error("error at changing group members", "error", err.Error(), "group_id", current.ID)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+// inTx runs fn in a transaction. fn returns the errors of the usecase as they are, the
+// errors of beginning and committing the transaction become server errors.
+func (u *GroupUsecase) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := u.txManager.Do(ctx, fn)
+	if _, ok := err.(*errors.CustomError); err != nil && !ok {
+		log.ErrorLogger.Error("error at running group transaction", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	return err
+}
+
+// readGroup reads the group the account is a member of.
+func (u *GroupUsecase) readGroup(ctx context.Context, groupID, memberID types.ID) (entity.Group, error) {
+	group, err := u.groupRepo.ReadOne(ctx, groupID, memberID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading one group", "error", err.Error(), "id", groupID)
+		return entity.Group{}, errors.NewServerError()
+	}
+
+	if !group.ID.Valid() {
+		return entity.Group{}, account.GroupDoesNotExist
+	}
+
+	return group, nil
+}
+
+// checkOwnedName makes sure the account does not own a group with the name already,
+// the names of the groups of an owner are unique.
+func (u *GroupUsecase) checkOwnedName(ctx context.Context, ownerID types.ID, name string) error {
+	groups, err := u.groupRepo.ReadByOwner(ctx, ownerID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading groups by owner", "error", err.Error(), "owner_id", ownerID)
+		return errors.NewServerError()
+	}
+
+	for _, group := range groups {
+		if group.Name == name {
+			return account.GroupNewOwnerNameTaken
+		}
+	}
+
+	return nil
+}
+
+func (u *GroupUsecase) readInvitationAsInvitee(ctx context.Context, inviteeID, id types.ID) (entity.GroupInvitation, error) {
+	invitation, err := u.readInvitation(ctx, id)
+	if err != nil {
+		return entity.GroupInvitation{}, err
+	}
+
+	if invitation.Invitee.Entity.ID != inviteeID || invitation.Expired(time.Now()) {
+		return entity.GroupInvitation{}, account.GroupInvitationDoesNotExist
+	}
+
+	return invitation, nil
+}
+
+func (u *GroupUsecase) readInvitation(ctx context.Context, id types.ID) (entity.GroupInvitation, error) {
+	invitation, err := u.invitationRepo.ReadOne(ctx, id)
+	if err == pgx.ErrNoRows {
+		return entity.GroupInvitation{}, account.GroupInvitationDoesNotExist
+	}
+
+	if err != nil {
+		log.ErrorLogger.Error("error at reading group invitation", "error", err.Error(), "id", id)
+		return entity.GroupInvitation{}, errors.NewServerError()
+	}
+
+	return invitation, nil
+}
+
+func (u *GroupUsecase) deleteInvitation(ctx context.Context, id types.ID) error {
+	if err := u.invitationRepo.Delete(ctx, id); err != nil {
+		log.ErrorLogger.Error("error at deleting group invitation", "error", err.Error(), "id", id)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+// invite invites the accounts to the group with their role on behalf of the inviter
+// and returns the invitations, they are mailed with notifyInvitations.
+func (u *GroupUsecase) invite(ctx context.Context, group entity.Group, inviterID types.ID,
+	roles map[types.ID]entity.GroupRole) ([]entity.GroupInvitation, error) {
+	if len(roles) == 0 {
+		return nil, nil
+	}
+
+	inviter, err := u.accountRepo.ReadByID(ctx, inviterID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", inviterID)
+		return nil, errors.NewServerError()
+	}
+
+	invitations := make([]entity.GroupInvitation, 0, len(roles))
+	for id, role := range roles {
+		invitee, err := u.accountRepo.ReadByID(ctx, id)
+		if err != nil {
+			log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", id)
+			return nil, errors.NewServerError()
+		}
+
+		invitation := entity.GroupInvitation{Group: group, Inviter: inviter, Invitee: invitee, Role: role}
+		if err := u.createInvitation(ctx, &invitation); err != nil {
+			return nil, err
+		}
+
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, nil
+}
+
+// createInvitation stores the invitation with the configured expiry.
+func (u *GroupUsecase) createInvitation(ctx context.Context, invitation *entity.GroupInvitation) error {
+	invitation.ExpiresAt = time.Now().Add(time.Hour * 24 * time.Duration(u.config.GroupInvitationDays))
+	if err := u.invitationRepo.Create(ctx, invitation); err != nil {
+		log.ErrorLogger.Error("error at creating group invitation", "error", err.Error(), "group_id", invitation.Group.ID)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+// notifyInvitations mails the invitations to their invitee, or to the email when the
+// invitee has no account yet. It is called once the invitations are committed, so no
+// mail is sent for an invitation that was rolled back.
+func (u *GroupUsecase) notifyInvitations(ctx context.Context, invitations []entity.GroupInvitation) {
+	for _, invitation := range invitations {
+		data := map[string]any{
+			"Username":  invitation.Invitee.Username,
+			"Other":     invitation.Inviter.Username,
+			"Group":     invitation.Group.Name,
+			"Role":      invitation.Role,
+			"ExpiresAt": invitation.ExpiresAt,
+		}
+		if invitation.Invitee.Entity.ID.Valid() {
+			notify(ctx, u.mailer, invitation.Invitee.Email, mail.TemplateGroupInvited, data)
+		} else {
+			notify(ctx, u.mailer, invitation.Email, mail.TemplateGroupInvitedByEmail, data)
+		}
+	}
+}
+
+// invitees returns the accounts of the roles that are not members of the group yet.
+func invitees(group entity.Group, roles map[types.ID]entity.GroupRole) map[types.ID]entity.GroupRole {
+	invitees := make(map[types.ID]entity.GroupRole)
+	for id, role := range roles {
+		if group.Role(id) == "" {
+			invitees[id] = role
+		}
+	}
+
+	return invitees
+}
+
+// memberRoles returns the role of every account the group is going to have. The owner
+// is always kept, members keep their current role unless group gives them another one
+// and new members join as viewers. Only the owner can add, remove or change admins.
+func memberRoles(current, group entity.Group, editorRole entity.GroupRole) (map[types.ID]entity.GroupRole, error) {
+	ownerID := current.Owner.Entity.ID
+	roles := map[types.ID]entity.GroupRole{ownerID: entity.GroupRoleOwner}
+
+	for _, member := range group.Members {
+		id := member.Entity.ID
+		if id == ownerID {
+			continue
+		}
+
+		role, ok := group.Roles[id]
+		if !ok {
+			role = current.Role(id)
+		}
+		if role == "" {
+			role = entity.GroupRoleViewer
+		}
+
+		if !role.Valid() || role == entity.GroupRoleOwner {
+			return nil, account.GroupInvalidRole
+		}
+		roles[id] = role
+	}
+
+	if editorRole == entity.GroupRoleOwner {
+		return roles, nil
+	}
+
+	for id, role := range current.Roles {
+		if role == entity.GroupRoleAdmin && roles[id] != entity.GroupRoleAdmin {
+			return nil, account.GroupOnlyTheOwnerCanManageAdmins
+		}
+	}
+	for id, role := range roles {
+		if role == entity.GroupRoleAdmin && current.Role(id) != entity.GroupRoleAdmin {
+			return nil, account.GroupOnlyTheOwnerCanManageAdmins
+		}
+	}
+
+	return roles, nil
 }
@@ -2,13 +2,18 @@ package usecase_test
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
 	params "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/param"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/seed"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
@@ -26,6 +31,7 @@ func TestGroupUsecase_Create(t *testing.T) {
 	testcases := []struct {
 		name        string
 		group       entity.Group
+		invited     []types.ID
 		expectedErr error
 	}{
 		{
@@ -49,6 +55,18 @@ func TestGroupUsecase_Create(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "success with members invited",
+			group: entity.Group{
+				Name: "John's Third Group",
+				Owner: entity.Account{
+					Entity: base.Entity{ID: johnDoe.Entity.ID},
+				},
+				Members: []entity.Account{{Entity: base.Entity{ID: seed.AccountEarl.Entity.ID}}},
+			},
+			invited:     []types.ID{seed.AccountEarl.Entity.ID},
+			expectedErr: nil,
+		},
 		{
 			name: "invalid owner id (does not exist)",
 			group: entity.Group{
@@ -59,6 +77,47 @@ func TestGroupUsecase_Create(t *testing.T) {
 			},
 			expectedErr: errors.NewServerError(),
 		},
+		{
+			name: "invalid member id leaves no group behind",
+			group: entity.Group{
+				Name: "John's Orphan Group",
+				Owner: entity.Account{
+					Entity: base.Entity{ID: johnDoe.Entity.ID},
+				},
+				Members: []entity.Account{{Entity: base.Entity{ID: 999999}}},
+			},
+			expectedErr: errors.NewServerError(),
+		},
+		{
+			name: "name with a line break",
+			group: entity.Group{
+				Name: "John's Group\r\nBcc: victim@example.com",
+				Owner: entity.Account{
+					Entity: base.Entity{ID: johnDoe.Entity.ID},
+				},
+			},
+			expectedErr: account.GroupInvalidName,
+		},
+		{
+			name: "name longer than the column",
+			group: entity.Group{
+				Name: strings.Repeat("g", entity.GroupNameMaxLength+1),
+				Owner: entity.Account{
+					Entity: base.Entity{ID: johnDoe.Entity.ID},
+				},
+			},
+			expectedErr: account.GroupInvalidName,
+		},
+		{
+			name: "blank name",
+			group: entity.Group{
+				Name: "   ",
+				Owner: entity.Account{
+					Entity: base.Entity{ID: johnDoe.Entity.ID},
+				},
+			},
+			expectedErr: account.GroupInvalidName,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -68,7 +127,15 @@ func TestGroupUsecase_Create(t *testing.T) {
 			err := u.Create(ctx, &tc.group)
 
 			if tc.expectedErr != nil {
-				require.Error(t, err)
+				require.EqualError(t, err, tc.expectedErr.Error())
+				require.False(t, tc.group.ID.Valid())
+
+				groupRepo := repository.NewGroupRepository(pgTestSuite.db)
+				owned, err := groupRepo.ReadByOwner(ctx, tc.group.Owner.Entity.ID)
+				require.NoError(t, err)
+				for _, group := range owned {
+					require.NotEqual(t, tc.group.Name, group.Name)
+				}
 			} else {
 				require.NoError(t, err)
 
@@ -76,8 +143,16 @@ func TestGroupUsecase_Create(t *testing.T) {
 				createdGroup, err := groupRepo.ReadOne(ctx, tc.group.ID, tc.group.Owner.Entity.ID)
 				require.NoError(t, err)
 				require.Equal(t, tc.group.Name, createdGroup.Name)
-				require.NotEmpty(t, createdGroup.Members)
+				require.Len(t, createdGroup.Members, 1)
 				require.Equal(t, johnDoe.Entity.ID, createdGroup.Members[0].Entity.ID)
+
+				invitations, err := u.ReadGroupInvitations(ctx, tc.group.ID, johnDoe.Entity.ID)
+				require.NoError(t, err)
+				require.Len(t, invitations, len(tc.invited))
+				for i, invitation := range invitations {
+					require.Equal(t, tc.invited[i], invitation.Invitee.Entity.ID)
+					require.Equal(t, entity.GroupRoleViewer, invitation.Role)
+				}
 			}
 		})
 	}
@@ -255,7 +330,7 @@ func TestGroupUsecase_Update(t *testing.T) {
 				Owner:       seed.GroupBrockhampton.Owner,
 				Members:     []entity.Account{seed.AccountEarl, seed.AccountFrankOcean},
 			},
-			err: account.GroupOnlyTheOwnerCanEdit,
+			err: account.GroupOnlyAdminsCanEdit,
 		},
 	}
 
@@ -275,12 +350,627 @@ func TestGroupUsecase_Update(t *testing.T) {
 				require.Equal(t, group.Name, tc.group.Name)
 				require.Equal(t, group.Description, tc.group.Description)
 				require.Equal(t, group.Owner.Entity.ID, tc.group.Owner.Entity.ID)
-				for i, member := range group.Members {
-					require.Equal(t, member.Entity.ID, tc.group.Members[i].Entity.ID)
+				require.Len(t, group.Members, 1)
+				require.Equal(t, seed.AccountKendrickLamar.Entity.ID, group.Members[0].Entity.ID)
+
+				invitations, err := usecase.ReadGroupInvitations(ctx, tc.group.ID, tc.group.Owner.Entity.ID)
+				require.NoError(t, err)
+				invitees := make([]types.ID, 0, len(invitations))
+				for _, invitation := range invitations {
+					invitees = append(invitees, invitation.Invitee.Entity.ID)
 				}
+				require.ElementsMatch(t, []types.ID{seed.AccountEarl.Entity.ID, seed.AccountFrankOcean.Entity.ID}, invitees)
+			}
+		})
+	}
+}
+
+func TestGroupUsecase_UpdateRoles(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	usecase := setupGroupUsecase()
+
+	owner := createEmergencyAccessAccount(t, "group_roles_owner")
+	admin := createEmergencyAccessAccount(t, "group_roles_admin")
+	editor := createEmergencyAccessAccount(t, "group_roles_editor")
+	viewer := createEmergencyAccessAccount(t, "group_roles_viewer")
+	newcomer := createEmergencyAccessAccount(t, "group_roles_newcomer")
+
+	group := entity.Group{
+		Name:    "roles group",
+		Owner:   owner,
+		Members: []entity.Account{admin, editor, viewer},
+		Roles: map[types.ID]entity.GroupRole{
+			admin.Entity.ID:  entity.GroupRoleAdmin,
+			editor.Entity.ID: entity.GroupRoleEditor,
+		},
+	}
+	require.NoError(t, usecase.Create(ctx, &group))
+	acceptGroupInvitations(t, usecase, group.ID, admin, editor, viewer)
+
+	created, err := usecase.ReadOne(ctx, group.ID, owner.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[types.ID]entity.GroupRole{
+		owner.Entity.ID:  entity.GroupRoleOwner,
+		admin.Entity.ID:  entity.GroupRoleAdmin,
+		editor.Entity.ID: entity.GroupRoleEditor,
+		viewer.Entity.ID: entity.GroupRoleViewer,
+	}, created.Roles)
+
+	testcases := []struct {
+		name    string
+		editor  entity.Account
+		members []entity.Account
+		roles   map[types.ID]entity.GroupRole
+		err     error
+		want    map[types.ID]entity.GroupRole
+		invited entity.GroupRole
+	}{
+		{
+			name:    "viewer",
+			editor:  viewer,
+			members: []entity.Account{admin, editor, viewer, newcomer},
+			err:     account.GroupOnlyAdminsCanEdit,
+		},
+		{
+			name:    "editor",
+			editor:  editor,
+			members: []entity.Account{admin, editor, viewer, newcomer},
+			err:     account.GroupOnlyAdminsCanEdit,
+		},
+		{
+			name:    "admin making an admin",
+			editor:  admin,
+			members: []entity.Account{admin, editor, viewer},
+			roles:   map[types.ID]entity.GroupRole{viewer.Entity.ID: entity.GroupRoleAdmin},
+			err:     account.GroupOnlyTheOwnerCanManageAdmins,
+		},
+		{
+			name:    "admin removing an admin",
+			editor:  admin,
+			members: []entity.Account{editor, viewer},
+			err:     account.GroupOnlyTheOwnerCanManageAdmins,
+		},
+		{
+			name:    "second owner",
+			editor:  owner,
+			members: []entity.Account{admin, editor, viewer},
+			roles:   map[types.ID]entity.GroupRole{admin.Entity.ID: entity.GroupRoleOwner},
+			err:     account.GroupInvalidRole,
+		},
+		{
+			name:    "unknown role",
+			editor:  owner,
+			members: []entity.Account{admin, editor, viewer},
+			roles:   map[types.ID]entity.GroupRole{viewer.Entity.ID: "guest"},
+			err:     account.GroupInvalidRole,
+		},
+		{
+			name:    "admin managing the members below admin",
+			editor:  admin,
+			members: []entity.Account{admin, viewer, newcomer},
+			roles:   map[types.ID]entity.GroupRole{viewer.Entity.ID: entity.GroupRoleEditor},
+			want: map[types.ID]entity.GroupRole{
+				owner.Entity.ID:  entity.GroupRoleOwner,
+				admin.Entity.ID:  entity.GroupRoleAdmin,
+				viewer.Entity.ID: entity.GroupRoleEditor,
+			},
+			invited: entity.GroupRoleViewer,
+		},
+		{
+			name:    "owner making an admin",
+			editor:  owner,
+			members: []entity.Account{admin, viewer, newcomer},
+			roles:   map[types.ID]entity.GroupRole{newcomer.Entity.ID: entity.GroupRoleAdmin},
+			want: map[types.ID]entity.GroupRole{
+				owner.Entity.ID:  entity.GroupRoleOwner,
+				admin.Entity.ID:  entity.GroupRoleAdmin,
+				viewer.Entity.ID: entity.GroupRoleEditor,
+			},
+			invited: entity.GroupRoleAdmin,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := usecase.Update(ctx, tc.editor, entity.Group{
+				Entity:  base.Entity{ID: group.ID},
+				Name:    group.Name,
+				Owner:   tc.editor,
+				Members: tc.members,
+				Roles:   tc.roles,
+			})
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			updated, err := usecase.ReadOne(ctx, group.ID, owner.Entity.ID)
+			require.NoError(t, err)
+			require.Equal(t, owner.Entity.ID, updated.Owner.Entity.ID)
+			require.Equal(t, tc.want, updated.Roles)
+
+			invitations, err := usecase.ReadInvitations(ctx, newcomer.Entity.ID)
+			require.NoError(t, err)
+			require.Len(t, invitations, 1)
+			require.Equal(t, tc.invited, invitations[0].Role)
+		})
+	}
+}
+
+func TestGroupUsecase_EditMember(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	usecase := setupGroupUsecase()
+
+	owner := createEmergencyAccessAccount(t, "group_edit_member_owner")
+	admin := createEmergencyAccessAccount(t, "group_edit_member_admin")
+	viewer := createEmergencyAccessAccount(t, "group_edit_member_viewer")
+	stranger := createEmergencyAccessAccount(t, "group_edit_member_stranger")
+
+	group := entity.Group{
+		Name:    "edit member group",
+		Owner:   owner,
+		Members: []entity.Account{admin, viewer},
+		Roles:   map[types.ID]entity.GroupRole{admin.Entity.ID: entity.GroupRoleAdmin},
+	}
+	require.NoError(t, usecase.Create(ctx, &group))
+	acceptGroupInvitations(t, usecase, group.ID, admin, viewer)
+
+	testcases := []struct {
+		name     string
+		editorID types.ID
+		memberID types.ID
+		role     entity.GroupRole
+		err      error
+	}{
+		{
+			name:     "viewer",
+			editorID: viewer.Entity.ID,
+			memberID: admin.Entity.ID,
+			role:     entity.GroupRoleViewer,
+			err:      account.GroupOnlyAdminsCanEdit,
+		},
+		{
+			name:     "not a member",
+			editorID: owner.Entity.ID,
+			memberID: stranger.Entity.ID,
+			role:     entity.GroupRoleViewer,
+			err:      account.GroupMemberDoesNotExist,
+		},
+		{
+			name:     "the owner",
+			editorID: admin.Entity.ID,
+			memberID: owner.Entity.ID,
+			role:     entity.GroupRoleViewer,
+			err:      account.GroupOwnerRoleFixed,
+		},
+		{
+			name:     "admin making an admin",
+			editorID: admin.Entity.ID,
+			memberID: viewer.Entity.ID,
+			role:     entity.GroupRoleAdmin,
+			err:      account.GroupOnlyTheOwnerCanManageAdmins,
+		},
+		{
+			name:     "unknown role",
+			editorID: owner.Entity.ID,
+			memberID: viewer.Entity.ID,
+			role:     "guest",
+			err:      account.GroupInvalidRole,
+		},
+		{
+			name:     "admin making an editor",
+			editorID: admin.Entity.ID,
+			memberID: viewer.Entity.ID,
+			role:     entity.GroupRoleEditor,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := usecase.ChangeMemberRole(ctx, tc.editorID, group.ID, tc.memberID, tc.role)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	require.ErrorIs(t, usecase.RemoveMember(ctx, admin.Entity.ID, group.ID, owner.Entity.ID), account.GroupOwnerRoleFixed)
+	require.NoError(t, usecase.RemoveMember(ctx, admin.Entity.ID, group.ID, viewer.Entity.ID))
+
+	edited, err := usecase.ReadOne(ctx, group.ID, owner.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[types.ID]entity.GroupRole{
+		owner.Entity.ID: entity.GroupRoleOwner,
+		admin.Entity.ID: entity.GroupRoleAdmin,
+	}, edited.Roles)
+
+	// owner created the group, admin and viewer joined, admin edited and removed viewer
+	events, err := usecase.ReadMemberEvents(ctx, group.ID, admin.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 5)
+	require.Equal(t, entity.GroupMemberRemoved, events[0].Action())
+	require.Equal(t, admin.Username, events[0].Actor.Username)
+	require.Equal(t, viewer.Username, events[0].Member.Username)
+	require.Equal(t, entity.GroupMemberRoleChanged, events[1].Action())
+	require.Equal(t, viewer.Username, events[2].Actor.Username)
+
+	_, err = usecase.ReadMemberEvents(ctx, group.ID, viewer.Entity.ID)
+	require.ErrorIs(t, err, account.GroupDoesNotExist)
+}
+
+func TestGroupUsecase_UpdateConcurrently(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	usecase := setupGroupUsecase()
+
+	owner := createEmergencyAccessAccount(t, "group_concurrent_owner")
+	admin := createEmergencyAccessAccount(t, "group_concurrent_admin")
+	removed := createEmergencyAccessAccount(t, "group_concurrent_removed")
+	promoted := createEmergencyAccessAccount(t, "group_concurrent_promoted")
+
+	group := entity.Group{
+		Name:    "concurrent group",
+		Owner:   owner,
+		Members: []entity.Account{admin, removed, promoted},
+		Roles:   map[types.ID]entity.GroupRole{admin.Entity.ID: entity.GroupRoleAdmin},
+	}
+	require.NoError(t, usecase.Create(ctx, &group))
+	acceptGroupInvitations(t, usecase, group.ID, admin, removed, promoted)
+
+	// the owner removes a member while the admin promotes another one, each from the
+	// page it loaded before the other one saved
+	edits := []struct {
+		editor  entity.Account
+		members []entity.Account
+		roles   map[types.ID]entity.GroupRole
+	}{
+		{editor: owner, members: []entity.Account{admin, promoted}},
+		{
+			editor:  admin,
+			members: []entity.Account{admin, removed, promoted},
+			roles:   map[types.ID]entity.GroupRole{promoted.Entity.ID: entity.GroupRoleEditor},
+		},
+	}
+
+	errs := make([]error, len(edits))
+	var wg sync.WaitGroup
+	for i, edit := range edits {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = usecase.Update(ctx, edit.editor, entity.Group{
+				Entity:  base.Entity{ID: group.ID},
+				Name:    group.Name,
+				Owner:   edit.editor,
+				Members: edit.members,
+				Roles:   edit.roles,
+			})
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	// neither edit undoes the other one, the removed member is at most invited again by
+	// the admin who still saw it
+	updated, err := usecase.ReadOne(ctx, group.ID, owner.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[types.ID]entity.GroupRole{
+		owner.Entity.ID:    entity.GroupRoleOwner,
+		admin.Entity.ID:    entity.GroupRoleAdmin,
+		promoted.Entity.ID: entity.GroupRoleEditor,
+	}, updated.Roles)
+}
+
+func TestGroupUsecase_Invite(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	usecase := setupGroupUsecase()
+
+	owner := createEmergencyAccessAccount(t, "group_invite_owner")
+	admin := createEmergencyAccessAccount(t, "group_invite_admin")
+	viewer := createEmergencyAccessAccount(t, "group_invite_viewer")
+	invitee := createEmergencyAccessAccount(t, "group_invite_invitee")
+
+	group := entity.Group{
+		Name:    "invite group",
+		Owner:   owner,
+		Members: []entity.Account{admin, viewer},
+		Roles:   map[types.ID]entity.GroupRole{admin.Entity.ID: entity.GroupRoleAdmin},
+	}
+	require.NoError(t, usecase.Create(ctx, &group))
+	acceptGroupInvitations(t, usecase, group.ID, admin, viewer)
+
+	testcases := []struct {
+		name    string
+		inviter entity.Account
+		groupID types.ID
+		email   string
+		role    entity.GroupRole
+		err     error
+	}{
+		{
+			name:    "not a member",
+			inviter: invitee,
+			groupID: group.ID,
+			email:   "group_invite_someone@example.com",
+			role:    entity.GroupRoleViewer,
+			err:     account.GroupDoesNotExist,
+		},
+		{
+			name:    "viewer",
+			inviter: viewer,
+			groupID: group.ID,
+			email:   invitee.Email,
+			role:    entity.GroupRoleViewer,
+			err:     account.GroupOnlyAdminsCanEdit,
+		},
+		{
+			name:    "owner role",
+			inviter: owner,
+			groupID: group.ID,
+			email:   invitee.Email,
+			role:    entity.GroupRoleOwner,
+			err:     account.GroupInvalidRole,
+		},
+		{
+			name:    "admin inviting an admin",
+			inviter: admin,
+			groupID: group.ID,
+			email:   invitee.Email,
+			role:    entity.GroupRoleAdmin,
+			err:     account.GroupOnlyTheOwnerCanManageAdmins,
+		},
+		{
+			name:    "member",
+			inviter: admin,
+			groupID: group.ID,
+			email:   viewer.Email,
+			role:    entity.GroupRoleEditor,
+			err:     account.GroupAlreadyMember,
+		},
+		{
+			name:    "account",
+			inviter: admin,
+			groupID: group.ID,
+			email:   invitee.Email,
+			role:    entity.GroupRoleEditor,
+		},
+		{
+			name:    "email without an account",
+			inviter: owner,
+			groupID: group.ID,
+			email:   "group_invite_newcomer@example.com",
+			role:    entity.GroupRoleAdmin,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			invitation, err := usecase.Invite(ctx, tc.inviter.Entity.ID, tc.groupID, tc.email, tc.role)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
 			}
+			require.NoError(t, err)
+			require.True(t, invitation.ID.Valid())
+			require.Equal(t, tc.role, invitation.Role)
+			require.True(t, invitation.ExpiresAt.After(time.Now()))
+			require.NotEmpty(t, mailer.Messages(tc.email))
 		})
 	}
+
+	invitations, err := usecase.ReadInvitations(ctx, invitee.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	require.Equal(t, group.ID, invitations[0].Group.ID)
+	require.Equal(t, admin.Entity.ID, invitations[0].Inviter.Entity.ID)
+
+	pending, err := usecase.ReadGroupInvitations(ctx, group.ID, viewer.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+}
+
+func TestGroupUsecase_InviteLimit(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	usecase := setupGroupUsecase()
+
+	owner := createEmergencyAccessAccount(t, "group_invite_limit_owner")
+	group := entity.Group{Name: "invite limit group", Owner: owner}
+	require.NoError(t, usecase.Create(ctx, &group))
+
+	for i := range conf.MaxGroupInvitations {
+		email := fmt.Sprintf("group_invite_limit_%d@example.com", i)
+		_, err := usecase.Invite(ctx, owner.Entity.ID, group.ID, email, entity.GroupRoleViewer)
+		require.NoError(t, err)
+	}
+
+	email := "group_invite_limit_spam@example.com"
+	_, err := usecase.Invite(ctx, owner.Entity.ID, group.ID, email, entity.GroupRoleViewer)
+	require.ErrorIs(t, err, account.GroupTooManyInvitations)
+	require.Empty(t, mailer.Messages(email))
+}
+
+func TestGroupUsecase_AnswerInvitation(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	usecase := setupGroupUsecase()
+
+	owner := createEmergencyAccessAccount(t, "group_answer_owner")
+	admin := createEmergencyAccessAccount(t, "group_answer_admin")
+	accepting := createEmergencyAccessAccount(t, "group_answer_accepting")
+	declining := createEmergencyAccessAccount(t, "group_answer_declining")
+	cancelled := createEmergencyAccessAccount(t, "group_answer_cancelled")
+
+	group := entity.Group{
+		Name:    "answer group",
+		Owner:   owner,
+		Members: []entity.Account{admin},
+		Roles:   map[types.ID]entity.GroupRole{admin.Entity.ID: entity.GroupRoleAdmin},
+	}
+	require.NoError(t, usecase.Create(ctx, &group))
+	acceptGroupInvitations(t, usecase, group.ID, admin)
+
+	invite := func(invitee entity.Account, role entity.GroupRole) types.ID {
+		invitation, err := usecase.Invite(ctx, owner.Entity.ID, group.ID, invitee.Email, role)
+		require.NoError(t, err)
+		return invitation.ID
+	}
+
+	t.Run("accept", func(t *testing.T) {
+		id := invite(accepting, entity.GroupRoleEditor)
+
+		require.ErrorIs(t, usecase.AcceptInvitation(ctx, declining.Entity.ID, id), account.GroupInvitationDoesNotExist)
+		require.NoError(t, usecase.AcceptInvitation(ctx, accepting.Entity.ID, id))
+		require.ErrorIs(t, usecase.AcceptInvitation(ctx, accepting.Entity.ID, id), account.GroupInvitationDoesNotExist)
+
+		joined, err := usecase.ReadOne(ctx, group.ID, accepting.Entity.ID)
+		require.NoError(t, err)
+		require.Equal(t, entity.GroupRoleEditor, joined.Role(accepting.Entity.ID))
+	})
+
+	t.Run("decline", func(t *testing.T) {
+		id := invite(declining, entity.GroupRoleViewer)
+
+		require.NoError(t, usecase.DeclineInvitation(ctx, declining.Entity.ID, id))
+		require.ErrorIs(t, usecase.AcceptInvitation(ctx, declining.Entity.ID, id), account.GroupInvitationDoesNotExist)
+
+		joined, err := usecase.ReadOne(ctx, group.ID, declining.Entity.ID)
+		require.NoError(t, err)
+		require.False(t, joined.ID.Valid())
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		id := invite(cancelled, entity.GroupRoleAdmin)
+
+		require.ErrorIs(t, usecase.CancelInvitation(ctx, accepting.Entity.ID, id), account.GroupOnlyAdminsCanEdit)
+		require.ErrorIs(t, usecase.CancelInvitation(ctx, admin.Entity.ID, id), account.GroupOnlyTheOwnerCanManageAdmins)
+		require.ErrorIs(t, usecase.CancelInvitation(ctx, cancelled.Entity.ID, id), account.GroupInvitationDoesNotExist)
+		require.NoError(t, usecase.CancelInvitation(ctx, owner.Entity.ID, id))
+
+		invitations, err := usecase.ReadInvitations(ctx, cancelled.Entity.ID)
+		require.NoError(t, err)
+		require.Empty(t, invitations)
+	})
+}
+
+func TestGroupUsecase_Leave(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	usecase := setupGroupUsecase()
+
+	owner := createEmergencyAccessAccount(t, "group_leave_owner")
+	member := createEmergencyAccessAccount(t, "group_leave_member")
+	stranger := createEmergencyAccessAccount(t, "group_leave_stranger")
+
+	group := entity.Group{Name: "leave group", Owner: owner, Members: []entity.Account{member}}
+	require.NoError(t, usecase.Create(ctx, &group))
+	acceptGroupInvitations(t, usecase, group.ID, member)
+
+	require.ErrorIs(t, usecase.Leave(ctx, owner.Entity.ID, group.ID), account.GroupOwnerCannotLeave)
+	require.ErrorIs(t, usecase.Leave(ctx, stranger.Entity.ID, group.ID), account.GroupDoesNotExist)
+	require.NoError(t, usecase.Leave(ctx, member.Entity.ID, group.ID))
+
+	left, err := usecase.ReadOne(ctx, group.ID, member.Entity.ID)
+	require.NoError(t, err)
+	require.False(t, left.ID.Valid())
+}
+
+func TestGroupUsecase_Ownership(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	usecase := setupGroupUsecase()
+
+	owner := createEmergencyAccessAccount(t, "group_ownership_owner")
+	admin := createEmergencyAccessAccount(t, "group_ownership_admin")
+	taken := createEmergencyAccessAccount(t, "group_ownership_taken")
+	stranger := createEmergencyAccessAccount(t, "group_ownership_stranger")
+
+	group := entity.Group{
+		Name:    "ownership group",
+		Owner:   owner,
+		Members: []entity.Account{admin, taken},
+		Roles:   map[types.ID]entity.GroupRole{admin.Entity.ID: entity.GroupRoleAdmin},
+	}
+	require.NoError(t, usecase.Create(ctx, &group))
+	acceptGroupInvitations(t, usecase, group.ID, admin, taken)
+
+	// taken already owns a group with the same name
+	require.NoError(t, usecase.Create(ctx, &entity.Group{Name: group.Name, Owner: taken}))
+
+	testcases := []struct {
+		name       string
+		ownerID    types.ID
+		newOwnerID types.ID
+		err        error
+	}{
+		{
+			name:       "not a member",
+			ownerID:    stranger.Entity.ID,
+			newOwnerID: admin.Entity.ID,
+			err:        account.GroupDoesNotExist,
+		},
+		{
+			name:       "not the owner",
+			ownerID:    admin.Entity.ID,
+			newOwnerID: taken.Entity.ID,
+			err:        account.GroupOnlyTheOwnerCanTransfer,
+		},
+		{
+			name:       "to a stranger",
+			ownerID:    owner.Entity.ID,
+			newOwnerID: stranger.Entity.ID,
+			err:        account.GroupInvalidNewOwner,
+		},
+		{
+			name:       "to the owner",
+			ownerID:    owner.Entity.ID,
+			newOwnerID: owner.Entity.ID,
+			err:        account.GroupInvalidNewOwner,
+		},
+		{
+			name:       "name taken",
+			ownerID:    owner.Entity.ID,
+			newOwnerID: taken.Entity.ID,
+			err:        account.GroupNewOwnerNameTaken,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := usecase.OfferOwnership(ctx, tc.ownerID, group.ID, tc.newOwnerID)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	require.NoError(t, usecase.OfferOwnership(ctx, owner.Entity.ID, group.ID, admin.Entity.ID))
+	require.NotEmpty(t, mailer.Messages(admin.Email))
+
+	offers, err := usecase.ReadOwnershipOffers(ctx, admin.Entity.ID)
+	require.NoError(t, err)
+	require.Len(t, offers, 1)
+	require.Equal(t, group.ID, offers[0].ID)
+
+	// the group stays with its owner until the offer is accepted
+	require.ErrorIs(t, usecase.Leave(ctx, owner.Entity.ID, group.ID), account.GroupOwnerCannotLeave)
+	require.ErrorIs(t, usecase.AcceptOwnership(ctx, taken.Entity.ID, group.ID), account.GroupOwnershipOfferDoesNotExist)
+	require.NoError(t, usecase.AcceptOwnership(ctx, admin.Entity.ID, group.ID))
+	require.ErrorIs(t, usecase.AcceptOwnership(ctx, admin.Entity.ID, group.ID), account.GroupOwnershipOfferDoesNotExist)
+
+	handedOver, err := usecase.ReadOne(ctx, group.ID, owner.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, admin.Entity.ID, handedOver.Owner.Entity.ID)
+	require.Equal(t, entity.GroupRoleAdmin, handedOver.Role(owner.Entity.ID))
+
+	// the old owner can leave now
+	require.NoError(t, usecase.Leave(ctx, owner.Entity.ID, group.ID))
+
+	err = usecase.DeleteOwnershipOffer(ctx, admin.Entity.ID, group.ID)
+	require.ErrorIs(t, err, account.GroupOwnershipOfferDoesNotExist)
 }
 
 func TestGroupUsecase_Delete(t *testing.T) {
@@ -373,9 +1063,28 @@ func TestGroupRepository_ReadByUsername(t *testing.T) {
 	}
 }
 
+// acceptGroupInvitations makes the accounts members of the group they were invited to.
+func acceptGroupInvitations(t *testing.T, u usecase.GroupUsecase, groupID types.ID, accounts ...entity.Account) {
+	ctx := context.Background()
+
+	for _, acc := range accounts {
+		invitations, err := u.ReadInvitations(ctx, acc.Entity.ID)
+		require.NoError(t, err)
+
+		for _, invitation := range invitations {
+			if invitation.Group.ID == groupID {
+				require.NoError(t, u.AcceptInvitation(ctx, acc.Entity.ID, invitation.ID))
+			}
+		}
+	}
+}
+
 func setupGroupUsecase() usecase.GroupUsecase {
 	groupRepo := repository.NewGroupRepository(pgTestSuite.db)
 	accountRepo := repository.NewAccountRepository(pgTestSuite.db)
+	invitationRepo := repository.NewGroupInvitationRepository(pgTestSuite.db)
+	atRepo := repository.NewAttemptRepository(redisClient)
+	txManager := database.NewTxManager(pgTestSuite.db)
 
-	return usecase.NewGroupUsecase(groupRepo, accountRepo)
+	return usecase.NewGroupUsecase(groupRepo, accountRepo, invitationRepo, atRepo, txManager, mailer, conf)
 }
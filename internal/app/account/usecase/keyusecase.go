@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/jackc/pgx/v5"
+)
+
+// KeyUsecase hands the wrapped keys of an account back to its client. The client
+// proves the master password with a fresh OPAQUE login, since the keys are wrapped
+// under the export key and the vault key and the session alone is not enough.
+type KeyUsecase struct {
+	accountRepo     repository.AccountRepository
+	vaultUnlockRepo repository.VaultUnlockRepository
+
+	opaqueServer opaque.OpaqueService
+
+	config *config.Config
+}
+
+func NewKeyUsecase(aRepo repository.AccountRepository, vuRepo repository.VaultUnlockRepository,
+	opaqueServer opaque.OpaqueService, config *config.Config) KeyUsecase {
+	return KeyUsecase{
+		accountRepo:     aRepo,
+		vaultUnlockRepo: vuRepo,
+		opaqueServer:    opaqueServer,
+		config:          config,
+	}
+}
+
+// Read returns the account with its public key, so the page knows whether the key
+// pair is published yet.
+func (u *KeyUsecase) Read(ctx context.Context, accountID types.ID) (entity.Account, error) {
+	acc, err := u.accountRepo.ReadByID(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", accountID)
+		return entity.Account{}, errors.NewServerError()
+	}
+
+	return acc, nil
+}
+
+// Publish stores the key pair generated by the client. An account publishes its key
+// pair only once.
+func (u *KeyUsecase) Publish(ctx context.Context, accountID types.ID, publicKey, encryptedPrivateKey []byte) error {
+	err := u.accountRepo.UpdateKeys(ctx, accountID, publicKey, encryptedPrivateKey)
+	if err == pgx.ErrNoRows {
+		return account.AccountKeysExist
+	}
+
+	if err != nil {
+		log.ErrorLogger.Error("error at publishing account keys", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+// UnlockInit starts the login with the master password and returns the KE2 message.
+func (u *KeyUsecase) UnlockInit(ctx context.Context, accountID types.ID, message []byte) ([]byte, types.CacheID, error) {
+	acc, err := u.accountRepo.ReadByID(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	response, state, err := u.opaqueServer.LoginInit(message, acc.OpaqueRecord, acc.OpaqueCredentialID, acc.Username, acc.OpaqueKeyVersion)
+	if err != nil {
+		log.ErrorLogger.Error("error at vault unlock initiation", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	unlockID, err := generateRandomID()
+	if err != nil {
+		log.ErrorLogger.Error("error generating vault unlock id", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	unlock := entity.VaultUnlock{
+		CacheEntity: base.CacheEntity{
+			ID:       types.CacheID(unlockID),
+			Duration: time.Minute * time.Duration(u.config.TwoFactorDuration),
+		},
+		AccountID: accountID,
+		AKEState:  state,
+	}
+
+	if err := u.vaultUnlockRepo.Create(ctx, unlock); err != nil {
+		log.ErrorLogger.Error("error at saving vault unlock", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	return response, unlock.ID, nil
+}
+
+// UnlockFinalize checks the KE3 message and returns the account with its wrapped
+// vault key and private key. The unlock can be used only once.
+func (u *KeyUsecase) UnlockFinalize(ctx context.Context, accountID types.ID, unlockID types.CacheID, message []byte) (entity.Account, error) {
+	exist, err := u.vaultUnlockRepo.Exist(ctx, unlockID)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking vault unlock existence", "error", err.Error())
+		return entity.Account{}, errors.NewServerError()
+	}
+
+	if !exist {
+		return entity.Account{}, account.VaultUnlockDoesNotExist
+	}
+
+	unlock, err := u.vaultUnlockRepo.Get(ctx, unlockID)
+	if err != nil {
+		log.ErrorLogger.Error("error at getting vault unlock", "error", err.Error())
+		return entity.Account{}, errors.NewServerError()
+	}
+
+	if unlock.AccountID != accountID {
+		return entity.Account{}, account.VaultUnlockDoesNotExist
+	}
+
+	if err := u.vaultUnlockRepo.Delete(ctx, unlockID); err != nil {
+		log.ErrorLogger.Error("error at deleting vault unlock", "error", err.Error(), "account_id", accountID)
+		return entity.Account{}, errors.NewServerError()
+	}
+
+	if _, err := u.opaqueServer.LoginFinalize(message, unlock.AKEState); err != nil {
+		log.WarningLogger.Warn("vault unlock rejected because of invalid password", "account_id", accountID)
+		return entity.Account{}, account.AuthInvalidPassword
+	}
+
+	acc, err := u.accountRepo.ReadByID(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", accountID)
+		return entity.Account{}, errors.NewServerError()
+	}
+
+	return acc, nil
+}
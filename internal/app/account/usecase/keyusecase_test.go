@@ -0,0 +1,88 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	bytemareOpaque "github.com/bytemare/opaque"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyUsecase_Publish(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	acc := createEmergencyAccessAccount(t, "keys_publish_user")
+	u := setupKeyUsecase()
+
+	err := u.Publish(ctx, acc.Entity.ID, []byte("public key"), []byte("private key"))
+	require.NoError(t, err)
+
+	// published keys can not be replaced
+	err = u.Publish(ctx, acc.Entity.ID, []byte("another public key"), []byte("another private key"))
+	require.ErrorIs(t, err, account.AccountKeysExist)
+
+	saved, err := u.Read(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("public key"), saved.PublicKey)
+	require.Equal(t, []byte("private key"), saved.EncryptedPrivateKey)
+}
+
+func TestKeyUsecase_Unlock(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	password := []byte("strong-password")
+	acc := createPasswordAccount(t, "keys_unlock_user", string(password))
+	u := setupKeyUsecase()
+	require.NoError(t, u.Publish(ctx, acc.Entity.ID, []byte("public key"), []byte("private key")))
+
+	// ---------- wrong password ----------
+	client := newOpaqueClient(t)
+	_, unlockID, err := u.UnlockInit(ctx, acc.Entity.ID, client.LoginInit([]byte("wrong-password")).Serialize())
+	require.NoError(t, err)
+
+	_, err = u.UnlockFinalize(ctx, acc.Entity.ID, unlockID, make([]byte, 32))
+	require.ErrorIs(t, err, account.AuthInvalidPassword)
+
+	// ---------- right password ----------
+	client = newOpaqueClient(t)
+	ke2Message, unlockID, err := u.UnlockInit(ctx, acc.Entity.ID, client.LoginInit(password).Serialize())
+	require.NoError(t, err)
+
+	ke2, err := client.Deserialize.KE2(ke2Message)
+	require.NoError(t, err)
+
+	ke3, _, err := client.LoginFinish(ke2, bytemareOpaque.ClientLoginFinishOptions{
+		ClientIdentity: []byte(acc.Username),
+		ServerIdentity: []byte(conf.Opaque.ServerID),
+	})
+	require.NoError(t, err)
+
+	_, err = u.UnlockFinalize(ctx, acc.Entity.ID+1, unlockID, ke3.Serialize())
+	require.ErrorIs(t, err, account.VaultUnlockDoesNotExist)
+
+	unlocked, err := u.UnlockFinalize(ctx, acc.Entity.ID, unlockID, ke3.Serialize())
+	require.NoError(t, err)
+	require.Equal(t, []byte("public key"), unlocked.PublicKey)
+	require.Equal(t, []byte("private key"), unlocked.EncryptedPrivateKey)
+
+	// the unlock can not be replayed
+	_, err = u.UnlockFinalize(ctx, acc.Entity.ID, unlockID, ke3.Serialize())
+	require.ErrorIs(t, err, account.VaultUnlockDoesNotExist)
+}
+
+func setupKeyUsecase() usecase.KeyUsecase {
+	aRepo := repository.NewAccountRepository(pgTestSuite.db)
+	vuRepo := repository.NewVaultUnlockRepository(redisClient)
+	opaqueAdaptor, err := opaque.New(conf)
+	if err != nil {
+		panic(err)
+	}
+
+	return usecase.NewKeyUsecase(aRepo, vuRepo, opaqueAdaptor, conf)
+}
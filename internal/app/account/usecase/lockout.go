@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+)
+
+// lockout counts the failed attempts of the logins, the codes and every other check of
+// the master password, so each of them locks out the same way.
+type lockout struct {
+	attemptRepo repository.AttemptRepository
+	config      *config.Config
+}
+
+func (l lockout) checkLock(ctx context.Context, scope entity.AttemptScope, identifier string, lockErr error) error {
+	lockedFor, err := l.attemptRepo.LockedFor(ctx, scope, identifier)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking lock", "error", err.Error(), "scope", scope, "identifier", identifier)
+		return errors.NewServerError()
+	}
+
+	if lockedFor > 0 {
+		return lockErr
+	}
+
+	return nil
+}
+
+// registerFailure increments the attempts of the identifier and locks it once the
+// attempts reach maxAttempts. Every attempt past the limit doubles the lockout.
+func (l lockout) registerFailure(ctx context.Context, scope entity.AttemptScope, identifier string, maxAttempts int) error {
+	window := time.Minute * time.Duration(l.config.AttemptWindow)
+	count, err := l.attemptRepo.Increment(ctx, scope, identifier, window)
+	if err != nil {
+		log.ErrorLogger.Error("error at counting attempts", "error", err.Error(), "scope", scope, "identifier", identifier)
+		return errors.NewServerError()
+	}
+
+	if count < int64(maxAttempts) {
+		return nil
+	}
+
+	duration := lockoutDuration(count-int64(maxAttempts), l.config.LockoutDuration, l.config.MaxLockoutDuration)
+	if err := l.attemptRepo.Lock(ctx, scope, identifier, duration); err != nil {
+		log.ErrorLogger.Error("error at locking", "error", err.Error(), "scope", scope, "identifier", identifier)
+		return errors.NewServerError()
+	}
+
+	log.WarningLogger.Warn("locked after too many attempts", "scope", scope, "identifier", identifier, "duration", duration.String())
+	return nil
+}
+
+func lockoutDuration(exceeded int64, baseMinutes, maxMinutes int) time.Duration {
+	maxDuration := time.Minute * time.Duration(maxMinutes)
+
+	// the shift would overflow long before reaching this point
+	if exceeded >= 32 {
+		return maxDuration
+	}
+
+	duration := time.Minute * time.Duration(baseMinutes) << exceeded
+	if duration > maxDuration {
+		return maxDuration
+	}
+
+	return duration
+}
+
+// countPasswordAttempt counts a check of the master password against the username the
+// way the login does. The client learns whether the password is right from the KE2
+// message already, so the attempt is counted before it is answered.
+func (l lockout) countPasswordAttempt(ctx context.Context, username string) error {
+	if err := l.checkLock(ctx, entity.AttemptScopeUsername, username, account.AuthAccountLocked); err != nil {
+		return err
+	}
+
+	return l.registerFailure(ctx, entity.AttemptScopeUsername, username, l.config.MaxLoginAttempts)
+}
+
+// passwordProven resets the attempts of the username once the KE3 message proved the
+// master password.
+func (l lockout) passwordProven(ctx context.Context, username string) error {
+	if err := l.attemptRepo.Reset(ctx, entity.AttemptScopeUsername, username); err != nil {
+		log.ErrorLogger.Error("error at resetting username attempts", "error", err.Error(), "username", username)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
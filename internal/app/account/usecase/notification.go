@@ -0,0 +1,22 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+)
+
+// notify sends a security notification. A failed notification is only logged, it
+// must not undo the change it reports.
+func notify(ctx context.Context, mailer mail.Mailer, to, template string, data any) {
+	message, err := mail.NewMessage(to, template, data)
+	if err != nil {
+		log.ErrorLogger.Error("error at rendering notification", "error", err.Error(), "template", template)
+		return
+	}
+
+	if err := mailer.Send(ctx, message); err != nil {
+		log.ErrorLogger.Error("error at sending notification", "error", err.Error(), "template", template)
+	}
+}
@@ -0,0 +1,281 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+)
+
+// PasswordUsecase changes the master password in three steps. The user proves the
+// current password with a fresh OPAQUE login, registers the new password and then
+// sends the new record along with the vault key wrapped under the new export key.
+type PasswordUsecase struct {
+	accountRepo        repository.AccountRepository
+	passwordChangeRepo repository.PasswordChangeRepository
+	sessionRepo        repository.SessionRepository
+	lockout
+
+	opaqueServer opaque.OpaqueService
+	mailer       mail.Mailer
+
+	config *config.Config
+}
+
+func NewPasswordUsecase(aRepo repository.AccountRepository, pcRepo repository.PasswordChangeRepository,
+	sRepo repository.SessionRepository, atRepo repository.AttemptRepository, opaqueServer opaque.OpaqueService,
+	mailer mail.Mailer, config *config.Config) PasswordUsecase {
+	return PasswordUsecase{
+		accountRepo:        aRepo,
+		passwordChangeRepo: pcRepo,
+		sessionRepo:        sRepo,
+		lockout:            lockout{attemptRepo: atRepo, config: config},
+		opaqueServer:       opaqueServer,
+		mailer:             mailer,
+		config:             config,
+	}
+}
+
+// ChangeInit starts the login with the current password and returns the KE2 message.
+// The attempt counts against the username like a login does, so a stolen session can
+// not guess the master password here.
+func (u *PasswordUsecase) ChangeInit(ctx context.Context, accountID types.ID, message []byte) ([]byte, types.CacheID, error) {
+	acc, err := u.accountRepo.ReadByID(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	if err := u.countPasswordAttempt(ctx, acc.Username); err != nil {
+		return nil, types.CacheID(""), err
+	}
+
+	response, state, err := u.opaqueServer.LoginInit(message, acc.OpaqueRecord, acc.OpaqueCredentialID, acc.Username, acc.OpaqueKeyVersion)
+	if err != nil {
+		log.ErrorLogger.Error("error at password change login initiation", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	changeID, err := generateRandomID()
+	if err != nil {
+		log.ErrorLogger.Error("error generating password change id", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	change := entity.PasswordChange{
+		CacheEntity: base.CacheEntity{ID: types.CacheID(changeID), Duration: u.changeDuration()},
+		AccountID:   accountID,
+		Username:    acc.Username,
+		AKEState:    state,
+	}
+
+	err = u.passwordChangeRepo.Create(ctx, change)
+	if err != nil {
+		log.ErrorLogger.Error("error at saving password change", "error", err.Error(), "account_id", accountID)
+		return nil, types.CacheID(""), errors.NewServerError()
+	}
+
+	return response, change.ID, nil
+}
+
+// ChangeVerify finishes the login with the client's KE3 message and, once the current
+// password is proven, answers the registration request of the new password. The
+// wrapped vault key is returned so the client can unwrap it with the old export key.
+func (u *PasswordUsecase) ChangeVerify(ctx context.Context, accountID types.ID, changeID types.CacheID,
+	loginMessage, registrationMessage []byte) ([]byte, []byte, error) {
+	change, err := u.getChange(ctx, accountID, changeID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if change.Verified {
+		return nil, nil, account.PasswordChangeDoesNotExist
+	}
+
+	if _, err := u.opaqueServer.LoginFinalize(loginMessage, change.AKEState); err != nil {
+		if err := u.passwordChangeRepo.Delete(ctx, changeID); err != nil {
+			log.ErrorLogger.Error("error at deleting password change", "error", err.Error(), "account_id", accountID)
+			return nil, nil, errors.NewServerError()
+		}
+
+		log.WarningLogger.Warn("password change rejected because of invalid current password", "account_id", accountID)
+		return nil, nil, account.AuthInvalidPassword
+	}
+
+	if err := u.passwordProven(ctx, change.Username); err != nil {
+		return nil, nil, err
+	}
+
+	acc, err := u.accountRepo.ReadByID(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", accountID)
+		return nil, nil, errors.NewServerError()
+	}
+
+	response, opaqueRegistrationID, err := u.opaqueServer.RegisterInit(registrationMessage, accountCredentialID(acc))
+	if err != nil {
+		log.ErrorLogger.Error("error at password change registration initiation", "error", err.Error(), "account_id", accountID)
+		return nil, nil, errors.NewServerError()
+	}
+
+	change.Verified = true
+	change.AKEState = nil
+	change.CredentialID = opaqueRegistrationID
+	change.Duration = u.changeDuration()
+
+	err = u.passwordChangeRepo.Create(ctx, change)
+	if err != nil {
+		log.ErrorLogger.Error("error at saving password change", "error", err.Error(), "account_id", accountID)
+		return nil, nil, errors.NewServerError()
+	}
+
+	return response, acc.EncryptedVaultKey, nil
+}
+
+// ChangeFinalize stores the new opaque record together with the re-wrapped vault key
+// and revokes every session of the account except the current one.
+func (u *PasswordUsecase) ChangeFinalize(ctx context.Context, accountID types.ID, currentSessionID, changeID types.CacheID,
+	message, encryptedVaultKey []byte) error {
+	change, err := u.getChange(ctx, accountID, changeID)
+	if err != nil {
+		return err
+	}
+
+	if !change.Verified {
+		return account.PasswordChangeDoesNotExist
+	}
+
+	if err := u.storeCredentials(ctx, change, message, encryptedVaultKey); err != nil {
+		return err
+	}
+
+	sessions, err := u.sessionRepo.ReadByAccount(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading sessions", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	for _, session := range sessions {
+		if session.ID == currentSessionID {
+			continue
+		}
+
+		if err := u.sessionRepo.Delete(ctx, accountID, session.ID); err != nil {
+			log.ErrorLogger.Error("error at revoking session", "error", err.Error(), "account_id", accountID)
+			return errors.NewServerError()
+		}
+	}
+
+	acc, err := u.accountRepo.ReadByID(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	notify(ctx, u.mailer, acc.Email, mail.TemplatePasswordChanged, map[string]any{
+		"Username": acc.Username,
+		"Time":     time.Now(),
+	})
+
+	log.InfoLogger.Info("password changed for account", "account_id", accountID)
+	return nil
+}
+
+// RekeyFinalize stores the record the client registered again with the same password,
+// moving the account to the current server keys. Unlike ChangeFinalize it keeps the
+// sessions and sends no mail, so it is refused to the accounts already on those keys.
+func (u *PasswordUsecase) RekeyFinalize(ctx context.Context, accountID types.ID, changeID types.CacheID,
+	message, encryptedVaultKey []byte) error {
+	change, err := u.getChange(ctx, accountID, changeID)
+	if err != nil {
+		return err
+	}
+
+	if !change.Verified {
+		return account.PasswordChangeDoesNotExist
+	}
+
+	acc, err := u.accountRepo.ReadByID(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading account by id", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	if acc.OpaqueKeyVersion == u.opaqueServer.KeyVersion() {
+		return account.PasswordRekeyNotNeeded
+	}
+
+	if err := u.storeCredentials(ctx, change, message, encryptedVaultKey); err != nil {
+		return err
+	}
+
+	log.InfoLogger.Info("password registered under new server keys", "account_id", accountID,
+		"old_version", acc.OpaqueKeyVersion, "new_version", u.opaqueServer.KeyVersion())
+	return nil
+}
+
+// storeCredentials finishes the registration of the verified change and replaces the
+// credentials of the account with it. The vault key wrapped under the new export key
+// is required, the vault could not be opened with the new password without it.
+func (u *PasswordUsecase) storeCredentials(ctx context.Context, change entity.PasswordChange,
+	message, encryptedVaultKey []byte) error {
+	if len(encryptedVaultKey) == 0 {
+		return account.PasswordVaultKeyMissing
+	}
+
+	opaqueRecord, credentialID, keyVersion, err := u.opaqueServer.RegisterFinalize(message, change.CredentialID, change.Username)
+	if err != nil {
+		log.ErrorLogger.Error("error at password change registration finalization", "error", err.Error(), "account_id", change.AccountID)
+		return errors.NewServerError()
+	}
+
+	err = u.accountRepo.UpdateCredentials(ctx, change.AccountID, opaqueRecord, credentialID, encryptedVaultKey, keyVersion)
+	if err != nil {
+		log.ErrorLogger.Error("error at updating account credentials", "error", err.Error(), "account_id", change.AccountID)
+		return errors.NewServerError()
+	}
+
+	if err := u.passwordChangeRepo.Delete(ctx, change.ID); err != nil {
+		log.ErrorLogger.Error("error at deleting password change", "error", err.Error(), "account_id", change.AccountID)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+func (u *PasswordUsecase) getChange(ctx context.Context, accountID types.ID, changeID types.CacheID) (entity.PasswordChange, error) {
+	exist, err := u.passwordChangeRepo.Exist(ctx, changeID)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking password change existence", "error", err.Error())
+		return entity.PasswordChange{}, errors.NewServerError()
+	}
+
+	if !exist {
+		return entity.PasswordChange{}, account.PasswordChangeDoesNotExist
+	}
+
+	change, err := u.passwordChangeRepo.Get(ctx, changeID)
+	if err != nil {
+		log.ErrorLogger.Error("error at getting password change", "error", err.Error())
+		return entity.PasswordChange{}, errors.NewServerError()
+	}
+
+	if change.AccountID != accountID {
+		return entity.PasswordChange{}, account.PasswordChangeDoesNotExist
+	}
+
+	return change, nil
+}
+
+func (u *PasswordUsecase) changeDuration() time.Duration {
+	return time.Minute * time.Duration(u.config.TwoFactorDuration)
+}
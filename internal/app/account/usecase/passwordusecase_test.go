@@ -0,0 +1,396 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/oprfutils"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/bytemare/ksf"
+	bytemareOpaque "github.com/bytemare/opaque"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordUsecase_ChangeInit(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	acc := createPasswordAccount(t, "change_init_user", "strong-password")
+	client := newOpaqueClient(t)
+	message := client.LoginInit([]byte("strong-password")).Serialize()
+
+	testcases := []struct {
+		name        string
+		accountID   types.ID
+		message     []byte
+		expectedErr error
+	}{
+		{
+			name:        "success",
+			accountID:   acc.Entity.ID,
+			message:     message,
+			expectedErr: nil,
+		},
+		{
+			name:        "invalid opaque message",
+			accountID:   acc.Entity.ID,
+			message:     []byte("invalid-message"),
+			expectedErr: errors.NewServerError(),
+		},
+		{
+			name:        "account does not exist",
+			accountID:   types.ID(0),
+			message:     message,
+			expectedErr: errors.NewServerError(),
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			u := setupPasswordUsecase()
+			ke2, changeID, err := u.ChangeInit(ctx, tc.accountID, tc.message)
+
+			if tc.expectedErr != nil {
+				require.EqualError(t, err, tc.expectedErr.Error())
+				require.Nil(t, ke2)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotEmpty(t, ke2)
+
+			change, err := repository.NewPasswordChangeRepository(redisClient).Get(ctx, changeID)
+			require.NoError(t, err)
+			require.Equal(t, tc.accountID, change.AccountID)
+			require.NotEmpty(t, change.AKEState)
+			require.False(t, change.Verified)
+		})
+	}
+}
+
+func TestPasswordUsecase_Change(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	password := []byte("strong-password")
+	newPassword := []byte("stronger-password")
+	acc := createPasswordAccount(t, "change_user", string(password))
+
+	sessionRepo := repository.NewSessionRepository(redisClient)
+	for _, id := range []types.CacheID{"change_current_session", "change_other_session"} {
+		err := sessionRepo.Create(ctx, entity.Session{
+			CacheEntity: base.CacheEntity{ID: id, Duration: time.Minute},
+			AccountID:   acc.Entity.ID,
+		})
+		require.NoError(t, err)
+	}
+
+	u := setupPasswordUsecase()
+
+	// ---------- fresh login with the current password ----------
+	loginClient := newOpaqueClient(t)
+	ke1 := loginClient.LoginInit(password).Serialize()
+
+	ke2Message, changeID, err := u.ChangeInit(ctx, acc.Entity.ID, ke1)
+	require.NoError(t, err)
+
+	ke2, err := loginClient.Deserialize.KE2(ke2Message)
+	require.NoError(t, err)
+
+	ke3, _, err := loginClient.LoginFinish(ke2, bytemareOpaque.ClientLoginFinishOptions{
+		ClientIdentity: []byte(acc.Username),
+		ServerIdentity: []byte(conf.Opaque.ServerID),
+	})
+	require.NoError(t, err)
+
+	// ---------- registration of the new password ----------
+	registrationClient := newOpaqueClient(t)
+	registrationRequest := registrationClient.RegistrationInit(newPassword).Serialize()
+
+	// a change can not be finalized before the current password is proven
+	err = u.ChangeFinalize(ctx, acc.Entity.ID, "change_current_session", changeID, []byte("record"), []byte("key"))
+	require.ErrorIs(t, err, account.PasswordChangeDoesNotExist)
+
+	// a change of another account is not visible
+	_, _, err = u.ChangeVerify(ctx, types.ID(0), changeID, ke3.Serialize(), registrationRequest)
+	require.ErrorIs(t, err, account.PasswordChangeDoesNotExist)
+
+	registrationResponse, encryptedVaultKey, err := u.ChangeVerify(ctx, acc.Entity.ID, changeID, ke3.Serialize(), registrationRequest)
+	require.NoError(t, err)
+	require.NotEmpty(t, registrationResponse)
+	require.Equal(t, acc.EncryptedVaultKey, encryptedVaultKey)
+
+	response, err := registrationClient.Deserialize.RegistrationResponse(registrationResponse)
+	require.NoError(t, err)
+
+	record, _ := registrationClient.RegistrationFinalize(response, bytemareOpaque.ClientRegistrationFinalizeOptions{
+		ClientIdentity: []byte(acc.Username),
+		ServerIdentity: []byte(conf.Opaque.ServerID),
+	})
+
+	// the vault could not be opened with the new password without the wrapped key
+	err = u.ChangeFinalize(ctx, acc.Entity.ID, "change_current_session", changeID, record.Serialize(), nil)
+	require.ErrorIs(t, err, account.PasswordVaultKeyMissing)
+
+	newVaultKey := []byte("vault key wrapped under the new export key")
+	err = u.ChangeFinalize(ctx, acc.Entity.ID, "change_current_session", changeID, record.Serialize(), newVaultKey)
+	require.NoError(t, err)
+
+	// ---------- verify ----------
+	updated, err := repository.NewAccountRepository(pgTestSuite.db).ReadByID(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	require.NotEqual(t, acc.OpaqueRecord, updated.OpaqueRecord)
+	require.Equal(t, acc.OpaqueCredentialID, updated.OpaqueCredentialID)
+	require.Equal(t, newVaultKey, updated.EncryptedVaultKey)
+
+	exist, err := sessionRepo.Exist(ctx, "change_current_session")
+	require.NoError(t, err)
+	require.True(t, exist)
+
+	exist, err = sessionRepo.Exist(ctx, "change_other_session")
+	require.NoError(t, err)
+	require.False(t, exist)
+
+	// the change can not be replayed
+	err = u.ChangeFinalize(ctx, acc.Entity.ID, "change_current_session", changeID, record.Serialize(), newVaultKey)
+	require.ErrorIs(t, err, account.PasswordChangeDoesNotExist)
+}
+
+func TestPasswordUsecase_ChangeVerifyInvalidPassword(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	acc := createPasswordAccount(t, "change_wrong_password_user", "strong-password")
+	u := setupPasswordUsecase()
+
+	client := newOpaqueClient(t)
+	ke1 := client.LoginInit([]byte("wrong-password")).Serialize()
+
+	_, changeID, err := u.ChangeInit(ctx, acc.Entity.ID, ke1)
+	require.NoError(t, err)
+
+	registrationRequest := newOpaqueClient(t).RegistrationInit([]byte("stronger-password")).Serialize()
+
+	// the client can not produce a valid ke3 without the right password
+	_, _, err = u.ChangeVerify(ctx, acc.Entity.ID, changeID, make([]byte, 32), registrationRequest)
+	require.ErrorIs(t, err, account.AuthInvalidPassword)
+
+	exist, err := repository.NewPasswordChangeRepository(redisClient).Exist(ctx, changeID)
+	require.NoError(t, err)
+	require.False(t, exist)
+}
+
+func TestPasswordUsecase_ChangeInitLockout(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	acc := createPasswordAccount(t, "change_lockout_user", "strong-password")
+	u := setupPasswordUsecase()
+
+	// every guess of the current password counts like a login does
+	for range conf.MaxLoginAttempts {
+		_, _, err := u.ChangeInit(ctx, acc.Entity.ID, newOpaqueClient(t).LoginInit([]byte("guess")).Serialize())
+		require.NoError(t, err)
+	}
+
+	_, _, err := u.ChangeInit(ctx, acc.Entity.ID, newOpaqueClient(t).LoginInit([]byte("strong-password")).Serialize())
+	require.ErrorIs(t, err, account.AuthAccountLocked)
+
+	lockedFor, err := repository.NewAttemptRepository(redisClient).LockedFor(ctx, entity.AttemptScopeUsername, acc.Username)
+	require.NoError(t, err)
+	require.Positive(t, lockedFor)
+}
+
+func TestPasswordUsecase_Rekey(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	password := []byte("strong-password")
+	acc := createPasswordAccount(t, "rekey_user", string(password))
+	require.Equal(t, 1, acc.OpaqueKeyVersion)
+
+	sessionRepo := repository.NewSessionRepository(redisClient)
+	err := sessionRepo.Create(ctx, entity.Session{
+		CacheEntity: base.CacheEntity{ID: "rekey_other_session", Duration: time.Minute},
+		AccountID:   acc.Entity.ID,
+	})
+	require.NoError(t, err)
+
+	// the account is on the current keys before the rotation
+	err = rekey(t, setupPasswordUsecase(), acc, password, []byte("vault key"))
+	require.ErrorIs(t, err, account.PasswordRekeyNotNeeded)
+
+	u := setupPasswordUsecaseWithConfig(rotatedConfig(t))
+
+	// the account still logs in with the keys it was registered under
+	newVaultKey := []byte("vault key wrapped under the new export key")
+	err = rekey(t, u, acc, password, newVaultKey)
+	require.NoError(t, err)
+
+	updated, err := repository.NewAccountRepository(pgTestSuite.db).ReadByID(ctx, acc.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, updated.OpaqueKeyVersion)
+	require.NotEqual(t, acc.OpaqueRecord, updated.OpaqueRecord)
+	require.Equal(t, newVaultKey, updated.EncryptedVaultKey)
+
+	// the sessions are kept, the password did not change
+	exist, err := sessionRepo.Exist(ctx, "rekey_other_session")
+	require.NoError(t, err)
+	require.True(t, exist)
+
+	// the new record logs in too, and is not registered again
+	err = rekey(t, u, updated, password, newVaultKey)
+	require.ErrorIs(t, err, account.PasswordRekeyNotNeeded)
+}
+
+// rekey registers the password of the account again the way the client does after a
+// login that asked for it.
+func rekey(t *testing.T, u usecase.PasswordUsecase, acc entity.Account, password, encryptedVaultKey []byte) error {
+	ctx := context.Background()
+
+	loginClient := newOpaqueClient(t)
+	ke2Message, changeID, err := u.ChangeInit(ctx, acc.Entity.ID, loginClient.LoginInit(password).Serialize())
+	require.NoError(t, err)
+
+	ke2, err := loginClient.Deserialize.KE2(ke2Message)
+	require.NoError(t, err)
+
+	ke3, _, err := loginClient.LoginFinish(ke2, bytemareOpaque.ClientLoginFinishOptions{
+		ClientIdentity: []byte(acc.Username),
+		ServerIdentity: []byte(conf.Opaque.ServerID),
+	})
+	require.NoError(t, err)
+
+	registrationClient := newOpaqueClient(t)
+	registrationRequest := registrationClient.RegistrationInit(password).Serialize()
+
+	registrationResponse, _, err := u.ChangeVerify(ctx, acc.Entity.ID, changeID, ke3.Serialize(), registrationRequest)
+	require.NoError(t, err)
+
+	response, err := registrationClient.Deserialize.RegistrationResponse(registrationResponse)
+	require.NoError(t, err)
+
+	record, _ := registrationClient.RegistrationFinalize(response, bytemareOpaque.ClientRegistrationFinalizeOptions{
+		ClientIdentity: []byte(acc.Username),
+		ServerIdentity: []byte(conf.Opaque.ServerID),
+	})
+
+	return u.RekeyFinalize(ctx, acc.Entity.ID, changeID, record.Serialize(), encryptedVaultKey)
+}
+
+// rotatedConfig is the test config after the AKE key pair was rotated to version 2,
+// the keys of version 1 are copied so the rotation stays out of the shared test keys.
+func rotatedConfig(t *testing.T) *config.Config {
+	dir := t.TempDir()
+	rotated := config.GetTestConfig()
+
+	for _, path := range []*string{
+		&rotated.Opaque.OprfKeyPath, &rotated.Opaque.PrivateKeyPath, &rotated.Opaque.PublicKeyPath,
+	} {
+		content, err := os.ReadFile(*path)
+		require.NoError(t, err)
+
+		*path = filepath.Join(dir, filepath.Base(*path))
+		require.NoError(t, os.WriteFile(*path, content, 0600))
+	}
+
+	require.NoError(t, oprfutils.GenerateKeyPair(dir, 2, oprfutils.DefaultSuite()))
+	rotated.Opaque.KeyVersion = 2
+
+	return rotated
+}
+
+func newOpaqueClient(t *testing.T) *bytemareOpaque.Client {
+	client, err := bytemareOpaque.NewClient(&bytemareOpaque.Configuration{
+		OPRF: bytemareOpaque.P256Sha256,
+		AKE:  bytemareOpaque.P256Sha256,
+		Hash: crypto.SHA256,
+		KDF:  crypto.SHA256,
+		MAC:  crypto.SHA256,
+		KSF:  ksf.Argon2id,
+	})
+	require.NoError(t, err)
+
+	return client
+}
+
+// createPasswordAccount registers an account the way the login expects its record,
+// so the fresh login of the password change can succeed.
+func createPasswordAccount(t *testing.T, username, password string) entity.Account {
+	ctx := context.Background()
+
+	server, err := (&bytemareOpaque.Configuration{
+		OPRF: bytemareOpaque.P256Sha256,
+		AKE:  bytemareOpaque.P256Sha256,
+		Hash: crypto.SHA256,
+		KDF:  crypto.SHA256,
+		MAC:  crypto.SHA256,
+		KSF:  ksf.Argon2id,
+	}).Server()
+	require.NoError(t, err)
+
+	publicKey, err := os.ReadFile(conf.Opaque.PublicKeyPath)
+	require.NoError(t, err)
+	oprfSeed, err := os.ReadFile(conf.Opaque.OprfKeyPath)
+	require.NoError(t, err)
+
+	pks, err := server.Deserialize.DecodeAkePublicKey(publicKey)
+	require.NoError(t, err)
+
+	client := newOpaqueClient(t)
+	credentialID := opaque.NewCredentialID()
+	request := client.RegistrationInit([]byte(password))
+	response := server.RegistrationResponse(request, pks, credentialID, oprfSeed)
+	record, _ := client.RegistrationFinalize(response, bytemareOpaque.ClientRegistrationFinalizeOptions{
+		ClientIdentity: []byte(username),
+		ServerIdentity: []byte(conf.Opaque.ServerID),
+	})
+
+	accountRepo := repository.NewAccountRepository(pgTestSuite.db)
+	err = accountRepo.Create(ctx, entity.Account{
+		Username:           username,
+		Email:              username + "@example.com",
+		FirstName:          "Change",
+		LastName:           "Password",
+		OpaqueRecord:       record.Serialize(),
+		OpaqueCredentialID: credentialID,
+		TOTPSecret:         []byte("secret"),
+	})
+	require.NoError(t, err)
+
+	acc, err := accountRepo.ReadByUsername(ctx, username)
+	require.NoError(t, err)
+
+	return acc
+}
+
+func setupPasswordUsecase() usecase.PasswordUsecase {
+	return setupPasswordUsecaseWithConfig(conf)
+}
+
+func setupPasswordUsecaseWithConfig(conf *config.Config) usecase.PasswordUsecase {
+	aRepo := repository.NewAccountRepository(pgTestSuite.db)
+	pcRepo := repository.NewPasswordChangeRepository(redisClient)
+	sRepo := repository.NewSessionRepository(redisClient)
+	atRepo := repository.NewAttemptRepository(redisClient)
+	opqaue, err := opaque.New(conf)
+	if err != nil {
+		panic(err)
+	}
+
+	return usecase.NewPasswordUsecase(aRepo, pcRepo, sRepo, atRepo, opqaue, mailer, conf)
+}
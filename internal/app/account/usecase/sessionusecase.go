@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+)
+
+// lastSeenInterval keeps the session info from being rewritten on every request.
+const lastSeenInterval = time.Minute
+
+type SessionUsecase struct {
+	sessionRepo repository.SessionRepository
+	config      *config.Config
+}
+
+func NewSessionUsecase(sessionRepo repository.SessionRepository, config *config.Config) SessionUsecase {
+	return SessionUsecase{sessionRepo: sessionRepo, config: config}
+}
+
+func (u *SessionUsecase) Create(ctx context.Context, accountID types.ID, sessionID types.CacheID, ip, userAgent string) error {
+	now := time.Now()
+	session := entity.Session{
+		CacheEntity: base.CacheEntity{ID: sessionID, Duration: u.SessionDuration()},
+		AccountID:   accountID,
+		IP:          ip,
+		UserAgent:   userAgent,
+		Device:      deviceFromUserAgent(userAgent),
+		CreatedAt:   now,
+		LastSeen:    now,
+	}
+
+	err := u.sessionRepo.Create(ctx, session)
+	if err != nil {
+		log.ErrorLogger.Error("error at creating session", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	log.InfoLogger.Info("session created for account", "account_id", accountID, "ip", ip, "device", session.Device)
+	return nil
+}
+
+func (u *SessionUsecase) Touch(ctx context.Context, sessionID types.CacheID, ip string) error {
+	exist, err := u.sessionRepo.Exist(ctx, sessionID)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking session existence", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	if !exist {
+		return nil
+	}
+
+	session, err := u.sessionRepo.Get(ctx, sessionID)
+	if err != nil {
+		log.ErrorLogger.Error("error at getting session", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	if time.Since(session.LastSeen) < lastSeenInterval && session.IP == ip {
+		return nil
+	}
+
+	session.LastSeen = time.Now()
+	session.IP = ip
+
+	err = u.sessionRepo.Update(ctx, session)
+	if err != nil {
+		log.ErrorLogger.Error("error at updating session", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+func (u *SessionUsecase) Read(ctx context.Context, accountID types.ID) ([]entity.Session, error) {
+	sessions, err := u.sessionRepo.ReadByAccount(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading sessions", "error", err.Error(), "account_id", accountID)
+		return nil, errors.NewServerError()
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeen.After(sessions[j].LastSeen)
+	})
+
+	return sessions, nil
+}
+
+func (u *SessionUsecase) Revoke(ctx context.Context, accountID types.ID, sessionID types.CacheID) error {
+	exist, err := u.sessionRepo.Exist(ctx, sessionID)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking session existence", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	if !exist {
+		return account.SessionDoesNotExist
+	}
+
+	session, err := u.sessionRepo.Get(ctx, sessionID)
+	if err != nil {
+		log.ErrorLogger.Error("error at getting session", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	if session.AccountID != accountID {
+		return account.SessionDoesNotExist
+	}
+
+	err = u.sessionRepo.Delete(ctx, accountID, sessionID)
+	if err != nil {
+		log.ErrorLogger.Error("error at revoking session", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	log.InfoLogger.Info("session revoked", "account_id", accountID)
+	return nil
+}
+
+func (u *SessionUsecase) RevokeOthers(ctx context.Context, accountID types.ID, currentID types.CacheID) error {
+	sessions, err := u.sessionRepo.ReadByAccount(ctx, accountID)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading sessions", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	for _, session := range sessions {
+		if session.ID == currentID {
+			continue
+		}
+
+		err := u.sessionRepo.Delete(ctx, accountID, session.ID)
+		if err != nil {
+			log.ErrorLogger.Error("error at revoking session", "error", err.Error(), "account_id", accountID)
+			return errors.NewServerError()
+		}
+	}
+
+	log.InfoLogger.Info("other sessions revoked", "account_id", accountID, "count", len(sessions))
+	return nil
+}
+
+func (u *SessionUsecase) Logout(ctx context.Context, accountID types.ID, sessionID types.CacheID) error {
+	err := u.sessionRepo.Delete(ctx, accountID, sessionID)
+	if err != nil {
+		log.ErrorLogger.Error("error at deleting session", "error", err.Error(), "account_id", accountID)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+func (u *SessionUsecase) SessionDuration() time.Duration {
+	return time.Minute * time.Duration(u.config.SessionDuration)
+}
+
+func deviceFromUserAgent(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/"):
+		browser = "curl"
+	}
+
+	system := "Unknown OS"
+	switch {
+	case strings.Contains(ua, "android"):
+		system = "Android"
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		system = "iOS"
+	case strings.Contains(ua, "windows"):
+		system = "Windows"
+	case strings.Contains(ua, "mac os"):
+		system = "macOS"
+	case strings.Contains(ua, "linux"):
+		system = "Linux"
+	}
+
+	return browser + " on " + system
+}
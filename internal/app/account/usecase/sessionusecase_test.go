@@ -0,0 +1,122 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionUsecase_Create(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	u := setupSessionUsecase()
+
+	accountID := types.ID(200)
+	userAgent := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/126.0 Safari/537.36"
+
+	err := u.Create(ctx, accountID, "usecase_create_session", "203.0.113.1", userAgent)
+	require.NoError(t, err)
+
+	sessions, err := u.Read(ctx, accountID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, "Chrome on Windows", sessions[0].Device)
+	require.Equal(t, "203.0.113.1", sessions[0].IP)
+}
+
+func TestSessionUsecase_Touch(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	u := setupSessionUsecase()
+
+	accountID := types.ID(201)
+	require.NoError(t, u.Create(ctx, accountID, "usecase_touch_session", "203.0.113.2", ""))
+
+	err := u.Touch(ctx, "usecase_touch_session", "203.0.113.3")
+	require.NoError(t, err)
+
+	sessions, err := u.Read(ctx, accountID)
+	require.NoError(t, err)
+	require.Equal(t, "203.0.113.3", sessions[0].IP)
+
+	// unknown sessions are ignored
+	err = u.Touch(ctx, "usecase_missing_session", "203.0.113.3")
+	require.NoError(t, err)
+}
+
+func TestSessionUsecase_Revoke(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	u := setupSessionUsecase()
+
+	ownerID := types.ID(202)
+	require.NoError(t, u.Create(ctx, ownerID, "usecase_revoke_session", "203.0.113.4", ""))
+
+	testcases := []struct {
+		name      string
+		accountID types.ID
+		sessionID types.CacheID
+		err       error
+	}{
+		{
+			name:      "session of another account",
+			accountID: types.ID(203),
+			sessionID: "usecase_revoke_session",
+			err:       account.SessionDoesNotExist,
+		},
+		{
+			name:      "session does not exist",
+			accountID: ownerID,
+			sessionID: "usecase_unknown_session",
+			err:       account.SessionDoesNotExist,
+		},
+		{
+			name:      "successful",
+			accountID: ownerID,
+			sessionID: "usecase_revoke_session",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := u.Revoke(ctx, tc.accountID, tc.sessionID)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+				sessions, err := u.Read(ctx, tc.accountID)
+				require.NoError(t, err)
+				require.Empty(t, sessions)
+			}
+		})
+	}
+}
+
+func TestSessionUsecase_RevokeOthers(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	u := setupSessionUsecase()
+
+	accountID := types.ID(204)
+	require.NoError(t, u.Create(ctx, accountID, "usecase_current_session", "203.0.113.5", ""))
+	require.NoError(t, u.Create(ctx, accountID, "usecase_other_session_1", "203.0.113.6", ""))
+	require.NoError(t, u.Create(ctx, accountID, "usecase_other_session_2", "203.0.113.7", ""))
+
+	err := u.RevokeOthers(ctx, accountID, "usecase_current_session")
+	require.NoError(t, err)
+
+	sessions, err := u.Read(ctx, accountID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, types.CacheID("usecase_current_session"), sessions[0].ID)
+}
+
+func setupSessionUsecase() usecase.SessionUsecase {
+	sessionRepo := repository.NewSessionRepository(redisClient)
+	return usecase.NewSessionUsecase(sessionRepo, conf)
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// API is the group the JSON API is served under along with the document of its routes.
+// Public serves the routes that are called before there is a token, like the login.
+type API struct {
+	Group  *gin.RouterGroup
+	Public *gin.RouterGroup
+	Doc    *openapi.Document
+}
+
+// NewAPIDocument returns the document the routes of the API are registered into.
+func NewAPIDocument() *openapi.Document {
+	return openapi.New("Cool Password Manager API", "1.0.0", PathAPI, SessionName)
+}
+
+// Register adds the routes to the group and the document. Every route can also answer
+// with the errors of the authentication and the server. An API without a group only
+// documents the routes.
+func (api API) Register(routes ...openapi.Route) {
+	for _, route := range routes {
+		responses := map[int]any{
+			http.StatusUnauthorized:        APIErrorResponse{},
+			http.StatusInternalServerError: APIErrorResponse{},
+		}
+		for status, body := range route.Responses {
+			responses[status] = body
+		}
+		route.Responses = responses
+
+		switch {
+		case api.Group == nil:
+			api.Doc.Add(route)
+		case route.Public:
+			api.Doc.Register(api.Public, route)
+		default:
+			api.Doc.Register(api.Group, route)
+		}
+	}
+}
+
+// APIErrorResponse is the body of every error of the JSON API, the code is the one of
+// the errors.CustomError behind it.
+type APIErrorResponse struct {
+	Error APIError `json:"error"`
+}
+
+type APIError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type APIPagination struct {
+	Page     int `json:"page"`
+	PageSize int `json:"pageSize"`
+	Total    int `json:"total"`
+}
+
+func HandleAPIError(ctx *gin.Context, customError errors.CustomError) {
+	ctx.JSON(errors.HttpCode(customError.Code), APIErrorResponse{
+		Error: APIError{Code: customError.Code, Message: customError.Message},
+	})
+	ctx.Abort()
+}
+
+// HandleAPIBindError answers a body that could not be bound to the request model.
+func HandleAPIBindError(ctx *gin.Context, bindError error) {
+	HandleAPIError(ctx, errors.CustomError{Code: http.StatusBadRequest, Message: bindError.Error()})
+}
@@ -0,0 +1,636 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/usecase"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	httpServer "github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/server"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/seed"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/encrypt"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/testdocker"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bytemare/ksf"
+	bytemareOpaque "github.com/bytemare/opaque"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	googleTotp "github.com/pquerna/otp/totp"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+type postgresTest struct {
+	db   *pgxpool.Pool
+	name string
+}
+
+var pgTestSuite postgresTest
+var server *gin.Engine
+var api localHttp.API
+var conf *config.Config
+
+// TestMain serves the API the way the app does, every response of the tests is
+// checked against the document the API serves.
+func TestMain(m *testing.M) {
+	ctx, cancel := context.WithCancel(context.Background())
+	gin.SetMode(gin.TestMode)
+
+	pgName, pgTest := database.SetupTestDB(ctx)
+	pgTestSuite = postgresTest{name: pgName, db: pgTest}
+
+	seed.CreateSeed(ctx, pgTestSuite.db)
+
+	mr, err := miniredis.Run()
+	if err != nil {
+		log.Fatalf("An error occurred while starting miniredis: %v", err)
+	}
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+	})
+
+	conf = config.GetTestConfig()
+	conf.SecretKey = "api-test-secret-key"
+	conf.SessionDuration = 10
+	conf.TwoFactorDuration = 5
+	conf.DefaultPage = 1
+	conf.DefaultPageSize = 10
+
+	opaqueAdaptor, err := opaque.New(conf)
+	if err != nil {
+		log.Fatalf("An error occurred while loading the opaque keys: %v", err)
+	}
+
+	server = gin.New()
+	api, err = httpServer.Register(ctx, server, conf, pgTestSuite.db, redisClient, opaqueAdaptor)
+	if err != nil {
+		log.Fatalf("An error occurred while registering the routers: %v", err)
+	}
+
+	exitCode := m.Run()
+	cancel()
+	testdocker.StopAndRemoveContainer(ctx, pgTestSuite.name, pgTestSuite.name)
+
+	os.Exit(exitCode)
+}
+
+func TestAPI_Account(t *testing.T) {
+	t.Parallel()
+	acc, token := createAccountWithToken(t, "api_account_user", entity.AccessTokenReadWrite, nil)
+
+	testcases := []struct {
+		name   string
+		method string
+		path   string
+		route  string
+		token  string
+		body   any
+		status int
+	}{
+		{
+			name:   "token without authentication",
+			method: http.MethodGet,
+			path:   "token/",
+			route:  "token/",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "invalid token",
+			method: http.MethodGet,
+			path:   "token/",
+			route:  "token/",
+			token:  "cpm_invalid",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "token",
+			method: http.MethodGet,
+			path:   "token/",
+			route:  "token/",
+			token:  token,
+			status: http.StatusOK,
+		},
+		{
+			name:   "read the account",
+			method: http.MethodGet,
+			path:   "account/",
+			route:  "account/",
+			token:  token,
+			status: http.StatusOK,
+		},
+		{
+			name:   "update the account without a name",
+			method: http.MethodPut,
+			path:   "account/",
+			route:  "account/",
+			token:  token,
+			body:   map[string]string{"firstName": "Api"},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "update the account",
+			method: http.MethodPut,
+			path:   "account/",
+			route:  "account/",
+			token:  token,
+			body:   map[string]string{"firstName": "Api", "lastName": "User"},
+			status: http.StatusOK,
+		},
+		{
+			name:   "search an account",
+			method: http.MethodGet,
+			path:   "accounts/search/?username=" + seed.AccountJohnDoe.Username,
+			route:  "accounts/search/",
+			token:  token,
+			status: http.StatusOK,
+		},
+		{
+			name:   "search an account that does not exist",
+			method: http.MethodGet,
+			path:   "accounts/search/?username=nobody",
+			route:  "accounts/search/",
+			token:  token,
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			call(t, tc.token, tc.method, tc.path, tc.route, tc.body, tc.status)
+		})
+	}
+
+	body := call(t, token, http.MethodGet, "account/", "account/", nil, http.StatusOK)
+	require.Equal(t, acc.Username, body["username"])
+	require.Equal(t, "User", body["lastName"])
+}
+
+func TestAPI_Groups(t *testing.T) {
+	t.Parallel()
+	acc, token := createAccountWithToken(t, "api_groups_user", entity.AccessTokenReadWrite, nil)
+
+	group := map[string]any{"name": "api group", "memberIDs": []types.ID{seed.AccountJohnDoe.Entity.ID}}
+	created := call(t, token, http.MethodPost, "groups/", "groups/", group, http.StatusCreated)
+	require.Len(t, created["members"], 1)
+	groupPath := fmt.Sprintf("groups/%v/", created["id"])
+	invitationsPath := groupPath + "invitations/"
+
+	list := call(t, token, http.MethodGet, "groups/", "groups/", nil, http.StatusOK)
+	require.Len(t, list["groups"], 1)
+
+	call(t, token, http.MethodGet, groupPath, "groups/:id/", nil, http.StatusOK)
+
+	group["description"] = "updated"
+	group["roles"] = map[types.ID]string{seed.AccountJohnDoe.Entity.ID: "editor"}
+	updated := call(t, token, http.MethodPut, groupPath, "groups/:id/", group, http.StatusOK)
+	require.Equal(t, "updated", updated["description"])
+	require.Equal(t, "owner", updated["owner"].(map[string]any)["role"])
+	require.Len(t, updated["members"], 1)
+
+	// the members are invited instead of added
+	pending := call(t, token, http.MethodGet, invitationsPath, "groups/:id/invitations/", nil, http.StatusOK)
+	require.Len(t, pending["invitations"], 1)
+	johnDoe := pending["invitations"].([]any)[0].(map[string]any)
+	require.Equal(t, seed.AccountJohnDoe.Username, johnDoe["invitee"])
+	require.Equal(t, "editor", johnDoe["role"])
+
+	invitee, inviteeToken := createAccountWithToken(t, "api_groups_invitee", entity.AccessTokenReadWrite, nil)
+	invite := map[string]any{"email": invitee.Email, "role": "viewer"}
+	invitation := call(t, token, http.MethodPost, invitationsPath, "groups/:id/invitations/", invite, http.StatusCreated)
+	call(t, token, http.MethodPost, invitationsPath, "groups/:id/invitations/", map[string]any{"email": "not an email"},
+		http.StatusBadRequest)
+	call(t, token, http.MethodPost, invitationsPath, "groups/:id/invitations/",
+		map[string]any{"email": "api_groups_stranger@example.com", "role": "editor"}, http.StatusCreated)
+
+	received := call(t, inviteeToken, http.MethodGet, "invitations/", "invitations/", nil, http.StatusOK)
+	require.Len(t, received["invitations"], 1)
+	call(t, inviteeToken, http.MethodGet, groupPath, "groups/:id/", nil, http.StatusNotFound)
+
+	acceptPath := fmt.Sprintf("invitations/%v/accept/", invitation["id"])
+	call(t, inviteeToken, http.MethodPost, acceptPath, "invitations/:id/accept/", nil, http.StatusNoContent)
+	call(t, inviteeToken, http.MethodPost, acceptPath, "invitations/:id/accept/", nil, http.StatusNotFound)
+	call(t, inviteeToken, http.MethodGet, groupPath, "groups/:id/", nil, http.StatusOK)
+
+	johnDoePath := fmt.Sprintf("invitations/%v/", johnDoe["id"])
+	call(t, inviteeToken, http.MethodDelete, johnDoePath, "invitations/:id/", nil, http.StatusForbidden)
+	call(t, token, http.MethodDelete, johnDoePath, "invitations/:id/", nil, http.StatusNoContent)
+	call(t, token, http.MethodPost, fmt.Sprintf("invitations/%v/decline/", johnDoe["id"]), "invitations/:id/decline/", nil,
+		http.StatusNotFound)
+
+	pending = call(t, token, http.MethodGet, invitationsPath, "groups/:id/invitations/", nil, http.StatusOK)
+	require.Len(t, pending["invitations"], 1)
+
+	// a group the account is not a member of
+	foreign := fmt.Sprintf("groups/%d/", seed.GroupBlackHippy.ID)
+	call(t, token, http.MethodGet, foreign, "groups/:id/", nil, http.StatusNotFound)
+
+	// members are edited one at a time and every change is recorded
+	inviteePath := fmt.Sprintf("%vmembers/%d/", groupPath, invitee.Entity.ID)
+	promoted := call(t, token, http.MethodPut, inviteePath, "groups/:id/members/:memberID/",
+		map[string]any{"role": "editor"}, http.StatusOK)
+	require.Equal(t, "editor", promoted["members"].([]any)[1].(map[string]any)["role"])
+	call(t, token, http.MethodPut, inviteePath, "groups/:id/members/:memberID/", map[string]any{}, http.StatusBadRequest)
+	call(t, token, http.MethodDelete, fmt.Sprintf("%vmembers/%d/", groupPath, acc.Entity.ID), "groups/:id/members/:memberID/",
+		nil, http.StatusForbidden)
+	call(t, token, http.MethodDelete, fmt.Sprintf("%vmembers/%d/", groupPath, seed.AccountJohnDoe.Entity.ID),
+		"groups/:id/members/:memberID/", nil, http.StatusNotFound)
+
+	events := call(t, inviteeToken, http.MethodGet, groupPath+"events/", "groups/:id/events/", nil, http.StatusOK)
+	require.Len(t, events["events"], 3)
+	latest := events["events"].([]any)[0].(map[string]any)
+	require.Equal(t, "role changed", latest["action"])
+	require.Equal(t, acc.Username, latest["actor"])
+	require.Equal(t, invitee.Username, latest["member"])
+
+	// the owner hands the group over to the invitee, who has to accept it
+	transferPath := groupPath + "transfer/"
+	offer := map[string]any{"newOwnerID": invitee.Entity.ID}
+	call(t, token, http.MethodPost, transferPath, "groups/:id/transfer/",
+		map[string]any{"newOwnerID": seed.AccountJohnDoe.Entity.ID}, http.StatusBadRequest)
+	offered := call(t, token, http.MethodPost, transferPath, "groups/:id/transfer/", offer, http.StatusOK)
+	require.Equal(t, float64(invitee.Entity.ID), offered["pendingOwnerID"])
+
+	offers := call(t, inviteeToken, http.MethodGet, "ownership-offers/", "ownership-offers/", nil, http.StatusOK)
+	require.Len(t, offers["offers"], 1)
+
+	call(t, inviteeToken, http.MethodDelete, transferPath, "groups/:id/transfer/", nil, http.StatusNoContent)
+	call(t, inviteeToken, http.MethodPost, transferPath+"accept/", "groups/:id/transfer/accept/", nil, http.StatusNotFound)
+
+	call(t, token, http.MethodPost, transferPath, "groups/:id/transfer/", offer, http.StatusOK)
+	call(t, token, http.MethodPost, groupPath+"leave/", "groups/:id/leave/", nil, http.StatusForbidden)
+	call(t, inviteeToken, http.MethodPost, transferPath+"accept/", "groups/:id/transfer/accept/", nil, http.StatusNoContent)
+
+	// the old owner stays on as an admin until it leaves
+	call(t, token, http.MethodDelete, groupPath, "groups/:id/", nil, http.StatusForbidden)
+	call(t, token, http.MethodPost, groupPath+"leave/", "groups/:id/leave/", nil, http.StatusNoContent)
+	call(t, token, http.MethodGet, groupPath, "groups/:id/", nil, http.StatusNotFound)
+
+	call(t, inviteeToken, http.MethodDelete, groupPath, "groups/:id/", nil, http.StatusNoContent)
+	call(t, inviteeToken, http.MethodGet, groupPath, "groups/:id/", nil, http.StatusNotFound)
+}
+
+func TestAPI_Items(t *testing.T) {
+	t.Parallel()
+	acc, token := createAccountWithToken(t, "api_items_user", entity.AccessTokenReadWrite, nil)
+
+	group := call(t, token, http.MethodPost, "groups/", "groups/", map[string]any{"name": "api items"}, http.StatusCreated)
+	groupID := types.ID(group["id"].(float64))
+
+	item := map[string]any{
+		"name":              "mail",
+		"encryptedUsername": []byte("username"),
+		"encryptedPassword": []byte("password"),
+		"nonce":             []byte("nonce"),
+		"groupIDs":          []types.ID{groupID},
+	}
+	created := call(t, token, http.MethodPost, "items/", "items/", item, http.StatusCreated)
+	itemPath := fmt.Sprintf("items/%v/", created["id"])
+
+	call(t, token, http.MethodPost, "items/", "items/", item, http.StatusConflict)
+	call(t, token, http.MethodPost, "items/", "items/", map[string]any{"name": "empty"}, http.StatusBadRequest)
+
+	private := map[string]any{
+		"name":              "private",
+		"encryptedUsername": []byte("username"),
+		"encryptedPassword": []byte("password"),
+		"encryptedNote":     []byte("note"),
+		"nonce":             []byte("nonce"),
+	}
+	privateItem := call(t, token, http.MethodPost, "items/", "items/", private, http.StatusCreated)
+	privatePath := fmt.Sprintf("items/%v/", privateItem["id"])
+
+	list := call(t, token, http.MethodGet, "items/", "items/", nil, http.StatusOK)
+	require.Len(t, list["items"], 2)
+
+	item["description"] = "work mail"
+	updated := call(t, token, http.MethodPut, itemPath, "items/:id/", item, http.StatusOK)
+	require.Equal(t, "work mail", updated["description"])
+
+	// a read-only token can not write
+	_, readOnly := createToken(t, acc, "read only", entity.AccessTokenRead, nil, nil)
+	call(t, readOnly, http.MethodGet, itemPath, "items/:id/", nil, http.StatusOK)
+	call(t, readOnly, http.MethodDelete, itemPath, "items/:id/", nil, http.StatusForbidden)
+
+	// a token restricted to the group only sees the items shared with it
+	_, restricted := createToken(t, acc, "restricted", entity.AccessTokenReadWrite, nil, []types.ID{groupID})
+	list = call(t, restricted, http.MethodGet, "items/", "items/", nil, http.StatusOK)
+	require.Len(t, list["items"], 1)
+	call(t, restricted, http.MethodGet, privatePath, "items/:id/", nil, http.StatusForbidden)
+	call(t, restricted, http.MethodPost, "items/", "items/", private, http.StatusForbidden)
+	call(t, restricted, http.MethodGet, "groups/", "groups/", nil, http.StatusForbidden)
+
+	// and can not add or drop the groups out of its scope
+	other := call(t, token, http.MethodPost, "groups/", "groups/", map[string]any{"name": "api items other"}, http.StatusCreated)
+	otherID := types.ID(other["id"].(float64))
+	item["groupIDs"] = []types.ID{groupID, otherID}
+	call(t, restricted, http.MethodPut, itemPath, "items/:id/", item, http.StatusForbidden)
+	call(t, token, http.MethodPut, itemPath, "items/:id/", item, http.StatusOK)
+	item["groupIDs"] = []types.ID{groupID}
+	call(t, restricted, http.MethodPut, itemPath, "items/:id/", item, http.StatusForbidden)
+	item["groupIDs"] = []types.ID{groupID, otherID}
+	item["description"] = "restricted"
+	updated = call(t, restricted, http.MethodPut, itemPath, "items/:id/", item, http.StatusOK)
+	require.Equal(t, "restricted", updated["description"])
+
+	// a token restricted to the item can update it as long as the groups stay
+	_, onlyItem := createToken(t, acc, "only item", entity.AccessTokenReadWrite, []types.ID{types.ID(created["id"].(float64))}, nil)
+	item["description"] = "only item"
+	call(t, onlyItem, http.MethodPut, itemPath, "items/:id/", item, http.StatusOK)
+	item["groupIDs"] = []types.ID{groupID}
+	call(t, onlyItem, http.MethodPut, itemPath, "items/:id/", item, http.StatusForbidden)
+	call(t, onlyItem, http.MethodPut, privatePath, "items/:id/", private, http.StatusForbidden)
+
+	// items of other accounts do not exist for the account
+	_, stranger := createAccountWithToken(t, "api_items_stranger", entity.AccessTokenReadWrite, nil)
+	call(t, stranger, http.MethodGet, itemPath, "items/:id/", nil, http.StatusNotFound)
+
+	call(t, token, http.MethodDelete, itemPath, "items/:id/", nil, http.StatusNoContent)
+	call(t, token, http.MethodGet, itemPath, "items/:id/", nil, http.StatusNotFound)
+}
+
+func TestAPI_Login(t *testing.T) {
+	t.Parallel()
+	password := []byte("api-login-password")
+	acc, secret := createLoginAccount(t, "api_login_user", password)
+
+	call(t, "", http.MethodPost, "auth/login/init/", "auth/login/init/", map[string]any{"ke1": []byte("ke1")}, http.StatusBadRequest)
+	call(t, "", http.MethodPost, "auth/login/init/", "auth/login/init/",
+		map[string]any{"username": "nobody", "ke1": newOpaqueClient(t).LoginInit(password).Serialize()}, http.StatusUnauthorized)
+
+	// a wrong password can not produce a valid KE3 message
+	client := newOpaqueClient(t)
+	challenge := call(t, "", http.MethodPost, "auth/login/init/", "auth/login/init/",
+		map[string]any{"username": acc.Username, "ke1": client.LoginInit([]byte("wrong-password")).Serialize()}, http.StatusOK)
+	call(t, "", http.MethodPost, "auth/login/", "auth/login/",
+		map[string]any{"loginID": challenge["loginID"], "ke3": make([]byte, 32)}, http.StatusUnprocessableEntity)
+
+	client = newOpaqueClient(t)
+	challenge = call(t, "", http.MethodPost, "auth/login/init/", "auth/login/init/",
+		map[string]any{"username": acc.Username, "ke1": client.LoginInit(password).Serialize()}, http.StatusOK)
+
+	ke2Message, err := base64.StdEncoding.DecodeString(challenge["ke2"].(string))
+	require.NoError(t, err)
+	ke2, err := client.Deserialize.KE2(ke2Message)
+	require.NoError(t, err)
+	ke3, _, err := client.LoginFinish(ke2, bytemareOpaque.ClientLoginFinishOptions{
+		ClientIdentity: []byte(acc.Username),
+		ServerIdentity: []byte(conf.Opaque.ServerID),
+	})
+	require.NoError(t, err)
+
+	finalize := map[string]any{"loginID": challenge["loginID"], "ke3": ke3.Serialize()}
+	twoFactor := call(t, "", http.MethodPost, "auth/login/", "auth/login/", finalize, http.StatusOK)
+
+	// the login can not be replayed
+	call(t, "", http.MethodPost, "auth/login/", "auth/login/", finalize, http.StatusNotFound)
+
+	code, err := googleTotp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+
+	verify := map[string]any{
+		"twoFactorID": twoFactor["twoFactorID"], "verificationCode": "000000", "tokenName": "cpm", "expiryDays": 7,
+	}
+	call(t, "", http.MethodPost, "auth/two-factor/", "auth/two-factor/", verify, http.StatusUnprocessableEntity)
+
+	verify["verificationCode"] = code
+	for _, days := range []int{0, 366} {
+		verify["expiryDays"] = days
+		call(t, "", http.MethodPost, "auth/two-factor/", "auth/two-factor/", verify, http.StatusBadRequest)
+	}
+
+	verify["expiryDays"] = 7
+	login := call(t, "", http.MethodPost, "auth/two-factor/", "auth/two-factor/", verify, http.StatusOK)
+	require.Equal(t, base64.StdEncoding.EncodeToString(acc.EncryptedVaultKey), login["encryptedVaultKey"])
+
+	// the code can not mint a second token
+	call(t, "", http.MethodPost, "auth/two-factor/", "auth/two-factor/", verify, http.StatusNotFound)
+
+	token := login["token"].(string)
+	body := call(t, token, http.MethodGet, "token/", "token/", nil, http.StatusOK)
+	require.Equal(t, acc.Username, body["username"])
+
+	// logging out revokes the token
+	call(t, token, http.MethodDelete, "token/", "token/", nil, http.StatusNoContent)
+	call(t, token, http.MethodGet, "token/", "token/", nil, http.StatusUnauthorized)
+}
+
+func TestAPI_LoginSpoofedIP(t *testing.T) {
+	t.Parallel()
+	client := newOpaqueClient(t)
+
+	// every attempt claims another address, but without a trusted proxy they all count
+	// against the remote address
+	status := func(attempt int) int {
+		var body bytes.Buffer
+		require.NoError(t, json.NewEncoder(&body).Encode(map[string]any{
+			"username": "nobody", "ke1": client.LoginInit([]byte("password")).Serialize(),
+		}))
+
+		request := httptest.NewRequest(http.MethodPost, localHttp.PathAPI+"auth/login/init/", &body)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("X-Forwarded-For", fmt.Sprintf("10.0.0.%d", attempt))
+		request.RemoteAddr = "198.51.100.7:4321"
+
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	for attempt := range conf.MaxIPAttempts {
+		require.Equal(t, http.StatusUnauthorized, status(attempt))
+	}
+	require.Equal(t, http.StatusTooManyRequests, status(conf.MaxIPAttempts))
+}
+
+func TestAPI_Document(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, localHttp.PathAPIDoc, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+	require.Equal(t, "3.0.3", document["openapi"])
+	require.Contains(t, document["paths"], "/items/{id}/")
+
+	login := document["paths"].(map[string]any)["/auth/login/"].(map[string]any)["post"].(map[string]any)
+	require.Empty(t, login["security"])
+}
+
+func TestAPI_Health(t *testing.T) {
+	t.Parallel()
+
+	// the probes call it without a session or a token
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, localHttp.PathHealth, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, recorder.Header().Get("Location"))
+	require.Empty(t, recorder.Header().Get("Set-Cookie"))
+
+	var health struct {
+		Status string           `json:"status"`
+		Opaque opaque.KeyStatus `json:"opaque"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &health))
+	require.Equal(t, "ok", health.Status)
+	require.Equal(t, 1, health.Opaque.KeyVersion)
+	require.Len(t, health.Opaque.Fingerprints, 1)
+}
+
+func TestAPI_OpaqueConfig(t *testing.T) {
+	t.Parallel()
+
+	body := call(t, "", http.MethodGet, "auth/opaque/", "auth/opaque/", nil, http.StatusOK)
+	require.Equal(t, conf.Opaque.ServerID, body["serverID"])
+	require.Equal(t, conf.GetOpaqueSuite().Name, body["suite"])
+
+	ksf := body["ksf"].(map[string]any)
+	require.Equal(t, conf.GetOpaqueSuite().KSF.Name, ksf["name"])
+	require.EqualValues(t, conf.GetOpaqueSuite().KSF.Argon2Memory, ksf["argon2Memory"])
+}
+
+// call makes the request with the token and checks the status and the body against
+// the document of the route.
+func call(t *testing.T, token, method, path, route string, body any, status int) map[string]any {
+	var reader bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reader).Encode(body))
+	}
+
+	request := httptest.NewRequest(method, localHttp.PathAPI+path, &reader)
+	request.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+
+	require.Equal(t, status, recorder.Code, recorder.Body.String())
+	require.NoError(t, api.Doc.ValidateResponse(method, route, recorder.Code, recorder.Body.Bytes()))
+
+	var decoded map[string]any
+	if recorder.Body.Len() > 0 {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &decoded))
+	}
+
+	return decoded
+}
+
+func createAccountWithToken(
+	t *testing.T, username string, permission entity.AccessTokenPermission, groupIDs []types.ID,
+) (entity.Account, string) {
+	ctx := context.Background()
+	repo := repository.NewAccountRepository(pgTestSuite.db)
+
+	err := repo.Create(ctx, entity.Account{
+		Username:     username,
+		Email:        username + "@example.com",
+		FirstName:    "First",
+		LastName:     "Last",
+		OpaqueRecord: []byte("record"),
+		TOTPSecret:   []byte("secret"),
+	})
+	require.NoError(t, err)
+
+	acc, err := repo.ReadByUsername(ctx, username)
+	require.NoError(t, err)
+
+	return createToken(t, acc, "api test", permission, nil, groupIDs)
+}
+
+func createToken(
+	t *testing.T, acc entity.Account, name string, permission entity.AccessTokenPermission, itemIDs, groupIDs []types.ID,
+) (entity.Account, string) {
+	u := usecase.NewAccessTokenUsecase(repository.NewAccessTokenRepository(pgTestSuite.db))
+
+	raw, _, err := u.Create(context.Background(), acc.Entity.ID, name, permission, 0, itemIDs, groupIDs)
+	require.NoError(t, err)
+
+	return acc, raw
+}
+
+// createLoginAccount registers an account the way the login expects its record and
+// returns the secret of its authenticator.
+func createLoginAccount(t *testing.T, username string, password []byte) (entity.Account, string) {
+	ctx := context.Background()
+
+	opaqueServer, err := opaqueConfiguration().Server()
+	require.NoError(t, err)
+
+	publicKey, err := os.ReadFile(conf.Opaque.PublicKeyPath)
+	require.NoError(t, err)
+	oprfSeed, err := os.ReadFile(conf.Opaque.OprfKeyPath)
+	require.NoError(t, err)
+
+	pks, err := opaqueServer.Deserialize.DecodeAkePublicKey(publicKey)
+	require.NoError(t, err)
+
+	client := newOpaqueClient(t)
+	credentialID := opaque.NewCredentialID()
+	response := opaqueServer.RegistrationResponse(client.RegistrationInit(password), pks, credentialID, oprfSeed)
+	record, _ := client.RegistrationFinalize(response, bytemareOpaque.ClientRegistrationFinalizeOptions{
+		ClientIdentity: []byte(username),
+		ServerIdentity: []byte(conf.Opaque.ServerID),
+	})
+
+	key, err := conf.GetAESSecretKey()
+	require.NoError(t, err)
+	authenticator, err := googleTotp.Generate(googleTotp.GenerateOpts{Issuer: "api", AccountName: username})
+	require.NoError(t, err)
+	secret, err := encrypt.EncryptAESSecret(key, authenticator.Secret())
+	require.NoError(t, err)
+
+	repo := repository.NewAccountRepository(pgTestSuite.db)
+	err = repo.Create(ctx, entity.Account{
+		Username:           username,
+		Email:              username + "@example.com",
+		FirstName:          "First",
+		LastName:           "Last",
+		OpaqueRecord:       record.Serialize(),
+		OpaqueCredentialID: credentialID,
+		TOTPSecret:         []byte(secret),
+		EncryptedVaultKey:  []byte("wrapped vault key"),
+	})
+	require.NoError(t, err)
+
+	acc, err := repo.ReadByUsername(ctx, username)
+	require.NoError(t, err)
+
+	return acc, authenticator.Secret()
+}
+
+func newOpaqueClient(t *testing.T) *bytemareOpaque.Client {
+	client, err := opaqueConfiguration().Client()
+	require.NoError(t, err)
+
+	return client
+}
+
+func opaqueConfiguration() *bytemareOpaque.Configuration {
+	return &bytemareOpaque.Configuration{
+		OPRF: bytemareOpaque.P256Sha256,
+		AKE:  bytemareOpaque.P256Sha256,
+		Hash: crypto.SHA256,
+		KDF:  crypto.SHA256,
+		MAC:  crypto.SHA256,
+		KSF:  ksf.Argon2id,
+	}
+}
@@ -0,0 +1,27 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/gin-gonic/gin"
+)
+
+type health struct {
+	Status string            `json:"status"`
+	Opaque *opaque.KeyStatus `json:"opaque,omitempty"`
+}
+
+// HealthServer reports whether the server holds its keys, along with their
+// fingerprints so the operators can tell the replicas run on the same keys.
+func HealthServer(server *gin.Engine, opaqueAdaptor opaque.OpaqueService) {
+	server.GET(PathHealth, func(ctx *gin.Context) {
+		status, err := opaqueAdaptor.Status()
+		if err != nil {
+			ctx.JSON(http.StatusServiceUnavailable, health{Status: "unavailable"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, health{Status: "ok", Opaque: &status})
+	})
+}
@@ -7,16 +7,20 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+const SessionName = "mysession"
+
 const (
 	AuthUserIDKey      = "user_id"
 	AuthUsernameKey    = "username"
 	AuthTwoFactorIDKey = "twoFactorID"
+	AuthAccessTokenKey = "access_token"
 )
 
 const (
 	PageKeyParam     = "page"
 	PageSizeKeyParam = "page-size"
 	SearchKeyParam   = "q"
+	GroupKeyParam    = "group"
 )
 
 func AuthRequired() gin.HandlerFunc {
@@ -5,13 +5,21 @@ const (
 	PathMe = "/"
 
 	// Auth
-	PathSignUp      = "/account/auth/sign-up/"
-	PathSignUpInit  = "/account/auth/sign-up/init/"
-	PathSignUpFinal = "/account/auth/sign-up/final/"
-	PathLogin       = "/account/auth/login/"
-	PathLoginInit   = "/account/auth/login/init/"
-	PathTwoFactor   = "/account/auth/two-factor/"
-	PathLogout      = "/account/auth/logout/"
+	PathSignUp       = "/account/auth/sign-up/"
+	PathSignUpInit   = "/account/auth/sign-up/init/"
+	PathSignUpVerify = "/account/auth/sign-up/verify/"
+	PathSignUpFinal  = "/account/auth/sign-up/final/"
+	PathLogin        = "/account/auth/login/"
+	PathLoginInit    = "/account/auth/login/init/"
+	PathTwoFactor    = "/account/auth/two-factor/"
+	PathLogout       = "/account/auth/logout/"
+	PathRecover      = "/account/auth/recover/"
+	PathRecoverInit  = "/account/auth/recover/init/"
+	PathRecoverFinal = "/account/auth/recover/final/"
+
+	// Authenticator
+	PathAuthenticator        = "/account/authenticator/"
+	PathAuthenticatorConfirm = "/account/authenticator/confirm/"
 
 	// Group
 	PathGroupList         = "/account/groups/"
@@ -19,4 +27,70 @@ const (
 	PathGroupEdit         = "/account/groups/edit/"
 	PathGroupDelete       = "/account/groups/delete/"
 	PathGroupSearchMember = "/account/groups/members/"
+	PathGroupInvite       = "/account/groups/invite/"
+	PathGroupLeave        = "/account/groups/leave/"
+
+	// Group invitation
+	PathGroupInvitations       = "/account/groups/invitations/"
+	PathGroupInvitationAccept  = "/account/groups/invitations/accept/"
+	PathGroupInvitationDecline = "/account/groups/invitations/decline/"
+	PathGroupInvitationCancel  = "/account/groups/invitations/cancel/"
+
+	// Group ownership
+	PathGroupOwnershipOffer   = "/account/groups/ownership/offer/"
+	PathGroupOwnershipCancel  = "/account/groups/ownership/cancel/"
+	PathGroupOwnershipAccept  = "/account/groups/ownership/accept/"
+	PathGroupOwnershipDecline = "/account/groups/ownership/decline/"
+
+	// Session
+	PathSessionList         = "/account/sessions/"
+	PathSessionRevoke       = "/account/sessions/revoke/"
+	PathSessionRevokeOthers = "/account/sessions/revoke-others/"
+
+	// Password
+	PathPasswordChange       = "/account/password/change/"
+	PathPasswordChangeInit   = "/account/password/change/init/"
+	PathPasswordChangeVerify = "/account/password/change/verify/"
+	PathPasswordChangeFinal  = "/account/password/change/final/"
+
+	// Profile
+	PathProfile               = "/account/profile/"
+	PathProfileUpdate         = "/account/profile/update/"
+	PathProfileEmail          = "/account/profile/email/"
+	PathProfileEmailVerify    = "/account/profile/email/verify/"
+	PathProfileUsernameInit   = "/account/profile/username/init/"
+	PathProfileUsernameVerify = "/account/profile/username/verify/"
+	PathProfileUsernameFinal  = "/account/profile/username/final/"
+	PathProfileDelete         = "/account/profile/delete/"
+	PathProfileDeleteInit     = "/account/profile/delete/init/"
+	PathProfileDeleteFinal    = "/account/profile/delete/final/"
+
+	// Keys
+	PathKeys            = "/account/keys/"
+	PathKeysUnlockInit  = "/account/keys/unlock/init/"
+	PathKeysUnlockFinal = "/account/keys/unlock/final/"
+
+	// Emergency access
+	PathEmergencyAccess         = "/account/emergency-access/"
+	PathEmergencyAccessCreate   = "/account/emergency-access/create/"
+	PathEmergencyAccessAccept   = "/account/emergency-access/accept/"
+	PathEmergencyAccessConfirm  = "/account/emergency-access/confirm/"
+	PathEmergencyAccessRequest  = "/account/emergency-access/request/"
+	PathEmergencyAccessApprove  = "/account/emergency-access/approve/"
+	PathEmergencyAccessReject   = "/account/emergency-access/reject/"
+	PathEmergencyAccessDelete   = "/account/emergency-access/delete/"
+	PathEmergencyAccessVaultKey = "/account/emergency-access/vault-key/"
+
+	// Access tokens
+	PathAccessTokens      = "/account/tokens/"
+	PathAccessTokenCreate = "/account/tokens/create/"
+	PathAccessTokenRevoke = "/account/tokens/revoke/"
+
+	// Health
+	PathHealth = "/health/"
+
+	// API
+	PathAPI      = "/api/v1/"
+	PathAPIDoc   = "/api/v1/openapi.json"
+	PathAPIToken = "/api/v1/token/"
 )
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"time"
+
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+)
+
+// APIVaultItem carries the encrypted fields as they were sent, the server can not
+// decrypt them.
+type APIVaultItem struct {
+	ID                types.ID            `json:"id"`
+	Name              string              `json:"name"`
+	Description       string              `json:"description,omitempty"`
+	EncryptedUsername []byte              `json:"encryptedUsername"`
+	EncryptedPassword []byte              `json:"encryptedPassword"`
+	EncryptedUrl      []byte              `json:"encryptedUrl,omitempty"`
+	EncryptedNote     []byte              `json:"encryptedNote,omitempty"`
+	Nonce             []byte              `json:"nonce"`
+	Creator           APIVaultItemCreator `json:"creator"`
+	Groups            []APIVaultItemGroup `json:"groups"`
+	CreatedAt         time.Time           `json:"createdAt"`
+	UpdatedAt         time.Time           `json:"updatedAt"`
+}
+
+type APIVaultItemCreator struct {
+	ID       types.ID `json:"id"`
+	Username string   `json:"username"`
+}
+
+type APIVaultItemGroup struct {
+	ID   types.ID `json:"id"`
+	Name string   `json:"name"`
+}
+
+type APIVaultItemList struct {
+	Items      []APIVaultItem          `json:"items"`
+	Pagination localHttp.APIPagination `json:"pagination"`
+}
+
+// APIVaultItemWrite creates or replaces an item, it is shared with every group in
+// GroupIDs and only with them.
+type APIVaultItemWrite struct {
+	Name              string     `json:"name" binding:"required,max=50"`
+	Description       string     `json:"description,omitempty"`
+	EncryptedUsername []byte     `json:"encryptedUsername" binding:"required"`
+	EncryptedPassword []byte     `json:"encryptedPassword" binding:"required"`
+	EncryptedUrl      []byte     `json:"encryptedUrl,omitempty"`
+	EncryptedNote     []byte     `json:"encryptedNote,omitempty"`
+	Nonce             []byte     `json:"nonce" binding:"required"`
+	GroupIDs          []types.ID `json:"groupIDs,omitempty"`
+}
+
+func NewAPIVaultItem(item entity.VaultItem) APIVaultItem {
+	groups := make([]APIVaultItemGroup, 0, len(item.Groups))
+	for _, group := range item.Groups {
+		groups = append(groups, APIVaultItemGroup{ID: group.ID, Name: group.Name})
+	}
+
+	return APIVaultItem{
+		ID:                item.ID,
+		Name:              item.Name,
+		Description:       item.Description.String,
+		EncryptedUsername: item.EncryptedUsername,
+		EncryptedPassword: item.EncryptedPassword,
+		EncryptedUrl:      item.EncryptedUrl,
+		EncryptedNote:     item.EncryptedNote,
+		Nonce:             item.Nonce,
+		Creator:           APIVaultItemCreator{ID: item.Creator.Entity.ID, Username: item.Creator.Username},
+		Groups:            groups,
+		CreatedAt:         item.CreatedAt,
+		UpdatedAt:         item.UpdatedAt,
+	}
+}
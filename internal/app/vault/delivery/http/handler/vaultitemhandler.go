@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account"
+	accountHandler "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/handler"
+	accountEntity "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/param"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/convertors"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// VaultItemListHandler lists the items the account can see, narrowed down to the items
+// and groups the access token is restricted to.
+func VaultItemListHandler(ctx *gin.Context, usecase usecase.VaultItemUsecase, conf *config.Config) {
+	page := convertors.ParseQueryParamToInt(ctx.Query(localHttp.PageKeyParam), conf.DefaultPage)
+	pageSize := convertors.ParseQueryParamToInt(ctx.Query(localHttp.PageSizeKeyParam), conf.DefaultPageSize)
+	limit, offset := convertors.SimplePaginationToLimitOffset(page, pageSize)
+
+	params := param.ReadVaultItemParams{
+		AccountID:   types.ID(ctx.GetInt64(localHttp.AuthUserIDKey)),
+		GroupID:     types.ID(convertors.ParseQueryParamToInt(ctx.Query(localHttp.GroupKeyParam), 0)),
+		SearchQuery: types.NewNullString(ctx.Query(localHttp.SearchKeyParam)),
+		Limit:       limit,
+		Offset:      offset,
+	}
+	if token, ok := accountHandler.CurrentAccessToken(ctx); ok {
+		params.OnlyItemIDs, params.OnlyGroupIDs = token.ItemIDs, token.GroupIDs
+	}
+
+	items, numRows, err := usecase.Read(ctx, params)
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	response := model.APIVaultItemList{
+		Items:      make([]model.APIVaultItem, 0, len(items)),
+		Pagination: localHttp.APIPagination{Page: page, PageSize: pageSize, Total: numRows},
+	}
+	for _, item := range items {
+		response.Items = append(response.Items, model.NewAPIVaultItem(item))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func VaultItemCreateHandler(ctx *gin.Context, usecase usecase.VaultItemUsecase) {
+	item, ok := bindVaultItem(ctx, nil)
+	if !ok {
+		return
+	}
+
+	if err := usecase.Create(ctx, &item); err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	created, err := usecase.ReadOne(ctx, item.ID, item.Creator.Entity.ID)
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, model.NewAPIVaultItem(created))
+}
+
+func VaultItemReadHandler(ctx *gin.Context, usecase usecase.VaultItemUsecase) {
+	item, ok := readVaultItem(ctx, usecase)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewAPIVaultItem(item))
+}
+
+func VaultItemUpdateHandler(ctx *gin.Context, usecase usecase.VaultItemUsecase) {
+	current, ok := readVaultItem(ctx, usecase)
+	if !ok {
+		return
+	}
+
+	item, ok := bindVaultItem(ctx, &current)
+	if !ok {
+		return
+	}
+
+	item.ID = current.ID
+	if err := usecase.Update(ctx, item); err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	VaultItemReadHandler(ctx, usecase)
+}
+
+func VaultItemDeleteHandler(ctx *gin.Context, usecase usecase.VaultItemUsecase) {
+	item, ok := readVaultItem(ctx, usecase)
+	if !ok {
+		return
+	}
+
+	if err := usecase.Delete(ctx, item.ID, types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))); err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// readVaultItem reads the item of the id param and answers with the error itself when
+// the account can not see it or the token is not allowed to use it.
+func readVaultItem(ctx *gin.Context, usecase usecase.VaultItemUsecase) (entity.VaultItem, bool) {
+	itemID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(vault.VaultItemDoesNotExist))
+		return entity.VaultItem{}, false
+	}
+
+	item, err := usecase.ReadOne(ctx, types.ID(itemID), types.ID(ctx.GetInt64(localHttp.AuthUserIDKey)))
+	if err != nil {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(err))
+		return entity.VaultItem{}, false
+	}
+
+	if token, ok := accountHandler.CurrentAccessToken(ctx); ok && !token.AllowsItem(item.ID, item.GroupIDs()) {
+		localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenRestricted))
+		return entity.VaultItem{}, false
+	}
+
+	return item, true
+}
+
+// bindVaultItem reads the item from the body, current is the stored item on updates.
+// A restricted token can not touch the groups out of its scope, and the items it
+// creates have to be shared with its groups, otherwise it could not read them back.
+func bindVaultItem(ctx *gin.Context, current *entity.VaultItem) (entity.VaultItem, bool) {
+	var body model.APIVaultItemWrite
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		localHttp.HandleAPIBindError(ctx, err)
+		return entity.VaultItem{}, false
+	}
+
+	if token, ok := accountHandler.CurrentAccessToken(ctx); ok && token.Restricted() {
+		var groupIDs []types.ID
+		if current != nil {
+			groupIDs = current.GroupIDs()
+		}
+
+		allowed := token.AllowsRegrouping(groupIDs, body.GroupIDs)
+		if current == nil {
+			allowed = allowed && len(body.GroupIDs) > 0
+		}
+
+		if !allowed {
+			localHttp.HandleAPIError(ctx, errors.Error2Custom(account.AccessTokenRestricted))
+			return entity.VaultItem{}, false
+		}
+	}
+
+	item := entity.VaultItem{
+		Name:              body.Name,
+		Description:       types.NewNullString(body.Description),
+		EncryptedUsername: body.EncryptedUsername,
+		EncryptedPassword: body.EncryptedPassword,
+		EncryptedUrl:      body.EncryptedUrl,
+		EncryptedNote:     body.EncryptedNote,
+		Nonce:             body.Nonce,
+		Creator:           accountEntity.Account{Entity: base.Entity{ID: types.ID(ctx.GetInt64(localHttp.AuthUserIDKey))}},
+	}
+
+	for _, groupID := range body.GroupIDs {
+		group := accountEntity.Group{}
+		group.ID = groupID
+		item.Groups = append(item.Groups, group)
+	}
+
+	return item, true
+}
@@ -0,0 +1,114 @@
+package router
+
+import (
+	nethttp "net/http"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	accountRepository "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/delivery/http/handler"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/delivery/http/handler/model"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/openapi"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// VaultRouter adds the vault items to the JSON API the account app created.
+func VaultRouter(api http.API, db *pgxpool.Pool, conf *config.Config) {
+	vaultItemRepo := repository.NewVaultItemRepository(db)
+	groupRepo := accountRepository.NewGroupRepository(db)
+	vaultItemUsecase := usecase.NewVaultItemUsecase(vaultItemRepo, groupRepo)
+
+	api.Register(VaultAPIRoutes(vaultItemUsecase, conf)...)
+}
+
+// VaultAPIRoutes are the routes of the vault app in the JSON API.
+func VaultAPIRoutes(vaultItemUsecase usecase.VaultItemUsecase, conf *config.Config) []openapi.Route {
+	apiError := http.APIErrorResponse{}
+	itemID := openapi.PathParam("id", "id of the item")
+
+	return []openapi.Route{
+		{
+			Method:  nethttp.MethodGet,
+			Path:    "items/",
+			Summary: "List the items the account created or can see through its groups",
+			Tag:     "items",
+			Params: []openapi.Param{
+				openapi.QueryParam(http.PageKeyParam, "integer", "page number"),
+				openapi.QueryParam(http.PageSizeKeyParam, "integer", "items per page"),
+				openapi.QueryParam(http.SearchKeyParam, "string", "search in the name and description"),
+				openapi.QueryParam(http.GroupKeyParam, "integer", "only the items shared with the group"),
+			},
+			Responses: map[int]any{nethttp.StatusOK: model.APIVaultItemList{}},
+			Handler: func(ctx *gin.Context) {
+				handler.VaultItemListHandler(ctx, vaultItemUsecase, conf)
+			},
+		},
+		{
+			Method:  nethttp.MethodPost,
+			Path:    "items/",
+			Summary: "Create an item",
+			Tag:     "items",
+			Request: model.APIVaultItemWrite{},
+			Responses: map[int]any{
+				nethttp.StatusCreated:    model.APIVaultItem{},
+				nethttp.StatusBadRequest: apiError,
+				nethttp.StatusForbidden:  apiError,
+				nethttp.StatusConflict:   apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.VaultItemCreateHandler(ctx, vaultItemUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodGet,
+			Path:    "items/:id/",
+			Summary: "Read an item",
+			Tag:     "items",
+			Params:  []openapi.Param{itemID},
+			Responses: map[int]any{
+				nethttp.StatusOK:        model.APIVaultItem{},
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.VaultItemReadHandler(ctx, vaultItemUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodPut,
+			Path:    "items/:id/",
+			Summary: "Replace an item, only its creator can",
+			Tag:     "items",
+			Params:  []openapi.Param{itemID},
+			Request: model.APIVaultItemWrite{},
+			Responses: map[int]any{
+				nethttp.StatusOK:         model.APIVaultItem{},
+				nethttp.StatusBadRequest: apiError,
+				nethttp.StatusForbidden:  apiError,
+				nethttp.StatusNotFound:   apiError,
+				nethttp.StatusConflict:   apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.VaultItemUpdateHandler(ctx, vaultItemUsecase)
+			},
+		},
+		{
+			Method:  nethttp.MethodDelete,
+			Path:    "items/:id/",
+			Summary: "Delete an item, only its creator can",
+			Tag:     "items",
+			Params:  []openapi.Param{itemID},
+			Responses: map[int]any{
+				nethttp.StatusNoContent: nil,
+				nethttp.StatusForbidden: apiError,
+				nethttp.StatusNotFound:  apiError,
+			},
+			Handler: func(ctx *gin.Context) {
+				handler.VaultItemDeleteHandler(ctx, vaultItemUsecase)
+			},
+		},
+	}
+}
@@ -6,15 +6,26 @@ import (
 	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/base"
 )
 
-type ValueItem struct {
+// VaultItem is encrypted by the client, the server only sees its name and description.
+// EncryptedUrl and EncryptedNote are nil when the item has none.
+type VaultItem struct {
 	base.Entity
 	Name              string
 	Description       types.NullString
-	EncryptedUsername byte
-	EncryptedPassword byte
-	EncryptedUrl      types.NullByte
-	EncryptedNote     types.NullByte
-	Nonce             byte
+	EncryptedUsername []byte
+	EncryptedPassword []byte
+	EncryptedUrl      []byte
+	EncryptedNote     []byte
+	Nonce             []byte
 	Creator           entity.Account
 	Groups            []entity.Group
 }
+
+func (i VaultItem) GroupIDs() []types.ID {
+	ids := make([]types.ID, 0, len(i.Groups))
+	for _, group := range i.Groups {
+		ids = append(ids, group.ID)
+	}
+
+	return ids
+}
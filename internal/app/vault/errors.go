@@ -0,0 +1,33 @@
+package vault
+
+import "github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+
+const (
+	CodeVaultItemInvalidGroup = 400_200
+
+	CodeVaultItemOnlyEditorsCanEdit      = 403_200
+	CodeVaultItemOnlyTheCreatorCanDelete = 403_201
+	CodeVaultItemOnlyTheCreatorCanShare  = 403_202
+
+	CodeVaultItemDoesNotExist = 404_200
+
+	CodeVaultItemNameExist = 409_200
+)
+
+const (
+	MessageVaultItemInvalidGroup            = "an item can only be shared with groups you are an editor of"
+	MessageVaultItemOnlyEditorsCanEdit      = "only the creator of the item and the editors of its groups can edit it"
+	MessageVaultItemOnlyTheCreatorCanDelete = "only the creator of the item can delete it"
+	MessageVaultItemOnlyTheCreatorCanShare  = "only the creator of the item can change the groups it is shared with"
+	MessageVaultItemDoesNotExist            = "vault item does not exist"
+	MessageVaultItemNameExist               = "you already have an item with that name"
+)
+
+var (
+	VaultItemInvalidGroup            = errors.NewError(MessageVaultItemInvalidGroup, CodeVaultItemInvalidGroup)
+	VaultItemOnlyEditorsCanEdit      = errors.NewError(MessageVaultItemOnlyEditorsCanEdit, CodeVaultItemOnlyEditorsCanEdit)
+	VaultItemOnlyTheCreatorCanDelete = errors.NewError(MessageVaultItemOnlyTheCreatorCanDelete, CodeVaultItemOnlyTheCreatorCanDelete)
+	VaultItemOnlyTheCreatorCanShare  = errors.NewError(MessageVaultItemOnlyTheCreatorCanShare, CodeVaultItemOnlyTheCreatorCanShare)
+	VaultItemDoesNotExist            = errors.NewError(MessageVaultItemDoesNotExist, CodeVaultItemDoesNotExist)
+	VaultItemNameExist               = errors.NewError(MessageVaultItemNameExist, CodeVaultItemNameExist)
+)
@@ -0,0 +1,16 @@
+package param
+
+import "github.com/TheAmirhosssein/cool-password-manage/internal/types"
+
+type ReadVaultItemParams struct {
+	AccountID   types.ID
+	GroupID     types.ID
+	SearchQuery types.NullString
+	Limit       int
+	Offset      int
+
+	// OnlyItemIDs and OnlyGroupIDs narrow the items down to the ones an access token
+	// is restricted to, they are ignored when both are empty.
+	OnlyItemIDs  []types.ID
+	OnlyGroupIDs []types.ID
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	accountEntity "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/param"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/helper"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/jackc/pgx/v5"
+)
+
+type VaultItemRepository interface {
+	Create(ctx context.Context, item *entity.VaultItem) error
+	Read(ctx context.Context, params param.ReadVaultItemParams) ([]entity.VaultItem, int, error)
+	ReadOne(ctx context.Context, id, accountID types.ID) (entity.VaultItem, error)
+	ExistByName(ctx context.Context, name string, creatorID, exceptID types.ID) (bool, error)
+	Update(ctx context.Context, item entity.VaultItem) error
+	Delete(ctx context.Context, id, creatorID types.ID) error
+}
+
+type vaultItemRepo struct {
+	db database.Querier
+}
+
+func NewVaultItemRepository(db database.Querier) VaultItemRepository {
+	return vaultItemRepo{db: database.Join(db)}
+}
+
+const vaultItemSelect = `
+	SELECT v.id, v.name, v.description, v.encrypted_username, v.encrypted_password, v.encrypted_url,
+		v.encrypted_note, v.nonce, v.created_at, v.updated_at, c.id, c.username,
+		ARRAY(SELECT g.id FROM vault_items_groups vg JOIN groups g ON g.id = vg.group_id
+			WHERE vg.vault_item_id = v.id ORDER BY g.id),
+		ARRAY(SELECT g.name FROM vault_items_groups vg JOIN groups g ON g.id = vg.group_id
+			WHERE vg.vault_item_id = v.id ORDER BY g.id)
+	FROM vault_items v
+	JOIN accounts c ON c.id = v.creator_id`
+
+// vaultItemVisible limits the items to the ones the account created or can see through
+// one of its groups, $1 is the account.
+const vaultItemVisible = `(v.creator_id = $1 OR v.id IN (
+	SELECT vg.vault_item_id FROM vault_items_groups vg
+	JOIN groups_accounts ga ON ga.group_id = vg.group_id
+	WHERE ga.account_id = $1
+))`
+
+// Create stores the item and shares it with its groups in one transaction.
+func (r vaultItemRepo) Create(ctx context.Context, item *entity.VaultItem) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		log.ErrorLogger.Error("error at beginning transaction", "error", err.Error())
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	INSERT INTO vault_items (name, description, encrypted_username, encrypted_password, encrypted_url,
+		encrypted_note, nonce, creator_id)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at, updated_at`
+
+	err = tx.QueryRow(
+		ctx, query, item.Name, item.Description, item.EncryptedUsername, item.EncryptedPassword, item.EncryptedUrl,
+		item.EncryptedNote, item.Nonce, item.Creator.Entity.ID,
+	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		log.ErrorLogger.Error("error at creating vault item", "error", err.Error())
+		return err
+	}
+
+	if err := shareVaultItem(ctx, tx, item.ID, item.GroupIDs()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.ErrorLogger.Error("error at committing vault item", "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r vaultItemRepo) Read(ctx context.Context, params param.ReadVaultItemParams) ([]entity.VaultItem, int, error) {
+	where := "WHERE " + vaultItemVisible
+	args := []any{params.AccountID}
+
+	if params.GroupID.Valid() {
+		args = append(args, params.GroupID)
+		where += fmt.Sprintf(" AND v.id IN (SELECT vault_item_id FROM vault_items_groups WHERE group_id = $%d)", len(args))
+	}
+
+	if len(params.OnlyItemIDs) > 0 || len(params.OnlyGroupIDs) > 0 {
+		args = append(args, int64s(params.OnlyItemIDs), int64s(params.OnlyGroupIDs))
+		where += fmt.Sprintf(
+			" AND (v.id = ANY($%d) OR v.id IN (SELECT vault_item_id FROM vault_items_groups WHERE group_id = ANY($%d)))",
+			len(args)-1, len(args),
+		)
+	}
+
+	where += " " + helper.MakeSearchQuery(params.SearchQuery, []string{"v.name", "v.description"})
+
+	var count int
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM vault_items v "+where, args...).Scan(&count)
+	if err != nil {
+		log.ErrorLogger.Error("error at counting vault items", "error", err.Error(), "account_id", params.AccountID)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("%s %s ORDER BY v.id LIMIT $%d OFFSET $%d", vaultItemSelect, where, len(args)+1, len(args)+2)
+	rows, err := r.db.Query(ctx, query, append(args, params.Limit, params.Offset)...)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading vault items", "error", err.Error(), "account_id", params.AccountID)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := make([]entity.VaultItem, 0)
+	for rows.Next() {
+		item, err := scanVaultItem(rows)
+		if err != nil {
+			log.ErrorLogger.Error("error at scanning vault item", "error", err.Error())
+			return nil, 0, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, count, rows.Err()
+}
+
+// ReadOne returns pgx.ErrNoRows when the item does not exist or the account can not see it.
+func (r vaultItemRepo) ReadOne(ctx context.Context, id, accountID types.ID) (entity.VaultItem, error) {
+	query := vaultItemSelect + " WHERE " + vaultItemVisible + " AND v.id = $2"
+
+	item, err := scanVaultItem(r.db.QueryRow(ctx, query, accountID, id))
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			log.ErrorLogger.Error("error at reading vault item", "error", err.Error(), "id", id)
+		}
+		return entity.VaultItem{}, err
+	}
+
+	return item, nil
+}
+
+func (r vaultItemRepo) ExistByName(ctx context.Context, name string, creatorID, exceptID types.ID) (bool, error) {
+	query := "SELECT EXISTS(SELECT 1 FROM vault_items WHERE name = $1 AND creator_id = $2 AND id <> $3)"
+
+	var exist bool
+	if err := r.db.QueryRow(ctx, query, name, creatorID, exceptID).Scan(&exist); err != nil {
+		log.ErrorLogger.Error("error at checking vault item existence", "error", err.Error())
+		return false, err
+	}
+
+	return exist, nil
+}
+
+// Update replaces the item and its groups, only the creator can update an item.
+func (r vaultItemRepo) Update(ctx context.Context, item entity.VaultItem) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		log.ErrorLogger.Error("error at beginning transaction", "error", err.Error())
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+	UPDATE vault_items
+	SET name = $1, description = $2, encrypted_username = $3, encrypted_password = $4, encrypted_url = $5,
+		encrypted_note = $6, nonce = $7, updated_at = CURRENT_TIMESTAMP
+	WHERE id = $8 AND creator_id = $9`
+
+	tag, err := tx.Exec(
+		ctx, query, item.Name, item.Description, item.EncryptedUsername, item.EncryptedPassword, item.EncryptedUrl,
+		item.EncryptedNote, item.Nonce, item.ID, item.Creator.Entity.ID,
+	)
+	if err != nil {
+		log.ErrorLogger.Error("error at updating vault item", "error", err.Error(), "id", item.ID)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM vault_items_groups WHERE vault_item_id = $1", item.ID); err != nil {
+		log.ErrorLogger.Error("error at unsharing vault item", "error", err.Error(), "id", item.ID)
+		return err
+	}
+
+	if err := shareVaultItem(ctx, tx, item.ID, item.GroupIDs()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.ErrorLogger.Error("error at committing vault item", "error", err.Error(), "id", item.ID)
+		return err
+	}
+
+	return nil
+}
+
+func (r vaultItemRepo) Delete(ctx context.Context, id, creatorID types.ID) error {
+	tag, err := r.db.Exec(ctx, "DELETE FROM vault_items WHERE id = $1 AND creator_id = $2", id, creatorID)
+	if err != nil {
+		log.ErrorLogger.Error("error at deleting vault item", "error", err.Error(), "id", id)
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func shareVaultItem(ctx context.Context, tx pgx.Tx, itemID types.ID, groupIDs []types.ID) error {
+	for _, groupID := range groupIDs {
+		query := "INSERT INTO vault_items_groups (vault_item_id, group_id) VALUES ($1, $2)"
+		if _, err := tx.Exec(ctx, query, itemID, groupID); err != nil {
+			log.ErrorLogger.Error("error at sharing vault item", "error", err.Error(), "id", itemID, "group_id", groupID)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func scanVaultItem(row pgx.Row) (entity.VaultItem, error) {
+	var (
+		item       entity.VaultItem
+		groupIDs   []int64
+		groupNames []string
+	)
+
+	err := row.Scan(
+		&item.ID, &item.Name, &item.Description, &item.EncryptedUsername, &item.EncryptedPassword, &item.EncryptedUrl,
+		&item.EncryptedNote, &item.Nonce, &item.CreatedAt, &item.UpdatedAt, &item.Creator.Entity.ID, &item.Creator.Username,
+		&groupIDs, &groupNames,
+	)
+	if err != nil {
+		return entity.VaultItem{}, err
+	}
+
+	item.Groups = make([]accountEntity.Group, 0, len(groupIDs))
+	for i, id := range groupIDs {
+		group := accountEntity.Group{Name: groupNames[i]}
+		group.ID = types.ID(id)
+		item.Groups = append(item.Groups, group)
+	}
+
+	return item, nil
+}
+
+func int64s(ids []types.ID) []int64 {
+	values := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		values = append(values, int64(id))
+	}
+
+	return values
+}
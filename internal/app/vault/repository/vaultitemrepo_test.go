@@ -0,0 +1,268 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	accountEntity "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	accountRepository "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/param"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/seed"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/testdocker"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+type postgresTest struct {
+	db   *pgxpool.Pool
+	name string
+}
+
+var pgTestSuite postgresTest
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+
+	pgName, pgTest := database.SetupTestDB(ctx)
+	pgTestSuite = postgresTest{name: pgName, db: pgTest}
+
+	seed.CreateSeed(ctx, pgTestSuite.db)
+
+	exitCode := m.Run()
+	testdocker.StopAndRemoveContainer(ctx, pgTestSuite.name, pgTestSuite.name)
+
+	os.Exit(exitCode)
+}
+
+func TestVaultItemRepository_CreateAndReadOne(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewVaultItemRepository(pgTestSuite.db)
+	creator := createAccount(t, "vault_create_creator")
+	member := createAccount(t, "vault_create_member")
+	stranger := createAccount(t, "vault_create_stranger")
+	group := createGroup(t, "vault create group", creator, member)
+
+	item := newVaultItem("mail", creator, group)
+	require.NoError(t, repo.Create(ctx, &item))
+	require.True(t, item.ID.Valid())
+
+	testcases := []struct {
+		name    string
+		account accountEntity.Account
+		err     error
+	}{
+		{
+			name:    "creator",
+			account: creator,
+		},
+		{
+			name:    "member of a group of the item",
+			account: member,
+		},
+		{
+			name:    "account that can not see the item",
+			account: stranger,
+			err:     pgx.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			saved, err := repo.ReadOne(ctx, item.ID, tc.account.Entity.ID)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, item.Name, saved.Name)
+			require.Equal(t, item.EncryptedPassword, saved.EncryptedPassword)
+			require.Nil(t, saved.EncryptedNote)
+			require.Equal(t, creator.Username, saved.Creator.Username)
+			require.Len(t, saved.Groups, 1)
+			require.Equal(t, group.Name, saved.Groups[0].Name)
+		})
+	}
+}
+
+func TestVaultItemRepository_Read(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewVaultItemRepository(pgTestSuite.db)
+	creator := createAccount(t, "vault_read_creator")
+	member := createAccount(t, "vault_read_member")
+	group := createGroup(t, "vault read group", creator, member)
+
+	private := newVaultItem("private", creator)
+	require.NoError(t, repo.Create(ctx, &private))
+	shared := newVaultItem("shared", creator, group)
+	require.NoError(t, repo.Create(ctx, &shared))
+
+	testcases := []struct {
+		name   string
+		params param.ReadVaultItemParams
+		want   []types.ID
+		count  int
+	}{
+		{
+			name:   "every item of the creator",
+			params: param.ReadVaultItemParams{AccountID: creator.Entity.ID, Limit: 10},
+			want:   []types.ID{private.ID, shared.ID},
+			count:  2,
+		},
+		{
+			name:   "items shared with the member",
+			params: param.ReadVaultItemParams{AccountID: member.Entity.ID, Limit: 10},
+			want:   []types.ID{shared.ID},
+			count:  1,
+		},
+		{
+			name:   "items of a group",
+			params: param.ReadVaultItemParams{AccountID: creator.Entity.ID, GroupID: group.ID, Limit: 10},
+			want:   []types.ID{shared.ID},
+			count:  1,
+		},
+		{
+			name: "search",
+			params: param.ReadVaultItemParams{
+				AccountID: creator.Entity.ID, SearchQuery: types.NewNullString("priv"), Limit: 10,
+			},
+			want:  []types.ID{private.ID},
+			count: 1,
+		},
+		{
+			name: "restricted to an item",
+			params: param.ReadVaultItemParams{
+				AccountID: creator.Entity.ID, OnlyItemIDs: []types.ID{private.ID}, Limit: 10,
+			},
+			want:  []types.ID{private.ID},
+			count: 1,
+		},
+		{
+			name: "restricted to a group",
+			params: param.ReadVaultItemParams{
+				AccountID: creator.Entity.ID, OnlyGroupIDs: []types.ID{group.ID}, Limit: 10,
+			},
+			want:  []types.ID{shared.ID},
+			count: 1,
+		},
+		{
+			name:   "second page",
+			params: param.ReadVaultItemParams{AccountID: creator.Entity.ID, Limit: 1, Offset: 1},
+			want:   []types.ID{shared.ID},
+			count:  2,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			items, count, err := repo.Read(ctx, tc.params)
+			require.NoError(t, err)
+			require.Equal(t, tc.count, count)
+
+			ids := make([]types.ID, 0, len(items))
+			for _, item := range items {
+				ids = append(ids, item.ID)
+			}
+			require.Equal(t, tc.want, ids)
+		})
+	}
+}
+
+func TestVaultItemRepository_UpdateAndDelete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repo := repository.NewVaultItemRepository(pgTestSuite.db)
+	creator := createAccount(t, "vault_update_creator")
+	member := createAccount(t, "vault_update_member")
+	group := createGroup(t, "vault update group", creator, member)
+
+	item := newVaultItem("bank", creator, group)
+	require.NoError(t, repo.Create(ctx, &item))
+
+	exist, err := repo.ExistByName(ctx, "bank", creator.Entity.ID, 0)
+	require.NoError(t, err)
+	require.True(t, exist)
+
+	exist, err = repo.ExistByName(ctx, "bank", creator.Entity.ID, item.ID)
+	require.NoError(t, err)
+	require.False(t, exist)
+
+	// only the creator can update the item
+	updated := item
+	updated.Name = "bank account"
+	updated.Creator = member
+	require.ErrorIs(t, repo.Update(ctx, updated), pgx.ErrNoRows)
+
+	// unsharing the item hides it from the members of the group
+	updated.Creator = creator
+	updated.Groups = nil
+	updated.EncryptedNote = []byte("note")
+	require.NoError(t, repo.Update(ctx, updated))
+
+	saved, err := repo.ReadOne(ctx, item.ID, creator.Entity.ID)
+	require.NoError(t, err)
+	require.Equal(t, "bank account", saved.Name)
+	require.Equal(t, []byte("note"), saved.EncryptedNote)
+	require.Empty(t, saved.Groups)
+
+	_, err = repo.ReadOne(ctx, item.ID, member.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	require.ErrorIs(t, repo.Delete(ctx, item.ID, member.Entity.ID), pgx.ErrNoRows)
+	require.NoError(t, repo.Delete(ctx, item.ID, creator.Entity.ID))
+
+	_, err = repo.ReadOne(ctx, item.ID, creator.Entity.ID)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func newVaultItem(name string, creator accountEntity.Account, groups ...accountEntity.Group) entity.VaultItem {
+	return entity.VaultItem{
+		Name:              name,
+		Description:       types.NewNullString(name + " login"),
+		EncryptedUsername: []byte("username"),
+		EncryptedPassword: []byte("password"),
+		Nonce:             []byte("nonce"),
+		Creator:           creator,
+		Groups:            groups,
+	}
+}
+
+func createAccount(t *testing.T, username string) accountEntity.Account {
+	ctx := context.Background()
+	repo := accountRepository.NewAccountRepository(pgTestSuite.db)
+
+	err := repo.Create(ctx, accountEntity.Account{
+		Username:     username,
+		Email:        username + "@example.com",
+		FirstName:    "First",
+		LastName:     "Last",
+		OpaqueRecord: []byte("record"),
+		TOTPSecret:   []byte("secret"),
+	})
+	require.NoError(t, err)
+
+	acc, err := repo.ReadByUsername(ctx, username)
+	require.NoError(t, err)
+
+	return acc
+}
+
+func createGroup(t *testing.T, name string, owner accountEntity.Account, members ...accountEntity.Account) accountEntity.Group {
+	ctx := context.Background()
+	repo := accountRepository.NewGroupRepository(pgTestSuite.db)
+
+	group := accountEntity.Group{Name: name, Owner: owner}
+	require.NoError(t, repo.Create(ctx, &group))
+	require.NoError(t, repo.AddAccounts(ctx, group.ID, []accountEntity.Account{owner}, accountEntity.GroupRoleOwner))
+	require.NoError(t, repo.AddAccounts(ctx, group.ID, members, accountEntity.GroupRoleEditor))
+
+	return group
+}
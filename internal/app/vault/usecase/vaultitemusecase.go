@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+
+	accountRepository "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/param"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/errors"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+	"github.com/jackc/pgx/v5"
+)
+
+type VaultItemUsecase struct {
+	vaultItemRepo repository.VaultItemRepository
+	groupRepo     accountRepository.GroupRepository
+}
+
+func NewVaultItemUsecase(
+	vaultItemRepo repository.VaultItemRepository, groupRepo accountRepository.GroupRepository,
+) VaultItemUsecase {
+	return VaultItemUsecase{vaultItemRepo: vaultItemRepo, groupRepo: groupRepo}
+}
+
+func (u VaultItemUsecase) Read(ctx context.Context, params param.ReadVaultItemParams) ([]entity.VaultItem, int, error) {
+	items, count, err := u.vaultItemRepo.Read(ctx, params)
+	if err != nil {
+		log.ErrorLogger.Error("error at reading vault items", "error", err.Error())
+		return nil, 0, errors.NewServerError()
+	}
+
+	return items, count, nil
+}
+
+// ReadOne returns the item when the account created it or is a member of one of its groups.
+func (u VaultItemUsecase) ReadOne(ctx context.Context, id, accountID types.ID) (entity.VaultItem, error) {
+	item, err := u.vaultItemRepo.ReadOne(ctx, id, accountID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return entity.VaultItem{}, vault.VaultItemDoesNotExist
+		}
+		log.ErrorLogger.Error("error at reading vault item", "error", err.Error())
+		return entity.VaultItem{}, errors.NewServerError()
+	}
+
+	return item, nil
+}
+
+func (u VaultItemUsecase) Create(ctx context.Context, item *entity.VaultItem) error {
+	if err := u.validate(ctx, *item, nil); err != nil {
+		return err
+	}
+
+	if err := u.vaultItemRepo.Create(ctx, item); err != nil {
+		log.ErrorLogger.Error("error at creating vault item", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+// Update replaces the item, item.Creator is the account that edits it. Its creator and
+// the editors of the groups it is shared with can edit it, but only the creator can
+// change the groups.
+func (u VaultItemUsecase) Update(ctx context.Context, item entity.VaultItem) error {
+	editorID := item.Creator.Entity.ID
+	current, err := u.ReadOne(ctx, item.ID, editorID)
+	if err != nil {
+		return err
+	}
+
+	if current.Creator.Entity.ID != editorID {
+		canEdit, err := u.canEdit(ctx, current, editorID)
+		if err != nil {
+			return err
+		}
+
+		if !canEdit {
+			return vault.VaultItemOnlyEditorsCanEdit
+		}
+
+		if !sameGroups(current.GroupIDs(), item.GroupIDs()) {
+			return vault.VaultItemOnlyTheCreatorCanShare
+		}
+
+		item.Creator = current.Creator
+	}
+
+	if err := u.validate(ctx, item, current.GroupIDs()); err != nil {
+		return err
+	}
+
+	if err := u.vaultItemRepo.Update(ctx, item); err != nil {
+		log.ErrorLogger.Error("error at updating vault item", "error", err.Error(), "id", item.ID)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+func (u VaultItemUsecase) Delete(ctx context.Context, id, accountID types.ID) error {
+	item, err := u.ReadOne(ctx, id, accountID)
+	if err != nil {
+		return err
+	}
+
+	if item.Creator.Entity.ID != accountID {
+		return vault.VaultItemOnlyTheCreatorCanDelete
+	}
+
+	if err := u.vaultItemRepo.Delete(ctx, id, accountID); err != nil {
+		log.ErrorLogger.Error("error at deleting vault item", "error", err.Error(), "id", id)
+		return errors.NewServerError()
+	}
+
+	return nil
+}
+
+// canEdit reports whether the account is an editor of one of the groups the item is
+// shared with.
+func (u VaultItemUsecase) canEdit(ctx context.Context, item entity.VaultItem, accountID types.ID) (bool, error) {
+	for _, groupID := range item.GroupIDs() {
+		group, err := u.groupRepo.ReadOne(ctx, groupID, accountID)
+		if err != nil {
+			log.ErrorLogger.Error("error at reading group", "error", err.Error(), "group_id", groupID)
+			return false, errors.NewServerError()
+		}
+
+		if group.Role(accountID).CanEditItems() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// validate checks the name is free among the items of the creator and the creator is
+// an editor of every group the item is newly shared with, sharedWith are the groups the
+// item is already shared with.
+func (u VaultItemUsecase) validate(ctx context.Context, item entity.VaultItem, sharedWith []types.ID) error {
+	exist, err := u.vaultItemRepo.ExistByName(ctx, item.Name, item.Creator.Entity.ID, item.ID)
+	if err != nil {
+		log.ErrorLogger.Error("error at checking vault item name", "error", err.Error())
+		return errors.NewServerError()
+	}
+
+	if exist {
+		return vault.VaultItemNameExist
+	}
+
+	for _, groupID := range item.GroupIDs() {
+		if slices.Contains(sharedWith, groupID) {
+			continue
+		}
+
+		group, err := u.groupRepo.ReadOne(ctx, groupID, item.Creator.Entity.ID)
+		if err != nil {
+			log.ErrorLogger.Error("error at reading group", "error", err.Error(), "group_id", groupID)
+			return errors.NewServerError()
+		}
+
+		if !group.Role(item.Creator.Entity.ID).CanEditItems() {
+			return vault.VaultItemInvalidGroup
+		}
+	}
+
+	return nil
+}
+
+func sameGroups(a, b []types.ID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, id := range b {
+		if !slices.Contains(a, id) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,257 @@
+package usecase_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	accountEntity "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/entity"
+	accountRepository "github.com/TheAmirhosssein/cool-password-manage/internal/app/account/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/entity"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/repository"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/usecase"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/seed"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/types"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/testdocker"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+)
+
+type postgresTest struct {
+	db   *pgxpool.Pool
+	name string
+}
+
+var pgTestSuite postgresTest
+
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+
+	pgName, pgTest := database.SetupTestDB(ctx)
+	pgTestSuite = postgresTest{name: pgName, db: pgTest}
+
+	seed.CreateSeed(ctx, pgTestSuite.db)
+
+	exitCode := m.Run()
+	testdocker.StopAndRemoveContainer(ctx, pgTestSuite.name, pgTestSuite.name)
+
+	os.Exit(exitCode)
+}
+
+func TestVaultItemUsecase_Create(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	u := setupVaultItemUsecase()
+	creator := createAccount(t, "vault_usecase_create_creator")
+	group := createGroup(t, "vault usecase create group", creator)
+	viewed := createGroup(t, "vault usecase create viewed group", createAccount(t, "vault_usecase_create_owner"))
+	addMember(t, viewed, creator, accountEntity.GroupRoleViewer)
+
+	existing := newVaultItem("existing", creator)
+	require.NoError(t, u.Create(ctx, &existing))
+
+	testcases := []struct {
+		name   string
+		item   entity.VaultItem
+		err    error
+		groups int
+	}{
+		{
+			name: "taken name",
+			item: newVaultItem("existing", creator),
+			err:  vault.VaultItemNameExist,
+		},
+		{
+			name: "group the creator is not a member of",
+			item: newVaultItem("foreign", creator, seed.GroupBlackHippy),
+			err:  vault.VaultItemInvalidGroup,
+		},
+		{
+			name: "group the creator is a viewer of",
+			item: newVaultItem("viewed", creator, viewed),
+			err:  vault.VaultItemInvalidGroup,
+		},
+		{
+			name:   "shared with a group of the creator",
+			item:   newVaultItem("shared", creator, group),
+			groups: 1,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := u.Create(ctx, &tc.item)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			saved, err := u.ReadOne(ctx, tc.item.ID, creator.Entity.ID)
+			require.NoError(t, err)
+			require.Len(t, saved.Groups, tc.groups)
+		})
+	}
+}
+
+func TestVaultItemUsecase_Update(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	u := setupVaultItemUsecase()
+	creator := createAccount(t, "vault_usecase_update_creator")
+	member := createAccount(t, "vault_usecase_update_member")
+	stranger := createAccount(t, "vault_usecase_update_stranger")
+	viewer := createAccount(t, "vault_usecase_update_viewer")
+	group := createGroup(t, "vault usecase update group", creator, member)
+	addMember(t, group, viewer, accountEntity.GroupRoleViewer)
+
+	item := newVaultItem("router", creator, group)
+	require.NoError(t, u.Create(ctx, &item))
+	other := newVaultItem("taken", creator)
+	require.NoError(t, u.Create(ctx, &other))
+
+	testcases := []struct {
+		name    string
+		editor  accountEntity.Account
+		rename  string
+		unshare bool
+		err     error
+	}{
+		{
+			name:   "account that can not see the item",
+			editor: stranger,
+			rename: "router",
+			err:    vault.VaultItemDoesNotExist,
+		},
+		{
+			name:   "viewer of a group of the item",
+			editor: viewer,
+			rename: "router",
+			err:    vault.VaultItemOnlyEditorsCanEdit,
+		},
+		{
+			name:    "editor unsharing the item",
+			editor:  member,
+			rename:  "router",
+			unshare: true,
+			err:     vault.VaultItemOnlyTheCreatorCanShare,
+		},
+		{
+			name:   "editor of a group of the item",
+			editor: member,
+			rename: "office router",
+		},
+		{
+			name:   "name of another item",
+			editor: creator,
+			rename: "taken",
+			err:    vault.VaultItemNameExist,
+		},
+		{
+			name:   "creator",
+			editor: creator,
+			rename: "home router",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			updated := item
+			updated.Name = tc.rename
+			updated.Creator = tc.editor
+			if tc.unshare {
+				updated.Groups = nil
+			}
+
+			err := u.Update(ctx, updated)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			saved, err := u.ReadOne(ctx, item.ID, member.Entity.ID)
+			require.NoError(t, err)
+			require.Equal(t, tc.rename, saved.Name)
+			require.Equal(t, creator.Entity.ID, saved.Creator.Entity.ID)
+		})
+	}
+}
+
+func TestVaultItemUsecase_Delete(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	u := setupVaultItemUsecase()
+	creator := createAccount(t, "vault_usecase_delete_creator")
+	member := createAccount(t, "vault_usecase_delete_member")
+	group := createGroup(t, "vault usecase delete group", creator, member)
+
+	item := newVaultItem("wifi", creator, group)
+	require.NoError(t, u.Create(ctx, &item))
+
+	err := u.Delete(ctx, item.ID, member.Entity.ID)
+	require.ErrorIs(t, err, vault.VaultItemOnlyTheCreatorCanDelete)
+
+	err = u.Delete(ctx, item.ID, creator.Entity.ID)
+	require.NoError(t, err)
+
+	_, err = u.ReadOne(ctx, item.ID, creator.Entity.ID)
+	require.ErrorIs(t, err, vault.VaultItemDoesNotExist)
+}
+
+func setupVaultItemUsecase() usecase.VaultItemUsecase {
+	return usecase.NewVaultItemUsecase(
+		repository.NewVaultItemRepository(pgTestSuite.db), accountRepository.NewGroupRepository(pgTestSuite.db),
+	)
+}
+
+func newVaultItem(name string, creator accountEntity.Account, groups ...accountEntity.Group) entity.VaultItem {
+	return entity.VaultItem{
+		Name:              name,
+		Description:       types.NewNullString(name + " login"),
+		EncryptedUsername: []byte("username"),
+		EncryptedPassword: []byte("password"),
+		Nonce:             []byte("nonce"),
+		Creator:           creator,
+		Groups:            groups,
+	}
+}
+
+func createAccount(t *testing.T, username string) accountEntity.Account {
+	ctx := context.Background()
+	repo := accountRepository.NewAccountRepository(pgTestSuite.db)
+
+	err := repo.Create(ctx, accountEntity.Account{
+		Username:     username,
+		Email:        username + "@example.com",
+		FirstName:    "First",
+		LastName:     "Last",
+		OpaqueRecord: []byte("record"),
+		TOTPSecret:   []byte("secret"),
+	})
+	require.NoError(t, err)
+
+	acc, err := repo.ReadByUsername(ctx, username)
+	require.NoError(t, err)
+
+	return acc
+}
+
+func createGroup(t *testing.T, name string, owner accountEntity.Account, members ...accountEntity.Account) accountEntity.Group {
+	ctx := context.Background()
+	repo := accountRepository.NewGroupRepository(pgTestSuite.db)
+
+	group := accountEntity.Group{Name: name, Owner: owner}
+	require.NoError(t, repo.Create(ctx, &group))
+	require.NoError(t, repo.AddAccounts(ctx, group.ID, []accountEntity.Account{owner}, accountEntity.GroupRoleOwner))
+	require.NoError(t, repo.AddAccounts(ctx, group.ID, members, accountEntity.GroupRoleEditor))
+
+	return group
+}
+
+func addMember(t *testing.T, group accountEntity.Group, account accountEntity.Account, role accountEntity.GroupRole) {
+	repo := accountRepository.NewGroupRepository(pgTestSuite.db)
+	require.NoError(t, repo.AddAccounts(context.Background(), group.ID, []accountEntity.Account{account}, role))
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS recovery_verifier BYTEA;
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS recovery_vault_key BYTEA;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE accounts DROP COLUMN IF EXISTS recovery_vault_key;
+ALTER TABLE accounts DROP COLUMN IF EXISTS recovery_verifier;
+-- +goose StatementEnd
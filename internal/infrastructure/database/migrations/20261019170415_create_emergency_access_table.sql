@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS public_key BYTEA;
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS encrypted_private_key BYTEA;
+
+CREATE TABLE IF NOT EXISTS emergency_access(
+    id SERIAL PRIMARY KEY,
+    grantor_id INT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    grantee_id INT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    wait_days INT NOT NULL,
+    encrypted_vault_key BYTEA,
+    requested_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (grantor_id, grantee_id),
+    CHECK (grantor_id <> grantee_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS emergency_access;
+ALTER TABLE accounts DROP COLUMN IF EXISTS encrypted_private_key;
+ALTER TABLE accounts DROP COLUMN IF EXISTS public_key;
+-- +goose StatementEnd
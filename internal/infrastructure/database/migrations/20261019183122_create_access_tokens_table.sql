@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS access_tokens(
+    id SERIAL PRIMARY KEY,
+    account_id INT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    token_hash BYTEA NOT NULL UNIQUE,
+    permission VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    UNIQUE (name, account_id)
+);
+
+CREATE TABLE IF NOT EXISTS access_tokens_vault_items (
+    access_token_id INT REFERENCES access_tokens(id) ON DELETE CASCADE,
+    vault_item_id INT REFERENCES vault_items(id) ON DELETE CASCADE,
+    PRIMARY KEY(access_token_id, vault_item_id)
+);
+
+CREATE TABLE IF NOT EXISTS access_tokens_groups (
+    access_token_id INT REFERENCES access_tokens(id) ON DELETE CASCADE,
+    group_id INT REFERENCES groups(id) ON DELETE CASCADE,
+    PRIMARY KEY(access_token_id, group_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS access_tokens_groups;
+DROP TABLE IF EXISTS access_tokens_vault_items;
+DROP TABLE IF EXISTS access_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE groups_accounts ADD COLUMN IF NOT EXISTS role VARCHAR(10) NOT NULL DEFAULT 'viewer'
+    CHECK (role IN ('owner', 'admin', 'editor', 'viewer'));
+
+-- every member could share and edit items before there were roles
+UPDATE groups_accounts ga SET role = CASE WHEN g.owner_id = ga.account_id THEN 'owner' ELSE 'editor' END
+FROM groups g WHERE g.id = ga.group_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE groups_accounts DROP COLUMN IF EXISTS role;
+-- +goose StatementEnd
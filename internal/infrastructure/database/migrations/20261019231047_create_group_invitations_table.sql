@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS group_invitations(
+    id SERIAL PRIMARY KEY,
+    group_id INT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    inviter_id INT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    invitee_id INT REFERENCES accounts(id) ON DELETE CASCADE,
+    email VARCHAR(150),
+    role VARCHAR(10) NOT NULL CHECK (role IN ('admin', 'editor', 'viewer')),
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    -- people without an account are invited by their email until they sign up
+    CHECK ((invitee_id IS NULL) <> (email IS NULL)),
+    UNIQUE (group_id, invitee_id),
+    UNIQUE (group_id, email)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS group_invitations;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the member the owner offered the group to, it changes hands once they accept
+ALTER TABLE groups ADD COLUMN IF NOT EXISTS pending_owner_id INT REFERENCES accounts(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE groups DROP COLUMN IF EXISTS pending_owner_id;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS group_member_events(
+    id SERIAL PRIMARY KEY,
+    group_id INT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    actor_id INT REFERENCES accounts(id) ON DELETE SET NULL,
+    account_id INT REFERENCES accounts(id) ON DELETE SET NULL,
+    -- no previous role means the account joined and no role means it left
+    previous_role VARCHAR(10) CHECK (previous_role IN ('owner', 'admin', 'editor', 'viewer')),
+    role VARCHAR(10) CHECK (role IN ('owner', 'admin', 'editor', 'viewer')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    CHECK (previous_role IS NOT NULL OR role IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS group_member_events_group_id_idx ON group_member_events(group_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS group_member_events;
+-- +goose StatementEnd
@@ -16,9 +16,20 @@ import (
 var dbPool *pgxpool.Pool
 
 func initDB(ctx context.Context, dsn string) error {
+	pool, err := Open(ctx, dsn)
+	if err != nil {
+		return err
+	}
+
+	dbPool = pool
+	return nil
+}
+
+// Open creates a pool of its own, GetDb shares one pool with the whole app.
+func Open(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
 	config, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
-		return fmt.Errorf("unable to parse database config: %w", err)
+		return nil, fmt.Errorf("unable to parse database config: %w", err)
 	}
 
 	config.MaxConns = 10
@@ -27,11 +38,10 @@ func initDB(ctx context.Context, dsn string) error {
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
-		return fmt.Errorf("unable to create connection pool: %w", err)
+		return nil, fmt.Errorf("unable to create connection pool: %w", err)
 	}
 
-	dbPool = pool
-	return nil
+	return pool, nil
 }
 
 func GetDb(ctx context.Context) *pgxpool.Pool {
@@ -48,18 +58,52 @@ func GetDb(ctx context.Context) *pgxpool.Pool {
 //go:embed migrations/*.sql
 var embedMigrations embed.FS
 
-func migrate(db *pgxpool.Pool) error {
-	goose.SetBaseFS(embedMigrations)
-
-	if err := goose.SetDialect("postgres"); err != nil {
+// Migrate applies the migrations embedded in the binary that the database is missing.
+func Migrate(db *pgxpool.Pool) error {
+	if err := setupGoose(); err != nil {
 		return err
 	}
 
-	if err := goose.UpPGX(db, "migrations"); err != nil {
+	return goose.UpPGX(db, "migrations")
+}
+
+// MigrateDown rolls the last applied migration back.
+func MigrateDown(db *pgxpool.Pool) error {
+	if err := setupGoose(); err != nil {
 		return err
 	}
 
-	return nil
+	return goose.DownPGX(db, "migrations")
+}
+
+// Version is the version of the last applied migration and of the last migration
+// embedded in the binary, the database is up to date when they are equal.
+func Version(ctx context.Context, db *pgxpool.Pool) (int64, int64, error) {
+	if err := setupGoose(); err != nil {
+		return 0, 0, err
+	}
+
+	current, err := goose.GetDBVersionContextPGX(ctx, db)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	migrations, err := goose.CollectMigrations("migrations", 0, goose.MaxVersion)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	last, err := migrations.Last()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return current, last.Version, nil
+}
+
+func setupGoose() error {
+	goose.SetBaseFS(embedMigrations)
+	return goose.SetDialect("postgres")
 }
 
 func SetupTestDB(ctx context.Context) (string, *pgxpool.Pool) {
@@ -79,7 +123,7 @@ func SetupTestDB(ctx context.Context) (string, *pgxpool.Pool) {
 		panic("error getting db")
 	}
 
-	err = migrate(db)
+	err = Migrate(db)
 	if err != nil {
 		panic("error getting db")
 	}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier runs statements, both *pgxpool.Pool and pgx.Tx are one so repositories can
+// be built on either.
+type Querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// TxManager runs the calls of several repositories as one unit of work.
+type TxManager interface {
+	// Do runs fn in a transaction that commits when fn returns nil and rolls back
+	// otherwise. The repositories fn calls with the context it is given run their
+	// statements in the transaction, a Do inside fn runs in a savepoint of it.
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txKey struct{}
+
+type txManager struct {
+	db Querier
+}
+
+func NewTxManager(db Querier) TxManager {
+	return txManager{db: Join(db)}
+}
+
+func (m txManager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return pgx.BeginFunc(ctx, m.db, func(tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// Join returns a querier that runs the statements in the transaction a TxManager put in
+// the context and on db when there is none, repositories wrap the querier they are
+// given with it.
+func Join(db Querier) Querier {
+	if joined, ok := db.(joinedQuerier); ok {
+		return joined
+	}
+
+	return joinedQuerier{db: db}
+}
+
+type joinedQuerier struct {
+	db Querier
+}
+
+func (q joinedQuerier) conn(ctx context.Context) Querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return q.db
+}
+
+func (q joinedQuerier) Begin(ctx context.Context) (pgx.Tx, error) {
+	return q.conn(ctx).Begin(ctx)
+}
+
+func (q joinedQuerier) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return q.conn(ctx).Exec(ctx, sql, arguments...)
+}
+
+func (q joinedQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return q.conn(ctx).Query(ctx, sql, args...)
+}
+
+func (q joinedQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return q.conn(ctx).QueryRow(ctx, sql, args...)
+}
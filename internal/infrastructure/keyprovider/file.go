@@ -0,0 +1,31 @@
+package keyprovider
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+type fileProvider struct {
+	paths  map[string]string
+	inline map[string][]byte
+}
+
+// NewFileProvider reads every secret from its own file on each call. The inline
+// secrets, like the AES key of the environment, are returned as they are.
+func NewFileProvider(paths map[string]string, inline map[string][]byte) KeyProvider {
+	return fileProvider{paths: paths, inline: inline}
+}
+
+func (p fileProvider) Key(_ context.Context, name string) ([]byte, error) {
+	if key, ok := p.inline[name]; ok {
+		return key, nil
+	}
+
+	path, ok := p.paths[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, name)
+	}
+
+	return os.ReadFile(path)
+}
@@ -0,0 +1,151 @@
+package keyprovider
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const keyfileVersion = 1
+
+// The argon2id parameters new keyfiles are sealed with, the file keeps its own so they
+// can be raised later.
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+)
+
+var ErrWrongPassphrase = errors.New("the passphrase does not unlock the keyfile")
+
+// keyfile is an envelope: the secrets are encrypted under a random data key, and only
+// the data key is encrypted under the key derived from the passphrase.
+type keyfile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Time       uint32 `json:"time"`
+	Memory     uint32 `json:"memory"`
+	Threads    uint8  `json:"threads"`
+	WrappedKey []byte `json:"wrapped_key"`
+	Secrets    []byte `json:"secrets"`
+}
+
+// SealKeyfile writes the secrets to a new keyfile at path, it never replaces a file.
+func SealKeyfile(path, passphrase string, secrets map[string][]byte) error {
+	if passphrase == "" {
+		return errors.New("the keyfile needs a passphrase")
+	}
+
+	salt := make([]byte, 16)
+	dataKey := make([]byte, 32)
+	for _, b := range [][]byte{salt, dataKey} {
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+	}
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	kf := keyfile{Version: keyfileVersion, Salt: salt, Time: argonTime, Memory: argonMemory, Threads: argonThreads}
+
+	kf.WrappedKey, err = seal(kf.passphraseKey(passphrase), dataKey, "data key")
+	if err != nil {
+		return err
+	}
+
+	kf.Secrets, err = seal(dataKey, plaintext, "secrets")
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeNewFile(path, content)
+}
+
+// OpenKeyfile unlocks the keyfile at path and keeps its secrets in memory.
+func OpenKeyfile(path, passphrase string) (KeyProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var kf keyfile
+	if err := json.Unmarshal(content, &kf); err != nil {
+		return nil, fmt.Errorf("keyfile %s: %w", path, err)
+	}
+	if kf.Version != keyfileVersion {
+		return nil, fmt.Errorf("keyfile %s has the unknown version %d", path, kf.Version)
+	}
+
+	dataKey, err := open(kf.passphraseKey(passphrase), kf.WrappedKey, "data key")
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	plaintext, err := open(dataKey, kf.Secrets, "secrets")
+	if err != nil {
+		return nil, fmt.Errorf("keyfile %s: %w", path, err)
+	}
+
+	secrets := map[string][]byte{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("keyfile %s: %w", path, err)
+	}
+
+	return memoryProvider(secrets), nil
+}
+
+func (kf keyfile) passphraseKey(passphrase string) []byte {
+	return argon2.IDKey([]byte(passphrase), kf.Salt, kf.Time, kf.Memory, kf.Threads, 32)
+}
+
+// seal encrypts with AES-GCM, the label is the additional data so the data key and
+// the secrets can not be swapped.
+func seal(key, plaintext []byte, label string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, []byte(label)), nil
+}
+
+func open(key, sealed []byte, label string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(label))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,50 @@
+package keyprovider_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/keyprovider"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyfile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "keys.json")
+
+	secrets := map[string][]byte{
+		keyprovider.OprfSeed: []byte("seed"),
+		keyprovider.AESKey:   []byte("aes"),
+	}
+	require.NoError(t, keyprovider.SealKeyfile(path, "correct horse", secrets))
+
+	// an existing keyfile is never replaced
+	require.Error(t, keyprovider.SealKeyfile(path, "correct horse", secrets))
+
+	_, err := keyprovider.OpenKeyfile(path, "wrong")
+	require.ErrorIs(t, err, keyprovider.ErrWrongPassphrase)
+
+	provider, err := keyprovider.OpenKeyfile(path, "correct horse")
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name     string
+		expected []byte
+		err      error
+	}{
+		{name: keyprovider.OprfSeed, expected: []byte("seed")},
+		{name: keyprovider.AESKey, expected: []byte("aes")},
+		{name: keyprovider.ServerPrivateKey, err: keyprovider.ErrKeyNotFound},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			key, err := provider.Key(ctx, tc.name)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.expected, key)
+		})
+	}
+}
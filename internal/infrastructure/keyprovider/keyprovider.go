@@ -0,0 +1,85 @@
+package keyprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	ProviderFile    = "file"
+	ProviderKeyfile = "keyfile"
+	ProviderTransit = "transit"
+)
+
+// The names of the server secrets.
+const (
+	OprfSeed         = "oprf_seed"
+	ServerPrivateKey = "server_private_key"
+	ServerPublicKey  = "server_public_key"
+	AESKey           = "aes_key"
+)
+
+// Names lists every secret the server needs when the AKE keys are at keyVersion, the
+// key pairs of the older versions keep the accounts registered under them logging in.
+func Names(keyVersion int) []string {
+	names := []string{OprfSeed}
+	for version := 1; version <= keyVersion; version++ {
+		names = append(names, ServerPrivateKeyName(version), ServerPublicKeyName(version))
+	}
+
+	return append(names, AESKey)
+}
+
+// ServerPrivateKeyName and ServerPublicKeyName name the AKE key pair of a version, the
+// pair of version 1 keeps the names it had before the versions.
+func ServerPrivateKeyName(version int) string {
+	if version <= 1 {
+		return ServerPrivateKey
+	}
+	return fmt.Sprintf("%s_v%d", ServerPrivateKey, version)
+}
+
+func ServerPublicKeyName(version int) string {
+	if version <= 1 {
+		return ServerPublicKey
+	}
+	return fmt.Sprintf("%s_v%d", ServerPublicKey, version)
+}
+
+var ErrKeyNotFound = errors.New("key not found")
+
+// KeyProvider hands out the secrets of the server by name. The providers that need
+// to unlock their secrets do it once when they are created, so Key is cheap enough to
+// call on every request.
+type KeyProvider interface {
+	Key(ctx context.Context, name string) ([]byte, error)
+}
+
+// memoryProvider serves secrets that were unlocked already.
+type memoryProvider map[string][]byte
+
+func (p memoryProvider) Key(_ context.Context, name string) ([]byte, error) {
+	key, ok := p[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, name)
+	}
+
+	return key, nil
+}
+
+// writeNewFile writes content readable only by the owner, and fails rather than
+// replace a file that may hold the only copy of some keys.
+func writeNewFile(path string, content []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,146 @@
+package keyprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultTransitMount = "transit"
+
+// TransitClient speaks the encrypt and decrypt endpoints of the transit engine of
+// HashiCorp Vault. The server only stores the ciphertexts of its secrets, the key that
+// decrypts them never leaves Vault.
+type TransitClient struct {
+	address string
+	token   string
+	mount   string
+	key     string
+	http    *http.Client
+}
+
+func NewTransitClient(address, token, mount, key string) TransitClient {
+	if mount == "" {
+		mount = defaultTransitMount
+	}
+
+	return TransitClient{
+		address: strings.TrimRight(address, "/"),
+		token:   token,
+		mount:   strings.Trim(mount, "/"),
+		key:     key,
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c TransitClient) Encrypt(ctx context.Context, plaintext []byte) (string, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+
+	body := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(plaintext)}
+	if err := c.post(ctx, "encrypt", body, &resp); err != nil {
+		return "", err
+	}
+
+	return resp.Data.Ciphertext, nil
+}
+
+func (c TransitClient) Decrypt(ctx context.Context, ciphertext string) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+
+	if err := c.post(ctx, "decrypt", map[string]string{"ciphertext": ciphertext}, &resp); err != nil {
+		return nil, err
+	}
+
+	return base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+}
+
+func (c TransitClient) post(ctx context.Context, operation string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/%s/%s/%s", c.address, c.mount, operation, url.PathEscape(c.key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&failure)
+		return fmt.Errorf("transit %s: %s: %s", operation, resp.Status, strings.Join(failure.Errors, "; "))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// SealTransitFile encrypts the secrets with transit and writes their ciphertexts to a
+// new file at path, it never replaces a file.
+func SealTransitFile(ctx context.Context, client TransitClient, path string, secrets map[string][]byte) error {
+	wrapped := map[string]string{}
+	for name, secret := range secrets {
+		ciphertext, err := client.Encrypt(ctx, secret)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		wrapped[name] = ciphertext
+	}
+
+	content, err := json.MarshalIndent(wrapped, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeNewFile(path, content)
+}
+
+// OpenTransitFile decrypts every ciphertext of the file at path with transit once, and
+// keeps the secrets in memory.
+func OpenTransitFile(ctx context.Context, client TransitClient, path string) (KeyProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped := map[string]string{}
+	if err := json.Unmarshal(content, &wrapped); err != nil {
+		return nil, fmt.Errorf("transit file %s: %w", path, err)
+	}
+
+	secrets := map[string][]byte{}
+	for name, ciphertext := range wrapped {
+		secret, err := client.Decrypt(ctx, ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		secrets[name] = secret
+	}
+
+	return memoryProvider(secrets), nil
+}
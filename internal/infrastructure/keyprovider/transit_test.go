@@ -0,0 +1,96 @@
+package keyprovider_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/keyprovider"
+	"github.com/stretchr/testify/require"
+)
+
+// transitStub answers like the transit engine, its "encryption" only reverses the
+// base64 plaintext so the test can tell the ciphertexts apart.
+func transitStub(t *testing.T, token string) *httptest.Server {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+			return
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var data map[string]string
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/cpm":
+			data = map[string]string{"ciphertext": "vault:v1:" + reverse(body["plaintext"])}
+		case "/v1/transit/decrypt/cpm":
+			data = map[string]string{"plaintext": reverse(strings.TrimPrefix(body["ciphertext"], "vault:v1:"))}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+func TestTransitClient(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server := transitStub(t, "token")
+
+	client := keyprovider.NewTransitClient(server.URL, "token", "", "cpm")
+	ciphertext, err := client.Encrypt(ctx, []byte("secret"))
+	require.NoError(t, err)
+	require.Equal(t, "vault:v1:"+reverse(base64.StdEncoding.EncodeToString([]byte("secret"))), ciphertext)
+
+	plaintext, err := client.Decrypt(ctx, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret"), plaintext)
+
+	_, err = keyprovider.NewTransitClient(server.URL, "wrong", "", "cpm").Decrypt(ctx, ciphertext)
+	require.ErrorContains(t, err, "permission denied")
+}
+
+func TestTransitFile(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client := keyprovider.NewTransitClient(transitStub(t, "token").URL, "token", "transit", "cpm")
+	path := filepath.Join(t.TempDir(), "keys.json")
+
+	err := keyprovider.SealTransitFile(ctx, client, path, map[string][]byte{keyprovider.ServerPublicKey: []byte("public")})
+	require.NoError(t, err)
+
+	provider, err := keyprovider.OpenTransitFile(ctx, client, path)
+	require.NoError(t, err)
+
+	key, err := provider.Key(ctx, keyprovider.ServerPublicKey)
+	require.NoError(t, err)
+	require.Equal(t, []byte("public"), key)
+
+	_, err = provider.Key(ctx, keyprovider.AESKey)
+	require.ErrorIs(t, err, keyprovider.ErrKeyNotFound)
+}
@@ -0,0 +1,46 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+)
+
+type fileMailer struct {
+	from      string
+	directory string
+}
+
+// NewFileMailer writes every message as an .eml file into the configured directory,
+// which is handy for local development.
+func NewFileMailer(config config.Mail) (Mailer, error) {
+	if err := os.MkdirAll(config.Directory, 0o755); err != nil {
+		return nil, err
+	}
+
+	return fileMailer{from: config.From, directory: config.Directory}, nil
+}
+
+func (m fileMailer) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	recipient := strings.NewReplacer("@", "_at_", "/", "_").Replace(strings.Join(message.To, "_"))
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), recipient)
+
+	err := os.WriteFile(filepath.Join(m.directory, name), format(m.from, message, now), 0o600)
+	if err != nil {
+		log.ErrorLogger.Error("error at writing mail", "error", err.Error(), "subject", message.Subject)
+		return err
+	}
+
+	return nil
+}
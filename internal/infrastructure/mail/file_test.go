@@ -0,0 +1,78 @@
+package mail_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMailer_Headers(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	testcases := []struct {
+		name    string
+		subject string
+		header  string
+	}{
+		{
+			name:    "plain subject",
+			subject: "John invited you to Friends",
+			header:  "Subject: John invited you to Friends",
+		},
+		{
+			name:    "subject with injected headers",
+			subject: "John invited you to Friends\r\nBcc: victim@example.com\r\n\r\nforged body",
+			header:  "Subject: John invited you to Friends Bcc: victim@example.com forged body",
+		},
+		{
+			name:    "subject with bare line feeds",
+			subject: "Friends\nTo: victim@example.com",
+			header:  "Subject: Friends To: victim@example.com",
+		},
+		{
+			name:    "non ascii subject",
+			subject: "Café\r\nBcc: victim@example.com",
+			header:  "Subject: =?UTF-8?q?Caf=C3=A9_Bcc:_victim@example.com?=",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			directory := t.TempDir()
+			mailer, err := mail.NewFileMailer(config.Mail{From: "noreply@example.com", Directory: directory})
+			require.NoError(t, err)
+
+			message := mail.Message{To: []string{"john@example.com"}, Subject: tc.subject, Body: "Hi John,\n"}
+			require.NoError(t, mailer.Send(ctx, message))
+
+			files, err := os.ReadDir(directory)
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+
+			content, err := os.ReadFile(filepath.Join(directory, files[0].Name()))
+			require.NoError(t, err)
+
+			headers, body, found := strings.Cut(string(content), "\r\n\r\n")
+			require.True(t, found)
+			require.Equal(t, "Hi John,\r\n", body)
+
+			lines := strings.Split(headers, "\r\n")
+			require.Len(t, lines, 6)
+			require.Equal(t, tc.header, lines[2])
+			for _, line := range lines {
+				require.NotContains(t, line, "\n")
+				require.NotContains(t, line, "\r")
+				require.False(t, strings.HasPrefix(line, "Bcc:"))
+			}
+		})
+	}
+}
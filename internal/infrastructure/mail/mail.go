@@ -0,0 +1,65 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+)
+
+const (
+	DriverSMTP   = "smtp"
+	DriverFile   = "file"
+	DriverMemory = "memory"
+)
+
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// New returns the mailer configured by conf.Mail.Driver.
+func New(conf *config.Config) (Mailer, error) {
+	switch conf.Mail.Driver {
+	case DriverSMTP:
+		return NewSMTPMailer(conf.Mail), nil
+	case DriverFile:
+		return NewFileMailer(conf.Mail)
+	case DriverMemory:
+		return NewMemoryMailer(), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", conf.Mail.Driver)
+	}
+}
+
+// format builds a plain text RFC 5322 message. Line breaks are removed from the header
+// values and the subject is Q-encoded when it is not plain ASCII, so user supplied text
+// in a subject cannot add headers or start the body.
+func format(from string, message Message, date time.Time) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", headerValue(from))
+	fmt.Fprintf(&buf, "To: %s\r\n", headerValue(strings.Join(message.To, ", ")))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", headerValue(message.Subject)))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	return buf.Bytes()
+}
+
+// headerValue puts the value on a single line.
+func headerValue(value string) string {
+	return strings.Join(strings.FieldsFunc(value, func(r rune) bool { return r == '\r' || r == '\n' }), " ")
+}
@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// MemoryMailer keeps the sent messages, so tests can look at them.
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// Messages returns the messages sent to the recipient, oldest first.
+func (m *MemoryMailer) Messages(recipient string) []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var messages []Message
+	for _, message := range m.messages {
+		if slices.Contains(message.To, recipient) {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
@@ -0,0 +1,41 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+)
+
+type smtpMailer struct {
+	config config.Mail
+}
+
+// NewSMTPMailer sends through an SMTP relay. STARTTLS is used when the server offers
+// it, and the credentials are only sent when a username is configured.
+func NewSMTPMailer(config config.Mail) Mailer {
+	return smtpMailer{config: config}
+}
+
+func (m smtpMailer) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", m.config.SMTPUsername, m.config.SMTPPassword, m.config.SMTPHost)
+	}
+
+	addr := net.JoinHostPort(m.config.SMTPHost, m.config.SMTPPort)
+	err := smtp.SendMail(addr, auth, m.config.From, message.To, format(m.config.From, message, time.Now()))
+	if err != nil {
+		log.ErrorLogger.Error("error at sending mail", "error", err.Error(), "subject", message.Subject)
+		return err
+	}
+
+	return nil
+}
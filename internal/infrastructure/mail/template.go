@@ -0,0 +1,57 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"strings"
+	"text/template"
+)
+
+const (
+	TemplateEmailVerification    = "email_verification"
+	TemplateNewLogin             = "new_login"
+	TemplatePasswordChanged      = "password_changed"
+	TemplateAuthenticatorChanged = "authenticator_changed"
+	TemplateAccountRecovered     = "account_recovered"
+
+	TemplateEmailChangeVerification = "email_change_verification"
+	TemplateEmailChanged            = "email_changed"
+	TemplateUsernameChanged         = "username_changed"
+	TemplateAccountDeleted          = "account_deleted"
+
+	TemplateEmergencyAccessInvited   = "emergency_access_invited"
+	TemplateEmergencyAccessAccepted  = "emergency_access_accepted"
+	TemplateEmergencyAccessRequested = "emergency_access_requested"
+	TemplateEmergencyAccessRejected  = "emergency_access_rejected"
+	TemplateEmergencyAccessApproved  = "emergency_access_approved"
+
+	TemplateGroupInvited        = "group_invited"
+	TemplateGroupInvitedByEmail = "group_invited_by_email"
+	TemplateGroupOwnershipOffer = "group_ownership_offer"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// NewMessage renders the named template. Every template defines a "<name>_subject"
+// and a "<name>_body" block. The subject is put on a single line, since the data it
+// interpolates, like group names, comes from users.
+func NewMessage(to, name string, data any) (Message, error) {
+	var subject, body bytes.Buffer
+
+	if err := templates.ExecuteTemplate(&subject, name+"_subject", data); err != nil {
+		return Message{}, err
+	}
+
+	if err := templates.ExecuteTemplate(&body, name+"_body", data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      []string{to},
+		Subject: headerValue(strings.TrimSpace(subject.String())),
+		Body:    strings.TrimSpace(body.String()) + "\n",
+	}, nil
+}
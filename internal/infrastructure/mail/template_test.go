@@ -0,0 +1,44 @@
+package mail_test
+
+import (
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/mail"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMessage(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		template string
+		group    string
+		subject  string
+	}{
+		{
+			name:     "ownership offer",
+			template: mail.TemplateGroupOwnershipOffer,
+			group:    "Friends",
+			subject:  "john wants to hand Friends over to you",
+		},
+		{
+			name:     "ownership offer with injected headers",
+			template: mail.TemplateGroupOwnershipOffer,
+			group:    "Friends\r\nBcc: victim@example.com",
+			subject:  "john wants to hand Friends Bcc: victim@example.com over to you",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			data := map[string]any{"Username": "jane", "Other": "john", "Group": tc.group}
+			message, err := mail.NewMessage("jane@example.com", tc.template, data)
+			require.NoError(t, err)
+			require.Equal(t, []string{"jane@example.com"}, message.To)
+			require.Equal(t, tc.subject, message.Subject)
+		})
+	}
+}
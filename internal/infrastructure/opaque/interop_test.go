@@ -0,0 +1,284 @@
+package opaque_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/oprfutils"
+	bytemareOpaque "github.com/bytemare/opaque"
+	"github.com/stretchr/testify/require"
+)
+
+// The vectors are recorded by running the client of the frontend against the server,
+// which needs node and the packages of the frontend:
+//
+//	(cd frontend && npm ci) && go test ./internal/infrastructure/opaque -run Interop -update
+var update = flag.Bool("update", false, "record the interoperability vectors again with the JS client")
+
+const (
+	interopVectors = "testdata/interop.json"
+	interopClient  = "../../../frontend/test/opaqueinterop.mjs"
+)
+
+// interopVector is a registration and a login of the JS client along with an output of
+// its key stretching function. The server keys and the randomness of the server login
+// are pinned, so the server answers the recorded messages of the client with the
+// recorded messages again.
+type interopVector struct {
+	Name     string          `json:"name"`
+	Suite    oprfutils.Suite `json:"suite"`
+	ServerID string          `json:"serverID"`
+	Username string          `json:"username"`
+	Password string          `json:"password"`
+
+	// CredentialID is empty in the vectors recorded before the accounts kept one,
+	// which still log in the way those accounts do.
+	CredentialID       []byte `json:"credentialID,omitempty"`
+	OprfSeed           []byte `json:"oprfSeed"`
+	PrivateKey         []byte `json:"privateKey"`
+	PublicKey          []byte `json:"publicKey"`
+	MaskingNonce       []byte `json:"maskingNonce"`
+	ServerNonce        []byte `json:"serverNonce"`
+	ServerEphemeralKey []byte `json:"serverEphemeralKey"`
+
+	RegistrationRequest  []byte `json:"registrationRequest"`
+	RegistrationResponse []byte `json:"registrationResponse"`
+	Record               []byte `json:"record"`
+	KE1                  []byte `json:"ke1"`
+	KE2                  []byte `json:"ke2"`
+	KE3                  []byte `json:"ke3"`
+	SessionKey           []byte `json:"sessionKey"`
+
+	StretchInput []byte `json:"stretchInput"`
+	Stretched    []byte `json:"stretched"`
+}
+
+// interopSuites are the suites the vectors are recorded in, the first one is the
+// default of the config.
+var interopSuites = []struct {
+	name  string
+	suite oprfutils.Suite
+}{
+	{name: "default", suite: oprfutils.DefaultSuite()},
+	{name: "p256 scrypt", suite: oprfutils.Suite{Name: oprfutils.SuiteP256, KSF: oprfutils.KSF{
+		Name: oprfutils.KSFScrypt, ScryptN: 1024, ScryptR: 8, ScryptP: 1,
+	}}},
+	{name: "p384 argon2id", suite: oprfutils.Suite{Name: oprfutils.SuiteP384, KSF: oprfutils.KSF{
+		Name: oprfutils.KSFArgon2id, Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 2,
+	}}},
+	{name: "p521 identity", suite: oprfutils.Suite{Name: oprfutils.SuiteP521, KSF: oprfutils.KSF{
+		Name: oprfutils.KSFIdentity,
+	}}},
+}
+
+func TestOpaqueAdaptor_Interop(t *testing.T) {
+	if *update {
+		recordInteropVectors(t)
+	}
+
+	content, err := os.ReadFile(interopVectors)
+	require.NoError(t, err)
+
+	var vectors []interopVector
+	require.NoError(t, json.Unmarshal(content, &vectors))
+	require.Len(t, vectors, len(interopSuites))
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			t.Parallel()
+
+			server := interopServer(t, v)
+
+			// the server answers the registration of the browser and takes its record
+			response, registrationID, err := server.RegisterInit(v.RegistrationRequest, v.CredentialID)
+			require.NoError(t, err)
+			require.Equal(t, v.RegistrationResponse, response)
+
+			_, credentialID, _, err := server.RegisterFinalize(v.Record, registrationID, v.Username)
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(v.CredentialID, credentialID))
+
+			// the login of the browser checks out against the server
+			ke2, sessionKey := interopLogin(t, v)
+			require.Equal(t, v.KE2, ke2)
+			require.Equal(t, v.SessionKey, sessionKey)
+
+			// and the browser stretches like cpm does, the output is as long as an element
+			// of the group, which the public key is one of
+			require.Equal(t, v.Stretched, v.Suite.KSF.Harden(v.StretchInput, nil, len(v.PublicKey)))
+		})
+	}
+}
+
+// interopServer runs the adaptor on the keys of the vector.
+func interopServer(t *testing.T, v interopVector) opaque.OpaqueService {
+	dir := t.TempDir()
+	conf := newConfig(dir, 1)
+	conf.Opaque.ServerID = v.ServerID
+	conf.Opaque.Suite = v.Suite.Name
+	conf.Opaque.KSF.Name = v.Suite.KSF.Name
+
+	for path, key := range map[string][]byte{
+		conf.Opaque.OprfKeyPath:    v.OprfSeed,
+		conf.Opaque.PrivateKeyPath: v.PrivateKey,
+		conf.Opaque.PublicKeyPath:  v.PublicKey,
+	} {
+		require.NoError(t, os.WriteFile(path, key, 0600))
+	}
+
+	server, err := opaque.New(conf)
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// interopLogin answers the KE1 of the vector with the pinned randomness of the server
+// and checks its KE3, the adaptor draws its own randomness.
+func interopLogin(t *testing.T, v interopVector) ([]byte, []byte) {
+	conf, err := v.Suite.Configuration()
+	require.NoError(t, err)
+
+	server, err := conf.Server()
+	require.NoError(t, err)
+	require.NoError(t, server.SetKeyMaterial([]byte(v.ServerID), v.PrivateKey, v.PublicKey, v.OprfSeed))
+
+	ke1, err := server.Deserialize.KE1(v.KE1)
+	require.NoError(t, err)
+
+	record, err := server.Deserialize.RegistrationRecord(v.Record)
+	require.NoError(t, err)
+
+	ephemeralKey, err := server.Deserialize.DecodeAkePrivateKey(v.ServerEphemeralKey)
+	require.NoError(t, err)
+
+	ke2, err := server.LoginInit(ke1, &bytemareOpaque.ClientRecord{
+		CredentialIdentifier: v.CredentialID,
+		RegistrationRecord:   record,
+		ClientIdentity:       []byte(v.Username),
+		TestMaskNonce:        v.MaskingNonce,
+	}, bytemareOpaque.ServerLoginInitOptions{EphemeralSecretKey: ephemeralKey, Nonce: v.ServerNonce})
+	require.NoError(t, err)
+
+	if v.KE3 == nil {
+		return ke2.Serialize(), nil
+	}
+
+	ke3, err := server.Deserialize.KE3(v.KE3)
+	require.NoError(t, err)
+	require.NoError(t, server.LoginFinish(ke3))
+
+	return ke2.Serialize(), server.SessionKey()
+}
+
+// recordInteropVectors runs the JS client through a registration and a login in every
+// suite and writes the vectors.
+func recordInteropVectors(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Fatal("recording the vectors needs node")
+	}
+	if _, err := os.Stat("../../../frontend/node_modules"); err != nil {
+		t.Fatal("recording the vectors needs the packages of the frontend, run npm ci in frontend")
+	}
+
+	cmd := exec.Command("node", "--no-warnings", interopClient)
+	stdin, err := cmd.StdinPipe()
+	require.NoError(t, err)
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	cmd.Stderr = os.Stderr
+	require.NoError(t, cmd.Start())
+	defer func() {
+		stdin.Close()
+		require.NoError(t, cmd.Wait())
+	}()
+
+	lines := bufio.NewScanner(stdout)
+	step := func(request map[string]any, response any) {
+		line, err := json.Marshal(request)
+		require.NoError(t, err)
+		_, err = stdin.Write(append(line, '\n'))
+		require.NoError(t, err)
+
+		require.True(t, lines.Scan(), "the JS client stopped")
+		var failure struct {
+			Error string `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &failure))
+		require.Empty(t, failure.Error)
+		require.NoError(t, json.Unmarshal(lines.Bytes(), response))
+	}
+
+	vectors := make([]interopVector, 0, len(interopSuites))
+	for _, s := range interopSuites {
+		conf, err := s.suite.Configuration()
+		require.NoError(t, err)
+
+		privateKey, publicKey := conf.KeyGen()
+		ephemeralKey, _ := conf.KeyGen()
+		v := interopVector{
+			Name:               s.name,
+			Suite:              s.suite,
+			ServerID:           serverID,
+			Username:           "interop_account",
+			Password:           "correct horse battery staple",
+			CredentialID:       opaque.NewCredentialID(),
+			OprfSeed:           conf.GenerateOPRFSeed(),
+			PrivateKey:         privateKey,
+			PublicKey:          publicKey,
+			MaskingNonce:       bytemareOpaque.RandomBytes(32),
+			ServerNonce:        bytemareOpaque.RandomBytes(32),
+			ServerEphemeralKey: ephemeralKey,
+		}
+		server := interopServer(t, v)
+
+		var registration struct {
+			RegistrationRequest []byte `json:"registrationRequest"`
+			Record              []byte `json:"record"`
+		}
+		step(map[string]any{
+			"step": "registerInit", "suite": s.suite.Name, "ksf": s.suite.KSF,
+			"serverID": v.ServerID, "username": v.Username, "password": v.Password,
+		}, &registration)
+		v.RegistrationRequest = registration.RegistrationRequest
+
+		v.RegistrationResponse, _, err = server.RegisterInit(v.RegistrationRequest, v.CredentialID)
+		require.NoError(t, err)
+
+		step(map[string]any{"step": "registerFinish", "registrationResponse": v.RegistrationResponse}, &registration)
+		v.Record = registration.Record
+
+		var login struct {
+			KE1        []byte `json:"ke1"`
+			KE3        []byte `json:"ke3"`
+			SessionKey []byte `json:"sessionKey"`
+		}
+		step(map[string]any{"step": "loginInit"}, &login)
+		v.KE1 = login.KE1
+		v.KE2, _ = interopLogin(t, v)
+
+		step(map[string]any{"step": "loginFinish", "ke2": v.KE2}, &login)
+		v.KE3, v.SessionKey = login.KE3, login.SessionKey
+
+		var stretch struct {
+			Output []byte `json:"output"`
+		}
+		v.StretchInput = bytemareOpaque.RandomBytes(conf.Hash.Size())
+		step(map[string]any{"step": "harden", "input": v.StretchInput}, &stretch)
+		v.Stretched = stretch.Output
+
+		vectors = append(vectors, v)
+	}
+
+	content, err := json.MarshalIndent(vectors, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(interopVectors), 0755))
+	require.NoError(t, os.WriteFile(interopVectors, append(content, '\n'), 0644))
+}
@@ -0,0 +1,97 @@
+package opaque
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/keyprovider"
+	"github.com/bytemare/opaque"
+)
+
+// KeyStatus reports the key material the server holds. The fingerprints are taken
+// from the public keys only, so they can be compared between the replicas freely.
+type KeyStatus struct {
+	KeyVersion   int            `json:"keyVersion"`
+	Fingerprints map[int]string `json:"fingerprints"`
+	LoadedAt     time.Time      `json:"loadedAt"`
+}
+
+// keyMaterial is every secret the server needs, read once from the key provider. It
+// owns its slices, so they can be zeroed without touching the ones of the provider.
+type keyMaterial struct {
+	oprfSeed   []byte
+	keyVersion int
+	keys       map[int]keyPair
+	loadedAt   time.Time
+}
+
+// keyPair is the AKE key pair of one version of the server keys.
+type keyPair struct {
+	privateKey []byte
+	publicKey  []byte
+}
+
+// loadKeys reads the OPRF seed and the key pairs of every version up to keyVersion,
+// and checks each pair against the configuration before anything uses it.
+func loadKeys(ctx context.Context, provider keyprovider.KeyProvider, conf *opaque.Configuration,
+	serverID []byte, keyVersion int) (*keyMaterial, error) {
+	material := &keyMaterial{keyVersion: keyVersion, keys: map[int]keyPair{}, loadedAt: time.Now()}
+
+	oprfSeed, err := provider.Key(ctx, keyprovider.OprfSeed)
+	if err != nil {
+		return nil, fmt.Errorf("oprf seed: %w", err)
+	}
+	material.oprfSeed = bytes.Clone(oprfSeed)
+
+	for version := 1; version <= keyVersion; version++ {
+		privateKey, err := provider.Key(ctx, keyprovider.ServerPrivateKeyName(version))
+		if err != nil {
+			material.zero()
+			return nil, fmt.Errorf("private key of version %d: %w", version, err)
+		}
+
+		publicKey, err := provider.Key(ctx, keyprovider.ServerPublicKeyName(version))
+		if err != nil {
+			material.zero()
+			return nil, fmt.Errorf("public key of version %d: %w", version, err)
+		}
+
+		material.keys[version] = keyPair{privateKey: bytes.Clone(privateKey), publicKey: bytes.Clone(publicKey)}
+
+		server, err := conf.Server()
+		if err != nil {
+			material.zero()
+			return nil, err
+		}
+
+		if err := server.SetKeyMaterial(serverID, privateKey, publicKey, oprfSeed); err != nil {
+			material.zero()
+			return nil, fmt.Errorf("key material of version %d: %w", version, err)
+		}
+	}
+
+	return material, nil
+}
+
+func (m *keyMaterial) status() KeyStatus {
+	status := KeyStatus{KeyVersion: m.keyVersion, Fingerprints: map[int]string{}, LoadedAt: m.loadedAt}
+	for version, keys := range m.keys {
+		sum := sha256.Sum256(keys.publicKey)
+		status.Fingerprints[version] = hex.EncodeToString(sum[:8])
+	}
+
+	return status
+}
+
+// zero overwrites the secrets, the material can not be used afterwards.
+func (m *keyMaterial) zero() {
+	clear(m.oprfSeed)
+	for _, keys := range m.keys {
+		clear(keys.privateKey)
+		clear(keys.publicKey)
+	}
+}
@@ -2,8 +2,18 @@ package opaque
 
 type OpaqueService interface {
 	Init() error
-	RegisterInit(message []byte) (response []byte, credID []byte, err error)
-	RegisterFinalize(message, credID []byte, username string) ([]byte, error)
-	LoginInit(message, userRecord []byte, username string) ([]byte, error)
-	LoginFinalize(message []byte) ([]byte, error)
+	// RegisterInit evaluates the OPRF for the credential ID of the account, see
+	// NewCredentialID. The returned registration ID goes back to RegisterFinalize.
+	RegisterInit(message, credentialID []byte) (response []byte, registrationID []byte, err error)
+	// RegisterFinalize returns the record along with the credential ID and the version
+	// of the server keys it was registered under, which LoginInit needs back.
+	RegisterFinalize(message, registrationID []byte, username string) (record, credentialID []byte, keyVersion int, err error)
+	LoginInit(message, userRecord, credentialID []byte, username string, keyVersion int) (response []byte, state []byte, err error)
+	LoginFinalize(message, state []byte) ([]byte, error)
+	// KeyVersion is the version of the server keys new registrations use.
+	KeyVersion() int
+	// Reload reads the keys from the key provider again, Close zeroes them.
+	Reload() error
+	Close()
+	Status() (KeyStatus, error)
 }
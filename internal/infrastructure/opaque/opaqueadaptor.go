@@ -1,18 +1,42 @@
 package opaque
 
 import (
-	"crypto"
-	"os"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/TheAmirhosssein/cool-password-manage/config"
 	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
-	"github.com/bytemare/ksf"
 	"github.com/bytemare/opaque"
 )
 
 type opaqueAdaptor struct {
-	server *opaque.Server
 	config *config.Config
+
+	configuration *opaque.Configuration
+	serverID      []byte
+
+	// mu guards material, the requests hold it for reading while they use the keys so
+	// Reload and Close can zero the old ones once they took it for writing.
+	mu       sync.RWMutex
+	material *keyMaterial
+}
+
+// credIDVersionLength is the length of the key version prefixed to the registration
+// IDs, the IDs without it were handed out before the versions and belong to version 1.
+const credIDVersionLength = 4
+
+// credentialIDLength is the length of the credential IDs given to the accounts.
+const credentialIDLength = 64
+
+var errClosed = errors.New("the opaque key material was closed")
+
+// NewCredentialID returns a credential ID for an account that has none. The account
+// keeps it for good, the registrations after a password change reuse it.
+func NewCredentialID() []byte {
+	return opaque.RandomBytes(credentialIDLength)
 }
 
 func New(config *config.Config) (OpaqueService, error) {
@@ -21,147 +45,256 @@ func New(config *config.Config) (OpaqueService, error) {
 }
 
 func (o *opaqueAdaptor) Init() error {
-	serverID := []byte(o.config.Opaque.ServerID)
-
-	conf := &opaque.Configuration{
-		OPRF: opaque.P256Sha256,
-		AKE:  opaque.P256Sha256,
-
-		Hash: crypto.SHA256,
-		KDF:  crypto.SHA256,
-		MAC:  crypto.SHA256,
-
-		KSF: ksf.Argon2id,
+	o.serverID = []byte(o.config.Opaque.ServerID)
+	configuration, err := o.config.GetOpaqueSuite().Configuration()
+	if err != nil {
+		log.ErrorLogger.Error("error at reading opaque suite", "error", err.Error())
+		return err
 	}
+	o.configuration = configuration
 
-	secretOprfSeed, err := o.getOprfKey()
+	provider, err := o.config.GetKeyProvider()
 	if err != nil {
-		log.ErrorLogger.Error("error at getting oprf key", "error", err.Error())
+		log.ErrorLogger.Error("error at opening key provider", "error", err.Error())
 		return err
 	}
 
-	serverPrivateKey, err := o.getPrivateKey()
+	material, err := loadKeys(context.Background(), provider, o.configuration, o.serverID, o.config.GetOpaqueKeyVersion())
 	if err != nil {
-		log.ErrorLogger.Error("error at getting private key", "error", err.Error())
+		log.ErrorLogger.Error("error at loading opaque keys", "error", err.Error())
 		return err
 	}
 
-	serverPublicKey, err := o.getPublicKey()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.material = material
+	return nil
+}
+
+// Reload opens the key provider again and swaps in the keys it serves now. The keys in
+// use are kept when the new ones do not load.
+func (o *opaqueAdaptor) Reload() error {
+	provider, err := o.config.OpenKeyProvider()
 	if err != nil {
-		log.ErrorLogger.Error("error at getting public key", "error", err.Error())
+		log.ErrorLogger.Error("error at opening key provider", "error", err.Error())
 		return err
 	}
 
-	server, err := conf.Server()
+	material, err := loadKeys(context.Background(), provider, o.configuration, o.serverID, o.config.GetOpaqueKeyVersion())
 	if err != nil {
-		log.ErrorLogger.Error("error at starting opaque server", "error", err.Error())
+		log.ErrorLogger.Error("error at reloading opaque keys", "error", err.Error())
 		return err
 	}
 
-	if err := server.SetKeyMaterial(serverID, serverPrivateKey, serverPublicKey, secretOprfSeed); err != nil {
-		log.ErrorLogger.Error("error at setting key material", "error", err.Error())
-		return err
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.material == nil {
+		material.zero()
+		return errClosed
 	}
 
-	o.server = server
+	o.material.zero()
+	o.material = material
+	log.InfoLogger.Info("opaque keys reloaded", "key_version", material.keyVersion)
 	return nil
 }
 
-func (o *opaqueAdaptor) RegisterInit(message []byte) ([]byte, []byte, error) {
-	req, err := o.server.Deserialize.RegistrationRequest(message)
+// Close zeroes the keys, every call afterwards fails.
+func (o *opaqueAdaptor) Close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.material != nil {
+		o.material.zero()
+		o.material = nil
+	}
+}
+
+func (o *opaqueAdaptor) Status() (KeyStatus, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if o.material == nil {
+		return KeyStatus{}, errClosed
+	}
+	return o.material.status(), nil
+}
+
+func (o *opaqueAdaptor) KeyVersion() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if o.material == nil {
+		return 0
+	}
+	return o.material.keyVersion
+}
+
+// newServer returns a server with its own AKE state, so concurrent logins do not
+// overwrite each other, holding the key pair of the version. The caller holds mu.
+func (o *opaqueAdaptor) newServer(version int) (*opaque.Server, error) {
+	if o.material == nil {
+		log.ErrorLogger.Error("error at starting opaque server", "error", errClosed.Error())
+		return nil, errClosed
+	}
+
+	keys, ok := o.material.keys[version]
+	if !ok {
+		err := fmt.Errorf("unknown opaque key version %d", version)
+		log.ErrorLogger.Error("error at starting opaque server", "error", err.Error())
+		return nil, err
+	}
+
+	server, err := o.configuration.Server()
 	if err != nil {
-		log.ErrorLogger.Error("error at deserializing message", "error", err.Error())
-		return nil, nil, err
+		log.ErrorLogger.Error("error at starting opaque server", "error", err.Error())
+		return nil, err
+	}
+
+	if err := server.SetKeyMaterial(o.serverID, keys.privateKey, keys.publicKey, o.material.oprfSeed); err != nil {
+		log.ErrorLogger.Error("error at setting key material", "error", err.Error())
+		return nil, err
 	}
 
-	credID := opaque.RandomBytes(64)
+	return server, nil
+}
+
+// RegisterInit answers with the active key pair. The OPRF key is derived from the
+// credential ID of the account, so every account gets its own. The returned
+// registration ID is the credential ID prefixed with the key version, so
+// RegisterFinalize can tell which keys the record belongs to.
+func (o *opaqueAdaptor) RegisterInit(message, credentialID []byte) ([]byte, []byte, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if o.material == nil {
+		return nil, nil, errClosed
+	}
 
-	serverPublicKey, err := o.getPublicKey()
+	keyVersion := o.material.keyVersion
+	server, err := o.newServer(keyVersion)
 	if err != nil {
-		log.ErrorLogger.Error("error at getting public key", "error", err.Error())
 		return nil, nil, err
 	}
 
-	pks, err := o.server.Deserialize.DecodeAkePublicKey(serverPublicKey)
+	req, err := server.Deserialize.RegistrationRequest(message)
 	if err != nil {
-		log.ErrorLogger.Error("error at decoding ake public key", "error", err.Error())
+		log.ErrorLogger.Error("error at deserializing message", "error", err.Error())
 		return nil, nil, err
 	}
 
-	secretOprfKey, err := o.getOprfKey()
+	pks, err := server.Deserialize.DecodeAkePublicKey(o.material.keys[keyVersion].publicKey)
 	if err != nil {
-		log.ErrorLogger.Error("error at getting oprf key", "error", err.Error())
+		log.ErrorLogger.Error("error at decoding ake public key", "error", err.Error())
 		return nil, nil, err
 	}
 
-	resp := o.server.RegistrationResponse(req, pks, credID, secretOprfKey)
+	resp := server.RegistrationResponse(req, pks, credentialID, o.material.oprfSeed)
 
-	return resp.Serialize(), credID, nil
+	registrationID := binary.BigEndian.AppendUint32(nil, uint32(keyVersion))
+	return resp.Serialize(), append(registrationID, credentialID...), nil
 }
 
-func (o *opaqueAdaptor) RegisterFinalize(message, credID []byte, username string) ([]byte, error) {
-	record, err := o.server.Deserialize.RegistrationRecord(message)
+func (o *opaqueAdaptor) RegisterFinalize(message, registrationID []byte, username string) ([]byte, []byte, int, error) {
+	server, err := o.configuration.Server()
+	if err != nil {
+		log.ErrorLogger.Error("error at starting opaque server", "error", err.Error())
+		return nil, nil, 0, err
+	}
+
+	record, err := server.Deserialize.RegistrationRecord(message)
 	if err != nil {
 		log.ErrorLogger.Error("error at deserializing message", "error", err.Error())
-		return nil, err
+		return nil, nil, 0, err
 	}
 
+	credentialID, keyVersion := splitRegistrationID(registrationID)
 	clientRecord := &opaque.ClientRecord{
-		CredentialIdentifier: credID,           // used during serialization
-		ClientIdentity:       []byte(username), // used during serialization
+		CredentialIdentifier: credentialID,
+		ClientIdentity:       []byte(username),
 		RegistrationRecord:   record,
 	}
 
-	return clientRecord.Serialize(), nil
+	return clientRecord.Serialize(), credentialID, keyVersion, nil
+}
+
+// splitRegistrationID reads the credential ID and the key version RegisterInit put in
+// the registration ID. The IDs handed out before the versions are the credential ID
+// alone.
+func splitRegistrationID(registrationID []byte) ([]byte, int) {
+	if len(registrationID) < credIDVersionLength || len(registrationID) == credentialIDLength {
+		return registrationID, 1
+	}
+	return registrationID[credIDVersionLength:], int(binary.BigEndian.Uint32(registrationID[:credIDVersionLength]))
 }
 
-func (o *opaqueAdaptor) LoginInit(message, userRecord []byte, username string) ([]byte, error) {
-	ke1, err := o.server.Deserialize.KE1(message)
+// LoginInit returns the KE2 message and the serialized AKE state, which has to be
+// passed back to LoginFinalize along with the client's KE3 message. The credentialID
+// and keyVersion are the ones the record was registered under, the records registered
+// before the accounts kept a credential ID have none.
+func (o *opaqueAdaptor) LoginInit(message, userRecord, credentialID []byte, username string, keyVersion int) ([]byte, []byte, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	server, err := o.newServer(keyVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ke1, err := server.Deserialize.KE1(message)
 	if err != nil {
 		log.ErrorLogger.Error("error at deserializing ke1 login message", "error", err.Error())
-		return nil, err
+		return nil, nil, err
 	}
 
-	registrationRecord, err := o.server.Deserialize.RegistrationRecord(userRecord)
+	registrationRecord, err := server.Deserialize.RegistrationRecord(userRecord)
 	if err != nil {
 		log.ErrorLogger.Error("error at deserializing register record", "error", err.Error())
-		return nil, err
+		return nil, nil, err
 	}
 
-	ke2, err := o.server.LoginInit(ke1, &opaque.ClientRecord{
-		RegistrationRecord: registrationRecord, ClientIdentity: []byte(username),
+	ke2, err := server.LoginInit(ke1, &opaque.ClientRecord{
+		CredentialIdentifier: credentialID, ClientIdentity: []byte(username), RegistrationRecord: registrationRecord,
 	})
 	if err != nil {
 		log.ErrorLogger.Error("error at login initializing", "error", err.Error())
-		return nil, err
+		return nil, nil, err
 	}
 
-	return ke2.Serialize(), nil
+	return ke2.Serialize(), server.SerializeState(), nil
 }
 
-func (o *opaqueAdaptor) LoginFinalize(message []byte) ([]byte, error) {
-	ke3, err := o.server.Deserialize.KE3(message)
+func (o *opaqueAdaptor) LoginFinalize(message, state []byte) ([]byte, error) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if o.material == nil {
+		return nil, errClosed
+	}
+
+	// the AKE state holds everything the login needs, any key pair will do
+	server, err := o.newServer(o.material.keyVersion)
 	if err != nil {
-		log.ErrorLogger.Error("error at deserializing ke3 message", "error", err.Error())
 		return nil, err
 	}
 
-	if err := o.server.LoginFinish(ke3); err != nil {
-		log.ErrorLogger.Error("error at finalizing user login", "error", err.Error())
+	if err := server.SetAKEState(state); err != nil {
+		log.ErrorLogger.Error("error at restoring login state", "error", err.Error())
 		return nil, err
 	}
 
-	return o.server.SessionKey(), nil
-}
-
-func (o *opaqueAdaptor) getPublicKey() ([]byte, error) {
-	return os.ReadFile(o.config.Opaque.PublicKeyPath)
-}
+	ke3, err := server.Deserialize.KE3(message)
+	if err != nil {
+		log.ErrorLogger.Error("error at deserializing ke3 message", "error", err.Error())
+		return nil, err
+	}
 
-func (o *opaqueAdaptor) getPrivateKey() ([]byte, error) {
-	return os.ReadFile(o.config.Opaque.PrivateKeyPath)
-}
+	if err := server.LoginFinish(ke3); err != nil {
+		log.ErrorLogger.Error("error at finalizing user login", "error", err.Error())
+		return nil, err
+	}
 
-func (o *opaqueAdaptor) getOprfKey() ([]byte, error) {
-	return os.ReadFile(o.config.Opaque.OprfKeyPath)
+	return server.SessionKey(), nil
 }
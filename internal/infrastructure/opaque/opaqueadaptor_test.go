@@ -0,0 +1,291 @@
+package opaque_test
+
+import (
+	"crypto"
+	"path/filepath"
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/config"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/utils/oprfutils"
+	"github.com/bytemare/ksf"
+	bytemareOpaque "github.com/bytemare/opaque"
+	"github.com/stretchr/testify/require"
+)
+
+const serverID = "cool-password-manager"
+
+func TestOpaqueAdaptor_KeyRotation(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, oprfutils.GenerateAndSaveKeys(dir, oprfutils.DefaultSuite()))
+
+	old, err := opaque.New(newConfig(dir, 1))
+	require.NoError(t, err)
+	require.Equal(t, 1, old.KeyVersion())
+
+	oldAccount := register(t, old, newClient(t), opaque.NewCredentialID(), "old_account", "old-password")
+	require.Equal(t, 1, oldAccount.keyVersion)
+
+	// the server can not start on a version it has no keys for
+	_, err = opaque.New(newConfig(dir, 2))
+	require.Error(t, err)
+
+	require.NoError(t, oprfutils.GenerateKeyPair(dir, 2, oprfutils.DefaultSuite()))
+	rotated, err := opaque.New(newConfig(dir, 2))
+	require.NoError(t, err)
+	require.Equal(t, 2, rotated.KeyVersion())
+
+	newAccount := register(t, rotated, newClient(t), opaque.NewCredentialID(), "new_account", "new-password")
+	require.Equal(t, 2, newAccount.keyVersion)
+
+	tests := []struct {
+		name     string
+		account  registration
+		username string
+		password string
+		valid    bool
+	}{
+		{
+			name:     "account of the old keys",
+			account:  oldAccount,
+			username: "old_account",
+			password: "old-password",
+			valid:    true,
+		},
+		{
+			name:     "account of the new keys",
+			account:  newAccount,
+			username: "new_account",
+			password: "new-password",
+			valid:    true,
+		},
+		{
+			name:     "account of the old keys with the new keys",
+			account:  registration{oldAccount.record, oldAccount.credentialID, newAccount.keyVersion},
+			username: "old_account",
+			password: "old-password",
+			valid:    false,
+		},
+		{
+			name:     "wrong password",
+			account:  oldAccount,
+			username: "old_account",
+			password: "wrong-password",
+			valid:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.valid, login(t, rotated, newClient(t), tt.account, tt.username, tt.password))
+		})
+	}
+}
+
+func TestOpaqueAdaptor_CredentialID(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, oprfutils.GenerateAndSaveKeys(dir, oprfutils.DefaultSuite()))
+
+	server, err := opaque.New(newConfig(dir, 1))
+	require.NoError(t, err)
+
+	first := register(t, server, newClient(t), opaque.NewCredentialID(), "first_account", "password")
+	second := register(t, server, newClient(t), opaque.NewCredentialID(), "second_account", "password")
+	require.Len(t, first.credentialID, 64)
+	require.NotEqual(t, first.credentialID, second.credentialID)
+
+	// the OPRF key belongs to the credential ID, the record logs in with its own only
+	require.True(t, login(t, server, newClient(t), first, "first_account", "password"))
+	require.False(t, login(t, server, newClient(t),
+		registration{first.record, second.credentialID, first.keyVersion}, "first_account", "password"))
+	require.False(t, login(t, server, newClient(t),
+		registration{first.record, nil, first.keyVersion}, "first_account", "password"))
+
+	// registering the account again keeps its credential ID
+	changed := register(t, server, newClient(t), first.credentialID, "first_account", "new-password")
+	require.Equal(t, first.credentialID, changed.credentialID)
+	require.True(t, login(t, server, newClient(t), changed, "first_account", "new-password"))
+}
+
+func TestOpaqueAdaptor_Reload(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, oprfutils.GenerateAndSaveKeys(dir, oprfutils.DefaultSuite()))
+
+	conf := newConfig(dir, 1)
+	server, err := opaque.New(conf)
+	require.NoError(t, err)
+
+	account := register(t, server, newClient(t), opaque.NewCredentialID(), "reload_account", "password")
+	before, err := server.Status()
+	require.NoError(t, err)
+	require.Len(t, before.Fingerprints, 1)
+
+	// a version without keys is refused and the keys in use stay
+	conf.Opaque.KeyVersion = 2
+	require.Error(t, server.Reload())
+	require.Equal(t, 1, server.KeyVersion())
+	require.True(t, login(t, server, newClient(t), account, "reload_account", "password"))
+
+	require.NoError(t, oprfutils.GenerateKeyPair(dir, 2, oprfutils.DefaultSuite()))
+	require.NoError(t, server.Reload())
+	require.Equal(t, 2, server.KeyVersion())
+
+	after, err := server.Status()
+	require.NoError(t, err)
+	require.Len(t, after.Fingerprints, 2)
+	require.Equal(t, before.Fingerprints[1], after.Fingerprints[1])
+	require.NotEqual(t, after.Fingerprints[1], after.Fingerprints[2])
+	require.True(t, login(t, server, newClient(t), account, "reload_account", "password"))
+
+	server.Close()
+	_, err = server.Status()
+	require.Error(t, err)
+	_, _, err = server.RegisterInit(newClient(t).RegistrationInit([]byte("password")).Serialize(), nil)
+	require.Error(t, err)
+	require.Error(t, server.Reload())
+}
+
+func TestOpaqueAdaptor_Suites(t *testing.T) {
+	tests := []struct {
+		name  string
+		suite oprfutils.Suite
+		// legacy tells whether the records of the client bytemare/opaque builds by
+		// default log in under the suite
+		legacy bool
+	}{
+		{
+			name:   "default suite",
+			suite:  oprfutils.DefaultSuite(),
+			legacy: true,
+		},
+		{
+			name: "argon2id with other parameters",
+			suite: oprfutils.Suite{Name: oprfutils.SuiteP256, KSF: oprfutils.KSF{
+				Name: oprfutils.KSFArgon2id, Argon2Time: 1, Argon2Memory: 8 * 1024, Argon2Threads: 1,
+			}},
+		},
+		{
+			name: "p384 with scrypt",
+			suite: oprfutils.Suite{Name: oprfutils.SuiteP384, KSF: oprfutils.KSF{
+				Name: oprfutils.KSFScrypt, ScryptN: 1024, ScryptR: 8, ScryptP: 1,
+			}},
+		},
+		{
+			name:  "p521 without stretching",
+			suite: oprfutils.Suite{Name: oprfutils.SuiteP521, KSF: oprfutils.KSF{Name: oprfutils.KSFIdentity}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			require.NoError(t, oprfutils.GenerateAndSaveKeys(dir, tt.suite))
+
+			conf := newConfig(dir, 1)
+			conf.Opaque.Suite = tt.suite.Name
+			conf.Opaque.KSF.Name = tt.suite.KSF.Name
+			conf.Opaque.KSF.Argon2Time = tt.suite.KSF.Argon2Time
+			conf.Opaque.KSF.Argon2Memory = tt.suite.KSF.Argon2Memory
+			conf.Opaque.KSF.Argon2Threads = tt.suite.KSF.Argon2Threads
+			conf.Opaque.KSF.ScryptN = tt.suite.KSF.ScryptN
+			conf.Opaque.KSF.ScryptR = tt.suite.KSF.ScryptR
+			conf.Opaque.KSF.ScryptP = tt.suite.KSF.ScryptP
+			require.Equal(t, tt.suite, conf.GetOpaqueSuite())
+
+			server, err := opaque.New(conf)
+			require.NoError(t, err)
+
+			suiteClient := func() *bytemareOpaque.Client {
+				client, err := tt.suite.Client()
+				require.NoError(t, err)
+				return client
+			}
+
+			account := register(t, server, suiteClient(), opaque.NewCredentialID(), "suite_account", "password")
+			require.True(t, login(t, server, suiteClient(), account, "suite_account", "password"))
+			require.False(t, login(t, server, suiteClient(), account, "suite_account", "wrong-password"))
+
+			if tt.suite.Name == oprfutils.SuiteP256 {
+				require.Equal(t, tt.legacy, login(t, server, newClient(t), account, "suite_account", "password"))
+			}
+		})
+	}
+}
+
+func newConfig(dir string, keyVersion int) *config.Config {
+	conf := &config.Config{}
+	conf.Opaque.ServerID = serverID
+	conf.Opaque.OprfKeyPath = filepath.Join(dir, oprfutils.OprfSeedFile)
+	conf.Opaque.PrivateKeyPath = filepath.Join(dir, oprfutils.PrivateKeyFile)
+	conf.Opaque.PublicKeyPath = filepath.Join(dir, oprfutils.PublicKeyFile)
+	conf.Opaque.KeyVersion = keyVersion
+
+	return conf
+}
+
+func newClient(t *testing.T) *bytemareOpaque.Client {
+	client, err := bytemareOpaque.NewClient(&bytemareOpaque.Configuration{
+		OPRF: bytemareOpaque.P256Sha256,
+		AKE:  bytemareOpaque.P256Sha256,
+		Hash: crypto.SHA256,
+		KDF:  crypto.SHA256,
+		MAC:  crypto.SHA256,
+		KSF:  ksf.Argon2id,
+	})
+	require.NoError(t, err)
+
+	return client
+}
+
+// registration is what the accounts keep of their opaque registration.
+type registration struct {
+	record       []byte
+	credentialID []byte
+	keyVersion   int
+}
+
+func register(t *testing.T, server opaque.OpaqueService, client *bytemareOpaque.Client, credentialID []byte,
+	username, password string) registration {
+
+	response, registrationID, err := server.RegisterInit(client.RegistrationInit([]byte(password)).Serialize(), credentialID)
+	require.NoError(t, err)
+
+	message, err := client.Deserialize.RegistrationResponse(response)
+	require.NoError(t, err)
+
+	record, _ := client.RegistrationFinalize(message, bytemareOpaque.ClientRegistrationFinalizeOptions{
+		ClientIdentity: []byte(username),
+		ServerIdentity: []byte(serverID),
+	})
+
+	opaqueRecord, credentialID, keyVersion, err := server.RegisterFinalize(record.Serialize(), registrationID, username)
+	require.NoError(t, err)
+
+	return registration{record: opaqueRecord, credentialID: credentialID, keyVersion: keyVersion}
+}
+
+// login tells whether both the client and the server accept the login.
+func login(t *testing.T, server opaque.OpaqueService, client *bytemareOpaque.Client, account registration,
+	username, password string) bool {
+
+	ke2Message, state, err := server.LoginInit(
+		client.LoginInit([]byte(password)).Serialize(), account.record, account.credentialID, username, account.keyVersion,
+	)
+	require.NoError(t, err)
+
+	ke2, err := client.Deserialize.KE2(ke2Message)
+	require.NoError(t, err)
+
+	ke3, _, err := client.LoginFinish(ke2, bytemareOpaque.ClientLoginFinishOptions{
+		ClientIdentity: []byte(username),
+		ServerIdentity: []byte(serverID),
+	})
+	if err != nil {
+		return false
+	}
+
+	_, err = server.LoginFinalize(ke3.Serialize(), state)
+	return err == nil
+}
@@ -0,0 +1,28 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
+)
+
+type Job func(ctx context.Context) error
+
+// Every runs the job on every tick of the interval until the context is done. A failed
+// run is logged and the job runs again on the next tick.
+func Every(ctx context.Context, interval time.Duration, name string, job Job) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job(ctx); err != nil {
+				log.ErrorLogger.Error("scheduled job failed", "job", name, "error", err.Error())
+			}
+		}
+	}
+}
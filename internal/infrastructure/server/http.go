@@ -3,17 +3,25 @@ package server
 import (
 	"context"
 	"fmt"
-	"log"
+	stdlog "log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/TheAmirhosssein/cool-password-manage/config"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/app/account/delivery/http/router"
 	localHttp "github.com/TheAmirhosssein/cool-password-manage/internal/app/http"
+	vaultRouter "github.com/TheAmirhosssein/cool-password-manage/internal/app/vault/delivery/http/router"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/database"
+	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/opaque"
 	"github.com/TheAmirhosssein/cool-password-manage/internal/infrastructure/redis"
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/log"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	goredis "github.com/redis/go-redis/v9"
 )
 
 func Run(ctx context.Context, conf *config.Config) error {
@@ -34,12 +42,16 @@ func Run(ctx context.Context, conf *config.Config) error {
 	server.LoadHTMLGlob(conf.APP.RootPath + conf.APP.TemplatePath)
 	server.Static(conf.APP.StaticPath, conf.APP.RootPath+conf.APP.StaticPath)
 
-	err := router.AccountRouter(server, conf, db, redisClient)
+	opaqueAdaptor, err := opaque.New(conf)
 	if err != nil {
 		return err
 	}
+	defer opaqueAdaptor.Close()
 
-	localHttp.ErrorServer(server)
+	if _, err := Register(ctx, server, conf, db, redisClient, opaqueAdaptor); err != nil {
+		return err
+	}
+	go reloadOnHangup(ctx, opaqueAdaptor)
 
 	srv := &http.Server{
 		Addr:    fmt.Sprintf("%v:%v", conf.HTTP.Host, conf.HTTP.Port),
@@ -48,7 +60,7 @@ func Run(ctx context.Context, conf *config.Config) error {
 
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %s\n", err)
+			stdlog.Fatalf("listen: %s\n", err)
 		}
 	}()
 
@@ -58,3 +70,47 @@ func Run(ctx context.Context, conf *config.Config) error {
 	defer cancel()
 	return srv.Shutdown(shutdownCtx)
 }
+
+// Register adds the routes of the apps to the server and returns the JSON API they are
+// documented in. The client IP is the remote address unless the request comes from one
+// of the trusted proxies, so X-Forwarded-For can not get around the lockouts. The health
+// check is added first, the routers of the apps add the session and auth middlewares to
+// the whole server and the probes have no session.
+func Register(ctx context.Context, server *gin.Engine, conf *config.Config, db *pgxpool.Pool,
+	redisClient *goredis.Client, opaqueAdaptor opaque.OpaqueService) (localHttp.API, error) {
+	if err := server.SetTrustedProxies(conf.HTTP.TrustedProxies); err != nil {
+		return localHttp.API{}, fmt.Errorf("trusted proxies: %w", err)
+	}
+
+	localHttp.HealthServer(server, opaqueAdaptor)
+
+	api, err := router.AccountRouter(ctx, server, conf, db, redisClient, opaqueAdaptor)
+	if err != nil {
+		return localHttp.API{}, err
+	}
+
+	vaultRouter.VaultRouter(api, db, conf)
+
+	localHttp.ErrorServer(server)
+
+	return api, nil
+}
+
+// reloadOnHangup reads the OPAQUE keys again on every SIGHUP, so restored or re-sealed
+// keys are picked up without a restart.
+func reloadOnHangup(ctx context.Context, opaqueAdaptor opaque.OpaqueService) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			if err := opaqueAdaptor.Reload(); err != nil {
+				log.ErrorLogger.Error("error at reloading opaque keys", "error", err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package session
+
+import (
+	"encoding/base32"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gorilla/securecookie"
+	gsessions "github.com/gorilla/sessions"
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "session:"
+
+var base32RawStdEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// RedisStore keeps the session values in redis and only a signed session id in
+// the cookie, so a session can be killed on the server side by deleting its key.
+type RedisStore struct {
+	client  *redis.Client
+	codecs  []securecookie.Codec
+	options *gsessions.Options
+}
+
+func NewRedisStore(client *redis.Client, maxAge time.Duration, keyPairs ...[]byte) *RedisStore {
+	store := &RedisStore{
+		client: client,
+		codecs: securecookie.CodecsFromPairs(keyPairs...),
+		options: &gsessions.Options{
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+	}
+
+	store.maxAge(int(maxAge.Seconds()))
+	return store
+}
+
+func Key(id string) string {
+	return keyPrefix + id
+}
+
+func (s *RedisStore) Get(r *http.Request, name string) (*gsessions.Session, error) {
+	return gsessions.GetRegistry(r).Get(s, name)
+}
+
+func (s *RedisStore) New(r *http.Request, name string) (*gsessions.Session, error) {
+	session := gsessions.NewSession(s, name)
+	options := *s.options
+	session.Options = &options
+	session.IsNew = true
+
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+
+	if err := securecookie.DecodeMulti(name, cookie.Value, &session.ID, s.codecs...); err != nil {
+		session.ID = ""
+		return session, err
+	}
+
+	data, err := s.client.Get(r.Context(), Key(session.ID)).Result()
+	if err != nil {
+		// the session expired or has been revoked, a new id is issued on save
+		session.ID = ""
+		if errors.Is(err, redis.Nil) {
+			return session, nil
+		}
+		return session, err
+	}
+
+	if err := securecookie.DecodeMulti(name, data, &session.Values, s.codecs...); err != nil {
+		return session, err
+	}
+
+	session.IsNew = false
+	return session, nil
+}
+
+func (s *RedisStore) Save(r *http.Request, w http.ResponseWriter, session *gsessions.Session) error {
+	if session.Options.MaxAge <= 0 {
+		if session.ID != "" {
+			if err := s.client.Del(r.Context(), Key(session.ID)).Err(); err != nil {
+				return err
+			}
+		}
+		http.SetCookie(w, gsessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	if session.ID == "" {
+		session.ID = base32RawStdEncoding.EncodeToString(securecookie.GenerateRandomKey(32))
+	}
+
+	data, err := securecookie.EncodeMulti(session.Name(), session.Values, s.codecs...)
+	if err != nil {
+		return err
+	}
+
+	duration := time.Duration(session.Options.MaxAge) * time.Second
+	if err := s.client.Set(r.Context(), Key(session.ID), data, duration).Err(); err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.codecs...)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, gsessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+// Renew drops the current session id so the next save stores the values under a
+// fresh one. It should be called whenever the privilege level of a session changes.
+func (s *RedisStore) Renew(r *http.Request, name string) error {
+	session, err := s.Get(r, name)
+	if err != nil {
+		return err
+	}
+
+	if session.ID != "" {
+		if err := s.client.Del(r.Context(), Key(session.ID)).Err(); err != nil {
+			return err
+		}
+	}
+
+	session.ID = ""
+	return nil
+}
+
+func (s *RedisStore) Options(options sessions.Options) {
+	s.options = options.ToGorillaOptions()
+	s.maxAge(s.options.MaxAge)
+}
+
+func (s *RedisStore) maxAge(age int) {
+	s.options.MaxAge = age
+
+	for _, codec := range s.codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			sc.MaxAge(age)
+		}
+	}
+}
@@ -86,11 +86,11 @@ func createGroupSeed(ctx context.Context, db *pgxpool.Pool) {
 	}
 
 	gaQuery := `
-	INSERT INTO groups_accounts(group_id, account_id)
-	VALUES (1, 2), (1, 3), (1, 4), -- Brockhampton
-		(2, 5), (2, 6), (2, 7), -- Odd Future
-		(3, 8), (3, 9), (3, 10), (3, 11), -- Black Hippy
-		(4, 8), (4, 9), (4, 10), (4, 11), (4, 5), (4, 6); -- West Coast Rappers
+	INSERT INTO groups_accounts(group_id, account_id, role)
+	VALUES (1, 2, 'editor'), (1, 3, 'owner'), (1, 4, 'editor'), -- Brockhampton
+		(2, 5, 'owner'), (2, 6, 'editor'), (2, 7, 'editor'), -- Odd Future
+		(3, 8, 'owner'), (3, 9, 'editor'), (3, 10, 'editor'), (3, 11, 'editor'), -- Black Hippy
+		(4, 8, 'owner'), (4, 9, 'editor'), (4, 10, 'editor'), (4, 11, 'editor'), (4, 5, 'editor'), (4, 6, 'editor'); -- West Coast Rappers
 
 	`
 
@@ -1,16 +1,72 @@
 package oprfutils
 
 import (
-	"crypto"
 	"fmt"
 	"os"
 	"path/filepath"
+)
 
-	"github.com/bytemare/ksf"
-	"github.com/bytemare/opaque"
+// The files GenerateAndSaveKeys writes to its directory.
+const (
+	OprfSeedFile   = "oprf_seed.bin"
+	PrivateKeyFile = "server_private.bin"
+	PublicKeyFile  = "server_public.bin"
 )
 
-func GenerateAndSaveKeys(dir string) error {
+// PrivateKeyFileVersion and PublicKeyFileVersion name the files of an AKE key pair,
+// version 1 is the pair GenerateAndSaveKeys writes.
+func PrivateKeyFileVersion(version int) string {
+	if version <= 1 {
+		return PrivateKeyFile
+	}
+	return fmt.Sprintf("server_private_v%d.bin", version)
+}
+
+func PublicKeyFileVersion(version int) string {
+	if version <= 1 {
+		return PublicKeyFile
+	}
+	return fmt.Sprintf("server_public_v%d.bin", version)
+}
+
+// GenerateKeyPair writes a new AKE key pair of the version to dir, next to the keys of
+// GenerateAndSaveKeys. The OPRF seed is kept, a new one would change the password of
+// every account.
+func GenerateKeyPair(dir string, version int, suite Suite) error {
+	conf, err := suite.Configuration()
+	if err != nil {
+		return err
+	}
+	privateKey, publicKey := conf.KeyGen()
+
+	for _, key := range []struct {
+		name    string
+		content []byte
+	}{
+		{PrivateKeyFileVersion(version), privateKey},
+		{PublicKeyFileVersion(version), publicKey},
+	} {
+		name, content := key.name, key.content
+		file, err := os.OpenFile(filepath.Join(dir, name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+
+		if _, err := file.Write(content); err != nil {
+			file.Close()
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GenerateAndSaveKeys writes the OPRF seed and the first AKE key pair of the suite,
+// the keys are only valid in the suite they were generated for.
+func GenerateAndSaveKeys(dir string, suite Suite) error {
 	// Resolve absolute path
 	absDir, err := filepath.Abs(dir)
 	if err != nil {
@@ -23,9 +79,9 @@ func GenerateAndSaveKeys(dir string) error {
 	}
 
 	files := []string{
-		"oprf.bin",
-		"private.bin",
-		"public.bin",
+		OprfSeedFile,
+		PrivateKeyFile,
+		PublicKeyFile,
 	}
 
 	// If all exist → do nothing (OPAQUE safety)
@@ -44,15 +100,9 @@ func GenerateAndSaveKeys(dir string) error {
 		return nil
 	}
 
-	conf := &opaque.Configuration{
-		OPRF: opaque.P256Sha256,
-		AKE:  opaque.P256Sha256,
-
-		Hash: crypto.SHA256,
-		KDF:  crypto.SHA256,
-		MAC:  crypto.SHA256,
-
-		KSF: ksf.Argon2id,
+	conf, err := suite.Configuration()
+	if err != nil {
+		return err
 	}
 
 	// Generate keys (RAW BYTES)
@@ -60,9 +110,9 @@ func GenerateAndSaveKeys(dir string) error {
 	privateKey, publicKey := conf.KeyGen()
 
 	data := map[string][]byte{
-		"oprf_seed.bin":      oprfSeed,
-		"server_private.bin": privateKey,
-		"server_public.bin":  publicKey,
+		OprfSeedFile:   oprfSeed,
+		PrivateKeyFile: privateKey,
+		PublicKeyFile:  publicKey,
 	}
 
 	for name, content := range data {
@@ -0,0 +1,161 @@
+package oprfutils
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/bytemare/ksf"
+	"github.com/bytemare/opaque"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// The suites OPAQUE can run in, each is a group with the hash of its OPRF.
+const (
+	SuiteP256 = "P256-SHA256"
+	SuiteP384 = "P384-SHA384"
+	SuiteP521 = "P521-SHA512"
+)
+
+// The key stretching functions the clients can run over the OPRF output.
+const (
+	KSFArgon2id = "argon2id"
+	KSFScrypt   = "scrypt"
+	KSFIdentity = "identity"
+)
+
+// Suite is the OPAQUE setup the server and every client have to agree on. The server
+// only uses the group, the key stretching function runs on the clients alone, but a
+// record registered under one KSF does not log in under another.
+type Suite struct {
+	Name string `json:"name"`
+	KSF  KSF    `json:"ksf"`
+}
+
+// KSF is a key stretching function with its parameters, Argon2Memory is in KiB. The
+// salt is empty, as it is in bytemare/opaque, and the output is as long as an element
+// of the group.
+type KSF struct {
+	Name          string `json:"name"`
+	Argon2Time    uint32 `json:"argon2Time,omitempty"`
+	Argon2Memory  uint32 `json:"argon2Memory,omitempty"`
+	Argon2Threads uint8  `json:"argon2Threads,omitempty"`
+	ScryptN       int    `json:"scryptN,omitempty"`
+	ScryptR       int    `json:"scryptR,omitempty"`
+	ScryptP       int    `json:"scryptP,omitempty"`
+}
+
+// DefaultSuite is the suite of the accounts registered before it was configurable,
+// Argon2id with the parameters bytemare/opaque runs it with.
+func DefaultSuite() Suite {
+	return Suite{
+		Name: SuiteP256,
+		KSF: KSF{
+			Name:          KSFArgon2id,
+			Argon2Time:    3,
+			Argon2Memory:  64 * 1024,
+			Argon2Threads: 4,
+		},
+	}
+}
+
+// Validate reports the first problem of the suite.
+func (s Suite) Validate() error {
+	if _, err := s.Configuration(); err != nil {
+		return err
+	}
+
+	k := s.KSF
+	switch k.Name {
+	case KSFArgon2id:
+		if k.Argon2Time == 0 || k.Argon2Threads == 0 || k.Argon2Memory < 8*uint32(k.Argon2Threads) {
+			return errors.New("argon2id needs a positive time and threads, and at least 8 KiB of memory per thread")
+		}
+	case KSFScrypt:
+		if k.ScryptN <= 1 || k.ScryptN&(k.ScryptN-1) != 0 || k.ScryptR <= 0 || k.ScryptP <= 0 {
+			return errors.New("scrypt needs N to be a power of two above 1, and a positive r and p")
+		}
+	case KSFIdentity:
+	default:
+		return fmt.Errorf("unknown key stretching function %q", k.Name)
+	}
+
+	return nil
+}
+
+// Configuration is the bytemare/opaque configuration of the suite. Its KSF only names
+// the function, Client is the one that runs it with the parameters of the suite.
+func (s Suite) Configuration() (*opaque.Configuration, error) {
+	var group opaque.Group
+	var hash crypto.Hash
+	switch s.Name {
+	case SuiteP256:
+		group, hash = opaque.P256Sha256, crypto.SHA256
+	case SuiteP384:
+		group, hash = opaque.P384Sha512, crypto.SHA384
+	case SuiteP521:
+		group, hash = opaque.P521Sha512, crypto.SHA512
+	default:
+		return nil, fmt.Errorf("unknown opaque suite %q", s.Name)
+	}
+
+	var id ksf.Identifier
+	switch s.KSF.Name {
+	case KSFArgon2id:
+		id = ksf.Argon2id
+	case KSFScrypt:
+		id = ksf.Scrypt
+	}
+
+	return &opaque.Configuration{
+		OPRF: group,
+		AKE:  group,
+		Hash: hash,
+		KDF:  hash,
+		MAC:  hash,
+		KSF:  id,
+	}, nil
+}
+
+// Client returns a client of the suite that stretches with the parameters of its KSF.
+func (s Suite) Client() (*opaque.Client, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	conf, err := s.Configuration()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := conf.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	// bytemare/opaque always runs its key stretching functions with their default
+	// parameters, so the one of the suite takes its place in the client.
+	field := reflect.ValueOf(client.GetConf().KSF).Elem().Field(0)
+	reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Set(reflect.ValueOf(s.KSF))
+
+	return client, nil
+}
+
+// Harden stretches the password, the salt is the empty one of bytemare/opaque.
+func (k KSF) Harden(password, salt []byte, length int) []byte {
+	switch k.Name {
+	case KSFArgon2id:
+		return argon2.IDKey(password, salt, k.Argon2Time, k.Argon2Memory, k.Argon2Threads, uint32(length))
+	case KSFScrypt:
+		key, err := scrypt.Key(password, salt, k.ScryptN, k.ScryptR, k.ScryptP, length)
+		if err != nil {
+			panic(fmt.Errorf("scrypt: %w", err))
+		}
+		return key
+	default:
+		return password
+	}
+}
@@ -29,7 +29,13 @@ func EncryptAESSecret(key []byte, secret string) (string, error) {
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
-func DecryptAESSecret(key []byte, encrypted []byte) (string, error) {
+// DecryptAESSecret reverses EncryptAESSecret, so it takes the base64 encoded ciphertext.
+func DecryptAESSecret(key []byte, encoded []byte) (string, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil {
+		return "", err
+	}
+
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", err
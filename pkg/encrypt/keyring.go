@@ -0,0 +1,87 @@
+package encrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// keyIDSeparator ends the key id in front of a ciphertext, base64 never produces it
+// so a ciphertext without a key id is told apart.
+const keyIDSeparator = ":"
+
+var ErrUnknownKey = errors.New("the secret was not encrypted with a known key")
+
+// Keyring holds versioned AES keys. It encrypts with the active key and puts the id
+// of the key in front of the ciphertext, so retired keys keep decrypting what they
+// encrypted until it is encrypted again with the active one.
+type Keyring struct {
+	active string
+	keys   map[string][]byte
+}
+
+func NewKeyring(active string, keys map[string][]byte) (*Keyring, error) {
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, keyIDSeparator) {
+			return nil, fmt.Errorf("key id %q must be set and not contain %q", id, keyIDSeparator)
+		}
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+	}
+
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("the active key %q is not in the keyring", active)
+	}
+
+	return &Keyring{active: active, keys: keys}, nil
+}
+
+// ActiveID is the id of the key new secrets are encrypted with.
+func (k *Keyring) ActiveID() string {
+	return k.active
+}
+
+func (k *Keyring) Encrypt(secret string) (string, error) {
+	ciphertext, err := EncryptAESSecret(k.keys[k.active], secret)
+	if err != nil {
+		return "", err
+	}
+
+	return k.active + keyIDSeparator + ciphertext, nil
+}
+
+// Decrypt reverses Encrypt with the key named in front of the ciphertext. Secrets
+// encrypted before the keyring carry no key id, every key is tried for them.
+func (k *Keyring) Decrypt(encoded []byte) (string, error) {
+	id, ciphertext, found := bytes.Cut(encoded, []byte(keyIDSeparator))
+	if found {
+		key, ok := k.keys[string(id)]
+		if !ok {
+			return "", fmt.Errorf("%w: %q", ErrUnknownKey, id)
+		}
+		return DecryptAESSecret(key, ciphertext)
+	}
+
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if secret, err := DecryptAESSecret(k.keys[id], encoded); err == nil {
+			return secret, nil
+		}
+	}
+
+	return "", ErrUnknownKey
+}
+
+// IsActive reports whether the secret is encrypted with the active key already.
+func (k *Keyring) IsActive(encoded []byte) bool {
+	return bytes.HasPrefix(encoded, []byte(k.active+keyIDSeparator))
+}
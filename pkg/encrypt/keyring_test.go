@@ -0,0 +1,96 @@
+package encrypt_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/encrypt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	old, err := encrypt.NewKeyring("1", map[string][]byte{"1": oldKey})
+	require.NoError(t, err)
+	rotated, err := encrypt.NewKeyring("2", map[string][]byte{"1": oldKey, "2": newKey})
+	require.NoError(t, err)
+
+	fromOld, err := old.Encrypt("secret")
+	require.NoError(t, err)
+	fromNew, err := rotated.Encrypt("secret")
+	require.NoError(t, err)
+	legacy, err := encrypt.EncryptAESSecret(oldKey, "secret")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		keyring *encrypt.Keyring
+		secret  string
+		active  bool
+		err     error
+	}{
+		{
+			name:    "retired key",
+			keyring: rotated,
+			secret:  fromOld,
+			active:  false,
+		},
+		{
+			name:    "active key",
+			keyring: rotated,
+			secret:  fromNew,
+			active:  true,
+		},
+		{
+			name:    "without key id",
+			keyring: rotated,
+			secret:  legacy,
+			active:  false,
+		},
+		{
+			name:    "unknown key",
+			keyring: old,
+			secret:  fromNew,
+			err:     encrypt.ErrUnknownKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, err := tt.keyring.Decrypt([]byte(tt.secret))
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "secret", secret)
+			require.Equal(t, tt.active, tt.keyring.IsActive([]byte(tt.secret)))
+		})
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	tests := []struct {
+		name   string
+		active string
+		keys   map[string][]byte
+		valid  bool
+	}{
+		{name: "valid", active: "1", keys: map[string][]byte{"1": key}, valid: true},
+		{name: "missing active key", active: "2", keys: map[string][]byte{"1": key}},
+		{name: "id with separator", active: "a:b", keys: map[string][]byte{"a:b": key}},
+		{name: "short key", active: "1", keys: map[string][]byte{"1": key[:10]}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := encrypt.NewKeyring(tt.active, tt.keys)
+			require.Equal(t, tt.valid, err == nil)
+		})
+	}
+}
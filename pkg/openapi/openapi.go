@@ -0,0 +1,236 @@
+// Package openapi builds the OpenAPI 3.0 document of a gin API from the routes it
+// registers, so the document can not drift from the handlers.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	InPath  = "path"
+	InQuery = "query"
+)
+
+// Route is a handler along with what the document says about it. Responses maps a
+// status to an example of the body, nil for a response without one. A Public route is
+// documented as callable without authentication.
+type Route struct {
+	Method    string
+	Path      string
+	Summary   string
+	Tag       string
+	Public    bool
+	Params    []Param
+	Request   any
+	Responses map[int]any
+	Handler   gin.HandlerFunc
+}
+
+type Param struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+// PathParam and QueryParam describe the parameters of a route.
+func PathParam(name, description string) Param {
+	return Param{Name: name, In: InPath, Description: description, Required: true, Schema: &Schema{Type: "integer", Format: "int64"}}
+}
+
+func QueryParam(name, typ, description string) Param {
+	return Param{Name: name, In: InQuery, Description: description, Schema: &Schema{Type: typ}}
+}
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Servers    []Server                        `json:"servers"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+	Security   []map[string][]string           `json:"security"`
+
+	// types remembers the type behind every component, two types of the same name
+	// would otherwise share a schema.
+	types map[string]reflect.Type
+
+	once    sync.Once
+	encoded []byte
+	err     error
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+type Operation struct {
+	Summary     string              `json:"summary,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Param             `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+
+	// Security overrides the security of the document, an empty list drops it.
+	Security *[]map[string][]string `json:"security,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+const jsonContentType = "application/json"
+
+// New returns an empty document for the routes served under basePath. Every route
+// accepts a bearer token or the session cookie of the browser.
+func New(title, version, basePath, sessionCookie string) *Document {
+	return &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: title, Version: version},
+		Servers: []Server{{URL: strings.TrimSuffix(basePath, "/")}},
+		Paths:   map[string]map[string]Operation{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth":  {Type: "http", Scheme: "bearer"},
+				"sessionAuth": {Type: "apiKey", In: "cookie", Name: sessionCookie},
+			},
+		},
+		Security: []map[string][]string{{"bearerAuth": {}}, {"sessionAuth": {}}},
+		types:    map[string]reflect.Type{},
+	}
+}
+
+// Register adds the routes to the document and to the group.
+func (d *Document) Register(group *gin.RouterGroup, routes ...Route) {
+	for _, route := range routes {
+		d.Add(route)
+		group.Handle(route.Method, route.Path, route.Handler)
+	}
+}
+
+func (d *Document) Add(route Route) {
+	operation := Operation{
+		Summary:    route.Summary,
+		Parameters: route.Params,
+		Responses:  map[string]Response{},
+	}
+
+	if route.Tag != "" {
+		operation.Tags = []string{route.Tag}
+	}
+
+	if route.Public {
+		operation.Security = &[]map[string][]string{}
+	}
+
+	if route.Request != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{jsonContentType: {Schema: d.schemaOf(reflect.TypeOf(route.Request))}},
+		}
+	}
+
+	for status, body := range route.Responses {
+		response := Response{Description: http.StatusText(status)}
+		if body != nil {
+			response.Content = map[string]MediaType{jsonContentType: {Schema: d.schemaOf(reflect.TypeOf(body))}}
+		}
+
+		operation.Responses[strconv.Itoa(status)] = response
+	}
+
+	path := documentPath(route.Path)
+	if d.Paths[path] == nil {
+		d.Paths[path] = map[string]Operation{}
+	}
+	d.Paths[path][strings.ToLower(route.Method)] = operation
+}
+
+// Handler serves the document, it is encoded on the first request so every router
+// had the chance to register its routes.
+func (d *Document) Handler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		d.once.Do(func() {
+			d.encoded, d.err = json.Marshal(d)
+		})
+
+		if d.err != nil {
+			ctx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+
+		ctx.Data(http.StatusOK, jsonContentType, d.encoded)
+	}
+}
+
+// ValidateResponse checks the body a route answered with against the schema the
+// document has for it. path is the gin path of the route, relative to the base path.
+func (d *Document) ValidateResponse(method, path string, status int, body []byte) error {
+	operation, ok := d.Paths[documentPath(path)][strings.ToLower(method)]
+	if !ok {
+		return fmt.Errorf("%s %s is not documented", method, path)
+	}
+
+	response, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok {
+		return fmt.Errorf("%s %s does not document the status %d", method, path, status)
+	}
+
+	media, ok := response.Content[jsonContentType]
+	if !ok {
+		if len(body) != 0 {
+			return fmt.Errorf("%s %s answered %d with a body the document does not describe", method, path, status)
+		}
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("%s %s answered %d with invalid json: %w", method, path, status, err)
+	}
+
+	return d.Validate(media.Schema, value)
+}
+
+var pathParamPattern = regexp.MustCompile(`:(\w+)`)
+
+func documentPath(path string) string {
+	return "/" + strings.TrimPrefix(pathParamPattern.ReplaceAllString(path, "{$1}"), "/")
+}
@@ -0,0 +1,172 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TheAmirhosssein/cool-password-manage/pkg/openapi"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+type pet struct {
+	ID       int64      `json:"id"`
+	Name     string     `json:"name"`
+	Tag      string     `json:"tag,omitempty"`
+	Photo    []byte     `json:"photo,omitempty"`
+	BornAt   time.Time  `json:"bornAt"`
+	Owner    *owner     `json:"owner"`
+	Siblings []pet      `json:"siblings"`
+	AdoptsAt *time.Time `json:"adoptsAt"`
+}
+
+type owner struct {
+	Name string `json:"name" binding:"required,max=50"`
+	Age  int    `json:"age,omitempty" binding:"min=1,max=120"`
+}
+
+type petError struct {
+	Message string `json:"message"`
+}
+
+func TestDocument_ValidateResponse(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	doc := openapi.New("Pets", "1.0.0", "/api/", "session")
+	doc.Register(gin.New().Group("/api/"), openapi.Route{
+		Method:    http.MethodGet,
+		Path:      "pets/:id/",
+		Params:    []openapi.Param{openapi.PathParam("id", "id of the pet")},
+		Responses: map[int]any{http.StatusOK: pet{}, http.StatusNotFound: petError{}, http.StatusNoContent: nil},
+		Handler:   func(ctx *gin.Context) {},
+	})
+
+	testcases := []struct {
+		name   string
+		path   string
+		status int
+		body   string
+		valid  bool
+	}{
+		{
+			name:   "valid pet",
+			path:   "pets/:id/",
+			status: http.StatusOK,
+			body: `{"id":1,"name":"rex","photo":"cmV4","bornAt":"2020-01-02T03:04:05Z","owner":{"name":"ann"},
+				"siblings":[{"id":2,"name":"max","bornAt":"2020-01-02T03:04:05Z","owner":null,"siblings":[],"adoptsAt":null}],
+				"adoptsAt":null}`,
+			valid: true,
+		},
+		{
+			name:   "missing required property",
+			path:   "pets/:id/",
+			status: http.StatusOK,
+			body:   `{"id":1,"bornAt":"2020-01-02T03:04:05Z","owner":null,"siblings":[],"adoptsAt":null}`,
+		},
+		{
+			name:   "unknown property",
+			path:   "pets/:id/",
+			status: http.StatusOK,
+			body:   `{"id":1,"name":"rex","bornAt":"2020-01-02T03:04:05Z","owner":null,"siblings":[],"adoptsAt":null,"age":3}`,
+		},
+		{
+			name:   "wrong type",
+			path:   "pets/:id/",
+			status: http.StatusOK,
+			body:   `{"id":"1","name":"rex","bornAt":"2020-01-02T03:04:05Z","owner":null,"siblings":[],"adoptsAt":null}`,
+		},
+		{
+			name:   "invalid date-time",
+			path:   "pets/:id/",
+			status: http.StatusOK,
+			body:   `{"id":1,"name":"rex","bornAt":"yesterday","owner":null,"siblings":[],"adoptsAt":null}`,
+		},
+		{
+			name:   "null that is not nullable",
+			path:   "pets/:id/",
+			status: http.StatusOK,
+			body:   `{"id":1,"name":null,"bornAt":"2020-01-02T03:04:05Z","owner":null,"siblings":[],"adoptsAt":null}`,
+		},
+		{
+			name:   "error response",
+			path:   "pets/:id/",
+			status: http.StatusNotFound,
+			body:   `{"message":"no such pet"}`,
+			valid:  true,
+		},
+		{
+			name:   "response without a body",
+			path:   "pets/:id/",
+			status: http.StatusNoContent,
+			valid:  true,
+		},
+		{
+			name:   "undocumented status",
+			path:   "pets/:id/",
+			status: http.StatusTeapot,
+			body:   `{}`,
+		},
+		{
+			name:   "undocumented route",
+			path:   "owners/",
+			status: http.StatusOK,
+			body:   `{}`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := doc.ValidateResponse(http.MethodGet, tc.path, tc.status, []byte(tc.body))
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDocument_Handler(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	server := gin.New()
+	doc := openapi.New("Pets", "1.0.0", "/api/", "session")
+	doc.Register(server.Group("/api/"), openapi.Route{
+		Method:    http.MethodPost,
+		Path:      "pets/",
+		Tag:       "pets",
+		Request:   owner{},
+		Responses: map[int]any{http.StatusCreated: pet{}},
+		Handler: func(ctx *gin.Context) {
+			ctx.Status(http.StatusCreated)
+		},
+	})
+	server.GET("/api/openapi.json", doc.Handler())
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var served struct {
+		OpenAPI string                                `json:"openapi"`
+		Servers []map[string]string                   `json:"servers"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &served))
+	require.Equal(t, "3.0.3", served.OpenAPI)
+	require.Equal(t, "/api", served.Servers[0]["url"])
+	require.Contains(t, served.Paths["/pets/"], "post")
+	require.Contains(t, recorder.Body.String(), `"#/components/schemas/owner"`)
+	require.Contains(t, recorder.Body.String(), `"age":{"type":"integer","format":"int32","minimum":1,"maximum":120}`)
+	require.Contains(t, recorder.Body.String(), `"name":{"type":"string","maxLength":50}`)
+
+	// the route is registered on the group as well
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/pets/", nil))
+	require.Equal(t, http.StatusCreated, recorder.Code)
+}
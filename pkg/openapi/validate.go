@@ -0,0 +1,138 @@
+package openapi
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Validate checks a value decoded from json against the schema. Objects built from
+// structs are closed, a property the schema does not know is an error.
+func (d *Document) Validate(schema *Schema, value any) error {
+	return d.validate(schema, value, "$")
+}
+
+func (d *Document) validate(schema *Schema, value any, at string) error {
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		referenced, ok := d.Components.Schemas[name]
+		if !ok {
+			return fmt.Errorf("%s: unknown schema %s", at, schema.Ref)
+		}
+		return d.validate(referenced, value, at)
+	}
+
+	if value == nil {
+		if schema.Nullable || (schema.Type == "" && len(schema.AllOf) == 0) {
+			return nil
+		}
+		return fmt.Errorf("%s: is null", at)
+	}
+
+	for _, sub := range schema.AllOf {
+		if err := d.validate(sub, value, at); err != nil {
+			return err
+		}
+	}
+
+	switch schema.Type {
+	case "":
+		return nil
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return typeError(at, schema.Type, value)
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return typeError(at, schema.Type, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return typeError(at, schema.Type, value)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return typeError(at, schema.Type, value)
+		}
+		return validateFormat(schema.Format, str, at)
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return typeError(at, schema.Type, value)
+		}
+		for i, item := range items {
+			if err := d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return typeError(at, schema.Type, value)
+		}
+		return d.validateObject(schema, object, at)
+	default:
+		return fmt.Errorf("%s: unknown type %s", at, schema.Type)
+	}
+
+	return nil
+}
+
+func (d *Document) validateObject(schema *Schema, object map[string]any, at string) error {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: misses the property %s", at, name)
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		property, ok := schema.Properties[key]
+		if !ok {
+			property = schema.AdditionalProperties
+		}
+
+		if property == nil {
+			if schema.Properties != nil {
+				return fmt.Errorf("%s: has the unknown property %s", at, key)
+			}
+			continue
+		}
+
+		if err := d.validate(property, object[key], at+"."+key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateFormat(format, value, at string) error {
+	var err error
+	switch format {
+	case "byte":
+		_, err = base64.StdEncoding.DecodeString(value)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339Nano, value)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: is not a valid %s: %w", at, format, err)
+	}
+
+	return nil
+}
+
+func typeError(at, expected string, value any) error {
+	return fmt.Errorf("%s: expected %s, got %T", at, expected, value)
+}